@@ -0,0 +1,281 @@
+// Package admin implements the maintenance commands of the portainer admin CLI. They run against the data directory
+// of a stopped Portainer instance.
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/database"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/internal/endpointutils"
+	"github.com/portainer/portainer/api/internal/randomstring"
+
+	"github.com/rs/zerolog/log"
+)
+
+const generatedPasswordLength = 24
+
+var (
+	ErrDatabaseNotFound      = errors.New("unable to find a database in the data directory")
+	ErrDatabaseExists        = errors.New("a database already exists in the data directory")
+	ErrDatabaseNotEncrypted  = errors.New("the database is not encrypted yet, start Portainer once with the encryption key")
+	ErrSchemaVersionMismatch = errors.New("the database schema version does not match this version of Portainer, start Portainer once to migrate it")
+	ErrAdminNotFound         = errors.New("unable to find an administrator")
+	ErrPasswordTooShort      = errors.New("the password is shorter than the required password length")
+)
+
+// Options locates the database of the data directory
+type Options struct {
+	DataPath      string
+	DatabaseType  string
+	EncryptionKey []byte
+}
+
+// Store is a datastore opened by the admin commands, along with the file service of its data directory
+type Store struct {
+	*datastore.Store
+	FileService portainer.FileService
+}
+
+func (opts Options) flags() *portainer.CLIFlags {
+	kubectlShellImage := portainer.DefaultKubectlShellImage
+
+	return &portainer.CLIFlags{
+		Data:              &opts.DataPath,
+		DatabaseType:      &opts.DatabaseType,
+		KubectlShellImage: &kubectlShellImage,
+	}
+}
+
+// connection returns a connection to the database of the data directory, and whether the database exists
+func (opts Options) connection() (portainer.Connection, bool, error) {
+	connection, err := database.NewDatabase(opts.DatabaseType, opts.DataPath, opts.EncryptionKey, false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	needsEncryption, err := connection.NeedsEncryptionMigration()
+	if err != nil {
+		return nil, false, err
+	} else if needsEncryption {
+		return nil, false, ErrDatabaseNotEncrypted
+	}
+
+	_, err = os.Stat(connection.GetDatabaseFilePath())
+
+	return connection, err == nil, nil
+}
+
+func (opts Options) newStore(connection portainer.Connection) (*Store, error) {
+	fileService, err := filesystem.NewService(opts.DataPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the file service: %w", err)
+	}
+
+	return &Store{
+		Store:       datastore.NewStore(opts.flags(), fileService, connection),
+		FileService: fileService,
+	}, nil
+}
+
+// OpenStore opens the existing database of the data directory. Its schema version must match this version of
+// Portainer so that the commands do not write outdated objects
+func OpenStore(opts Options) (*Store, error) {
+	connection, exists, err := opts.connection()
+	if err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, ErrDatabaseNotFound
+	}
+
+	store, err := opts.newStore(connection)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := store.Open(); err != nil {
+		return nil, fmt.Errorf("unable to open the database, make sure Portainer is stopped: %w", err)
+	}
+
+	v, err := store.Version().Version()
+	if err != nil || v.SchemaVersion != portainer.APIVersion {
+		store.Close()
+
+		return nil, ErrSchemaVersionMismatch
+	}
+
+	return store, nil
+}
+
+// Export writes the database of the data directory to a JSON file, in the format of the database exports
+func Export(opts Options, filename string) error {
+	connection, exists, err := opts.connection()
+	if err != nil {
+		return err
+	} else if !exists {
+		return ErrDatabaseNotFound
+	}
+
+	return connection.ExportRaw(filename)
+}
+
+// Import creates the database of the data directory from a JSON export and migrates it to the schema of this
+// version of Portainer. The data directory must not hold a database yet
+func Import(opts Options, filename string) (err error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	connection, exists, err := opts.connection()
+	if err != nil {
+		return err
+	} else if exists {
+		return ErrDatabaseExists
+	}
+
+	store, err := opts.newStore(connection)
+	if err != nil {
+		return err
+	}
+
+	if _, err := store.Open(); err != nil {
+		return fmt.Errorf("unable to create the database: %w", err)
+	}
+
+	defer func() {
+		err = errors.Join(err, store.Close())
+		if err == nil {
+			return
+		}
+
+		// removes the incomplete database, along with the journal files of SQLite
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			os.Remove(connection.GetDatabaseFilePath() + suffix)
+		}
+	}()
+
+	if err := store.ImportJSON(f); err != nil {
+		return fmt.Errorf("unable to import the export: %w", err)
+	}
+
+	if err := store.MigrateData(); err != nil {
+		return fmt.Errorf("unable to migrate the imported database: %w", err)
+	}
+
+	return nil
+}
+
+// ResetAdminPassword sets the password of an administrator, the first one when no username is provided. A random
+// password is generated when none is provided, the password is returned either way
+func ResetAdminPassword(store dataservices.DataStore, username, password string) (string, error) {
+	var user *portainer.User
+
+	err := store.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		var err error
+		if user, err = findAdmin(tx, username); err != nil {
+			return err
+		}
+
+		settings, err := tx.Settings().Settings()
+		if err != nil {
+			return fmt.Errorf("unable to read the settings: %w", err)
+		}
+
+		if password == "" {
+			password = randomstring.RandomString(max(generatedPasswordLength, settings.InternalAuthSettings.RequiredPasswordLength))
+		} else if len(password) < settings.InternalAuthSettings.RequiredPasswordLength {
+			return ErrPasswordTooShort
+		}
+
+		if user.Password, err = (crypto.Service{}).Hash(password); err != nil {
+			return fmt.Errorf("unable to hash the password: %w", err)
+		}
+
+		// revokes the sessions of the user
+		user.TokenIssueAt = time.Now().Unix()
+
+		return tx.User().Update(user.ID, user)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Info().Str("username", user.Username).Msg("administrator password reset")
+
+	return password, nil
+}
+
+func findAdmin(tx dataservices.DataStoreTx, username string) (*portainer.User, error) {
+	if username != "" {
+		user, err := tx.User().UserByUsername(username)
+		if tx.IsErrObjectNotFound(err) || (err == nil && user.Role != portainer.AdministratorRole) {
+			return nil, fmt.Errorf("%w with the username %s", ErrAdminNotFound, username)
+		}
+
+		return user, err
+	}
+
+	admins, err := tx.User().UsersByRole(portainer.AdministratorRole)
+	if err != nil {
+		return nil, err
+	} else if len(admins) == 0 {
+		return nil, ErrAdminNotFound
+	}
+
+	sort.Slice(admins, func(i, j int) bool { return admins[i].ID < admins[j].ID })
+
+	return &admins[0], nil
+}
+
+// ListEnvironments returns the environments of the store, sorted by identifier
+func ListEnvironments(store dataservices.DataStore) ([]portainer.Endpoint, error) {
+	endpoints, err := store.Endpoint().Endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].ID < endpoints[j].ID })
+
+	return endpoints, nil
+}
+
+// RemoveEnvironment removes an environment and the objects referencing it, as its deletion through the API does
+func RemoveEnvironment(store *Store, endpointID portainer.EndpointID) error {
+	return store.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		endpoint, err := tx.Endpoint().Endpoint(endpointID)
+		if err != nil {
+			return fmt.Errorf("unable to find the environment %d: %w", endpointID, err)
+		}
+
+		if err := endpointutils.DeleteEndpoint(tx, store.FileService, endpoint); err != nil {
+			return err
+		}
+
+		if len(endpoint.UserAccessPolicies) > 0 || len(endpoint.TeamAccessPolicies) > 0 {
+			return authorization.NewService(tx).UpdateUsersAuthorizationsTx(tx)
+		}
+
+		return nil
+	})
+}
+
+// RotateEncryptionKey re-encrypts the database with a new encryption key and returns the path of the backup taken
+// before the rotation, which is encrypted with the previous key
+func RotateEncryptionKey(store *Store, newKey []byte) (string, error) {
+	if newKey == nil {
+		return "", errors.New("unable to load the new encryption key")
+	}
+
+	return store.RotateEncryptionKey(newKey)
+}
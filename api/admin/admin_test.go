@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/pkg/fips"
+
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	fips.InitFIPS(false)
+}
+
+// newTestDataPath creates a data directory holding a database with an administrator and an environment, and returns
+// the options to open it once the test store is closed
+func newTestDataPath(t *testing.T) Options {
+	t.Helper()
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	require.NoError(t, store.User().Create(&portainer.User{Username: "admin", Role: portainer.AdministratorRole}))
+	require.NoError(t, store.User().Create(&portainer.User{Username: "bob", Role: portainer.StandardUserRole}))
+	require.NoError(t, store.Tag().Create(&portainer.Tag{Name: "tag", Endpoints: map[portainer.EndpointID]bool{1: true}}))
+	require.NoError(t, store.Endpoint().Create(&portainer.Endpoint{ID: 1, Name: "local", TagIDs: []portainer.TagID{1}}))
+	require.NoError(t, store.EndpointRelation().Create(&portainer.EndpointRelation{EndpointID: 1}))
+
+	opts := Options{DataPath: store.Connection().GetStorePath(), DatabaseType: "boltdb"}
+	require.NoError(t, store.Close())
+
+	return opts
+}
+
+func openTestStore(t *testing.T, opts Options) *Store {
+	t.Helper()
+
+	store, err := OpenStore(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func TestOpenStore_noDatabase(t *testing.T) {
+	_, err := OpenStore(Options{DataPath: t.TempDir(), DatabaseType: "boltdb"})
+	require.ErrorIs(t, err, ErrDatabaseNotFound)
+}
+
+func TestExportImport(t *testing.T) {
+	opts := newTestDataPath(t)
+
+	exportPath := filepath.Join(t.TempDir(), "export.json")
+	require.NoError(t, Export(opts, exportPath))
+
+	// the data directory of the export already holds a database
+	require.ErrorIs(t, Import(opts, exportPath), ErrDatabaseExists)
+
+	for _, databaseType := range []string{"boltdb", "sqlite"} {
+		target := Options{DataPath: t.TempDir(), DatabaseType: databaseType}
+		require.NoError(t, Import(target, exportPath))
+
+		store := openTestStore(t, target)
+
+		endpoints, err := ListEnvironments(store)
+		require.NoError(t, err)
+		require.Len(t, endpoints, 1)
+		require.Equal(t, "local", endpoints[0].Name)
+
+		users, err := store.User().ReadAll()
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+
+		require.NoError(t, store.Close())
+	}
+}
+
+func TestResetAdminPassword(t *testing.T) {
+	store := openTestStore(t, newTestDataPath(t))
+
+	password, err := ResetAdminPassword(store, "", "")
+	require.NoError(t, err)
+	require.Len(t, password, generatedPasswordLength)
+
+	user, err := store.User().UserByUsername("admin")
+	require.NoError(t, err)
+	require.NoError(t, crypto.Service{}.CompareHashAndData(user.Password, password))
+	require.NotZero(t, user.TokenIssueAt)
+
+	password, err = ResetAdminPassword(store, "admin", "a-chosen-password")
+	require.NoError(t, err)
+	require.Equal(t, "a-chosen-password", password)
+
+	_, err = ResetAdminPassword(store, "admin", "short")
+	require.ErrorIs(t, err, ErrPasswordTooShort)
+
+	_, err = ResetAdminPassword(store, "bob", "")
+	require.ErrorIs(t, err, ErrAdminNotFound)
+
+	_, err = ResetAdminPassword(store, "unknown", "")
+	require.ErrorIs(t, err, ErrAdminNotFound)
+}
+
+func TestRemoveEnvironment(t *testing.T) {
+	store := openTestStore(t, newTestDataPath(t))
+
+	require.NoError(t, RemoveEnvironment(store, 1))
+
+	_, err := store.Endpoint().Endpoint(1)
+	require.True(t, dataservices.IsErrObjectNotFound(err))
+
+	_, err = store.EndpointRelation().EndpointRelation(1)
+	require.True(t, dataservices.IsErrObjectNotFound(err))
+
+	tag, err := store.Tag().Read(1)
+	require.NoError(t, err)
+	require.Empty(t, tag.Endpoints)
+
+	require.Error(t, RemoveEnvironment(store, 1))
+}
@@ -16,7 +16,10 @@ import (
 
 const portainerAPIKeyPrefix = "ptr_"
 
-var ErrInvalidAPIKey = errors.New("Invalid API key")
+var (
+	ErrInvalidAPIKey = errors.New("Invalid API key")
+	ErrExpiredAPIKey = errors.New("API key has expired")
+)
 
 type apiKeyService struct {
 	apiKeyRepository dataservices.APIKeyRepository
@@ -57,6 +60,13 @@ func (a *apiKeyService) HashRaw(rawKey string) string {
 // GenerateApiKey generates a raw API key for a user (for one-time display).
 // The generated API key is stored in the cache and database.
 func (a *apiKeyService) GenerateApiKey(user portainer.User, description string) (string, *portainer.APIKey, error) {
+	return a.GenerateScopedApiKey(user, description, 0, portainer.APIKeyScope{})
+}
+
+// GenerateScopedApiKey generates a raw API key for a user (for one-time display), which expires at the specified
+// Unix timestamp (0 for never) and only authenticates the requests allowed by the scope.
+// The generated API key is stored in the cache and database.
+func (a *apiKeyService) GenerateScopedApiKey(user portainer.User, description string, expiresAt int64, scope portainer.APIKeyScope) (string, *portainer.APIKey, error) {
 	randKey := GenerateRandomKey(32)
 	encodedRawAPIKey := base64.StdEncoding.EncodeToString(randKey)
 	prefixedAPIKey := portainerAPIKeyPrefix + encodedRawAPIKey
@@ -68,6 +78,8 @@ func (a *apiKeyService) GenerateApiKey(user portainer.User, description string)
 		Prefix:      prefixedAPIKey[:7],
 		DateCreated: time.Now().Unix(),
 		Digest:      hashDigest,
+		ExpiresAt:   expiresAt,
+		Scope:       scope,
 	}
 
 	if err := a.apiKeyRepository.Create(apiKey); err != nil {
@@ -92,9 +104,14 @@ func (a *apiKeyService) GetAPIKeys(userID portainer.UserID) ([]portainer.APIKey,
 
 // GetDigestUserAndKey returns the user and api-key associated to a specified hash digest.
 // A cache lookup is performed first; if the user/api-key is not found in the cache, respective database lookups are performed.
+// An expired api-key is rejected with ErrExpiredAPIKey.
 func (a *apiKeyService) GetDigestUserAndKey(digest string) (portainer.User, portainer.APIKey, error) {
 	cachedUser, cachedKey, ok := a.cache.Get(digest)
 	if ok {
+		if IsExpired(cachedKey) {
+			return portainer.User{}, portainer.APIKey{}, ErrExpiredAPIKey
+		}
+
 		return cachedUser, cachedKey, nil
 	}
 
@@ -103,6 +120,10 @@ func (a *apiKeyService) GetDigestUserAndKey(digest string) (portainer.User, port
 		return portainer.User{}, portainer.APIKey{}, errors.Wrap(err, "Unable to retrieve API key")
 	}
 
+	if IsExpired(*apiKey) {
+		return portainer.User{}, portainer.APIKey{}, ErrExpiredAPIKey
+	}
+
 	user, err := a.userRepository.Read(apiKey.UserID)
 	if err != nil {
 		return portainer.User{}, portainer.APIKey{}, errors.Wrap(err, "Unable to retrieve digest user")
@@ -138,6 +159,11 @@ func (a *apiKeyService) DeleteAPIKey(apiKeyID portainer.APIKeyID) error {
 	return a.apiKeyRepository.Delete(apiKeyID)
 }
 
+// IsExpired returns true when the expiry date of the API key has passed
+func IsExpired(apiKey portainer.APIKey) bool {
+	return apiKey.ExpiresAt != 0 && time.Now().Unix() >= apiKey.ExpiresAt
+}
+
 func (a *apiKeyService) InvalidateUserKeyCache(userId portainer.UserID) bool {
 	return a.cache.InvalidateUserKeyCache(userId)
 }
@@ -148,6 +148,48 @@ func Test_GetDigestUserAndKey(t *testing.T) {
 	})
 }
 
+func Test_GetDigestUserAndKey_Expiry(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	service := NewAPIKeyService(store.APIKeyRepository(), store.User())
+	user := portainer.User{ID: 1}
+
+	t.Run("Returns an api key that has not expired yet", func(t *testing.T) {
+		_, apiKey, err := service.GenerateScopedApiKey(user, "test-1", time.Now().Add(time.Hour).Unix(), portainer.APIKeyScope{})
+		require.NoError(t, err)
+
+		_, apiKeyGot, err := service.GetDigestUserAndKey(apiKey.Digest)
+		require.NoError(t, err)
+		is.Equal(*apiKey, apiKeyGot)
+	})
+
+	t.Run("Rejects an expired api key found in the cache", func(t *testing.T) {
+		_, apiKey, err := service.GenerateScopedApiKey(user, "test-2", time.Now().Add(-time.Minute).Unix(), portainer.APIKeyScope{})
+		require.NoError(t, err)
+
+		_, _, ok := service.cache.Get(apiKey.Digest)
+		is.True(ok)
+
+		_, _, err = service.GetDigestUserAndKey(apiKey.Digest)
+		is.ErrorIs(err, ErrExpiredAPIKey)
+	})
+
+	t.Run("Rejects an expired api key found in the database", func(t *testing.T) {
+		_, apiKey, err := service.GenerateScopedApiKey(user, "test-3", time.Now().Add(-time.Minute).Unix(), portainer.APIKeyScope{})
+		require.NoError(t, err)
+
+		service.cache.Delete(apiKey.Digest)
+
+		_, _, err = service.GetDigestUserAndKey(apiKey.Digest)
+		is.ErrorIs(err, ErrExpiredAPIKey)
+
+		_, _, ok := service.cache.Get(apiKey.Digest)
+		is.False(ok)
+	})
+}
+
 func Test_UpdateAPIKey(t *testing.T) {
 	is := assert.New(t)
 
@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/rs/zerolog/log"
+)
+
+const queueSize = 1024
+
+// Service records the audit trail of the API in the datastore
+type Service struct {
+	dataStore dataservices.DataStore
+	entries   chan portainer.AuditLog
+}
+
+// NewService creates a new audit service, entries are persisted in the background until the context is done
+func NewService(ctx context.Context, dataStore dataservices.DataStore) *Service {
+	service := &Service{
+		dataStore: dataStore,
+		entries:   make(chan portainer.AuditLog, queueSize),
+	}
+
+	go service.run(ctx)
+
+	return service
+}
+
+// RecordRequest records the outcome of a request made by an authenticated user.
+// Read-only requests are not recorded.
+func (service *Service) RecordRequest(r *http.Request, tokenData *portainer.TokenData, authMethod portainer.AuditAuthMethod, statusCode int) {
+	if !IsMutatingMethod(r.Method) {
+		return
+	}
+
+	path := requestPath(r)
+	endpointID, resourceType, resourceID := ParseResource(path)
+	if endpointID == 0 {
+		endpointID = endpointIDFromQuery(r)
+	}
+
+	outcome := portainer.AuditOutcomeSuccess
+	if statusCode >= http.StatusBadRequest {
+		outcome = portainer.AuditOutcomeFailure
+	}
+
+	service.Record(portainer.AuditLog{
+		Timestamp:    time.Now().UTC().Unix(),
+		UserID:       tokenData.ID,
+		Username:     tokenData.Username,
+		AuthMethod:   authMethod,
+		EndpointID:   endpointID,
+		Method:       r.Method,
+		Path:         path,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		StatusCode:   statusCode,
+		Outcome:      outcome,
+	})
+}
+
+// Record queues an audit log entry to be persisted. When the queue is full,
+// the entry is persisted synchronously so that no entry is ever dropped.
+func (service *Service) Record(entry portainer.AuditLog) {
+	select {
+	case service.entries <- entry:
+	default:
+		service.persist(entry)
+	}
+}
+
+// Prune removes the audit log entries older than the retention configured in the settings
+func (service *Service) Prune() error {
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return err
+	}
+
+	if settings.AuditLog.RetentionDays <= 0 {
+		return nil
+	}
+
+	before := time.Now().UTC().AddDate(0, 0, -settings.AuditLog.RetentionDays).Unix()
+
+	return service.dataStore.AuditLog().DeleteBefore(before)
+}
+
+func (service *Service) run(ctx context.Context) {
+	for {
+		select {
+		case entry := <-service.entries:
+			service.persist(entry)
+		case <-ctx.Done():
+			for {
+				select {
+				case entry := <-service.entries:
+					service.persist(entry)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (service *Service) persist(entry portainer.AuditLog) {
+	if err := service.dataStore.AuditLog().Create(&entry); err != nil {
+		log.Error().
+			Err(err).
+			Str("method", entry.Method).
+			Str("path", entry.Path).
+			Int("user_id", int(entry.UserID)).
+			Msg("unable to persist audit log entry")
+	}
+}
+
+// IsMutatingMethod returns true when the HTTP method can modify a resource
+func IsMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	return true
+}
+
+// requestPath returns the path of the request as received by the server,
+// before any prefix was stripped by the routers
+func requestPath(r *http.Request) string {
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil && u.Path != "" {
+		return u.Path
+	}
+
+	return r.URL.Path
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordRequest(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	service := &Service{dataStore: store, entries: make(chan portainer.AuditLog)}
+	tokenData := &portainer.TokenData{ID: 2, Username: "bob"}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stacks", nil)
+	service.RecordRequest(req, tokenData, portainer.AuditAuthMethodBearer, http.StatusOK)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/stacks/4?endpointId=3", nil)
+	service.RecordRequest(req, tokenData, portainer.AuditAuthMethodAPIKey, http.StatusForbidden)
+
+	entries, err := store.AuditLog().ReadAll()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	require.Equal(t, portainer.UserID(2), entry.UserID)
+	require.Equal(t, "bob", entry.Username)
+	require.Equal(t, portainer.AuditAuthMethodAPIKey, entry.AuthMethod)
+	require.Equal(t, portainer.EndpointID(3), entry.EndpointID)
+	require.Equal(t, "stacks", entry.ResourceType)
+	require.Equal(t, "4", entry.ResourceID)
+	require.Equal(t, http.StatusForbidden, entry.StatusCode)
+	require.Equal(t, portainer.AuditOutcomeFailure, entry.Outcome)
+}
+
+func TestPrune(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service := NewService(ctx, store)
+
+	settings, err := store.Settings().Settings()
+	require.NoError(t, err)
+	settings.AuditLog.RetentionDays = 7
+	require.NoError(t, store.Settings().UpdateSettings(settings))
+
+	now := time.Now().UTC()
+	require.NoError(t, store.AuditLog().Create(&portainer.AuditLog{Timestamp: now.AddDate(0, 0, -30).Unix()}))
+	require.NoError(t, store.AuditLog().Create(&portainer.AuditLog{Timestamp: now.Unix()}))
+
+	require.NoError(t, service.Prune())
+
+	entries, err := store.AuditLog().ReadAll()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, now.Unix(), entries[0].Timestamp)
+}
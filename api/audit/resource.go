@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+var dockerAPIVersionRegex = regexp.MustCompile(`^v\d+(\.\d+)?$`)
+
+// ParseResource extracts the environment identifier, the resource type and the resource identifier
+// targeted by an API path. Proxied Docker and Kubernetes requests are resolved to the
+// underlying Docker or Kubernetes resource.
+func ParseResource(path string) (portainer.EndpointID, string, string) {
+	segments := splitPath(strings.TrimPrefix(path, "/api"))
+	if len(segments) == 0 {
+		return 0, "", ""
+	}
+
+	if segments[0] != "endpoints" || len(segments) < 2 {
+		return 0, segments[0], segmentAt(segments, 1)
+	}
+
+	id, err := strconv.Atoi(segments[1])
+	if err != nil {
+		return 0, segments[0], segments[1]
+	}
+	endpointID := portainer.EndpointID(id)
+
+	if len(segments) < 3 {
+		return endpointID, "endpoints", segments[1]
+	}
+
+	switch segments[2] {
+	case "docker":
+		resourceType, resourceID := parseDockerResource(segments[3:])
+		return endpointID, resourceType, resourceID
+	case "kubernetes":
+		resourceType, resourceID := parseKubernetesResource(segments[3:])
+		return endpointID, resourceType, resourceID
+	}
+
+	return endpointID, "endpoints." + segments[2], segmentAt(segments, 3)
+}
+
+// parseDockerResource resolves a Docker API path such as /v1.41/containers/{id}/start
+func parseDockerResource(segments []string) (string, string) {
+	if len(segments) > 0 && dockerAPIVersionRegex.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+
+	if len(segments) == 0 {
+		return "docker", ""
+	}
+
+	resourceID := segmentAt(segments, 1)
+	switch resourceID {
+	case "create", "prune", "json":
+		resourceID = ""
+	}
+
+	return "docker." + segments[0], resourceID
+}
+
+// parseKubernetesResource resolves a Kubernetes API path such as
+// /api/v1/namespaces/{namespace}/pods/{name} or /apis/apps/v1/namespaces/{namespace}/deployments/{name}
+func parseKubernetesResource(segments []string) (string, string) {
+	switch segmentAt(segments, 0) {
+	case "api":
+		segments = segments[min(2, len(segments)):]
+	case "apis":
+		segments = segments[min(3, len(segments)):]
+	default:
+		if len(segments) == 0 {
+			return "kubernetes", ""
+		}
+
+		return "kubernetes." + segments[0], segmentAt(segments, 1)
+	}
+
+	if len(segments) == 0 {
+		return "kubernetes", ""
+	}
+
+	if segments[0] == "namespaces" && len(segments) > 2 {
+		namespace := segments[1]
+		resourceID := namespace
+		if name := segmentAt(segments, 3); name != "" {
+			resourceID = namespace + "/" + name
+		}
+
+		return "kubernetes." + segments[2], resourceID
+	}
+
+	return "kubernetes." + segments[0], segmentAt(segments, 1)
+}
+
+// endpointIDFromQuery returns the environment identifier passed as the endpointId query parameter, if any
+func endpointIDFromQuery(r *http.Request) portainer.EndpointID {
+	id, err := strconv.Atoi(r.URL.Query().Get("endpointId"))
+	if err != nil {
+		return 0
+	}
+
+	return portainer.EndpointID(id)
+}
+
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/'
+	})
+}
+
+func segmentAt(segments []string, index int) string {
+	if index < len(segments) {
+		return segments[index]
+	}
+
+	return ""
+}
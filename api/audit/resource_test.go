@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResource(t *testing.T) {
+	tests := []struct {
+		path             string
+		wantEndpointID   portainer.EndpointID
+		wantResourceType string
+		wantResourceID   string
+	}{
+		{path: "/api/stacks/3", wantResourceType: "stacks", wantResourceID: "3"},
+		{path: "/api/users", wantResourceType: "users"},
+		{path: "/api/endpoints/2", wantEndpointID: 2, wantResourceType: "endpoints", wantResourceID: "2"},
+		{path: "/api/endpoints/2/snapshot", wantEndpointID: 2, wantResourceType: "endpoints.snapshot"},
+		{path: "/api/endpoints/1/docker/containers/abc/start", wantEndpointID: 1, wantResourceType: "docker.containers", wantResourceID: "abc"},
+		{path: "/api/endpoints/1/docker/v1.41/containers/create", wantEndpointID: 1, wantResourceType: "docker.containers"},
+		{path: "/api/endpoints/1/docker/volumes/prune", wantEndpointID: 1, wantResourceType: "docker.volumes"},
+		{path: "/api/endpoints/5/kubernetes/api/v1/namespaces/default/pods/nginx", wantEndpointID: 5, wantResourceType: "kubernetes.pods", wantResourceID: "default/nginx"},
+		{path: "/api/endpoints/5/kubernetes/apis/apps/v1/namespaces/prod/deployments/web", wantEndpointID: 5, wantResourceType: "kubernetes.deployments", wantResourceID: "prod/web"},
+		{path: "/api/endpoints/5/kubernetes/api/v1/namespaces/prod/configmaps", wantEndpointID: 5, wantResourceType: "kubernetes.configmaps", wantResourceID: "prod"},
+		{path: "/api/endpoints/5/kubernetes/api/v1/nodes/node-1", wantEndpointID: 5, wantResourceType: "kubernetes.nodes", wantResourceID: "node-1"},
+		{path: "/", wantResourceType: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			endpointID, resourceType, resourceID := ParseResource(test.path)
+
+			assert.Equal(t, test.wantEndpointID, endpointID)
+			assert.Equal(t, test.wantResourceType, resourceType)
+			assert.Equal(t, test.wantResourceID, resourceID)
+		})
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"path/filepath"
 	"time"
 
+	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/archive"
 	"github.com/portainer/portainer/api/crypto"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/http/offlinegate"
+	"github.com/portainer/portainer/api/notifications"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -30,11 +32,36 @@ var filesToBackup = []string{
 	"extensions",
 	"portainer.key",
 	"portainer.pub",
+	"secret.key",
 	"tls",
 }
 
 // Creates a tar.gz system archive and encrypts it if password is not empty. Returns a path to the archive file.
 func CreateBackupArchive(password string, gate *offlinegate.OfflineGate, datastore dataservices.DataStore, filestorePath string) (string, error) {
+	archivePath, err := createBackupArchive(password, gate, datastore, filestorePath)
+	notifyBackup(err)
+
+	return archivePath, err
+}
+
+// notifyBackup sends the notification of the outcome of a backup
+func notifyBackup(err error) {
+	if err != nil {
+		notifications.Notify(portainer.NotificationEvent{
+			Type:    portainer.NotificationEventBackupFailed,
+			Message: fmt.Sprintf("Backup failed: %s", err),
+		})
+
+		return
+	}
+
+	notifications.Notify(portainer.NotificationEvent{
+		Type:    portainer.NotificationEventBackupCompleted,
+		Message: "Backup completed",
+	})
+}
+
+func createBackupArchive(password string, gate *offlinegate.OfflineGate, datastore dataservices.DataStore, filestorePath string) (string, error) {
 	backupDirPath, err := backupDatabaseAndFilesystem(gate, datastore, filestorePath)
 	if err != nil {
 		return "", err
@@ -0,0 +1,61 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/pkg/errors"
+)
+
+// ArchivePrefix is the prefix of the name of the archives created by the scheduled backups
+const ArchivePrefix = "portainer-backup_"
+
+// ArchiveInfo describes a backup archive stored at a destination
+type ArchiveInfo struct {
+	Name string `json:"Name" example:"portainer-backup_2024-01-01_02-00-00.tar.gz"`
+	Size int64  `json:"Size" example:"1048576"`
+}
+
+// Destination stores the backup archives
+type Destination interface {
+	Upload(ctx context.Context, name string, archive io.ReadSeeker, size int64) error
+	Download(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns the backup archives stored at the destination, sorted by name
+	List(ctx context.Context) ([]ArchiveInfo, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// NewDestination returns the destination matching the configuration
+func NewDestination(config portainer.BackupDestination) (Destination, error) {
+	switch config.Type {
+	case portainer.BackupDestinationLocal:
+		if config.LocalPath == "" {
+			return nil, errors.New("missing local path")
+		}
+
+		return &localDestination{dir: config.LocalPath}, nil
+	case portainer.BackupDestinationS3:
+		if config.S3 == nil {
+			return nil, errors.New("missing S3 configuration")
+		}
+
+		return newS3Destination(config.S3)
+	}
+
+	return nil, fmt.Errorf("unsupported backup destination type: %q", config.Type)
+}
+
+// validateArchiveName ensures the name designates an archive created by the scheduled backups and
+// cannot be used to reach outside of the destination
+func validateArchiveName(name string) error {
+	if !strings.HasPrefix(name, ArchivePrefix) || filepath.Base(name) != name || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid backup archive name: %q", name)
+	}
+
+	return nil
+}
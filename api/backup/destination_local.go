@@ -0,0 +1,92 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const rw_r_____ os.FileMode = 0o640
+
+type localDestination struct {
+	dir string
+}
+
+func (d *localDestination) Upload(ctx context.Context, name string, archive io.ReadSeeker, size int64) error {
+	if err := validateArchiveName(name); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(d.dir, rwxr__r__); err != nil {
+		return errors.Wrap(err, "failed to create the backup directory")
+	}
+
+	// Write to a temporary file first so that a partial archive is never listed
+	tmpPath := filepath.Join(d.dir, "."+name+".tmp")
+
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, rw_r_____)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, archive); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		os.Remove(tmpPath)
+
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Join(d.dir, name))
+}
+
+func (d *localDestination) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	if err := validateArchiveName(name); err != nil {
+		return nil, err
+	}
+
+	return os.Open(filepath.Join(d.dir, name))
+}
+
+func (d *localDestination) List(ctx context.Context) ([]ArchiveInfo, error) {
+	entries, err := os.ReadDir(d.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var archives []ArchiveInfo
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), ArchivePrefix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		archives = append(archives, ArchiveInfo{Name: entry.Name(), Size: info.Size()})
+	}
+
+	return archives, nil
+}
+
+func (d *localDestination) Delete(ctx context.Context, name string) error {
+	if err := validateArchiveName(name); err != nil {
+		return err
+	}
+
+	return os.Remove(filepath.Join(d.dir, name))
+}
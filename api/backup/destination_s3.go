@@ -0,0 +1,233 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	s3DefaultEndpoint = "https://s3.amazonaws.com"
+	s3DefaultRegion   = "us-east-1"
+	// sha256 of an empty payload
+	s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3Destination stores the archives in an S3-compatible bucket, using path-style requests so that
+// self-hosted implementations such as MinIO work without DNS configuration
+type s3Destination struct {
+	config   *portainer.S3BackupDestination
+	endpoint *url.URL
+	client   *http.Client
+	signer   *v4.Signer
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func newS3Destination(config *portainer.S3BackupDestination) (*s3Destination, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("missing S3 bucket")
+	}
+
+	rawEndpoint := config.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = s3DefaultEndpoint
+	}
+
+	endpoint, err := url.Parse(rawEndpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint: %q", config.Endpoint)
+	}
+
+	return &s3Destination{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 30 * time.Minute},
+		signer:   v4.NewSigner(),
+	}, nil
+}
+
+func (d *s3Destination) Upload(ctx context.Context, name string, archive io.ReadSeeker, size int64) error {
+	if err := validateArchiveName(name); err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, archive); err != nil {
+		return err
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := d.newRequest(ctx, http.MethodPut, d.key(name), nil, io.NopCloser(archive))
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+
+	resp, err := d.do(req, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+func (d *s3Destination) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	if err := validateArchiveName(name); err != nil {
+		return nil, err
+	}
+
+	req, err := d.newRequest(ctx, http.MethodGet, d.key(name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.do(req, s3EmptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (d *s3Destination) List(ctx context.Context) ([]ArchiveInfo, error) {
+	var archives []ArchiveInfo
+
+	continuationToken := ""
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", d.key(ArchivePrefix))
+
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		req, err := d.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := d.do(req, s3EmptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode the S3 bucket listing")
+		}
+
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, d.config.Prefix)
+			if validateArchiveName(name) != nil {
+				continue
+			}
+
+			archives = append(archives, ArchiveInfo{Name: name, Size: object.Size})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return archives, nil
+		}
+
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (d *s3Destination) Delete(ctx context.Context, name string) error {
+	if err := validateArchiveName(name); err != nil {
+		return err
+	}
+
+	req, err := d.newRequest(ctx, http.MethodDelete, d.key(name), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.do(req, s3EmptyPayloadHash)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+func (d *s3Destination) key(name string) string {
+	return d.config.Prefix + name
+}
+
+func (d *s3Destination) newRequest(ctx context.Context, method, key string, query url.Values, body io.ReadCloser) (*http.Request, error) {
+	u := *d.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + d.config.Bucket + "/" + key
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Body = body
+	}
+
+	return req, nil
+}
+
+// do signs the request with the configured credentials and sends it, the response body must be
+// closed by the caller when no error is returned
+func (d *s3Destination) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	region := d.config.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	credentials := aws.Credentials{AccessKeyID: d.config.AccessKeyID, SecretAccessKey: d.config.SecretAccessKey}
+	if err := d.signer.SignHTTP(req.Context(), credentials, req, payloadHash, "s3", region, time.Now()); err != nil {
+		return nil, errors.Wrap(err, "failed to sign the S3 request")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("unexpected S3 response status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,147 @@
+package backup
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// s3Stub is a minimal in-memory implementation of the S3 path-style API, its listing is paginated
+// with a single object per page to exercise the continuation tokens
+type s3Stub struct {
+	t       *testing.T
+	bucket  string
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket != s.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		s.list(w, r)
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		s.objects[key] = body
+	case r.Method == http.MethodGet:
+		body, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *s3Stub) list(w http.ResponseWriter, r *http.Request) {
+	assert.Equal(s.t, "2", r.URL.Query().Get("list-type"))
+
+	prefix := r.URL.Query().Get("prefix")
+
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// the continuation token is the index of the next key to return
+	var start int
+	fmt.Sscanf(r.URL.Query().Get("continuation-token"), "%d", &start)
+	keys = keys[start:]
+
+	var result listBucketResult
+	if len(keys) > 0 {
+		result.Contents = append(result.Contents, struct {
+			Key  string `xml:"Key"`
+			Size int64  `xml:"Size"`
+		}{Key: keys[0], Size: int64(len(s.objects[keys[0]]))})
+	}
+
+	if len(keys) > 1 {
+		result.IsTruncated = true
+		result.NextContinuationToken = strconv.Itoa(start + 1)
+	}
+
+	xml.NewEncoder(w).Encode(result)
+}
+
+func Test_s3Destination(t *testing.T) {
+	stub := &s3Stub{t: t, bucket: "backups", objects: map[string][]byte{"portainer/other": []byte("x")}}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	destination, err := NewDestination(portainer.BackupDestination{
+		Type: portainer.BackupDestinationS3,
+		S3: &portainer.S3BackupDestination{
+			Endpoint:        srv.URL,
+			Region:          "eu-west-1",
+			Bucket:          "backups",
+			Prefix:          "portainer/",
+			AccessKeyID:     "access",
+			SecretAccessKey: "secret",
+		},
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"2024-01-01", "2024-01-02", "2024-01-03"} {
+		upload(t, destination, ArchivePrefix+name+".tar.gz", name)
+	}
+
+	assert.Contains(t, stub.objects, "portainer/"+ArchivePrefix+"2024-01-01.tar.gz")
+
+	archives, err := destination.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []ArchiveInfo{
+		{Name: ArchivePrefix + "2024-01-01.tar.gz", Size: 10},
+		{Name: ArchivePrefix + "2024-01-02.tar.gz", Size: 10},
+		{Name: ArchivePrefix + "2024-01-03.tar.gz", Size: 10},
+	}, archives)
+
+	require.NoError(t, rotate(context.Background(), destination, 1))
+
+	r, err := destination.Download(context.Background(), ArchivePrefix+"2024-01-03.tar.gz")
+	require.NoError(t, err)
+
+	content, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-03", string(content))
+
+	_, err = destination.Download(context.Background(), ArchivePrefix+"2024-01-01.tar.gz")
+	assert.Error(t, err, "the rotated archive should be deleted")
+	assert.Contains(t, stub.objects, "portainer/other", "objects that are not backup archives should be left untouched")
+}
@@ -0,0 +1,118 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func upload(t *testing.T, destination Destination, name, content string) {
+	t.Helper()
+
+	err := destination.Upload(context.Background(), name, bytes.NewReader([]byte(content)), int64(len(content)))
+	require.NoError(t, err)
+}
+
+func Test_localDestination_roundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backups")
+
+	destination, err := NewDestination(portainer.BackupDestination{Type: portainer.BackupDestinationLocal, LocalPath: dir})
+	require.NoError(t, err)
+
+	archives, err := destination.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, archives, "a missing directory should not be an error")
+
+	upload(t, destination, ArchivePrefix+"2024-01-01_02-00-00.tar.gz", "content")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unrelated.txt"), []byte("x"), 0o600))
+
+	archives, err = destination.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []ArchiveInfo{{Name: ArchivePrefix + "2024-01-01_02-00-00.tar.gz", Size: 7}}, archives)
+
+	r, err := destination.Download(context.Background(), archives[0].Name)
+	require.NoError(t, err)
+
+	content, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+
+	require.NoError(t, destination.Delete(context.Background(), archives[0].Name))
+
+	archives, err = destination.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, archives)
+}
+
+func Test_localDestination_rejectsInvalidNames(t *testing.T) {
+	destination, err := NewDestination(portainer.BackupDestination{Type: portainer.BackupDestinationLocal, LocalPath: t.TempDir()})
+	require.NoError(t, err)
+
+	for _, name := range []string{"../" + ArchivePrefix + "x", ArchivePrefix + "../x", "portainer.db", ""} {
+		_, err := destination.Download(context.Background(), name)
+		assert.Error(t, err, name)
+
+		assert.Error(t, destination.Delete(context.Background(), name), name)
+	}
+}
+
+func Test_rotate(t *testing.T) {
+	destination, err := NewDestination(portainer.BackupDestination{Type: portainer.BackupDestinationLocal, LocalPath: t.TempDir()})
+	require.NoError(t, err)
+
+	for _, name := range []string{"2024-01-03", "2024-01-01", "2024-01-04", "2024-01-02"} {
+		upload(t, destination, ArchivePrefix+name+".tar.gz", name)
+	}
+
+	require.NoError(t, rotate(context.Background(), destination, 0))
+
+	archives, err := destination.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, archives, 4, "a retention of 0 should keep every archive")
+
+	require.NoError(t, rotate(context.Background(), destination, 2))
+
+	archives, err = destination.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []ArchiveInfo{
+		{Name: ArchivePrefix + "2024-01-03.tar.gz", Size: 10},
+		{Name: ArchivePrefix + "2024-01-04.tar.gz", Size: 10},
+	}, archives)
+
+	latest, err := LatestArchive(context.Background(), destination)
+	require.NoError(t, err)
+	assert.Equal(t, ArchivePrefix+"2024-01-04.tar.gz", latest)
+}
+
+func Test_ValidateSettings(t *testing.T) {
+	local := portainer.BackupDestination{Type: portainer.BackupDestinationLocal, LocalPath: "/backups"}
+
+	tests := []struct {
+		name     string
+		settings portainer.BackupSettings
+		valid    bool
+	}{
+		{"disabled without destination", portainer.BackupSettings{}, true},
+		{"enabled", portainer.BackupSettings{Enabled: true, CronRule: "0 2 * * *", Destination: local}, true},
+		{"invalid cron rule", portainer.BackupSettings{Enabled: true, CronRule: "every day", Destination: local}, false},
+		{"missing destination", portainer.BackupSettings{Enabled: true, CronRule: "0 2 * * *"}, false},
+		{"negative retention", portainer.BackupSettings{MaxBackups: -1, Destination: local}, false},
+		{"missing bucket", portainer.BackupSettings{Destination: portainer.BackupDestination{Type: portainer.BackupDestinationS3, S3: &portainer.S3BackupDestination{}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSettings(&tt.settings)
+			assert.Equal(t, tt.valid, err == nil, err)
+		})
+	}
+}
@@ -2,17 +2,20 @@ package backup
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/portainer/portainer/api/archive"
 	"github.com/portainer/portainer/api/crypto"
 	"github.com/portainer/portainer/api/database/boltdb"
+	"github.com/portainer/portainer/api/database/sqlite"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/http/offlinegate"
@@ -20,6 +23,12 @@ import (
 
 var filesToRestore = append(filesToBackup, "portainer.db")
 
+// databaseFileNames lists the database files of each database type, unencrypted first
+var databaseFileNames = [][]string{
+	{boltdb.DatabaseFileName, boltdb.EncryptedDatabaseFileName},
+	{sqlite.DatabaseFileName, sqlite.EncryptedDatabaseFileName},
+}
+
 // Restores system state from backup archive, will trigger system shutdown, when finished.
 func RestoreArchive(archive io.Reader, password string, filestorePath string, gate *offlinegate.OfflineGate, datastore dataservices.DataStore, shutdownTrigger context.CancelFunc) error {
 	var err error
@@ -38,6 +47,17 @@ func RestoreArchive(archive io.Reader, password string, filestorePath string, ga
 		return errors.Wrap(err, "cannot extract files from the archive. Please ensure the password is correct and try again")
 	}
 
+	// At some point, backups were created containing a subdirectory, now we need to handle both
+	restorePath, err = getRestoreSourcePath(restorePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to restore from backup. Portainer database missing from backup file")
+	}
+
+	// Restoring the database of another type would leave the instance without a database, uninitialized
+	if err := checkDatabaseType(restorePath, datastore.Connection().GetDatabaseFileName()); err != nil {
+		return err
+	}
+
 	unlock := gate.Lock()
 	defer unlock()
 
@@ -45,12 +65,6 @@ func RestoreArchive(archive io.Reader, password string, filestorePath string, ga
 		return errors.Wrap(err, "Failed to stop db")
 	}
 
-	// At some point, backups were created containing a subdirectory, now we need to handle both
-	restorePath, err = getRestoreSourcePath(restorePath)
-	if err != nil {
-		return errors.Wrap(err, "failed to restore from backup. Portainer database missing from backup file")
-	}
-
 	if err = restoreFiles(restorePath, filestorePath); err != nil {
 		return errors.Wrap(err, "failed to restore the system state")
 	}
@@ -68,8 +82,8 @@ func extractArchive(r io.Reader, destinationDirPath string) error {
 }
 
 func getRestoreSourcePath(dir string) (string, error) {
-	// find portainer.db or portainer.edb file. Return the parent directory
-	var portainerdbRegex = regexp.MustCompile(`^portainer.e?db$`)
+	// find portainer.db, portainer.edb, portainer.sqlite or portainer.esqlite file. Return the parent directory
+	var portainerdbRegex = regexp.MustCompile(`^portainer\.e?(db|sqlite)$`)
 
 	backupDirPath := dir
 	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
@@ -87,6 +101,26 @@ func getRestoreSourcePath(dir string) (string, error) {
 	return backupDirPath, err
 }
 
+// checkDatabaseType returns an error when the archive extracted in dir does not contain a database of the same type as
+// the database file of the running instance
+func checkDatabaseType(dir string, currentFileName string) error {
+	for _, fileNames := range databaseFileNames {
+		if !slices.Contains(fileNames, currentFileName) {
+			continue
+		}
+
+		for _, fileName := range fileNames {
+			if _, err := os.Stat(filepath.Join(dir, fileName)); err == nil {
+				return nil
+			}
+		}
+
+		return errors.New("the database of the backup file is not of the type used by this instance, restart Portainer with the database type of the backup to restore it")
+	}
+
+	return fmt.Errorf("unknown database file %s", currentFileName)
+}
+
 func restoreFiles(srcDir string, destinationDir string) error {
 	for _, filename := range filesToRestore {
 		err := filesystem.CopyPath(filepath.Join(srcDir, filename), destinationDir)
@@ -95,19 +129,21 @@ func restoreFiles(srcDir string, destinationDir string) error {
 		}
 	}
 
-	// TODO:  This is very boltdb module specific once again due to the filename.  Move to bolt module? Refactor for another day
-
-	// Prevent the possibility of having both databases.  Remove any default new instance
-	os.Remove(filepath.Join(destinationDir, boltdb.DatabaseFileName))
-	os.Remove(filepath.Join(destinationDir, boltdb.EncryptedDatabaseFileName))
+	// Prevent the possibility of having several databases.  Remove any default new instance
+	for _, filename := range slices.Concat(databaseFileNames...) {
+		os.Remove(filepath.Join(destinationDir, filename))
+		os.Remove(filepath.Join(destinationDir, filename+"-wal"))
+		os.Remove(filepath.Join(destinationDir, filename+"-shm"))
+	}
 
-	// Now copy the database.  It'll be either portainer.db or portainer.edb
+	// Now copy the database.  It'll be one of portainer.db, portainer.edb, portainer.sqlite or portainer.esqlite
 
 	// Note: CopyPath does not return an error if the source file doesn't exist
-	err := filesystem.CopyPath(filepath.Join(srcDir, boltdb.EncryptedDatabaseFileName), destinationDir)
-	if err != nil {
-		return err
+	for _, filename := range slices.Concat(databaseFileNames...) {
+		if err := filesystem.CopyPath(filepath.Join(srcDir, filename), destinationDir); err != nil {
+			return err
+		}
 	}
 
-	return filesystem.CopyPath(filepath.Join(srcDir, boltdb.DatabaseFileName), destinationDir)
+	return nil
 }
@@ -0,0 +1,33 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRestoreSourcePath(t *testing.T) {
+	for _, fileName := range []string{"portainer.db", "portainer.edb", "portainer.sqlite", "portainer.esqlite"} {
+		dir := t.TempDir()
+		backupDir := filepath.Join(dir, "portainer-backup")
+		require.NoError(t, os.MkdirAll(backupDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(backupDir, fileName), nil, 0o600))
+
+		path, err := getRestoreSourcePath(dir)
+		require.NoError(t, err)
+		assert.Equal(t, backupDir, path, fileName)
+	}
+}
+
+func TestCheckDatabaseType(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "portainer.esqlite"), nil, 0o600))
+
+	require.NoError(t, checkDatabaseType(dir, "portainer.sqlite"))
+	require.NoError(t, checkDatabaseType(dir, "portainer.esqlite"))
+	require.Error(t, checkDatabaseType(dir, "portainer.db"), "a SQLite backup must not be restored on a BoltDB instance")
+	require.Error(t, checkDatabaseType(dir, "portainer.edb"))
+}
@@ -0,0 +1,301 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/offlinegate"
+	"github.com/portainer/portainer/api/scheduler"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const secretKeyInfo = "portainer backup settings"
+
+// ScheduledService runs the scheduled backups and uploads the archives to the configured destination
+type ScheduledService struct {
+	dataStore     dataservices.DataStore
+	cipher        *crypto.SecretCipher
+	gate          *offlinegate.OfflineGate
+	filestorePath string
+	scheduler     *scheduler.Scheduler
+	shutdownCtx   context.Context
+	mu            sync.Mutex
+	jobID         string
+	running       sync.Mutex
+}
+
+// NewScheduledService creates a new service, Start must be called to schedule the configured backups. The backup
+// password and the S3 secret access key are encrypted with a key derived from secretKey, the secret key of the instance
+func NewScheduledService(shutdownCtx context.Context, dataStore dataservices.DataStore, gate *offlinegate.OfflineGate, filestorePath string, scheduler *scheduler.Scheduler, secretKey []byte) (*ScheduledService, error) {
+	cipher, err := crypto.NewSecretCipher(secretKey, secretKeyInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScheduledService{
+		dataStore:     dataStore,
+		cipher:        cipher,
+		gate:          gate,
+		filestorePath: filestorePath,
+		scheduler:     scheduler,
+		shutdownCtx:   shutdownCtx,
+	}, nil
+}
+
+// Start schedules the backups according to the settings persisted in the database
+func (service *ScheduledService) Start() error {
+	settings, err := service.Settings()
+	if err != nil {
+		return err
+	}
+
+	return service.Reschedule(settings)
+}
+
+// Settings returns the backup settings with their secrets decrypted, empty settings are returned when none were saved
+func (service *ScheduledService) Settings() (*portainer.BackupSettings, error) {
+	settings, err := service.dataStore.BackupSettings().Settings()
+	if service.dataStore.IsErrObjectNotFound(err) {
+		return &portainer.BackupSettings{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, field := range secretFields(settings) {
+		if *field, err = service.cipher.Decrypt(*field); err != nil {
+			return nil, fmt.Errorf("unable to decrypt the backup settings: %w", err)
+		}
+	}
+
+	return settings, nil
+}
+
+// UpdateSettings encrypts the secrets of the settings and saves them, the settings of the caller are left unchanged
+func (service *ScheduledService) UpdateSettings(settings *portainer.BackupSettings) error {
+	encrypted := *settings
+	if settings.Destination.S3 != nil {
+		s3 := *settings.Destination.S3
+		encrypted.Destination.S3 = &s3
+	}
+
+	for _, field := range secretFields(&encrypted) {
+		var err error
+		if *field, err = service.cipher.Encrypt(*field); err != nil {
+			return err
+		}
+	}
+
+	return service.dataStore.BackupSettings().UpdateSettings(&encrypted)
+}
+
+// secretFields returns the archive password and the S3 secret access key of the settings
+func secretFields(settings *portainer.BackupSettings) []*string {
+	fields := []*string{&settings.Password}
+
+	if settings.Destination.S3 != nil {
+		fields = append(fields, &settings.Destination.S3.SecretAccessKey)
+	}
+
+	return fields
+}
+
+// Reschedule replaces the current backup job with one matching the given settings
+func (service *ScheduledService) Reschedule(settings *portainer.BackupSettings) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.jobID != "" {
+		if err := service.scheduler.StopJob(service.jobID); err != nil {
+			return err
+		}
+
+		service.jobID = ""
+	}
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	jobID, err := service.scheduler.StartCronJob(settings.CronRule, func() error {
+		if err := service.Run(); err != nil {
+			log.Error().Err(err).Msg("scheduled backup failed")
+		}
+
+		// the job must keep running on its schedule whatever the outcome
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	service.jobID = jobID
+
+	return nil
+}
+
+// Run creates a backup archive, uploads it to the configured destination, removes the archives exceeding
+// the retention count and records the outcome in the backup status
+func (service *ScheduledService) Run() error {
+	if !service.running.TryLock() {
+		return errors.New("a backup is already running")
+	}
+	defer service.running.Unlock()
+
+	settings, err := service.Settings()
+	if err != nil {
+		return pkgerrors.Wrap(err, "failed to retrieve the backup settings")
+	}
+
+	status := &portainer.BackupStatus{LastRunAt: time.Now().Unix()}
+
+	// The backup is only completed once the archive is uploaded
+	archive, size, err := service.run(settings)
+	notifyBackup(err)
+
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Archive = archive
+		status.Size = size
+	}
+
+	if err := service.dataStore.BackupSettings().UpdateStatus(status); err != nil {
+		log.Error().Err(err).Msg("unable to persist the backup status")
+	}
+
+	return err
+}
+
+func (service *ScheduledService) run(settings *portainer.BackupSettings) (string, int64, error) {
+	destination, err := NewDestination(settings.Destination)
+	if err != nil {
+		return "", 0, err
+	}
+
+	archivePath, err := createBackupArchive(settings.Password, service.gate, service.dataStore, service.filestorePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.RemoveAll(filepath.Dir(archivePath))
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+
+	name := ArchivePrefix + filepath.Base(archivePath)
+
+	if err := destination.Upload(service.shutdownCtx, name, f, info.Size()); err != nil {
+		return "", 0, pkgerrors.Wrap(err, "failed to upload the backup archive")
+	}
+
+	if err := rotate(service.shutdownCtx, destination, settings.MaxBackups); err != nil {
+		log.Warn().Err(err).Msg("unable to remove the old backup archives")
+	}
+
+	return name, info.Size(), nil
+}
+
+// rotate keeps the maxBackups most recent archives of the destination, 0 keeps them all
+func rotate(ctx context.Context, destination Destination, maxBackups int) error {
+	if maxBackups <= 0 {
+		return nil
+	}
+
+	archives, err := destination.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(archives) <= maxBackups {
+		return nil
+	}
+
+	// the archive names embed their creation date, sorting them by name sorts them by age
+	slices.SortFunc(archives, func(a, b ArchiveInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var errs []error
+	for _, archive := range archives[:len(archives)-maxBackups] {
+		if err := destination.Delete(ctx, archive.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// LatestArchive returns the name of the most recent archive of the destination
+func LatestArchive(ctx context.Context, destination Destination) (string, error) {
+	archives, err := destination.List(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(archives) == 0 {
+		return "", errors.New("no backup archive found at the destination")
+	}
+
+	return slices.MaxFunc(archives, func(a, b ArchiveInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	}).Name, nil
+}
+
+// RestoreFromDestination downloads an archive from the destination and restores it, the most recent
+// archive is used when name is empty
+func RestoreFromDestination(ctx context.Context, destination Destination, name string, password string, filestorePath string, gate *offlinegate.OfflineGate, datastore dataservices.DataStore, shutdownTrigger context.CancelFunc) error {
+	if name == "" {
+		var err error
+		if name, err = LatestArchive(ctx, destination); err != nil {
+			return err
+		}
+	}
+
+	archive, err := destination.Download(ctx, name)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "failed to download the backup archive %q", name)
+	}
+	defer archive.Close()
+
+	return RestoreArchive(archive, password, filestorePath, gate, datastore, shutdownTrigger)
+}
+
+// ValidateSettings checks that the backup settings can be used to schedule backups
+func ValidateSettings(settings *portainer.BackupSettings) error {
+	if settings.MaxBackups < 0 {
+		return errors.New("the maximum number of backups cannot be negative")
+	}
+
+	if !settings.Enabled && settings.Destination.Type == "" {
+		return nil
+	}
+
+	if settings.Enabled {
+		if err := scheduler.ValidateCronRule(settings.CronRule); err != nil {
+			return fmt.Errorf("invalid cron rule: %w", err)
+		}
+	}
+
+	_, err := NewDestination(settings.Destination)
+
+	return err
+}
@@ -235,3 +235,16 @@ func encryptCredentials(username, password, key string) (string, error) {
 
 	return base64.RawStdEncoding.EncodeToString(encryptedCredentials), nil
 }
+
+// ActiveTunnels returns a copy of the details of every tunnel that is currently open
+func (s *Service) ActiveTunnels() map[portainer.EndpointID]portainer.TunnelDetails {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tunnels := make(map[portainer.EndpointID]portainer.TunnelDetails, len(s.activeTunnels))
+	for endpointID, tun := range s.activeTunnels {
+		tunnels[endpointID] = *tun
+	}
+
+	return tunnels
+}
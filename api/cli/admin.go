@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"github.com/alecthomas/kingpin/v2"
+)
+
+// AdminCommand is the name of the command running the admin sub-commands, as in portainer admin export
+const AdminCommand = "admin"
+
+// AdminFlags holds the flags of the admin sub-commands, Command is the name of the sub-command to run
+type AdminFlags struct {
+	Command          string
+	Data             *string
+	DatabaseType     *string
+	SecretKeyName    *string
+	File             *string
+	Username         *string
+	Password         *string
+	EndpointID       *int
+	NewSecretKeyName *string
+}
+
+// ParseAdminFlags parses the arguments following the admin command
+func (Service) ParseAdminFlags(args []string) (*AdminFlags, error) {
+	app := kingpin.New("portainer "+AdminCommand, "Maintenance commands run against the data directory of a stopped Portainer instance")
+
+	flags := &AdminFlags{
+		Data:          app.Flag("data", "Path to the folder where the data is stored").Default(defaultDataDirectory).Short('d').String(),
+		DatabaseType:  app.Flag("database-type", "Database storing the data").Default("boltdb").Enum("boltdb", "sqlite"),
+		SecretKeyName: app.Flag("secret-key-name", "Secret key name for encryption and will be used as /run/secrets/<secret-key-name>.").Default(defaultSecretKeyName).String(),
+	}
+
+	exportCmd := app.Command("export", "Export the database to a JSON file")
+	exportFile := exportCmd.Arg("file", "Path of the JSON file to create").Required().String()
+
+	importCmd := app.Command("import", "Create the database from a JSON export, the data directory must not hold a database")
+	importFile := importCmd.Arg("file", "Path of the JSON export").Required().ExistingFile()
+
+	resetCmd := app.Command("reset-admin-password", "Reset the password of an administrator")
+	flags.Username = resetCmd.Flag("username", "Username of the administrator, the first administrator when omitted").String()
+	flags.Password = resetCmd.Flag("password", "New password, a random password is generated when omitted").String()
+
+	app.Command("list-environments", "List the environments")
+
+	removeCmd := app.Command("remove-environment", "Remove an environment and the objects referencing it")
+	flags.EndpointID = removeCmd.Arg("id", "Identifier of the environment").Required().Int()
+
+	rotateCmd := app.Command("rotate-encryption-key", "Re-encrypt the database with a new encryption key, --secret-key-name being the current one")
+	flags.NewSecretKeyName = rotateCmd.Flag("new-secret-key-name", "Secret key name of the new encryption key and will be used as /run/secrets/<new-secret-key-name>.").Required().String()
+
+	command, err := app.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	flags.Command = command
+
+	switch command {
+	case exportCmd.FullCommand():
+		flags.File = exportFile
+	case importCmd.FullCommand():
+		flags.File = importFile
+	}
+
+	return flags, nil
+}
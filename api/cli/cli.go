@@ -50,6 +50,7 @@ func CLIFlags() *portainer.CLIFlags {
 		MaxBatchSize:              kingpin.Flag("max-batch-size", "Maximum size of a batch").Int(),
 		MaxBatchDelay:             kingpin.Flag("max-batch-delay", "Maximum delay before a batch starts").Duration(),
 		SecretKeyName:             kingpin.Flag("secret-key-name", "Secret key name for encryption and will be used as /run/secrets/<secret-key-name>.").Default(defaultSecretKeyName).String(),
+		NewSecretKeyName:          kingpin.Flag("new-secret-key-name", "Secret key name of a new encryption key, the database is re-encrypted with it on startup. It will be used as /run/secrets/<new-secret-key-name>.").String(),
 		LogLevel:                  kingpin.Flag("log-level", "Set the minimum logging level to show").Default("INFO").Enum("DEBUG", "INFO", "WARN", "ERROR"),
 		LogMode:                   kingpin.Flag("log-mode", "Set the logging output mode").Default("PRETTY").Enum("NOCOLOR", "PRETTY", "JSON"),
 		KubectlShellImage:         kingpin.Flag("kubectl-shell-image", "Kubectl shell image").Envar(portainer.KubectlShellImageEnvVar).Default(portainer.DefaultKubectlShellImage).String(),
@@ -57,6 +58,9 @@ func CLIFlags() *portainer.CLIFlags {
 		TrustedOrigins:            kingpin.Flag("trusted-origins", "List of trusted origins for CSRF protection. Separate multiple origins with a comma.").Envar(portainer.TrustedOriginsEnvVar).String(),
 		CSP:                       kingpin.Flag("csp", "Content Security Policy (CSP) header").Envar(portainer.CSPEnvVar).Default("true").Bool(),
 		CompactDB:                 kingpin.Flag("compact-db", "Enable database compaction on startup").Envar(portainer.CompactDBEnvVar).Default("false").Bool(),
+		DatabaseType:              kingpin.Flag("database-type", "Database storing the data").Envar(portainer.DatabaseTypeEnvVar).Default("boltdb").Enum("boltdb", "sqlite"),
+		MigrateDatabaseToSQLite:   kingpin.Flag("migrate-database-to-sqlite", "Copy the BoltDB database into a new SQLite database, validate the copy and exit").Bool(),
+		MetricsToken:              kingpin.Flag("metrics-token", "Bearer token allowed to scrape the metrics endpoint without an administrator session").Envar(portainer.MetricsTokenEnvVar).String(),
 	}
 }
 
@@ -207,3 +207,39 @@ func setupLogOutput(t *testing.T, w io.Writer) {
 		zerolog.Logger = oldLogger
 	})
 }
+
+func TestParseAdminFlags(t *testing.T) {
+	p := Service{}
+
+	flags, err := p.ParseAdminFlags([]string{"--data", "/tmp/data", "export", "/tmp/export.json"})
+	require.NoError(t, err)
+	require.Equal(t, "export", flags.Command)
+	require.Equal(t, "/tmp/data", *flags.Data)
+	require.Equal(t, "boltdb", *flags.DatabaseType)
+	require.Equal(t, "/tmp/export.json", *flags.File)
+
+	flags, err = p.ParseAdminFlags([]string{"reset-admin-password", "--username", "admin", "--database-type", "sqlite"})
+	require.NoError(t, err)
+	require.Equal(t, "reset-admin-password", flags.Command)
+	require.Equal(t, "admin", *flags.Username)
+	require.Equal(t, "sqlite", *flags.DatabaseType)
+
+	flags, err = p.ParseAdminFlags([]string{"remove-environment", "3"})
+	require.NoError(t, err)
+	require.Equal(t, 3, *flags.EndpointID)
+
+	_, err = p.ParseAdminFlags([]string{"remove-environment"})
+	require.Error(t, err)
+
+	flags, err = p.ParseAdminFlags([]string{"rotate-encryption-key", "--secret-key-name", "old", "--new-secret-key-name", "new"})
+	require.NoError(t, err)
+	require.Equal(t, "rotate-encryption-key", flags.Command)
+	require.Equal(t, "old", *flags.SecretKeyName)
+	require.Equal(t, "new", *flags.NewSecretKeyName)
+
+	_, err = p.ParseAdminFlags([]string{"rotate-encryption-key"})
+	require.Error(t, err)
+
+	_, err = p.ParseAdminFlags([]string{"unknown"})
+	require.Error(t, err)
+}
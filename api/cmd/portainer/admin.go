@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/admin"
+	"github.com/portainer/portainer/api/cli"
+	"github.com/portainer/portainer/pkg/fips"
+)
+
+// runAdmin runs an admin sub-command against the data directory of a stopped instance, as in portainer admin export
+func runAdmin(args []string, out io.Writer) error {
+	flags, err := cli.Service{}.ParseAdminFlags(args)
+	if err != nil {
+		return err
+	}
+
+	// -ce can not ever be run in FIPS mode
+	fips.InitFIPS(false)
+
+	opts := admin.Options{
+		DataPath:      *flags.Data,
+		DatabaseType:  *flags.DatabaseType,
+		EncryptionKey: loadEncryptionSecretKey(dbSecretPath(*flags.SecretKeyName)),
+	}
+
+	switch flags.Command {
+	case "export":
+		if err := admin.Export(opts, *flags.File); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "database exported to %s\n", *flags.File)
+
+		return nil
+	case "import":
+		if err := admin.Import(opts, *flags.File); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "database imported from %s\n", *flags.File)
+
+		return nil
+	}
+
+	store, err := admin.OpenStore(opts)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	switch flags.Command {
+	case "reset-admin-password":
+		password, err := admin.ResetAdminPassword(store, *flags.Username, *flags.Password)
+		if err != nil {
+			return err
+		}
+
+		if *flags.Password == "" {
+			fmt.Fprintf(out, "password reset, the new password is: %s\n", password)
+		} else {
+			fmt.Fprintln(out, "password reset")
+		}
+	case "list-environments":
+		endpoints, err := admin.ListEnvironments(store)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tTYPE\tURL")
+		for _, endpoint := range endpoints {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", endpoint.ID, endpoint.Name, endpoint.Type, endpoint.URL)
+		}
+
+		return w.Flush()
+	case "remove-environment":
+		if err := admin.RemoveEnvironment(store, portainer.EndpointID(*flags.EndpointID)); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "environment %d removed\n", *flags.EndpointID)
+	case "rotate-encryption-key":
+		backupFilename, err := admin.RotateEncryptionKey(store, loadEncryptionSecretKey(dbSecretPath(*flags.NewSecretKeyName)))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "encryption key rotated, use --secret-key-name=%s from now on. The previous database is kept in %s\n", *flags.NewSecretKeyName, backupFilename)
+	}
+
+	return nil
+}
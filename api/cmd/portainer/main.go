@@ -7,15 +7,18 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/apikey"
+	"github.com/portainer/portainer/api/audit"
 	"github.com/portainer/portainer/api/chisel"
 	"github.com/portainer/portainer/api/cli"
 	"github.com/portainer/portainer/api/crypto"
 	"github.com/portainer/portainer/api/database"
 	"github.com/portainer/portainer/api/database/boltdb"
 	"github.com/portainer/portainer/api/database/models"
+	"github.com/portainer/portainer/api/database/sqlite"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/datastore"
 	"github.com/portainer/portainer/api/datastore/migrator"
@@ -25,6 +28,7 @@ import (
 	"github.com/portainer/portainer/api/exec"
 	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/git"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
 	"github.com/portainer/portainer/api/hostmanagement/openamt"
 	"github.com/portainer/portainer/api/http"
 	"github.com/portainer/portainer/api/http/proxy"
@@ -40,6 +44,8 @@ import (
 	kubecli "github.com/portainer/portainer/api/kubernetes/cli"
 	"github.com/portainer/portainer/api/ldap"
 	"github.com/portainer/portainer/api/logs"
+	"github.com/portainer/portainer/api/metrics"
+	"github.com/portainer/portainer/api/notifications"
 	"github.com/portainer/portainer/api/oauth"
 	"github.com/portainer/portainer/api/pendingactions"
 	"github.com/portainer/portainer/api/pendingactions/actions"
@@ -53,6 +59,7 @@ import (
 	"github.com/portainer/portainer/pkg/libhelm"
 	libhelmtypes "github.com/portainer/portainer/pkg/libhelm/types"
 	"github.com/portainer/portainer/pkg/libstack/compose"
+	"github.com/portainer/portainer/pkg/libstack/swarm"
 	"github.com/portainer/portainer/pkg/validate"
 
 	"github.com/gofrs/uuid"
@@ -84,17 +91,38 @@ func initFileService(dataStorePath string) portainer.FileService {
 }
 
 func initDataStore(flags *portainer.CLIFlags, secretKey []byte, fileService portainer.FileService, shutdownCtx context.Context) dataservices.DataStore {
-	connection, err := database.NewDatabase("boltdb", *flags.Data, secretKey, *flags.CompactDB)
+	if *flags.MigrateDatabaseToSQLite {
+		if err := database.MigrateToSQLite(*flags.Data, secretKey); err != nil {
+			log.Fatal().Err(err).Msg("failed migrating the database to SQLite")
+		}
+
+		log.Info().Msg("exiting database migration, start Portainer with --database-type=sqlite to use the SQLite database")
+		os.Exit(0)
+	}
+
+	// a new SQLite database next to an existing BoltDB one would start an empty instance
+	if *flags.DatabaseType == "sqlite" && database.HasUnmigratedBoltDB(*flags.Data) {
+		log.Fatal().Msg("failed creating database connection: a BoltDB database exists, run Portainer with --migrate-database-to-sqlite to copy it into SQLite first")
+	}
+
+	if *flags.NewSecretKeyName != "" {
+		secretKey = rotateEncryptionKey(flags, secretKey, fileService)
+	}
+
+	connection, err := database.NewDatabase(*flags.DatabaseType, *flags.Data, secretKey, *flags.CompactDB)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed creating database connection")
 	}
 
-	if bconn, ok := connection.(*boltdb.DbConnection); ok {
-		bconn.MaxBatchSize = *flags.MaxBatchSize
-		bconn.MaxBatchDelay = *flags.MaxBatchDelay
-		bconn.InitialMmapSize = *flags.InitialMmapSize
-	} else {
-		log.Fatal().Msg("failed creating database connection: expecting a boltdb database type but a different one was received")
+	switch conn := connection.(type) {
+	case *boltdb.DbConnection:
+		conn.MaxBatchSize = *flags.MaxBatchSize
+		conn.MaxBatchDelay = *flags.MaxBatchDelay
+		conn.InitialMmapSize = *flags.InitialMmapSize
+	case *sqlite.DbConnection:
+		// no tuning flags for SQLite
+	default:
+		log.Fatal().Msg("failed creating database connection: unexpected database type")
 	}
 
 	store := datastore.NewStore(flags, fileService, connection)
@@ -159,6 +187,62 @@ func initDataStore(flags *portainer.CLIFlags, secretKey []byte, fileService port
 	return store
 }
 
+// rotateEncryptionKey re-encrypts the database with the key of the new secret and returns that key. When the database
+// cannot be opened with the current key, it must open with the new one, as when Portainer restarts with the same flags
+// after the rotation
+func rotateEncryptionKey(flags *portainer.CLIFlags, currentKey []byte, fileService portainer.FileService) []byte {
+	newKey := loadEncryptionSecretKey(dbSecretPath(*flags.NewSecretKeyName))
+	if newKey == nil {
+		log.Fatal().Str("secret", *flags.NewSecretKeyName).Msg("failed loading the new encryption key")
+	}
+
+	if currentKey == nil {
+		log.Fatal().Msg("failed rotating the encryption key: the database is not encrypted, use --secret-key-name to encrypt it")
+	}
+
+	connection, err := database.NewDatabase(*flags.DatabaseType, *flags.Data, currentKey, false)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed creating database connection")
+	}
+
+	store := datastore.NewStore(flags, fileService, connection)
+	if _, err := store.Open(); err != nil {
+		store.Close()
+
+		if newKeyErr := checkEncryptionKey(flags, newKey, fileService); newKeyErr != nil {
+			log.Fatal().Err(err).AnErr("new_key_error", newKeyErr).Msg("failed rotating the encryption key: the database cannot be opened with the current key nor with the new one")
+		}
+
+		log.Info().Msg("the database is encrypted with the new key already")
+
+		return newKey
+	}
+	defer store.Close()
+
+	if _, err := store.RotateEncryptionKey(newKey); err != nil {
+		log.Fatal().Err(err).Msg("failed rotating the encryption key")
+	}
+
+	log.Info().Str("secret", *flags.NewSecretKeyName).Msg("encryption key rotated, use this secret with --secret-key-name from now on")
+
+	return newKey
+}
+
+// checkEncryptionKey returns an error when the database cannot be opened with the encryption key
+func checkEncryptionKey(flags *portainer.CLIFlags, key []byte, fileService portainer.FileService) error {
+	connection, err := database.NewDatabase(*flags.DatabaseType, *flags.Data, key, false)
+	if err != nil {
+		return err
+	}
+
+	store := datastore.NewStore(flags, fileService, connection)
+	defer store.Close()
+
+	_, err = store.Open()
+
+	return err
+}
+
 // checkDBSchemaServerVersionMatch checks if the server version matches the db scehma version
 func checkDBSchemaServerVersionMatch(dbStore dataservices.DataStore, serverVersion string, serverEdition int) bool {
 	v, err := dbStore.Version().Version()
@@ -383,6 +467,18 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 
 	apiKeyService := initAPIKeyService(dataStore)
 
+	secretKey, err := fileService.LoadSecretKey()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed loading the secret key")
+	}
+
+	notificationService, err := notifications.NewService(shutdownCtx, dataStore, secretKey)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed initializing the notification service")
+	}
+
+	notifications.SetDefault(notificationService)
+
 	settings, err := dataStore.Settings().Settings()
 	if err != nil {
 		log.Fatal().Err(err).Msg("")
@@ -397,7 +493,12 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 
 	oauthService := oauth.NewService()
 
-	gitService := git.NewService(shutdownCtx)
+	gitCredentialService, err := gitcredentials.NewService(dataStore, secretKey)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed initializing the Git credential service")
+	}
+
+	gitService := git.NewService(shutdownCtx, gitCredentialService)
 
 	// Setting insecureSkipVerify to true to preserve the old behaviour.
 	openAMTService := openamt.NewService(true)
@@ -424,6 +525,14 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 
 	reverseTunnelService := chisel.NewService(dataStore, shutdownCtx, fileService)
 
+	if err := metrics.RegisterTunnelCollector(reverseTunnelService); err != nil {
+		log.Fatal().Err(err).Msg("failed registering the tunnel metrics")
+	}
+
+	if err := metrics.RegisterDatabaseCollector(dataStore.Connection()); err != nil {
+		log.Fatal().Err(err).Msg("failed registering the database metrics")
+	}
+
 	dockerClientFactory := dockerclient.NewClientFactory(signatureService, reverseTunnelService)
 
 	kubernetesClientFactory, err := kubecli.NewClientFactory(signatureService, reverseTunnelService, dataStore, instanceID, *flags.AddrHTTPS, settings.UserSessionTimeout)
@@ -442,16 +551,11 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 
 	reverseTunnelService.ProxyManager = proxyManager
 
-	dockerConfigPath := fileService.GetDockerConfigPath()
-
 	composeDeployer := compose.NewComposeDeployer()
 
 	composeStackManager := exec.NewComposeStackManager(composeDeployer, proxyManager, dataStore)
 
-	swarmStackManager, err := exec.NewSwarmStackManager(*flags.Assets, dockerConfigPath, signatureService, fileService, reverseTunnelService, dataStore)
-	if err != nil {
-		log.Fatal().Err(err).Msg("failed initializing swarm stack manager")
-	}
+	swarmStackManager := exec.NewSwarmStackManager(swarm.NewSwarmDeployer(), dockerClientFactory, dataStore)
 
 	kubernetesDeployer := initKubernetesDeployer(kubernetesTokenCacheManager, kubernetesClientFactory, dataStore, reverseTunnelService, signatureService, proxyManager)
 
@@ -528,9 +632,14 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 	}
 
 	scheduler := scheduler.NewScheduler(shutdownCtx)
-	stackDeployer := deployments.NewStackDeployer(swarmStackManager, composeStackManager, kubernetesDeployer, dockerClientFactory, dataStore)
+	stackDeployer := deployments.NewStackDeployer(swarmStackManager, composeStackManager, kubernetesDeployer, dockerClientFactory, dataStore, gitService)
 	deployments.StartStackSchedules(scheduler, stackDeployer, dataStore, gitService)
 
+	auditService := audit.NewService(shutdownCtx, dataStore)
+	scheduler.StartJobEvery(time.Hour, auditService.Prune)
+	scheduler.StartJobEvery(time.Hour, notificationService.Prune)
+	scheduler.StartJobEvery(30*time.Second, func() error { return edgeStacksService.AdvanceRollouts(fileService) })
+
 	sslDBSettings, err := dataStore.SSLSettings().Settings()
 	if err != nil {
 		log.Fatal().Msg("failed to fetch SSL settings from DB")
@@ -570,6 +679,7 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 	}
 
 	return &http.Server{
+		AuditService:                auditService,
 		AuthorizationService:        authorizationService,
 		ReverseTunnelService:        reverseTunnelService,
 		Status:                      applicationStatus,
@@ -610,6 +720,10 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 		PlatformService:             platformService,
 		PullLimitCheckDisabled:      *flags.PullLimitCheckDisabled,
 		TrustedOrigins:              trustedOrigins,
+		MetricsToken:                *flags.MetricsToken,
+		NotificationService:         notificationService,
+		GitCredentialService:        gitCredentialService,
+		SecretKey:                   secretKey,
 	}
 }
 
@@ -617,6 +731,14 @@ func main() {
 	logs.ConfigureLogger()
 	logs.SetLoggingMode("PRETTY")
 
+	if len(os.Args) > 1 && os.Args[1] == cli.AdminCommand {
+		if err := runAdmin(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal().Err(err).Msg("failed running the admin command")
+		}
+
+		return
+	}
+
 	flags := initCLI()
 
 	logs.SetLoggingLevel(*flags.LogLevel)
@@ -47,6 +47,7 @@ type Connection interface {
 	IsEncryptedStore() bool
 	NeedsEncryptionMigration() (bool, error)
 	SetEncrypted(encrypted bool)
+	RotateEncryptionKey(newKey []byte) error
 
 	BackupMetadata() (map[string]any, error)
 	RestoreMetadata(s map[string]any) error
@@ -0,0 +1,76 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// SecretCipher encrypts the secrets stored in the database, such as passwords and tokens
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSecretCipher creates a cipher whose key is derived from secretKey, info separates the keys of the
+// different kinds of secrets encrypted with the same secretKey
+func NewSecretCipher(secretKey []byte, info string) (*SecretCipher, error) {
+	if len(secretKey) == 0 {
+		return nil, errors.New("missing secret key to derive the encryption key from")
+	}
+
+	key, err := hkdf.Key(sha256.New, secretKey, nil, info, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretCipher{aead: aead}, nil
+}
+
+// Encrypt returns the base64 encoded AES-GCM encryption of plaintext, an empty plaintext is left empty
+func (c *SecretCipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+// Decrypt returns the plaintext of a secret encrypted with Encrypt
+func (c *SecretCipher) Decrypt(ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	nonceSize := c.aead.NonceSize()
+	if err != nil || len(sealed) < nonceSize {
+		return "", errors.New("invalid encrypted secret")
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt the secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
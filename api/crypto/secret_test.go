@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretCipher(t *testing.T) {
+	c, err := NewSecretCipher([]byte("secret key"), "test")
+	require.NoError(t, err)
+
+	encrypted, err := c.Encrypt("password")
+	require.NoError(t, err)
+	require.NotContains(t, encrypted, "password")
+
+	decrypted, err := c.Decrypt(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, "password", decrypted)
+
+	empty, err := c.Encrypt("")
+	require.NoError(t, err)
+	require.Empty(t, empty)
+
+	// Another info derives another key
+	other, err := NewSecretCipher([]byte("secret key"), "other")
+	require.NoError(t, err)
+
+	_, err = other.Decrypt(encrypted)
+	require.Error(t, err)
+
+	_, err = c.Decrypt("not encrypted")
+	require.Error(t, err)
+
+	_, err = NewSecretCipher(nil, "test")
+	require.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package customtemplates
+
+import (
+	"strconv"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/slicesx"
+)
+
+// UserCanAccess returns whether the user of the security context can use the custom template, either because they
+// can edit it or through its resource control. The resource control is attached to the template
+func UserCanAccess(tx dataservices.DataStoreTx, customTemplate *portainer.CustomTemplate, securityContext *security.RestrictedRequestContext) (bool, error) {
+	resourceControl, err := tx.ResourceControl().ResourceControlByResourceIDAndType(strconv.Itoa(int(customTemplate.ID)), portainer.CustomTemplateResourceControl)
+	if err != nil {
+		return false, err
+	}
+
+	if securityContext.IsAdmin || customTemplate.CreatedByUserID == securityContext.UserID {
+		customTemplate.ResourceControl = resourceControl
+
+		return true, nil
+	}
+
+	if resourceControl == nil {
+		return false, nil
+	}
+
+	customTemplate.ResourceControl = resourceControl
+
+	teamIDs := slicesx.Map(securityContext.UserMemberships, func(m portainer.TeamMembership) portainer.TeamID {
+		return m.TeamID
+	})
+
+	return authorization.UserCanAccessResource(securityContext.UserID, teamIDs, resourceControl), nil
+}
+
+// FileContent returns the content of the entry point of the custom template
+func FileContent(fileService portainer.FileService, customTemplate *portainer.CustomTemplate) ([]byte, error) {
+	entryPath := customTemplate.EntryPoint
+	if customTemplate.GitConfig != nil {
+		entryPath = customTemplate.GitConfig.ConfigFilePath
+	}
+
+	return fileService.GetFileContent(customTemplate.ProjectPath, entryPath)
+}
@@ -0,0 +1,158 @@
+package customtemplates
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/cbroglie/mustache"
+)
+
+// ErrInvalidVariables is returned when the values provided to render a template don't match its variable definitions
+var ErrInvalidVariables = errors.New("invalid template variables")
+
+// ValidateVariableDefinitions checks the variable definitions of a custom template
+func ValidateVariableDefinitions(definitions []portainer.CustomTemplateVariableDefinition) error {
+	for i, definition := range definitions {
+		if definition.Name == "" {
+			return errors.New("variable name is required")
+		}
+
+		if definition.Label == "" {
+			return errors.New("variable label is required")
+		}
+
+		if slices.ContainsFunc(definitions[:i], func(d portainer.CustomTemplateVariableDefinition) bool {
+			return d.Name == definition.Name
+		}) {
+			return fmt.Errorf("variable %s is defined more than once", definition.Name)
+		}
+
+		switch definition.Type {
+		case "", portainer.CustomTemplateVariableString, portainer.CustomTemplateVariableNumber, portainer.CustomTemplateVariableBoolean:
+		default:
+			return fmt.Errorf("invalid type %q for variable %s. Valid values are: string, number or boolean", definition.Type, definition.Name)
+		}
+
+		if definition.Pattern != "" {
+			if _, err := regexp.Compile(definition.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern for variable %s: %w", definition.Name, err)
+			}
+		}
+
+		if definition.DefaultValue != "" {
+			if err := validateValue(definition, definition.DefaultValue); err != nil {
+				return fmt.Errorf("invalid default value for variable %s: %w", definition.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResolveVariables validates the values provided for the variables of a template and applies the default values to
+// the variables without a value, an empty value is kept. It returns the value of every defined variable, in the
+// order of the definitions
+func ResolveVariables(definitions []portainer.CustomTemplateVariableDefinition, values []portainer.Pair) ([]portainer.Pair, error) {
+	provided := make(map[string]string, len(values))
+	for _, value := range values {
+		if !slices.ContainsFunc(definitions, func(d portainer.CustomTemplateVariableDefinition) bool { return d.Name == value.Name }) {
+			return nil, fmt.Errorf("%w: variable %s is not defined by the template", ErrInvalidVariables, value.Name)
+		}
+
+		provided[value.Name] = value.Value
+	}
+
+	resolved := make([]portainer.Pair, 0, len(definitions))
+	for _, definition := range definitions {
+		value, ok := provided[definition.Name]
+		if !ok {
+			value = definition.DefaultValue
+		}
+
+		if value == "" {
+			if definition.Required {
+				return nil, fmt.Errorf("%w: a value is required for variable %s", ErrInvalidVariables, definition.Name)
+			}
+		} else if err := validateValue(definition, value); err != nil {
+			return nil, fmt.Errorf("%w: invalid value for variable %s: %w", ErrInvalidVariables, definition.Name, err)
+		}
+
+		resolved = append(resolved, portainer.Pair{Name: definition.Name, Value: value})
+	}
+
+	return resolved, nil
+}
+
+// Render substitutes the variables of the template content. The values are not HTML escaped, and the variables
+// without value are replaced by an empty string
+func Render(content string, variables []portainer.Pair) (string, error) {
+	tmpl, err := mustache.ParseStringRaw(content, true)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse the template: %w", err)
+	}
+
+	values := make(map[string]string, len(variables))
+	for _, variable := range variables {
+		values[variable.Name] = variable.Value
+	}
+
+	return tmpl.Render(values)
+}
+
+// VariableNames returns the names of the variables, sections and partials referenced by the template content
+func VariableNames(content string) ([]string, error) {
+	tmpl, err := mustache.ParseStringRaw(content, true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the template: %w", err)
+	}
+
+	var names []string
+
+	var collect func(tags []mustache.Tag)
+	collect = func(tags []mustache.Tag) {
+		for _, tag := range tags {
+			names = append(names, tag.Name())
+
+			if tag.Type() == mustache.Section || tag.Type() == mustache.InvertedSection {
+				collect(tag.Tags())
+			}
+		}
+	}
+
+	collect(tmpl.Tags())
+
+	return names, nil
+}
+
+func validateValue(definition portainer.CustomTemplateVariableDefinition, value string) error {
+	switch definition.Type {
+	case portainer.CustomTemplateVariableNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.New("the value must be a number")
+		}
+	case portainer.CustomTemplateVariableBoolean:
+		if value != "true" && value != "false" {
+			return errors.New("the value must be true or false")
+		}
+	}
+
+	if definition.Pattern == "" {
+		return nil
+	}
+
+	pattern, err := regexp.Compile("^(?:" + definition.Pattern + ")$")
+	if err != nil {
+		return err
+	}
+
+	if !pattern.MatchString(value) {
+		return fmt.Errorf("the value must match the pattern %s", definition.Pattern)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package customtemplates
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateVariableDefinitions(t *testing.T) {
+	require.NoError(t, ValidateVariableDefinitions([]portainer.CustomTemplateVariableDefinition{
+		{Name: "PORT", Label: "Port", Type: portainer.CustomTemplateVariableNumber, DefaultValue: "80"},
+		{Name: "DEBUG", Label: "Debug", Type: portainer.CustomTemplateVariableBoolean},
+		{Name: "ENV", Label: "Environment", Pattern: "dev|prod", Required: true},
+	}))
+
+	for name, definition := range map[string]portainer.CustomTemplateVariableDefinition{
+		"missing name":          {Label: "Port"},
+		"missing label":         {Name: "PORT"},
+		"invalid type":          {Name: "PORT", Label: "Port", Type: "integer"},
+		"invalid pattern":       {Name: "PORT", Label: "Port", Pattern: "[0-9"},
+		"invalid default value": {Name: "PORT", Label: "Port", Type: portainer.CustomTemplateVariableNumber, DefaultValue: "http"},
+	} {
+		require.Error(t, ValidateVariableDefinitions([]portainer.CustomTemplateVariableDefinition{definition}), name)
+	}
+
+	require.Error(t, ValidateVariableDefinitions([]portainer.CustomTemplateVariableDefinition{
+		{Name: "PORT", Label: "Port"},
+		{Name: "PORT", Label: "Other port"},
+	}), "duplicate names")
+}
+
+func TestResolveVariables(t *testing.T) {
+	definitions := []portainer.CustomTemplateVariableDefinition{
+		{Name: "DEBUG", Label: "Debug", Type: portainer.CustomTemplateVariableBoolean, DefaultValue: "false"},
+		{Name: "REPLICAS", Label: "Replicas", Type: portainer.CustomTemplateVariableNumber, Required: true},
+		{Name: "NAME", Label: "Name", Pattern: "[a-z]+"},
+	}
+
+	resolved, err := ResolveVariables(definitions, []portainer.Pair{{Name: "REPLICAS", Value: "2"}})
+	require.NoError(t, err)
+	require.Equal(t, []portainer.Pair{{Name: "DEBUG", Value: "false"}, {Name: "REPLICAS", Value: "2"}, {Name: "NAME", Value: ""}}, resolved)
+
+	// An empty value is not replaced by the default value
+	resolved, err = ResolveVariables(definitions, []portainer.Pair{{Name: "REPLICAS", Value: "2"}, {Name: "DEBUG", Value: ""}})
+	require.NoError(t, err)
+	require.Equal(t, portainer.Pair{Name: "DEBUG", Value: ""}, resolved[0])
+
+	for name, values := range map[string][]portainer.Pair{
+		"missing required value": {{Name: "DEBUG", Value: "true"}},
+		"empty required value":   {{Name: "REPLICAS", Value: ""}},
+		"invalid boolean":        {{Name: "REPLICAS", Value: "2"}, {Name: "DEBUG", Value: "yes"}},
+		"partial pattern match":  {{Name: "REPLICAS", Value: "2"}, {Name: "NAME", Value: "web1"}},
+		"unknown variable":       {{Name: "REPLICAS", Value: "2"}, {Name: "IMAGE", Value: "nginx"}},
+	} {
+		_, err := ResolveVariables(definitions, values)
+		require.ErrorIs(t, err, ErrInvalidVariables, name)
+	}
+}
+
+func TestRender(t *testing.T) {
+	rendered, err := Render("command: {{ CMD }}\nname: {{NAME}}\nlabel: {{ MISSING }}", []portainer.Pair{
+		{Name: "CMD", Value: `echo "<ok>" && exit`},
+		{Name: "NAME", Value: "web"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "command: echo \"<ok>\" && exit\nname: web\nlabel: ", rendered)
+
+	_, err = Render("name: {{ NAME", nil)
+	require.Error(t, err)
+}
+
+func TestVariableNames(t *testing.T) {
+	names, err := VariableNames("name: {{ NAME }}\n{{#DEBUG}}level: {{ LEVEL }}{{/DEBUG}}\nplain: text")
+	require.NoError(t, err)
+	require.Equal(t, []string{"NAME", "DEBUG", "LEVEL"}, names)
+
+	_, err = VariableNames("name: {{ NAME")
+	require.Error(t, err)
+}
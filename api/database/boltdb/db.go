@@ -29,6 +29,7 @@ const (
 var (
 	ErrHaveEncryptedAndUnencrypted = errors.New("Portainer has detected both an encrypted and un-encrypted database and cannot start.  Only one database should exist")
 	ErrHaveEncryptedWithNoKey      = errors.New("The portainer database is encrypted, but no secret was loaded")
+	ErrRotateUnencryptedDatabase   = errors.New("the encryption key of an unencrypted database cannot be rotated")
 )
 
 type DbConnection struct {
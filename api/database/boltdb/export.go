@@ -73,6 +73,10 @@ func (c *DbConnection) ExportJSON(databasePath string, metadata bool) ([]byte, e
 				}
 
 				if bucketName == "version" {
+					if raw, err := DecryptObject(v, c.getEncryptionKey()); err == nil {
+						v = raw
+					}
+
 					version[string(k)] = string(v)
 				} else {
 					list = append(list, obj)
@@ -13,6 +13,17 @@ var errEncryptedStringTooShort = errors.New("encrypted string too short")
 
 // MarshalObject encodes an object to binary format
 func (connection *DbConnection) MarshalObject(object any) ([]byte, error) {
+	return MarshalObject(object, connection.getEncryptionKey())
+}
+
+// UnmarshalObject decodes an object from binary data
+func (connection *DbConnection) UnmarshalObject(data []byte, object any) error {
+	return UnmarshalObject(data, object, connection.getEncryptionKey())
+}
+
+// MarshalObject encodes an object to the binary format of the database, encrypted when an encryption key is provided.
+// It is shared by the database implementations so that their objects can be copied as is
+func MarshalObject(object any, encryptionKey []byte) ([]byte, error) {
 	buf := &bytes.Buffer{}
 
 	// Special case for the VERSION bucket. Here we're not using json
@@ -28,18 +39,18 @@ func (connection *DbConnection) MarshalObject(object any) ([]byte, error) {
 		}
 	}
 
-	if connection.getEncryptionKey() == nil {
+	if encryptionKey == nil {
 		return buf.Bytes(), nil
 	}
 
-	return encrypt(buf.Bytes(), connection.getEncryptionKey())
+	return encrypt(buf.Bytes(), encryptionKey)
 }
 
-// UnmarshalObject decodes an object from binary data
-func (connection *DbConnection) UnmarshalObject(data []byte, object any) error {
+// UnmarshalObject decodes an object from the binary format of the database
+func UnmarshalObject(data []byte, object any, encryptionKey []byte) error {
 	var err error
-	if connection.getEncryptionKey() != nil {
-		data, err = decrypt(data, connection.getEncryptionKey())
+	if encryptionKey != nil {
+		data, err = decrypt(data, encryptionKey)
 		if err != nil {
 			return errors.Wrap(err, "Failed decrypting object")
 		}
@@ -59,6 +70,29 @@ func (connection *DbConnection) UnmarshalObject(data []byte, object any) error {
 	return err
 }
 
+// DecryptObject returns the decrypted binary data of an object
+func DecryptObject(data []byte, encryptionKey []byte) ([]byte, error) {
+	if encryptionKey == nil {
+		return data, nil
+	}
+
+	value, err := decrypt(data, encryptionKey)
+	if err != nil {
+		return value, errors.Wrap(err, "Failed decrypting object")
+	}
+
+	return value, nil
+}
+
+// EncryptObject returns the binary data of an object encrypted with the encryption key
+func EncryptObject(data []byte, encryptionKey []byte) ([]byte, error) {
+	if encryptionKey == nil {
+		return data, nil
+	}
+
+	return encrypt(data, encryptionKey)
+}
+
 // mmm, don't have a KMS .... aes GCM seems the most likely from
 // https://gist.github.com/atoponce/07d8d4c833873be2f68c34f9afc5a78a#symmetric-encryption
 
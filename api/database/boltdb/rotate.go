@@ -0,0 +1,86 @@
+package boltdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// RotateEncryptionKey re-encrypts every object of the database with a new encryption key within a single transaction.
+// The objects are read back with the new key before the transaction is committed, the database is left untouched when
+// any of them does not match
+func (connection *DbConnection) RotateEncryptionKey(newKey []byte) error {
+	oldKey := connection.getEncryptionKey()
+	if oldKey == nil || newKey == nil {
+		return ErrRotateUnencryptedDatabase
+	}
+
+	if err := connection.DB.Update(func(tx *bolt.Tx) error {
+		var bucketNames [][]byte
+		if err := tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			bucketNames = append(bucketNames, bytes.Clone(name))
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, name := range bucketNames {
+			if err := rotateBucket(tx.Bucket(name), oldKey, newKey); err != nil {
+				return fmt.Errorf("unable to rotate the encryption key of the bucket %s: %w", name, err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	connection.EncryptionKey = newKey
+
+	return nil
+}
+
+func rotateBucket(bucket *bolt.Bucket, oldKey, newKey []byte) error {
+	plaintexts := make(map[string][]byte)
+
+	// the values are collected first as the bucket cannot be modified while it is iterated over
+	if err := bucket.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return nil
+		}
+
+		plaintext, err := decrypt(v, oldKey)
+		if err != nil {
+			return fmt.Errorf("unable to decrypt the object %s: %w", keyToString(k), err)
+		}
+
+		plaintexts[string(k)] = plaintext
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for k, plaintext := range plaintexts {
+		data, err := encrypt(plaintext, newKey)
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put([]byte(k), data); err != nil {
+			return err
+		}
+	}
+
+	for k, plaintext := range plaintexts {
+		data, err := decrypt(bucket.Get([]byte(k)), newKey)
+		if err != nil || !bytes.Equal(data, plaintext) {
+			return errors.Join(fmt.Errorf("the object %s does not match once re-encrypted", keyToString([]byte(k))), err)
+		}
+	}
+
+	return nil
+}
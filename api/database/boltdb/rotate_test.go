@@ -0,0 +1,58 @@
+package boltdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateEncryptionKey(t *testing.T) {
+	oldKey := []byte("apassphrasewhichneedstobe32bytes")
+	newKey := []byte("anotherpassphrasethatis32bytes!!")
+	dir := t.TempDir()
+
+	conn := &DbConnection{Path: dir, EncryptionKey: oldKey}
+	_, err := conn.NeedsEncryptionMigration()
+	require.NoError(t, err)
+	require.NoError(t, conn.Open())
+
+	require.NoError(t, conn.SetServiceName(testBucketName))
+	require.NoError(t, conn.CreateObjectWithId(testBucketName, testId, testStruct{Key: "key", Value: "value"}))
+	require.NoError(t, conn.SetServiceName("version"))
+	require.NoError(t, conn.CreateObjectWithStringId("version", []byte("VERSION"), `{"SchemaVersion":"2.0.0"}`))
+
+	require.NoError(t, conn.RotateEncryptionKey(newKey))
+	require.Equal(t, newKey, conn.EncryptionKey)
+	require.NoError(t, conn.Close())
+
+	// the previous key cannot read the database anymore
+	conn = &DbConnection{Path: dir, EncryptionKey: oldKey}
+	_, err = conn.NeedsEncryptionMigration()
+	require.NoError(t, err)
+	require.NoError(t, conn.Open())
+
+	obj := testStruct{}
+	require.Error(t, conn.GetObject(testBucketName, conn.ConvertToKey(testId), &obj))
+	require.NoError(t, conn.Close())
+
+	conn = &DbConnection{Path: dir, EncryptionKey: newKey}
+	_, err = conn.NeedsEncryptionMigration()
+	require.NoError(t, err)
+	require.NoError(t, conn.Open())
+	defer conn.Close()
+
+	require.NoError(t, conn.GetObject(testBucketName, conn.ConvertToKey(testId), &obj))
+	require.Equal(t, testStruct{Key: "key", Value: "value"}, obj)
+
+	var version string
+	require.NoError(t, conn.GetObject("version", []byte("VERSION"), &version))
+	require.JSONEq(t, `{"SchemaVersion":"2.0.0"}`, version)
+}
+
+func TestRotateEncryptionKey_unencrypted(t *testing.T) {
+	conn := &DbConnection{Path: t.TempDir()}
+	require.NoError(t, conn.Open())
+	defer conn.Close()
+
+	require.ErrorIs(t, conn.RotateEncryptionKey([]byte("apassphrasewhichneedstobe32bytes")), ErrRotateUnencryptedDatabase)
+}
@@ -1,21 +1,111 @@
 package database
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/database/boltdb"
+	"github.com/portainer/portainer/api/database/sqlite"
+
+	"github.com/rs/zerolog/log"
 )
 
 // NewDatabase should use config options to return a connection to the requested database
 func NewDatabase(storeType, storePath string, encryptionKey []byte, compact bool) (connection portainer.Connection, err error) {
-	if storeType == "boltdb" {
+	switch storeType {
+	case "boltdb":
 		return &boltdb.DbConnection{
 			Path:          storePath,
 			EncryptionKey: encryptionKey,
 			Compact:       compact,
 		}, nil
+	case "sqlite":
+		return &sqlite.DbConnection{
+			Path:          storePath,
+			EncryptionKey: encryptionKey,
+			Compact:       compact,
+		}, nil
 	}
 
 	return nil, fmt.Errorf("Unknown storage database: %s", storeType)
 }
+
+// HasUnmigratedBoltDB returns true when the store path holds a BoltDB database but no SQLite database
+func HasUnmigratedBoltDB(storePath string) bool {
+	exists := func(fileNames ...string) bool {
+		for _, fileName := range fileNames {
+			if _, err := os.Stat(path.Join(storePath, fileName)); err == nil {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return exists(boltdb.DatabaseFileName, boltdb.EncryptedDatabaseFileName) &&
+		!exists(sqlite.DatabaseFileName, sqlite.EncryptedDatabaseFileName)
+}
+
+// MigrateToSQLite copies the BoltDB database of the store path into a new SQLite database and validates the copy.
+// The BoltDB database is left untouched so that it can still be used if the SQLite database is discarded
+func MigrateToSQLite(storePath string, encryptionKey []byte) (err error) {
+	source := &boltdb.DbConnection{Path: storePath, EncryptionKey: encryptionKey}
+	if needsEncryption, err := source.NeedsEncryptionMigration(); err != nil {
+		return err
+	} else if needsEncryption {
+		return errors.New("the BoltDB database is not encrypted yet, start Portainer once with the encryption key before migrating it")
+	}
+
+	if _, err := os.Stat(source.GetDatabaseFilePath()); err != nil {
+		return fmt.Errorf("unable to find the BoltDB database: %w", err)
+	}
+
+	target := &sqlite.DbConnection{Path: storePath, EncryptionKey: encryptionKey}
+	if _, err := target.NeedsEncryptionMigration(); err != nil {
+		return err
+	}
+
+	targetPath := target.GetDatabaseFilePath()
+	if _, err := os.Stat(targetPath); err == nil {
+		return fmt.Errorf("the SQLite database %s already exists", targetPath)
+	}
+
+	if err := source.Open(); err != nil {
+		return fmt.Errorf("unable to open the BoltDB database: %w", err)
+	}
+	defer source.Close()
+
+	if err := target.Open(); err != nil {
+		return fmt.Errorf("unable to create the SQLite database: %w", err)
+	}
+
+	defer func() {
+		err = errors.Join(err, target.Close())
+		if err == nil {
+			return
+		}
+
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			if removeErr := os.Remove(targetPath + suffix); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+				log.Warn().Err(removeErr).Str("path", targetPath+suffix).Msg("unable to remove the incomplete SQLite database")
+			}
+		}
+	}()
+
+	log.Info().Str("from", source.GetDatabaseFilePath()).Str("to", targetPath).Msg("copying the database")
+
+	if err := target.CopyFromBoltDB(source.DB); err != nil {
+		return fmt.Errorf("unable to copy the database: %w", err)
+	}
+
+	if err := target.ValidateCopy(source.DB); err != nil {
+		return fmt.Errorf("the copy of the database is invalid: %w", err)
+	}
+
+	log.Info().Str("path", path.Join(storePath, target.GetDatabaseFileName())).Msg("database copied and validated")
+
+	return nil
+}
@@ -1,9 +1,13 @@
 package database
 
 import (
+	"os"
+	"path"
 	"testing"
 
+	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/database/boltdb"
+	"github.com/portainer/portainer/api/database/sqlite"
 	"github.com/portainer/portainer/api/filesystem"
 
 	"github.com/stretchr/testify/require"
@@ -18,7 +22,78 @@ func TestNewDatabase(t *testing.T) {
 	_, ok := connection.(*boltdb.DbConnection)
 	require.True(t, ok)
 
+	connection, err = NewDatabase("sqlite", dbPath, nil, false)
+	require.NoError(t, err)
+
+	_, ok = connection.(*sqlite.DbConnection)
+	require.True(t, ok)
+
 	connection, err = NewDatabase("unknown", dbPath, nil, false)
 	require.Error(t, err)
 	require.Nil(t, connection)
 }
+
+type testStruct struct {
+	Name string
+}
+
+func TestMigrateToSQLite(t *testing.T) {
+	for _, encryptionKey := range [][]byte{nil, []byte("apassphrasewhichneedstobe32bytes")} {
+		storePath := t.TempDir()
+
+		source := &boltdb.DbConnection{Path: storePath, EncryptionKey: encryptionKey}
+		_, err := source.NeedsEncryptionMigration()
+		require.NoError(t, err)
+		require.NoError(t, source.Open())
+
+		require.NoError(t, source.SetServiceName("tests"))
+		for _, name := range []string{"first", "second"} {
+			require.NoError(t, source.CreateObject("tests", func(id uint64) (int, any) {
+				return int(id), testStruct{Name: name}
+			}))
+		}
+		require.NoError(t, source.Close())
+
+		require.True(t, HasUnmigratedBoltDB(storePath))
+		require.NoError(t, MigrateToSQLite(storePath, encryptionKey))
+		require.False(t, HasUnmigratedBoltDB(storePath))
+
+		// the BoltDB database is kept
+		_, err = os.Stat(source.GetDatabaseFilePath())
+		require.NoError(t, err)
+
+		target := &sqlite.DbConnection{Path: storePath, EncryptionKey: encryptionKey}
+		_, err = target.NeedsEncryptionMigration()
+		require.NoError(t, err)
+		require.NoError(t, target.Open())
+
+		var names []string
+		err = target.GetAll("tests", &testStruct{}, func(o any) (any, error) {
+			names = append(names, o.(*testStruct).Name)
+
+			return &testStruct{}, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"first", "second"}, names)
+
+		err = target.UpdateTx(func(tx portainer.Transaction) error {
+			require.Equal(t, 3, tx.GetNextIdentifier("tests"))
+
+			return nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, target.Close())
+
+		// an existing SQLite database is never overwritten
+		require.Error(t, MigrateToSQLite(storePath, encryptionKey))
+	}
+}
+
+func TestMigrateToSQLite_noBoltDB(t *testing.T) {
+	storePath := t.TempDir()
+
+	require.Error(t, MigrateToSQLite(storePath, nil))
+
+	_, err := os.Stat(path.Join(storePath, sqlite.DatabaseFileName))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,483 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/database/boltdb"
+
+	"github.com/rs/zerolog/log"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	DatabaseFileName          = "portainer.sqlite"
+	EncryptedDatabaseFileName = "portainer.esqlite"
+
+	busyTimeout = 5000
+)
+
+var (
+	ErrHaveEncryptedAndUnencrypted = errors.New("Portainer has detected both an encrypted and un-encrypted SQLite database and cannot start.  Only one database should exist")
+	ErrHaveEncryptedWithNoKey      = errors.New("The portainer SQLite database is encrypted, but no secret was loaded")
+	ErrRotateUnencryptedDatabase   = errors.New("the encryption key of an unencrypted database cannot be rotated")
+	errTxNotWritable               = errors.New("tx not writable")
+)
+
+// The buckets of BoltDB are stored as rows of the buckets table, holding their sequence, and their objects as rows of
+// the objects table. Keys are stored as blobs so that they are sorted like in BoltDB: integer identifiers are 8-byte
+// big endian blobs. Values are stored as JSON text when the database is not encrypted, so that they can be inspected
+// with the JSON functions of SQLite.
+const schema = `
+CREATE TABLE IF NOT EXISTS buckets (
+	name TEXT NOT NULL PRIMARY KEY,
+	sequence INTEGER NOT NULL DEFAULT 0
+) WITHOUT ROWID;
+
+CREATE TABLE IF NOT EXISTS objects (
+	bucket TEXT NOT NULL,
+	key BLOB NOT NULL,
+	value BLOB NOT NULL,
+	PRIMARY KEY (bucket, key)
+) WITHOUT ROWID;
+`
+
+// DbConnection is a connection to a SQLite database storing the buckets of Portainer
+type DbConnection struct {
+	Path          string
+	EncryptionKey []byte
+	isEncrypted   bool
+	// Compact rebuilds the database file on startup to reclaim the space of the deleted objects
+	Compact bool
+
+	db *sql.DB
+	// writeMu serializes the write transactions, SQLite allows a single writer at a time
+	writeMu sync.Mutex
+}
+
+// GetDatabaseFileName get the database filename
+func (connection *DbConnection) GetDatabaseFileName() string {
+	if connection.IsEncryptedStore() {
+		return EncryptedDatabaseFileName
+	}
+
+	return DatabaseFileName
+}
+
+// GetDatabaseFilePath get the path + filename for the database file
+func (connection *DbConnection) GetDatabaseFilePath() string {
+	return path.Join(connection.Path, connection.GetDatabaseFileName())
+}
+
+// GetStorePath get the filename and path for the database file
+func (connection *DbConnection) GetStorePath() string {
+	return connection.Path
+}
+
+func (connection *DbConnection) GetDatabaseFileSize() (int64, error) {
+	file, err := os.Stat(connection.GetDatabaseFilePath())
+	if err != nil {
+		return 0, fmt.Errorf("Failed to stat database file path: %s err: %w", connection.GetDatabaseFilePath(), err)
+	}
+
+	return file.Size(), nil
+}
+
+func (connection *DbConnection) SetEncrypted(flag bool) {
+	connection.isEncrypted = flag
+}
+
+// Return true if the database is encrypted
+func (connection *DbConnection) IsEncryptedStore() bool {
+	return connection.getEncryptionKey() != nil
+}
+
+// NeedsEncryptionMigration returns true if database encryption is enabled and
+// we have an un-encrypted DB that requires migration to an encrypted DB.
+// It follows the same rules as the BoltDB database
+func (connection *DbConnection) NeedsEncryptionMigration() (bool, error) {
+	if connection.EncryptionKey != nil {
+		connection.SetEncrypted(true)
+	}
+
+	_, err := os.Stat(path.Join(connection.Path, DatabaseFileName))
+	haveDbFile := err == nil
+
+	_, err = os.Stat(path.Join(connection.Path, EncryptedDatabaseFileName))
+	haveEdbFile := err == nil
+
+	switch {
+	case haveDbFile && haveEdbFile:
+		return false, ErrHaveEncryptedAndUnencrypted
+	case haveDbFile && connection.EncryptionKey != nil:
+		return true, nil
+	case haveEdbFile && connection.EncryptionKey == nil:
+		return false, ErrHaveEncryptedWithNoKey
+	}
+
+	return false, nil
+}
+
+// Open opens and initializes the SQLite database.
+func (connection *DbConnection) Open() error {
+	log.Info().Str("filename", connection.GetDatabaseFileName()).Msg("loading PortainerDB")
+
+	db, err := openDatabase(connection.GetDatabaseFilePath(), false)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		return errors.Join(fmt.Errorf("unable to create the database schema: %w", err), db.Close())
+	}
+
+	connection.db = db
+
+	if connection.Compact {
+		log.Info().Msg("compacting database")
+
+		if _, err := db.Exec("VACUUM"); err != nil {
+			log.Error().Err(err).Msg("failed to compact database")
+		} else {
+			log.Info().Msg("database compaction completed")
+		}
+	}
+
+	return nil
+}
+
+// openDatabase opens the SQLite database file. Write transactions take the write lock when they begin so that
+// concurrent transactions cannot fail when upgrading their lock
+func openDatabase(databasePath string, readOnly bool) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "busy_timeout("+strconv.Itoa(busyTimeout)+")")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_txlock", "immediate")
+
+	if readOnly {
+		params.Add("mode", "ro")
+	}
+
+	db, err := sql.Open("sqlite", "file:"+databasePath+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, errors.Join(err, db.Close())
+	}
+
+	return db, nil
+}
+
+// Close closes the SQLite database.
+// Safe to being called multiple times.
+func (connection *DbConnection) Close() error {
+	log.Info().Msg("closing PortainerDB")
+
+	if connection.db == nil {
+		return nil
+	}
+
+	err := connection.db.Close()
+	connection.db = nil
+
+	return err
+}
+
+// UpdateTx executes the given function inside a read-write transaction
+func (connection *DbConnection) UpdateTx(fn func(portainer.Transaction) error) error {
+	connection.writeMu.Lock()
+	defer connection.writeMu.Unlock()
+
+	return connection.runTx(fn, true)
+}
+
+// ViewTx executes the given function inside a read-only transaction
+func (connection *DbConnection) ViewTx(fn func(portainer.Transaction) error) error {
+	return connection.runTx(fn, false)
+}
+
+func (connection *DbConnection) runTx(fn func(portainer.Transaction) error, writable bool) error {
+	tx, err := connection.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: !writable})
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&DbTransaction{conn: connection, tx: tx, writable: writable}); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if !writable {
+		return tx.Rollback()
+	}
+
+	return tx.Commit()
+}
+
+// BackupTo backs up db to a provided writer.
+// It does hot backup and doesn't block other database reads
+func (connection *DbConnection) BackupTo(w io.Writer) error {
+	dir, err := os.MkdirTemp("", "portainer-sqlite-backup")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	backupPath := path.Join(dir, connection.GetDatabaseFileName())
+	if _, err := connection.db.Exec("VACUUM INTO ?", backupPath); err != nil {
+		return fmt.Errorf("unable to copy the database: %w", err)
+	}
+
+	f, err := os.Open(backupPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+
+	return err
+}
+
+func (connection *DbConnection) ExportRaw(filename string) error {
+	databasePath := connection.GetDatabaseFilePath()
+	if _, err := os.Stat(databasePath); err != nil {
+		return fmt.Errorf("stat on %s failed, error: %w", databasePath, err)
+	}
+
+	b, err := connection.ExportJSON(databasePath, true)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, b, 0600)
+}
+
+// ConvertToKey returns an 8-byte big endian representation of v, as BoltDB does
+func (connection *DbConnection) ConvertToKey(v int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+
+	return b
+}
+
+// keyToString Converts a key to a string value suitable for logging
+func keyToString(b []byte) string {
+	if len(b) != 8 {
+		return string(b)
+	}
+
+	v := binary.BigEndian.Uint64(b)
+	if v <= math.MaxInt32 {
+		return strconv.FormatUint(v, 10)
+	}
+
+	return string(b)
+}
+
+func (connection *DbConnection) getEncryptionKey() []byte {
+	if !connection.isEncrypted {
+		return nil
+	}
+
+	return connection.EncryptionKey
+}
+
+// MarshalObject encodes an object to binary format
+func (connection *DbConnection) MarshalObject(object any) ([]byte, error) {
+	return boltdb.MarshalObject(object, connection.getEncryptionKey())
+}
+
+// UnmarshalObject decodes an object from binary data
+func (connection *DbConnection) UnmarshalObject(data []byte, object any) error {
+	return boltdb.UnmarshalObject(data, object, connection.getEncryptionKey())
+}
+
+// storedValue returns the value stored in the objects table, JSON text when the database is not encrypted
+func (connection *DbConnection) storedValue(data []byte) any {
+	if connection.getEncryptionKey() == nil {
+		return string(data)
+	}
+
+	return data
+}
+
+// SetServiceName creates a bucket inside the database.
+func (connection *DbConnection) SetServiceName(bucketName string) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.SetServiceName(bucketName)
+	})
+}
+
+// GetObject is a generic function used to retrieve an unmarshalled object from a database.
+func (connection *DbConnection) GetObject(bucketName string, key []byte, object any) error {
+	return connection.ViewTx(func(tx portainer.Transaction) error {
+		return tx.GetObject(bucketName, key, object)
+	})
+}
+
+func (connection *DbConnection) GetRawBytes(bucketName string, key []byte) ([]byte, error) {
+	var value []byte
+
+	err := connection.ViewTx(func(tx portainer.Transaction) error {
+		var err error
+		value, err = tx.GetRawBytes(bucketName, key)
+
+		return err
+	})
+
+	return value, err
+}
+
+func (connection *DbConnection) KeyExists(bucketName string, key []byte) (bool, error) {
+	var exists bool
+
+	err := connection.ViewTx(func(tx portainer.Transaction) error {
+		var err error
+		exists, err = tx.KeyExists(bucketName, key)
+
+		return err
+	})
+
+	return exists, err
+}
+
+// UpdateObject is a generic function used to update an object inside a database.
+func (connection *DbConnection) UpdateObject(bucketName string, key []byte, object any) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.UpdateObject(bucketName, key, object)
+	})
+}
+
+// UpdateObjectFunc is a generic function used to update an object safely without race conditions.
+func (connection *DbConnection) UpdateObjectFunc(bucketName string, key []byte, object any, updateFn func()) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		if err := tx.GetObject(bucketName, key, object); err != nil {
+			return err
+		}
+
+		updateFn()
+
+		return tx.UpdateObject(bucketName, key, object)
+	})
+}
+
+// DeleteObject is a generic function used to delete an object inside a database.
+func (connection *DbConnection) DeleteObject(bucketName string, key []byte) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.DeleteObject(bucketName, key)
+	})
+}
+
+// DeleteAllObjects delete all objects where matching() returns (id, ok).
+func (connection *DbConnection) DeleteAllObjects(bucketName string, obj any, matching func(o any) (id int, ok bool)) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.DeleteAllObjects(bucketName, obj, matching)
+	})
+}
+
+// GetNextIdentifier is a generic function that returns the specified bucket identifier incremented by 1.
+func (connection *DbConnection) GetNextIdentifier(bucketName string) int {
+	var identifier int
+
+	_ = connection.UpdateTx(func(tx portainer.Transaction) error {
+		identifier = tx.GetNextIdentifier(bucketName)
+		return nil
+	})
+
+	return identifier
+}
+
+// CreateObject creates a new object in the bucket, using the next bucket sequence id
+func (connection *DbConnection) CreateObject(bucketName string, fn func(uint64) (int, any)) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.CreateObject(bucketName, fn)
+	})
+}
+
+// CreateObjectWithId creates a new object in the bucket, using the specified id
+func (connection *DbConnection) CreateObjectWithId(bucketName string, id int, obj any) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.CreateObjectWithId(bucketName, id, obj)
+	})
+}
+
+// CreateObjectWithStringId creates a new object in the bucket, using the specified id
+func (connection *DbConnection) CreateObjectWithStringId(bucketName string, id []byte, obj any) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.CreateObjectWithStringId(bucketName, id, obj)
+	})
+}
+
+func (connection *DbConnection) GetAll(bucketName string, obj any, appendFn func(o any) (any, error)) error {
+	return connection.ViewTx(func(tx portainer.Transaction) error {
+		return tx.GetAll(bucketName, obj, appendFn)
+	})
+}
+
+func (connection *DbConnection) GetAllWithKeyPrefix(bucketName string, keyPrefix []byte, obj any, appendFn func(o any) (any, error)) error {
+	return connection.ViewTx(func(tx portainer.Transaction) error {
+		return tx.GetAllWithKeyPrefix(bucketName, keyPrefix, obj, appendFn)
+	})
+}
+
+// BackupMetadata will return a copy of the sequence numbers for all buckets.
+func (connection *DbConnection) BackupMetadata() (map[string]any, error) {
+	return bucketSequences(connection.db)
+}
+
+func bucketSequences(db *sql.DB) (map[string]any, error) {
+	rows, err := db.Query("SELECT name, sequence FROM buckets UNION ALL SELECT DISTINCT bucket, 0 FROM objects WHERE bucket NOT IN (SELECT name FROM buckets)")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := map[string]any{}
+	for rows.Next() {
+		var name string
+		var sequence int
+
+		if err := rows.Scan(&name, &sequence); err != nil {
+			return nil, err
+		}
+
+		buckets[name] = sequence
+	}
+
+	return buckets, rows.Err()
+}
+
+// RestoreMetadata will restore the sequence numbers for all buckets.
+func (connection *DbConnection) RestoreMetadata(s map[string]any) error {
+	return connection.UpdateTx(func(tx portainer.Transaction) error {
+		sqlTx := tx.(*DbTransaction)
+
+		for bucketName, v := range s {
+			id, ok := v.(float64) // JSON ints are unmarshalled to interface as float64. See: https://pkg.go.dev/encoding/json#Decoder.Decode
+			if !ok {
+				log.Error().Str("bucket", bucketName).Msg("failed to restore metadata to bucket, skipped")
+
+				continue
+			}
+
+			if err := sqlTx.setSequence(bucketName, uint64(id)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NeedsEncryptionMigration(t *testing.T) {
+	tests := []struct {
+		name          string
+		dbname        string
+		key           bool
+		expectError   error
+		expectMigrate bool
+	}{
+		{name: "no database, no key", expectMigrate: false},
+		{name: "unencrypted database, no key", dbname: DatabaseFileName, expectMigrate: false},
+		{name: "unencrypted database, key", dbname: DatabaseFileName, key: true, expectMigrate: true},
+		{name: "encrypted database, key", dbname: EncryptedDatabaseFileName, key: true, expectMigrate: false},
+		{name: "encrypted database, no key", dbname: EncryptedDatabaseFileName, expectError: ErrHaveEncryptedWithNoKey},
+		{name: "both databases", dbname: "both", key: true, expectError: ErrHaveEncryptedAndUnencrypted},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			connection := DbConnection{Path: dir}
+			if tc.key {
+				connection.EncryptionKey = []byte("secret")
+			}
+
+			switch tc.dbname {
+			case "":
+			case "both":
+				require.NoError(t, os.WriteFile(path.Join(dir, DatabaseFileName), nil, 0600))
+				require.NoError(t, os.WriteFile(path.Join(dir, EncryptedDatabaseFileName), nil, 0600))
+			default:
+				require.NoError(t, os.WriteFile(path.Join(dir, tc.dbname), nil, 0600))
+			}
+
+			migrate, err := connection.NeedsEncryptionMigration()
+			require.ErrorIs(t, err, tc.expectError)
+			require.Equal(t, tc.expectMigrate, migrate)
+		})
+	}
+}
+
+func TestDbConnection_encrypted(t *testing.T) {
+	key := []byte("apassphrasewhichneedstobe32bytes")
+	conn := openTestConnection(t, key)
+
+	require.Equal(t, EncryptedDatabaseFileName, conn.GetDatabaseFileName())
+
+	require.NoError(t, conn.SetServiceName(testBucketName))
+	require.NoError(t, conn.CreateObjectWithId(testBucketName, testId, testStruct{Key: "key", Value: "secret-value"}))
+
+	var raw []byte
+	require.NoError(t, conn.db.QueryRow("SELECT value FROM objects WHERE bucket = ?", testBucketName).Scan(&raw))
+	require.False(t, bytes.Contains(raw, []byte("secret-value")), "the stored value should be encrypted")
+
+	obj := testStruct{}
+	require.NoError(t, conn.GetObject(testBucketName, conn.ConvertToKey(testId), &obj))
+	require.Equal(t, "secret-value", obj.Value)
+
+	data, err := conn.GetRawBytes(testBucketName, conn.ConvertToKey(testId))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Key":"key","Value":"secret-value"}`, string(data))
+}
+
+func TestDbConnection_metadataAndExport(t *testing.T) {
+	conn := openTestConnection(t, nil)
+
+	require.NoError(t, conn.CreateObject(testBucketName, func(id uint64) (int, any) {
+		return int(id), testStruct{Key: "key", Value: "value"}
+	}))
+	require.NoError(t, conn.UpdateObject("settings", []byte("SETTINGS"), map[string]any{"LogoURL": "logo"}))
+	require.NoError(t, conn.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.(*DbTransaction).put("version", []byte("VERSION"), []byte(`{"SchemaVersion":"2.0.0"}`))
+	}))
+
+	metadata, err := conn.BackupMetadata()
+	require.NoError(t, err)
+	require.Equal(t, 1, metadata[testBucketName])
+
+	require.NoError(t, conn.RestoreMetadata(map[string]any{testBucketName: float64(10)}))
+	require.Equal(t, 11, conn.GetNextIdentifier(testBucketName))
+
+	exportPath := path.Join(t.TempDir(), "export.json")
+	require.NoError(t, conn.ExportRaw(exportPath))
+
+	b, err := os.ReadFile(exportPath)
+	require.NoError(t, err)
+
+	var export map[string]any
+	require.NoError(t, json.Unmarshal(b, &export))
+
+	require.Equal(t, map[string]any{testBucketName: float64(11), "settings": float64(0), "version": float64(0)}, export["__metadata"])
+	require.Equal(t, []any{map[string]any{"Key": "key", "Value": "value"}}, export[testBucketName])
+	require.Equal(t, map[string]any{"LogoURL": "logo"}, export["settings"])
+	require.Equal(t, map[string]any{"VERSION": `{"SchemaVersion":"2.0.0"}`}, export["version"])
+}
+
+func TestDbConnection_BackupTo(t *testing.T) {
+	conn := openTestConnection(t, nil)
+	require.NoError(t, conn.CreateObjectWithId(testBucketName, testId, testStruct{Key: "key", Value: "value"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, conn.BackupTo(&buf))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, DatabaseFileName), buf.Bytes(), 0600))
+
+	restored := &DbConnection{Path: dir}
+	require.NoError(t, restored.Open())
+	defer restored.Close()
+
+	obj := testStruct{}
+	require.NoError(t, restored.GetObject(testBucketName, restored.ConvertToKey(testId), &obj))
+	require.Equal(t, "value", obj.Value)
+}
+
+func TestDbConnection_RotateEncryptionKey(t *testing.T) {
+	oldKey := []byte("apassphrasewhichneedstobe32bytes")
+	newKey := []byte("anotherpassphrasethatis32bytes!!")
+
+	conn := openTestConnection(t, oldKey)
+	require.NoError(t, conn.CreateObjectWithId(testBucketName, testId, testStruct{Key: "key", Value: "value"}))
+	require.NoError(t, conn.CreateObjectWithStringId("version", []byte("VERSION"), `{"SchemaVersion":"2.0.0"}`))
+
+	require.NoError(t, conn.RotateEncryptionKey(newKey))
+	require.NoError(t, conn.Close())
+
+	conn = &DbConnection{Path: conn.Path, EncryptionKey: oldKey}
+	_, err := conn.NeedsEncryptionMigration()
+	require.NoError(t, err)
+	require.NoError(t, conn.Open())
+
+	obj := testStruct{}
+	require.Error(t, conn.GetObject(testBucketName, conn.ConvertToKey(testId), &obj), "the previous key cannot read the database anymore")
+	require.NoError(t, conn.Close())
+
+	conn.EncryptionKey = newKey
+	require.NoError(t, conn.Open())
+	defer conn.Close()
+
+	require.NoError(t, conn.GetObject(testBucketName, conn.ConvertToKey(testId), &obj))
+	require.Equal(t, testStruct{Key: "key", Value: "value"}, obj)
+
+	var version string
+	require.NoError(t, conn.GetObject("version", []byte("VERSION"), &version))
+	require.JSONEq(t, `{"SchemaVersion":"2.0.0"}`, version)
+
+	require.ErrorIs(t, openTestConnection(t, nil).RotateEncryptionKey(newKey), ErrRotateUnencryptedDatabase)
+}
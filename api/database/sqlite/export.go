@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/portainer/portainer/api/database/boltdb"
+
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/encoding/json"
+)
+
+// bucketNames returns the names of the buckets of the database, including those only known through their objects
+func bucketNames(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT name FROM buckets UNION SELECT DISTINCT bucket FROM objects ORDER BY 1")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// ExportJSON creates a JSON representation from a DbConnection, in the same format as the BoltDB export. You can
+// include the database's metadata or ignore it. The database is read from a separate read-only connection so it can
+// be exported while it is open.
+func (c *DbConnection) ExportJSON(databasePath string, metadata bool) ([]byte, error) {
+	log.Debug().Str("databasePath", databasePath).Msg("exportJson")
+
+	db, err := openDatabase(databasePath, true)
+	if err != nil {
+		return []byte("{}"), err
+	}
+	defer db.Close()
+
+	backup := make(map[string]any)
+	if metadata {
+		meta, err := bucketSequences(db)
+		if err != nil {
+			log.Error().Err(err).Msg("failed exporting metadata")
+		}
+
+		backup["__metadata"] = meta
+	}
+
+	names, err := bucketNames(db)
+	if err != nil {
+		return []byte("{}"), err
+	}
+
+	for _, bucketName := range names {
+		list, version, err := c.exportBucket(db, bucketName)
+		if err != nil {
+			return []byte("{}"), err
+		}
+
+		switch bucketName {
+		case "version":
+			backup[bucketName] = version
+		case "ssl", "settings", "tunnel_server":
+			backup[bucketName] = nil
+			if len(list) > 0 {
+				backup[bucketName] = list[0]
+			}
+		default:
+			backup[bucketName] = list
+		}
+	}
+
+	return json.MarshalIndent(backup, "", "  ")
+}
+
+func (c *DbConnection) exportBucket(db *sql.DB, bucketName string) ([]any, map[string]string, error) {
+	rows, err := db.Query("SELECT key, value FROM objects WHERE bucket = ? ORDER BY key", bucketName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var list []any
+	version := make(map[string]string)
+	for rows.Next() {
+		var k, v []byte
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, nil, err
+		}
+
+		if bucketName == "version" {
+			if raw, err := boltdb.DecryptObject(v, c.getEncryptionKey()); err == nil {
+				v = raw
+			}
+
+			version[string(k)] = string(v)
+
+			continue
+		}
+
+		var obj any
+		if err := c.UnmarshalObject(v, &obj); err != nil {
+			log.Error().
+				Str("bucket", bucketName).
+				Str("object", string(v)).
+				Err(err).
+				Msg("failed to unmarshal")
+
+			obj = v
+		}
+
+		list = append(list, obj)
+	}
+
+	return list, version, rows.Err()
+}
@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"bytes"
+	"fmt"
+
+	portainer "github.com/portainer/portainer/api"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// CopyFromBoltDB copies the buckets of a BoltDB database, with their objects and sequences, into the SQLite
+// database. The objects are copied as is, both databases must use the same encryption key
+func (connection *DbConnection) CopyFromBoltDB(source *bolt.DB) error {
+	return source.View(func(boltTx *bolt.Tx) error {
+		return connection.UpdateTx(func(tx portainer.Transaction) error {
+			sqlTx := tx.(*DbTransaction)
+
+			return boltTx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+				bucketName := string(name)
+
+				if err := sqlTx.setSequence(bucketName, bucket.Sequence()); err != nil {
+					return fmt.Errorf("unable to create the bucket %s: %w", bucketName, err)
+				}
+
+				return bucket.ForEach(func(k, v []byte) error {
+					if v == nil {
+						// nested buckets are not used by Portainer
+						return nil
+					}
+
+					if err := sqlTx.put(bucketName, k, v); err != nil {
+						return fmt.Errorf("unable to copy the object %s of the bucket %s: %w", keyToString(k), bucketName, err)
+					}
+
+					return nil
+				})
+			})
+		})
+	})
+}
+
+// ValidateCopy checks that the SQLite database holds the same buckets, sequences and objects as the BoltDB database
+func (connection *DbConnection) ValidateCopy(source *bolt.DB) error {
+	sequences, err := bucketSequences(connection.db)
+	if err != nil {
+		return err
+	}
+
+	return source.View(func(boltTx *bolt.Tx) error {
+		return connection.ViewTx(func(tx portainer.Transaction) error {
+			sqlTx := tx.(*DbTransaction)
+
+			return boltTx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+				bucketName := string(name)
+
+				if sequence, ok := sequences[bucketName]; !ok || uint64(sequence.(int)) != bucket.Sequence() {
+					return fmt.Errorf("the sequence of the bucket %s does not match", bucketName)
+				}
+
+				objects, err := sqlTx.objects(bucketName, nil)
+				if err != nil {
+					return err
+				}
+
+				count := 0
+				if err := bucket.ForEach(func(k, v []byte) error {
+					if v == nil {
+						return nil
+					}
+
+					if count >= len(objects) || !bytes.Equal(objects[count].key, k) || !bytes.Equal(objects[count].value, v) {
+						return fmt.Errorf("the object %s of the bucket %s does not match", keyToString(k), bucketName)
+					}
+
+					count++
+
+					return nil
+				}); err != nil {
+					return err
+				}
+
+				if count != len(objects) {
+					return fmt.Errorf("the bucket %s holds %d objects instead of %d", bucketName, len(objects), count)
+				}
+
+				return nil
+			})
+		})
+	})
+}
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/database/boltdb"
+)
+
+type bucketObject struct {
+	bucket string
+	object
+}
+
+// RotateEncryptionKey re-encrypts every object of the database with a new encryption key within a single transaction.
+// The objects are read back with the new key before the transaction is committed, the database is left untouched when
+// any of them does not match
+func (connection *DbConnection) RotateEncryptionKey(newKey []byte) error {
+	oldKey := connection.getEncryptionKey()
+	if oldKey == nil || newKey == nil {
+		return ErrRotateUnencryptedDatabase
+	}
+
+	if err := connection.UpdateTx(func(tx portainer.Transaction) error {
+		sqlTx := tx.(*DbTransaction).tx
+
+		objects, err := allObjects(sqlTx)
+		if err != nil {
+			return err
+		}
+
+		plaintexts := make([][]byte, len(objects))
+		for i, o := range objects {
+			if plaintexts[i], err = boltdb.DecryptObject(o.value, oldKey); err != nil {
+				return fmt.Errorf("unable to decrypt the object %s of the bucket %s: %w", keyToString(o.key), o.bucket, err)
+			}
+
+			data, err := boltdb.EncryptObject(plaintexts[i], newKey)
+			if err != nil {
+				return err
+			}
+
+			if _, err := sqlTx.Exec("UPDATE objects SET value = ? WHERE bucket = ? AND key = ?", data, o.bucket, o.key); err != nil {
+				return err
+			}
+		}
+
+		rotated, err := allObjects(sqlTx)
+		if err != nil {
+			return err
+		} else if len(rotated) != len(objects) {
+			return errors.New("the number of objects does not match once re-encrypted")
+		}
+
+		for i, o := range rotated {
+			data, err := boltdb.DecryptObject(o.value, newKey)
+			if err != nil || o.bucket != objects[i].bucket || !bytes.Equal(o.key, objects[i].key) || !bytes.Equal(data, plaintexts[i]) {
+				return errors.Join(fmt.Errorf("the object %s of the bucket %s does not match once re-encrypted", keyToString(o.key), o.bucket), err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	connection.EncryptionKey = newKey
+
+	return nil
+}
+
+func allObjects(tx *sql.Tx) ([]bucketObject, error) {
+	rows, err := tx.Query("SELECT bucket, key, value FROM objects ORDER BY bucket, key")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []bucketObject
+	for rows.Next() {
+		var o bucketObject
+		if err := rows.Scan(&o.bucket, &o.key, &o.value); err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, o)
+	}
+
+	return objects, rows.Err()
+}
@@ -0,0 +1,241 @@
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/portainer/portainer/api/database/boltdb"
+	dserrors "github.com/portainer/portainer/api/dataservices/errors"
+
+	"github.com/rs/zerolog/log"
+)
+
+type DbTransaction struct {
+	conn     *DbConnection
+	tx       *sql.Tx
+	writable bool
+}
+
+type object struct {
+	key   []byte
+	value []byte
+}
+
+func (tx *DbTransaction) checkWritable() error {
+	if !tx.writable {
+		return errTxNotWritable
+	}
+
+	return nil
+}
+
+func (tx *DbTransaction) SetServiceName(bucketName string) error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+
+	_, err := tx.tx.Exec("INSERT INTO buckets (name) VALUES (?) ON CONFLICT (name) DO NOTHING", bucketName)
+
+	return err
+}
+
+func (tx *DbTransaction) getValue(bucketName string, key []byte) ([]byte, error) {
+	var value []byte
+
+	err := tx.tx.QueryRow("SELECT value FROM objects WHERE bucket = ? AND key = ?", bucketName, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w (bucket=%s, key=%s)", dserrors.ErrObjectNotFound, bucketName, keyToString(key))
+	}
+
+	return value, err
+}
+
+func (tx *DbTransaction) GetObject(bucketName string, key []byte, object any) error {
+	value, err := tx.getValue(bucketName, key)
+	if err != nil {
+		return err
+	}
+
+	return tx.conn.UnmarshalObject(value, object)
+}
+
+func (tx *DbTransaction) GetRawBytes(bucketName string, key []byte) ([]byte, error) {
+	value, err := tx.getValue(bucketName, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return boltdb.DecryptObject(value, tx.conn.getEncryptionKey())
+}
+
+func (tx *DbTransaction) KeyExists(bucketName string, key []byte) (bool, error) {
+	var exists bool
+	err := tx.tx.QueryRow("SELECT EXISTS (SELECT 1 FROM objects WHERE bucket = ? AND key = ?)", bucketName, key).Scan(&exists)
+
+	return exists, err
+}
+
+func (tx *DbTransaction) put(bucketName string, key []byte, data []byte) error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+
+	_, err := tx.tx.Exec("INSERT INTO objects (bucket, key, value) VALUES (?, ?, ?) ON CONFLICT (bucket, key) DO UPDATE SET value = excluded.value",
+		bucketName, key, tx.conn.storedValue(data))
+
+	return err
+}
+
+func (tx *DbTransaction) UpdateObject(bucketName string, key []byte, object any) error {
+	data, err := tx.conn.MarshalObject(object)
+	if err != nil {
+		return err
+	}
+
+	return tx.put(bucketName, key, data)
+}
+
+func (tx *DbTransaction) DeleteObject(bucketName string, key []byte) error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+
+	_, err := tx.tx.Exec("DELETE FROM objects WHERE bucket = ? AND key = ?", bucketName, key)
+
+	return err
+}
+
+func (tx *DbTransaction) DeleteAllObjects(bucketName string, obj any, matchingFn func(o any) (id int, ok bool)) error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+
+	objects, err := tx.objects(bucketName, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objects {
+		if err := tx.conn.UnmarshalObject(o.value, &obj); err != nil {
+			return err
+		}
+
+		if id, ok := matchingFn(obj); ok {
+			if err := tx.DeleteObject(bucketName, tx.conn.ConvertToKey(id)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// nextSequence increments the sequence of the bucket and returns it, like the NextSequence of the BoltDB buckets
+func (tx *DbTransaction) nextSequence(bucketName string) (uint64, error) {
+	if err := tx.checkWritable(); err != nil {
+		return 0, err
+	}
+
+	var sequence uint64
+	err := tx.tx.QueryRow("INSERT INTO buckets (name, sequence) VALUES (?, 1) ON CONFLICT (name) DO UPDATE SET sequence = sequence + 1 RETURNING sequence",
+		bucketName).Scan(&sequence)
+
+	return sequence, err
+}
+
+func (tx *DbTransaction) setSequence(bucketName string, sequence uint64) error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+
+	_, err := tx.tx.Exec("INSERT INTO buckets (name, sequence) VALUES (?, ?) ON CONFLICT (name) DO UPDATE SET sequence = excluded.sequence",
+		bucketName, sequence)
+
+	return err
+}
+
+func (tx *DbTransaction) GetNextIdentifier(bucketName string) int {
+	id, err := tx.nextSequence(bucketName)
+	if err != nil {
+		log.Error().Err(err).Str("bucket", bucketName).Msg("failed to get the next identifier")
+
+		return 0
+	}
+
+	return int(id)
+}
+
+func (tx *DbTransaction) CreateObject(bucketName string, fn func(uint64) (int, any)) error {
+	seqId, err := tx.nextSequence(bucketName)
+	if err != nil {
+		return err
+	}
+
+	id, obj := fn(seqId)
+
+	return tx.CreateObjectWithId(bucketName, id, obj)
+}
+
+func (tx *DbTransaction) CreateObjectWithId(bucketName string, id int, obj any) error {
+	return tx.UpdateObject(bucketName, tx.conn.ConvertToKey(id), obj)
+}
+
+func (tx *DbTransaction) CreateObjectWithStringId(bucketName string, id []byte, obj any) error {
+	return tx.UpdateObject(bucketName, id, obj)
+}
+
+// objects returns the objects of the bucket whose key starts with the prefix, sorted by key. They are read at once
+// so that the callers can run other queries in the transaction while going through them
+func (tx *DbTransaction) objects(bucketName string, keyPrefix []byte) ([]object, error) {
+	query, args := "SELECT key, value FROM objects WHERE bucket = ? ORDER BY key", []any{bucketName}
+	if len(keyPrefix) > 0 {
+		query, args = "SELECT key, value FROM objects WHERE bucket = ? AND key >= ? ORDER BY key", []any{bucketName, keyPrefix}
+	}
+
+	rows, err := tx.tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []object
+	for rows.Next() {
+		var o object
+		if err := rows.Scan(&o.key, &o.value); err != nil {
+			return nil, err
+		}
+
+		if !bytes.HasPrefix(o.key, keyPrefix) {
+			break
+		}
+
+		objects = append(objects, o)
+	}
+
+	return objects, rows.Err()
+}
+
+func (tx *DbTransaction) GetAll(bucketName string, obj any, appendFn func(o any) (any, error)) error {
+	return tx.GetAllWithKeyPrefix(bucketName, nil, obj, appendFn)
+}
+
+func (tx *DbTransaction) GetAllWithKeyPrefix(bucketName string, keyPrefix []byte, obj any, appendFn func(o any) (any, error)) error {
+	objects, err := tx.objects(bucketName, keyPrefix)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objects {
+		if err := tx.conn.UnmarshalObject(o.value, obj); err != nil {
+			return err
+		}
+
+		if obj, err = appendFn(obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
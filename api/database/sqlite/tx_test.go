@@ -0,0 +1,176 @@
+package sqlite
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testBucketName = "test-bucket"
+const testId = 1234
+
+type testStruct struct {
+	Key   string
+	Value string
+}
+
+func openTestConnection(t *testing.T, encryptionKey []byte) *DbConnection {
+	t.Helper()
+
+	conn := &DbConnection{
+		Path:          t.TempDir(),
+		EncryptionKey: encryptionKey,
+	}
+
+	_, err := conn.NeedsEncryptionMigration()
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Open())
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestTxs(t *testing.T) {
+	conn := openTestConnection(t, nil)
+
+	// Error propagation
+	err := conn.UpdateTx(func(tx portainer.Transaction) error {
+		if err := tx.SetServiceName(testBucketName); err != nil {
+			return err
+		}
+
+		if err := tx.CreateObjectWithId(testBucketName, testId, testStruct{Key: "rolled back"}); err != nil {
+			return err
+		}
+
+		return errors.New("this is an error")
+	})
+	require.Error(t, err)
+
+	exists, err := conn.KeyExists(testBucketName, conn.ConvertToKey(testId))
+	require.NoError(t, err)
+	require.False(t, exists, "the failed transaction should be rolled back")
+
+	// Create an object
+	newObj := testStruct{Key: "key", Value: "value"}
+
+	err = conn.UpdateTx(func(tx portainer.Transaction) error {
+		if err := tx.SetServiceName(testBucketName); err != nil {
+			return err
+		}
+
+		return tx.CreateObjectWithId(testBucketName, testId, newObj)
+	})
+	require.NoError(t, err)
+
+	obj := testStruct{}
+	err = conn.ViewTx(func(tx portainer.Transaction) error {
+		return tx.GetObject(testBucketName, conn.ConvertToKey(testId), &obj)
+	})
+	require.NoError(t, err)
+	require.Equal(t, newObj, obj)
+
+	// Update an object
+	updatedObj := testStruct{Key: "updated-key", Value: "updated-value"}
+
+	err = conn.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.UpdateObject(testBucketName, conn.ConvertToKey(testId), &updatedObj)
+	})
+	require.NoError(t, err)
+
+	err = conn.ViewTx(func(tx portainer.Transaction) error {
+		return tx.GetObject(testBucketName, conn.ConvertToKey(testId), &obj)
+	})
+	require.NoError(t, err)
+	require.Equal(t, updatedObj, obj)
+
+	// Writes are rejected in read-only transactions
+	err = conn.ViewTx(func(tx portainer.Transaction) error {
+		return tx.DeleteObject(testBucketName, conn.ConvertToKey(testId))
+	})
+	require.ErrorIs(t, err, errTxNotWritable)
+
+	// Delete an object
+	err = conn.UpdateTx(func(tx portainer.Transaction) error {
+		return tx.DeleteObject(testBucketName, conn.ConvertToKey(testId))
+	})
+	require.NoError(t, err)
+
+	err = conn.ViewTx(func(tx portainer.Transaction) error {
+		return tx.GetObject(testBucketName, conn.ConvertToKey(testId), &obj)
+	})
+	require.True(t, dataservices.IsErrObjectNotFound(err))
+
+	// Get next identifier
+	err = conn.UpdateTx(func(tx portainer.Transaction) error {
+		id1 := tx.GetNextIdentifier(testBucketName)
+		id2 := tx.GetNextIdentifier(testBucketName)
+
+		if id1+1 != id2 {
+			return errors.New("unexpected identifier sequence")
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestTxs_createAndList(t *testing.T) {
+	conn := openTestConnection(t, nil)
+
+	err := conn.UpdateTx(func(tx portainer.Transaction) error {
+		for range 3 {
+			if err := tx.CreateObject(testBucketName, func(id uint64) (int, any) {
+				return int(id), testStruct{Key: "key", Value: strconv.Itoa(int(id))}
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, key := range []string{"prefix-b", "prefix-a", "other"} {
+			if err := tx.CreateObjectWithStringId("strings", []byte(key), testStruct{Key: key}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	var values []string
+	err = conn.GetAll(testBucketName, &testStruct{}, func(o any) (any, error) {
+		values = append(values, o.(*testStruct).Value)
+
+		return &testStruct{}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2", "3"}, values)
+
+	var keys []string
+	err = conn.GetAllWithKeyPrefix("strings", []byte("prefix-"), &testStruct{}, func(o any) (any, error) {
+		keys = append(keys, o.(*testStruct).Key)
+
+		return &testStruct{}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"prefix-a", "prefix-b"}, keys)
+
+	err = conn.DeleteAllObjects(testBucketName, &testStruct{}, func(o any) (int, bool) {
+		obj := o.(*testStruct)
+
+		return 2, obj.Value == "2"
+	})
+	require.NoError(t, err)
+
+	exists, err := conn.KeyExists(testBucketName, conn.ConvertToKey(2))
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.Equal(t, 4, conn.GetNextIdentifier(testBucketName))
+}
@@ -0,0 +1,63 @@
+package auditlog
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "audit_logs"
+
+// Service represents a service for managing audit log data.
+type Service struct {
+	dataservices.BaseDataService[portainer.AuditLog, portainer.AuditLogID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.AuditLog, portainer.AuditLogID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+func (service *Service) Tx(tx portainer.Transaction) ServiceTx {
+	return ServiceTx{
+		BaseDataServiceTx: dataservices.BaseDataServiceTx[portainer.AuditLog, portainer.AuditLogID]{
+			Bucket:     BucketName,
+			Connection: service.Connection,
+			Tx:         tx,
+		},
+	}
+}
+
+// Create assigns an ID to a new audit log entry and saves it.
+func (service *Service) Create(entry *portainer.AuditLog) error {
+	return service.Connection.UpdateTx(func(tx portainer.Transaction) error {
+		return service.Tx(tx).Create(entry)
+	})
+}
+
+// DeleteBefore removes all the audit log entries recorded before the given unix timestamp.
+func (service *Service) DeleteBefore(timestamp int64) error {
+	return service.Connection.UpdateTx(func(tx portainer.Transaction) error {
+		return service.Tx(tx).DeleteBefore(timestamp)
+	})
+}
+
+// ReadPage returns a page of the entries matching the filter, most recent first, and the number of matching entries.
+func (service *Service) ReadPage(filter func(portainer.AuditLog) bool, start, limit int) (entries []portainer.AuditLog, total int, err error) {
+	err = service.Connection.ViewTx(func(tx portainer.Transaction) error {
+		entries, total, err = service.Tx(tx).ReadPage(filter, start, limit)
+		return err
+	})
+
+	return entries, total, err
+}
@@ -0,0 +1,70 @@
+package auditlog
+
+import (
+	"math"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/database/boltdb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPage(t *testing.T) {
+	var conn portainer.Connection = &boltdb.DbConnection{Path: t.TempDir()}
+	require.NoError(t, conn.Open())
+
+	defer conn.Close()
+
+	service, err := NewService(conn)
+	require.NoError(t, err)
+
+	for i := range 10 {
+		require.NoError(t, service.Create(&portainer.AuditLog{UserID: portainer.UserID(i % 2)}))
+	}
+
+	ids := func(entries []portainer.AuditLog) []portainer.AuditLogID {
+		var ids []portainer.AuditLogID
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+
+		return ids
+	}
+
+	everyEntry := func(portainer.AuditLog) bool { return true }
+
+	entries, total, err := service.ReadPage(everyEntry, 0, 3)
+	require.NoError(t, err)
+	require.Equal(t, 10, total)
+	require.Equal(t, []portainer.AuditLogID{10, 9, 8}, ids(entries))
+
+	entries, _, err = service.ReadPage(everyEntry, 8, 3)
+	require.NoError(t, err)
+	require.Equal(t, []portainer.AuditLogID{2, 1}, ids(entries))
+
+	entries, total, err = service.ReadPage(func(entry portainer.AuditLog) bool { return entry.UserID == 1 }, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, 5, total)
+	require.Equal(t, []portainer.AuditLogID{8, 6, 4, 2}, ids(entries))
+
+	entries, total, err = service.ReadPage(everyEntry, 20, 5)
+	require.NoError(t, err)
+	require.Equal(t, 10, total)
+	require.Empty(t, entries)
+
+	// The page is sized from the matching entries, not from the requested window
+	entries, _, err = service.ReadPage(everyEntry, math.MaxInt/2, math.MaxInt/2)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	entries, _, err = service.ReadPage(everyEntry, 7, math.MaxInt/2)
+	require.NoError(t, err)
+	require.Equal(t, []portainer.AuditLogID{3, 2, 1}, ids(entries))
+
+	_, _, err = service.ReadPage(everyEntry, -1, 3)
+	require.Error(t, err)
+
+	_, _, err = service.ReadPage(everyEntry, 0, -1)
+	require.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package auditlog
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/rs/zerolog/log"
+)
+
+type ServiceTx struct {
+	dataservices.BaseDataServiceTx[portainer.AuditLog, portainer.AuditLogID]
+}
+
+// Create assigns an ID to a new audit log entry and saves it.
+func (service ServiceTx) Create(entry *portainer.AuditLog) error {
+	return service.Tx.CreateObject(
+		BucketName,
+		func(id uint64) (int, any) {
+			entry.ID = portainer.AuditLogID(id)
+			return int(entry.ID), entry
+		},
+	)
+}
+
+// DeleteBefore removes all the audit log entries recorded before the given unix timestamp.
+func (service ServiceTx) DeleteBefore(timestamp int64) error {
+	return service.Tx.DeleteAllObjects(
+		BucketName,
+		&portainer.AuditLog{},
+		func(obj any) (id int, ok bool) {
+			entry, ok := obj.(*portainer.AuditLog)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to AuditLog object")
+				return -1, false
+			}
+
+			if entry.Timestamp < timestamp {
+				return int(entry.ID), true
+			}
+
+			return -1, false
+		})
+}
+
+// ReadPage returns the entries matching the filter, most recent first, skipping the first start entries and returning
+// at most limit entries, every entry when limit is 0. It also returns the number of matching entries. Only the entries
+// of the page are kept in memory while reading.
+func (service ServiceTx) ReadPage(filter func(portainer.AuditLog) bool, start, limit int) ([]portainer.AuditLog, int, error) {
+	if start < 0 || limit < 0 {
+		return nil, 0, errors.New("invalid audit log page")
+	}
+
+	// The entries are read by ascending identifier, the matching ones are counted first to locate the page
+	total := 0
+	if err := service.readMatching(filter, func(portainer.AuditLog) { total++ }); err != nil {
+		return nil, 0, err
+	}
+
+	start = min(start, total)
+	size := total - start
+	if limit > 0 {
+		size = min(limit, size)
+	}
+
+	// Position of the first and last entries of the page in the ascending order
+	first, last := total-start-size, total-start
+
+	page := make([]portainer.AuditLog, 0, size)
+	index := 0
+	if err := service.readMatching(filter, func(entry portainer.AuditLog) {
+		if index >= first && index < last {
+			page = append(page, entry)
+		}
+
+		index++
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	slices.Reverse(page)
+
+	return page, total, nil
+}
+
+// readMatching calls fn with the entries matching the filter, by ascending identifier
+func (service ServiceTx) readMatching(filter func(portainer.AuditLog) bool, fn func(portainer.AuditLog)) error {
+	return service.Tx.GetAll(BucketName, &portainer.AuditLog{}, func(obj any) (any, error) {
+		entry, ok := obj.(*portainer.AuditLog)
+		if !ok {
+			return nil, fmt.Errorf("failed to convert %T to AuditLog object", obj)
+		}
+
+		if filter(*entry) {
+			fn(*entry)
+		}
+
+		return &portainer.AuditLog{}, nil
+	})
+}
@@ -0,0 +1,74 @@
+package backupsettings
+
+import (
+	portainer "github.com/portainer/portainer/api"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName  = "backup_settings"
+	settingsKey = "SETTINGS"
+	statusKey   = "STATUS"
+)
+
+// Service represents a service for managing the scheduled backups data.
+type Service struct {
+	connection portainer.Connection
+}
+
+func (service *Service) BucketName() string {
+	return BucketName
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+func (service *Service) Tx(tx portainer.Transaction) ServiceTx {
+	return ServiceTx{
+		service: service,
+		tx:      tx,
+	}
+}
+
+// Settings retrieve the scheduled backups settings object.
+func (service *Service) Settings() (*portainer.BackupSettings, error) {
+	var settings portainer.BackupSettings
+
+	err := service.connection.GetObject(BucketName, []byte(settingsKey), &settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// UpdateSettings persists a BackupSettings object.
+func (service *Service) UpdateSettings(settings *portainer.BackupSettings) error {
+	return service.connection.UpdateObject(BucketName, []byte(settingsKey), settings)
+}
+
+// Status retrieve the outcome of the last scheduled backup.
+func (service *Service) Status() (*portainer.BackupStatus, error) {
+	var status portainer.BackupStatus
+
+	err := service.connection.GetObject(BucketName, []byte(statusKey), &status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// UpdateStatus persists a BackupStatus object.
+func (service *Service) UpdateStatus(status *portainer.BackupStatus) error {
+	return service.connection.UpdateObject(BucketName, []byte(statusKey), status)
+}
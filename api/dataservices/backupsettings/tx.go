@@ -0,0 +1,48 @@
+package backupsettings
+
+import (
+	portainer "github.com/portainer/portainer/api"
+)
+
+type ServiceTx struct {
+	service *Service
+	tx      portainer.Transaction
+}
+
+func (service ServiceTx) BucketName() string {
+	return BucketName
+}
+
+// Settings retrieve the scheduled backups settings object.
+func (service ServiceTx) Settings() (*portainer.BackupSettings, error) {
+	var settings portainer.BackupSettings
+
+	err := service.tx.GetObject(BucketName, []byte(settingsKey), &settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// UpdateSettings persists a BackupSettings object.
+func (service ServiceTx) UpdateSettings(settings *portainer.BackupSettings) error {
+	return service.tx.UpdateObject(BucketName, []byte(settingsKey), settings)
+}
+
+// Status retrieve the outcome of the last scheduled backup.
+func (service ServiceTx) Status() (*portainer.BackupStatus, error) {
+	var status portainer.BackupStatus
+
+	err := service.tx.GetObject(BucketName, []byte(statusKey), &status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// UpdateStatus persists a BackupStatus object.
+func (service ServiceTx) UpdateStatus(status *portainer.BackupStatus) error {
+	return service.tx.UpdateObject(BucketName, []byte(statusKey), status)
+}
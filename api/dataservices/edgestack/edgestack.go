@@ -5,6 +5,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/set"
 )
 
 // BucketName represents the name of the bucket where this service stores data.
@@ -14,10 +15,17 @@ const BucketName = "edge_stack"
 type Service struct {
 	connection          portainer.Connection
 	idxVersion          map[portainer.EdgeStackID]int
+	idxRollout          map[portainer.EdgeStackID]rolloutGate
 	mu                  sync.RWMutex
 	cacheInvalidationFn func(portainer.Transaction, portainer.EdgeStackID)
 }
 
+// rolloutGate holds the environments the version of an edge stack being rolled out is released to
+type rolloutGate struct {
+	previousVersion int
+	released        set.Set[portainer.EndpointID]
+}
+
 func (service *Service) BucketName() string {
 	return BucketName
 }
@@ -32,6 +40,7 @@ func NewService(connection portainer.Connection, cacheInvalidationFn func(portai
 	s := &Service{
 		connection:          connection,
 		idxVersion:          make(map[portainer.EdgeStackID]int),
+		idxRollout:          make(map[portainer.EdgeStackID]rolloutGate),
 		cacheInvalidationFn: cacheInvalidationFn,
 	}
 
@@ -45,7 +54,7 @@ func NewService(connection portainer.Connection, cacheInvalidationFn func(portai
 	}
 
 	for _, e := range es {
-		s.idxVersion[e.ID] = e.Version
+		s.index(e.ID, &e)
 	}
 
 	return s, nil
@@ -91,6 +100,38 @@ func (service *Service) EdgeStackVersion(ID portainer.EdgeStackID) (int, bool) {
 	return v, ok
 }
 
+// EdgeStackVersionForEndpoint returns the version of the given edge stack ID advertised to an environment directly
+// from an in-memory index. It is the previous version when the environment is not released the version being rolled out
+func (service *Service) EdgeStackVersionForEndpoint(ID portainer.EdgeStackID, endpointID portainer.EndpointID) (int, bool) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	v, ok := service.idxVersion[ID]
+
+	if gate, gated := service.idxRollout[ID]; gated && !gate.released.Contains(endpointID) {
+		return gate.previousVersion, ok
+	}
+
+	return v, ok
+}
+
+// index updates the in-memory indexes of an edge stack, the caller must hold the lock
+func (service *Service) index(ID portainer.EdgeStackID, edgeStack *portainer.EdgeStack) {
+	service.idxVersion[ID] = edgeStack.Version
+
+	rollout := edgeStack.Rollout
+	if rollout == nil || rollout.Version != edgeStack.Version || rollout.Status == portainer.EdgeStackRolloutCompleted {
+		delete(service.idxRollout, ID)
+
+		return
+	}
+
+	service.idxRollout[ID] = rolloutGate{
+		previousVersion: rollout.PreviousVersion,
+		released:        set.ToSet(rollout.Endpoints[:min(rollout.Released, len(rollout.Endpoints))]),
+	}
+}
+
 // CreateEdgeStack saves an Edge stack object to db.
 func (service *Service) Create(id portainer.EdgeStackID, edgeStack *portainer.EdgeStack) error {
 	edgeStack.ID = id
@@ -105,7 +146,7 @@ func (service *Service) Create(id portainer.EdgeStackID, edgeStack *portainer.Ed
 	}
 
 	service.mu.Lock()
-	service.idxVersion[id] = edgeStack.Version
+	service.index(id, edgeStack)
 	service.cacheInvalidationFn(service.connection, id)
 	service.mu.Unlock()
 
@@ -124,7 +165,7 @@ func (service *Service) UpdateEdgeStack(ID portainer.EdgeStackID, edgeStack *por
 		return err
 	}
 
-	service.idxVersion[ID] = edgeStack.Version
+	service.index(ID, edgeStack)
 	service.cacheInvalidationFn(service.connection, ID)
 
 	return nil
@@ -141,7 +182,7 @@ func (service *Service) UpdateEdgeStackFunc(ID portainer.EdgeStackID, updateFunc
 	return service.connection.UpdateObjectFunc(BucketName, id, edgeStack, func() {
 		updateFunc(edgeStack)
 
-		service.idxVersion[ID] = edgeStack.Version
+		service.index(ID, edgeStack)
 		service.cacheInvalidationFn(service.connection, ID)
 	})
 }
@@ -164,6 +205,7 @@ func (service *Service) DeleteEdgeStack(ID portainer.EdgeStackID) error {
 	}
 
 	delete(service.idxVersion, ID)
+	delete(service.idxRollout, ID)
 
 	service.cacheInvalidationFn(service.connection, ID)
 
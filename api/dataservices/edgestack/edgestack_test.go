@@ -48,3 +48,60 @@ func TestUpdate(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "Updated Stack Again", updatedStack.Name)
 }
+
+func TestEdgeStackVersionForEndpoint(t *testing.T) {
+	var conn portainer.Connection = &boltdb.DbConnection{Path: t.TempDir()}
+	err := conn.Open()
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	service, err := NewService(conn, func(portainer.Transaction, portainer.EdgeStackID) {})
+	require.NoError(t, err)
+
+	const edgeStackID = 1
+	edgeStack := &portainer.EdgeStack{
+		ID:      edgeStackID,
+		Version: 3,
+		Rollout: &portainer.EdgeStackRollout{
+			Version:         3,
+			PreviousVersion: 2,
+			Endpoints:       []portainer.EndpointID{1, 2, 3},
+			Released:        1,
+			Status:          portainer.EdgeStackRolloutInProgress,
+		},
+	}
+
+	err = service.Create(edgeStackID, edgeStack)
+	require.NoError(t, err)
+
+	version, ok := service.EdgeStackVersionForEndpoint(edgeStackID, 1)
+	require.True(t, ok)
+	require.Equal(t, 3, version)
+
+	version, _ = service.EdgeStackVersionForEndpoint(edgeStackID, 2)
+	require.Equal(t, 2, version)
+
+	err = service.UpdateEdgeStackFunc(edgeStackID, func(edgeStack *portainer.EdgeStack) {
+		edgeStack.Rollout.Released = 2
+	})
+	require.NoError(t, err)
+
+	version, _ = service.EdgeStackVersionForEndpoint(edgeStackID, 2)
+	require.Equal(t, 3, version)
+
+	// The index is rebuilt from the database
+	service, err = NewService(conn, func(portainer.Transaction, portainer.EdgeStackID) {})
+	require.NoError(t, err)
+
+	version, _ = service.EdgeStackVersionForEndpoint(edgeStackID, 3)
+	require.Equal(t, 2, version)
+
+	err = service.UpdateEdgeStackFunc(edgeStackID, func(edgeStack *portainer.EdgeStack) {
+		edgeStack.Rollout.Status = portainer.EdgeStackRolloutCompleted
+	})
+	require.NoError(t, err)
+
+	version, _ = service.EdgeStackVersionForEndpoint(edgeStackID, 3)
+	require.Equal(t, 3, version)
+}
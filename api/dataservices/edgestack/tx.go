@@ -60,6 +60,12 @@ func (service ServiceTx) EdgeStackVersion(ID portainer.EdgeStackID) (int, bool)
 	return v, ok
 }
 
+// EdgeStackVersionForEndpoint returns the version of the given edge stack ID advertised to an environment directly
+// from an in-memory index
+func (service ServiceTx) EdgeStackVersionForEndpoint(ID portainer.EdgeStackID, endpointID portainer.EndpointID) (int, bool) {
+	return service.service.EdgeStackVersionForEndpoint(ID, endpointID)
+}
+
 // CreateEdgeStack saves an Edge stack object to db.
 func (service ServiceTx) Create(id portainer.EdgeStackID, edgeStack *portainer.EdgeStack) error {
 	edgeStack.ID = id
@@ -73,7 +79,7 @@ func (service ServiceTx) Create(id portainer.EdgeStackID, edgeStack *portainer.E
 	}
 
 	service.service.mu.Lock()
-	service.service.idxVersion[id] = edgeStack.Version
+	service.service.index(id, edgeStack)
 	service.service.cacheInvalidationFn(service.tx, id)
 	service.service.mu.Unlock()
 
@@ -91,7 +97,7 @@ func (service ServiceTx) UpdateEdgeStack(ID portainer.EdgeStackID, edgeStack *po
 		return err
 	}
 
-	service.service.idxVersion[ID] = edgeStack.Version
+	service.service.index(ID, edgeStack)
 	service.service.cacheInvalidationFn(service.tx, ID)
 
 	return nil
@@ -121,6 +127,7 @@ func (service ServiceTx) DeleteEdgeStack(ID portainer.EdgeStackID) error {
 	}
 
 	delete(service.service.idxVersion, ID)
+	delete(service.service.idxRollout, ID)
 
 	service.service.cacheInvalidationFn(service.tx, ID)
 
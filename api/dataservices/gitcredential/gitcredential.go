@@ -0,0 +1,57 @@
+package gitcredential
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "git_credentials"
+
+// Service represents a service for managing Git credential data.
+type Service struct {
+	dataservices.BaseDataService[portainer.GitCredential, portainer.GitCredentialID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.GitCredential, portainer.GitCredentialID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+func (service *Service) Tx(tx portainer.Transaction) ServiceTx {
+	return ServiceTx{
+		BaseDataServiceTx: dataservices.BaseDataServiceTx[portainer.GitCredential, portainer.GitCredentialID]{
+			Bucket:     BucketName,
+			Connection: service.Connection,
+			Tx:         tx,
+		},
+	}
+}
+
+// Create assigns an ID to a new Git credential and saves it.
+func (service *Service) Create(credential *portainer.GitCredential) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, any) {
+			credential.ID = portainer.GitCredentialID(id)
+			return int(credential.ID), credential
+		},
+	)
+}
+
+// GitCredentialsByUserID returns the Git credentials owned by the user.
+func (service *Service) GitCredentialsByUserID(userID portainer.UserID) ([]portainer.GitCredential, error) {
+	return service.ReadAll(func(credential portainer.GitCredential) bool {
+		return credential.UserID == userID
+	})
+}
@@ -0,0 +1,28 @@
+package gitcredential
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+type ServiceTx struct {
+	dataservices.BaseDataServiceTx[portainer.GitCredential, portainer.GitCredentialID]
+}
+
+// Create assigns an ID to a new Git credential and saves it.
+func (service ServiceTx) Create(credential *portainer.GitCredential) error {
+	return service.Tx.CreateObject(
+		BucketName,
+		func(id uint64) (int, any) {
+			credential.ID = portainer.GitCredentialID(id)
+			return int(credential.ID), credential
+		},
+	)
+}
+
+// GitCredentialsByUserID returns the Git credentials owned by the user.
+func (service ServiceTx) GitCredentialsByUserID(userID portainer.UserID) ([]portainer.GitCredential, error) {
+	return service.ReadAll(func(credential portainer.GitCredential) bool {
+		return credential.UserID == userID
+	})
+}
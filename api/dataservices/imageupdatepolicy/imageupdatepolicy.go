@@ -0,0 +1,44 @@
+package imageupdatepolicy
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "image_update_policies"
+
+// Service represents a service for managing the image update policies of the environments(endpoints).
+type Service struct {
+	dataservices.BaseDataService[portainer.ImageUpdatePolicy, portainer.EndpointID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.ImageUpdatePolicy, portainer.EndpointID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+func (service *Service) Tx(tx portainer.Transaction) ServiceTx {
+	return ServiceTx{
+		BaseDataServiceTx: dataservices.BaseDataServiceTx[portainer.ImageUpdatePolicy, portainer.EndpointID]{
+			Bucket:     BucketName,
+			Connection: service.Connection,
+			Tx:         tx,
+		},
+	}
+}
+
+// Create saves the policy of an environment, the policy is identified by the environment identifier.
+func (service *Service) Create(policy *portainer.ImageUpdatePolicy) error {
+	return service.Connection.CreateObjectWithId(BucketName, int(policy.ID), policy)
+}
@@ -0,0 +1,15 @@
+package imageupdatepolicy
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+type ServiceTx struct {
+	dataservices.BaseDataServiceTx[portainer.ImageUpdatePolicy, portainer.EndpointID]
+}
+
+// Create saves the policy of an environment, the policy is identified by the environment identifier.
+func (service ServiceTx) Create(policy *portainer.ImageUpdatePolicy) error {
+	return service.Tx.CreateObjectWithId(BucketName, int(policy.ID), policy)
+}
@@ -8,6 +8,7 @@ import (
 type (
 	DataStoreTx interface {
 		IsErrObjectNotFound(err error) bool
+		AuditLog() AuditLogService
 		CustomTemplate() CustomTemplateService
 		EdgeGroup() EdgeGroupService
 		EdgeJob() EdgeJobService
@@ -33,6 +34,14 @@ type (
 		Version() VersionService
 		Webhook() WebhookService
 		PendingActions() PendingActionsService
+		NotificationChannel() NotificationChannelService
+		NotificationDelivery() NotificationDeliveryService
+		BackupSettings() BackupSettingsService
+		GitCredential() GitCredentialService
+		StackRevision() StackRevisionService
+		ImageUpdatePolicy() ImageUpdatePolicyService
+		SnapshotHistory() SnapshotHistoryService
+		TemplateSource() TemplateSourceService
 	}
 
 	DataStore interface {
@@ -51,6 +60,60 @@ type (
 		DataStoreTx
 	}
 
+	// NotificationChannelService represents a service to manage notification channels
+	NotificationChannelService interface {
+		BaseCRUD[portainer.NotificationChannel, portainer.NotificationChannelID]
+	}
+
+	// NotificationDeliveryService represents a service to manage the notification delivery history
+	NotificationDeliveryService interface {
+		BaseCRUD[portainer.NotificationDelivery, portainer.NotificationDeliveryID]
+		DeleteBefore(timestamp int64) error
+	}
+
+	// BackupSettingsService represents a service to manage the scheduled backups settings and status
+	BackupSettingsService interface {
+		Settings() (*portainer.BackupSettings, error)
+		UpdateSettings(settings *portainer.BackupSettings) error
+		Status() (*portainer.BackupStatus, error)
+		UpdateStatus(status *portainer.BackupStatus) error
+		BucketName() string
+	}
+
+	// GitCredentialService represents a service for managing Git credential data
+	GitCredentialService interface {
+		BaseCRUD[portainer.GitCredential, portainer.GitCredentialID]
+		GitCredentialsByUserID(userID portainer.UserID) ([]portainer.GitCredential, error)
+	}
+
+	// StackRevisionService represents a service for managing stack revision data
+	StackRevisionService interface {
+		BaseCRUD[portainer.StackRevision, portainer.StackRevisionID]
+		StackRevisionsByStackID(stackID portainer.StackID) ([]portainer.StackRevision, error)
+	}
+
+	// ImageUpdatePolicyService represents a service for managing image update policies data
+	ImageUpdatePolicyService interface {
+		BaseCRUD[portainer.ImageUpdatePolicy, portainer.EndpointID]
+	}
+
+	// SnapshotHistoryService represents a service for managing the snapshot history data
+	SnapshotHistoryService interface {
+		BaseCRUD[portainer.SnapshotHistory, portainer.EndpointID]
+	}
+
+	// TemplateSourceService represents a service for managing template sources data
+	TemplateSourceService interface {
+		BaseCRUD[portainer.TemplateSource, portainer.TemplateSourceID]
+	}
+
+	// AuditLogService represents a service to manage the audit trail
+	AuditLogService interface {
+		BaseCRUD[portainer.AuditLog, portainer.AuditLogID]
+		DeleteBefore(timestamp int64) error
+		ReadPage(filter func(portainer.AuditLog) bool, start, limit int) ([]portainer.AuditLog, int, error)
+	}
+
 	// CustomTemplateService represents a service to manage custom templates
 	CustomTemplateService interface {
 		BaseCRUD[portainer.CustomTemplate, portainer.CustomTemplateID]
@@ -82,6 +145,7 @@ type (
 		EdgeStacks() ([]portainer.EdgeStack, error)
 		EdgeStack(ID portainer.EdgeStackID) (*portainer.EdgeStack, error)
 		EdgeStackVersion(ID portainer.EdgeStackID) (int, bool)
+		EdgeStackVersionForEndpoint(ID portainer.EdgeStackID, endpointID portainer.EndpointID) (int, bool)
 		Create(id portainer.EdgeStackID, edgeStack *portainer.EdgeStack) error
 		UpdateEdgeStack(ID portainer.EdgeStackID, edgeStack *portainer.EdgeStack) error
 		UpdateEdgeStackFunc(ID portainer.EdgeStackID, updateFunc func(edgeStack *portainer.EdgeStack)) error
@@ -0,0 +1,50 @@
+package notificationchannel
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "notification_channels"
+
+// Service represents a service for managing notification channel data.
+type Service struct {
+	dataservices.BaseDataService[portainer.NotificationChannel, portainer.NotificationChannelID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.NotificationChannel, portainer.NotificationChannelID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+func (service *Service) Tx(tx portainer.Transaction) ServiceTx {
+	return ServiceTx{
+		BaseDataServiceTx: dataservices.BaseDataServiceTx[portainer.NotificationChannel, portainer.NotificationChannelID]{
+			Bucket:     BucketName,
+			Connection: service.Connection,
+			Tx:         tx,
+		},
+	}
+}
+
+// Create assigns an ID to a new notification channel and saves it.
+func (service *Service) Create(channel *portainer.NotificationChannel) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, any) {
+			channel.ID = portainer.NotificationChannelID(id)
+			return int(channel.ID), channel
+		},
+	)
+}
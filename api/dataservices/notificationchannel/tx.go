@@ -0,0 +1,21 @@
+package notificationchannel
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+type ServiceTx struct {
+	dataservices.BaseDataServiceTx[portainer.NotificationChannel, portainer.NotificationChannelID]
+}
+
+// Create assigns an ID to a new notification channel and saves it.
+func (service ServiceTx) Create(channel *portainer.NotificationChannel) error {
+	return service.Tx.CreateObject(
+		BucketName,
+		func(id uint64) (int, any) {
+			channel.ID = portainer.NotificationChannelID(id)
+			return int(channel.ID), channel
+		},
+	)
+}
@@ -0,0 +1,53 @@
+package notificationdelivery
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "notification_deliveries"
+
+// Service represents a service for managing notification delivery data.
+type Service struct {
+	dataservices.BaseDataService[portainer.NotificationDelivery, portainer.NotificationDeliveryID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.NotificationDelivery, portainer.NotificationDeliveryID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+func (service *Service) Tx(tx portainer.Transaction) ServiceTx {
+	return ServiceTx{
+		BaseDataServiceTx: dataservices.BaseDataServiceTx[portainer.NotificationDelivery, portainer.NotificationDeliveryID]{
+			Bucket:     BucketName,
+			Connection: service.Connection,
+			Tx:         tx,
+		},
+	}
+}
+
+// Create assigns an ID to a new notification delivery and saves it.
+func (service *Service) Create(delivery *portainer.NotificationDelivery) error {
+	return service.Connection.UpdateTx(func(tx portainer.Transaction) error {
+		return service.Tx(tx).Create(delivery)
+	})
+}
+
+// DeleteBefore removes all the notification deliveries attempted before the given unix timestamp.
+func (service *Service) DeleteBefore(timestamp int64) error {
+	return service.Connection.UpdateTx(func(tx portainer.Transaction) error {
+		return service.Tx(tx).DeleteBefore(timestamp)
+	})
+}
@@ -0,0 +1,45 @@
+package notificationdelivery
+
+import (
+	"fmt"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/rs/zerolog/log"
+)
+
+type ServiceTx struct {
+	dataservices.BaseDataServiceTx[portainer.NotificationDelivery, portainer.NotificationDeliveryID]
+}
+
+// Create assigns an ID to a new notification delivery and saves it.
+func (service ServiceTx) Create(delivery *portainer.NotificationDelivery) error {
+	return service.Tx.CreateObject(
+		BucketName,
+		func(id uint64) (int, any) {
+			delivery.ID = portainer.NotificationDeliveryID(id)
+			return int(delivery.ID), delivery
+		},
+	)
+}
+
+// DeleteBefore removes all the notification deliveries attempted before the given unix timestamp.
+func (service ServiceTx) DeleteBefore(timestamp int64) error {
+	return service.Tx.DeleteAllObjects(
+		BucketName,
+		&portainer.NotificationDelivery{},
+		func(obj any) (id int, ok bool) {
+			delivery, ok := obj.(*portainer.NotificationDelivery)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to NotificationDelivery object")
+				return -1, false
+			}
+
+			if delivery.Timestamp < timestamp {
+				return int(delivery.ID), true
+			}
+
+			return -1, false
+		})
+}
@@ -0,0 +1,44 @@
+package snapshothistory
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "snapshot_history"
+
+// Service represents a service for managing the snapshot history of the environments(endpoints).
+type Service struct {
+	dataservices.BaseDataService[portainer.SnapshotHistory, portainer.EndpointID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.SnapshotHistory, portainer.EndpointID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+func (service *Service) Tx(tx portainer.Transaction) ServiceTx {
+	return ServiceTx{
+		BaseDataServiceTx: dataservices.BaseDataServiceTx[portainer.SnapshotHistory, portainer.EndpointID]{
+			Bucket:     BucketName,
+			Connection: service.Connection,
+			Tx:         tx,
+		},
+	}
+}
+
+// Create saves the history of an environment, the history is identified by the environment identifier.
+func (service *Service) Create(history *portainer.SnapshotHistory) error {
+	return service.Connection.CreateObjectWithId(BucketName, int(history.EndpointID), history)
+}
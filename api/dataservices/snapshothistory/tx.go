@@ -0,0 +1,15 @@
+package snapshothistory
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+type ServiceTx struct {
+	dataservices.BaseDataServiceTx[portainer.SnapshotHistory, portainer.EndpointID]
+}
+
+// Create saves the history of an environment, the history is identified by the environment identifier.
+func (service ServiceTx) Create(history *portainer.SnapshotHistory) error {
+	return service.Tx.CreateObjectWithId(BucketName, int(history.EndpointID), history)
+}
@@ -0,0 +1,57 @@
+package stackrevision
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// BucketName represents the name of the bucket where this service stores data.
+const BucketName = "stack_revisions"
+
+// Service represents a service for managing stack revision data.
+type Service struct {
+	dataservices.BaseDataService[portainer.StackRevision, portainer.StackRevisionID]
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		BaseDataService: dataservices.BaseDataService[portainer.StackRevision, portainer.StackRevisionID]{
+			Bucket:     BucketName,
+			Connection: connection,
+		},
+	}, nil
+}
+
+func (service *Service) Tx(tx portainer.Transaction) ServiceTx {
+	return ServiceTx{
+		BaseDataServiceTx: dataservices.BaseDataServiceTx[portainer.StackRevision, portainer.StackRevisionID]{
+			Bucket:     BucketName,
+			Connection: service.Connection,
+			Tx:         tx,
+		},
+	}
+}
+
+// Create assigns an ID to a new stack revision and saves it.
+func (service *Service) Create(revision *portainer.StackRevision) error {
+	return service.Connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, any) {
+			revision.ID = portainer.StackRevisionID(id)
+			return int(revision.ID), revision
+		},
+	)
+}
+
+// StackRevisionsByStackID returns the revisions of the stack.
+func (service *Service) StackRevisionsByStackID(stackID portainer.StackID) ([]portainer.StackRevision, error) {
+	return service.ReadAll(func(revision portainer.StackRevision) bool {
+		return revision.StackID == stackID
+	})
+}
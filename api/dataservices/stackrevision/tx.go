@@ -0,0 +1,28 @@
+package stackrevision
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+type ServiceTx struct {
+	dataservices.BaseDataServiceTx[portainer.StackRevision, portainer.StackRevisionID]
+}
+
+// Create assigns an ID to a new stack revision and saves it.
+func (service ServiceTx) Create(revision *portainer.StackRevision) error {
+	return service.Tx.CreateObject(
+		BucketName,
+		func(id uint64) (int, any) {
+			revision.ID = portainer.StackRevisionID(id)
+			return int(revision.ID), revision
+		},
+	)
+}
+
+// StackRevisionsByStackID returns the revisions of the stack.
+func (service ServiceTx) StackRevisionsByStackID(stackID portainer.StackID) ([]portainer.StackRevision, error) {
+	return service.ReadAll(func(revision portainer.StackRevision) bool {
+		return revision.StackID == stackID
+	})
+}
@@ -0,0 +1,21 @@
+package templatesource
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+type ServiceTx struct {
+	dataservices.BaseDataServiceTx[portainer.TemplateSource, portainer.TemplateSourceID]
+}
+
+// Create creates a new template source.
+func (service ServiceTx) Create(source *portainer.TemplateSource) error {
+	return service.Tx.CreateObject(
+		BucketName,
+		func(id uint64) (int, any) {
+			source.ID = portainer.TemplateSourceID(id)
+			return int(source.ID), source
+		},
+	)
+}
@@ -76,3 +76,18 @@ func TestRestore(t *testing.T) {
 		testVersion(store, "2.4", t)
 	})
 }
+
+func TestRotateEncryptionKey(t *testing.T) {
+	_, store := MustNewTestStore(t, true, true)
+
+	backupFilename, err := store.RotateEncryptionKey([]byte("anotherpassphrasethatis32bytes!!"))
+	require.NoError(t, err)
+	require.FileExists(t, backupFilename)
+
+	v, err := store.VersionService.Version()
+	require.NoError(t, err)
+	require.Equal(t, portainer.APIVersion, v.SchemaVersion)
+
+	_, err = store.SettingsService.Settings()
+	require.NoError(t, err)
+}
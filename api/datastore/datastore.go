@@ -179,6 +179,13 @@ func (store *Store) encryptDB() error {
 		log.Error().Msg("failed to remove the un-encrypted db file")
 	}
 
+	// SQLite keeps the journal of the un-encrypted db next to it
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(oldFilename + suffix); err != nil && !os.IsNotExist(err) {
+			log.Error().Err(err).Msg("failed to remove the journal of the un-encrypted db file")
+		}
+	}
+
 	err = os.Remove(exportFilename)
 	if err != nil {
 		log.Error().Msg("failed to remove the json backup file")
@@ -191,3 +198,28 @@ func (store *Store) encryptDB() error {
 
 	return nil
 }
+
+// RotateEncryptionKey re-encrypts the database with a new encryption key. The database is backed up first, the backup
+// is encrypted with the previous key and can be copied back in place of the database to revert the rotation
+func (store *Store) RotateEncryptionKey(newKey []byte) (backupFilename string, err error) {
+	if !store.connection.IsEncryptedStore() {
+		return "", errors.New("the database is not encrypted, start Portainer with an encryption key to encrypt it")
+	}
+
+	backupFilename = path.Join(store.connection.GetStorePath(), "backups",
+		fmt.Sprintf("%s.%d.key-rotation.bak", store.connection.GetDatabaseFileName(), time.Now().Unix()))
+
+	if _, err := store.Backup(backupFilename); err != nil {
+		return "", fmt.Errorf("failed to backup database prior to rotating the encryption key: %w", err)
+	}
+
+	log.Info().Msg("rotating the encryption key of the database")
+
+	if err := store.connection.RotateEncryptionKey(newKey); err != nil {
+		return backupFilename, fmt.Errorf("failed to rotate the encryption key: %w", err)
+	}
+
+	log.Info().Str("backup", backupFilename).Msg("encryption key of the database rotated")
+
+	return backupFilename, nil
+}
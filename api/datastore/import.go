@@ -0,0 +1,199 @@
+package datastore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices/apikeyrepository"
+	"github.com/portainer/portainer/api/dataservices/backupsettings"
+	"github.com/portainer/portainer/api/dataservices/dockerhub"
+	"github.com/portainer/portainer/api/dataservices/edgestackstatus"
+	"github.com/portainer/portainer/api/dataservices/endpointrelation"
+	"github.com/portainer/portainer/api/dataservices/pendingactions"
+	"github.com/portainer/portainer/api/dataservices/settings"
+	"github.com/portainer/portainer/api/dataservices/snapshot"
+	"github.com/portainer/portainer/api/dataservices/snapshothistory"
+	"github.com/portainer/portainer/api/dataservices/ssl"
+	"github.com/portainer/portainer/api/dataservices/tag"
+	"github.com/portainer/portainer/api/dataservices/tunnelserver"
+	"github.com/portainer/portainer/api/dataservices/version"
+
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/encoding/json"
+)
+
+var errImportMissingVersion = errors.New("the export does not hold the version of the database")
+
+// importSingletonKeys lists the buckets holding a single object, with the key of that object
+var importSingletonKeys = map[string]string{
+	dockerhub.BucketName:    "DOCKERHUB",
+	settings.BucketName:     "SETTINGS",
+	ssl.BucketName:          "SSL",
+	tunnelserver.BucketName: "INFO",
+}
+
+// importKeyFields lists the buckets whose objects are not keyed by their Id field, with the field holding their key
+var importKeyFields = map[string]string{
+	apikeyrepository.BucketName: "id",
+	endpointrelation.BucketName: "EndpointID",
+	pendingactions.BucketName:   "ID",
+	snapshot.BucketName:         "EndpointId",
+	snapshothistory.BucketName:  "EndpointId",
+	tag.BucketName:              "ID",
+}
+
+// ImportJSON recreates the buckets of a JSON export in the store, along with their sequences when the export holds
+// them. It reads the format of ExportRaw, where the sequences are stored in the __metadata section, as well as the
+// format of Export. The edge stack statuses cannot be keyed back from an export, they are skipped and reported again
+// by the agents. The store is expected to be empty, MigrateData must be run afterwards
+func (store *Store) ImportJSON(r io.Reader) error {
+	var export map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return fmt.Errorf("unable to decode the export: %w", err)
+	}
+
+	if _, ok := export[version.BucketName]; !ok {
+		return errImportMissingVersion
+	}
+
+	var metadata map[string]any
+	if err := store.connection.UpdateTx(func(tx portainer.Transaction) error {
+		for bucketName, data := range export {
+			switch bucketName {
+			case "__metadata", "metadata":
+				// JSON ints are unmarshalled as float64, as expected by RestoreMetadata
+				if err := json.Unmarshal(data, &metadata); err != nil {
+					return fmt.Errorf("unable to decode the metadata: %w", err)
+				}
+
+				continue
+			case edgestackstatus.BucketName:
+				if !bytes.Equal(data, []byte("null")) {
+					log.Warn().Msg("the edge stack statuses are not imported, they are reported again by the agents")
+				}
+
+				continue
+			}
+
+			if err := store.importBucket(tx, bucketName, data); err != nil {
+				return fmt.Errorf("unable to import the bucket %s: %w", bucketName, err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if metadata == nil {
+		return nil
+	}
+
+	return store.connection.RestoreMetadata(metadata)
+}
+
+func (store *Store) importBucket(tx portainer.Transaction, bucketName string, data json.RawMessage) error {
+	var value any
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&value); err != nil {
+		return err
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	if err := tx.SetServiceName(bucketName); err != nil {
+		return err
+	}
+
+	if bucketName == version.BucketName {
+		return importVersion(tx, value)
+	}
+
+	objects, ok := value.([]any)
+	if !ok {
+		// the singletons are exported as an object
+		objects = []any{value}
+	}
+
+	for _, object := range objects {
+		key, err := store.importKey(bucketName, object)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.CreateObjectWithStringId(bucketName, key, object); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importVersion writes the version bucket, exported as its raw values by ExportRaw and as a models.Version by Export
+func importVersion(tx portainer.Transaction, value any) error {
+	values, ok := value.(map[string]any)
+	if !ok {
+		return errors.New("unexpected format")
+	}
+
+	if _, ok := values["VERSION"].(string); !ok {
+		return tx.CreateObjectWithStringId(version.BucketName, []byte("VERSION"), values)
+	}
+
+	for key, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("unexpected value for %s", key)
+		}
+
+		if err := tx.CreateObjectWithStringId(version.BucketName, []byte(key), raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (store *Store) importKey(bucketName string, object any) ([]byte, error) {
+	if key, ok := importSingletonKeys[bucketName]; ok {
+		return []byte(key), nil
+	}
+
+	fields, ok := object.(map[string]any)
+	if !ok {
+		return nil, errors.New("unexpected object format")
+	}
+
+	if bucketName == backupsettings.BucketName {
+		// the bucket holds the settings and the status of the scheduled backups
+		if _, ok := fields["CronRule"]; ok {
+			return []byte("SETTINGS"), nil
+		}
+
+		return []byte("STATUS"), nil
+	}
+
+	field, ok := importKeyFields[bucketName]
+	if !ok {
+		field = "Id"
+	}
+
+	n, ok := fields[field].(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("missing %s field", field)
+	}
+
+	id, err := n.Int64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s field: %w", field, err)
+	}
+
+	return store.connection.ConvertToKey(int(id)), nil
+}
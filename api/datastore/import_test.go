@@ -0,0 +1,106 @@
+package datastore
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/database/boltdb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportJSON_roundTrip(t *testing.T) {
+	const exportPath = "test_data/output_24_to_latest.json"
+
+	export, err := os.ReadFile(exportPath)
+	require.NoError(t, err)
+
+	_, store := MustNewTestStore(t, false, false)
+	require.NoError(t, store.connection.DeleteObject("version", []byte("VERSION")))
+
+	require.NoError(t, store.ImportJSON(bytes.NewReader(export)))
+	require.NoError(t, store.MigrateData())
+	require.NoError(t, store.connection.Close())
+
+	con, ok := store.connection.(*boltdb.DbConnection)
+	require.True(t, ok)
+
+	got, err := con.ExportJSON(con.GetDatabaseFilePath(), false)
+	require.NoError(t, err)
+	require.JSONEq(t, string(export), string(got))
+
+	// reopen the connection for the teardown of the test store
+	require.NoError(t, store.connection.Open())
+}
+
+func TestImportJSON_exportRaw(t *testing.T) {
+	_, source := MustNewTestStore(t, true, true)
+
+	endpoint := &portainer.Endpoint{ID: 1, Name: "local", Type: portainer.DockerEnvironment}
+	require.NoError(t, source.Endpoint().Create(endpoint))
+	require.NoError(t, source.EndpointRelation().Create(&portainer.EndpointRelation{EndpointID: endpoint.ID}))
+	require.NoError(t, source.Snapshot().Create(&portainer.Snapshot{EndpointID: endpoint.ID}))
+	require.NoError(t, source.BackupSettings().UpdateSettings(&portainer.BackupSettings{CronRule: "0 2 * * *"}))
+	require.NoError(t, source.BackupSettings().UpdateStatus(&portainer.BackupStatus{LastRunAt: 1700000000}))
+
+	for range 3 {
+		require.NoError(t, source.Tag().Create(&portainer.Tag{Name: "tag"}))
+	}
+	require.NoError(t, source.Tag().Delete(3))
+
+	sourceVersion, err := source.Version().Version()
+	require.NoError(t, err)
+
+	// BoltDB databases are exported once closed
+	require.NoError(t, source.connection.Close())
+
+	exportPath := filepath.Join(t.TempDir(), "export.json")
+	require.NoError(t, source.connection.ExportRaw(exportPath))
+	require.NoError(t, source.connection.Open())
+
+	f, err := os.Open(exportPath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, store := MustNewTestStore(t, false, false)
+	require.NoError(t, store.ImportJSON(f))
+	require.NoError(t, store.MigrateData())
+
+	got, err := store.Endpoint().Endpoint(endpoint.ID)
+	require.NoError(t, err)
+	require.Equal(t, "local", got.Name)
+
+	_, err = store.EndpointRelation().EndpointRelation(endpoint.ID)
+	require.NoError(t, err)
+
+	_, err = store.Snapshot().Read(endpoint.ID)
+	require.NoError(t, err)
+
+	backupSettings, err := store.BackupSettings().Settings()
+	require.NoError(t, err)
+	require.Equal(t, "0 2 * * *", backupSettings.CronRule)
+
+	backupStatus, err := store.BackupSettings().Status()
+	require.NoError(t, err)
+	require.EqualValues(t, 1700000000, backupStatus.LastRunAt)
+
+	version, err := store.Version().Version()
+	require.NoError(t, err)
+	require.Equal(t, sourceVersion.SchemaVersion, version.SchemaVersion)
+	require.Equal(t, sourceVersion.Edition, version.Edition)
+
+	// the sequences are restored so that the identifiers are not reused
+	tag := &portainer.Tag{Name: "new"}
+	require.NoError(t, store.Tag().Create(tag))
+	require.Equal(t, portainer.TagID(4), tag.ID)
+}
+
+func TestImportJSON_missingVersion(t *testing.T) {
+	_, store := MustNewTestStore(t, false, false)
+
+	err := store.ImportJSON(bytes.NewReader([]byte(`{"endpoints": []}`)))
+	require.ErrorIs(t, err, errImportMissingVersion)
+}
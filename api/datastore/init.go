@@ -58,6 +58,14 @@ func (store *Store) checkOrCreateDefaultSettings() error {
 			UserSessionTimeout:       portainer.DefaultUserSessionTimeout,
 			KubeconfigExpiry:         portainer.DefaultKubeconfigExpiry,
 			KubectlShellImage:        *store.flags.KubectlShellImage,
+			AuditLog: portainer.AuditLogSettings{
+				RetentionDays: portainer.DefaultAuditLogRetentionDays,
+			},
+			MaxStackRevisions: portainer.DefaultMaxStackRevisions,
+			SnapshotHistory: portainer.SnapshotHistorySettings{
+				RawRetention:    portainer.DefaultSnapshotHistoryRawRetention,
+				HourlyRetention: portainer.DefaultSnapshotHistoryHourlyRetention,
+			},
 
 			IsDockerDesktopExtension: isDDExtention,
 		}
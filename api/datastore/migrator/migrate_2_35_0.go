@@ -0,0 +1,25 @@
+package migrator
+
+import (
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/rs/zerolog/log"
+)
+
+// setAuditLogRetention_2_35_0 sets the default retention of the audit trail on the instances created before it
+func (m *Migrator) setAuditLogRetention_2_35_0() error {
+	log.Info().Msg("setting the default audit log retention")
+
+	settings, err := m.settingsService.Settings()
+	if err != nil {
+		return err
+	}
+
+	if settings.AuditLog.RetentionDays != 0 {
+		return nil
+	}
+
+	settings.AuditLog.RetentionDays = portainer.DefaultAuditLogRetentionDays
+
+	return m.settingsService.UpdateSettings(settings)
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/portainer/portainer/api/database/models"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/dataservices/apikeyrepository"
+	"github.com/portainer/portainer/api/dataservices/auditlog"
+	"github.com/portainer/portainer/api/dataservices/backupsettings"
 	"github.com/portainer/portainer/api/dataservices/customtemplate"
 	"github.com/portainer/portainer/api/dataservices/dockerhub"
 	"github.com/portainer/portainer/api/dataservices/edgegroup"
@@ -18,7 +20,11 @@ import (
 	"github.com/portainer/portainer/api/dataservices/endpointgroup"
 	"github.com/portainer/portainer/api/dataservices/endpointrelation"
 	"github.com/portainer/portainer/api/dataservices/extension"
+	"github.com/portainer/portainer/api/dataservices/gitcredential"
 	"github.com/portainer/portainer/api/dataservices/helmuserrepository"
+	"github.com/portainer/portainer/api/dataservices/imageupdatepolicy"
+	"github.com/portainer/portainer/api/dataservices/notificationchannel"
+	"github.com/portainer/portainer/api/dataservices/notificationdelivery"
 	"github.com/portainer/portainer/api/dataservices/pendingactions"
 	"github.com/portainer/portainer/api/dataservices/registry"
 	"github.com/portainer/portainer/api/dataservices/resourcecontrol"
@@ -26,11 +32,14 @@ import (
 	"github.com/portainer/portainer/api/dataservices/schedule"
 	"github.com/portainer/portainer/api/dataservices/settings"
 	"github.com/portainer/portainer/api/dataservices/snapshot"
+	"github.com/portainer/portainer/api/dataservices/snapshothistory"
 	"github.com/portainer/portainer/api/dataservices/ssl"
 	"github.com/portainer/portainer/api/dataservices/stack"
+	"github.com/portainer/portainer/api/dataservices/stackrevision"
 	"github.com/portainer/portainer/api/dataservices/tag"
 	"github.com/portainer/portainer/api/dataservices/team"
 	"github.com/portainer/portainer/api/dataservices/teammembership"
+	"github.com/portainer/portainer/api/dataservices/templatesource"
 	"github.com/portainer/portainer/api/dataservices/tunnelserver"
 	"github.com/portainer/portainer/api/dataservices/user"
 	"github.com/portainer/portainer/api/dataservices/version"
@@ -48,35 +57,44 @@ type Store struct {
 	flags      *portainer.CLIFlags
 	connection portainer.Connection
 
-	fileService               portainer.FileService
-	CustomTemplateService     *customtemplate.Service
-	DockerHubService          *dockerhub.Service
-	EdgeGroupService          *edgegroup.Service
-	EdgeJobService            *edgejob.Service
-	EdgeStackService          *edgestack.Service
-	EdgeStackStatusService    *edgestackstatus.Service
-	EndpointGroupService      *endpointgroup.Service
-	EndpointService           *endpoint.Service
-	EndpointRelationService   *endpointrelation.Service
-	ExtensionService          *extension.Service
-	HelmUserRepositoryService *helmuserrepository.Service
-	RegistryService           *registry.Service
-	ResourceControlService    *resourcecontrol.Service
-	RoleService               *role.Service
-	APIKeyRepositoryService   *apikeyrepository.Service
-	ScheduleService           *schedule.Service
-	SettingsService           *settings.Service
-	SnapshotService           *snapshot.Service
-	SSLSettingsService        *ssl.Service
-	StackService              *stack.Service
-	TagService                *tag.Service
-	TeamMembershipService     *teammembership.Service
-	TeamService               *team.Service
-	TunnelServerService       *tunnelserver.Service
-	UserService               *user.Service
-	VersionService            *version.Service
-	WebhookService            *webhook.Service
-	PendingActionsService     *pendingactions.Service
+	fileService                 portainer.FileService
+	AuditLogService             *auditlog.Service
+	CustomTemplateService       *customtemplate.Service
+	DockerHubService            *dockerhub.Service
+	EdgeGroupService            *edgegroup.Service
+	EdgeJobService              *edgejob.Service
+	EdgeStackService            *edgestack.Service
+	EdgeStackStatusService      *edgestackstatus.Service
+	EndpointGroupService        *endpointgroup.Service
+	EndpointService             *endpoint.Service
+	EndpointRelationService     *endpointrelation.Service
+	ExtensionService            *extension.Service
+	HelmUserRepositoryService   *helmuserrepository.Service
+	RegistryService             *registry.Service
+	ResourceControlService      *resourcecontrol.Service
+	RoleService                 *role.Service
+	APIKeyRepositoryService     *apikeyrepository.Service
+	ScheduleService             *schedule.Service
+	SettingsService             *settings.Service
+	SnapshotService             *snapshot.Service
+	SSLSettingsService          *ssl.Service
+	StackService                *stack.Service
+	TagService                  *tag.Service
+	TeamMembershipService       *teammembership.Service
+	TeamService                 *team.Service
+	TunnelServerService         *tunnelserver.Service
+	UserService                 *user.Service
+	VersionService              *version.Service
+	WebhookService              *webhook.Service
+	PendingActionsService       *pendingactions.Service
+	NotificationChannelService  *notificationchannel.Service
+	NotificationDeliveryService *notificationdelivery.Service
+	BackupSettingsService       *backupsettings.Service
+	GitCredentialService        *gitcredential.Service
+	StackRevisionService        *stackrevision.Service
+	ImageUpdatePolicyService    *imageupdatepolicy.Service
+	SnapshotHistoryService      *snapshothistory.Service
+	TemplateSourceService       *templatesource.Service
 }
 
 func (store *Store) initServices() error {
@@ -86,6 +104,60 @@ func (store *Store) initServices() error {
 	}
 	store.RoleService = authorizationsetService
 
+	notificationChannelService, err := notificationchannel.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.NotificationChannelService = notificationChannelService
+
+	notificationDeliveryService, err := notificationdelivery.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.NotificationDeliveryService = notificationDeliveryService
+
+	backupSettingsService, err := backupsettings.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.BackupSettingsService = backupSettingsService
+
+	gitCredentialService, err := gitcredential.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.GitCredentialService = gitCredentialService
+
+	stackRevisionService, err := stackrevision.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.StackRevisionService = stackRevisionService
+
+	imageUpdatePolicyService, err := imageupdatepolicy.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.ImageUpdatePolicyService = imageUpdatePolicyService
+
+	snapshotHistoryService, err := snapshothistory.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.SnapshotHistoryService = snapshotHistoryService
+
+	templateSourceService, err := templatesource.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.TemplateSourceService = templateSourceService
+
+	auditLogService, err := auditlog.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.AuditLogService = auditLogService
+
 	customTemplateService, err := customtemplate.NewService(store.connection)
 	if err != nil {
 		return err
@@ -259,6 +331,51 @@ func (store *Store) PendingActions() dataservices.PendingActionsService {
 	return store.PendingActionsService
 }
 
+// NotificationChannel gives access to the NotificationChannel data management layer
+func (store *Store) NotificationChannel() dataservices.NotificationChannelService {
+	return store.NotificationChannelService
+}
+
+// NotificationDelivery gives access to the NotificationDelivery data management layer
+func (store *Store) NotificationDelivery() dataservices.NotificationDeliveryService {
+	return store.NotificationDeliveryService
+}
+
+// BackupSettings gives access to the scheduled backups data management layer
+func (store *Store) BackupSettings() dataservices.BackupSettingsService {
+	return store.BackupSettingsService
+}
+
+// GitCredential gives access to the GitCredential data management layer
+func (store *Store) GitCredential() dataservices.GitCredentialService {
+	return store.GitCredentialService
+}
+
+// StackRevision gives access to the StackRevision data management layer
+func (store *Store) StackRevision() dataservices.StackRevisionService {
+	return store.StackRevisionService
+}
+
+// ImageUpdatePolicy gives access to the ImageUpdatePolicy data management layer
+func (store *Store) ImageUpdatePolicy() dataservices.ImageUpdatePolicyService {
+	return store.ImageUpdatePolicyService
+}
+
+// SnapshotHistory gives access to the SnapshotHistory data management layer
+func (store *Store) SnapshotHistory() dataservices.SnapshotHistoryService {
+	return store.SnapshotHistoryService
+}
+
+// TemplateSource gives access to the TemplateSource data management layer
+func (store *Store) TemplateSource() dataservices.TemplateSourceService {
+	return store.TemplateSourceService
+}
+
+// AuditLog gives access to the AuditLog data management layer
+func (store *Store) AuditLog() dataservices.AuditLogService {
+	return store.AuditLogService
+}
+
 // CustomTemplate gives access to the CustomTemplate data management layer
 func (store *Store) CustomTemplate() dataservices.CustomTemplateService {
 	return store.CustomTemplateService
@@ -378,36 +495,109 @@ func (store *Store) Webhook() dataservices.WebhookService {
 }
 
 type storeExport struct {
-	CustomTemplate     []portainer.CustomTemplate     `json:"customtemplates,omitempty"`
-	EdgeGroup          []portainer.EdgeGroup          `json:"edgegroups,omitempty"`
-	EdgeJob            []portainer.EdgeJob            `json:"edgejobs,omitempty"`
-	EdgeStack          []portainer.EdgeStack          `json:"edge_stack,omitempty"`
-	Endpoint           []portainer.Endpoint           `json:"endpoints,omitempty"`
-	EndpointGroup      []portainer.EndpointGroup      `json:"endpoint_groups,omitempty"`
-	EndpointRelation   []portainer.EndpointRelation   `json:"endpoint_relations,omitempty"`
-	Extensions         []portainer.Extension          `json:"extension,omitempty"`
-	HelmUserRepository []portainer.HelmUserRepository `json:"helm_user_repository,omitempty"`
-	Registry           []portainer.Registry           `json:"registries,omitempty"`
-	ResourceControl    []portainer.ResourceControl    `json:"resource_control,omitempty"`
-	Role               []portainer.Role               `json:"roles,omitempty"`
-	Schedules          []portainer.Schedule           `json:"schedules,omitempty"`
-	Settings           portainer.Settings             `json:"settings,omitempty"`
-	Snapshot           []portainer.Snapshot           `json:"snapshots,omitempty"`
-	SSLSettings        portainer.SSLSettings          `json:"ssl,omitempty"`
-	Stack              []portainer.Stack              `json:"stacks,omitempty"`
-	Tag                []portainer.Tag                `json:"tags,omitempty"`
-	TeamMembership     []portainer.TeamMembership     `json:"team_membership,omitempty"`
-	Team               []portainer.Team               `json:"teams,omitempty"`
-	TunnelServer       portainer.TunnelServerInfo     `json:"tunnel_server,omitempty"`
-	User               []portainer.User               `json:"users,omitempty"`
-	Version            models.Version                 `json:"version,omitempty"`
-	Webhook            []portainer.Webhook            `json:"webhooks,omitempty"`
-	Metadata           map[string]any                 `json:"metadata,omitempty"`
+	AuditLog             []portainer.AuditLog             `json:"audit_logs,omitempty"`
+	BackupSettings       portainer.BackupSettings         `json:"backup_settings,omitempty"`
+	CustomTemplate       []portainer.CustomTemplate       `json:"customtemplates,omitempty"`
+	EdgeGroup            []portainer.EdgeGroup            `json:"edgegroups,omitempty"`
+	EdgeJob              []portainer.EdgeJob              `json:"edgejobs,omitempty"`
+	EdgeStack            []portainer.EdgeStack            `json:"edge_stack,omitempty"`
+	Endpoint             []portainer.Endpoint             `json:"endpoints,omitempty"`
+	EndpointGroup        []portainer.EndpointGroup        `json:"endpoint_groups,omitempty"`
+	EndpointRelation     []portainer.EndpointRelation     `json:"endpoint_relations,omitempty"`
+	Extensions           []portainer.Extension            `json:"extension,omitempty"`
+	HelmUserRepository   []portainer.HelmUserRepository   `json:"helm_user_repository,omitempty"`
+	Registry             []portainer.Registry             `json:"registries,omitempty"`
+	ResourceControl      []portainer.ResourceControl      `json:"resource_control,omitempty"`
+	Role                 []portainer.Role                 `json:"roles,omitempty"`
+	Schedules            []portainer.Schedule             `json:"schedules,omitempty"`
+	Settings             portainer.Settings               `json:"settings,omitempty"`
+	Snapshot             []portainer.Snapshot             `json:"snapshots,omitempty"`
+	SSLSettings          portainer.SSLSettings            `json:"ssl,omitempty"`
+	Stack                []portainer.Stack                `json:"stacks,omitempty"`
+	Tag                  []portainer.Tag                  `json:"tags,omitempty"`
+	TeamMembership       []portainer.TeamMembership       `json:"team_membership,omitempty"`
+	Team                 []portainer.Team                 `json:"teams,omitempty"`
+	TunnelServer         portainer.TunnelServerInfo       `json:"tunnel_server,omitempty"`
+	User                 []portainer.User                 `json:"users,omitempty"`
+	Version              models.Version                   `json:"version,omitempty"`
+	Webhook              []portainer.Webhook              `json:"webhooks,omitempty"`
+	NotificationChannel  []portainer.NotificationChannel  `json:"notification_channels,omitempty"`
+	NotificationDelivery []portainer.NotificationDelivery `json:"notification_deliveries,omitempty"`
+	GitCredential        []portainer.GitCredential        `json:"git_credentials,omitempty"`
+	StackRevision        []portainer.StackRevision        `json:"stack_revisions,omitempty"`
+	ImageUpdatePolicy    []portainer.ImageUpdatePolicy    `json:"image_update_policies,omitempty"`
+	SnapshotHistory      []portainer.SnapshotHistory      `json:"snapshot_history,omitempty"`
+	TemplateSource       []portainer.TemplateSource       `json:"template_sources,omitempty"`
+	Metadata             map[string]any                   `json:"metadata,omitempty"`
 }
 
 func (store *Store) Export(filename string) (err error) {
 	backup := storeExport{}
 
+	if v, err := store.NotificationChannel().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Notification Channels")
+		}
+	} else {
+		backup.NotificationChannel = v
+	}
+
+	if v, err := store.NotificationDelivery().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Notification Deliveries")
+		}
+	} else {
+		backup.NotificationDelivery = v
+	}
+
+	if v, err := store.GitCredential().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Git Credentials")
+		}
+	} else {
+		backup.GitCredential = v
+	}
+
+	if v, err := store.StackRevision().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting stack revisions")
+		}
+	} else {
+		backup.StackRevision = v
+	}
+
+	if v, err := store.ImageUpdatePolicy().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting image update policies")
+		}
+	} else {
+		backup.ImageUpdatePolicy = v
+	}
+
+	if v, err := store.SnapshotHistory().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting SnapshotHistory")
+		}
+	} else {
+		backup.SnapshotHistory = v
+	}
+
+	if v, err := store.TemplateSource().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting TemplateSource")
+		}
+	} else {
+		backup.TemplateSource = v
+	}
+
+	if a, err := store.AuditLog().ReadAll(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Audit Logs")
+		}
+	} else {
+		backup.AuditLog = a
+	}
+
 	if c, err := store.CustomTemplate().ReadAll(); err != nil {
 		if !store.IsErrObjectNotFound(err) {
 			log.Error().Err(err).Msg("exporting Custom Templates")
@@ -512,6 +702,22 @@ func (store *Store) Export(filename string) (err error) {
 		backup.Schedules = r
 	}
 
+	if settings, err := store.BackupSettings().Settings(); err != nil {
+		if !store.IsErrObjectNotFound(err) {
+			log.Error().Err(err).Msg("exporting Backup Settings")
+		}
+	} else {
+		// The secrets of the backup settings are not exported
+		settings.Password = ""
+		if settings.Destination.S3 != nil {
+			s3 := *settings.Destination.S3
+			s3.SecretAccessKey = ""
+			settings.Destination.S3 = &s3
+		}
+
+		backup.BackupSettings = *settings
+	}
+
 	if settings, err := store.Settings().Settings(); err != nil {
 		if !store.IsErrObjectNotFound(err) {
 			log.Error().Err(err).Msg("exporting Settings")
@@ -627,6 +833,38 @@ func (store *Store) Import(filename string) (err error) {
 
 	store.Version().UpdateVersion(&backup.Version)
 
+	for _, v := range backup.NotificationChannel {
+		store.NotificationChannel().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.NotificationDelivery {
+		store.NotificationDelivery().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.GitCredential {
+		store.GitCredential().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.StackRevision {
+		store.StackRevision().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.ImageUpdatePolicy {
+		store.ImageUpdatePolicy().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.SnapshotHistory {
+		store.SnapshotHistory().Update(v.EndpointID, &v)
+	}
+
+	for _, v := range backup.TemplateSource {
+		store.TemplateSource().Update(v.ID, &v)
+	}
+
+	for _, v := range backup.AuditLog {
+		store.AuditLog().Update(v.ID, &v)
+	}
+
 	for _, v := range backup.CustomTemplate {
 		store.CustomTemplate().Update(v.ID, &v)
 	}
@@ -672,6 +910,7 @@ func (store *Store) Import(filename string) (err error) {
 	}
 
 	store.Settings().UpdateSettings(&backup.Settings)
+	store.BackupSettings().UpdateSettings(&backup.BackupSettings)
 	store.SSLSettings().UpdateSettings(&backup.SSLSettings)
 
 	for _, v := range backup.Snapshot {
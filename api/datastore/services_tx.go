@@ -14,6 +14,42 @@ func (tx *StoreTx) IsErrObjectNotFound(err error) bool {
 	return tx.store.IsErrObjectNotFound(err)
 }
 
+func (tx *StoreTx) NotificationChannel() dataservices.NotificationChannelService {
+	return tx.store.NotificationChannelService.Tx(tx.tx)
+}
+
+func (tx *StoreTx) NotificationDelivery() dataservices.NotificationDeliveryService {
+	return tx.store.NotificationDeliveryService.Tx(tx.tx)
+}
+
+func (tx *StoreTx) BackupSettings() dataservices.BackupSettingsService {
+	return tx.store.BackupSettingsService.Tx(tx.tx)
+}
+
+func (tx *StoreTx) GitCredential() dataservices.GitCredentialService {
+	return tx.store.GitCredentialService.Tx(tx.tx)
+}
+
+func (tx *StoreTx) StackRevision() dataservices.StackRevisionService {
+	return tx.store.StackRevisionService.Tx(tx.tx)
+}
+
+func (tx *StoreTx) ImageUpdatePolicy() dataservices.ImageUpdatePolicyService {
+	return tx.store.ImageUpdatePolicyService.Tx(tx.tx)
+}
+
+func (tx *StoreTx) SnapshotHistory() dataservices.SnapshotHistoryService {
+	return tx.store.SnapshotHistoryService.Tx(tx.tx)
+}
+
+func (tx *StoreTx) TemplateSource() dataservices.TemplateSourceService {
+	return tx.store.TemplateSourceService.Tx(tx.tx)
+}
+
+func (tx *StoreTx) AuditLog() dataservices.AuditLogService {
+	return tx.store.AuditLogService.Tx(tx.tx)
+}
+
 func (tx *StoreTx) CustomTemplate() dataservices.CustomTemplateService {
 	return tx.store.CustomTemplateService.Tx(tx.tx)
 }
@@ -45,11 +45,22 @@ func NewClientFactory(signatureService portainer.DigitalSignatureService, revers
 // with an agent enabled environment(endpoint) to target a specific node in an agent cluster.
 // The underlying http client timeout may be specified, a default value is used otherwise.
 func (factory *ClientFactory) CreateClient(endpoint *portainer.Endpoint, nodeName string, timeout *time.Duration) (*client.Client, error) {
+	return factory.createClient(endpoint, nodeName, false, timeout)
+}
+
+// CreateManagerClient creates a Docker client used for the operations that must reach a Swarm manager,
+// such as the deployment of a stack. With an agent enabled environment(endpoint), the agent forwards
+// the requests of this client to a manager node of the cluster.
+func (factory *ClientFactory) CreateManagerClient(endpoint *portainer.Endpoint, timeout *time.Duration) (*client.Client, error) {
+	return factory.createClient(endpoint, "", true, timeout)
+}
+
+func (factory *ClientFactory) createClient(endpoint *portainer.Endpoint, nodeName string, managerOperation bool, timeout *time.Duration) (*client.Client, error) {
 	switch endpoint.Type {
 	case portainer.AzureEnvironment:
 		return nil, errUnsupportedEnvironmentType
 	case portainer.AgentOnDockerEnvironment:
-		return createAgentClient(endpoint, endpoint.URL, factory.signatureService, nodeName, timeout)
+		return createAgentClient(endpoint, endpoint.URL, factory.signatureService, nodeName, managerOperation, timeout)
 	case portainer.EdgeAgentOnDockerEnvironment:
 		tunnelAddr, err := factory.reverseTunnelService.TunnelAddr(endpoint)
 		if err != nil {
@@ -58,7 +69,7 @@ func (factory *ClientFactory) CreateClient(endpoint *portainer.Endpoint, nodeNam
 
 		endpointURL := "http://" + tunnelAddr
 
-		return createAgentClient(endpoint, endpointURL, factory.signatureService, nodeName, timeout)
+		return createAgentClient(endpoint, endpointURL, factory.signatureService, nodeName, managerOperation, timeout)
 	}
 
 	if strings.HasPrefix(endpoint.URL, "unix://") || strings.HasPrefix(endpoint.URL, "npipe://") {
@@ -94,7 +105,7 @@ func createTCPClient(endpoint *portainer.Endpoint, timeout *time.Duration) (*cli
 	return client.NewClientWithOpts(opts...)
 }
 
-func createAgentClient(endpoint *portainer.Endpoint, endpointURL string, signatureService portainer.DigitalSignatureService, nodeName string, timeout *time.Duration) (*client.Client, error) {
+func createAgentClient(endpoint *portainer.Endpoint, endpointURL string, signatureService portainer.DigitalSignatureService, nodeName string, managerOperation bool, timeout *time.Duration) (*client.Client, error) {
 	httpCli, err := httpClient(endpoint, timeout)
 	if err != nil {
 		return nil, err
@@ -114,6 +125,10 @@ func createAgentClient(endpoint *portainer.Endpoint, endpointURL string, signatu
 		headers[portainer.PortainerAgentTargetHeader] = nodeName
 	}
 
+	if managerOperation {
+		headers[portainer.PortainerAgentManagerOperationHeader] = "1"
+	}
+
 	opts := []client.Opt{
 		client.WithHost(endpointURL),
 		client.WithAPIVersionNegotiation(),
@@ -8,6 +8,7 @@ import (
 	"github.com/portainer/portainer/api/dataservices"
 	dockerclient "github.com/portainer/portainer/api/docker/client"
 	"github.com/portainer/portainer/api/docker/images"
+	"github.com/portainer/portainer/api/internal/authorization"
 
 	"github.com/Masterminds/semver"
 	"github.com/docker/docker/api/types"
@@ -221,6 +222,45 @@ func (c *ContainerService) Recreate(ctx context.Context, endpoint *portainer.End
 	return &newContainer, nil
 }
 
+// TransferResources moves the resource control and the webhook of a recreated container to the new container
+func (c *ContainerService) TransferResources(oldContainerID, newContainerID string) {
+	c.updateWebhook(oldContainerID, newContainerID)
+	c.createResourceControl(oldContainerID, newContainerID)
+}
+
+func (c *ContainerService) createResourceControl(oldContainerId string, newContainerId string) {
+	resourceControls, err := c.dataStore.ResourceControl().ReadAll()
+	if err != nil {
+		log.Error().Err(err).Msg("Exporting Resource Controls")
+
+		return
+	}
+
+	resourceControl := authorization.GetResourceControlByResourceIDAndType(oldContainerId, portainer.ContainerResourceControl, resourceControls)
+	if resourceControl == nil {
+		return
+	}
+
+	resourceControl.ResourceID = newContainerId
+	if err := c.dataStore.ResourceControl().Create(resourceControl); err != nil {
+		log.Error().Err(err).Str("containerId", newContainerId).Msg("Failed to create new resource control for container")
+	}
+}
+
+func (c *ContainerService) updateWebhook(oldContainerId string, newContainerId string) {
+	webhook, err := c.dataStore.Webhook().WebhookByResourceID(oldContainerId)
+	if err != nil {
+		log.Error().Err(err).Str("containerId", oldContainerId).Msg("cannot find webhook by containerId")
+
+		return
+	}
+
+	webhook.ResourceID = newContainerId
+	if err := c.dataStore.Webhook().Update(webhook.ID, webhook); err != nil {
+		log.Error().Err(err).Int("webhookId", int(webhook.ID)).Msg("cannot update webhook")
+	}
+}
+
 type serviceRestore struct {
 	restoreC chan struct{}
 	fs       []func()
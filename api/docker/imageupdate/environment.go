@@ -0,0 +1,87 @@
+package imageupdate
+
+import (
+	"context"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/docker"
+	"github.com/portainer/portainer/api/docker/images"
+	"github.com/portainer/portainer/api/stacks/deployments"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// environment holds the operations performed on a Docker environment during a run
+type environment interface {
+	Containers(ctx context.Context) ([]container.Summary, error)
+	ImageStatus(ctx context.Context, containerID string) (images.Status, error)
+	Inspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	// Recreate replaces the container with a new one, pulling its image first when pull is true
+	Recreate(ctx context.Context, containerID string, pull bool) (*container.InspectResponse, error)
+	TagImage(ctx context.Context, imageID, reference string) error
+	RedeployStack(stack *portainer.Stack) error
+	Close() error
+}
+
+type dockerEnvironment struct {
+	endpoint         *portainer.Endpoint
+	cli              *client.Client
+	dataStore        dataservices.DataStore
+	digestClient     *images.DigestClient
+	containerService *docker.ContainerService
+	stackDeployer    deployments.StackDeployer
+}
+
+func (service *Service) newEnvironment(endpoint *portainer.Endpoint) (environment, error) {
+	cli, err := service.clientFactory.CreateClient(endpoint, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dockerEnvironment{
+		endpoint:         endpoint,
+		cli:              cli,
+		dataStore:        service.dataStore,
+		digestClient:     images.NewClientWithRegistry(images.NewRegistryClient(service.dataStore), service.clientFactory),
+		containerService: service.containerService,
+		stackDeployer:    service.stackDeployer,
+	}, nil
+}
+
+func (env *dockerEnvironment) Containers(ctx context.Context) ([]container.Summary, error) {
+	return env.cli.ContainerList(ctx, container.ListOptions{})
+}
+
+func (env *dockerEnvironment) ImageStatus(ctx context.Context, containerID string) (images.Status, error) {
+	return env.digestClient.ContainerImageStatus(ctx, containerID, env.endpoint, "")
+}
+
+func (env *dockerEnvironment) Inspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
+	return env.cli.ContainerInspect(ctx, containerID)
+}
+
+func (env *dockerEnvironment) Recreate(ctx context.Context, containerID string, pull bool) (*container.InspectResponse, error) {
+	ct, err := env.containerService.Recreate(ctx, env.endpoint, containerID, pull, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	env.containerService.TransferResources(containerID, ct.ID)
+	images.EvictImageStatus(containerID)
+
+	return ct, nil
+}
+
+func (env *dockerEnvironment) TagImage(ctx context.Context, imageID, reference string) error {
+	return env.cli.ImageTag(ctx, imageID, reference)
+}
+
+func (env *dockerEnvironment) RedeployStack(stack *portainer.Stack) error {
+	return deployments.RedeployWithLatestImages(stack, env.stackDeployer, env.dataStore)
+}
+
+func (env *dockerEnvironment) Close() error {
+	return env.cli.Close()
+}
@@ -0,0 +1,501 @@
+package imageupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/docker"
+	dockerclient "github.com/portainer/portainer/api/docker/client"
+	"github.com/portainer/portainer/api/docker/consts"
+	"github.com/portainer/portainer/api/docker/images"
+	"github.com/portainer/portainer/api/notifications"
+	"github.com/portainer/portainer/api/scheduler"
+	"github.com/portainer/portainer/api/stacks/deployments"
+
+	"github.com/docker/docker/api/types/container"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// EnableLabel excludes a container from the automatic image updates when set to false
+	EnableLabel = "io.portainer.imageupdate.enable"
+	// MaxRuns is the number of runs kept in the history of a policy
+	MaxRuns = 10
+
+	defaultHealthcheckTimeout = time.Minute
+)
+
+var (
+	healthPollInterval = 2 * time.Second
+	// startupGracePeriod is the time a container without healthcheck must keep running after being recreated
+	startupGracePeriod = 10 * time.Second
+)
+
+// ErrRunInProgress is returned when the policy of the environment is already running
+var ErrRunInProgress = errors.New("an image update is already running for this environment")
+
+// Service runs the image update policies of the Docker environments on their schedule
+type Service struct {
+	dataStore        dataservices.DataStore
+	clientFactory    *dockerclient.ClientFactory
+	containerService *docker.ContainerService
+	stackDeployer    deployments.StackDeployer
+	scheduler        *scheduler.Scheduler
+	shutdownCtx      context.Context
+	mu               sync.Mutex
+	jobs             map[portainer.EndpointID]string
+	running          map[portainer.EndpointID]bool
+}
+
+// NewService creates a new service, Start must be called to schedule the enabled policies
+func NewService(shutdownCtx context.Context, dataStore dataservices.DataStore, clientFactory *dockerclient.ClientFactory, containerService *docker.ContainerService, stackDeployer deployments.StackDeployer, scheduler *scheduler.Scheduler) *Service {
+	return &Service{
+		dataStore:        dataStore,
+		clientFactory:    clientFactory,
+		containerService: containerService,
+		stackDeployer:    stackDeployer,
+		scheduler:        scheduler,
+		shutdownCtx:      shutdownCtx,
+		jobs:             make(map[portainer.EndpointID]string),
+		running:          make(map[portainer.EndpointID]bool),
+	}
+}
+
+// Start schedules the policies persisted in the database
+func (service *Service) Start() error {
+	policies, err := service.dataStore.ImageUpdatePolicy().ReadAll()
+	if err != nil {
+		return err
+	}
+
+	for i := range policies {
+		if err := service.Reschedule(&policies[i]); err != nil {
+			log.Warn().Err(err).Int("endpoint_id", int(policies[i].ID)).Msg("unable to schedule the image update policy")
+		}
+	}
+
+	return nil
+}
+
+// Reschedule replaces the job of the environment with one matching the policy
+func (service *Service) Reschedule(policy *portainer.ImageUpdatePolicy) error {
+	service.Stop(policy.ID)
+
+	if !policy.Enabled {
+		return nil
+	}
+
+	interval, err := time.ParseDuration(policy.Interval)
+	if err != nil {
+		return pkgerrors.Wrap(err, "invalid interval")
+	}
+
+	endpointID := policy.ID
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	service.jobs[endpointID] = service.scheduler.StartJobEvery(interval, func() error {
+		_, err := service.Run(service.shutdownCtx, endpointID)
+		if dataservices.IsErrObjectNotFound(err) {
+			return scheduler.NewPermanentError(err)
+		} else if err != nil {
+			log.Error().Err(err).Int("endpoint_id", int(endpointID)).Msg("scheduled image update failed")
+		}
+
+		// the job must keep running on its schedule whatever the outcome
+		return nil
+	})
+
+	return nil
+}
+
+// Stop removes the job of the environment
+func (service *Service) Stop(endpointID portainer.EndpointID) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	jobID, ok := service.jobs[endpointID]
+	if !ok {
+		return
+	}
+
+	if err := service.scheduler.StopJob(jobID); err != nil {
+		log.Debug().Err(err).Int("endpoint_id", int(endpointID)).Msg("unable to stop the image update job")
+	}
+
+	delete(service.jobs, endpointID)
+}
+
+// Run updates the outdated containers of the environment according to its policy and records the run in the policy
+func (service *Service) Run(ctx context.Context, endpointID portainer.EndpointID) (*portainer.ImageUpdateRun, error) {
+	if !service.lock(endpointID) {
+		return nil, ErrRunInProgress
+	}
+	defer service.unlock(endpointID)
+
+	policy, err := service.dataStore.ImageUpdatePolicy().Read(endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := service.dataStore.Endpoint().Endpoint(endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	stacks, err := service.dataStore.Stack().ReadAll(func(stack portainer.Stack) bool {
+		return stack.EndpointID == endpointID && stack.Type == portainer.DockerComposeStack
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	run := portainer.ImageUpdateRun{StartedAt: time.Now().Unix()}
+
+	env, err := service.newEnvironment(endpoint)
+	if err != nil {
+		run.Error = err.Error()
+	} else {
+		defer env.Close()
+
+		run = update(ctx, env, policy, stacks)
+	}
+
+	run.FinishedAt = time.Now().Unix()
+
+	if err := service.dataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		policy, err := tx.ImageUpdatePolicy().Read(endpointID)
+		if err != nil {
+			return err
+		}
+
+		policy.Runs = AppendRun(policy.Runs, run)
+
+		return tx.ImageUpdatePolicy().Update(endpointID, policy)
+	}); err != nil {
+		return nil, err
+	}
+
+	notifyFailures(endpoint, run)
+
+	return &run, nil
+}
+
+func (service *Service) lock(endpointID portainer.EndpointID) bool {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.running[endpointID] {
+		return false
+	}
+
+	service.running[endpointID] = true
+
+	return true
+}
+
+func (service *Service) unlock(endpointID portainer.EndpointID) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	delete(service.running, endpointID)
+}
+
+// AppendRun adds the run at the beginning of the history and removes the runs exceeding MaxRuns
+func AppendRun(runs []portainer.ImageUpdateRun, run portainer.ImageUpdateRun) []portainer.ImageUpdateRun {
+	runs = append([]portainer.ImageUpdateRun{run}, runs...)
+	if len(runs) > MaxRuns {
+		runs = runs[:MaxRuns]
+	}
+
+	return runs
+}
+
+// ValidateLabelSelector checks the format of a label selector, a comma separated list of key or key=value
+func ValidateLabelSelector(selector string) error {
+	if selector == "" {
+		return nil
+	}
+
+	for _, requirement := range strings.Split(selector, ",") {
+		key, _, _ := strings.Cut(requirement, "=")
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid label selector requirement %q", requirement)
+		}
+	}
+
+	return nil
+}
+
+// matchesLabelSelector returns true when the labels satisfy all the requirements of the selector
+func matchesLabelSelector(labels map[string]string, selector string) bool {
+	if selector == "" {
+		return true
+	}
+
+	for _, requirement := range strings.Split(selector, ",") {
+		key, value, hasValue := strings.Cut(requirement, "=")
+
+		labelValue, ok := labels[strings.TrimSpace(key)]
+		if !ok || (hasValue && labelValue != strings.TrimSpace(value)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// update recreates the outdated standalone containers one at a time and redeploys the outdated stacks
+func update(ctx context.Context, env environment, policy *portainer.ImageUpdatePolicy, stacks []portainer.Stack) portainer.ImageUpdateRun {
+	run := portainer.ImageUpdateRun{
+		StartedAt: time.Now().Unix(),
+		Results:   []portainer.ImageUpdateResult{},
+	}
+
+	containers, err := env.Containers(ctx)
+	if err != nil {
+		run.Error = err.Error()
+
+		return run
+	}
+
+	timeout := defaultHealthcheckTimeout
+	if policy.HealthcheckTimeout > 0 {
+		timeout = time.Duration(policy.HealthcheckTimeout) * time.Second
+	}
+
+	var outdatedStacks []string
+
+	for _, ct := range containers {
+		if !matchesLabelSelector(ct.Labels, policy.LabelSelector) || ct.Labels[EnableLabel] == "false" {
+			continue
+		}
+
+		// the tasks of the Swarm services are updated by Swarm
+		if ct.Labels[consts.SwarmServiceIDLabel] != "" {
+			continue
+		}
+
+		status, err := env.ImageStatus(ctx, ct.ID)
+		if err != nil {
+			log.Warn().Err(err).Str("container_id", ct.ID).Msg("unable to retrieve the image status of the container")
+
+			continue
+		}
+
+		if status != images.Outdated {
+			continue
+		}
+
+		if project := ct.Labels[consts.ComposeStackNameLabel]; project != "" {
+			if !containsString(outdatedStacks, project) {
+				outdatedStacks = append(outdatedStacks, project)
+			}
+
+			continue
+		}
+
+		run.Results = append(run.Results, updateContainer(ctx, env, ct, timeout))
+	}
+
+	for _, project := range outdatedStacks {
+		run.Results = append(run.Results, updateStack(env, policy, project, stacks))
+	}
+
+	return run
+}
+
+func updateContainer(ctx context.Context, env environment, ct container.Summary, timeout time.Duration) portainer.ImageUpdateResult {
+	result := portainer.ImageUpdateResult{
+		ContainerName:   containerName(ct),
+		Image:           ct.Image,
+		PreviousImageID: ct.ImageID,
+	}
+
+	// Recreate restores the previous container when the new one cannot be created or started
+	updated, err := env.Recreate(ctx, ct.ID, true)
+	if err != nil {
+		result.Status = portainer.ImageUpdateStatusFailed
+		result.Error = err.Error()
+
+		return result
+	}
+
+	result.Image = updated.Config.Image
+	result.ImageID = updated.Image
+
+	healthErr := waitHealthy(ctx, env, updated.ID, timeout)
+	if healthErr == nil {
+		result.Status = portainer.ImageUpdateStatusUpdated
+
+		return result
+	}
+
+	log.Warn().Err(healthErr).Str("container", result.ContainerName).Msg("rolling back the image update of the container")
+
+	// the pull moved the tag to the new image, move it back to recreate the container with the previous image
+	if err := env.TagImage(ctx, result.PreviousImageID, updated.Config.Image); err != nil {
+		result.Status = portainer.ImageUpdateStatusFailed
+		result.Error = fmt.Sprintf("%s, unable to restore the previous image: %s", healthErr, err)
+
+		return result
+	}
+
+	if _, err := env.Recreate(ctx, updated.ID, false); err != nil {
+		result.Status = portainer.ImageUpdateStatusFailed
+		result.Error = fmt.Sprintf("%s, unable to recreate the container with the previous image: %s", healthErr, err)
+
+		return result
+	}
+
+	result.Status = portainer.ImageUpdateStatusRolledBack
+	result.Error = healthErr.Error()
+
+	return result
+}
+
+func updateStack(env environment, policy *portainer.ImageUpdatePolicy, project string, stacks []portainer.Stack) portainer.ImageUpdateResult {
+	result := portainer.ImageUpdateResult{StackName: project, Status: portainer.ImageUpdateStatusSkipped}
+
+	var stack *portainer.Stack
+	for i := range stacks {
+		if stacks[i].Name == project {
+			stack = &stacks[i]
+
+			break
+		}
+	}
+
+	switch {
+	case stack == nil:
+		result.Error = "the stack is not managed by Portainer"
+	case policy.StackStrategy != portainer.ImageUpdateStackRedeploy:
+	default:
+		if err := env.RedeployStack(stack); err != nil {
+			result.Status = portainer.ImageUpdateStatusFailed
+			result.Error = err.Error()
+		} else {
+			result.Status = portainer.ImageUpdateStatusUpdated
+		}
+	}
+
+	return result
+}
+
+// waitHealthy waits for a recreated container to report a healthy status, containers without healthcheck
+// must keep running during the startup grace period
+func waitHealthy(ctx context.Context, env environment, containerID string, timeout time.Duration) error {
+	started := time.Now()
+	grace := min(startupGracePeriod, timeout)
+
+	for {
+		ct, err := env.Inspect(ctx, containerID)
+		if err != nil {
+			return err
+		}
+
+		if ct.State == nil || !ct.State.Running || ct.State.Restarting {
+			return errors.New("the container stopped after being recreated")
+		}
+
+		if ct.State.Health != nil {
+			switch ct.State.Health.Status {
+			case container.Healthy:
+				return nil
+			case container.Unhealthy:
+				return errors.New("the container is unhealthy")
+			}
+		} else if time.Since(started) >= grace {
+			return nil
+		}
+
+		if time.Since(started) >= timeout {
+			return errors.New("the container did not become healthy in time")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(healthPollInterval):
+		}
+	}
+}
+
+func notifyFailures(endpoint *portainer.Endpoint, run portainer.ImageUpdateRun) {
+	if run.Error != "" {
+		notifications.Notify(portainer.NotificationEvent{
+			Type:       portainer.NotificationEventImageUpdateFailed,
+			Message:    fmt.Sprintf("Image update of the environment %s failed: %s", endpoint.Name, run.Error),
+			EndpointID: endpoint.ID,
+		})
+	}
+
+	for _, result := range run.Results {
+		if result.Status != portainer.ImageUpdateStatusFailed && result.Status != portainer.ImageUpdateStatusRolledBack {
+			continue
+		}
+
+		name := result.ContainerName
+		if name == "" {
+			name = result.StackName
+		}
+
+		notifications.Notify(portainer.NotificationEvent{
+			Type:       portainer.NotificationEventImageUpdateFailed,
+			Message:    fmt.Sprintf("Image update of %s on the environment %s was %s: %s", name, endpoint.Name, result.Status, result.Error),
+			EndpointID: endpoint.ID,
+		})
+	}
+}
+
+func containerName(ct container.Summary) string {
+	if len(ct.Names) == 0 {
+		return ct.ID
+	}
+
+	return strings.TrimPrefix(ct.Names[0], "/")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidatePolicy checks the schedule, the stack strategy and the label selector of a policy
+func ValidatePolicy(policy *portainer.ImageUpdatePolicy) error {
+	if policy.Enabled || policy.Interval != "" {
+		interval, err := time.ParseDuration(policy.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval %q: %w", policy.Interval, err)
+		}
+
+		if interval < time.Minute {
+			return errors.New("the interval must be at least one minute")
+		}
+	}
+
+	switch policy.StackStrategy {
+	case portainer.ImageUpdateStackSkip, portainer.ImageUpdateStackRedeploy:
+	default:
+		return fmt.Errorf("invalid stack strategy %q", policy.StackStrategy)
+	}
+
+	if policy.HealthcheckTimeout < 0 {
+		return errors.New("the healthcheck timeout cannot be negative")
+	}
+
+	return ValidateLabelSelector(policy.LabelSelector)
+}
@@ -0,0 +1,255 @@
+package imageupdate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/docker/consts"
+	"github.com/portainer/portainer/api/docker/images"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEnvironment struct {
+	containers []container.Summary
+	statuses   map[string]images.Status
+	// health returned by Inspect for the recreated containers, nil when they have no healthcheck
+	health     *container.Health
+	recreated  []string
+	pulled     []bool
+	tagged     map[string]string
+	redeployed []string
+}
+
+func (env *fakeEnvironment) Containers(ctx context.Context) ([]container.Summary, error) {
+	return env.containers, nil
+}
+
+func (env *fakeEnvironment) ImageStatus(ctx context.Context, containerID string) (images.Status, error) {
+	return env.statuses[containerID], nil
+}
+
+func (env *fakeEnvironment) Inspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
+	return container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:    containerID,
+			State: &container.State{Running: true, Health: env.health},
+		},
+	}, nil
+}
+
+func (env *fakeEnvironment) Recreate(ctx context.Context, containerID string, pull bool) (*container.InspectResponse, error) {
+	env.recreated = append(env.recreated, containerID)
+	env.pulled = append(env.pulled, pull)
+
+	return &container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{ID: containerID + "-new", Image: "sha256:new"},
+		Config:            &container.Config{Image: "nginx:latest"},
+	}, nil
+}
+
+func (env *fakeEnvironment) TagImage(ctx context.Context, imageID, reference string) error {
+	if env.tagged == nil {
+		env.tagged = make(map[string]string)
+	}
+
+	env.tagged[reference] = imageID
+
+	return nil
+}
+
+func (env *fakeEnvironment) RedeployStack(stack *portainer.Stack) error {
+	env.redeployed = append(env.redeployed, stack.Name)
+
+	return nil
+}
+
+func (env *fakeEnvironment) Close() error {
+	return nil
+}
+
+func setFastHealthchecks(t *testing.T) {
+	previousPoll, previousGrace := healthPollInterval, startupGracePeriod
+	healthPollInterval, startupGracePeriod = time.Millisecond, 5*time.Millisecond
+
+	t.Cleanup(func() {
+		healthPollInterval, startupGracePeriod = previousPoll, previousGrace
+	})
+}
+
+func standalone(id string, labels map[string]string) container.Summary {
+	return container.Summary{ID: id, Names: []string{"/" + id}, Image: "nginx:latest", ImageID: "sha256:old", Labels: labels}
+}
+
+func Test_update_updatesOutdatedContainers(t *testing.T) {
+	setFastHealthchecks(t)
+
+	env := &fakeEnvironment{
+		containers: []container.Summary{
+			standalone("outdated", nil),
+			standalone("uptodate", nil),
+			standalone("optedout", map[string]string{EnableLabel: "false"}),
+			standalone("task", map[string]string{consts.SwarmServiceIDLabel: "service"}),
+		},
+		statuses: map[string]images.Status{
+			"outdated": images.Outdated,
+			"uptodate": images.Updated,
+			"optedout": images.Outdated,
+			"task":     images.Outdated,
+		},
+		health: &container.Health{Status: container.Healthy},
+	}
+
+	run := update(context.Background(), env, &portainer.ImageUpdatePolicy{StackStrategy: portainer.ImageUpdateStackSkip}, nil)
+
+	assert.Empty(t, run.Error)
+	assert.Equal(t, []string{"outdated"}, env.recreated)
+	assert.Equal(t, []bool{true}, env.pulled)
+	require.Len(t, run.Results, 1)
+	assert.Equal(t, portainer.ImageUpdateResult{
+		ContainerName:   "outdated",
+		Image:           "nginx:latest",
+		PreviousImageID: "sha256:old",
+		ImageID:         "sha256:new",
+		Status:          portainer.ImageUpdateStatusUpdated,
+	}, run.Results[0])
+}
+
+func Test_update_rollsBackUnhealthyContainers(t *testing.T) {
+	setFastHealthchecks(t)
+
+	env := &fakeEnvironment{
+		containers: []container.Summary{standalone("web", nil)},
+		statuses:   map[string]images.Status{"web": images.Outdated},
+		health:     &container.Health{Status: container.Unhealthy},
+	}
+
+	run := update(context.Background(), env, &portainer.ImageUpdatePolicy{}, nil)
+
+	require.Len(t, run.Results, 1)
+	assert.Equal(t, portainer.ImageUpdateStatusRolledBack, run.Results[0].Status)
+	assert.NotEmpty(t, run.Results[0].Error)
+	assert.Equal(t, map[string]string{"nginx:latest": "sha256:old"}, env.tagged, "the tag should be moved back to the previous image")
+	assert.Equal(t, []string{"web", "web-new"}, env.recreated)
+	assert.Equal(t, []bool{true, false}, env.pulled, "the rollback should not pull the image")
+}
+
+func Test_update_timesOutStartingContainers(t *testing.T) {
+	setFastHealthchecks(t)
+
+	env := &fakeEnvironment{
+		containers: []container.Summary{standalone("web", nil)},
+		statuses:   map[string]images.Status{"web": images.Outdated},
+		health:     &container.Health{Status: container.Starting},
+	}
+
+	healthPollInterval = 200 * time.Millisecond
+
+	run := update(context.Background(), env, &portainer.ImageUpdatePolicy{HealthcheckTimeout: 1}, nil)
+
+	require.Len(t, run.Results, 1)
+	assert.Equal(t, portainer.ImageUpdateStatusRolledBack, run.Results[0].Status)
+}
+
+func Test_update_stacks(t *testing.T) {
+	setFastHealthchecks(t)
+
+	containers := []container.Summary{
+		standalone("web-1", map[string]string{consts.ComposeStackNameLabel: "web"}),
+		standalone("web-2", map[string]string{consts.ComposeStackNameLabel: "web"}),
+		standalone("external-1", map[string]string{consts.ComposeStackNameLabel: "external"}),
+	}
+	statuses := map[string]images.Status{"web-1": images.Outdated, "web-2": images.Outdated, "external-1": images.Outdated}
+	stacks := []portainer.Stack{{ID: 1, Name: "web"}}
+
+	t.Run("skip", func(t *testing.T) {
+		env := &fakeEnvironment{containers: containers, statuses: statuses}
+
+		run := update(context.Background(), env, &portainer.ImageUpdatePolicy{StackStrategy: portainer.ImageUpdateStackSkip}, stacks)
+
+		assert.Empty(t, env.recreated, "the containers of a stack should not be recreated one by one")
+		assert.Empty(t, env.redeployed)
+		require.Len(t, run.Results, 2)
+		assert.Equal(t, portainer.ImageUpdateStatusSkipped, run.Results[0].Status)
+		assert.Equal(t, portainer.ImageUpdateStatusSkipped, run.Results[1].Status)
+	})
+
+	t.Run("redeploy", func(t *testing.T) {
+		env := &fakeEnvironment{containers: containers, statuses: statuses}
+
+		run := update(context.Background(), env, &portainer.ImageUpdatePolicy{StackStrategy: portainer.ImageUpdateStackRedeploy}, stacks)
+
+		assert.Empty(t, env.recreated)
+		assert.Equal(t, []string{"web"}, env.redeployed, "the stack should be redeployed once")
+		require.Len(t, run.Results, 2)
+		assert.Equal(t, portainer.ImageUpdateResult{StackName: "web", Status: portainer.ImageUpdateStatusUpdated}, run.Results[0])
+		assert.Equal(t, "external", run.Results[1].StackName)
+		assert.Equal(t, portainer.ImageUpdateStatusSkipped, run.Results[1].Status, "stacks not managed by Portainer should be skipped")
+	})
+}
+
+func Test_update_labelSelector(t *testing.T) {
+	setFastHealthchecks(t)
+
+	env := &fakeEnvironment{
+		containers: []container.Summary{
+			standalone("selected", map[string]string{"autoupdate": "true", "tier": "front"}),
+			standalone("other-value", map[string]string{"autoupdate": "false", "tier": "front"}),
+			standalone("missing", map[string]string{"autoupdate": "true"}),
+		},
+		statuses: map[string]images.Status{"selected": images.Outdated, "other-value": images.Outdated, "missing": images.Outdated},
+	}
+
+	update(context.Background(), env, &portainer.ImageUpdatePolicy{LabelSelector: "autoupdate=true, tier"}, nil)
+
+	assert.Equal(t, []string{"selected"}, env.recreated)
+}
+
+func Test_AppendRun(t *testing.T) {
+	var runs []portainer.ImageUpdateRun
+	for i := range MaxRuns + 2 {
+		runs = AppendRun(runs, portainer.ImageUpdateRun{StartedAt: int64(i)})
+	}
+
+	require.Len(t, runs, MaxRuns)
+	assert.Equal(t, int64(MaxRuns+1), runs[0].StartedAt, "the most recent run should come first")
+}
+
+func Test_ValidatePolicy(t *testing.T) {
+	valid := portainer.ImageUpdatePolicy{Enabled: true, Interval: "6h", StackStrategy: portainer.ImageUpdateStackSkip, LabelSelector: "a=b,c"}
+	require.NoError(t, ValidatePolicy(&valid))
+
+	for name, update := range map[string]func(*portainer.ImageUpdatePolicy){
+		"invalid interval":  func(p *portainer.ImageUpdatePolicy) { p.Interval = "often" },
+		"short interval":    func(p *portainer.ImageUpdatePolicy) { p.Interval = "10s" },
+		"invalid strategy":  func(p *portainer.ImageUpdatePolicy) { p.StackStrategy = "ignore" },
+		"negative timeout":  func(p *portainer.ImageUpdatePolicy) { p.HealthcheckTimeout = -1 },
+		"invalid selectors": func(p *portainer.ImageUpdatePolicy) { p.LabelSelector = "a,=b" },
+	} {
+		policy := valid
+		update(&policy)
+
+		assert.Error(t, ValidatePolicy(&policy), name)
+	}
+}
+
+func Test_waitHealthy_stoppedContainer(t *testing.T) {
+	setFastHealthchecks(t)
+
+	env := &stoppedEnvironment{}
+
+	err := waitHealthy(context.Background(), env, "web", time.Second)
+	assert.Error(t, err)
+}
+
+type stoppedEnvironment struct {
+	fakeEnvironment
+}
+
+func (env *stoppedEnvironment) Inspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
+	return container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{State: &container.State{Running: false}}}, nil
+}
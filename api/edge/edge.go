@@ -56,8 +56,7 @@ type (
 
 		// Mount point for relative path
 		FilesystemPath string
-		// Used only for EE
-		// EnvVars is a list of environment variables to inject into the stack
+		// EnvVars is a list of environment variables to inject into the stack, resolved for the environment
 		EnvVars []portainer.Pair
 
 		// Used only for EE async edge agent
@@ -10,6 +10,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/http/proxy"
 	"github.com/portainer/portainer/api/http/proxy/factory"
 	"github.com/portainer/portainer/api/internal/registryutils"
@@ -150,6 +151,82 @@ func (manager *ComposeStackManager) Pull(ctx context.Context, stack *portainer.S
 	return errors.Wrap(err, "failed to pull images of the stack")
 }
 
+// WaitForHealthy waits until every service of the stack is running, and healthy when it has a healthcheck,
+// or has completed. It fails when a service fails or when the context is done
+func (manager *ComposeStackManager) WaitForHealthy(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	url, proxy, err := manager.fetchEndpointProxy(endpoint)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch environment proxy")
+	} else if proxy != nil {
+		defer proxy.Close()
+	}
+
+	result := manager.deployer.WaitForHealthy(ctx, libstack.Options{
+		Host:        url,
+		ProjectName: stack.Name,
+	})
+	if result.ErrorMsg != "" {
+		return errors.New(result.ErrorMsg)
+	}
+
+	return nil
+}
+
+// Plan returns the changes that Up would apply to the containers of the stack without applying them.
+// The stack environment variables are passed directly instead of through the stack.env file so that
+// nothing is written in the project folder of the stack.
+func (manager *ComposeStackManager) Plan(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint, options portainer.ComposePlanOptions) ([]portainer.ComposeServiceChange, error) {
+	url, proxy, err := manager.fetchEndpointProxy(endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch environment proxy")
+	} else if proxy != nil {
+		defer proxy.Close()
+	}
+
+	filePaths := options.FilePaths
+	if len(filePaths) == 0 {
+		filePaths = stackutils.GetStackFilePaths(stack, true)
+	}
+
+	env := make([]string, 0, len(stack.Env))
+	for _, envvar := range stack.Env {
+		env = append(env, envvar.Name+"="+envvar.Value)
+	}
+
+	plan, err := manager.deployer.Plan(ctx, filePaths, libstack.DeployOptions{
+		Options: libstack.Options{
+			WorkingDir: stack.ProjectPath,
+			// Candidate files can live outside of the project folder, relative paths must still resolve
+			// to the same location as during the deployment
+			ProjectDir:  filesystem.JoinPaths(stack.ProjectPath, path.Dir(stack.EntryPoint)),
+			Env:         env,
+			Host:        url,
+			ProjectName: stack.Name,
+		},
+		ForceRecreate: options.ForceRecreate,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to plan the stack deployment")
+	}
+
+	changes := make([]portainer.ComposeServiceChange, 0, len(plan.Services))
+	for _, service := range plan.Services {
+		change := portainer.ComposeServiceChange{
+			Service: service.Name,
+			Action:  string(service.Action),
+			Reasons: make([]string, 0, len(service.Reasons)),
+		}
+
+		for _, reason := range service.Reasons {
+			change.Reasons = append(change.Reasons, string(reason))
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
 // NormalizeStackName returns a new stack name with unsupported characters replaced
 func (manager *ComposeStackManager) NormalizeStackName(name string) string {
 	return stackNameNormalizeRegex.ReplaceAllString(strings.ToLower(name), "")
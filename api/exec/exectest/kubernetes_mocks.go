@@ -24,3 +24,7 @@ func (deployer *kubernetesMockDeployer) Remove(userID portainer.UserID, endpoint
 func (deployer *kubernetesMockDeployer) Restart(userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
 	return "", nil
 }
+
+func (deployer *kubernetesMockDeployer) SetImage(userID portainer.UserID, endpoint *portainer.Endpoint, resource string, images map[string]string, namespace string) (string, error) {
+	return "", nil
+}
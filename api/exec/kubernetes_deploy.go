@@ -85,7 +85,47 @@ func (deployer *KubernetesDeployer) Remove(userID portainer.UserID, endpoint *po
 	return deployer.command("delete", userID, endpoint, resources, namespace)
 }
 
+// Restart triggers a rollout restart of the Kubernetes resources
+func (deployer *KubernetesDeployer) Restart(userID portainer.UserID, endpoint *portainer.Endpoint, resources []string, namespace string) (string, error) {
+	return deployer.command("restart", userID, endpoint, resources, namespace)
+}
+
+// SetImage updates the image of the containers of a Kubernetes resource, images maps the container names to their new image
+func (deployer *KubernetesDeployer) SetImage(userID portainer.UserID, endpoint *portainer.Endpoint, resource string, images map[string]string, namespace string) (string, error) {
+	output, err := deployer.withClient(userID, endpoint, namespace, func(client *libkubectl.Client) (string, error) {
+		return client.SetImage(context.Background(), resource, images)
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to execute kubectl set image command")
+	}
+
+	return output, nil
+}
+
 func (deployer *KubernetesDeployer) command(operation string, userID portainer.UserID, endpoint *portainer.Endpoint, resources []string, namespace string) (string, error) {
+	output, err := deployer.withClient(userID, endpoint, namespace, func(client *libkubectl.Client) (string, error) {
+		operations := map[string]func(context.Context, []string) (string, error){
+			"apply":   client.Apply,
+			"delete":  client.Delete,
+			"restart": client.RolloutRestart,
+		}
+
+		operationFunc, ok := operations[operation]
+		if !ok {
+			return "", errors.Errorf("unsupported operation: %s", operation)
+		}
+
+		return operationFunc(context.Background(), resources)
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to execute kubectl %s command", operation)
+	}
+
+	return output, nil
+}
+
+// withClient runs fn with a kubectl client authenticated as the user against the environment
+func (deployer *KubernetesDeployer) withClient(userID portainer.UserID, endpoint *portainer.Endpoint, namespace string, fn func(client *libkubectl.Client) (string, error)) (string, error) {
 	token, err := deployer.getToken(userID, endpoint, endpoint.Type == portainer.KubernetesLocalEnvironment)
 	if err != nil {
 		return "", errors.Wrap(err, "failed generating a user token")
@@ -110,22 +150,7 @@ func (deployer *KubernetesDeployer) command(operation string, userID portainer.U
 		return "", errors.Wrap(err, "failed to create kubectl client")
 	}
 
-	operations := map[string]func(context.Context, []string) (string, error){
-		"apply":  client.Apply,
-		"delete": client.Delete,
-	}
-
-	operationFunc, ok := operations[operation]
-	if !ok {
-		return "", errors.Errorf("unsupported operation: %s", operation)
-	}
-
-	output, err := operationFunc(context.Background(), resources)
-	if err != nil {
-		return "", errors.Wrapf(err, "failed to execute kubectl %s command", operation)
-	}
-
-	return output, nil
+	return fn(client)
 }
 
 func (deployer *KubernetesDeployer) getAgentURL(endpoint *portainer.Endpoint) (string, *factory.ProxyServer, error) {
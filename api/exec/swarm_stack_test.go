@@ -3,13 +3,24 @@ package exec
 import (
 	"testing"
 
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/pkg/libstack/swarm"
+
+	"github.com/docker/cli/cli/config/types"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestConfigFilePaths(t *testing.T) {
-	args := []string{"stack", "deploy", "--with-registry-auth"}
-	filePaths := []string{"dir/file", "dir/file-two", "dir/file-three"}
-	expected := []string{"stack", "deploy", "--with-registry-auth", "--compose-file", "dir/file", "--compose-file", "dir/file-two", "--compose-file", "dir/file-three"}
-	output := configureFilePaths(args, filePaths)
-	assert.ElementsMatch(t, expected, output, "wrong output file paths")
+func TestSwarmStackManagerLoginLogout(t *testing.T) {
+	manager := NewSwarmStackManager(swarm.NewSwarmDeployer(), nil, nil)
+	endpoint := &portainer.Endpoint{ID: 1}
+
+	registries := []portainer.Registry{{URL: "registry.example.com", Username: "user", Password: "pass"}}
+
+	err := manager.Login(registries, endpoint)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.AuthConfig{{ServerAddress: "registry.example.com", Username: "user", Password: "pass"}}, manager.registries[endpoint.ID])
+
+	err = manager.Logout(endpoint)
+	assert.NoError(t, err)
+	assert.NotContains(t, manager.registries, endpoint.ID)
 }
@@ -2,6 +2,7 @@ package filesystem
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -41,6 +42,8 @@ const (
 	PrivateKeyFile = "portainer.key"
 	// PublicKeyFile represents the name on disk of the file containing the public key.
 	PublicKeyFile = "portainer.pub"
+	// SecretKeyFile represents the name on disk of the file containing the key encrypting the secrets stored in the database.
+	SecretKeyFile = "secret.key"
 	// BinaryStorePath represents the subfolder where binaries are stored in the file store folder.
 	BinaryStorePath = "bin"
 	// EdgeJobStorePath represents the subfolder where schedule files are stored.
@@ -52,6 +55,10 @@ const (
 	ExtensionRegistryManagementStorePath = "extensions"
 	// CustomTemplateStorePath represents the subfolder where custom template files are stored in the file store folder.
 	CustomTemplateStorePath = "custom_templates"
+	// TemplateSourceStorePath represents the subfolder where the last good copy of the templates of each source is stored.
+	TemplateSourceStorePath = "template_sources"
+	// TemplateSourceFileName represents the name of the file holding the last good copy of the templates of a source.
+	TemplateSourceFileName = "templates.json"
 	// TempPath represent the subfolder where temporary files are saved
 	TempPath = "tmp"
 	// SSLCertPath represents the default ssl certificates path
@@ -73,6 +80,11 @@ const (
 	ChiselPrivateKeyFilename = "private-key.pem"
 )
 
+const (
+	secretKeySize      = 32
+	secretKeyPEMHeader = "SECRET KEY"
+)
+
 // ErrUndefinedTLSFileType represents an error returned on undefined TLS file type
 var ErrUndefinedTLSFileType = errors.New("Undefined TLS file type")
 
@@ -656,6 +668,39 @@ func (service *Service) LoadKeyPair() ([]byte, []byte, error) {
 	return privateKey, publicKey, nil
 }
 
+// LoadSecretKey retrieves the key encrypting the secrets stored in the database, it is generated on first use.
+// The key is part of the backups so that the secrets remain readable once restored on another host.
+func (service *Service) LoadSecretKey() ([]byte, error) {
+	exists, err := service.FileExists(JoinPaths(service.dataStorePath, SecretKeyFile))
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		key, err := service.getContentFromPEMFile(SecretKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(key) != secretKeySize {
+			return nil, errors.New("invalid secret key file")
+		}
+
+		return key, nil
+	}
+
+	key := make([]byte, secretKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	if err := service.createPEMFileInStore(key, secretKeyPEMHeader, SecretKeyFile); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
 // createDirectoryInStore creates a new directory in the file store
 func (service *Service) createDirectoryInStore(name string) error {
 	path := service.wrapFileStore(name)
@@ -716,6 +761,10 @@ func (service *Service) getContentFromPEMFile(filePath string) ([]byte, error) {
 	}
 
 	block, _ := pem.Decode(fileContent)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM file %s", filePath)
+	}
+
 	return block.Bytes, nil
 }
 
@@ -745,6 +794,27 @@ func (service *Service) StoreCustomTemplateFileFromBytes(identifier, fileName st
 	return service.wrapFileStore(customTemplateStorePath), nil
 }
 
+// GetTemplateSourcePath returns the absolute path on the filesystem of the folder holding the last good copy of the
+// templates of a source.
+func (service *Service) GetTemplateSourcePath(identifier string) string {
+	return JoinPaths(service.wrapFileStore(TemplateSourceStorePath), identifier)
+}
+
+// StoreTemplateSourceFileFromBytes creates a subfolder in the TemplateSourceStorePath and stores the last good copy of
+// the templates of a source. It returns the path to the folder where the file is stored.
+func (service *Service) StoreTemplateSourceFileFromBytes(identifier string, data []byte) (string, error) {
+	templateSourceStorePath := JoinPaths(TemplateSourceStorePath, identifier)
+	if err := service.createDirectoryInStore(templateSourceStorePath); err != nil {
+		return "", err
+	}
+
+	if err := service.createFileInStore(JoinPaths(templateSourceStorePath, TemplateSourceFileName), bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+
+	return service.wrapFileStore(templateSourceStorePath), nil
+}
+
 // GetEdgeJobFolder returns the absolute path on the filesystem for an Edge job based
 // on its identifier.
 func (service *Service) GetEdgeJobFolder(identifier string) string {
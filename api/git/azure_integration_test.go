@@ -21,7 +21,7 @@ func TestService_ClonePublicRepository_Azure(t *testing.T) {
 	ensureIntegrationTest(t)
 
 	pat := getRequiredValue(t, "AZURE_DEVOPS_PAT")
-	service := NewService(context.TODO())
+	service := NewService(context.TODO(), nil)
 
 	type args struct {
 		repositoryURLFormat string
@@ -78,7 +78,7 @@ func TestService_ClonePrivateRepository_Azure(t *testing.T) {
 	ensureIntegrationTest(t)
 
 	pat := getRequiredValue(t, "AZURE_DEVOPS_PAT")
-	service := NewService(context.TODO())
+	service := NewService(context.TODO(), nil)
 
 	dst := t.TempDir()
 
@@ -99,7 +99,7 @@ func TestService_LatestCommitID_Azure(t *testing.T) {
 	ensureIntegrationTest(t)
 
 	pat := getRequiredValue(t, "AZURE_DEVOPS_PAT")
-	service := NewService(context.TODO())
+	service := NewService(context.TODO(), nil)
 
 	id, err := service.LatestCommitID(
 		privateAzureRepoURL,
@@ -118,7 +118,7 @@ func TestService_ListRefs_Azure(t *testing.T) {
 
 	accessToken := getRequiredValue(t, "AZURE_DEVOPS_PAT")
 	username := getRequiredValue(t, "AZURE_DEVOPS_USERNAME")
-	service := NewService(context.TODO())
+	service := NewService(context.TODO(), nil)
 
 	refs, err := service.ListRefs(
 		privateAzureRepoURL,
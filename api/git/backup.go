@@ -25,7 +25,7 @@ type CloneOptions struct {
 }
 
 func CloneWithBackup(gitService portainer.GitService, fileService portainer.FileService, options CloneOptions) (clean func(), err error) {
-	backupProjectPath := options.ProjectPath + "-old"
+	backupProjectPath := backupPath(options.ProjectPath)
 	cleanUp := false
 	cleanFn := func() {
 		if !cleanUp {
@@ -66,3 +66,13 @@ func CloneWithBackup(gitService portainer.GitService, fileService portainer.File
 
 	return cleanFn, nil
 }
+
+// RestoreBackup replaces the clone made by CloneWithBackup with the backup of the previous project folder, it must be
+// called before the backup is cleaned
+func RestoreBackup(options CloneOptions) error {
+	return filesystem.MoveDirectory(backupPath(options.ProjectPath), options.ProjectPath, true)
+}
+
+func backupPath(projectPath string) string {
+	return projectPath + "-old"
+}
@@ -4,10 +4,44 @@ import (
 	gittypes "github.com/portainer/portainer/api/git/types"
 )
 
-func GetCredentials(auth *gittypes.GitAuthentication) (string, string, error) {
+// CredentialStore resolves the stored Git credentials referenced by a GitCredentialID
+type CredentialStore interface {
+	// GitAuthentication returns the credential as it must be handed to the GitService
+	GitAuthentication(credentialID int) (*gittypes.GitAuthentication, error)
+}
+
+// AuthenticationResolver resolves the stored credential referenced by the authentication of a repository,
+// it is implemented by the GitService
+type AuthenticationResolver interface {
+	ResolveAuthentication(auth *gittypes.GitAuthentication) (*gittypes.GitAuthentication, error)
+}
+
+// ResolveAuthentication returns the authentication to use for a repository, the stored credential
+// is returned when auth references one
+func (service *Service) ResolveAuthentication(auth *gittypes.GitAuthentication) (*gittypes.GitAuthentication, error) {
+	if auth == nil || auth.GitCredentialID == 0 {
+		return auth, nil
+	}
+
+	if service.credentialStore == nil {
+		return nil, ErrInvalidGitCredential
+	}
+
+	return service.credentialStore.GitAuthentication(auth.GitCredentialID)
+}
+
+// GetCredentials returns the username, the password and the authorization type to hand to the GitService
+func GetCredentials(resolver AuthenticationResolver, auth *gittypes.GitAuthentication) (string, string, gittypes.GitCredentialAuthType, error) {
+	if auth != nil && auth.GitCredentialID != 0 {
+		var err error
+		if auth, err = resolver.ResolveAuthentication(auth); err != nil {
+			return "", "", gittypes.GitCredentialAuthType_Basic, err
+		}
+	}
+
 	if auth == nil {
-		return "", "", nil
+		return "", "", gittypes.GitCredentialAuthType_Basic, nil
 	}
 
-	return auth.Username, auth.Password, nil
+	return auth.Username, auth.Password, auth.AuthorizationType, nil
 }
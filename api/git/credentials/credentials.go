@@ -0,0 +1,180 @@
+// Package credentials stores the Git credentials of the users, encrypting their secrets at rest,
+// and resolves the credentials referenced by the Git configurations
+package credentials
+
+import (
+	"errors"
+	"fmt"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/dataservices"
+	gittypes "github.com/portainer/portainer/api/git/types"
+)
+
+const keyInfo = "portainer git credentials"
+
+var (
+	// ErrUnauthorized is returned when a user references a Git credential they do not own
+	ErrUnauthorized = errors.New("the Git credential does not exist or does not belong to the user")
+	// ErrCredentialInUse is returned when deleting a Git credential still referenced by a Git configuration
+	ErrCredentialInUse = errors.New("the Git credential is still used by a stack, an Edge stack or a custom template")
+)
+
+// Service stores the Git credentials with their secrets encrypted
+type Service struct {
+	dataStore dataservices.DataStore
+	cipher    *crypto.SecretCipher
+}
+
+// NewService creates a service encrypting the secrets with a key derived from secretKey, the secret key of the
+// instance returned by FileService.LoadSecretKey
+func NewService(dataStore dataservices.DataStore, secretKey []byte) (*Service, error) {
+	cipher, err := crypto.NewSecretCipher(secretKey, keyInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{dataStore: dataStore, cipher: cipher}, nil
+}
+
+// Create encrypts the secrets of the credential and saves it, the credential is left unchanged apart from its ID
+func (service *Service) Create(credential *portainer.GitCredential) error {
+	encrypted, err := service.encryptCredential(credential)
+	if err != nil {
+		return err
+	}
+
+	if err := service.dataStore.GitCredential().Create(encrypted); err != nil {
+		return err
+	}
+
+	credential.ID = encrypted.ID
+
+	return nil
+}
+
+// Update encrypts the secrets of the credential and saves it
+func (service *Service) Update(credential *portainer.GitCredential) error {
+	encrypted, err := service.encryptCredential(credential)
+	if err != nil {
+		return err
+	}
+
+	return service.dataStore.GitCredential().Update(credential.ID, encrypted)
+}
+
+// Read returns the credential with its secrets decrypted
+func (service *Service) Read(id portainer.GitCredentialID) (*portainer.GitCredential, error) {
+	credential, err := service.dataStore.GitCredential().Read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.decryptCredential(credential)
+}
+
+// GitAuthentication returns the credential as it must be handed to the GitService, it implements git.CredentialStore
+func (service *Service) GitAuthentication(credentialID int) (*gittypes.GitAuthentication, error) {
+	credential, err := service.Read(portainer.GitCredentialID(credentialID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the Git credential %d: %w", credentialID, err)
+	}
+
+	return Authentication(credential), nil
+}
+
+// Authentication converts a decrypted credential to the authentication handed to the GitService
+func Authentication(credential *portainer.GitCredential) *gittypes.GitAuthentication {
+	auth := &gittypes.GitAuthentication{
+		Username:          credential.Username,
+		Password:          credential.Password,
+		AuthorizationType: credential.AuthorizationType,
+		GitCredentialID:   int(credential.ID),
+	}
+
+	if credential.AuthorizationType == gittypes.GitCredentialAuthType_SSH {
+		auth.Password = gittypes.SSHCredential{
+			PrivateKey: credential.SSHPrivateKey,
+			Passphrase: credential.SSHPassphrase,
+			KnownHosts: credential.SSHKnownHosts,
+		}.Encode()
+	}
+
+	return auth
+}
+
+// ValidateAccess checks that the credential exists and belongs to the user, it is a no-op when credentialID is 0
+func ValidateAccess(dataStore dataservices.DataStoreTx, credentialID int, userID portainer.UserID) error {
+	if credentialID == 0 {
+		return nil
+	}
+
+	credential, err := dataStore.GitCredential().Read(portainer.GitCredentialID(credentialID))
+	if dataStore.IsErrObjectNotFound(err) {
+		return ErrUnauthorized
+	} else if err != nil {
+		return err
+	}
+
+	if credential.UserID != userID {
+		return ErrUnauthorized
+	}
+
+	return nil
+}
+
+// IsInUse returns true when the Git configuration of a stack, a custom template or a template source references the credential
+func IsInUse(tx dataservices.DataStoreTx, credentialID portainer.GitCredentialID) (bool, error) {
+	stacks, err := tx.Stack().ReadAll(func(stack portainer.Stack) bool {
+		return references(stack.GitConfig, credentialID)
+	})
+	if err != nil || len(stacks) > 0 {
+		return len(stacks) > 0, err
+	}
+
+	customTemplates, err := tx.CustomTemplate().ReadAll(func(customTemplate portainer.CustomTemplate) bool {
+		return references(customTemplate.GitConfig, credentialID)
+	})
+	if err != nil || len(customTemplates) > 0 {
+		return len(customTemplates) > 0, err
+	}
+
+	sources, err := tx.TemplateSource().ReadAll(func(source portainer.TemplateSource) bool {
+		return references(source.Git, credentialID)
+	})
+
+	return len(sources) > 0, err
+}
+
+func references(config *gittypes.RepoConfig, credentialID portainer.GitCredentialID) bool {
+	return config != nil && config.Authentication != nil && config.Authentication.GitCredentialID == int(credentialID)
+}
+
+func (service *Service) encryptCredential(credential *portainer.GitCredential) (*portainer.GitCredential, error) {
+	encrypted := *credential
+
+	for _, field := range secretFields(&encrypted) {
+		var err error
+		if *field, err = service.cipher.Encrypt(*field); err != nil {
+			return nil, err
+		}
+	}
+
+	return &encrypted, nil
+}
+
+func (service *Service) decryptCredential(credential *portainer.GitCredential) (*portainer.GitCredential, error) {
+	for _, field := range secretFields(credential) {
+		var err error
+		if *field, err = service.cipher.Decrypt(*field); err != nil {
+			return nil, fmt.Errorf("unable to decrypt the Git credential: %w", err)
+		}
+	}
+
+	return credential, nil
+}
+
+func secretFields(credential *portainer.GitCredential) []*string {
+	return []*string{&credential.Password, &credential.SSHPrivateKey, &credential.SSHPassphrase}
+}
@@ -0,0 +1,128 @@
+package credentials
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/git"
+	gittypes "github.com/portainer/portainer/api/git/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CredentialsAreEncryptedAtRest(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	service, err := NewService(store, []byte("instance secret key"))
+	require.NoError(t, err)
+
+	credential := &portainer.GitCredential{
+		UserID:            1,
+		Name:              "github",
+		AuthorizationType: gittypes.GitCredentialAuthType_Token,
+		Password:          "ghp_token",
+	}
+	require.NoError(t, service.Create(credential))
+	assert.Equal(t, "ghp_token", credential.Password, "the credential of the caller must be left unchanged")
+
+	stored, err := store.GitCredential().Read(credential.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, stored.Password)
+	assert.NotContains(t, stored.Password, "ghp_token")
+
+	read, err := service.Read(credential.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_token", read.Password)
+
+	otherService, err := NewService(store, []byte("another secret key"))
+	require.NoError(t, err)
+
+	_, err = otherService.Read(credential.ID)
+	assert.Error(t, err, "the credential must not be readable with another key")
+}
+
+func Test_GitAuthentication(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	service, err := NewService(store, []byte("instance secret key"))
+	require.NoError(t, err)
+
+	basic := &portainer.GitCredential{
+		UserID:            1,
+		Name:              "basic",
+		AuthorizationType: gittypes.GitCredentialAuthType_Basic,
+		Username:          "user",
+		Password:          "password",
+	}
+	require.NoError(t, service.Create(basic))
+
+	sshCredential := &portainer.GitCredential{
+		UserID:            1,
+		Name:              "deploy-key",
+		AuthorizationType: gittypes.GitCredentialAuthType_SSH,
+		SSHPrivateKey:     "private key",
+		SSHPassphrase:     "passphrase",
+		SSHKnownHosts:     "github.com ssh-ed25519 AAAA",
+	}
+	require.NoError(t, service.Create(sshCredential))
+
+	gitService := git.NewService(t.Context(), service)
+
+	username, password, authType, err := git.GetCredentials(gitService, &gittypes.GitAuthentication{GitCredentialID: int(basic.ID)})
+	require.NoError(t, err)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "password", password)
+	assert.Equal(t, gittypes.GitCredentialAuthType_Basic, authType)
+
+	_, password, authType, err = git.GetCredentials(gitService, &gittypes.GitAuthentication{GitCredentialID: int(sshCredential.ID)})
+	require.NoError(t, err)
+	assert.Equal(t, gittypes.GitCredentialAuthType_SSH, authType)
+
+	decoded, err := gittypes.DecodeSSHCredential(password)
+	require.NoError(t, err)
+	assert.Equal(t, gittypes.SSHCredential{
+		PrivateKey: "private key",
+		Passphrase: "passphrase",
+		KnownHosts: "github.com ssh-ed25519 AAAA",
+	}, decoded)
+
+	_, _, _, err = git.GetCredentials(gitService, &gittypes.GitAuthentication{GitCredentialID: 42})
+	assert.Error(t, err)
+
+	_, _, _, err = git.GetCredentials(git.NewService(t.Context(), nil), &gittypes.GitAuthentication{GitCredentialID: int(basic.ID)})
+	assert.ErrorIs(t, err, git.ErrInvalidGitCredential)
+}
+
+func Test_IsInUse(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	gitConfig := &gittypes.RepoConfig{URL: "https://github.com/portainer/portainer", Authentication: &gittypes.GitAuthentication{GitCredentialID: 1}}
+
+	require.NoError(t, store.Stack().Create(&portainer.Stack{ID: 1, Name: "stack", GitConfig: gitConfig}))
+	require.NoError(t, store.CustomTemplate().Create(&portainer.CustomTemplate{ID: 1, GitConfig: &gittypes.RepoConfig{Authentication: &gittypes.GitAuthentication{GitCredentialID: 2}}}))
+	require.NoError(t, store.TemplateSource().Create(&portainer.TemplateSource{Name: "git", Git: &gittypes.RepoConfig{Authentication: &gittypes.GitAuthentication{GitCredentialID: 3}}}))
+
+	for credentialID, expected := range map[portainer.GitCredentialID]bool{1: true, 2: true, 3: true, 4: false} {
+		require.NoError(t, store.ViewTx(func(tx dataservices.DataStoreTx) error {
+			inUse, err := IsInUse(tx, credentialID)
+			require.NoError(t, err)
+			assert.Equal(t, expected, inUse, "credential %d", credentialID)
+
+			return nil
+		}))
+	}
+}
+
+func Test_ValidateAccess(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	require.NoError(t, store.GitCredential().Create(&portainer.GitCredential{UserID: 1, Name: "github"}))
+
+	require.NoError(t, ValidateAccess(store, 0, 2))
+	require.NoError(t, ValidateAccess(store, 1, 1))
+	require.ErrorIs(t, ValidateAccess(store, 1, 2), ErrUnauthorized)
+	require.ErrorIs(t, ValidateAccess(store, 2, 1), ErrUnauthorized)
+}
@@ -31,11 +31,16 @@ func NewGitClient(preserveGitDir bool) *gitClient {
 }
 
 func (c *gitClient) download(ctx context.Context, dst string, opt cloneOption) error {
+	auth, err := getAuth(opt.authType, opt.username, opt.password)
+	if err != nil {
+		return err
+	}
+
 	gitOptions := git.CloneOptions{
 		URL:             opt.repositoryUrl,
 		Depth:           opt.depth,
 		InsecureSkipTLS: opt.tlsSkipVerify,
-		Auth:            getAuth(opt.authType, opt.username, opt.password),
+		Auth:            auth,
 		Tags:            git.NoTags,
 	}
 
@@ -43,7 +48,7 @@ func (c *gitClient) download(ctx context.Context, dst string, opt cloneOption) e
 		gitOptions.ReferenceName = plumbing.ReferenceName(opt.referenceName)
 	}
 
-	_, err := git.PlainCloneContext(ctx, dst, false, &gitOptions)
+	_, err = git.PlainCloneContext(ctx, dst, false, &gitOptions)
 
 	if err != nil {
 		if err.Error() == "authentication required" {
@@ -68,8 +73,13 @@ func (c *gitClient) latestCommitID(ctx context.Context, opt fetchOption) (string
 		URLs: []string{opt.repositoryUrl},
 	})
 
+	auth, err := getAuth(opt.authType, opt.username, opt.password)
+	if err != nil {
+		return "", err
+	}
+
 	listOptions := &git.ListOptions{
-		Auth:            getAuth(opt.authType, opt.username, opt.password),
+		Auth:            auth,
 		InsecureSkipTLS: opt.tlsSkipVerify,
 	}
 
@@ -99,19 +109,21 @@ func (c *gitClient) latestCommitID(ctx context.Context, opt fetchOption) (string
 	return "", errors.Errorf("could not find ref %q in the repository", opt.referenceName)
 }
 
-func getAuth(authType gittypes.GitCredentialAuthType, username, password string) transport.AuthMethod {
+func getAuth(authType gittypes.GitCredentialAuthType, username, password string) (transport.AuthMethod, error) {
 	if password == "" {
-		return nil
+		return nil, nil
 	}
 
 	switch authType {
 	case gittypes.GitCredentialAuthType_Basic:
-		return getBasicAuth(username, password)
+		return getBasicAuth(username, password), nil
 	case gittypes.GitCredentialAuthType_Token:
-		return getTokenAuth(password)
+		return getTokenAuth(password), nil
+	case gittypes.GitCredentialAuthType_SSH:
+		return getSSHAuth(username, password)
 	default:
 		log.Warn().Msg("unknown git credentials authorization type, defaulting to None")
-		return nil
+		return nil, nil
 	}
 }
 
@@ -144,8 +156,13 @@ func (c *gitClient) listRefs(ctx context.Context, opt baseOption) ([]string, err
 		URLs: []string{opt.repositoryUrl},
 	})
 
+	auth, err := getAuth(opt.authType, opt.username, opt.password)
+	if err != nil {
+		return nil, err
+	}
+
 	listOptions := &git.ListOptions{
-		Auth:            getAuth(opt.authType, opt.username, opt.password),
+		Auth:            auth,
 		InsecureSkipTLS: opt.tlsSkipVerify,
 	}
 
@@ -167,13 +184,18 @@ func (c *gitClient) listRefs(ctx context.Context, opt baseOption) ([]string, err
 
 // listFiles list all filenames under the specific repository
 func (c *gitClient) listFiles(ctx context.Context, opt fetchOption) ([]string, error) {
+	auth, err := getAuth(opt.authType, opt.username, opt.password)
+	if err != nil {
+		return nil, err
+	}
+
 	cloneOption := &git.CloneOptions{
 		URL:             opt.repositoryUrl,
 		NoCheckout:      true,
 		Depth:           1,
 		SingleBranch:    true,
 		ReferenceName:   plumbing.ReferenceName(opt.referenceName),
-		Auth:            getAuth(opt.authType, opt.username, opt.password),
+		Auth:            auth,
 		InsecureSkipTLS: opt.tlsSkipVerify,
 		Tags:            git.NoTags,
 	}
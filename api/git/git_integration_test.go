@@ -287,7 +287,7 @@ func TestService_purgeCache_Github(t *testing.T) {
 	repositoryUrl := privateGitRepoURL
 	accessToken := getRequiredValue(t, "GITHUB_PAT")
 	username := getRequiredValue(t, "GITHUB_USERNAME")
-	service := NewService(context.TODO())
+	service := NewService(context.TODO(), nil)
 
 	service.ListRefs(repositoryUrl, username, accessToken, gittypes.GitCredentialAuthType_Basic, false, false)
 	service.ListFiles(
@@ -346,7 +346,7 @@ func TestService_canStopCacheCleanTimer_whenContextDone(t *testing.T) {
 	deadlineCtx, cancel := context.WithDeadline(context.TODO(), time.Now().Add(10*timeout))
 	defer cancel()
 
-	service := NewService(deadlineCtx)
+	service := NewService(deadlineCtx, nil)
 	assert.False(t, service.timerHasStopped(), "timer should not be stopped")
 
 	<-time.After(20 * timeout)
@@ -49,11 +49,12 @@ type repoManager interface {
 
 // Service represents a service for managing Git.
 type Service struct {
-	shutdownCtx  context.Context
-	azure        repoManager
-	git          repoManager
-	timerStopped bool
-	mut          sync.Mutex
+	shutdownCtx     context.Context
+	credentialStore CredentialStore
+	azure           repoManager
+	git             repoManager
+	timerStopped    bool
+	mut             sync.Mutex
 
 	cacheEnabled bool
 	// Cache the result of repository refs, key is repository URL
@@ -62,9 +63,12 @@ type Service struct {
 	repoFileCache *lru.Cache
 }
 
-// NewService initializes a new service.
-func NewService(ctx context.Context) *Service {
-	return newService(ctx, repositoryCacheSize, repositoryCacheTTL)
+// NewService initializes a new service, credentialStore resolves the stored credentials referenced by the repositories.
+func NewService(ctx context.Context, credentialStore CredentialStore) *Service {
+	service := newService(ctx, repositoryCacheSize, repositoryCacheTTL)
+	service.credentialStore = credentialStore
+
+	return service
 }
 
 func newService(ctx context.Context, cacheSize int, cacheTTL time.Duration) *Service {
@@ -154,7 +158,8 @@ func (service *Service) CloneRepository(
 func (service *Service) repoManager(options baseOption) repoManager {
 	repoManager := service.git
 
-	if isAzureUrl(options.repositoryUrl) {
+	// The Azure client relies on the HTTPS API with a token, SSH keys can only be used by the generic client
+	if isAzureUrl(options.repositoryUrl) && options.authType != gittypes.GitCredentialAuthType_SSH {
 		repoManager = service.azure
 	}
 
@@ -0,0 +1,88 @@
+package git
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	gittypes "github.com/portainer/portainer/api/git/types"
+
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const defaultSSHUser = "git"
+
+// scpLikeURL matches the scp-like syntax of SSH repository URLs, such as git@github.com:portainer/portainer.git
+var scpLikeURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:.+$`)
+
+// IsSSHURL returns true when the repository URL designates a repository served over SSH
+func IsSSHURL(url string) bool {
+	if strings.HasPrefix(url, "ssh://") {
+		return true
+	}
+
+	return !strings.Contains(url, "://") && scpLikeURL.MatchString(url)
+}
+
+func getSSHAuth(username, encodedCredential string) (*gitssh.PublicKeys, error) {
+	credential, err := gittypes.DecodeSSHCredential(encodedCredential)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid SSH credential")
+	}
+
+	if username == "" {
+		username = defaultSSHUser
+	}
+
+	auth, err := gitssh.NewPublicKeys(username, []byte(credential.PrivateKey), credential.Passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the SSH private key")
+	}
+
+	// The host keys are always pinned, the known hosts of the Portainer host are never trusted implicitly
+	if credential.KnownHosts == "" {
+		return nil, errors.New("the known hosts of the SSH server are required")
+	}
+
+	if auth.HostKeyCallback, err = knownHostsCallback(credential.KnownHosts); err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+// knownHostsCallback returns a callback accepting only the host keys listed in knownHosts
+func knownHostsCallback(knownHosts string) (ssh.HostKeyCallback, error) {
+	// knownhosts only reads files, the content is fully loaded when the callback is created
+	f, err := os.CreateTemp("", "portainer-known-hosts-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(knownHosts)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := knownhosts.New(f.Name())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid known hosts")
+	}
+
+	return callback, nil
+}
+
+// ValidateSSHCredential checks that the private key can be used with its passphrase and that the known hosts can be parsed
+func ValidateSSHCredential(credential gittypes.SSHCredential) error {
+	_, err := getSSHAuth("", credential.Encode())
+
+	return err
+}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	gittypes "github.com/portainer/portainer/api/git/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func generatePrivateKey(t *testing.T, passphrase string) (string, ssh.PublicKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(private, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(private, "", []byte(passphrase))
+	}
+	require.NoError(t, err)
+
+	sshPublic, err := ssh.NewPublicKey(public)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(block)), sshPublic
+}
+
+func Test_IsSSHURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{url: "ssh://git@github.com/portainer/portainer.git", expected: true},
+		{url: "git@github.com:portainer/portainer.git", expected: true},
+		{url: "deploy@git.example.com:team/repo", expected: true},
+		{url: "https://github.com/portainer/portainer.git", expected: false},
+		{url: "https://user@github.com:443/portainer/portainer.git", expected: false},
+		{url: "github.com:portainer/portainer.git", expected: false},
+		{url: "/srv/repositories/portainer.git", expected: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, IsSSHURL(tt.url), tt.url)
+	}
+}
+
+func knownHosts(t *testing.T) string {
+	_, hostKey := generatePrivateKey(t, "")
+
+	return "git.example.com " + string(ssh.MarshalAuthorizedKey(hostKey))
+}
+
+func Test_getSSHAuth(t *testing.T) {
+	t.Run("defaults the user to git", func(t *testing.T) {
+		privateKey, _ := generatePrivateKey(t, "")
+
+		auth, err := getSSHAuth("", gittypes.SSHCredential{PrivateKey: privateKey, KnownHosts: knownHosts(t)}.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, "git", auth.User)
+	})
+
+	t.Run("decrypts the private key with its passphrase", func(t *testing.T) {
+		privateKey, public := generatePrivateKey(t, "secret")
+
+		auth, err := getSSHAuth("deploy", gittypes.SSHCredential{PrivateKey: privateKey, Passphrase: "secret", KnownHosts: knownHosts(t)}.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, "deploy", auth.User)
+		assert.Equal(t, public.Marshal(), auth.Signer.PublicKey().Marshal())
+
+		_, err = getSSHAuth("deploy", gittypes.SSHCredential{PrivateKey: privateKey, Passphrase: "wrong", KnownHosts: knownHosts(t)}.Encode())
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects an invalid private key", func(t *testing.T) {
+		err := ValidateSSHCredential(gittypes.SSHCredential{PrivateKey: "not a key", KnownHosts: knownHosts(t)})
+		assert.Error(t, err)
+	})
+
+	t.Run("requires the known hosts", func(t *testing.T) {
+		privateKey, _ := generatePrivateKey(t, "")
+
+		err := ValidateSSHCredential(gittypes.SSHCredential{PrivateKey: privateKey})
+		assert.Error(t, err)
+	})
+
+	t.Run("pins the known hosts", func(t *testing.T) {
+		privateKey, _ := generatePrivateKey(t, "")
+		_, hostKey := generatePrivateKey(t, "")
+		_, otherHostKey := generatePrivateKey(t, "")
+
+		knownHosts := "git.example.com " + string(ssh.MarshalAuthorizedKey(hostKey))
+
+		auth, err := getSSHAuth("", gittypes.SSHCredential{PrivateKey: privateKey, KnownHosts: knownHosts}.Encode())
+		require.NoError(t, err)
+
+		addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+
+		require.NoError(t, auth.HostKeyCallback("git.example.com:22", addr, hostKey))
+		require.Error(t, auth.HostKeyCallback("git.example.com:22", addr, otherHostKey))
+		require.Error(t, auth.HostKeyCallback("other.example.com:22", addr, hostKey))
+	})
+
+	t.Run("rejects invalid known hosts", func(t *testing.T) {
+		privateKey, _ := generatePrivateKey(t, "")
+
+		err := ValidateSSHCredential(gittypes.SSHCredential{PrivateKey: privateKey, KnownHosts: "git.example.com not-a-key"})
+		assert.Error(t, err)
+	})
+}
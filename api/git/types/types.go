@@ -1,6 +1,7 @@
 package gittypes
 
 import (
+	"encoding/json"
 	"errors"
 )
 
@@ -14,6 +15,7 @@ type GitCredentialAuthType int
 const (
 	GitCredentialAuthType_Basic GitCredentialAuthType = iota
 	GitCredentialAuthType_Token
+	GitCredentialAuthType_SSH
 )
 
 // RepoConfig represents a configuration for a repo
@@ -41,3 +43,29 @@ type GitAuthentication struct {
 	// This is introduced since 2.15.0
 	GitCredentialID int `example:"0"`
 }
+
+// SSHCredential holds the key used to authenticate against a repository over SSH. It is handed to the
+// GitService encoded in the password when the authorization type is GitCredentialAuthType_SSH
+type SSHCredential struct {
+	// PEM encoded private key
+	PrivateKey string
+	// Passphrase of the private key, if it is encrypted
+	Passphrase string
+	// Host keys the server must present, in the known_hosts format. The system known_hosts files are used when empty
+	KnownHosts string
+}
+
+// Encode returns the credential in the form expected as the password by the GitService
+func (c SSHCredential) Encode() string {
+	b, _ := json.Marshal(c)
+
+	return string(b)
+}
+
+// DecodeSSHCredential parses a credential encoded with SSHCredential.Encode
+func DecodeSSHCredential(encoded string) (SSHCredential, error) {
+	var c SSHCredential
+	err := json.Unmarshal([]byte(encoded), &c)
+
+	return c, err
+}
@@ -24,7 +24,7 @@ func UpdateGitObject(gitService portainer.GitService, objId string, gitConfig *g
 		Str("object", objId).
 		Msg("the object has a git config, try to poll from git repository")
 
-	username, password, err := git.GetCredentials(gitConfig.Authentication)
+	username, password, authType, err := git.GetCredentials(gitService, gitConfig.Authentication)
 	if err != nil {
 		return false, "", errors.WithMessagef(err, "failed to get credentials for %v", objId)
 	}
@@ -34,7 +34,7 @@ func UpdateGitObject(gitService portainer.GitService, objId string, gitConfig *g
 		gitConfig.ReferenceName,
 		username,
 		password,
-		gittypes.GitCredentialAuthType_Basic,
+		authType,
 		gitConfig.TLSSkipVerify,
 	)
 	if err != nil {
@@ -69,7 +69,7 @@ func UpdateGitObject(gitService portainer.GitService, objId string, gitConfig *g
 		cloneParams.auth = &gitAuth{
 			username: username,
 			password: password,
-			authType: gitConfig.Authentication.AuthorizationType,
+			authType: authType,
 		}
 	}
 
@@ -6,8 +6,13 @@ import (
 	"github.com/portainer/portainer/pkg/validate"
 )
 
+// IsRepositoryURL returns true if url is a valid HTTP(S) URL or SSH URL of a Git repository
+func IsRepositoryURL(url string) bool {
+	return validate.IsURL(url) || IsSSHURL(url)
+}
+
 func ValidateRepoConfig(repoConfig *gittypes.RepoConfig) error {
-	if len(repoConfig.URL) == 0 || !validate.IsURL(repoConfig.URL) {
+	if len(repoConfig.URL) == 0 || !IsRepositoryURL(repoConfig.URL) {
 		return httperrors.NewInvalidPayloadError("Invalid repository URL. Must correspond to a valid URL format")
 	}
 
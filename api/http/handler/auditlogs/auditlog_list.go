@@ -0,0 +1,119 @@
+package auditlogs
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// maxLimit is the maximum number of entries returned in a page
+const maxLimit = 1000
+
+type auditLogFilters struct {
+	since      int64
+	until      int64
+	userID     portainer.UserID
+	endpointID portainer.EndpointID
+}
+
+// @id AuditLogList
+// @summary List audit log entries
+// @description List the audit trail of the mutating requests made by authenticated users, most recent first.
+// @description **Access policy**: administrator
+// @tags audit_logs
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param since query int false "Only return entries recorded at or after this unix timestamp"
+// @param until query int false "Only return entries recorded at or before this unix timestamp"
+// @param userId query int false "Only return entries of this user"
+// @param endpointId query int false "Only return entries targeting this environment(endpoint)"
+// @param start query int false "Start searching from"
+// @param limit query int false "Limit results to this value, at most 1000"
+// @success 200 {array} portainer.AuditLog "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /audit_logs [get]
+func (handler *Handler) auditLogList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	filters, err := parseFilters(r)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter", err)
+	}
+
+	start, _ := request.RetrieveNumericQueryParameter(r, "start", true)
+	if start < 0 {
+		return httperror.BadRequest("Invalid query parameter: start", errors.New("start must not be negative"))
+	} else if start != 0 {
+		start--
+	}
+
+	limit, _ := request.RetrieveNumericQueryParameter(r, "limit", true)
+	if limit < 0 {
+		return httperror.BadRequest("Invalid query parameter: limit", errors.New("limit must not be negative"))
+	}
+	limit = min(limit, maxLimit)
+
+	entries, total, err := handler.DataStore.AuditLog().ReadPage(filters.match, start, limit)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve audit log entries from the database", err)
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	return response.JSON(w, entries)
+}
+
+func parseFilters(r *http.Request) (auditLogFilters, error) {
+	var filters auditLogFilters
+
+	since, err := request.RetrieveNumericQueryParameter(r, "since", true)
+	if err != nil {
+		return filters, err
+	}
+	filters.since = int64(since)
+
+	until, err := request.RetrieveNumericQueryParameter(r, "until", true)
+	if err != nil {
+		return filters, err
+	}
+	filters.until = int64(until)
+
+	userID, err := request.RetrieveNumericQueryParameter(r, "userId", true)
+	if err != nil {
+		return filters, err
+	}
+	filters.userID = portainer.UserID(userID)
+
+	endpointID, err := request.RetrieveNumericQueryParameter(r, "endpointId", true)
+	if err != nil {
+		return filters, err
+	}
+	filters.endpointID = portainer.EndpointID(endpointID)
+
+	return filters, nil
+}
+
+func (filters auditLogFilters) match(entry portainer.AuditLog) bool {
+	if filters.since != 0 && entry.Timestamp < filters.since {
+		return false
+	}
+
+	if filters.until != 0 && entry.Timestamp > filters.until {
+		return false
+	}
+
+	if filters.userID != 0 && entry.UserID != filters.userID {
+		return false
+	}
+
+	if filters.endpointID != 0 && entry.EndpointID != filters.endpointID {
+		return false
+	}
+
+	return true
+}
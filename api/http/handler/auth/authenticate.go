@@ -9,6 +9,7 @@ import (
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/totp"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
@@ -27,6 +28,15 @@ type authenticatePayload struct {
 type authenticateResponse struct {
 	// JWT token used to authenticate against the API
 	JWT string `json:"jwt" example:"abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789abcdefghijklmnopqrstuvwxyzAB"`
+	// Single-use recovery codes, only returned when the user enrolled a second factor during the authentication
+	RecoveryCodes []string `json:"recoveryCodes,omitempty" example:"abcde-fghjk"`
+}
+
+type totpChallengeResponse struct {
+	// Token identifying the authentication when providing the second factor
+	TOTPToken string `json:"totpToken" example:"c2VjcmV0LWNoYWxsZW5nZS10b2tlbg"`
+	// Whether the user must enroll a second factor before providing a code
+	EnrollmentRequired bool `json:"enrollmentRequired" example:"false"`
 }
 
 func (payload *authenticatePayload) Validate(r *http.Request) error {
@@ -45,11 +55,13 @@ func (payload *authenticatePayload) Validate(r *http.Request) error {
 // @summary Authenticate
 // @description **Access policy**: public
 // @description Use this environment(endpoint) to authenticate against Portainer using a username and password.
+// @description When the user must provide a second factor, no JWT is returned but a token to use with /auth/totp.
 // @tags auth
 // @accept json
 // @produce json
 // @param body body authenticatePayload true "Credentials used for authentication"
 // @success 200 {object} authenticateResponse "Success"
+// @success 202 {object} totpChallengeResponse "Second factor required"
 // @failure 400 "Invalid request"
 // @failure 422 "Invalid Credentials"
 // @failure 500 "Server error"
@@ -89,7 +101,7 @@ func (handler *Handler) authenticate(rw http.ResponseWriter, r *http.Request) *h
 	}
 
 	if user != nil && isUserInitialAdmin(user) || settings.AuthenticationMethod == portainer.AuthenticationInternal {
-		return handler.authenticateInternal(rw, user, payload.Password)
+		return handler.authenticateInternal(rw, user, payload.Password, settings)
 	}
 
 	if settings.AuthenticationMethod == portainer.AuthenticationOAuth {
@@ -107,13 +119,17 @@ func isUserInitialAdmin(user *portainer.User) bool {
 	return int(user.ID) == 1
 }
 
-func (handler *Handler) authenticateInternal(w http.ResponseWriter, user *portainer.User, password string) *httperror.HandlerError {
+func (handler *Handler) authenticateInternal(w http.ResponseWriter, user *portainer.User, password string, settings *portainer.Settings) *httperror.HandlerError {
 	if err := handler.CryptoService.CompareHashAndData(user.Password, password); err != nil {
 		return httperror.NewError(http.StatusUnprocessableEntity, "Invalid credentials", httperrors.ErrUnauthorized)
 	}
 
 	forceChangePassword := !handler.passwordStrengthChecker.Check(password)
 
+	if user.TOTP.Enabled || totp.Required(settings, user) {
+		return handler.writeTOTPChallenge(w, user, forceChangePassword)
+	}
+
 	return handler.writeToken(w, user, forceChangePassword)
 }
 
@@ -152,6 +168,10 @@ func (handler *Handler) writeToken(w http.ResponseWriter, user *portainer.User,
 }
 
 func (handler *Handler) persistAndWriteToken(w http.ResponseWriter, tokenData *portainer.TokenData) *httperror.HandlerError {
+	return handler.persistAndWriteTokenWithRecoveryCodes(w, tokenData, nil)
+}
+
+func (handler *Handler) persistAndWriteTokenWithRecoveryCodes(w http.ResponseWriter, tokenData *portainer.TokenData, recoveryCodes []string) *httperror.HandlerError {
 	token, expirationTime, err := handler.JWTService.GenerateToken(tokenData)
 	if err != nil {
 		return httperror.InternalServerError("Unable to generate JWT token", err)
@@ -159,7 +179,7 @@ func (handler *Handler) persistAndWriteToken(w http.ResponseWriter, tokenData *p
 
 	security.AddAuthCookie(w, token, expirationTime)
 
-	return response.JSON(w, &authenticateResponse{JWT: token})
+	return response.JSON(w, &authenticateResponse{JWT: token, RecoveryCodes: recoveryCodes})
 }
 
 func (handler *Handler) syncUserTeamsWithLDAPGroups(user *portainer.User, settings *portainer.LDAPSettings) error {
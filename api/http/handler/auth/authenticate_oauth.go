@@ -3,18 +3,31 @@ package auth
 import (
 	"errors"
 	"net/http"
+	"slices"
+	"strings"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
 	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/oauth"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
 
 	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
 )
 
 type oauthPayload struct {
 	// OAuth code returned from OAuth Provided
 	Code string
+	// State returned from OAuth Provided, required when the login was started with /auth/oauth/login
+	State string
+}
+
+type oauthLoginResponse struct {
+	// URL of the authorization server the user must be redirected to
+	LoginURI string `json:"loginUri" example:"https://gitlab.com/oauth/authorize?client_id=portainer&response_type=code&state=abc"`
 }
 
 func (payload *oauthPayload) Validate(r *http.Request) error {
@@ -25,21 +38,57 @@ func (payload *oauthPayload) Validate(r *http.Request) error {
 	return nil
 }
 
-func (handler *Handler) authenticateOAuth(code string, settings *portainer.OAuthSettings) (string, error) {
+func (handler *Handler) authenticateOAuth(code string, settings *portainer.OAuthSettings, login oauthLogin) (*portainer.OAuthUser, error) {
 	if code == "" {
-		return "", errors.New("Invalid OAuth authorization code")
+		return nil, errors.New("Invalid OAuth authorization code")
 	}
 
 	if settings == nil {
-		return "", errors.New("Invalid OAuth configuration")
+		return nil, errors.New("Invalid OAuth configuration")
+	}
+
+	return handler.OAuthService.Authenticate(code, settings, login.codeVerifier, login.nonce)
+}
+
+// @id OAuthLogin
+// @summary Start a login with OAuth
+// @description **Access policy**: public
+// @description Returns the URL of the authorization server to redirect the user to. The URL carries a single-use state,
+// @description a nonce and, when PKCE is enabled, a code challenge. The state must be sent back to /auth/oauth/validate.
+// @tags auth
+// @produce json
+// @success 200 {object} oauthLoginResponse "Success"
+// @failure 403 "OAuth authentication is not enabled"
+// @failure 500 "Server error"
+// @router /auth/oauth/login [get]
+func (handler *Handler) oauthLogin(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve settings from the database", err)
 	}
 
-	username, err := handler.OAuthService.Authenticate(code, settings)
+	if settings.AuthenticationMethod != portainer.AuthenticationOAuth {
+		return httperror.Forbidden("OAuth authentication is not enabled", errors.New("OAuth authentication is not enabled"))
+	}
+
+	nonce, err := randomToken()
 	if err != nil {
-		return "", err
+		return httperror.InternalServerError("Unable to generate the OAuth login", err)
 	}
 
-	return username, nil
+	login := oauthLogin{nonce: nonce}
+	if settings.OAuthSettings.PKCE {
+		login.codeVerifier = oauth2.GenerateVerifier()
+	}
+
+	state, err := handler.oauthLogins.create(login)
+	if err != nil {
+		return httperror.InternalServerError("Unable to generate the OAuth login", err)
+	}
+
+	return response.JSON(w, &oauthLoginResponse{
+		LoginURI: oauth.LoginURL(&settings.OAuthSettings, state, login.nonce, login.codeVerifier),
+	})
 }
 
 // @id ValidateOAuth
@@ -70,13 +119,25 @@ func (handler *Handler) validateOAuth(w http.ResponseWriter, r *http.Request) *h
 		return httperror.Forbidden("OAuth authentication is not enabled", errors.New("OAuth authentication is not enabled"))
 	}
 
-	username, err := handler.authenticateOAuth(payload.Code, &settings.OAuthSettings)
+	var login oauthLogin
+	if payload.State != "" {
+		var ok bool
+		if login, ok = handler.oauthLogins.consume(payload.State); !ok {
+			return httperror.BadRequest("Invalid or expired OAuth state", errors.New("unknown OAuth state"))
+		}
+	} else if settings.OAuthSettings.PKCE {
+		return httperror.BadRequest("Invalid request payload", errors.New("the OAuth state is required when PKCE is enabled"))
+	}
+
+	oauthUser, err := handler.authenticateOAuth(payload.Code, &settings.OAuthSettings, login)
 	if err != nil {
 		log.Debug().Err(err).Msg("OAuth authentication error")
 
 		return httperror.InternalServerError("Unable to authenticate through OAuth", httperrors.ErrUnauthorized)
 	}
 
+	username := oauthUser.Username
+
 	user, err := handler.DataStore.User().UserByUsername(username)
 	if err != nil && !handler.DataStore.IsErrObjectNotFound(err) {
 		return httperror.InternalServerError("Unable to retrieve a user with the specified username from the database", err)
@@ -109,8 +170,70 @@ func (handler *Handler) validateOAuth(w http.ResponseWriter, r *http.Request) *h
 				return httperror.InternalServerError("Unable to persist team membership inside the database", err)
 			}
 		}
+	}
 
+	if settings.OAuthSettings.GroupClaim != "" {
+		if err := handler.syncUserTeamsWithOAuthGroups(user, oauthUser.Groups, settings.OAuthSettings.TeamMappings); err != nil {
+			log.Warn().Err(err).Msg("unable to automatically sync user teams with the OAuth groups")
+		}
 	}
 
 	return handler.writeToken(w, user, false)
 }
+
+// syncUserTeamsWithOAuthGroups makes the user a member of the teams mapped to its groups, and removes it from the
+// mapped teams its groups no longer map to. The memberships of the teams that are not mapped are left unchanged.
+func (handler *Handler) syncUserTeamsWithOAuthGroups(user *portainer.User, groups []string, mappings []portainer.OAuthTeamMapping) error {
+	mappedTeams := make(map[portainer.TeamID]bool)
+	for _, mapping := range mappings {
+		mappedTeams[mapping.TeamID] = mappedTeams[mapping.TeamID] || slices.ContainsFunc(groups, func(group string) bool {
+			return strings.EqualFold(group, mapping.ClaimValue)
+		})
+	}
+
+	return handler.DataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		memberships, err := tx.TeamMembership().TeamMembershipsByUserID(user.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, membership := range memberships {
+			isMember, mapped := mappedTeams[membership.TeamID]
+			if !mapped {
+				continue
+			}
+
+			if !isMember {
+				if err := tx.TeamMembership().Delete(membership.ID); err != nil {
+					return err
+				}
+			}
+
+			delete(mappedTeams, membership.TeamID)
+		}
+
+		for teamID, isMember := range mappedTeams {
+			if !isMember {
+				continue
+			}
+
+			if _, err := tx.Team().Read(teamID); tx.IsErrObjectNotFound(err) {
+				log.Warn().Int("team_id", int(teamID)).Msg("the team mapped to an OAuth group does not exist")
+
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			if err := tx.TeamMembership().Create(&portainer.TeamMembership{
+				UserID: user.ID,
+				TeamID: teamID,
+				Role:   portainer.TeamMember,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOAuthService struct {
+	user         *portainer.OAuthUser
+	codeVerifier string
+	nonce        string
+}
+
+func (service *testOAuthService) Authenticate(code string, configuration *portainer.OAuthSettings, codeVerifier, nonce string) (*portainer.OAuthUser, error) {
+	service.codeVerifier = codeVerifier
+	service.nonce = nonce
+
+	return service.user, nil
+}
+
+func startOAuthLogin(t *testing.T, h *Handler) url.Values {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/oauth/login", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp oauthLoginResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+
+	loginURL, err := url.Parse(resp.LoginURI)
+	require.NoError(t, err)
+
+	return loginURL.Query()
+}
+
+func TestValidateOAuth(t *testing.T) {
+	h, store := newTOTPTestHandler(t)
+
+	oauthService := &testOAuthService{user: &portainer.OAuthUser{Username: "john", Groups: []string{"Developers"}}}
+	h.OAuthService = oauthService
+
+	require.NoError(t, store.User().Create(&portainer.User{ID: 1, Username: "john", Role: portainer.StandardUserRole}))
+	require.NoError(t, store.Team().Create(&portainer.Team{ID: 1, Name: "developers"}))
+	require.NoError(t, store.Team().Create(&portainer.Team{ID: 2, Name: "operators"}))
+	require.NoError(t, store.Team().Create(&portainer.Team{ID: 3, Name: "manual"}))
+	require.NoError(t, store.TeamMembership().Create(&portainer.TeamMembership{UserID: 1, TeamID: 2, Role: portainer.TeamMember}))
+	require.NoError(t, store.TeamMembership().Create(&portainer.TeamMembership{UserID: 1, TeamID: 3, Role: portainer.TeamMember}))
+
+	settings, err := store.Settings().Settings()
+	require.NoError(t, err)
+	settings.AuthenticationMethod = portainer.AuthenticationOAuth
+	settings.OAuthSettings = portainer.OAuthSettings{
+		ClientID:         "portainer",
+		AuthorizationURI: "https://example.com/authorize",
+		PKCE:             true,
+		GroupClaim:       "groups",
+		TeamMappings: []portainer.OAuthTeamMapping{
+			{ClaimValue: "developers", TeamID: 1},
+			{ClaimValue: "operators", TeamID: 2},
+			{ClaimValue: "unknown", TeamID: 42},
+		},
+	}
+	require.NoError(t, store.Settings().UpdateSettings(settings))
+
+	t.Run("state is required when PKCE is enabled", func(t *testing.T) {
+		rr := postJSON(t, h, "/auth/oauth/validate", oauthPayload{Code: "code"})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("unknown state is rejected", func(t *testing.T) {
+		rr := postJSON(t, h, "/auth/oauth/validate", oauthPayload{Code: "code", State: "unknown"})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("login with PKCE syncs the mapped teams", func(t *testing.T) {
+		query := startOAuthLogin(t, h)
+		require.NotEmpty(t, query.Get("state"))
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+		rr := postJSON(t, h, "/auth/oauth/validate", oauthPayload{Code: "code", State: query.Get("state")})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		assert.NotEmpty(t, oauthService.codeVerifier)
+		assert.Equal(t, query.Get("nonce"), oauthService.nonce)
+
+		memberships, err := store.TeamMembership().TeamMembershipsByUserID(1)
+		require.NoError(t, err)
+
+		teamIDs := make([]portainer.TeamID, 0, len(memberships))
+		for _, membership := range memberships {
+			teamIDs = append(teamIDs, membership.TeamID)
+		}
+		assert.ElementsMatch(t, []portainer.TeamID{1, 3}, teamIDs)
+
+		rr = postJSON(t, h, "/auth/oauth/validate", oauthPayload{Code: "code", State: query.Get("state")})
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "a state can only be used once")
+	})
+}
@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/totp"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/pkg/errors"
+)
+
+var errInvalidTOTPCode = errors.New("invalid authentication code")
+
+type authenticateTOTPPayload struct {
+	// Token returned by the authentication with the password
+	Token string `example:"c2VjcmV0LWNoYWxsZW5nZS10b2tlbg" validate:"required"`
+	// Code displayed by the authenticator application
+	Code string `example:"123456"`
+	// Single-use recovery code, used instead of Code when the authenticator application is not available
+	RecoveryCode string `example:"abcde-fghjk"`
+}
+
+func (payload *authenticateTOTPPayload) Validate(r *http.Request) error {
+	if len(payload.Token) == 0 {
+		return errors.New("Invalid token")
+	}
+
+	if len(payload.Code) == 0 && len(payload.RecoveryCode) == 0 {
+		return errors.New("Invalid code. A code or a recovery code must be specified")
+	}
+
+	return nil
+}
+
+type authenticateTOTPSetupPayload struct {
+	// Token returned by the authentication with the password
+	Token string `example:"c2VjcmV0LWNoYWxsZW5nZS10b2tlbg" validate:"required"`
+}
+
+func (payload *authenticateTOTPSetupPayload) Validate(r *http.Request) error {
+	if len(payload.Token) == 0 {
+		return errors.New("Invalid token")
+	}
+
+	return nil
+}
+
+type totpSetupResponse struct {
+	// Base32 encoded secret to type in the authenticator application
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	// otpauth URI of the secret, to display as a QR code
+	ProvisioningURI string `json:"provisioningUri" example:"otpauth://totp/Portainer:admin?issuer=Portainer&secret=JBSWY3DPEHPK3PXP"`
+}
+
+// @id AuthenticateTOTPSetup
+// @summary Generate the second factor of a user that must enroll one
+// @description **Access policy**: public
+// @description Generates the secret of the authenticator application of a user who authenticated with the password
+// @description but must enroll a second factor. The enrollment is completed by providing a code to /auth/totp.
+// @tags auth
+// @accept json
+// @produce json
+// @param body body authenticateTOTPSetupPayload true "Token of the authentication"
+// @success 200 {object} totpSetupResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 422 "Invalid or expired token"
+// @failure 500 "Server error"
+// @router /auth/totp/setup [post]
+func (handler *Handler) authenticateTOTPSetup(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload authenticateTOTPSetupPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	challenge, ok := handler.totpChallenges.get(payload.Token)
+	if !ok {
+		return httperror.NewError(http.StatusUnprocessableEntity, "Invalid or expired authentication token", httperrors.ErrUnauthorized)
+	}
+
+	if !challenge.enrollment {
+		return httperror.BadRequest("The user already enrolled a second factor", errors.New("second factor already enrolled"))
+	}
+
+	user, err := handler.DataStore.User().Read(challenge.userID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the user from the database", err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return httperror.InternalServerError("Unable to generate the second factor", err)
+	}
+
+	if !handler.totpChallenges.setSecret(payload.Token, secret) {
+		return httperror.NewError(http.StatusUnprocessableEntity, "Invalid or expired authentication token", httperrors.ErrUnauthorized)
+	}
+
+	return response.JSON(w, &totpSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(user.Username, secret),
+	})
+}
+
+// @id AuthenticateTOTP
+// @summary Complete an authentication with the second factor
+// @description **Access policy**: public
+// @description Completes the authentication of a user with the code of the authenticator application or a recovery code.
+// @description When the user enrolls a second factor, the response contains the recovery codes, they are not displayed again.
+// @tags auth
+// @accept json
+// @produce json
+// @param body body authenticateTOTPPayload true "Second factor"
+// @success 200 {object} authenticateResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 422 "Invalid code or expired token"
+// @failure 500 "Server error"
+// @router /auth/totp [post]
+func (handler *Handler) authenticateTOTP(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload authenticateTOTPPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	challenge, ok := handler.totpChallenges.get(payload.Token)
+	if !ok {
+		return httperror.NewError(http.StatusUnprocessableEntity, "Invalid or expired authentication token", httperrors.ErrUnauthorized)
+	}
+
+	var user *portainer.User
+	var recoveryCodes, recoveryCodeHashes []string
+
+	if challenge.enrollment {
+		// hashing is slow, the codes are generated before locking the database
+		var err error
+		if recoveryCodes, err = totp.GenerateRecoveryCodes(); err != nil {
+			return httperror.InternalServerError("Unable to generate the recovery codes", err)
+		}
+
+		if recoveryCodeHashes, err = totp.HashRecoveryCodes(handler.CryptoService, recoveryCodes); err != nil {
+			return httperror.InternalServerError("Unable to generate the recovery codes", err)
+		}
+	}
+
+	err := handler.DataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		var err error
+		if challenge.enrollment {
+			user, err = enrollTOTP(tx, challenge, payload.Code, recoveryCodeHashes)
+		} else {
+			user, err = handler.verifyTOTP(tx, challenge, payload.Code, payload.RecoveryCode)
+		}
+
+		return err
+	})
+	if errors.Is(err, errInvalidTOTPCode) {
+		handler.totpChallenges.fail(payload.Token)
+
+		return httperror.NewError(http.StatusUnprocessableEntity, "Invalid authentication code", httperrors.ErrUnauthorized)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to verify the second factor", err)
+	}
+
+	handler.totpChallenges.remove(payload.Token)
+
+	return handler.persistAndWriteTokenWithRecoveryCodes(w, composeTokenData(user, challenge.forceChangePassword), recoveryCodes)
+}
+
+// enrollTOTP enables the second factor of the user once the code proves the authenticator application was configured
+func enrollTOTP(tx dataservices.DataStoreTx, challenge totpChallenge, code string, recoveryCodeHashes []string) (*portainer.User, error) {
+	if challenge.secret == "" {
+		return nil, errInvalidTOTPCode
+	}
+
+	step, ok := totp.Validate(code, challenge.secret, time.Now(), 0)
+	if !ok {
+		return nil, errInvalidTOTPCode
+	}
+
+	user, err := tx.User().Read(challenge.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTP = portainer.UserTOTP{
+		Enabled:       true,
+		Secret:        challenge.secret,
+		RecoveryCodes: recoveryCodeHashes,
+		LastUsedStep:  step,
+	}
+
+	return user, tx.User().Update(user.ID, user)
+}
+
+// verifyTOTP verifies the code, or consumes the recovery code, of a user who enrolled a second factor
+func (handler *Handler) verifyTOTP(tx dataservices.DataStoreTx, challenge totpChallenge, code, recoveryCode string) (*portainer.User, error) {
+	user, err := tx.User().Read(challenge.userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TOTP.Enabled {
+		return nil, errInvalidTOTPCode
+	}
+
+	if code != "" {
+		step, ok := totp.Validate(code, user.TOTP.Secret, time.Now(), user.TOTP.LastUsedStep)
+		if !ok {
+			return nil, errInvalidTOTPCode
+		}
+
+		user.TOTP.LastUsedStep = step
+
+		return user, tx.User().Update(user.ID, user)
+	}
+
+	if !totp.ConsumeRecoveryCode(handler.CryptoService, &user.TOTP, recoveryCode) {
+		return nil, errInvalidTOTPCode
+	}
+
+	return user, tx.User().Update(user.ID, user)
+}
+
+func (handler *Handler) writeTOTPChallenge(w http.ResponseWriter, user *portainer.User, forceChangePassword bool) *httperror.HandlerError {
+	challenge := totpChallenge{
+		userID:              user.ID,
+		forceChangePassword: forceChangePassword,
+		enrollment:          !user.TOTP.Enabled,
+	}
+
+	token, err := handler.totpChallenges.create(challenge)
+	if err != nil {
+		return httperror.InternalServerError("Unable to create the authentication challenge", err)
+	}
+
+	return response.JSONWithStatus(w, &totpChallengeResponse{
+		TOTPToken:          token,
+		EnrollmentRequired: challenge.enrollment,
+	}, http.StatusAccepted)
+}
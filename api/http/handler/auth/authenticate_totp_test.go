@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	"github.com/portainer/portainer/api/jwt"
+	"github.com/portainer/portainer/api/kubernetes/cli"
+	"github.com/portainer/portainer/api/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTOTPTestHandler(t *testing.T) (*Handler, *datastore.Store) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	jwtService, err := jwt.NewService("1h", store)
+	require.NoError(t, err)
+
+	k, err := cli.NewClientFactory(nil, nil, nil, "", "", "")
+	require.NoError(t, err)
+
+	h := NewHandler(testhelpers.NewTestRequestBouncer(), security.NewRateLimiter(100, time.Second, time.Hour), security.NewPasswordStrengthChecker(store.SettingsService), k)
+	h.DataStore = store
+	h.CryptoService = &crypto.Service{}
+	h.JWTService = jwtService
+
+	return h, store
+}
+
+func createTOTPTestUser(t *testing.T, store *datastore.Store, cryptoService portainer.CryptoService, user *portainer.User) {
+	hash, err := cryptoService.Hash("password")
+	require.NoError(t, err)
+
+	user.Password = hash
+	require.NoError(t, store.User().Create(user))
+}
+
+func postJSON(t *testing.T, h *Handler, path string, payload any) *httptest.ResponseRecorder {
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+
+	return rr
+}
+
+func login(t *testing.T, h *Handler, username string) *httptest.ResponseRecorder {
+	return postJSON(t, h, "/auth", authenticatePayload{Username: username, Password: "password"})
+}
+
+func TestAuthenticateTOTP(t *testing.T) {
+	h, store := newTOTPTestHandler(t)
+
+	createTOTPTestUser(t, store, h.CryptoService, &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole})
+	createTOTPTestUser(t, store, h.CryptoService, &portainer.User{ID: 2, Username: "standard", Role: portainer.StandardUserRole})
+
+	settings, err := store.Settings().Settings()
+	require.NoError(t, err)
+	settings.TwoFactorRequirement = portainer.TwoFactorRequiredForAdministrators
+	require.NoError(t, store.Settings().UpdateSettings(settings))
+
+	t.Run("user without second factor receives a JWT", func(t *testing.T) {
+		rr := login(t, h, "standard")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp authenticateResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		assert.NotEmpty(t, resp.JWT)
+	})
+
+	var secret string
+
+	t.Run("administrator must enroll a second factor", func(t *testing.T) {
+		rr := login(t, h, "admin")
+		require.Equal(t, http.StatusAccepted, rr.Code)
+
+		var challenge totpChallengeResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&challenge))
+		assert.True(t, challenge.EnrollmentRequired)
+		assert.Empty(t, rr.Result().Cookies(), "no session is created before the second factor")
+
+		rr = postJSON(t, h, "/auth/totp", authenticateTOTPPayload{Token: challenge.TOTPToken, Code: "123456"})
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code, "a code cannot be verified before the setup")
+
+		rr = postJSON(t, h, "/auth/totp/setup", authenticateTOTPSetupPayload{Token: challenge.TOTPToken})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var setup totpSetupResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&setup))
+		assert.Contains(t, setup.ProvisioningURI, "Portainer:admin")
+		secret = setup.Secret
+
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+
+		rr = postJSON(t, h, "/auth/totp", authenticateTOTPPayload{Token: challenge.TOTPToken, Code: code})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp authenticateResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		assert.NotEmpty(t, resp.JWT)
+		assert.Len(t, resp.RecoveryCodes, totp.RecoveryCodeCount)
+
+		user, err := store.User().Read(1)
+		require.NoError(t, err)
+		assert.True(t, user.TOTP.Enabled)
+		assert.Equal(t, secret, user.TOTP.Secret)
+		assert.NotContains(t, user.TOTP.RecoveryCodes, resp.RecoveryCodes[0], "recovery codes are stored hashed")
+
+		// the challenge is consumed
+		rr = postJSON(t, h, "/auth/totp", authenticateTOTPPayload{Token: challenge.TOTPToken, Code: code})
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+
+	t.Run("enrolled user authenticates with a code", func(t *testing.T) {
+		rr := login(t, h, "admin")
+		require.Equal(t, http.StatusAccepted, rr.Code)
+
+		var challenge totpChallengeResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&challenge))
+		assert.False(t, challenge.EnrollmentRequired)
+
+		user, err := store.User().Read(1)
+		require.NoError(t, err)
+
+		// the code used for the enrollment cannot be replayed
+		replayed, err := totp.GenerateCode(secret, time.Unix(user.TOTP.LastUsedStep*totp.Period, 0))
+		require.NoError(t, err)
+
+		rr = postJSON(t, h, "/auth/totp", authenticateTOTPPayload{Token: challenge.TOTPToken, Code: replayed})
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+		code, err := totp.GenerateCode(secret, time.Unix((user.TOTP.LastUsedStep+1)*totp.Period, 0))
+		require.NoError(t, err)
+
+		rr = postJSON(t, h, "/auth/totp", authenticateTOTPPayload{Token: challenge.TOTPToken, Code: code})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp authenticateResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		assert.NotEmpty(t, resp.JWT)
+		assert.Empty(t, resp.RecoveryCodes)
+	})
+
+	t.Run("challenge is dropped after too many invalid codes", func(t *testing.T) {
+		rr := login(t, h, "admin")
+		require.Equal(t, http.StatusAccepted, rr.Code)
+
+		var challenge totpChallengeResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&challenge))
+
+		for range totpChallengeMaxAttempts {
+			rr = postJSON(t, h, "/auth/totp", authenticateTOTPPayload{Token: challenge.TOTPToken, Code: "abcdef"})
+			require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		}
+
+		_, ok := h.totpChallenges.get(challenge.TOTPToken)
+		assert.False(t, ok)
+	})
+}
+
+func TestAuthenticateTOTP_RecoveryCode(t *testing.T) {
+	h, store := newTOTPTestHandler(t)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	recoveryCodes, err := totp.GenerateRecoveryCodes()
+	require.NoError(t, err)
+
+	hashes, err := totp.HashRecoveryCodes(h.CryptoService, recoveryCodes[:2])
+	require.NoError(t, err)
+
+	createTOTPTestUser(t, store, h.CryptoService, &portainer.User{
+		ID:       1,
+		Username: "admin",
+		Role:     portainer.AdministratorRole,
+		TOTP:     portainer.UserTOTP{Enabled: true, Secret: secret, RecoveryCodes: hashes},
+	})
+
+	rr := login(t, h, "admin")
+	require.Equal(t, http.StatusAccepted, rr.Code)
+
+	var challenge totpChallengeResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&challenge))
+
+	rr = postJSON(t, h, "/auth/totp", authenticateTOTPPayload{Token: challenge.TOTPToken, RecoveryCode: recoveryCodes[2]})
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	rr = postJSON(t, h, "/auth/totp", authenticateTOTPPayload{Token: challenge.TOTPToken, RecoveryCode: recoveryCodes[1]})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	user, err := store.User().Read(1)
+	require.NoError(t, err)
+	assert.Equal(t, hashes[:1], user.TOTP.RecoveryCodes, "a recovery code can only be used once")
+}
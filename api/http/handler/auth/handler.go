@@ -27,6 +27,8 @@ type Handler struct {
 	KubernetesClientFactory     *cli.ClientFactory
 	passwordStrengthChecker     security.PasswordStrengthChecker
 	bouncer                     security.BouncerService
+	totpChallenges              *totpChallengeStore
+	oauthLogins                 *oauthLoginStore
 }
 
 // NewHandler creates a handler to manage authentication operations.
@@ -36,12 +38,20 @@ func NewHandler(bouncer security.BouncerService, rateLimiter *security.RateLimit
 		passwordStrengthChecker: passwordStrengthChecker,
 		bouncer:                 bouncer,
 		KubernetesClientFactory: kubernetesClientFactory,
+		totpChallenges:          newTOTPChallengeStore(),
+		oauthLogins:             newOAuthLoginStore(),
 	}
 
+	h.Handle("/auth/oauth/login",
+		rateLimiter.LimitAccess(bouncer.PublicAccess(httperror.LoggerHandler(h.oauthLogin)))).Methods(http.MethodGet)
 	h.Handle("/auth/oauth/validate",
 		rateLimiter.LimitAccess(bouncer.PublicAccess(httperror.LoggerHandler(h.validateOAuth)))).Methods(http.MethodPost)
 	h.Handle("/auth",
 		rateLimiter.LimitAccess(bouncer.PublicAccess(httperror.LoggerHandler(h.authenticate)))).Methods(http.MethodPost)
+	h.Handle("/auth/totp",
+		rateLimiter.LimitAccess(bouncer.PublicAccess(httperror.LoggerHandler(h.authenticateTOTP)))).Methods(http.MethodPost)
+	h.Handle("/auth/totp/setup",
+		rateLimiter.LimitAccess(bouncer.PublicAccess(httperror.LoggerHandler(h.authenticateTOTPSetup)))).Methods(http.MethodPost)
 	h.Handle("/auth/logout",
 		bouncer.PublicAccess(httperror.LoggerHandler(h.logout))).Methods(http.MethodPost)
 
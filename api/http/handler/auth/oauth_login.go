@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// oauthLoginTTL is the time a user has to log in on the authorization server
+	oauthLoginTTL = 10 * time.Minute
+	// oauthLoginMaxPending bounds the memory used by the logins that are never completed
+	oauthLoginMaxPending = 10000
+)
+
+var errTooManyOAuthLogins = errors.New("too many pending OAuth logins")
+
+// oauthLogin represents a login started on the authorization server, identified by its state parameter
+type oauthLogin struct {
+	nonce        string
+	codeVerifier string
+	expiresAt    time.Time
+}
+
+// oauthLoginStore keeps the pending logins in memory, a restart only requires the users to log in again
+type oauthLoginStore struct {
+	mu     sync.Mutex
+	logins map[string]oauthLogin
+}
+
+func newOAuthLoginStore() *oauthLoginStore {
+	return &oauthLoginStore{logins: make(map[string]oauthLogin)}
+}
+
+// create stores the login and returns the state identifying it
+func (store *oauthLoginStore) create(login oauthLogin) (string, error) {
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	login.expiresAt = time.Now().Add(oauthLoginTTL)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	for s, l := range store.logins {
+		if now.After(l.expiresAt) {
+			delete(store.logins, s)
+		}
+	}
+
+	if len(store.logins) >= oauthLoginMaxPending {
+		return "", errTooManyOAuthLogins
+	}
+
+	store.logins[state] = login
+
+	return state, nil
+}
+
+// consume returns and removes the login identified by the state, a state can only be used once
+func (store *oauthLoginStore) consume(state string) (oauthLogin, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	login, ok := store.logins[state]
+	if !ok {
+		return oauthLogin{}, false
+	}
+
+	delete(store.logins, state)
+
+	if time.Now().After(login.expiresAt) {
+		return oauthLogin{}, false
+	}
+
+	return login, true
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
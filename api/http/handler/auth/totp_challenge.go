@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+const (
+	// totpChallengeTTL is the time a user has to provide the second factor after the password
+	totpChallengeTTL = 5 * time.Minute
+	// totpChallengeMaxAttempts is the number of invalid codes after which the user must provide the password again
+	totpChallengeMaxAttempts = 5
+)
+
+// totpChallenge represents an authentication that succeeded with the password and waits for the second factor
+type totpChallenge struct {
+	userID              portainer.UserID
+	forceChangePassword bool
+	// enrollment is true when the user must enroll a second factor before being authenticated
+	enrollment bool
+	// secret is the secret generated for the enrollment
+	secret    string
+	attempts  int
+	expiresAt time.Time
+}
+
+// totpChallengeStore keeps the pending challenges in memory, they are lost on restart which only
+// requires the users to provide their password again
+type totpChallengeStore struct {
+	mu         sync.Mutex
+	challenges map[string]*totpChallenge
+}
+
+func newTOTPChallengeStore() *totpChallengeStore {
+	return &totpChallengeStore{challenges: make(map[string]*totpChallenge)}
+}
+
+// create stores the challenge and returns the opaque token identifying it
+func (store *totpChallengeStore) create(challenge totpChallenge) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	challenge.expiresAt = time.Now().Add(totpChallengeTTL)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.purgeExpired()
+	store.challenges[token] = &challenge
+
+	return token, nil
+}
+
+// get returns a copy of the challenge identified by the token, if it has not expired
+func (store *totpChallengeStore) get(token string) (totpChallenge, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.purgeExpired()
+
+	challenge, ok := store.challenges[token]
+	if !ok {
+		return totpChallenge{}, false
+	}
+
+	return *challenge, true
+}
+
+// setSecret attaches the secret generated for an enrollment to the challenge
+func (store *totpChallengeStore) setSecret(token, secret string) bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	challenge, ok := store.challenges[token]
+	if ok {
+		challenge.secret = secret
+	}
+
+	return ok
+}
+
+// fail records an invalid code and removes the challenge once too many were provided
+func (store *totpChallengeStore) fail(token string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	challenge, ok := store.challenges[token]
+	if !ok {
+		return
+	}
+
+	challenge.attempts++
+	if challenge.attempts >= totpChallengeMaxAttempts {
+		delete(store.challenges, token)
+	}
+}
+
+func (store *totpChallengeStore) remove(token string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.challenges, token)
+}
+
+func (store *totpChallengeStore) purgeExpired() {
+	now := time.Now()
+
+	for token, challenge := range store.challenges {
+		if now.After(challenge.expiresAt) {
+			delete(store.challenges, token)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/portainer/portainer/api/adminmonitor"
+	operations "github.com/portainer/portainer/api/backup"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/http/offlinegate"
 	"github.com/portainer/portainer/api/http/security"
@@ -22,6 +23,7 @@ type Handler struct {
 	filestorePath   string
 	shutdownTrigger context.CancelFunc
 	adminMonitor    *adminmonitor.Monitor
+	scheduled       *operations.ScheduledService
 }
 
 // NewHandler creates an new instance of backup handler
@@ -32,6 +34,7 @@ func NewHandler(
 	filestorePath string,
 	shutdownTrigger context.CancelFunc,
 	adminMonitor *adminmonitor.Monitor,
+	scheduled *operations.ScheduledService,
 ) *Handler {
 	h := &Handler{
 		Router:          mux.NewRouter(),
@@ -41,10 +44,16 @@ func NewHandler(
 		filestorePath:   filestorePath,
 		shutdownTrigger: shutdownTrigger,
 		adminMonitor:    adminMonitor,
+		scheduled:       scheduled,
 	}
 
 	h.Handle("/backup", bouncer.RestrictedAccess(adminAccess(httperror.LoggerHandler(h.backup)))).Methods(http.MethodPost)
+	h.Handle("/backup/settings", bouncer.RestrictedAccess(adminAccess(httperror.LoggerHandler(h.backupSettingsInspect)))).Methods(http.MethodGet)
+	h.Handle("/backup/settings", bouncer.RestrictedAccess(adminAccess(httperror.LoggerHandler(h.backupSettingsUpdate)))).Methods(http.MethodPut)
+	h.Handle("/backup/status", bouncer.RestrictedAccess(adminAccess(httperror.LoggerHandler(h.backupStatusInspect)))).Methods(http.MethodGet)
+	h.Handle("/backup/archives", bouncer.RestrictedAccess(adminAccess(httperror.LoggerHandler(h.backupArchiveList)))).Methods(http.MethodGet)
 	h.Handle("/restore", bouncer.PublicAccess(httperror.LoggerHandler(h.restore))).Methods(http.MethodPost)
+	h.Handle("/restore/destination", bouncer.RestrictedAccess(adminAccess(httperror.LoggerHandler(h.restoreFromDestination)))).Methods(http.MethodPost)
 
 	return h
 }
@@ -66,3 +66,59 @@ func decodeForm(r *http.Request, p *restorePayload) error {
 	p.Password = password
 	return nil
 }
+
+type restoreFromDestinationPayload struct {
+	// Name of the archive to restore, the most recent archive is used when empty
+	Archive string `example:"portainer-backup_2024-01-01_02-00-00.tar.gz"`
+	// Password used to decrypt the archive, the password of the backup settings is used when empty
+	Password string `example:"secret"`
+}
+
+func (payload *restoreFromDestinationPayload) Validate(r *http.Request) error {
+	return nil
+}
+
+// @id RestoreFromDestination
+// @summary Triggers a system restore using a backup archive stored at the backup destination
+// @description Triggers a system restore using a backup archive stored at the configured backup destination.
+// @description The current state of the instance is replaced by the content of the archive.
+// @description **Access policy**: administrator
+// @tags backup
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @param body body restoreFromDestinationPayload true "Restore request payload"
+// @success 200 "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /restore/destination [post]
+func (h *Handler) restoreFromDestination(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload restoreFromDestinationPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	// Only the destination configured by an administrator is used, a caller supplied one would let the server reach
+	// arbitrary hosts or read arbitrary directories
+	settings, err := h.scheduled.Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the backup settings from the database", err)
+	}
+
+	destination, err := operations.NewDestination(settings.Destination)
+	if err != nil {
+		return httperror.BadRequest("Invalid backup destination", err)
+	}
+
+	password := payload.Password
+	if password == "" {
+		password = settings.Password
+	}
+
+	err = operations.RestoreFromDestination(r.Context(), destination, payload.Archive, password, h.filestorePath, h.gate, h.dataStore, h.shutdownTrigger)
+	if err != nil {
+		return httperror.InternalServerError("Failed to restore the backup", err)
+	}
+
+	return nil
+}
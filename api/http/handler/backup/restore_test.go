@@ -8,6 +8,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -48,6 +49,9 @@ func Test_restoreArchive_usingCombinationOfPasswords(t *testing.T) {
 		},
 	}
 
+	// the restore copies the database of the archive in the file store
+	t.Cleanup(func() { os.Remove("./test_assets/handler_test/portainer.db") })
+
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			datastore := testhelpers.NewDatastore(
@@ -63,6 +67,7 @@ func Test_restoreArchive_usingCombinationOfPasswords(t *testing.T) {
 				"./test_assets/handler_test",
 				func() {},
 				adminMonitor,
+				nil,
 			)
 
 			// backup
@@ -94,6 +99,7 @@ func Test_restoreArchive_shouldFailIfSystemWasAlreadyInitialized(t *testing.T) {
 		"./test_assets/handler_test",
 		func() {},
 		adminMonitor,
+		nil,
 	)
 
 	// backup
@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	operations "github.com/portainer/portainer/api/backup"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+type backupSettingsPayload struct {
+	// Enable the scheduled backups
+	Enabled bool `example:"true"`
+	// Cron rule, in the standard 5 fields format, used to schedule the backups
+	CronRule string `example:"0 2 * * *"`
+	// Password used to encrypt the archives. Omit it to keep the current password, an empty value disables the encryption
+	Password *string `example:"secret"`
+	// Number of archives kept at the destination, 0 keeps them all
+	MaxBackups int `example:"7"`
+	// Where the archives are uploaded. An empty S3 secret access key keeps the current one
+	Destination portainer.BackupDestination
+}
+
+func (payload *backupSettingsPayload) Validate(r *http.Request) error {
+	return operations.ValidateSettings(&portainer.BackupSettings{
+		Enabled:     payload.Enabled,
+		CronRule:    payload.CronRule,
+		MaxBackups:  payload.MaxBackups,
+		Destination: payload.Destination,
+	})
+}
+
+// @id BackupSettingsInspect
+// @summary Retrieve the scheduled backup settings
+// @description Retrieve the scheduled backup settings, the secrets are not returned.
+// @description **Access policy**: admin
+// @tags backup
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {object} portainer.BackupSettings "Success"
+// @failure 500 "Server error"
+// @router /backup/settings [get]
+func (h *Handler) backupSettingsInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := h.scheduled.Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the backup settings from the database", err)
+	}
+
+	hideSecrets(settings)
+
+	return response.JSON(w, settings)
+}
+
+// @id BackupSettingsUpdate
+// @summary Update the scheduled backup settings
+// @description Update the scheduled backup settings and reschedule the backups accordingly.
+// @description **Access policy**: admin
+// @tags backup
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body backupSettingsPayload true "Backup settings"
+// @success 200 {object} portainer.BackupSettings "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /backup/settings [put]
+func (h *Handler) backupSettingsUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload backupSettingsPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	settings, err := h.scheduled.Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the backup settings from the database", err)
+	}
+
+	settings.Enabled = payload.Enabled
+	settings.CronRule = payload.CronRule
+	settings.MaxBackups = payload.MaxBackups
+
+	if payload.Password != nil {
+		settings.Password = *payload.Password
+	}
+
+	previousS3 := settings.Destination.S3
+	settings.Destination = payload.Destination
+
+	if s3 := settings.Destination.S3; s3 != nil && s3.SecretAccessKey == "" && previousS3 != nil {
+		s3.SecretAccessKey = previousS3.SecretAccessKey
+	}
+
+	if err := h.scheduled.UpdateSettings(settings); err != nil {
+		return httperror.InternalServerError("Unable to persist the backup settings inside the database", err)
+	}
+
+	if err := h.scheduled.Reschedule(settings); err != nil {
+		return httperror.InternalServerError("Unable to schedule the backups", err)
+	}
+
+	hideSecrets(settings)
+
+	return response.JSON(w, settings)
+}
+
+func hideSecrets(settings *portainer.BackupSettings) {
+	settings.Password = ""
+
+	if settings.Destination.S3 != nil {
+		settings.Destination.S3.SecretAccessKey = ""
+	}
+}
@@ -0,0 +1,140 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	operations "github.com/portainer/portainer/api/backup"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/offlinegate"
+	"github.com/portainer/portainer/api/scheduler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScheduledTestHandler(t *testing.T) (*Handler, *datastore.Store) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	gate := offlinegate.NewOfflineGate()
+	filestorePath := t.TempDir()
+
+	scheduled, err := operations.NewScheduledService(ctx, store, gate, filestorePath, scheduler.NewScheduler(ctx), []byte("secret key"))
+	require.NoError(t, err)
+
+	h := &Handler{
+		dataStore:     store,
+		gate:          gate,
+		filestorePath: filestorePath,
+		scheduled:     scheduled,
+	}
+
+	return h, store
+}
+
+func updateBackupSettings(t *testing.T, h *Handler, body string) portainer.BackupSettings {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPut, "/backup/settings", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	require.Nil(t, h.backupSettingsUpdate(w, r))
+
+	var settings portainer.BackupSettings
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&settings))
+
+	return settings
+}
+
+func Test_backupSettingsUpdate_keepsSecrets(t *testing.T) {
+	h, store := newScheduledTestHandler(t)
+
+	settings := updateBackupSettings(t, h, `{
+		"Enabled": true,
+		"CronRule": "0 2 * * *",
+		"Password": "password",
+		"MaxBackups": 3,
+		"Destination": {"Type": "s3", "S3": {"Bucket": "backups", "AccessKeyID": "access", "SecretAccessKey": "secret"}}
+	}`)
+
+	assert.Empty(t, settings.Password, "the password should not be returned")
+	assert.Empty(t, settings.Destination.S3.SecretAccessKey, "the secret access key should not be returned")
+
+	settings = updateBackupSettings(t, h, `{
+		"Enabled": true,
+		"CronRule": "0 3 * * *",
+		"MaxBackups": 5,
+		"Destination": {"Type": "s3", "S3": {"Bucket": "backups", "AccessKeyID": "access"}}
+	}`)
+	assert.Equal(t, "0 3 * * *", settings.CronRule)
+
+	encrypted, err := store.BackupSettings().Settings()
+	require.NoError(t, err)
+	assert.NotEqual(t, "password", encrypted.Password, "the password should be encrypted at rest")
+	assert.NotEqual(t, "secret", encrypted.Destination.S3.SecretAccessKey, "the secret access key should be encrypted at rest")
+
+	stored, err := h.scheduled.Settings()
+	require.NoError(t, err)
+	assert.Equal(t, "password", stored.Password)
+	assert.Equal(t, "secret", stored.Destination.S3.SecretAccessKey)
+	assert.Equal(t, 5, stored.MaxBackups)
+
+	updateBackupSettings(t, h, `{"Password": "", "Destination": {"Type": "local", "LocalPath": "/backups"}}`)
+
+	stored, err = h.scheduled.Settings()
+	require.NoError(t, err)
+	assert.Empty(t, stored.Password, "an empty password should disable the encryption")
+}
+
+func Test_backupSettingsUpdate_rejectsInvalidCronRule(t *testing.T) {
+	h, _ := newScheduledTestHandler(t)
+
+	r := httptest.NewRequest(http.MethodPut, "/backup/settings", strings.NewReader(`{
+		"Enabled": true,
+		"CronRule": "every night",
+		"Destination": {"Type": "local", "LocalPath": "/backups"}
+	}`))
+
+	handlerErr := h.backupSettingsUpdate(httptest.NewRecorder(), r)
+	require.NotNil(t, handlerErr)
+	assert.Equal(t, http.StatusBadRequest, handlerErr.StatusCode)
+}
+
+func Test_scheduledBackup_updatesStatus(t *testing.T) {
+	h, _ := newScheduledTestHandler(t)
+
+	backupDir := t.TempDir()
+
+	require.NoError(t, h.scheduled.UpdateSettings(&portainer.BackupSettings{
+		MaxBackups:  1,
+		Destination: portainer.BackupDestination{Type: portainer.BackupDestinationLocal, LocalPath: backupDir},
+	}))
+
+	require.NoError(t, h.scheduled.Run())
+
+	w := httptest.NewRecorder()
+	require.Nil(t, h.backupStatusInspect(w, httptest.NewRequest(http.MethodGet, "/backup/status", nil)))
+
+	var status portainer.BackupStatus
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&status))
+	assert.Empty(t, status.Error)
+	assert.NotZero(t, status.LastRunAt)
+	assert.NotZero(t, status.Size)
+	assert.True(t, strings.HasPrefix(status.Archive, operations.ArchivePrefix))
+
+	w = httptest.NewRecorder()
+	require.Nil(t, h.backupArchiveList(w, httptest.NewRequest(http.MethodGet, "/backup/archives", nil)))
+
+	var archives []operations.ArchiveInfo
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&archives))
+	require.Len(t, archives, 1)
+	assert.Equal(t, status.Archive, archives[0].Name)
+}
@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	operations "github.com/portainer/portainer/api/backup"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id BackupStatusInspect
+// @summary Retrieve the status of the last scheduled backup
+// @description Retrieve the date, the archive, the size and the error of the last scheduled backup.
+// @description **Access policy**: admin
+// @tags backup
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {object} portainer.BackupStatus "Success"
+// @failure 500 "Server error"
+// @router /backup/status [get]
+func (h *Handler) backupStatusInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	status, err := h.dataStore.BackupSettings().Status()
+	if h.dataStore.IsErrObjectNotFound(err) {
+		return response.JSON(w, &portainer.BackupStatus{})
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the backup status from the database", err)
+	}
+
+	return response.JSON(w, status)
+}
+
+// @id BackupArchiveList
+// @summary List the archives stored at the configured backup destination
+// @description **Access policy**: admin
+// @tags backup
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} operations.ArchiveInfo "Success"
+// @failure 400 "No backup destination is configured"
+// @failure 500 "Server error"
+// @router /backup/archives [get]
+func (h *Handler) backupArchiveList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := h.scheduled.Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the backup settings from the database", err)
+	}
+
+	destination, err := operations.NewDestination(settings.Destination)
+	if err != nil {
+		return httperror.BadRequest("Invalid backup destination", err)
+	}
+
+	archives, err := destination.List(r.Context())
+	if err != nil {
+		return httperror.InternalServerError("Unable to list the backup archives", err)
+	}
+
+	if archives == nil {
+		archives = []operations.ArchiveInfo{}
+	}
+
+	return response.JSON(w, archives)
+}
@@ -8,7 +8,10 @@ import (
 	"strconv"
 
 	portainer "github.com/portainer/portainer/api"
+	operations "github.com/portainer/portainer/api/customtemplates"
 	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/git"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
@@ -16,7 +19,6 @@ import (
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
-	"github.com/portainer/portainer/pkg/validate"
 
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/encoding/json"
@@ -125,7 +127,7 @@ func (payload *customTemplateFromFileContentPayload) Validate(r *http.Request) e
 		return errors.New("Invalid note. <img> tag is not supported")
 	}
 
-	return validateVariablesDefinitions(payload.Variables)
+	return operations.ValidateVariableDefinitions(payload.Variables)
 }
 
 func isValidNote(note string) bool {
@@ -209,6 +211,8 @@ type customTemplateFromGitRepositoryPayload struct {
 	RepositoryUsername string `example:"myGitUsername"`
 	// Password used in basic authentication. Required when RepositoryAuthentication is true.
 	RepositoryPassword string `example:"myGitPassword"`
+	// Git credential used in place of RepositoryUsername and RepositoryPassword
+	RepositoryGitCredentialID int `example:"0"`
 	// Path to the Stack file inside the Git repository
 	ComposeFilePathInRepository string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// Definitions of variables in the stack file
@@ -228,11 +232,11 @@ func (payload *customTemplateFromGitRepositoryPayload) Validate(r *http.Request)
 	if len(payload.Description) == 0 {
 		return errors.New("Invalid custom template description")
 	}
-	if len(payload.RepositoryURL) == 0 || !validate.IsURL(payload.RepositoryURL) {
+	if len(payload.RepositoryURL) == 0 || !git.IsRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && (len(payload.RepositoryUsername) == 0 || len(payload.RepositoryPassword) == 0) {
-		return errors.New("Invalid repository credentials. Username and password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID == 0 && (len(payload.RepositoryUsername) == 0 || len(payload.RepositoryPassword) == 0) {
+		return errors.New("Invalid repository credentials. Username and password or Git credential must be specified when authentication is enabled")
 	}
 	if len(payload.ComposeFilePathInRepository) == 0 {
 		payload.ComposeFilePathInRepository = filesystem.ComposeFileDefaultName
@@ -249,7 +253,7 @@ func (payload *customTemplateFromGitRepositoryPayload) Validate(r *http.Request)
 		return errors.New("Invalid note. <img> tag is not supported")
 	}
 
-	return validateVariablesDefinitions(payload.Variables)
+	return operations.ValidateVariableDefinitions(payload.Variables)
 }
 
 // @id CustomTemplateCreateRepository
@@ -299,7 +303,20 @@ func (handler *Handler) createCustomTemplateFromGitRepository(r *http.Request) (
 		TLSSkipVerify:  payload.TLSSkipVerify,
 	}
 
-	if payload.RepositoryAuthentication {
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID != 0 {
+		tokenData, err := security.RetrieveTokenData(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := gitcredentials.ValidateAccess(handler.DataStore, payload.RepositoryGitCredentialID, tokenData.ID); err != nil {
+			return nil, err
+		}
+
+		gitConfig.Authentication = &gittypes.GitAuthentication{
+			GitCredentialID: payload.RepositoryGitCredentialID,
+		}
+	} else if payload.RepositoryAuthentication {
 		gitConfig.Authentication = &gittypes.GitAuthentication{
 			Username: payload.RepositoryUsername,
 			Password: payload.RepositoryPassword,
@@ -421,7 +438,7 @@ func (payload *customTemplateFromFileUploadPayload) Validate(r *http.Request) er
 		if err := json.Unmarshal([]byte(varsString), &payload.Variables); err != nil {
 			return errors.New("Invalid variables. Ensure that the variables are valid JSON")
 		}
-		if err := validateVariablesDefinitions(payload.Variables); err != nil {
+		if err := operations.ValidateVariableDefinitions(payload.Variables); err != nil {
 			return err
 		}
 	}
@@ -2,14 +2,12 @@ package customtemplates
 
 import (
 	"net/http"
-	"strconv"
 
 	portainer "github.com/portainer/portainer/api"
+	operations "github.com/portainer/portainer/api/customtemplates"
 	"github.com/portainer/portainer/api/dataservices"
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
-	"github.com/portainer/portainer/api/internal/authorization"
-	"github.com/portainer/portainer/api/slicesx"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
@@ -44,31 +42,17 @@ func (handler *Handler) customTemplateInspect(w http.ResponseWriter, r *http.Req
 			return httperror.InternalServerError("Unable to find a custom template with the specified identifier inside the database", err)
 		}
 
-		resourceControl, err := tx.ResourceControl().ResourceControlByResourceIDAndType(strconv.Itoa(customTemplateID), portainer.CustomTemplateResourceControl)
-		if err != nil {
-			return httperror.InternalServerError("Unable to retrieve a resource control associated to the custom template", err)
-		}
-
 		securityContext, err := security.RetrieveRestrictedRequestContext(r)
 		if err != nil {
 			return httperror.InternalServerError("Unable to retrieve user info from request context", err)
 		}
 
-		canEdit := userCanEditTemplate(customTemplate, securityContext)
-		hasAccess := false
-
-		if resourceControl != nil {
-			customTemplate.ResourceControl = resourceControl
-
-			teamIDs := slicesx.Map(securityContext.UserMemberships, func(m portainer.TeamMembership) portainer.TeamID {
-				return m.TeamID
-			})
-
-			hasAccess = authorization.UserCanAccessResource(securityContext.UserID, teamIDs, resourceControl)
-
+		hasAccess, err := operations.UserCanAccess(tx, customTemplate, securityContext)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve a resource control associated to the custom template", err)
 		}
 
-		if canEdit || hasAccess {
+		if hasAccess {
 			return nil
 		}
 
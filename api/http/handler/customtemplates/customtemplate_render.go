@@ -0,0 +1,109 @@
+package customtemplates
+
+import (
+	"errors"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	operations "github.com/portainer/portainer/api/customtemplates"
+	"github.com/portainer/portainer/api/dataservices"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+type customTemplateRenderPayload struct {
+	// Values of the template variables, the default value of a variable is used when it has no value
+	Variables []portainer.Pair
+}
+
+func (payload *customTemplateRenderPayload) Validate(r *http.Request) error {
+	return nil
+}
+
+type customTemplateRenderResponse struct {
+	// Rendered content of the template file
+	FileContent string
+	// Values the template was rendered with, including the default values
+	Variables []portainer.Pair
+}
+
+// @id CustomTemplateRender
+// @summary Render a custom template
+// @description Substitute the variables of the custom template file with the provided values and the default values.
+// @description The values are validated against the type and the pattern of the variable definitions.
+// @description **Access policy**: authenticated
+// @tags custom_templates
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Template identifier"
+// @param body body customTemplateRenderPayload true "Values of the template variables"
+// @success 200 {object} customTemplateRenderResponse "Success"
+// @failure 400 "Invalid request or invalid variable values"
+// @failure 403 "Access denied to the template"
+// @failure 404 "Custom template not found"
+// @failure 500 "Server error"
+// @router /custom_templates/{id}/render [post]
+func (handler *Handler) customTemplateRender(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	customTemplateID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid custom template identifier route variable", err)
+	}
+
+	var payload customTemplateRenderPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user info from request context", err)
+	}
+
+	var customTemplate *portainer.CustomTemplate
+	if err := handler.DataStore.ViewTx(func(tx dataservices.DataStoreTx) error {
+		customTemplate, err = tx.CustomTemplate().Read(portainer.CustomTemplateID(customTemplateID))
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return httperror.NotFound("Unable to find a custom template with the specified identifier inside the database", err)
+		} else if err != nil {
+			return httperror.InternalServerError("Unable to find a custom template with the specified identifier inside the database", err)
+		}
+
+		hasAccess, err := operations.UserCanAccess(tx, customTemplate, securityContext)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve a resource control associated to the custom template", err)
+		} else if !hasAccess {
+			return httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+		}
+
+		return nil
+	}); err != nil {
+		var httpErr *httperror.HandlerError
+		if errors.As(err, &httpErr) {
+			return httpErr
+		}
+
+		return httperror.InternalServerError("Unexpected error", err)
+	}
+
+	variables, err := operations.ResolveVariables(customTemplate.Variables, payload.Variables)
+	if err != nil {
+		return httperror.BadRequest("Invalid template variables", err)
+	}
+
+	fileContent, err := operations.FileContent(handler.FileService, customTemplate)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve custom template file from disk", err)
+	}
+
+	rendered, err := operations.Render(string(fileContent), variables)
+	if err != nil {
+		return httperror.InternalServerError("Unable to render the custom template", err)
+	}
+
+	return response.JSON(w, &customTemplateRenderResponse{FileContent: rendered, Variables: variables})
+}
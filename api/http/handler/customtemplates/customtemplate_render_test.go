@@ -0,0 +1,89 @@
+package customtemplates
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/mux"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHandler(t *testing.T) {
+	_, ds := datastore.MustNewTestStore(t, true, false)
+
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "docker-compose.yml"), []byte("image: nginx:{{ TAG }}\nports: [\"{{ PORT }}:80\"]\nenv: {{ ENV }}\n"), 0o600))
+
+	require.NoError(t, ds.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		require.NoError(t, tx.User().Create(&portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}))
+		require.NoError(t, tx.User().Create(&portainer.User{ID: 2, Username: "std", Role: portainer.StandardUserRole}))
+
+		return tx.CustomTemplate().Create(&portainer.CustomTemplate{
+			ID:          1,
+			ProjectPath: projectPath,
+			EntryPoint:  "docker-compose.yml",
+			Variables: []portainer.CustomTemplateVariableDefinition{
+				{Name: "TAG", Label: "Tag", DefaultValue: "latest"},
+				{Name: "PORT", Label: "Port", Type: portainer.CustomTemplateVariableNumber, Required: true},
+				{Name: "ENV", Label: "Environment", Pattern: "dev|prod"},
+			},
+		})
+	}))
+
+	handler := NewHandler(testhelpers.NewTestRequestBouncer(), ds, &TestFileService{}, nil)
+
+	test := func(restrictedContext *security.RestrictedRequestContext, variables ...portainer.Pair) (*httptest.ResponseRecorder, *httperror.HandlerError) {
+		body, err := json.Marshal(customTemplateRenderPayload{Variables: variables})
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodPost, "/custom_templates/1/render", bytes.NewReader(body))
+		r = mux.SetURLVars(r, map[string]string{"id": "1"})
+		r = r.WithContext(security.StoreRestrictedRequestContext(r, restrictedContext))
+
+		rr := httptest.NewRecorder()
+
+		return rr, handler.customTemplateRender(rr, r)
+	}
+
+	admin := &security.RestrictedRequestContext{UserID: 1, IsAdmin: true}
+
+	t.Run("renders the template with the values and the defaults", func(t *testing.T) {
+		rr, httpErr := test(admin, portainer.Pair{Name: "PORT", Value: "8080"}, portainer.Pair{Name: "ENV", Value: "prod"})
+		require.Nil(t, httpErr)
+
+		var resp customTemplateRenderResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		require.Equal(t, "image: nginx:latest\nports: [\"8080:80\"]\nenv: prod\n", resp.FileContent)
+		require.Equal(t, []portainer.Pair{{Name: "TAG", Value: "latest"}, {Name: "PORT", Value: "8080"}, {Name: "ENV", Value: "prod"}}, resp.Variables)
+	})
+
+	t.Run("rejects invalid values", func(t *testing.T) {
+		for name, variables := range map[string][]portainer.Pair{
+			"missing required value": {{Name: "ENV", Value: "dev"}},
+			"invalid number":         {{Name: "PORT", Value: "http"}},
+			"pattern mismatch":       {{Name: "PORT", Value: "80"}, {Name: "ENV", Value: "staging"}},
+			"unknown variable":       {{Name: "PORT", Value: "80"}, {Name: "NAME", Value: "web"}},
+		} {
+			_, httpErr := test(admin, variables...)
+			require.NotNil(t, httpErr, name)
+			require.Equal(t, http.StatusBadRequest, httpErr.StatusCode, name)
+		}
+	})
+
+	t.Run("std should not render adminonly template", func(t *testing.T) {
+		_, httpErr := test(&security.RestrictedRequestContext{UserID: 2}, portainer.Pair{Name: "PORT", Value: "80"})
+		require.NotNil(t, httpErr)
+		require.Equal(t, http.StatusForbidden, httpErr.StatusCode)
+	})
+}
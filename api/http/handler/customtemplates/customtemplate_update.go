@@ -7,15 +7,16 @@ import (
 	"strconv"
 
 	portainer "github.com/portainer/portainer/api"
+	operations "github.com/portainer/portainer/api/customtemplates"
 	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/git"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
-	"github.com/portainer/portainer/pkg/validate"
 )
 
 type customTemplateUpdatePayload struct {
@@ -89,15 +90,15 @@ func (payload *customTemplateUpdatePayload) Validate(r *http.Request) error {
 		return errors.New("Invalid note. <img> tag is not supported")
 	}
 
-	if payload.RepositoryAuthentication && (len(payload.RepositoryUsername) == 0 || len(payload.RepositoryPassword) == 0) {
-		return errors.New("Invalid repository credentials. Username and password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID == 0 && (len(payload.RepositoryUsername) == 0 || len(payload.RepositoryPassword) == 0) {
+		return errors.New("Invalid repository credentials. Username and password or Git credential must be specified when authentication is enabled")
 	}
 
 	if len(payload.ComposeFilePathInRepository) == 0 {
 		payload.ComposeFilePathInRepository = filesystem.ComposeFileDefaultName
 	}
 
-	if err := validateVariablesDefinitions(payload.Variables); err != nil {
+	if err := operations.ValidateVariableDefinitions(payload.Variables); err != nil {
 		return err
 	}
 
@@ -171,7 +172,7 @@ func (handler *Handler) customTemplateUpdate(w http.ResponseWriter, r *http.Requ
 	customTemplate.EdgeTemplate = payload.EdgeTemplate
 
 	if payload.RepositoryURL != "" {
-		if !validate.IsURL(payload.RepositoryURL) {
+		if !git.IsRepositoryURL(payload.RepositoryURL) {
 			return httperror.BadRequest("Invalid repository URL. Must correspond to a valid URL format", err)
 		}
 
@@ -182,13 +183,19 @@ func (handler *Handler) customTemplateUpdate(w http.ResponseWriter, r *http.Requ
 			TLSSkipVerify:  payload.TLSSkipVerify,
 		}
 
-		repositoryUsername := ""
-		repositoryPassword := ""
-		repositoryAuthType := gittypes.GitCredentialAuthType_Basic
-		if payload.RepositoryAuthentication {
-			repositoryUsername = payload.RepositoryUsername
-			repositoryPassword = payload.RepositoryPassword
-			repositoryAuthType = payload.RepositoryAuthorizationType
+		if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID != 0 {
+			// A credential already referenced by the template can be kept by any user allowed to edit it
+			current := customTemplate.GitConfig
+			if current == nil || current.Authentication == nil || current.Authentication.GitCredentialID != payload.RepositoryGitCredentialID {
+				if err := gitcredentials.ValidateAccess(handler.DataStore, payload.RepositoryGitCredentialID, securityContext.UserID); err != nil {
+					return httperror.Forbidden("Permission denied to use the Git credential", err)
+				}
+			}
+
+			gitConfig.Authentication = &gittypes.GitAuthentication{
+				GitCredentialID: payload.RepositoryGitCredentialID,
+			}
+		} else if payload.RepositoryAuthentication {
 			gitConfig.Authentication = &gittypes.GitAuthentication{
 				Username:          payload.RepositoryUsername,
 				Password:          payload.RepositoryPassword,
@@ -196,6 +203,11 @@ func (handler *Handler) customTemplateUpdate(w http.ResponseWriter, r *http.Requ
 			}
 		}
 
+		repositoryUsername, repositoryPassword, repositoryAuthType, err := git.GetCredentials(handler.GitService, gitConfig.Authentication)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the Git credential", err)
+		}
+
 		cleanBackup, err := git.CloneWithBackup(handler.GitService, handler.FileService, git.CloneOptions{
 			ProjectPath:   customTemplate.ProjectPath,
 			URL:           gitConfig.URL,
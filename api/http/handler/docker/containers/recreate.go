@@ -7,12 +7,9 @@ import (
 	"github.com/portainer/portainer/api/docker/consts"
 	"github.com/portainer/portainer/api/docker/images"
 	"github.com/portainer/portainer/api/http/middlewares"
-	"github.com/portainer/portainer/api/internal/authorization"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
-
-	"github.com/rs/zerolog/log"
 )
 
 type RecreatePayload struct {
@@ -51,8 +48,7 @@ func (handler *Handler) recreate(w http.ResponseWriter, r *http.Request) *httper
 		return httperror.InternalServerError("Error recreating container", err)
 	}
 
-	handler.updateWebhook(containerID, newContainer.ID)
-	handler.createResourceControl(containerID, newContainer.ID)
+	handler.containerService.TransferResources(containerID, newContainer.ID)
 
 	go func() {
 		images.EvictImageStatus(containerID)
@@ -62,36 +58,3 @@ func (handler *Handler) recreate(w http.ResponseWriter, r *http.Request) *httper
 
 	return response.JSON(w, newContainer)
 }
-
-func (handler *Handler) createResourceControl(oldContainerId string, newContainerId string) {
-	resourceControls, err := handler.dataStore.ResourceControl().ReadAll()
-	if err != nil {
-		log.Error().Err(err).Msg("Exporting Resource Controls")
-
-		return
-	}
-
-	resourceControl := authorization.GetResourceControlByResourceIDAndType(oldContainerId, portainer.ContainerResourceControl, resourceControls)
-	if resourceControl == nil {
-		return
-	}
-
-	resourceControl.ResourceID = newContainerId
-	if err := handler.dataStore.ResourceControl().Create(resourceControl); err != nil {
-		log.Error().Err(err).Str("containerId", newContainerId).Msg("Failed to create new resource control for container")
-	}
-}
-
-func (handler *Handler) updateWebhook(oldContainerId string, newContainerId string) {
-	webhook, err := handler.dataStore.Webhook().WebhookByResourceID(oldContainerId)
-	if err != nil {
-		log.Error().Err(err).Str("containerId", oldContainerId).Msg("cannot find webhook by containerId")
-
-		return
-	}
-
-	webhook.ResourceID = newContainerId
-	if err := handler.dataStore.Webhook().Update(webhook.ID, webhook); err != nil {
-		log.Error().Err(err).Int("webhookId", int(webhook.ID)).Msg("cannot update webhook")
-	}
-}
@@ -7,6 +7,7 @@ import (
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/docker"
 	dockerclient "github.com/portainer/portainer/api/docker/client"
+	"github.com/portainer/portainer/api/docker/imageupdate"
 	"github.com/portainer/portainer/api/http/handler/docker/containers"
 	"github.com/portainer/portainer/api/http/handler/docker/images"
 	"github.com/portainer/portainer/api/http/middlewares"
@@ -26,10 +27,11 @@ type Handler struct {
 	dockerClientFactory  *dockerclient.ClientFactory
 	authorizationService *authorization.Service
 	containerService     *docker.ContainerService
+	imageUpdateService   *imageupdate.Service
 }
 
 // NewHandler creates a handler to process non-proxied requests to docker APIs directly.
-func NewHandler(bouncer security.BouncerService, authorizationService *authorization.Service, dataStore dataservices.DataStore, dockerClientFactory *dockerclient.ClientFactory, containerService *docker.ContainerService) *Handler {
+func NewHandler(bouncer security.BouncerService, authorizationService *authorization.Service, dataStore dataservices.DataStore, dockerClientFactory *dockerclient.ClientFactory, containerService *docker.ContainerService, imageUpdateService *imageupdate.Service) *Handler {
 	h := &Handler{
 		Router:               mux.NewRouter(),
 		requestBouncer:       bouncer,
@@ -37,6 +39,7 @@ func NewHandler(bouncer security.BouncerService, authorizationService *authoriza
 		dataStore:            dataStore,
 		dockerClientFactory:  dockerClientFactory,
 		containerService:     containerService,
+		imageUpdateService:   imageUpdateService,
 	}
 
 	// endpoints
@@ -46,6 +49,12 @@ func NewHandler(bouncer security.BouncerService, authorizationService *authoriza
 
 	endpointRouter.Handle("/dashboard", httperror.LoggerHandler(h.dashboard)).Methods(http.MethodGet)
 
+	adminRouter := endpointRouter.NewRoute().Subrouter()
+	adminRouter.Use(bouncer.AdminAccess)
+	adminRouter.Handle("/image_update_policy", httperror.LoggerHandler(h.imageUpdatePolicyInspect)).Methods(http.MethodGet)
+	adminRouter.Handle("/image_update_policy", httperror.LoggerHandler(h.imageUpdatePolicyUpdate)).Methods(http.MethodPut)
+	adminRouter.Handle("/image_update_policy/run", httperror.LoggerHandler(h.imageUpdatePolicyRun)).Methods(http.MethodPost)
+
 	containersHandler := containers.NewHandler("/docker/{id}/containers", bouncer, dataStore, dockerClientFactory, containerService)
 	endpointRouter.PathPrefix("/containers").Handler(containersHandler)
 
@@ -0,0 +1,167 @@
+package docker
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/docker/imageupdate"
+	"github.com/portainer/portainer/api/http/middlewares"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+type imageUpdatePolicyPayload struct {
+	// Enable the scheduled image updates
+	Enabled bool `example:"true"`
+	// Interval between two runs, in the Go duration format
+	Interval string `example:"6h"`
+	// Only update the containers matching the selector, a comma separated list of key or key=value
+	LabelSelector string `example:"com.example.autoupdate=true"`
+	// What to do with the outdated containers of a compose stack, skip (default) or redeploy
+	StackStrategy portainer.ImageUpdateStackStrategy `example:"redeploy" enums:"skip,redeploy"`
+	// Time in seconds given to an updated container to become healthy before rolling back, 60 when omitted
+	HealthcheckTimeout int `example:"120"`
+}
+
+func (payload *imageUpdatePolicyPayload) Validate(r *http.Request) error {
+	return imageupdate.ValidatePolicy(&portainer.ImageUpdatePolicy{
+		Enabled:            payload.Enabled,
+		Interval:           payload.Interval,
+		LabelSelector:      payload.LabelSelector,
+		StackStrategy:      cmp.Or(payload.StackStrategy, portainer.ImageUpdateStackSkip),
+		HealthcheckTimeout: payload.HealthcheckTimeout,
+	})
+}
+
+// @id DockerImageUpdatePolicyInspect
+// @summary Retrieve the image update policy of an environment
+// @description Retrieve the image update policy of a Docker environment with the results of its last runs.
+// @description **Access policy**: administrator
+// @tags docker
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param environmentId path int true "Environment identifier"
+// @success 200 {object} portainer.ImageUpdatePolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Environment not found"
+// @failure 500 "Server error"
+// @router /docker/{environmentId}/image_update_policy [get]
+func (h *Handler) imageUpdatePolicyInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, err := middlewares.FetchEndpoint(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to find an environment on request context", err)
+	}
+
+	policy, err := h.dataStore.ImageUpdatePolicy().Read(endpoint.ID)
+	if h.dataStore.IsErrObjectNotFound(err) {
+		return response.JSON(w, &portainer.ImageUpdatePolicy{
+			ID:            endpoint.ID,
+			StackStrategy: portainer.ImageUpdateStackSkip,
+			Runs:          []portainer.ImageUpdateRun{},
+		})
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the image update policy from the database", err)
+	}
+
+	return response.JSON(w, policy)
+}
+
+// @id DockerImageUpdatePolicyUpdate
+// @summary Update the image update policy of an environment
+// @description Update the image update policy of a Docker environment and reschedule its runs accordingly.
+// @description Containers labelled io.portainer.imageupdate.enable=false are never updated.
+// @description **Access policy**: administrator
+// @tags docker
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param environmentId path int true "Environment identifier"
+// @param body body imageUpdatePolicyPayload true "Image update policy"
+// @success 200 {object} portainer.ImageUpdatePolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Environment not found"
+// @failure 500 "Server error"
+// @router /docker/{environmentId}/image_update_policy [put]
+func (h *Handler) imageUpdatePolicyUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, err := middlewares.FetchEndpoint(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to find an environment on request context", err)
+	}
+
+	var payload imageUpdatePolicyPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	policy, err := h.dataStore.ImageUpdatePolicy().Read(endpoint.ID)
+	if h.dataStore.IsErrObjectNotFound(err) {
+		policy = &portainer.ImageUpdatePolicy{ID: endpoint.ID, Runs: []portainer.ImageUpdateRun{}}
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the image update policy from the database", err)
+	}
+
+	exists := err == nil
+
+	policy.Enabled = payload.Enabled
+	policy.Interval = payload.Interval
+	policy.LabelSelector = payload.LabelSelector
+	policy.StackStrategy = cmp.Or(payload.StackStrategy, portainer.ImageUpdateStackSkip)
+	policy.HealthcheckTimeout = payload.HealthcheckTimeout
+
+	if exists {
+		err = h.dataStore.ImageUpdatePolicy().Update(endpoint.ID, policy)
+	} else {
+		err = h.dataStore.ImageUpdatePolicy().Create(policy)
+	}
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the image update policy inside the database", err)
+	}
+
+	if err := h.imageUpdateService.Reschedule(policy); err != nil {
+		return httperror.InternalServerError("Unable to schedule the image updates", err)
+	}
+
+	return response.JSON(w, policy)
+}
+
+// @id DockerImageUpdatePolicyRun
+// @summary Run the image update policy of an environment
+// @description Update the outdated containers of a Docker environment now, whether its policy is enabled or not.
+// @description The request returns once all the containers are updated.
+// @description **Access policy**: administrator
+// @tags docker
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param environmentId path int true "Environment identifier"
+// @success 200 {object} portainer.ImageUpdateRun "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Environment or image update policy not found"
+// @failure 409 "An image update is already running for this environment"
+// @failure 500 "Server error"
+// @router /docker/{environmentId}/image_update_policy/run [post]
+func (h *Handler) imageUpdatePolicyRun(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, err := middlewares.FetchEndpoint(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to find an environment on request context", err)
+	}
+
+	// The update goes on when the client disconnects, a cancelled context would leave the containers on images whose
+	// health is not verified, without rolling them back
+	run, err := h.imageUpdateService.Run(context.WithoutCancel(r.Context()), endpoint.ID)
+	if h.dataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find the image update policy of the environment", err)
+	} else if errors.Is(err, imageupdate.ErrRunInProgress) {
+		return httperror.Conflict("An image update is already running for this environment", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to update the images of the environment", err)
+	}
+
+	return response.JSON(w, run)
+}
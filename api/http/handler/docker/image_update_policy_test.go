@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/docker/imageupdate"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	"github.com/portainer/portainer/api/scheduler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_imageUpdatePolicyUpdate(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	require.NoError(t, store.Endpoint().Create(&portainer.Endpoint{ID: 1, Name: "local", Type: portainer.DockerEnvironment}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	service := imageupdate.NewService(ctx, store, nil, nil, nil, scheduler.NewScheduler(ctx))
+	h := NewHandler(testhelpers.NewTestRequestBouncer(), nil, store, nil, nil, service)
+
+	do := func(method, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/docker/1/image_update_policy", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var policy portainer.ImageUpdatePolicy
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&policy))
+	assert.False(t, policy.Enabled)
+	assert.Equal(t, portainer.ImageUpdateStackSkip, policy.StackStrategy)
+
+	w = do(http.MethodPut, `{"Enabled": true, "Interval": "1s"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "intervals under a minute should be rejected")
+
+	w = do(http.MethodPut, `{"Enabled": true, "Interval": "6h", "LabelSelector": "autoupdate=true", "StackStrategy": "redeploy"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	stored, err := store.ImageUpdatePolicy().Read(1)
+	require.NoError(t, err)
+	assert.True(t, stored.Enabled)
+	assert.Equal(t, "6h", stored.Interval)
+	assert.Equal(t, "autoupdate=true", stored.LabelSelector)
+	assert.Equal(t, portainer.ImageUpdateStackRedeploy, stored.StackStrategy)
+
+	w = do(http.MethodPut, `{"Enabled": false}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	stored, err = store.ImageUpdatePolicy().Read(1)
+	require.NoError(t, err)
+	assert.False(t, stored.Enabled)
+	assert.Equal(t, portainer.ImageUpdateStackSkip, stored.StackStrategy)
+}
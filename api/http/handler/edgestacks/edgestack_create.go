@@ -1,12 +1,15 @@
 package edgestacks
 
 import (
+	"maps"
 	"net/http"
+	"slices"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
+	edgestackservice "github.com/portainer/portainer/api/internal/edge/edgestacks"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
@@ -55,3 +58,45 @@ func (handler *Handler) createSwarmStack(tx dataservices.DataStoreTx, method str
 
 	return nil, httperrors.NewInvalidPayloadError("Invalid value for query parameter: method. Value must be one of: string, repository or file")
 }
+
+// edgeStackEnvPayload holds the environment variables of an edge stack and their overrides. The values can use the
+// metadata of the environment, e.g. {{ PORTAINER_ENVIRONMENT_NAME }}. The omitted fields are left unchanged, an empty
+// list or map removes the variables
+type edgeStackEnvPayload struct {
+	// A list of environment variables used by the stack on every environment
+	Env []portainer.Pair
+	// Environment variables overriding Env on the environments of an edge group
+	EdgeGroupEnv map[portainer.EdgeGroupID][]portainer.Pair
+	// Environment variables overriding Env and EdgeGroupEnv on an environment
+	EndpointEnv map[portainer.EndpointID][]portainer.Pair
+}
+
+func (payload *edgeStackEnvPayload) validate() error {
+	if err := edgestackservice.ValidateEnv(payload.Env, payload.EdgeGroupEnv, payload.EndpointEnv); err != nil {
+		return httperrors.NewInvalidPayloadError(err.Error())
+	}
+
+	return nil
+}
+
+// changes returns whether the payload changes the environment variables of the stack
+func (payload *edgeStackEnvPayload) changes(stack *portainer.EdgeStack) bool {
+	return (payload.Env != nil && !slices.Equal(stack.Env, payload.Env)) ||
+		(payload.EdgeGroupEnv != nil && !maps.EqualFunc(stack.EdgeGroupEnv, payload.EdgeGroupEnv, slices.Equal)) ||
+		(payload.EndpointEnv != nil && !maps.EqualFunc(stack.EndpointEnv, payload.EndpointEnv, slices.Equal))
+}
+
+// applyTo sets the environment variables of the stack provided in the payload
+func (payload *edgeStackEnvPayload) applyTo(stack *portainer.EdgeStack) {
+	if payload.Env != nil {
+		stack.Env = payload.Env
+	}
+
+	if payload.EdgeGroupEnv != nil {
+		stack.EdgeGroupEnv = payload.EdgeGroupEnv
+	}
+
+	if payload.EndpointEnv != nil {
+		stack.EndpointEnv = payload.EndpointEnv
+	}
+}
@@ -29,6 +29,8 @@ type edgeStackFromFileUploadPayload struct {
 	Registries     []portainer.RegistryID
 	// Uses the manifest's namespaces instead of the default one
 	UseManifestNamespaces bool
+
+	edgeStackEnvPayload
 }
 
 func (payload *edgeStackFromFileUploadPayload) Validate(r *http.Request) error {
@@ -74,7 +76,19 @@ func (payload *edgeStackFromFileUploadPayload) Validate(r *http.Request) error {
 	useManifestNamespaces, _ := request.RetrieveBooleanMultiPartFormValue(r, "UseManifestNamespaces", true)
 	payload.UseManifestNamespaces = useManifestNamespaces
 
-	return nil
+	if err := request.RetrieveMultiPartFormJSONValue(r, "Env", &payload.Env, true); err != nil {
+		return httperrors.NewInvalidPayloadError("Invalid environment variables")
+	}
+
+	if err := request.RetrieveMultiPartFormJSONValue(r, "EdgeGroupEnv", &payload.EdgeGroupEnv, true); err != nil {
+		return httperrors.NewInvalidPayloadError("Invalid edge group environment variables")
+	}
+
+	if err := request.RetrieveMultiPartFormJSONValue(r, "EndpointEnv", &payload.EndpointEnv, true); err != nil {
+		return httperrors.NewInvalidPayloadError("Invalid environment(endpoint) environment variables")
+	}
+
+	return payload.edgeStackEnvPayload.validate()
 }
 
 // @id EdgeStackCreateFile
@@ -93,6 +107,9 @@ func (payload *edgeStackFromFileUploadPayload) Validate(r *http.Request) error {
 // @param UseManifestNamespaces formData bool false "Uses the manifest's namespaces instead of the default one, relevant only for kube environments"
 // @param PrePullImage formData bool false "Pre Pull image"
 // @param RetryDeploy formData bool false "Retry deploy"
+// @param Env formData string false "JSON stringified array of environment variables used by the stack on every environment"
+// @param EdgeGroupEnv formData string false "JSON stringified object of environment variables overriding Env, by Edge Group id"
+// @param EndpointEnv formData string false "JSON stringified object of environment variables overriding Env and EdgeGroupEnv, by environment id"
 // @param dryrun query string false "if true, will not create an edge stack, but just will check the settings and return a non-persisted edge stack object"
 // @success 200 {object} portainer.EdgeStack
 // @failure 400 "Bad request"
@@ -110,6 +127,8 @@ func (handler *Handler) createEdgeStackFromFileUpload(r *http.Request, tx datase
 		return nil, errors.Wrap(err, "failed to create edge stack object")
 	}
 
+	payload.applyTo(stack)
+
 	if dryrun {
 		return stack, nil
 	}
@@ -7,11 +7,12 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/git"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/pkg/edge"
 	"github.com/portainer/portainer/pkg/libhttp/request"
-	"github.com/portainer/portainer/pkg/validate"
 
 	"github.com/pkg/errors"
 )
@@ -35,6 +36,8 @@ type edgeStackFromGitRepositoryPayload struct {
 	RepositoryPassword string `example:"myGitPassword"`
 	// RepositoryAuthorizationType is the authorization type to use
 	RepositoryAuthorizationType gittypes.GitCredentialAuthType `example:"0"`
+	// Git credential used in place of RepositoryUsername and RepositoryPassword
+	RepositoryGitCredentialID int `example:"0"`
 	// Path to the Stack file inside the Git repository
 	FilePathInRepository string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// List of identifiers of EdgeGroups
@@ -50,6 +53,8 @@ type edgeStackFromGitRepositoryPayload struct {
 	UseManifestNamespaces bool
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
+
+	edgeStackEnvPayload
 }
 
 func (payload *edgeStackFromGitRepositoryPayload) Validate(r *http.Request) error {
@@ -61,12 +66,12 @@ func (payload *edgeStackFromGitRepositoryPayload) Validate(r *http.Request) erro
 		return httperrors.NewInvalidPayloadError("Invalid stack name. Stack name must only consist of lowercase alpha characters, numbers, hyphens, or underscores as well as start with a lowercase character or number")
 	}
 
-	if len(payload.RepositoryURL) == 0 || !validate.IsURL(payload.RepositoryURL) {
+	if len(payload.RepositoryURL) == 0 || !git.IsRepositoryURL(payload.RepositoryURL) {
 		return httperrors.NewInvalidPayloadError("Invalid repository URL. Must correspond to a valid URL format")
 	}
 
-	if payload.RepositoryAuthentication && len(payload.RepositoryPassword) == 0 {
-		return httperrors.NewInvalidPayloadError("Invalid repository credentials. Password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && len(payload.RepositoryPassword) == 0 && payload.RepositoryGitCredentialID == 0 {
+		return httperrors.NewInvalidPayloadError("Invalid repository credentials. Password or Git credential must be specified when authentication is enabled")
 	}
 
 	if payload.DeploymentType != portainer.EdgeStackDeploymentCompose && payload.DeploymentType != portainer.EdgeStackDeploymentKubernetes {
@@ -86,7 +91,7 @@ func (payload *edgeStackFromGitRepositoryPayload) Validate(r *http.Request) erro
 		return httperrors.NewInvalidPayloadError("Invalid edge groups. At least one edge group must be specified")
 	}
 
-	return nil
+	return payload.edgeStackEnvPayload.validate()
 }
 
 // @id EdgeStackCreateRepository
@@ -114,6 +119,8 @@ func (handler *Handler) createEdgeStackFromGitRepository(r *http.Request, tx dat
 		return nil, errors.Wrap(err, "failed to create edge stack object")
 	}
 
+	payload.applyTo(stack)
+
 	if dryrun {
 		return stack, nil
 	}
@@ -125,7 +132,15 @@ func (handler *Handler) createEdgeStackFromGitRepository(r *http.Request, tx dat
 		TLSSkipVerify:  payload.TLSSkipVerify,
 	}
 
-	if payload.RepositoryAuthentication {
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID != 0 {
+		if err := gitcredentials.ValidateAccess(tx, payload.RepositoryGitCredentialID, userID); err != nil {
+			return nil, httperrors.NewInvalidPayloadError(err.Error())
+		}
+
+		repoConfig.Authentication = &gittypes.GitAuthentication{
+			GitCredentialID: payload.RepositoryGitCredentialID,
+		}
+	} else if payload.RepositoryAuthentication {
 		repoConfig.Authentication = &gittypes.GitAuthentication{
 			Username:          payload.RepositoryUsername,
 			Password:          payload.RepositoryPassword,
@@ -146,13 +161,9 @@ func (handler *Handler) storeManifestFromGitRepository(tx dataservices.DataStore
 	}
 
 	projectPath = handler.FileService.GetEdgeStackProjectPath(stackFolder)
-	repositoryUsername := ""
-	repositoryPassword := ""
-	repositoryAuthType := gittypes.GitCredentialAuthType_Basic
-	if repositoryConfig.Authentication != nil && repositoryConfig.Authentication.Password != "" {
-		repositoryUsername = repositoryConfig.Authentication.Username
-		repositoryPassword = repositoryConfig.Authentication.Password
-		repositoryAuthType = repositoryConfig.Authentication.AuthorizationType
+	repositoryUsername, repositoryPassword, repositoryAuthType, err := git.GetCredentials(handler.GitService, repositoryConfig.Authentication)
+	if err != nil {
+		return "", "", "", err
 	}
 
 	if err := handler.GitService.CloneRepository(
@@ -34,6 +34,8 @@ type edgeStackFromStringPayload struct {
 	Registries []portainer.RegistryID
 	// Uses the manifest's namespaces instead of the default one
 	UseManifestNamespaces bool
+
+	edgeStackEnvPayload
 }
 
 func (payload *edgeStackFromStringPayload) Validate(r *http.Request) error {
@@ -57,7 +59,7 @@ func (payload *edgeStackFromStringPayload) Validate(r *http.Request) error {
 		return httperrors.NewInvalidPayloadError("Invalid deployment type")
 	}
 
-	return nil
+	return payload.edgeStackEnvPayload.validate()
 }
 
 // @id EdgeStackCreateString
@@ -85,6 +87,8 @@ func (handler *Handler) createEdgeStackFromFileContent(r *http.Request, tx datas
 		return nil, errors.Wrap(err, "failed to create Edge stack object")
 	}
 
+	payload.applyTo(stack)
+
 	if dryrun {
 		return stack, nil
 	}
@@ -53,5 +53,11 @@ func (handler *Handler) deleteEdgeStack(tx dataservices.DataStoreTx, edgeStackID
 		return httperror.InternalServerError("Unable to remove edge stack project folder", err)
 	}
 
+	if edgeStack.Rollout != nil && edgeStack.Rollout.PreviousProjectPath != "" {
+		if err := handler.FileService.RemoveDirectory(edgeStack.Rollout.PreviousProjectPath); err != nil {
+			return httperror.InternalServerError("Unable to remove the folder of the previous version of the edge stack", err)
+		}
+	}
+
 	return nil
 }
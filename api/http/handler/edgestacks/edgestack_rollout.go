@@ -0,0 +1,105 @@
+package edgestacks
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	edgestackservice "github.com/portainer/portainer/api/internal/edge/edgestacks"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id EdgeStackRolloutPromote
+// @summary Promote the rollout of an EdgeStack
+// @description Release the version being rolled out to the next batch of environments, without waiting for the
+// @description environments of the previous batch or the batch interval.
+// @description **Access policy**: administrator
+// @tags edge_stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "EdgeStack Id"
+// @success 200 {object} portainer.EdgeStack
+// @failure 400 "The edge stack has no rollout in progress"
+// @failure 404
+// @failure 500
+// @failure 503 "Edge compute features are disabled"
+// @router /edge_stacks/{id}/rollout/promote [post]
+func (handler *Handler) edgeStackRolloutPromote(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	return handler.updateRollout(w, r, func(tx dataservices.DataStoreTx, stack *portainer.EdgeStack) error {
+		return edgestackservice.PromoteRollout(tx, stack, time.Now())
+	})
+}
+
+// @id EdgeStackRolloutAbort
+// @summary Abort the rollout of an EdgeStack
+// @description Stop the rollout of the version of an EdgeStack, the environments it was released to keep it and the
+// @description other environments keep the previous version.
+// @description **Access policy**: administrator
+// @tags edge_stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "EdgeStack Id"
+// @success 200 {object} portainer.EdgeStack
+// @failure 400 "The edge stack has no rollout in progress"
+// @failure 404
+// @failure 500
+// @failure 503 "Edge compute features are disabled"
+// @router /edge_stacks/{id}/rollout/abort [post]
+func (handler *Handler) edgeStackRolloutAbort(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	return handler.updateRollout(w, r, func(tx dataservices.DataStoreTx, stack *portainer.EdgeStack) error {
+		return edgestackservice.AbortRollout(stack)
+	})
+}
+
+func (handler *Handler) updateRollout(w http.ResponseWriter, r *http.Request, updateFn func(tx dataservices.DataStoreTx, stack *portainer.EdgeStack) error) *httperror.HandlerError {
+	edgeStackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid edge stack identifier route variable", err)
+	}
+
+	var stack *portainer.EdgeStack
+	if err := handler.DataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		stack, err = tx.EdgeStack().EdgeStack(portainer.EdgeStackID(edgeStackID))
+		if err != nil {
+			return handlerDBErr(err, "Unable to find an edge stack with the specified identifier inside the database")
+		}
+
+		if err := updateFn(tx, stack); errors.Is(err, edgestackservice.ErrRolloutNotActive) || errors.Is(err, edgestackservice.ErrRolloutFullyReleased) {
+			return httperror.BadRequest("Unable to update the rollout of the edge stack", err)
+		} else if err != nil {
+			return httperror.InternalServerError("Unable to update the rollout of the edge stack", err)
+		}
+
+		if err := tx.EdgeStack().UpdateEdgeStack(stack.ID, stack); err != nil {
+			return httperror.InternalServerError("Unable to persist the stack changes inside the database", err)
+		}
+
+		return nil
+	}); err != nil {
+		return response.TxErrorResponse(err)
+	}
+
+	if err := fillEdgeStackStatus(handler.DataStore, stack); err != nil {
+		return handlerDBErr(err, "Unable to retrieve edge stack status from the database")
+	}
+
+	return response.JSON(w, stack)
+}
+
+// advanceRollout advances the rollout of the stack after a status update of one of its environments
+func (handler *Handler) advanceRollout(tx dataservices.DataStoreTx, stack *portainer.EdgeStack) error {
+	changed, err := edgestackservice.AdvanceRollout(tx, stack, time.Now())
+	if err != nil || !changed {
+		return err
+	}
+
+	edgestackservice.RemovePreviousVersion(handler.FileService, stack)
+
+	return tx.EdgeStack().UpdateEdgeStack(stack.ID, stack)
+}
@@ -10,6 +10,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/notifications"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
@@ -78,6 +79,7 @@ func (handler *Handler) edgeStackStatusUpdate(w http.ResponseWriter, r *http.Req
 	}
 
 	var stack *portainer.EdgeStack
+	var event *portainer.NotificationEvent
 
 	if err := handler.DataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
 		var err error
@@ -90,15 +92,24 @@ func (handler *Handler) edgeStackStatusUpdate(w http.ResponseWriter, r *http.Req
 			return httperror.InternalServerError("Unable to retrieve Edge stack from the database", err)
 		}
 
-		if err := handler.updateEdgeStackStatus(tx, stack, stack.ID, payload); err != nil {
+		if event, err = handler.updateEdgeStackStatus(tx, stack, stack.ID, payload); err != nil {
 			return httperror.InternalServerError("Unable to update Edge stack status", err)
 		}
 
+		if err := handler.advanceRollout(tx, stack); err != nil {
+			return httperror.InternalServerError("Unable to advance the rollout of the Edge stack", err)
+		}
+
 		return nil
 	}); err != nil {
 		return response.TxErrorResponse(err)
 	}
 
+	// Notified once the transaction is committed, a retried transaction must not notify twice
+	if event != nil {
+		notifications.Notify(*event)
+	}
+
 	if ok, _ := strconv.ParseBool(r.Header.Get("X-Portainer-No-Body")); ok {
 		return nil
 	}
@@ -110,9 +121,17 @@ func (handler *Handler) edgeStackStatusUpdate(w http.ResponseWriter, r *http.Req
 	return response.JSON(w, stack)
 }
 
-func (handler *Handler) updateEdgeStackStatus(tx dataservices.DataStoreTx, stack *portainer.EdgeStack, stackID portainer.EdgeStackID, payload updateStatusPayload) error {
-	if payload.Version > 0 && payload.Version < stack.Version {
-		return nil
+// updateEdgeStackStatus persists the status reported by the environment, it returns the event to notify when the
+// environment failed to deploy the stack
+func (handler *Handler) updateEdgeStackStatus(tx dataservices.DataStoreTx, stack *portainer.EdgeStack, stackID portainer.EdgeStackID, payload updateStatusPayload) (*portainer.NotificationEvent, error) {
+	// The environments a rollout is not released to yet report the status of the previous version
+	version := stack.Version
+	if v, ok := tx.EdgeStack().EdgeStackVersionForEndpoint(stackID, payload.EndpointID); ok {
+		version = v
+	}
+
+	if payload.Version > 0 && payload.Version < version {
+		return nil, nil
 	}
 
 	status := *payload.Status
@@ -124,12 +143,12 @@ func (handler *Handler) updateEdgeStackStatus(tx dataservices.DataStoreTx, stack
 	}
 
 	if deploymentStatus.Type == portainer.EdgeStackStatusRemoved {
-		return tx.EdgeStackStatus().Delete(stackID, payload.EndpointID)
+		return nil, tx.EdgeStackStatus().Delete(stackID, payload.EndpointID)
 	}
 
 	environmentStatus, err := tx.EdgeStackStatus().Read(stackID, payload.EndpointID)
 	if err != nil && !tx.IsErrObjectNotFound(err) {
-		return err
+		return nil, err
 	} else if tx.IsErrObjectNotFound(err) {
 		environmentStatus = &portainer.EdgeStackStatusForEnv{
 			EndpointID: payload.EndpointID,
@@ -137,11 +156,25 @@ func (handler *Handler) updateEdgeStackStatus(tx dataservices.DataStoreTx, stack
 		}
 	}
 
-	if containsStatus := slices.ContainsFunc(environmentStatus.Status, func(e portainer.EdgeStackDeploymentStatus) bool {
+	containsStatus := slices.ContainsFunc(environmentStatus.Status, func(e portainer.EdgeStackDeploymentStatus) bool {
 		return e.Type == deploymentStatus.Type
-	}); !containsStatus {
+	})
+	if !containsStatus {
 		environmentStatus.Status = append(environmentStatus.Status, deploymentStatus)
 	}
 
-	return tx.EdgeStackStatus().Update(stackID, payload.EndpointID, environmentStatus)
+	if err := tx.EdgeStackStatus().Update(stackID, payload.EndpointID, environmentStatus); err != nil {
+		return nil, err
+	}
+
+	if containsStatus || deploymentStatus.Type != portainer.EdgeStackStatusError {
+		return nil, nil
+	}
+
+	return &portainer.NotificationEvent{
+		Type:        portainer.NotificationEventEdgeStackError,
+		Message:     fmt.Sprintf("Edge stack %s failed to deploy on environment %d: %s", stack.Name, payload.EndpointID, payload.Error),
+		EndpointID:  payload.EndpointID,
+		EdgeStackID: stackID,
+	}, nil
 }
@@ -6,6 +6,7 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/internal/edge"
+	edgestackservice "github.com/portainer/portainer/api/internal/edge/edgestacks"
 	"github.com/portainer/portainer/api/set"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
@@ -21,6 +22,13 @@ type updateEdgeStackPayload struct {
 	DeploymentType   portainer.EdgeStackDeploymentType
 	// Uses the manifest's namespaces instead of the default one
 	UseManifestNamespaces bool
+
+	// A change of the environment variables updates the version of the stack
+	edgeStackEnvPayload
+	// Releases the new versions of the stack to its environments in batches, the current strategy is kept when omitted
+	RolloutStrategy *portainer.EdgeStackRolloutStrategy
+	// Removes the rollout strategy of the stack, every environment is then updated at once
+	ClearRolloutStrategy bool `example:"false"`
 }
 
 func (payload *updateEdgeStackPayload) Validate(r *http.Request) error {
@@ -32,7 +40,15 @@ func (payload *updateEdgeStackPayload) Validate(r *http.Request) error {
 		return errors.New("edge Groups are mandatory for an Edge stack")
 	}
 
-	return nil
+	if payload.ClearRolloutStrategy && payload.RolloutStrategy != nil {
+		return errors.New("a rollout strategy cannot be provided when clearing it")
+	}
+
+	if err := edgestackservice.ValidateRolloutStrategy(payload.RolloutStrategy); err != nil {
+		return err
+	}
+
+	return payload.edgeStackEnvPayload.validate()
 }
 
 // @id EdgeStackUpdate
@@ -118,12 +134,21 @@ func (handler *Handler) updateEdgeStack(tx dataservices.DataStoreTx, stackID por
 
 	stack.EdgeGroups = groupsIds
 
-	if payload.UpdateVersion {
+	if payload.ClearRolloutStrategy {
+		stack.RolloutStrategy = nil
+	} else if payload.RolloutStrategy != nil {
+		stack.RolloutStrategy = payload.RolloutStrategy
+	}
+
+	// The variables are applied once the new version is created so that a rollout keeps the previous ones
+	if payload.UpdateVersion || payload.changes(stack) {
 		if err := handler.updateStackVersion(tx, stack, payload.DeploymentType, []byte(payload.StackFileContent), "", relatedEndpointIds); err != nil {
 			return nil, httperror.InternalServerError("Unable to update stack version", err)
 		}
 	}
 
+	payload.applyTo(stack)
+
 	if err := tx.EdgeStack().UpdateEdgeStack(stack.ID, stack); err != nil {
 		return nil, httperror.InternalServerError("Unable to persist the stack changes inside the database", err)
 	}
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"testing"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/roar"
 
 	"github.com/segmentio/encoding/json"
@@ -233,3 +237,182 @@ func TestUpdateWithInvalidPayload(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateEnvUpdatesVersion(t *testing.T) {
+	handler, rawAPIKey := setupHandler(t)
+
+	endpoint := createEndpoint(t, handler.DataStore)
+	edgeStack := createEdgeStack(t, handler.DataStore, endpoint.ID)
+
+	update := func(payload updateEdgeStackPayload) portainer.EdgeStack {
+		jsonPayload, err := json.Marshal(payload)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/edge_stacks/%d", edgeStack.ID), bytes.NewBuffer(jsonPayload))
+		require.NoError(t, err)
+
+		req.Header.Add("x-api-key", rawAPIKey)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var updatedStack portainer.EdgeStack
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&updatedStack))
+
+		return updatedStack
+	}
+
+	payload := updateEdgeStackPayload{
+		StackFileContent: "env-test",
+		EdgeGroups:       edgeStack.EdgeGroups,
+		DeploymentType:   portainer.EdgeStackDeploymentCompose,
+		edgeStackEnvPayload: edgeStackEnvPayload{
+			Env: []portainer.Pair{{Name: "STORE_ID", Value: "{{ PORTAINER_ENVIRONMENT_ID }}"}},
+			EndpointEnv: map[portainer.EndpointID][]portainer.Pair{
+				endpoint.ID: {{Name: "STORE_ID", Value: "042"}},
+			},
+		},
+	}
+
+	updatedStack := update(payload)
+	require.Equal(t, edgeStack.Version+1, updatedStack.Version)
+	require.Equal(t, payload.Env, updatedStack.Env)
+	require.Equal(t, payload.EndpointEnv, updatedStack.EndpointEnv)
+
+	// The version is kept when the variables are unchanged
+	updatedStack = update(payload)
+	require.Equal(t, edgeStack.Version+1, updatedStack.Version)
+
+	// The variables are kept when they are omitted
+	updatedStack = update(updateEdgeStackPayload{
+		StackFileContent: "env-test",
+		EdgeGroups:       edgeStack.EdgeGroups,
+		DeploymentType:   portainer.EdgeStackDeploymentCompose,
+	})
+	require.Equal(t, edgeStack.Version+1, updatedStack.Version)
+	require.Equal(t, payload.Env, updatedStack.Env)
+	require.Equal(t, payload.EndpointEnv, updatedStack.EndpointEnv)
+
+	// An empty list removes the variables
+	updatedStack = update(updateEdgeStackPayload{
+		StackFileContent:    "env-test",
+		EdgeGroups:          edgeStack.EdgeGroups,
+		DeploymentType:      portainer.EdgeStackDeploymentCompose,
+		edgeStackEnvPayload: edgeStackEnvPayload{Env: []portainer.Pair{}},
+	})
+	require.Equal(t, edgeStack.Version+2, updatedStack.Version)
+	require.Empty(t, updatedStack.Env)
+	require.Equal(t, payload.EndpointEnv, updatedStack.EndpointEnv)
+}
+
+func TestUpdateWithRolloutStrategy(t *testing.T) {
+	handler, rawAPIKey := setupHandler(t)
+
+	endpoint := createEndpoint(t, handler.DataStore)
+	edgeStack := createEdgeStack(t, handler.DataStore, endpoint.ID)
+	otherEndpoint := createEndpointWithId(t, handler.DataStore, 6)
+
+	err := handler.DataStore.EdgeGroup().UpdateEdgeGroupFunc(edgeStack.EdgeGroups[0], func(edgeGroup *portainer.EdgeGroup) {
+		edgeGroup.EndpointIDs = roar.FromSlice([]portainer.EndpointID{endpoint.ID, otherEndpoint.ID})
+	})
+	require.NoError(t, err)
+
+	err = handler.DataStore.EdgeStack().UpdateEdgeStackFunc(edgeStack.ID, func(edgeStack *portainer.EdgeStack) {
+		edgeStack.ProjectPath = handler.FileService.GetEdgeStackProjectPath(strconv.Itoa(int(edgeStack.ID)))
+	})
+	require.NoError(t, err)
+
+	send := func(method, url string, payload any, expectedStatus int) portainer.EdgeStack {
+		jsonPayload, err := json.Marshal(payload)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(method, url, bytes.NewBuffer(jsonPayload))
+		require.NoError(t, err)
+
+		req.Header.Add("x-api-key", rawAPIKey)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, expectedStatus, rec.Code, rec.Body.String())
+
+		var updatedStack portainer.EdgeStack
+		if expectedStatus == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&updatedStack))
+		}
+
+		return updatedStack
+	}
+
+	stackURL := fmt.Sprintf("/edge_stacks/%d", edgeStack.ID)
+
+	payload := updateEdgeStackPayload{
+		StackFileContent: "version-1",
+		EdgeGroups:       edgeStack.EdgeGroups,
+		DeploymentType:   portainer.EdgeStackDeploymentCompose,
+		UpdateVersion:    true,
+		RolloutStrategy:  &portainer.EdgeStackRolloutStrategy{BatchSize: 1},
+		edgeStackEnvPayload: edgeStackEnvPayload{
+			Env: []portainer.Pair{{Name: "STORE_ID", Value: "1"}},
+		},
+	}
+
+	// The deployment type changed, the version is released at once
+	updatedStack := send(http.MethodPut, stackURL, payload, http.StatusOK)
+	require.Nil(t, updatedStack.Rollout)
+
+	payload.StackFileContent = "version-2"
+	payload.Env = []portainer.Pair{{Name: "STORE_ID", Value: "2"}}
+	updatedStack = send(http.MethodPut, stackURL, payload, http.StatusOK)
+	require.NotNil(t, updatedStack.Rollout)
+	require.Equal(t, edgeStack.Version+2, updatedStack.Rollout.Version)
+	require.Equal(t, edgeStack.Version+1, updatedStack.Rollout.PreviousVersion)
+	require.Equal(t, 1, updatedStack.Rollout.Released)
+
+	previousFile, err := os.ReadFile(filepath.Join(updatedStack.Rollout.PreviousProjectPath, filesystem.ComposeFileDefaultName))
+	require.NoError(t, err)
+	require.Equal(t, "version-1", string(previousFile))
+
+	// The environments held on the previous version keep its variables
+	require.Equal(t, payload.Env, updatedStack.Env)
+	require.NotNil(t, updatedStack.Rollout.PreviousEnv)
+	require.Equal(t, []portainer.Pair{{Name: "STORE_ID", Value: "1"}}, updatedStack.Rollout.PreviousEnv.Env)
+
+	version, ok := handler.DataStore.EdgeStack().EdgeStackVersionForEndpoint(edgeStack.ID, endpoint.ID)
+	require.True(t, ok)
+	require.Equal(t, updatedStack.Version, version)
+
+	version, _ = handler.DataStore.EdgeStack().EdgeStackVersionForEndpoint(edgeStack.ID, otherEndpoint.ID)
+	require.Equal(t, updatedStack.Rollout.PreviousVersion, version)
+
+	updatedStack = send(http.MethodPost, stackURL+"/rollout/promote", nil, http.StatusOK)
+	require.Equal(t, 2, updatedStack.Rollout.Released)
+
+	version, _ = handler.DataStore.EdgeStack().EdgeStackVersionForEndpoint(edgeStack.ID, otherEndpoint.ID)
+	require.Equal(t, updatedStack.Version, version)
+
+	send(http.MethodPost, stackURL+"/rollout/promote", nil, http.StatusBadRequest)
+
+	updatedStack = send(http.MethodPost, stackURL+"/rollout/abort", nil, http.StatusOK)
+	require.Equal(t, portainer.EdgeStackRolloutAborted, updatedStack.Rollout.Status)
+
+	send(http.MethodPost, stackURL+"/rollout/abort", nil, http.StatusBadRequest)
+
+	// The strategy is kept when it is omitted
+	payload.StackFileContent = "version-3"
+	payload.RolloutStrategy = nil
+	updatedStack = send(http.MethodPut, stackURL, payload, http.StatusOK)
+	require.Equal(t, &portainer.EdgeStackRolloutStrategy{BatchSize: 1}, updatedStack.RolloutStrategy)
+	require.NotNil(t, updatedStack.Rollout)
+	require.Equal(t, updatedStack.Version, updatedStack.Rollout.Version)
+	require.Equal(t, 1, updatedStack.Rollout.Released)
+
+	payload.ClearRolloutStrategy = true
+	payload.RolloutStrategy = &portainer.EdgeStackRolloutStrategy{BatchSize: 1}
+	send(http.MethodPut, stackURL, payload, http.StatusBadRequest)
+
+	// Clearing the strategy releases the next version at once
+	payload.StackFileContent = "version-4"
+	payload.RolloutStrategy = nil
+	updatedStack = send(http.MethodPut, stackURL, payload, http.StatusOK)
+	require.Nil(t, updatedStack.RolloutStrategy)
+	require.Nil(t, updatedStack.Rollout)
+}
@@ -3,17 +3,26 @@ package edgestacks
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/filesystem"
+	edgestackservice "github.com/portainer/portainer/api/internal/edge/edgestacks"
 
 	"github.com/rs/zerolog/log"
 )
 
 func (handler *Handler) updateStackVersion(tx dataservices.DataStoreTx, stack *portainer.EdgeStack, deploymentType portainer.EdgeStackDeploymentType, config []byte, oldGitHash string, relatedEnvironmentsIDs []portainer.EndpointID) error {
+	previousVersion := stack.Version
 	stack.Version++
 
+	if stack.RolloutStrategy != nil && deploymentType == stack.DeploymentType && len(relatedEnvironmentsIDs) > 0 {
+		return handler.rolloutStackVersion(tx, stack, previousVersion, config, relatedEnvironmentsIDs)
+	}
+
+	handler.removeRollout(stack)
+
 	if err := tx.EdgeStackStatus().Clear(stack.ID, relatedEnvironmentsIDs); err != nil {
 		return err
 	}
@@ -21,6 +30,59 @@ func (handler *Handler) updateStackVersion(tx dataservices.DataStoreTx, stack *p
 	return handler.storeStackFile(stack, deploymentType, config)
 }
 
+// rolloutStackVersion stores the new version of the stack and releases it to the first batch of the rollout, the
+// files of the previous version are kept aside for the other environments
+func (handler *Handler) rolloutStackVersion(tx dataservices.DataStoreTx, stack *portainer.EdgeStack, previousVersion int, config []byte, relatedEnvironmentsIDs []portainer.EndpointID) error {
+	var previousProjectPath string
+	var previousEnv *portainer.EdgeStackEnv
+
+	if rollout := stack.Rollout; rollout != nil && rollout.Status != portainer.EdgeStackRolloutCompleted && rollout.PreviousProjectPath != "" {
+		// The environments not released the version of the unfinished rollout still run its previous version, which
+		// stays the one advertised to the environments outside of the new batches
+		previousVersion = rollout.PreviousVersion
+		previousProjectPath = rollout.PreviousProjectPath
+		previousEnv = rollout.PreviousEnv
+	} else {
+		previousEnv = &portainer.EdgeStackEnv{
+			Env:          stack.Env,
+			EdgeGroupEnv: stack.EdgeGroupEnv,
+			EndpointEnv:  stack.EndpointEnv,
+		}
+
+		previousProjectPath = handler.FileService.GetEdgeStackProjectPath(fmt.Sprintf("%d_v%d", stack.ID, previousVersion))
+
+		if err := handler.FileService.RemoveDirectory(previousProjectPath); err != nil {
+			return fmt.Errorf("unable to clear the folder of the previous version: %w", err)
+		}
+
+		if err := filesystem.CopyDir(stack.ProjectPath, previousProjectPath, false); err != nil {
+			return fmt.Errorf("unable to keep the files of the previous version: %w", err)
+		}
+	}
+
+	if err := handler.storeStackFile(stack, stack.DeploymentType, config); err != nil {
+		return err
+	}
+
+	return edgestackservice.StartRollout(tx, stack, previousVersion, previousProjectPath, previousEnv, relatedEnvironmentsIDs, time.Now())
+}
+
+// removeRollout removes the rollout of the previous version of the stack, the new version is released to every
+// environment at once
+func (handler *Handler) removeRollout(stack *portainer.EdgeStack) {
+	if stack.Rollout == nil {
+		return
+	}
+
+	if stack.Rollout.PreviousProjectPath != "" {
+		if err := handler.FileService.RemoveDirectory(stack.Rollout.PreviousProjectPath); err != nil {
+			log.Warn().Err(err).Msg("Unable to clear the files of the previous version")
+		}
+	}
+
+	stack.Rollout = nil
+}
+
 func (handler *Handler) storeStackFile(stack *portainer.EdgeStack, deploymentType portainer.EdgeStackDeploymentType, config []byte) error {
 	if deploymentType != stack.DeploymentType {
 		// deployment type was changed - need to delete all old files
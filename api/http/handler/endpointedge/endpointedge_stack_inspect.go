@@ -7,9 +7,11 @@ import (
 	"strconv"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/edge"
 	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/http/middlewares"
+	"github.com/portainer/portainer/api/internal/edge/edgestacks"
 	"github.com/portainer/portainer/api/internal/endpointutils"
 	"github.com/portainer/portainer/api/kubernetes"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
@@ -88,7 +90,24 @@ func (handler *Handler) endpointEdgeStackInspect(w http.ResponseWriter, r *http.
 		}
 	}
 
-	dirEntries, err := filesystem.LoadDir(edgeStack.ProjectPath)
+	// The environments a rollout is not released to yet are sent the files and variables of the previous version
+	projectPath := edgeStack.ProjectPath
+	envStack := edgeStack
+	if rollout := edgeStack.Rollout; rollout != nil && rollout.PreviousProjectPath != "" {
+		if version, ok := handler.DataStore.EdgeStack().EdgeStackVersionForEndpoint(edgeStack.ID, endpoint.ID); ok && version != edgeStack.Version && version == rollout.PreviousVersion {
+			projectPath = rollout.PreviousProjectPath
+
+			if rollout.PreviousEnv != nil {
+				previousStack := *edgeStack
+				previousStack.Env = rollout.PreviousEnv.Env
+				previousStack.EdgeGroupEnv = rollout.PreviousEnv.EdgeGroupEnv
+				previousStack.EndpointEnv = rollout.PreviousEnv.EndpointEnv
+				envStack = &previousStack
+			}
+		}
+	}
+
+	dirEntries, err := filesystem.LoadDir(projectPath)
 	if err != nil {
 		return httperror.InternalServerError("Unable to load repository", fmt.Errorf("failed to load project directory: %w. Environment name: %s", err, endpoint.Name))
 	}
@@ -100,11 +119,20 @@ func (handler *Handler) endpointEdgeStackInspect(w http.ResponseWriter, r *http.
 
 	dirEntries = filesystem.FilterDirForEntryFile(dirEntries, fileName)
 
+	var envVars []portainer.Pair
+	if err := handler.DataStore.ViewTx(func(tx dataservices.DataStoreTx) error {
+		envVars, err = edgestacks.ResolveEnv(tx, envStack, endpoint)
+		return err
+	}); err != nil {
+		return httperror.InternalServerError("Unable to resolve the environment variables of the stack", fmt.Errorf("failed to resolve the stack environment variables: %w. Environment name: %s", err, endpoint.Name))
+	}
+
 	return response.JSON(w, edge.StackPayload{
 		DirEntries:       dirEntries,
 		EntryFileName:    fileName,
 		StackFileContent: fileContent,
 		Name:             edgeStack.Name,
 		Namespace:        namespace,
+		EnvVars:          envVars,
 	})
 }
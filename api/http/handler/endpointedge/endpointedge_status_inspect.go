@@ -18,6 +18,7 @@ import (
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/internal/edge"
 	"github.com/portainer/portainer/api/internal/edge/cache"
+	"github.com/portainer/portainer/api/metrics"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
@@ -101,6 +102,7 @@ func (handler *Handler) endpointEdgeStatusInspect(w http.ResponseWriter, r *http
 	}
 
 	handler.DataStore.Endpoint().UpdateHeartbeat(endpoint.ID)
+	metrics.IncEdgeCheckin(endpoint.ID)
 
 	if err := handler.requestBouncer.TrustedEdgeEnvironmentAccess(handler.DataStore, endpoint); err != nil {
 		return httperror.Forbidden("Permission denied to access environment. The device has not been trusted yet", fmt.Errorf("untrusted Edge environment access: %w. Environment name: %s", err, endpoint.Name))
@@ -274,7 +276,7 @@ func (handler *Handler) buildEdgeStacks(tx dataservices.DataStoreTx, endpointID
 
 	edgeStacksStatus := []stackStatusResponse{}
 	for stackID := range relation.EdgeStacks {
-		version, ok := tx.EdgeStack().EdgeStackVersion(stackID)
+		version, ok := tx.EdgeStack().EdgeStackVersionForEndpoint(stackID, endpointID)
 		if !ok {
 			return nil, httperror.InternalServerError("Unable to retrieve edge stack from the database", err)
 		}
@@ -336,6 +338,7 @@ func (handler *Handler) respondFromCache(w http.ResponseWriter, r *http.Request,
 		}
 
 		handler.DataStore.Endpoint().UpdateHeartbeat(endpointID)
+		metrics.IncEdgeCheckin(endpointID)
 
 		w.Header().Set("ETag", etag)
 		w.WriteHeader(http.StatusNotModified)
@@ -3,7 +3,6 @@ package endpoints
 import (
 	"errors"
 	"net/http"
-	"strconv"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
@@ -148,15 +147,8 @@ func (handler *Handler) deleteEndpoint(tx dataservices.DataStoreTx, endpointID p
 		return httperror.InternalServerError("Unable to read the environment record from the database", err)
 	}
 
-	if endpoint.TLSConfig.TLS {
-		folder := strconv.Itoa(int(endpointID))
-		if err := handler.FileService.DeleteTLSFiles(folder); err != nil {
-			log.Error().Err(err).Msgf("Unable to remove TLS files from disk when deleting endpoint %d", endpointID)
-		}
-	}
-
-	if err := tx.Snapshot().Delete(endpointID); err != nil {
-		log.Warn().Err(err).Msg("Unable to remove the snapshot from the database")
+	if err := endpointutils.DeleteEndpoint(tx, handler.FileService, endpoint); err != nil {
+		return httperror.InternalServerError("Unable to delete the environment from the database", err)
 	}
 
 	handler.ProxyManager.DeleteEndpointProxy(endpoint.ID)
@@ -167,91 +159,5 @@ func (handler *Handler) deleteEndpoint(tx dataservices.DataStoreTx, endpointID p
 		}
 	}
 
-	if err := tx.EndpointRelation().DeleteEndpointRelation(endpoint.ID); err != nil {
-		log.Warn().Err(err).Msg("Unable to remove environment relation from the database")
-	}
-
-	for _, tagID := range endpoint.TagIDs {
-		var tag *portainer.Tag
-		tag, err = tx.Tag().Read(tagID)
-		if err == nil {
-			delete(tag.Endpoints, endpoint.ID)
-			err = tx.Tag().Update(tagID, tag)
-		}
-
-		if handler.DataStore.IsErrObjectNotFound(err) {
-			log.Warn().Err(err).Msg("Unable to find tag inside the database")
-		} else if err != nil {
-			log.Warn().Err(err).Msg("Unable to delete tag relation from the database")
-		}
-	}
-
-	edgeGroups, err := tx.EdgeGroup().ReadAll()
-	if err != nil {
-		log.Warn().Err(err).Msgf("Unable to retrieve edge groups from the database")
-	}
-
-	for _, edgeGroup := range edgeGroups {
-		edgeGroup.EndpointIDs.Remove(endpoint.ID)
-
-		if err := tx.EdgeGroup().Update(edgeGroup.ID, &edgeGroup); err != nil {
-			log.Warn().Err(err).Msg("Unable to update edge group")
-		}
-	}
-
-	edgeStacks, err := tx.EdgeStack().EdgeStacks()
-	if err != nil {
-		log.Warn().Err(err).Msg("Unable to retrieve edge stacks from the database")
-	}
-
-	for _, edgeStack := range edgeStacks {
-		if err := tx.EdgeStackStatus().Delete(edgeStack.ID, endpoint.ID); err != nil {
-			log.Warn().Err(err).Msg("Unable to delete edge stack status")
-		}
-	}
-
-	registries, err := tx.Registry().ReadAll()
-	if err != nil {
-		log.Warn().Err(err).Msg("Unable to retrieve registries from the database")
-	}
-
-	for idx := range registries {
-		registry := &registries[idx]
-		if _, ok := registry.RegistryAccesses[endpoint.ID]; ok {
-			delete(registry.RegistryAccesses, endpoint.ID)
-
-			if err := tx.Registry().Update(registry.ID, registry); err != nil {
-				log.Warn().Err(err).Msg("Unable to update registry accesses")
-			}
-		}
-	}
-
-	if endpointutils.IsEdgeEndpoint(endpoint) {
-		edgeJobs, err := handler.DataStore.EdgeJob().ReadAll()
-		if err != nil {
-			log.Warn().Err(err).Msg("Unable to retrieve edge jobs from the database")
-		}
-
-		for idx := range edgeJobs {
-			edgeJob := &edgeJobs[idx]
-			if _, ok := edgeJob.Endpoints[endpoint.ID]; ok {
-				delete(edgeJob.Endpoints, endpoint.ID)
-
-				if err := tx.EdgeJob().Update(edgeJob.ID, edgeJob); err != nil {
-					log.Warn().Err(err).Msg("Unable to update edge job")
-				}
-			}
-		}
-	}
-
-	// delete the pending actions
-	if err := tx.PendingActions().DeleteByEndpointID(endpoint.ID); err != nil {
-		log.Warn().Err(err).Int("endpointId", int(endpoint.ID)).Msg("Unable to delete pending actions")
-	}
-
-	if err := tx.Endpoint().DeleteEndpoint(endpointID); err != nil {
-		return httperror.InternalServerError("Unable to delete the environment from the database", err)
-	}
-
 	return nil
 }
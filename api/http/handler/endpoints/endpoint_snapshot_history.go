@@ -0,0 +1,81 @@
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/snapshot"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// defaultSnapshotHistoryRange is the time range returned when the request doesn't specify one
+const defaultSnapshotHistoryRange = 24 * time.Hour
+
+// @id EndpointSnapshotHistory
+// @summary Retrieve the snapshot history of an environment(endpoint)
+// @description Retrieve the summaries of the snapshots of an environment(endpoint) recorded in a time range, sorted by time.
+// @description Samples older than the raw retention of the snapshot history settings are hourly averages.
+// @description **Access policy**: restricted
+// @tags endpoints
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment(Endpoint) identifier"
+// @param since query int false "Only return samples recorded at or after this unix timestamp, defaults to 24 hours ago"
+// @param until query int false "Only return samples recorded at or before this unix timestamp, defaults to now"
+// @success 200 {array} portainer.SnapshotHistorySample "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied to access environment"
+// @failure 404 "Environment(Endpoint) not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/snapshots/history [get]
+func (handler *Handler) endpointSnapshotHistory(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid environment identifier route variable", err)
+	}
+
+	now := time.Now().Unix()
+
+	until, err := request.RetrieveNumericQueryParameter(r, "until", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: until", err)
+	} else if until == 0 {
+		until = int(now)
+	}
+
+	since, err := request.RetrieveNumericQueryParameter(r, "since", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: since", err)
+	} else if since == 0 {
+		since = until - int(defaultSnapshotHistoryRange.Seconds())
+	}
+
+	if since > until {
+		return httperror.BadRequest("Invalid time range", errors.New("since must be before until"))
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find an environment with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find an environment with the specified identifier inside the database", err)
+	}
+
+	if err := handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint); err != nil {
+		return httperror.Forbidden("Permission denied to access environment", err)
+	}
+
+	history, err := handler.DataStore.SnapshotHistory().Read(endpoint.ID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return response.JSON(w, []portainer.SnapshotHistorySample{})
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the snapshot history from the database", err)
+	}
+
+	return response.JSON(w, snapshot.HistorySamples(history, int64(since), int64(until)))
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/portainer/portainer/api/git"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
 	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/http/security"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
-	"github.com/portainer/portainer/pkg/validate"
 )
 
 type fileResponse struct {
@@ -22,6 +24,8 @@ type repositoryFilePreviewPayload struct {
 	Username          string                         `json:"username" example:"myGitUsername"`
 	Password          string                         `json:"password" example:"myGitPassword"`
 	AuthorizationType gittypes.GitCredentialAuthType `json:"authorizationType"`
+	// Git credential used in place of the username and password
+	GitCredentialID int `json:"gitCredentialID" example:"0"`
 	// Path to file whose content will be read
 	TargetFile string `json:"targetFile" example:"docker-compose.yml"`
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
@@ -29,7 +33,7 @@ type repositoryFilePreviewPayload struct {
 }
 
 func (payload *repositoryFilePreviewPayload) Validate(r *http.Request) error {
-	if len(payload.Repository) == 0 || !validate.IsURL(payload.Repository) {
+	if len(payload.Repository) == 0 || !git.IsRepositoryURL(payload.Repository) {
 		return errors.New("invalid repository URL. Must correspond to a valid URL format")
 	}
 
@@ -64,6 +68,23 @@ func (handler *Handler) gitOperationRepoFilePreview(w http.ResponseWriter, r *ht
 		return httperror.BadRequest("Invalid request payload", err)
 	}
 
+	username, password, authType := payload.Username, payload.Password, payload.AuthorizationType
+	if payload.GitCredentialID != 0 {
+		tokenData, err := security.RetrieveTokenData(r)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+		}
+
+		if err := gitcredentials.ValidateAccess(handler.dataStore, payload.GitCredentialID, tokenData.ID); err != nil {
+			return httperror.Forbidden("Permission denied to use the Git credential", err)
+		}
+
+		username, password, authType, err = git.GetCredentials(handler.gitService, &gittypes.GitAuthentication{GitCredentialID: payload.GitCredentialID})
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the Git credential", err)
+		}
+	}
+
 	projectPath, err := handler.fileService.GetTemporaryPath()
 	if err != nil {
 		return httperror.InternalServerError("Unable to create temporary folder", err)
@@ -73,9 +94,9 @@ func (handler *Handler) gitOperationRepoFilePreview(w http.ResponseWriter, r *ht
 		projectPath,
 		payload.Repository,
 		payload.Reference,
-		payload.Username,
-		payload.Password,
-		payload.AuthorizationType,
+		username,
+		password,
+		authType,
 		payload.TLSSkipVerify,
 	)
 	if err != nil {
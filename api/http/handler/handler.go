@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/portainer/portainer/api/http/handler/auditlogs"
 	"github.com/portainer/portainer/api/http/handler/auth"
 	"github.com/portainer/portainer/api/http/handler/backup"
 	"github.com/portainer/portainer/api/http/handler/customtemplates"
@@ -21,7 +22,9 @@ import (
 	"github.com/portainer/portainer/api/http/handler/hostmanagement/openamt"
 	"github.com/portainer/portainer/api/http/handler/kubernetes"
 	"github.com/portainer/portainer/api/http/handler/ldap"
+	"github.com/portainer/portainer/api/http/handler/metrics"
 	"github.com/portainer/portainer/api/http/handler/motd"
+	"github.com/portainer/portainer/api/http/handler/notifications"
 	"github.com/portainer/portainer/api/http/handler/registries"
 	"github.com/portainer/portainer/api/http/handler/resourcecontrols"
 	"github.com/portainer/portainer/api/http/handler/roles"
@@ -38,10 +41,13 @@ import (
 	"github.com/portainer/portainer/api/http/handler/users"
 	"github.com/portainer/portainer/api/http/handler/webhooks"
 	"github.com/portainer/portainer/api/http/handler/websocket"
+
+	"github.com/gorilla/mux"
 )
 
 // Handler is a collection of all the service handlers.
 type Handler struct {
+	AuditLogHandler        *auditlogs.Handler
 	AuthHandler            *auth.Handler
 	BackupHandler          *backup.Handler
 	CustomTemplatesHandler *customtemplates.Handler
@@ -59,7 +65,9 @@ type Handler struct {
 	KubernetesHandler      *kubernetes.Handler
 	FileHandler            *file.Handler
 	LDAPHandler            *ldap.Handler
+	MetricsHandler         *metrics.Handler
 	MOTDHandler            *motd.Handler
+	NotificationHandler    *notifications.Handler
 	RegistryHandler        *registries.Handler
 	ResourceControlHandler *resourcecontrols.Handler
 	RoleHandler            *roles.Handler
@@ -102,6 +110,8 @@ type Handler struct {
 // @in header
 // @name Authorization
 
+// @tag.name audit_logs
+// @tag.description Inspect the audit trail
 // @tag.name auth
 // @tag.description Authenticate against Portainer HTTP API
 // @tag.name backup
@@ -134,8 +144,12 @@ type Handler struct {
 // @tag.description Manage Kubernetes cluster
 // @tag.name ldap
 // @tag.description Manage LDAP settings
+// @tag.name metrics
+// @tag.description Expose the Prometheus metrics
 // @tag.name motd
 // @tag.description Fetch the message of the day
+// @tag.name notifications
+// @tag.description Manage the notification channels and inspect their deliveries
 // @tag.name registries
 // @tag.description Manage Docker registries
 // @tag.name resource_controls
@@ -169,11 +183,64 @@ type Handler struct {
 // @tag.name websocket
 // @tag.description Create exec sessions using websockets
 
+// Use adds middlewares to the routers of the API subhandlers, they are only run for the requests matching a route.
+func (h *Handler) Use(middlewares ...mux.MiddlewareFunc) {
+	routers := []*mux.Router{
+		h.AuditLogHandler.Router,
+		h.AuthHandler.Router,
+		h.BackupHandler.Router,
+		h.CustomTemplatesHandler.Router,
+		h.DockerHandler.Router,
+		h.EdgeGroupsHandler.Router,
+		h.EdgeJobsHandler.Router,
+		h.EdgeStacksHandler.Router,
+		h.EndpointEdgeHandler.Router,
+		h.EndpointGroupHandler.Router,
+		h.EndpointHandler.Router,
+		h.EndpointHelmHandler.Router,
+		h.EndpointProxyHandler.Router,
+		h.GitOperationHandler.Router,
+		h.HelmTemplatesHandler.Router,
+		h.KubernetesHandler.Router,
+		h.LDAPHandler.Router,
+		h.MetricsHandler.Router,
+		h.MOTDHandler.Router,
+		h.NotificationHandler.Router,
+		h.RegistryHandler.Router,
+		h.ResourceControlHandler.Router,
+		h.RoleHandler.Router,
+		h.SettingsHandler.Router,
+		h.SSLHandler.Router,
+		h.OpenAMTHandler.Router,
+		h.StackHandler.Router,
+		h.SystemHandler.Router,
+		h.TagHandler.Router,
+		h.TeamMembershipHandler.Router,
+		h.TeamHandler.Router,
+		h.TemplatesHandler.Router,
+		h.UploadHandler.Router,
+		h.UserHandler.Router,
+		h.WebSocketHandler.Router,
+		h.WebhookHandler.Router,
+	}
+
+	// The user Helm handler is optional
+	if h.UserHelmHandler != nil {
+		routers = append(routers, h.UserHelmHandler.Router)
+	}
+
+	for _, router := range routers {
+		router.Use(middlewares...)
+	}
+}
+
 // ServeHTTP delegates a request to the appropriate subhandler.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch {
 	case strings.HasPrefix(r.URL.Path, "/api/endpoints") && strings.Contains(r.URL.Path, "/edge/"):
 		h.EndpointEdgeHandler.ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/audit_logs"):
+		http.StripPrefix("/api", h.AuditLogHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/auth"):
 		http.StripPrefix("/api", h.AuthHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/backup"):
@@ -216,8 +283,12 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.StripPrefix("/api", h.GitOperationHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/ldap"):
 		http.StripPrefix("/api", h.LDAPHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/metrics"):
+		http.StripPrefix("/api", h.MetricsHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/motd"):
 		http.StripPrefix("/api", h.MOTDHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/notifications"):
+		http.StripPrefix("/api", h.NotificationHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/registries"):
 		http.StripPrefix("/api", h.RegistryHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/resource_controls"):
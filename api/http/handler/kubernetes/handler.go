@@ -59,6 +59,8 @@ func NewHandler(bouncer security.BouncerService, authorizationService *authoriza
 	endpointRouter.Handle("/cron_jobs", httperror.LoggerHandler(h.getAllKubernetesCronJobs)).Methods(http.MethodGet)
 	endpointRouter.Handle("/cron_jobs/delete", httperror.LoggerHandler(h.deleteKubernetesCronJobs)).Methods(http.MethodPost)
 	endpointRouter.Handle("/events", httperror.LoggerHandler(h.getAllKubernetesEvents)).Methods(http.MethodGet)
+	endpointRouter.Handle("/horizontal_pod_autoscalers", httperror.LoggerHandler(h.getAllKubernetesHorizontalPodAutoscalers)).Methods(http.MethodGet)
+	endpointRouter.Handle("/horizontal_pod_autoscalers/delete", httperror.LoggerHandler(h.deleteKubernetesHorizontalPodAutoscalers)).Methods(http.MethodPost)
 	endpointRouter.Handle("/jobs", httperror.LoggerHandler(h.getAllKubernetesJobs)).Methods(http.MethodGet)
 	endpointRouter.Handle("/jobs/delete", httperror.LoggerHandler(h.deleteKubernetesJobs)).Methods(http.MethodPost)
 	endpointRouter.Handle("/cluster_roles", httperror.LoggerHandler(h.getAllKubernetesClusterRoles)).Methods(http.MethodGet)
@@ -75,6 +77,10 @@ func NewHandler(bouncer security.BouncerService, authorizationService *authoriza
 	endpointRouter.Handle("/metrics/nodes/{name}", httperror.LoggerHandler(h.getKubernetesMetricsForNode)).Methods(http.MethodGet)
 	endpointRouter.Handle("/metrics/pods/namespace/{namespace}", httperror.LoggerHandler(h.getKubernetesMetricsForAllPods)).Methods(http.MethodGet)
 	endpointRouter.Handle("/metrics/pods/namespace/{namespace}/{name}", httperror.LoggerHandler(h.getKubernetesMetricsForPod)).Methods(http.MethodGet)
+	endpointRouter.Handle("/network_policies", httperror.LoggerHandler(h.getAllKubernetesNetworkPolicies)).Methods(http.MethodGet)
+	endpointRouter.Handle("/network_policies/delete", httperror.LoggerHandler(h.deleteKubernetesNetworkPolicies)).Methods(http.MethodPost)
+	endpointRouter.Handle("/pod_disruption_budgets", httperror.LoggerHandler(h.getAllKubernetesPodDisruptionBudgets)).Methods(http.MethodGet)
+	endpointRouter.Handle("/pod_disruption_budgets/delete", httperror.LoggerHandler(h.deleteKubernetesPodDisruptionBudgets)).Methods(http.MethodPost)
 	endpointRouter.Handle("/ingresscontrollers", httperror.LoggerHandler(h.getAllKubernetesIngressControllers)).Methods(http.MethodGet)
 	endpointRouter.Handle("/ingresscontrollers", httperror.LoggerHandler(h.updateKubernetesIngressControllers)).Methods(http.MethodPut)
 	endpointRouter.Handle("/ingresses/delete", httperror.LoggerHandler(h.deleteKubernetesIngresses)).Methods(http.MethodPost)
@@ -114,12 +120,23 @@ func NewHandler(bouncer security.BouncerService, authorizationService *authoriza
 	namespaceRouter.Handle("/configmaps/{configmap}", httperror.LoggerHandler(h.getKubernetesConfigMap)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/events", httperror.LoggerHandler(h.getKubernetesEventsForNamespace)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/system", bouncer.RestrictedAccess(httperror.LoggerHandler(h.namespacesToggleSystem))).Methods(http.MethodPut)
+	namespaceRouter.Handle("/horizontal_pod_autoscalers", httperror.LoggerHandler(h.getKubernetesHorizontalPodAutoscalersByNamespace)).Methods(http.MethodGet)
+	namespaceRouter.Handle("/horizontal_pod_autoscalers", httperror.LoggerHandler(h.createKubernetesHorizontalPodAutoscaler)).Methods(http.MethodPost)
+	namespaceRouter.Handle("/horizontal_pod_autoscalers", httperror.LoggerHandler(h.updateKubernetesHorizontalPodAutoscaler)).Methods(http.MethodPut)
+	namespaceRouter.Handle("/horizontal_pod_autoscalers/{name}", httperror.LoggerHandler(h.getKubernetesHorizontalPodAutoscaler)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/ingresscontrollers", httperror.LoggerHandler(h.getKubernetesIngressControllersByNamespace)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/ingresscontrollers", httperror.LoggerHandler(h.updateKubernetesIngressControllersByNamespace)).Methods(http.MethodPut)
 	namespaceRouter.Handle("/ingresses/{ingress}", httperror.LoggerHandler(h.getKubernetesIngress)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/ingresses", httperror.LoggerHandler(h.createKubernetesIngress)).Methods(http.MethodPost)
 	namespaceRouter.Handle("/ingresses", httperror.LoggerHandler(h.updateKubernetesIngress)).Methods(http.MethodPut)
 	namespaceRouter.Handle("/ingresses", httperror.LoggerHandler(h.getKubernetesIngresses)).Methods(http.MethodGet)
+	namespaceRouter.Handle("/network_policies", httperror.LoggerHandler(h.getKubernetesNetworkPoliciesByNamespace)).Methods(http.MethodGet)
+	namespaceRouter.Handle("/network_policies", httperror.LoggerHandler(h.createKubernetesNetworkPolicy)).Methods(http.MethodPost)
+	namespaceRouter.Handle("/network_policies/{name}", httperror.LoggerHandler(h.getKubernetesNetworkPolicy)).Methods(http.MethodGet)
+	namespaceRouter.Handle("/pod_disruption_budgets", httperror.LoggerHandler(h.getKubernetesPodDisruptionBudgetsByNamespace)).Methods(http.MethodGet)
+	namespaceRouter.Handle("/pod_disruption_budgets", httperror.LoggerHandler(h.createKubernetesPodDisruptionBudget)).Methods(http.MethodPost)
+	namespaceRouter.Handle("/pod_disruption_budgets", httperror.LoggerHandler(h.updateKubernetesPodDisruptionBudget)).Methods(http.MethodPut)
+	namespaceRouter.Handle("/pod_disruption_budgets/{name}", httperror.LoggerHandler(h.getKubernetesPodDisruptionBudget)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/secrets/{secret}", httperror.LoggerHandler(h.getKubernetesSecret)).Methods(http.MethodGet)
 	namespaceRouter.Handle("/services", httperror.LoggerHandler(h.createKubernetesService)).Methods(http.MethodPost)
 	namespaceRouter.Handle("/services", httperror.LoggerHandler(h.updateKubernetesService)).Methods(http.MethodPut)
@@ -0,0 +1,292 @@
+package kubernetes
+
+import (
+	"net/http"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+	"github.com/rs/zerolog/log"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// @id GetKubernetesHorizontalPodAutoscalers
+// @summary Get a list of horizontal pod autoscalers
+// @description Get a list of horizontal pod autoscalers across all namespaces that the user has access to.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @produce json
+// @param id path int true "Environment identifier"
+// @success 200 {array} models.K8sHorizontalPodAutoscaler "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 500 "Server error occurred while attempting to retrieve the list of horizontal pod autoscalers."
+// @router /kubernetes/{id}/horizontal_pod_autoscalers [get]
+func (handler *Handler) getAllKubernetesHorizontalPodAutoscalers(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	cli, httpErr := handler.prepareKubeClient(r)
+	if httpErr != nil {
+		log.Error().Err(httpErr).Str("context", "GetAllKubernetesHorizontalPodAutoscalers").Msg("Unable to prepare kube client")
+		return httperror.InternalServerError("unable to prepare kube client. Error: ", httpErr)
+	}
+
+	hpas, err := cli.GetHorizontalPodAutoscalers("")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetAllKubernetesHorizontalPodAutoscalers").Msg("Unable to fetch horizontal pod autoscalers across all namespaces")
+		return httperror.InternalServerError("unable to fetch horizontal pod autoscalers. Error: ", err)
+	}
+
+	return response.JSON(w, hpas)
+}
+
+// @id GetKubernetesHorizontalPodAutoscalersByNamespace
+// @summary Get a list of horizontal pod autoscalers for a given namespace
+// @description Get a list of horizontal pod autoscalers for a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @success 200 {array} models.K8sHorizontalPodAutoscaler "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 500 "Server error occurred while attempting to retrieve the horizontal pod autoscalers of a namespace."
+// @router /kubernetes/{id}/namespaces/{namespace}/horizontal_pod_autoscalers [get]
+func (handler *Handler) getKubernetesHorizontalPodAutoscalersByNamespace(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetKubernetesHorizontalPodAutoscalersByNamespace").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	hpas, err := cli.GetHorizontalPodAutoscalers(namespace)
+	if err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "GetKubernetesHorizontalPodAutoscalersByNamespace").Str("namespace", namespace).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "GetKubernetesHorizontalPodAutoscalersByNamespace").Str("namespace", namespace).Msg("Unable to retrieve horizontal pod autoscalers")
+		return httperror.InternalServerError("unable to retrieve horizontal pod autoscalers. Error: ", err)
+	}
+
+	return response.JSON(w, hpas)
+}
+
+// @id GetKubernetesHorizontalPodAutoscaler
+// @summary Get a horizontal pod autoscaler
+// @description Get a horizontal pod autoscaler by name for a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @param name path string true "Horizontal pod autoscaler name"
+// @success 200 {object} models.K8sHorizontalPodAutoscaler "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier or unable to find a horizontal pod autoscaler with the specified name."
+// @failure 500 "Server error occurred while attempting to retrieve a horizontal pod autoscaler."
+// @router /kubernetes/{id}/namespaces/{namespace}/horizontal_pod_autoscalers/{name} [get]
+func (handler *Handler) getKubernetesHorizontalPodAutoscaler(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetKubernetesHorizontalPodAutoscaler").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Msg("Unable to retrieve horizontal pod autoscaler name route variable")
+		return httperror.BadRequest("unable to retrieve horizontal pod autoscaler name route variable. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	hpa, err := cli.GetHorizontalPodAutoscaler(namespace, name)
+	if err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "GetKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", name).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		if k8serrors.IsNotFound(err) {
+			log.Error().Err(err).Str("context", "GetKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", name).Msg("Unable to find the horizontal pod autoscaler")
+			return httperror.NotFound("unable to find the horizontal pod autoscaler. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "GetKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", name).Msg("Unable to retrieve the horizontal pod autoscaler")
+		return httperror.InternalServerError("unable to retrieve the horizontal pod autoscaler. Error: ", err)
+	}
+
+	return response.JSON(w, hpa)
+}
+
+// @id CreateKubernetesHorizontalPodAutoscaler
+// @summary Create a horizontal pod autoscaler
+// @description Create a horizontal pod autoscaler for an application of a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @accept json
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @param body body models.K8sHorizontalPodAutoscaler true "Horizontal pod autoscaler definition"
+// @success 204 "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier or unable to find the application to autoscale."
+// @failure 409 "Conflict - a horizontal pod autoscaler with the same name already exists in the specified namespace."
+// @failure 500 "Server error occurred while attempting to create a horizontal pod autoscaler."
+// @router /kubernetes/{id}/namespaces/{namespace}/horizontal_pod_autoscalers [post]
+func (handler *Handler) createKubernetesHorizontalPodAutoscaler(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "CreateKubernetesHorizontalPodAutoscaler").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	var payload models.K8sHorizontalPodAutoscaler
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		log.Error().Err(err).Str("context", "CreateKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Msg("Unable to decode and validate the request payload")
+		return httperror.BadRequest("unable to decode and validate the request payload. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	if err := cli.CreateHorizontalPodAutoscaler(namespace, payload); err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "CreateKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", payload.Name).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		if k8serrors.IsNotFound(err) {
+			log.Error().Err(err).Str("context", "CreateKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", payload.Name).Msg("Unable to find the application to autoscale")
+			return httperror.NotFound("unable to find the application to autoscale. Error: ", err)
+		}
+
+		if k8serrors.IsAlreadyExists(err) {
+			log.Error().Err(err).Str("context", "CreateKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", payload.Name).Msg("A horizontal pod autoscaler with the same name already exists in the namespace")
+			return httperror.Conflict("a horizontal pod autoscaler with the same name already exists in the namespace. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "CreateKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", payload.Name).Msg("Unable to create the horizontal pod autoscaler")
+		return httperror.InternalServerError("unable to create the horizontal pod autoscaler. Error: ", err)
+	}
+
+	return response.Empty(w)
+}
+
+// @id UpdateKubernetesHorizontalPodAutoscaler
+// @summary Update a horizontal pod autoscaler
+// @description Update the application, the replicas and the metrics of a horizontal pod autoscaler of a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @accept json
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @param body body models.K8sHorizontalPodAutoscaler true "Horizontal pod autoscaler definition"
+// @success 204 "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier or unable to find the horizontal pod autoscaler or the application."
+// @failure 500 "Server error occurred while attempting to update a horizontal pod autoscaler."
+// @router /kubernetes/{id}/namespaces/{namespace}/horizontal_pod_autoscalers [put]
+func (handler *Handler) updateKubernetesHorizontalPodAutoscaler(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "UpdateKubernetesHorizontalPodAutoscaler").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	var payload models.K8sHorizontalPodAutoscaler
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		log.Error().Err(err).Str("context", "UpdateKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Msg("Unable to decode and validate the request payload")
+		return httperror.BadRequest("unable to decode and validate the request payload. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	if err := cli.UpdateHorizontalPodAutoscaler(namespace, payload); err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "UpdateKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", payload.Name).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		if k8serrors.IsNotFound(err) {
+			log.Error().Err(err).Str("context", "UpdateKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", payload.Name).Msg("Unable to find the horizontal pod autoscaler or the application to update")
+			return httperror.NotFound("unable to find the horizontal pod autoscaler or the application. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "UpdateKubernetesHorizontalPodAutoscaler").Str("namespace", namespace).Str("name", payload.Name).Msg("Unable to update the horizontal pod autoscaler")
+		return httperror.InternalServerError("unable to update the horizontal pod autoscaler. Error: ", err)
+	}
+
+	return response.Empty(w)
+}
+
+// @id DeleteKubernetesHorizontalPodAutoscalers
+// @summary Delete horizontal pod autoscalers
+// @description Delete the provided list of horizontal pod autoscalers.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @accept json
+// @param id path int true "Environment identifier"
+// @param payload body models.K8sHorizontalPodAutoscalerDeleteRequests true "A map where the key is the namespace and the value is an array of horizontal pod autoscalers to delete"
+// @success 204 "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 500 "Server error occurred while attempting to delete horizontal pod autoscalers."
+// @router /kubernetes/{id}/horizontal_pod_autoscalers/delete [post]
+func (handler *Handler) deleteKubernetesHorizontalPodAutoscalers(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload models.K8sHorizontalPodAutoscalerDeleteRequests
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	cli, handlerErr := handler.getProxyKubeClient(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	if err := cli.DeleteHorizontalPodAutoscalers(payload); err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "DeleteKubernetesHorizontalPodAutoscalers").Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		return httperror.InternalServerError("Unable to delete horizontal pod autoscalers", err)
+	}
+
+	return response.Empty(w)
+}
@@ -0,0 +1,233 @@
+package kubernetes
+
+import (
+	"net/http"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+	"github.com/rs/zerolog/log"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// @id GetKubernetesNetworkPolicies
+// @summary Get a list of network policies
+// @description Get a list of network policies across all namespaces that the user has access to.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @produce json
+// @param id path int true "Environment identifier"
+// @success 200 {array} models.K8sNetworkPolicy "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 500 "Server error occurred while attempting to retrieve the list of network policies."
+// @router /kubernetes/{id}/network_policies [get]
+func (handler *Handler) getAllKubernetesNetworkPolicies(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	cli, httpErr := handler.prepareKubeClient(r)
+	if httpErr != nil {
+		log.Error().Err(httpErr).Str("context", "GetAllKubernetesNetworkPolicies").Msg("Unable to prepare kube client")
+		return httperror.InternalServerError("unable to prepare kube client. Error: ", httpErr)
+	}
+
+	policies, err := cli.GetNetworkPolicies("")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetAllKubernetesNetworkPolicies").Msg("Unable to fetch network policies across all namespaces")
+		return httperror.InternalServerError("unable to fetch network policies. Error: ", err)
+	}
+
+	return response.JSON(w, policies)
+}
+
+// @id GetKubernetesNetworkPoliciesByNamespace
+// @summary Get a list of network policies for a given namespace
+// @description Get a list of network policies for a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @success 200 {array} models.K8sNetworkPolicy "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 500 "Server error occurred while attempting to retrieve the network policies of a namespace."
+// @router /kubernetes/{id}/namespaces/{namespace}/network_policies [get]
+func (handler *Handler) getKubernetesNetworkPoliciesByNamespace(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetKubernetesNetworkPoliciesByNamespace").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	policies, err := cli.GetNetworkPolicies(namespace)
+	if err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "GetKubernetesNetworkPoliciesByNamespace").Str("namespace", namespace).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "GetKubernetesNetworkPoliciesByNamespace").Str("namespace", namespace).Msg("Unable to retrieve network policies")
+		return httperror.InternalServerError("unable to retrieve network policies. Error: ", err)
+	}
+
+	return response.JSON(w, policies)
+}
+
+// @id GetKubernetesNetworkPolicy
+// @summary Get a network policy
+// @description Get a network policy by name for a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @param name path string true "Network policy name"
+// @success 200 {object} models.K8sNetworkPolicy "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier or unable to find a network policy with the specified name."
+// @failure 500 "Server error occurred while attempting to retrieve a network policy."
+// @router /kubernetes/{id}/namespaces/{namespace}/network_policies/{name} [get]
+func (handler *Handler) getKubernetesNetworkPolicy(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetKubernetesNetworkPolicy").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetKubernetesNetworkPolicy").Str("namespace", namespace).Msg("Unable to retrieve network policy name route variable")
+		return httperror.BadRequest("unable to retrieve network policy name route variable. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	policy, err := cli.GetNetworkPolicy(namespace, name)
+	if err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "GetKubernetesNetworkPolicy").Str("namespace", namespace).Str("name", name).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		if k8serrors.IsNotFound(err) {
+			log.Error().Err(err).Str("context", "GetKubernetesNetworkPolicy").Str("namespace", namespace).Str("name", name).Msg("Unable to find the network policy")
+			return httperror.NotFound("unable to find the network policy. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "GetKubernetesNetworkPolicy").Str("namespace", namespace).Str("name", name).Msg("Unable to retrieve the network policy")
+		return httperror.InternalServerError("unable to retrieve the network policy. Error: ", err)
+	}
+
+	return response.JSON(w, policy)
+}
+
+// @id CreateKubernetesNetworkPolicy
+// @summary Create a network policy
+// @description Create a network policy in a given namespace.
+// @description **Access policy**: Administrator.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @accept json
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @param body body models.K8sNetworkPolicy true "Network policy definition"
+// @success 204 "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 409 "Conflict - a network policy with the same name already exists in the specified namespace."
+// @failure 500 "Server error occurred while attempting to create a network policy."
+// @router /kubernetes/{id}/namespaces/{namespace}/network_policies [post]
+func (handler *Handler) createKubernetesNetworkPolicy(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "CreateKubernetesNetworkPolicy").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	var payload models.K8sNetworkPolicy
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		log.Error().Err(err).Str("context", "CreateKubernetesNetworkPolicy").Str("namespace", namespace).Msg("Unable to decode and validate the request payload")
+		return httperror.BadRequest("unable to decode and validate the request payload. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	if err := cli.CreateNetworkPolicy(namespace, payload); err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "CreateKubernetesNetworkPolicy").Str("namespace", namespace).Str("name", payload.Name).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		if k8serrors.IsAlreadyExists(err) {
+			log.Error().Err(err).Str("context", "CreateKubernetesNetworkPolicy").Str("namespace", namespace).Str("name", payload.Name).Msg("A network policy with the same name already exists in the namespace")
+			return httperror.Conflict("a network policy with the same name already exists in the namespace. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "CreateKubernetesNetworkPolicy").Str("namespace", namespace).Str("name", payload.Name).Msg("Unable to create the network policy")
+		return httperror.InternalServerError("unable to create the network policy. Error: ", err)
+	}
+
+	return response.Empty(w)
+}
+
+// @id DeleteKubernetesNetworkPolicies
+// @summary Delete network policies
+// @description Delete the provided list of network policies.
+// @description **Access policy**: Administrator.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @accept json
+// @param id path int true "Environment identifier"
+// @param payload body models.K8sNetworkPolicyDeleteRequests true "A map where the key is the namespace and the value is an array of network policies to delete"
+// @success 204 "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 500 "Server error occurred while attempting to delete network policies."
+// @router /kubernetes/{id}/network_policies/delete [post]
+func (handler *Handler) deleteKubernetesNetworkPolicies(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload models.K8sNetworkPolicyDeleteRequests
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	cli, handlerErr := handler.getProxyKubeClient(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	if err := cli.DeleteNetworkPolicies(payload); err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "DeleteKubernetesNetworkPolicies").Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		return httperror.InternalServerError("Unable to delete network policies", err)
+	}
+
+	return response.Empty(w)
+}
@@ -0,0 +1,292 @@
+package kubernetes
+
+import (
+	"net/http"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+	"github.com/rs/zerolog/log"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// @id GetKubernetesPodDisruptionBudgets
+// @summary Get a list of pod disruption budgets
+// @description Get a list of pod disruption budgets across all namespaces that the user has access to.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @produce json
+// @param id path int true "Environment identifier"
+// @success 200 {array} models.K8sPodDisruptionBudget "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 500 "Server error occurred while attempting to retrieve the list of pod disruption budgets."
+// @router /kubernetes/{id}/pod_disruption_budgets [get]
+func (handler *Handler) getAllKubernetesPodDisruptionBudgets(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	cli, httpErr := handler.prepareKubeClient(r)
+	if httpErr != nil {
+		log.Error().Err(httpErr).Str("context", "GetAllKubernetesPodDisruptionBudgets").Msg("Unable to prepare kube client")
+		return httperror.InternalServerError("unable to prepare kube client. Error: ", httpErr)
+	}
+
+	pdbs, err := cli.GetPodDisruptionBudgets("")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetAllKubernetesPodDisruptionBudgets").Msg("Unable to fetch pod disruption budgets across all namespaces")
+		return httperror.InternalServerError("unable to fetch pod disruption budgets. Error: ", err)
+	}
+
+	return response.JSON(w, pdbs)
+}
+
+// @id GetKubernetesPodDisruptionBudgetsByNamespace
+// @summary Get a list of pod disruption budgets for a given namespace
+// @description Get a list of pod disruption budgets for a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @success 200 {array} models.K8sPodDisruptionBudget "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 500 "Server error occurred while attempting to retrieve the pod disruption budgets of a namespace."
+// @router /kubernetes/{id}/namespaces/{namespace}/pod_disruption_budgets [get]
+func (handler *Handler) getKubernetesPodDisruptionBudgetsByNamespace(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetKubernetesPodDisruptionBudgetsByNamespace").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	pdbs, err := cli.GetPodDisruptionBudgets(namespace)
+	if err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "GetKubernetesPodDisruptionBudgetsByNamespace").Str("namespace", namespace).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "GetKubernetesPodDisruptionBudgetsByNamespace").Str("namespace", namespace).Msg("Unable to retrieve pod disruption budgets")
+		return httperror.InternalServerError("unable to retrieve pod disruption budgets. Error: ", err)
+	}
+
+	return response.JSON(w, pdbs)
+}
+
+// @id GetKubernetesPodDisruptionBudget
+// @summary Get a pod disruption budget
+// @description Get a pod disruption budget by name for a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @param name path string true "Pod disruption budget name"
+// @success 200 {object} models.K8sPodDisruptionBudget "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier or unable to find a pod disruption budget with the specified name."
+// @failure 500 "Server error occurred while attempting to retrieve a pod disruption budget."
+// @router /kubernetes/{id}/namespaces/{namespace}/pod_disruption_budgets/{name} [get]
+func (handler *Handler) getKubernetesPodDisruptionBudget(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetKubernetesPodDisruptionBudget").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		log.Error().Err(err).Str("context", "GetKubernetesPodDisruptionBudget").Str("namespace", namespace).Msg("Unable to retrieve pod disruption budget name route variable")
+		return httperror.BadRequest("unable to retrieve pod disruption budget name route variable. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	pdb, err := cli.GetPodDisruptionBudget(namespace, name)
+	if err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "GetKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", name).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		if k8serrors.IsNotFound(err) {
+			log.Error().Err(err).Str("context", "GetKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", name).Msg("Unable to find the pod disruption budget")
+			return httperror.NotFound("unable to find the pod disruption budget. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "GetKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", name).Msg("Unable to retrieve the pod disruption budget")
+		return httperror.InternalServerError("unable to retrieve the pod disruption budget. Error: ", err)
+	}
+
+	return response.JSON(w, pdb)
+}
+
+// @id CreateKubernetesPodDisruptionBudget
+// @summary Create a pod disruption budget
+// @description Create a pod disruption budget protecting the pods of an application of a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @accept json
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @param body body models.K8sPodDisruptionBudget true "Pod disruption budget definition"
+// @success 204 "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier or unable to find the application to protect."
+// @failure 409 "Conflict - a pod disruption budget with the same name already exists in the specified namespace."
+// @failure 500 "Server error occurred while attempting to create a pod disruption budget."
+// @router /kubernetes/{id}/namespaces/{namespace}/pod_disruption_budgets [post]
+func (handler *Handler) createKubernetesPodDisruptionBudget(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "CreateKubernetesPodDisruptionBudget").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	var payload models.K8sPodDisruptionBudget
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		log.Error().Err(err).Str("context", "CreateKubernetesPodDisruptionBudget").Str("namespace", namespace).Msg("Unable to decode and validate the request payload")
+		return httperror.BadRequest("unable to decode and validate the request payload. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	if err := cli.CreatePodDisruptionBudget(namespace, payload); err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "CreateKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", payload.Name).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		if k8serrors.IsNotFound(err) {
+			log.Error().Err(err).Str("context", "CreateKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", payload.Name).Msg("Unable to find the application to protect")
+			return httperror.NotFound("unable to find the application to protect. Error: ", err)
+		}
+
+		if k8serrors.IsAlreadyExists(err) {
+			log.Error().Err(err).Str("context", "CreateKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", payload.Name).Msg("A pod disruption budget with the same name already exists in the namespace")
+			return httperror.Conflict("a pod disruption budget with the same name already exists in the namespace. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "CreateKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", payload.Name).Msg("Unable to create the pod disruption budget")
+		return httperror.InternalServerError("unable to create the pod disruption budget. Error: ", err)
+	}
+
+	return response.Empty(w)
+}
+
+// @id UpdateKubernetesPodDisruptionBudget
+// @summary Update a pod disruption budget
+// @description Update the application and the availability requirements of a pod disruption budget of a given namespace.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @accept json
+// @produce json
+// @param id path int true "Environment identifier"
+// @param namespace path string true "Namespace name"
+// @param body body models.K8sPodDisruptionBudget true "Pod disruption budget definition"
+// @success 204 "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier or unable to find the pod disruption budget or the application."
+// @failure 500 "Server error occurred while attempting to update a pod disruption budget."
+// @router /kubernetes/{id}/namespaces/{namespace}/pod_disruption_budgets [put]
+func (handler *Handler) updateKubernetesPodDisruptionBudget(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	namespace, err := request.RetrieveRouteVariableValue(r, "namespace")
+	if err != nil {
+		log.Error().Err(err).Str("context", "UpdateKubernetesPodDisruptionBudget").Msg("Unable to retrieve namespace identifier route variable")
+		return httperror.BadRequest("unable to retrieve namespace identifier route variable. Error: ", err)
+	}
+
+	var payload models.K8sPodDisruptionBudget
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		log.Error().Err(err).Str("context", "UpdateKubernetesPodDisruptionBudget").Str("namespace", namespace).Msg("Unable to decode and validate the request payload")
+		return httperror.BadRequest("unable to decode and validate the request payload. Error: ", err)
+	}
+
+	cli, httpError := handler.getProxyKubeClient(r)
+	if httpError != nil {
+		return httpError
+	}
+
+	if err := cli.UpdatePodDisruptionBudget(namespace, payload); err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "UpdateKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", payload.Name).Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		if k8serrors.IsNotFound(err) {
+			log.Error().Err(err).Str("context", "UpdateKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", payload.Name).Msg("Unable to find the pod disruption budget or the application to update")
+			return httperror.NotFound("unable to find the pod disruption budget or the application. Error: ", err)
+		}
+
+		log.Error().Err(err).Str("context", "UpdateKubernetesPodDisruptionBudget").Str("namespace", namespace).Str("name", payload.Name).Msg("Unable to update the pod disruption budget")
+		return httperror.InternalServerError("unable to update the pod disruption budget. Error: ", err)
+	}
+
+	return response.Empty(w)
+}
+
+// @id DeleteKubernetesPodDisruptionBudgets
+// @summary Delete pod disruption budgets
+// @description Delete the provided list of pod disruption budgets.
+// @description **Access policy**: Authenticated user.
+// @tags kubernetes
+// @security ApiKeyAuth || jwt
+// @accept json
+// @param id path int true "Environment identifier"
+// @param payload body models.K8sPodDisruptionBudgetDeleteRequests true "A map where the key is the namespace and the value is an array of pod disruption budgets to delete"
+// @success 204 "Success"
+// @failure 400 "Invalid request payload, such as missing required fields or fields not meeting validation criteria."
+// @failure 401 "Unauthorized access - the user is not authenticated or does not have the necessary permissions. Ensure that you have provided a valid API key or JWT token, and that you have the required permissions."
+// @failure 403 "Permission denied - the user is authenticated but does not have the necessary permissions to access the requested resource or perform the specified operation. Check your user roles and permissions."
+// @failure 404 "Unable to find an environment with the specified identifier."
+// @failure 500 "Server error occurred while attempting to delete pod disruption budgets."
+// @router /kubernetes/{id}/pod_disruption_budgets/delete [post]
+func (handler *Handler) deleteKubernetesPodDisruptionBudgets(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload models.K8sPodDisruptionBudgetDeleteRequests
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	cli, handlerErr := handler.getProxyKubeClient(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	if err := cli.DeletePodDisruptionBudgets(payload); err != nil {
+		if k8serrors.IsUnauthorized(err) || k8serrors.IsForbidden(err) {
+			log.Error().Err(err).Str("context", "DeleteKubernetesPodDisruptionBudgets").Msg("Unauthorized access to the Kubernetes API")
+			return httperror.Forbidden("unauthorized access to the Kubernetes API. Error: ", err)
+		}
+
+		return httperror.InternalServerError("Unable to delete pod disruption budgets", err)
+	}
+
+	return response.Empty(w)
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/metrics"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+
+	"github.com/gorilla/mux"
+)
+
+// Handler is the HTTP handler used to expose the Prometheus metrics.
+type Handler struct {
+	*mux.Router
+	token string
+}
+
+// NewHandler creates a handler to expose the Prometheus metrics.
+// When token is not empty, requests bearing it are allowed to scrape the metrics without an administrator session.
+func NewHandler(bouncer security.BouncerService, token string) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+		token:  token,
+	}
+
+	metricsHandler := httperror.LoggerHandler(h.metricsInspect)
+
+	h.Handle("/metrics",
+		h.scrapeTokenAccess(metricsHandler, bouncer.AdminAccess(metricsHandler))).Methods(http.MethodGet)
+
+	return h
+}
+
+// scrapeTokenAccess serves the request with tokenHandler when it bears the scrape token, and with next otherwise
+func (handler *Handler) scrapeTokenAccess(tokenHandler, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handler.token == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(token), []byte(handler.token)) == 1 {
+			tokenHandler.ServeHTTP(w, r)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// @id MetricsInspect
+// @summary Retrieve the Prometheus metrics
+// @description Retrieve the metrics of the Portainer instance in the Prometheus/OpenMetrics text format.
+// @description **Access policy**: administrator, or any client bearing the token set with --metrics-token
+// @tags metrics
+// @security ApiKeyAuth
+// @security jwt
+// @produce text/plain
+// @success 200 "Success"
+// @failure 403 "Permission denied"
+// @router /metrics [get]
+func (handler *Handler) metricsInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	metrics.Handler().ServeHTTP(w, r)
+
+	return nil
+}
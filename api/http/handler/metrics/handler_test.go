@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+
+	"github.com/stretchr/testify/require"
+)
+
+type denyingBouncer struct {
+	security.BouncerService
+}
+
+func (denyingBouncer) AdminAccess(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+}
+
+func TestMetricsInspect(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name          string
+		token         string
+		authorization string
+		bouncer       security.BouncerService
+		expected      int
+	}{
+		{name: "administrator", bouncer: testhelpers.NewTestRequestBouncer(), expected: http.StatusOK},
+		{name: "denied without token", bouncer: denyingBouncer{}, expected: http.StatusForbidden},
+		{name: "denied when no token is configured", authorization: "Bearer ", bouncer: denyingBouncer{}, expected: http.StatusForbidden},
+		{name: "scrape token", token: "secret", authorization: "Bearer secret", bouncer: denyingBouncer{}, expected: http.StatusOK},
+		{name: "invalid scrape token", token: "secret", authorization: "Bearer other", bouncer: denyingBouncer{}, expected: http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := NewHandler(tc.bouncer, tc.token)
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.expected, rr.Code)
+
+			if tc.expected == http.StatusOK {
+				require.Contains(t, rr.Body.String(), "go_goroutines")
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package notifications
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+var supportedEvents = []portainer.NotificationEventType{
+	portainer.NotificationEventEndpointDown,
+	portainer.NotificationEventEndpointUp,
+	portainer.NotificationEventStackAutoUpdateFailed,
+	portainer.NotificationEventEdgeStackError,
+	portainer.NotificationEventBackupCompleted,
+	portainer.NotificationEventBackupFailed,
+	portainer.NotificationEventImageUpdateFailed,
+}
+
+type channelPayload struct {
+	// Notification channel name
+	Name string `validate:"required" example:"ops-team"`
+	// Type of the channel, one of webhook, slack or smtp
+	Type portainer.NotificationChannelType `validate:"required" example:"webhook"`
+	// Whether events are delivered to this channel
+	Enabled bool `example:"true"`
+	// Events this channel is subscribed to
+	Events []portainer.NotificationEventType `example:"endpoint.down"`
+	// Configuration of a webhook channel, required when Type is webhook
+	Webhook *portainer.WebhookNotificationConfig
+	// Configuration of a Slack channel, required when Type is slack
+	Slack *portainer.SlackNotificationConfig
+	// Configuration of an SMTP channel, required when Type is smtp
+	SMTP *portainer.SMTPNotificationConfig
+}
+
+func (payload *channelPayload) Validate(r *http.Request) error {
+	if payload.Name == "" {
+		return errors.New("invalid channel name")
+	}
+
+	for _, event := range payload.Events {
+		if !slices.Contains(supportedEvents, event) {
+			return errors.New("invalid event type: " + string(event))
+		}
+	}
+
+	switch payload.Type {
+	case portainer.NotificationChannelTypeWebhook:
+		if payload.Webhook == nil || !isValidURL(payload.Webhook.URL) {
+			return errors.New("invalid webhook URL")
+		}
+	case portainer.NotificationChannelTypeSlack:
+		if payload.Slack == nil || !isValidURL(payload.Slack.URL) {
+			return errors.New("invalid Slack URL")
+		}
+	case portainer.NotificationChannelTypeSMTP:
+		if payload.SMTP == nil || payload.SMTP.Host == "" || payload.SMTP.Port <= 0 || payload.SMTP.Port > 65535 {
+			return errors.New("invalid SMTP server")
+		}
+
+		if payload.SMTP.From == "" || len(payload.SMTP.To) == 0 {
+			return errors.New("invalid SMTP sender or recipients")
+		}
+	default:
+		return errors.New("invalid channel type, must be one of webhook, slack or smtp")
+	}
+
+	return nil
+}
+
+func isValidURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// apply copies the payload to the channel, only keeping the configuration matching the channel type
+func (payload *channelPayload) apply(channel *portainer.NotificationChannel) {
+	channel.Name = payload.Name
+	channel.Type = payload.Type
+	channel.Enabled = payload.Enabled
+	channel.Events = payload.Events
+	channel.Webhook = nil
+	channel.Slack = nil
+	channel.SMTP = nil
+
+	switch payload.Type {
+	case portainer.NotificationChannelTypeWebhook:
+		channel.Webhook = payload.Webhook
+	case portainer.NotificationChannelTypeSlack:
+		channel.Slack = payload.Slack
+	case portainer.NotificationChannelTypeSMTP:
+		channel.SMTP = payload.SMTP
+	}
+}
+
+// @id NotificationChannelCreate
+// @summary Create a notification channel
+// @description Create a notification channel and subscribe it to a list of events.
+// @description Webhook channels post the event as JSON, signed with HMAC-SHA256 in the X-Portainer-Signature header when a secret is set.
+// @description The webhook secret, the Slack URL and the SMTP password are encrypted at rest.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body channelPayload true "Notification channel details"
+// @success 200 {object} portainer.NotificationChannel "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /notifications/channels [post]
+func (handler *Handler) channelCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload channelPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	channel := &portainer.NotificationChannel{}
+	payload.apply(channel)
+
+	if err := handler.NotificationService.CreateChannel(channel); err != nil {
+		return httperror.InternalServerError("Unable to persist the notification channel inside the database", err)
+	}
+
+	hideSecrets(channel)
+
+	return response.JSON(w, channel)
+}
@@ -0,0 +1,173 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	"github.com/portainer/portainer/api/notifications"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelPayloadValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name    string
+		payload channelPayload
+		valid   bool
+	}{
+		{
+			name:    "webhook",
+			payload: channelPayload{Name: "ops", Type: portainer.NotificationChannelTypeWebhook, Webhook: &portainer.WebhookNotificationConfig{URL: "https://example.com/hook"}},
+			valid:   true,
+		},
+		{
+			name:    "webhook without URL",
+			payload: channelPayload{Name: "ops", Type: portainer.NotificationChannelTypeWebhook, Webhook: &portainer.WebhookNotificationConfig{URL: "example.com"}},
+		},
+		{
+			name:    "slack without configuration",
+			payload: channelPayload{Name: "ops", Type: portainer.NotificationChannelTypeSlack},
+		},
+		{
+			name:    "smtp",
+			payload: channelPayload{Name: "ops", Type: portainer.NotificationChannelTypeSMTP, SMTP: &portainer.SMTPNotificationConfig{Host: "smtp.example.com", Port: 587, From: "a@example.com", To: []string{"b@example.com"}}},
+			valid:   true,
+		},
+		{
+			name:    "smtp without recipients",
+			payload: channelPayload{Name: "ops", Type: portainer.NotificationChannelTypeSMTP, SMTP: &portainer.SMTPNotificationConfig{Host: "smtp.example.com", Port: 587, From: "a@example.com"}},
+		},
+		{
+			name:    "unknown event",
+			payload: channelPayload{Name: "ops", Type: portainer.NotificationChannelTypeSlack, Slack: &portainer.SlackNotificationConfig{URL: "https://hooks.slack.com/x"}, Events: []portainer.NotificationEventType{"stack.created"}},
+		},
+		{
+			name:    "unknown type",
+			payload: channelPayload{Name: "ops", Type: "pager"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.payload.Validate(nil)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestChannelCreateHidesSecrets(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	service, err := notifications.NewService(t.Context(), store, []byte("secret key"))
+	require.NoError(t, err)
+
+	h := NewHandler(testhelpers.NewTestRequestBouncer())
+	h.DataStore = store
+	h.NotificationService = service
+
+	payload, err := json.Marshal(channelPayload{
+		Name:    "ops",
+		Type:    portainer.NotificationChannelTypeWebhook,
+		Enabled: true,
+		Events:  []portainer.NotificationEventType{portainer.NotificationEventEndpointDown},
+		Webhook: &portainer.WebhookNotificationConfig{URL: "https://example.com/hook", Secret: "s3cr3t"},
+	})
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/notifications/channels", bytes.NewReader(payload)))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var channel portainer.NotificationChannel
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&channel))
+	require.Empty(t, channel.Webhook.Secret)
+
+	// The secret is encrypted at rest
+	stored, err := store.NotificationChannel().Read(channel.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, stored.Webhook.Secret)
+	require.NotEqual(t, "s3cr3t", stored.Webhook.Secret)
+
+	stored, err = service.ReadChannel(channel.ID)
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", stored.Webhook.Secret)
+
+	// An empty secret keeps the current one on update
+	payload, err = json.Marshal(channelPayload{
+		Name:    "ops",
+		Type:    portainer.NotificationChannelTypeWebhook,
+		Webhook: &portainer.WebhookNotificationConfig{URL: "https://example.com/other"},
+	})
+	require.NoError(t, err)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/notifications/channels/1", bytes.NewReader(payload)))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	stored, err = service.ReadChannel(channel.ID)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/other", stored.Webhook.URL)
+	require.Equal(t, "s3cr3t", stored.Webhook.Secret)
+}
+
+func TestChannelHidesSlackURL(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	service, err := notifications.NewService(t.Context(), store, []byte("secret key"))
+	require.NoError(t, err)
+
+	h := NewHandler(testhelpers.NewTestRequestBouncer())
+	h.DataStore = store
+	h.NotificationService = service
+
+	payload, err := json.Marshal(channelPayload{
+		Name:    "ops",
+		Type:    portainer.NotificationChannelTypeSlack,
+		Enabled: true,
+		Events:  []portainer.NotificationEventType{portainer.NotificationEventEndpointDown},
+		Slack:   &portainer.SlackNotificationConfig{URL: "https://hooks.slack.com/services/T000/B000/XXXX"},
+	})
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/notifications/channels", bytes.NewReader(payload)))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var channel portainer.NotificationChannel
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&channel))
+	require.Empty(t, channel.Slack.URL)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/notifications/channels/1", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NotContains(t, rr.Body.String(), "hooks.slack.com")
+
+	// An empty URL keeps the current one on update
+	payload, err = json.Marshal(channelPayload{
+		Name:  "alerts",
+		Type:  portainer.NotificationChannelTypeSlack,
+		Slack: &portainer.SlackNotificationConfig{},
+	})
+	require.NoError(t, err)
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/notifications/channels/1", bytes.NewReader(payload)))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	stored, err := service.ReadChannel(channel.ID)
+	require.NoError(t, err)
+	require.Equal(t, "alerts", stored.Name)
+	require.Equal(t, "https://hooks.slack.com/services/T000/B000/XXXX", stored.Slack.URL)
+}
@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id NotificationChannelInspect
+// @summary Inspect a notification channel
+// @description Retrieve details about a notification channel, secrets and passwords are not returned.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Notification channel identifier"
+// @success 200 {object} portainer.NotificationChannel "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Notification channel not found"
+// @failure 500 "Server error"
+// @router /notifications/channels/{id} [get]
+func (handler *Handler) channelInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	channel, httpErr := handler.readChannel(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	hideSecrets(channel)
+
+	return response.JSON(w, channel)
+}
+
+func (handler *Handler) readChannel(r *http.Request) (*portainer.NotificationChannel, *httperror.HandlerError) {
+	id, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid notification channel identifier route variable", err)
+	}
+
+	channel, err := handler.NotificationService.ReadChannel(portainer.NotificationChannelID(id))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find a notification channel with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a notification channel with the specified identifier inside the database", err)
+	}
+
+	return channel, nil
+}
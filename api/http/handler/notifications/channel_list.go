@@ -0,0 +1,32 @@
+package notifications
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id NotificationChannelList
+// @summary List notification channels
+// @description List the notification channels, secrets and passwords are not returned.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.NotificationChannel "Success"
+// @failure 500 "Server error"
+// @router /notifications/channels [get]
+func (handler *Handler) channelList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	channels, err := handler.NotificationService.ReadChannels()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the notification channels from the database", err)
+	}
+
+	for i := range channels {
+		hideSecrets(&channels[i])
+	}
+
+	return response.JSON(w, channels)
+}
@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id NotificationChannelTest
+// @summary Send a test notification
+// @description Send a test event to a notification channel, without retrying, to check its configuration.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Notification channel identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Notification channel not found"
+// @failure 502 "Unable to deliver the test notification"
+// @router /notifications/channels/{id}/test [post]
+func (handler *Handler) channelTest(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	channel, httpErr := handler.readChannel(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	event := portainer.NotificationEvent{
+		Type:      portainer.NotificationEventTest,
+		Timestamp: time.Now().Unix(),
+		Message:   "This is a test notification sent from Portainer",
+	}
+
+	if err := handler.NotificationService.Send(r.Context(), channel, event); err != nil {
+		return httperror.NewError(http.StatusBadGateway, "Unable to deliver the test notification", err)
+	}
+
+	return response.Empty(w)
+}
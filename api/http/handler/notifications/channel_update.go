@@ -0,0 +1,70 @@
+package notifications
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id NotificationChannelUpdate
+// @summary Update a notification channel
+// @description Update a notification channel. An empty webhook secret, Slack URL or SMTP password keeps the current one.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Notification channel identifier"
+// @param body body channelPayload true "Notification channel details"
+// @success 200 {object} portainer.NotificationChannel "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Notification channel not found"
+// @failure 500 "Server error"
+// @router /notifications/channels/{id} [put]
+func (handler *Handler) channelUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	channel, httpErr := handler.readChannel(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	payload := channelUpdatePayload{current: channel}
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	payload.apply(channel)
+
+	if err := handler.NotificationService.UpdateChannel(channel); err != nil {
+		return httperror.InternalServerError("Unable to persist the notification channel changes inside the database", err)
+	}
+
+	hideSecrets(channel)
+
+	return response.JSON(w, channel)
+}
+
+// channelUpdatePayload is a channelPayload where the omitted secrets are taken from the current channel
+type channelUpdatePayload struct {
+	channelPayload
+	current *portainer.NotificationChannel
+}
+
+func (payload *channelUpdatePayload) Validate(r *http.Request) error {
+	if payload.Webhook != nil && payload.Webhook.Secret == "" && payload.current.Webhook != nil {
+		payload.Webhook.Secret = payload.current.Webhook.Secret
+	}
+
+	if payload.Slack != nil && payload.Slack.URL == "" && payload.current.Slack != nil {
+		payload.Slack.URL = payload.current.Slack.URL
+	}
+
+	if payload.SMTP != nil && payload.SMTP.Password == "" && payload.current.SMTP != nil {
+		payload.SMTP.Password = payload.current.SMTP.Password
+	}
+
+	return payload.channelPayload.Validate(r)
+}
@@ -0,0 +1,69 @@
+package notifications
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	portainer "github.com/portainer/portainer/api"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id NotificationDeliveryList
+// @summary List notification deliveries
+// @description List the history of the notifications delivered to the channels, most recent first.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param channelId query int false "Only return the deliveries to this channel"
+// @param status query string false "Only return the deliveries with this status" Enums(success, failure)
+// @param start query int false "Start searching from"
+// @param limit query int false "Limit results to this value"
+// @success 200 {array} portainer.NotificationDelivery "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /notifications/deliveries [get]
+func (handler *Handler) deliveryList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	channelID, err := request.RetrieveNumericQueryParameter(r, "channelId", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: channelId", err)
+	}
+
+	status, _ := request.RetrieveQueryParameter(r, "status", true)
+
+	start, _ := request.RetrieveNumericQueryParameter(r, "start", true)
+	if start != 0 {
+		start--
+	}
+
+	limit, _ := request.RetrieveNumericQueryParameter(r, "limit", true)
+	if limit < 0 {
+		return httperror.BadRequest("Invalid query parameter: limit", errors.New("limit must not be negative"))
+	}
+
+	deliveries, err := handler.DataStore.NotificationDelivery().ReadAll(func(delivery portainer.NotificationDelivery) bool {
+		return (channelID == 0 || delivery.ChannelID == portainer.NotificationChannelID(channelID)) &&
+			(status == "" || delivery.Status == portainer.NotificationDeliveryStatus(status))
+	})
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the notification deliveries from the database", err)
+	}
+
+	slices.SortStableFunc(deliveries, func(a, b portainer.NotificationDelivery) int {
+		return int(b.ID) - int(a.ID)
+	})
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(deliveries)))
+
+	if limit != 0 {
+		start = min(max(start, 0), len(deliveries))
+		deliveries = deliveries[start : start+min(limit, len(deliveries)-start)]
+	}
+
+	return response.JSON(w, deliveries)
+}
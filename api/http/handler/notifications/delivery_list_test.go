@@ -0,0 +1,57 @@
+package notifications
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryListWindow(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	for range 3 {
+		require.NoError(t, store.NotificationDelivery().Create(&portainer.NotificationDelivery{ChannelID: 1, Status: portainer.NotificationDeliverySuccess}))
+	}
+
+	h := NewHandler(testhelpers.NewTestRequestBouncer())
+	h.DataStore = store
+
+	for _, tc := range []struct {
+		query  string
+		status int
+		ids    []portainer.NotificationDeliveryID
+	}{
+		{query: "", status: http.StatusOK, ids: []portainer.NotificationDeliveryID{3, 2, 1}},
+		{query: "?start=2&limit=1", status: http.StatusOK, ids: []portainer.NotificationDeliveryID{2}},
+		{query: "?start=-5&limit=2", status: http.StatusOK, ids: []portainer.NotificationDeliveryID{3, 2}},
+		{query: "?start=10&limit=2", status: http.StatusOK, ids: []portainer.NotificationDeliveryID{}},
+		{query: "?limit=-1", status: http.StatusBadRequest},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/notifications/deliveries"+tc.query, nil))
+			require.Equal(t, tc.status, rr.Code)
+
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var deliveries []portainer.NotificationDelivery
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&deliveries))
+
+			ids := []portainer.NotificationDeliveryID{}
+			for _, delivery := range deliveries {
+				ids = append(ids, delivery.ID)
+			}
+			require.Equal(t, tc.ids, ids)
+			require.Equal(t, "3", rr.Header().Get("X-Total-Count"))
+		})
+	}
+}
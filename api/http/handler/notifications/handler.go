@@ -0,0 +1,61 @@
+package notifications
+
+import (
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/notifications"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+
+	"github.com/gorilla/mux"
+)
+
+// Handler is the HTTP handler used to handle notification operations.
+type Handler struct {
+	*mux.Router
+	DataStore           dataservices.DataStore
+	NotificationService *notifications.Service
+}
+
+// NewHandler creates a handler to manage notification operations.
+func NewHandler(bouncer security.BouncerService) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+
+	adminRouter := h.NewRoute().Subrouter()
+	adminRouter.Use(bouncer.AdminAccess)
+
+	adminRouter.Handle("/notifications/channels", httperror.LoggerHandler(h.channelList)).Methods(http.MethodGet)
+	adminRouter.Handle("/notifications/channels", httperror.LoggerHandler(h.channelCreate)).Methods(http.MethodPost)
+	adminRouter.Handle("/notifications/channels/{id}", httperror.LoggerHandler(h.channelInspect)).Methods(http.MethodGet)
+	adminRouter.Handle("/notifications/channels/{id}", httperror.LoggerHandler(h.channelUpdate)).Methods(http.MethodPut)
+	adminRouter.Handle("/notifications/channels/{id}", httperror.LoggerHandler(h.channelDelete)).Methods(http.MethodDelete)
+	adminRouter.Handle("/notifications/channels/{id}/test", httperror.LoggerHandler(h.channelTest)).Methods(http.MethodPost)
+	adminRouter.Handle("/notifications/deliveries", httperror.LoggerHandler(h.deliveryList)).Methods(http.MethodGet)
+
+	return h
+}
+
+// hideSecrets removes the webhook secret, the Slack URL and the SMTP password from a channel returned by the API
+func hideSecrets(channel *portainer.NotificationChannel) {
+	if channel.Webhook != nil {
+		webhook := *channel.Webhook
+		webhook.Secret = ""
+		channel.Webhook = &webhook
+	}
+
+	if channel.Slack != nil {
+		slack := *channel.Slack
+		slack.URL = ""
+		channel.Slack = &slack
+	}
+
+	if channel.SMTP != nil {
+		smtp := *channel.SMTP
+		smtp.Password = ""
+		channel.SMTP = &smtp
+	}
+}
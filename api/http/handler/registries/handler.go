@@ -62,11 +62,17 @@ func (handler *Handler) initRouter(bouncer accessGuard) {
 	adminRouter.Handle("/registries/{id}", httperror.LoggerHandler(handler.registryUpdate)).Methods(http.MethodPut)
 	adminRouter.Handle("/registries/{id}/configure", httperror.LoggerHandler(handler.registryConfigure)).Methods(http.MethodPost)
 	adminRouter.Handle("/registries/{id}", httperror.LoggerHandler(handler.registryDelete)).Methods(http.MethodDelete)
+	// Tags are written with the credentials of the registry, pulling access is not enough
+	adminRouter.Handle("/registries/{id}/repositories/{repository:.+}/tags", httperror.LoggerHandler(handler.registryTagCreate)).Methods(http.MethodPost)
+	adminRouter.Handle("/registries/{id}/repositories/{repository:.+}/tags/{tag}", httperror.LoggerHandler(handler.registryTagDelete)).Methods(http.MethodDelete)
 
 	// Use registry-specific access bouncer for inspect and repositories endpoints
 	registryAccessRouter := handler.NewRoute().Subrouter()
 	registryAccessRouter.Use(bouncer.AuthenticatedAccess, handler.RegistryAccess)
 	registryAccessRouter.Handle("/registries/{id}", httperror.LoggerHandler(handler.registryInspect)).Methods(http.MethodGet)
+	registryAccessRouter.Handle("/registries/{id}/repositories", httperror.LoggerHandler(handler.registryRepositoryList)).Methods(http.MethodGet)
+	registryAccessRouter.Handle("/registries/{id}/repositories/{repository:.+}/tags", httperror.LoggerHandler(handler.registryTagList)).Methods(http.MethodGet)
+	registryAccessRouter.Handle("/registries/{id}/repositories/{repository:.+}/manifests/{reference}", httperror.LoggerHandler(handler.registryManifestInspect)).Methods(http.MethodGet)
 
 	// Keep the gitlab proxy on the regular authenticated router as it doesn't require specific registry access
 	authenticatedRouter := handler.NewRoute().Subrouter()
@@ -0,0 +1,295 @@
+package registries
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/registryutils"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+	"github.com/portainer/portainer/pkg/liboras"
+
+	"github.com/segmentio/encoding/json"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// tagPattern is the format of a tag defined by the OCI distribution specification
+var tagPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+
+type registryManifestResponse struct {
+	// Digest of the manifest
+	Digest string `json:"Digest" example:"sha256:6af6f8a1b5b7d0c0f2b7d8e3c4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3"`
+	// Media type of the manifest
+	MediaType string `json:"MediaType" example:"application/vnd.oci.image.manifest.v1+json"`
+	// Size of the manifest in bytes
+	Size int64 `json:"Size" example:"1024"`
+	// Content of the manifest
+	Manifest json.RawMessage `json:"Manifest" swaggertype:"object"`
+}
+
+type registryTagCreatePayload struct {
+	// Tag or digest of the manifest to tag
+	Source string `example:"1.25.3" validate:"required"`
+	// Tag to create, an existing tag is moved to the manifest
+	Tag string `example:"stable" validate:"required"`
+}
+
+func (payload *registryTagCreatePayload) Validate(r *http.Request) error {
+	if payload.Source == "" {
+		return errors.New("Invalid source. A tag or a digest is required")
+	}
+
+	if !tagPattern.MatchString(payload.Tag) {
+		return errors.New("Invalid tag. Must match " + tagPattern.String())
+	}
+
+	return nil
+}
+
+// @id RegistryRepositoryList
+// @summary List the repositories of a registry
+// @description **Access policy**: restricted
+// @tags registries
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Registry identifier"
+// @param endpointId query int false "Environment identifier, required for non administrators"
+// @success 200 {array} string "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied to access registry"
+// @failure 404 "Registry not found"
+// @failure 500 "Server error"
+// @router /registries/{id}/repositories [get]
+func (handler *Handler) registryRepositoryList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	registry, registryClient, httpErr := handler.registryClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	repositories, err := liboras.ListRepositories(r.Context(), registry, registryClient)
+	if err != nil {
+		return httperror.InternalServerError("Unable to list the repositories of the registry", err)
+	}
+
+	if repositories == nil {
+		repositories = []string{}
+	}
+
+	return response.JSON(w, repositories)
+}
+
+// @id RegistryTagList
+// @summary List the tags of a repository
+// @description List the tags of a repository with the digest, size, creation date and platforms of the content they point to.
+// @description A tag whose details cannot be retrieved is listed with an error instead.
+// @description **Access policy**: restricted
+// @tags registries
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Registry identifier"
+// @param repository path string true "Repository name, it can contain slashes"
+// @param endpointId query int false "Environment identifier, required for non administrators"
+// @success 200 {array} liboras.TagDetails "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied to access registry"
+// @failure 404 "Registry or repository not found"
+// @failure 500 "Server error"
+// @router /registries/{id}/repositories/{repository}/tags [get]
+func (handler *Handler) registryTagList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	repository, err := request.RetrieveRouteVariableValue(r, "repository")
+	if err != nil {
+		return httperror.BadRequest("Invalid repository route variable", err)
+	}
+
+	_, registryClient, httpErr := handler.registryClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	tags, err := liboras.ListTagDetails(r.Context(), registryClient, repository)
+	if liboras.IsNotFound(err) {
+		return httperror.NotFound("Unable to find the repository inside the registry", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to list the tags of the repository", err)
+	}
+
+	return response.JSON(w, tags)
+}
+
+// @id RegistryManifestInspect
+// @summary Inspect a manifest
+// @description Retrieve the manifest a tag or a digest points to.
+// @description **Access policy**: restricted
+// @tags registries
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Registry identifier"
+// @param repository path string true "Repository name, it can contain slashes"
+// @param reference path string true "Tag or digest"
+// @param endpointId query int false "Environment identifier, required for non administrators"
+// @success 200 {object} registryManifestResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied to access registry"
+// @failure 404 "Registry or manifest not found"
+// @failure 500 "Server error"
+// @router /registries/{id}/repositories/{repository}/manifests/{reference} [get]
+func (handler *Handler) registryManifestInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	repository, err := request.RetrieveRouteVariableValue(r, "repository")
+	if err != nil {
+		return httperror.BadRequest("Invalid repository route variable", err)
+	}
+
+	reference, err := request.RetrieveRouteVariableValue(r, "reference")
+	if err != nil {
+		return httperror.BadRequest("Invalid reference route variable", err)
+	}
+
+	_, registryClient, httpErr := handler.registryClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	descriptor, manifest, err := liboras.FetchManifest(r.Context(), registryClient, repository, reference)
+	if liboras.IsNotFound(err) {
+		return httperror.NotFound("Unable to find the manifest inside the registry", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the manifest", err)
+	}
+
+	return response.JSON(w, &registryManifestResponse{
+		Digest:    descriptor.Digest.String(),
+		MediaType: descriptor.MediaType,
+		Size:      descriptor.Size,
+		Manifest:  manifest,
+	})
+}
+
+// @id RegistryTagCreate
+// @summary Tag a manifest
+// @description Create a tag pointing to the manifest of another tag or digest. An existing tag is moved.
+// @description **Access policy**: administrator
+// @tags registries
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Registry identifier"
+// @param repository path string true "Repository name, it can contain slashes"
+// @param body body registryTagCreatePayload true "Tag details"
+// @success 200 {object} liboras.TagDetails "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Registry or source manifest not found"
+// @failure 500 "Server error"
+// @router /registries/{id}/repositories/{repository}/tags [post]
+func (handler *Handler) registryTagCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	repository, err := request.RetrieveRouteVariableValue(r, "repository")
+	if err != nil {
+		return httperror.BadRequest("Invalid repository route variable", err)
+	}
+
+	var payload registryTagCreatePayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	_, registryClient, httpErr := handler.registryClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if _, err := liboras.RetagManifest(r.Context(), registryClient, repository, payload.Source, payload.Tag); liboras.IsNotFound(err) {
+		return httperror.NotFound("Unable to find the source manifest inside the registry", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to tag the manifest", err)
+	}
+
+	repo, err := registryClient.Repository(r.Context(), repository)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the repository", err)
+	}
+
+	tag, err := liboras.GetTagDetails(r.Context(), repo, payload.Tag)
+	if err != nil {
+		return httperror.InternalServerError("Unable to inspect the tag", err)
+	}
+
+	return response.JSON(w, tag)
+}
+
+// @id RegistryTagDelete
+// @summary Delete a tag
+// @description Delete a tag without removing the other tags pointing to the same manifest.
+// @description **Access policy**: administrator
+// @tags registries
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Registry identifier"
+// @param repository path string true "Repository name, it can contain slashes"
+// @param tag path string true "Tag to delete"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Registry or tag not found"
+// @failure 500 "Server error"
+// @router /registries/{id}/repositories/{repository}/tags/{tag} [delete]
+func (handler *Handler) registryTagDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	repository, err := request.RetrieveRouteVariableValue(r, "repository")
+	if err != nil {
+		return httperror.BadRequest("Invalid repository route variable", err)
+	}
+
+	tag, err := request.RetrieveRouteVariableValue(r, "tag")
+	if err != nil {
+		return httperror.BadRequest("Invalid tag route variable", err)
+	}
+
+	_, registryClient, httpErr := handler.registryClient(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if _, _, err := liboras.FetchManifest(r.Context(), registryClient, repository, tag); liboras.IsNotFound(err) {
+		return httperror.NotFound("Unable to find the tag inside the registry", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the tag", err)
+	}
+
+	if err := liboras.SafeDeleteTags(registryClient, repository, []string{tag}); err != nil {
+		return httperror.InternalServerError("Unable to delete the tag", err)
+	}
+
+	return response.Empty(w)
+}
+
+// registryClient returns the registry of the request and a client authenticated with its effective credentials
+func (handler *Handler) registryClient(r *http.Request) (*portainer.Registry, *remote.Registry, *httperror.HandlerError) {
+	registryID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, nil, httperror.BadRequest("Invalid registry identifier route variable", err)
+	}
+
+	registry, err := handler.DataStore.Registry().Read(portainer.RegistryID(registryID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, nil, httperror.NotFound("Unable to find a registry with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to find a registry with the specified identifier inside the database", err)
+	}
+
+	if err := registryutils.PrepareRegistryCredentials(handler.DataStore, registry); err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to retrieve the registry credentials", err)
+	}
+
+	registryClient, err := liboras.CreateClient(*registry)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to create the registry client", err)
+	}
+
+	return registry, registryClient, nil
+}
@@ -0,0 +1,120 @@
+package registries
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	"github.com/portainer/portainer/pkg/liboras"
+	"github.com/portainer/portainer/pkg/liboras/orastest"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_registryRepositories(t *testing.T) {
+	srv, reg := orastest.RunRegistry()
+	defer srv.Close()
+
+	_, store := datastore.MustNewTestStore(t, false, false)
+
+	registry := &portainer.Registry{Type: portainer.CustomRegistry, URL: strings.TrimPrefix(srv.URL, "http://")}
+	require.NoError(t, store.Registry().Create(registry))
+
+	registryClient, err := liboras.CreateClient(*registry)
+	require.NoError(t, err)
+
+	platform := ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	for _, tag := range []string{"1.0", "ci-1", "ci-2"} {
+		_, err := orastest.PushImage(context.Background(), registryClient, "team/app", tag, platform, time.Now())
+		require.NoError(t, err)
+	}
+
+	handler := NewHandler(testhelpers.NewTestRequestBouncer())
+	handler.DataStore = store
+
+	do := func(method, url string, payload any) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if payload != nil {
+			require.NoError(t, json.NewEncoder(&body).Encode(payload))
+		}
+
+		r := httptest.NewRequest(method, url, &body)
+		r = r.WithContext(security.StoreTokenData(r, &portainer.TokenData{ID: 1, Role: portainer.AdministratorRole}))
+		r = r.WithContext(security.StoreRestrictedRequestContext(r, &security.RestrictedRequestContext{IsAdmin: true, UserID: 1}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	t.Run("lists the repositories", func(t *testing.T) {
+		w := do(http.MethodGet, "/registries/1/repositories", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var repositories []string
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&repositories))
+		assert.Equal(t, []string{"team/app"}, repositories)
+	})
+
+	t.Run("lists the tags", func(t *testing.T) {
+		w := do(http.MethodGet, "/registries/1/repositories/team/app/tags", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var tags []liboras.TagDetails
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&tags))
+		require.Len(t, tags, 3)
+		assert.Equal(t, "1.0", tags[0].Name)
+		assert.Equal(t, []ocispec.Platform{platform}, tags[0].Platforms)
+		assert.NotNil(t, tags[0].Created)
+		assert.Positive(t, tags[0].Size)
+
+		w = do(http.MethodGet, "/registries/1/repositories/team/missing/tags", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("inspects a manifest", func(t *testing.T) {
+		w := do(http.MethodGet, "/registries/1/repositories/team/app/manifests/1.0", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var manifest registryManifestResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&manifest))
+		assert.Equal(t, ocispec.MediaTypeImageManifest, manifest.MediaType)
+		assert.Contains(t, string(manifest.Manifest), ocispec.MediaTypeImageConfig)
+	})
+
+	t.Run("retags a manifest", func(t *testing.T) {
+		w := do(http.MethodPost, "/registries/1/repositories/team/app/tags", registryTagCreatePayload{Source: "1.0", Tag: "latest"})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var tag liboras.TagDetails
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&tag))
+		assert.Equal(t, "latest", tag.Name)
+
+		w = do(http.MethodPost, "/registries/1/repositories/team/app/tags", registryTagCreatePayload{Source: "1.0", Tag: "-invalid"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = do(http.MethodPost, "/registries/1/repositories/team/app/tags", registryTagCreatePayload{Source: "missing", Tag: "other"})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("deletes a tag", func(t *testing.T) {
+		w := do(http.MethodDelete, "/registries/1/repositories/team/app/tags/latest", nil)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, []string{"1.0", "ci-1", "ci-2"}, reg.Tags("team/app"))
+
+		w = do(http.MethodDelete, "/registries/1/repositories/team/app/tags/latest", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
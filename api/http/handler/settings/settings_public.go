@@ -25,6 +25,8 @@ type publicSettingsResponse struct {
 	Features map[featureflags.Feature]bool `json:"Features"`
 	// The URL used for oauth login
 	OAuthLoginURI string `json:"OAuthLoginURI" example:"https://gitlab.com/oauth"`
+	// Whether the oauth login must be started with /auth/oauth/login to use PKCE
+	OAuthPKCE bool `json:"OAuthPKCE" example:"true"`
 	// The URL used for oauth logout
 	OAuthLogoutURI string `json:"OAuthLogoutURI" example:"https://gitlab.com/oauth/logout"`
 	// Whether telemetry is enabled
@@ -94,6 +96,7 @@ func generatePublicSettings(appSettings *portainer.Settings) *publicSettingsResp
 	// If OAuth authentication is on, compose the related fields from application settings
 	if publicSettings.AuthenticationMethod == portainer.AuthenticationOAuth {
 		publicSettings.OAuthLogoutURI = appSettings.OAuthSettings.LogoutURI
+		publicSettings.OAuthPKCE = appSettings.OAuthSettings.PKCE
 		publicSettings.OAuthLoginURI = fmt.Sprintf("%s?response_type=code&client_id=%s&redirect_uri=%s&scope=%s",
 			appSettings.OAuthSettings.AuthorizationURI,
 			appSettings.OAuthSettings.ClientID,
@@ -2,6 +2,7 @@ package settings
 
 import (
 	"cmp"
+	"context"
 	"net/http"
 	"strings"
 	"time"
@@ -10,6 +11,8 @@ import (
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/internal/edge"
+	"github.com/portainer/portainer/api/internal/snapshot"
+	"github.com/portainer/portainer/api/oauth"
 	"github.com/portainer/portainer/pkg/libhelm"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
@@ -20,6 +23,8 @@ import (
 	"golang.org/x/oauth2"
 )
 
+const oauthDiscoveryTimeout = 10 * time.Second
+
 type settingsUpdatePayload struct {
 	// URL to a logo that will be displayed on the login page as well as on top of the sidebar. Will use default Portainer logo when value is empty string
 	LogoURL *string `example:"https://mycompany.mydomain.tld/logo.png"`
@@ -55,6 +60,14 @@ type settingsUpdatePayload struct {
 	EnforceEdgeID *bool `example:"false"`
 	// EdgePortainerURL is the URL that is exposed to edge agents
 	EdgePortainerURL *string `json:"EdgePortainerURL"`
+	// Audit trail configuration
+	AuditLog *portainer.AuditLogSettings
+	// The number of revisions kept for each stack
+	MaxStackRevisions *int `example:"10"`
+	// The internal users that must authenticate with a second factor: 0 for none, 1 for administrators or 2 for all users
+	TwoFactorRequirement *portainer.TwoFactorRequirement `example:"1"`
+	// Retention of the summaries of the environment snapshots
+	SnapshotHistory *portainer.SnapshotHistorySettings
 }
 
 func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
@@ -92,10 +105,46 @@ func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
 		}
 	}
 
+	if payload.AuditLog != nil && payload.AuditLog.RetentionDays < 0 {
+		return errors.New("Invalid audit log retention. Value must be a positive number of days or 0 to keep entries forever")
+	}
+
+	if payload.MaxStackRevisions != nil && *payload.MaxStackRevisions < 1 {
+		return errors.New("Invalid maximum number of stack revisions. Value must be at least 1")
+	}
+
+	if payload.SnapshotHistory != nil {
+		if _, _, err := snapshot.HistoryRetention(*payload.SnapshotHistory); err != nil {
+			return errors.Wrap(err, "Invalid snapshot history retention. Values must be durations such as 24h, or 0")
+		}
+	}
+
+	if payload.TwoFactorRequirement != nil && (*payload.TwoFactorRequirement < portainer.TwoFactorOptional || *payload.TwoFactorRequirement > portainer.TwoFactorRequiredForAll) {
+		return errors.New("Invalid two-factor authentication requirement. Value must be one of: 0 (none), 1 (administrators) or 2 (all users)")
+	}
+
 	if payload.OAuthSettings != nil {
 		if payload.OAuthSettings.AuthStyle < oauth2.AuthStyleAutoDetect || payload.OAuthSettings.AuthStyle > oauth2.AuthStyleInHeader {
 			return errors.New("Invalid OAuth AuthStyle")
 		}
+
+		if payload.OAuthSettings.IssuerURL != "" && !validate.IsURL(payload.OAuthSettings.IssuerURL) {
+			return errors.New("Invalid OAuth issuer URL. Must correspond to a valid URL format")
+		}
+
+		if payload.OAuthSettings.JWKSURI != "" && !validate.IsURL(payload.OAuthSettings.JWKSURI) {
+			return errors.New("Invalid OAuth JWKS URL. Must correspond to a valid URL format")
+		}
+
+		if len(payload.OAuthSettings.TeamMappings) > 0 && payload.OAuthSettings.GroupClaim == "" {
+			return errors.New("Invalid OAuth team mappings. A group claim is required")
+		}
+
+		for _, mapping := range payload.OAuthSettings.TeamMappings {
+			if mapping.ClaimValue == "" || mapping.TeamID <= 0 {
+				return errors.New("Invalid OAuth team mapping. A claim value and a team are required")
+			}
+		}
 	}
 
 	return nil
@@ -122,6 +171,18 @@ func (handler *Handler) settingsUpdate(w http.ResponseWriter, r *http.Request) *
 		return httperror.BadRequest("Invalid request payload", err)
 	}
 
+	if payload.OAuthSettings != nil && payload.OAuthSettings.IssuerURL != "" {
+		ctx, cancel := context.WithTimeout(r.Context(), oauthDiscoveryTimeout)
+		defer cancel()
+
+		doc, err := oauth.Discover(ctx, payload.OAuthSettings.IssuerURL)
+		if err != nil {
+			return httperror.BadRequest("Unable to discover the OAuth provider configuration", err)
+		}
+
+		oauth.ApplyDiscovery(payload.OAuthSettings, doc)
+	}
+
 	var settings *portainer.Settings
 	if err = handler.DataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
 		settings, err = handler.updateSettings(tx, payload)
@@ -229,6 +290,10 @@ func (handler *Handler) updateSettings(tx dataservices.DataStoreTx, payload sett
 	}
 
 	settings.KubectlShellImage = *cmp.Or(payload.KubectlShellImage, &settings.KubectlShellImage)
+	settings.AuditLog = *cmp.Or(payload.AuditLog, &settings.AuditLog)
+	settings.MaxStackRevisions = *cmp.Or(payload.MaxStackRevisions, &settings.MaxStackRevisions)
+	settings.TwoFactorRequirement = *cmp.Or(payload.TwoFactorRequirement, &settings.TwoFactorRequirement)
+	settings.SnapshotHistory = *cmp.Or(payload.SnapshotHistory, &settings.SnapshotHistory)
 
 	if err := tx.Settings().UpdateSettings(settings); err != nil {
 		return nil, httperror.InternalServerError("Unable to persist settings changes inside the database", err)
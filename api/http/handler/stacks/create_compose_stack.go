@@ -7,6 +7,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/git"
 	"github.com/portainer/portainer/api/git/update"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/stacks/deployments"
@@ -14,7 +15,6 @@ import (
 	"github.com/portainer/portainer/api/stacks/stackutils"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
-	"github.com/portainer/portainer/pkg/validate"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -23,12 +23,16 @@ import (
 type composeStackFromFileContentPayload struct {
 	// Name of the stack
 	Name string `example:"myStack" validate:"required"`
-	// Content of the Stack file
-	StackFileContent string `example:"version: 3\n services:\n web:\n image:nginx" validate:"required"`
+	// Content of the Stack file, required unless CustomTemplateID is set
+	StackFileContent string `example:"version: 3\n services:\n web:\n image:nginx"`
 	// A list of environment variables used during stack deployment
 	Env []portainer.Pair
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
+	// Identifier of a custom template rendered as the stack file, used instead of StackFileContent
+	CustomTemplateID int `example:"1"`
+	// Values of the variables of the custom template, the default value of a variable is used when it has no value
+	Variables []portainer.Pair
 }
 
 func (payload *composeStackFromFileContentPayload) Validate(r *http.Request) error {
@@ -36,9 +40,18 @@ func (payload *composeStackFromFileContentPayload) Validate(r *http.Request) err
 		return errors.New("Invalid stack name")
 	}
 
-	if len(payload.StackFileContent) == 0 {
+	if len(payload.StackFileContent) == 0 && payload.CustomTemplateID == 0 {
 		return errors.New("Invalid stack file content")
 	}
+
+	if payload.CustomTemplateID != 0 && len(payload.StackFileContent) > 0 {
+		return errors.New("Stack file content and custom template are mutually exclusive")
+	}
+
+	if payload.CustomTemplateID == 0 && len(payload.Variables) > 0 {
+		return errors.New("Variables can only be used with a custom template")
+	}
+
 	return nil
 }
 
@@ -91,6 +104,7 @@ func (handler *Handler) checkAndCleanStackDupFromSwarm(w http.ResponseWriter, r
 // @id StackCreateDockerStandaloneString
 // @summary Deploy a new compose stack from a text
 // @description Deploy a new stack into a Docker environment specified via the environment identifier.
+// @description The stack file can be rendered from a custom template with CustomTemplateID and Variables instead of StackFileContent.
 // @description **Access policy**: authenticated
 // @tags stacks
 // @security ApiKeyAuth
@@ -139,7 +153,17 @@ func (handler *Handler) createComposeStackFromFileContent(w http.ResponseWriter,
 		return httperror.InternalServerError("Unable to retrieve info from request context", err)
 	}
 
+	if payload.CustomTemplateID != 0 {
+		var httpErr *httperror.HandlerError
+		payload.StackFileContent, payload.Variables, httpErr = handler.renderCustomTemplate(r, portainer.CustomTemplateID(payload.CustomTemplateID), portainer.DockerComposeStack, payload.Variables)
+		if httpErr != nil {
+			return httpErr
+		}
+	}
+
 	stackPayload := createStackPayloadFromComposeFileContentPayload(payload.Name, payload.StackFileContent, payload.Env, payload.FromAppTemplate)
+	stackPayload.CustomTemplateID = portainer.CustomTemplateID(payload.CustomTemplateID)
+	stackPayload.CustomTemplateVariables = payload.Variables
 
 	composeStackBuilder := stackbuilders.CreateComposeStackFileContentBuilder(securityContext,
 		handler.DataStore,
@@ -168,6 +192,8 @@ type composeStackFromGitRepositoryPayload struct {
 	RepositoryUsername string `example:"myGitUsername"`
 	// Password used in basic authentication. Required when RepositoryAuthentication is true.
 	RepositoryPassword string `example:"myGitPassword"`
+	// Git credential used in place of RepositoryUsername and RepositoryPassword
+	RepositoryGitCredentialID int `example:"0"`
 	// Path to the Stack file inside the Git repository
 	ComposeFile string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// Applicable when deploying with multiple stack files
@@ -205,11 +231,11 @@ func (payload *composeStackFromGitRepositoryPayload) Validate(r *http.Request) e
 	if len(payload.Name) == 0 {
 		return errors.New("Invalid stack name")
 	}
-	if len(payload.RepositoryURL) == 0 || !validate.IsURL(payload.RepositoryURL) {
+	if len(payload.RepositoryURL) == 0 || !git.IsRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && len(payload.RepositoryPassword) == 0 {
-		return errors.New("Invalid repository credentials. Password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && len(payload.RepositoryPassword) == 0 && payload.RepositoryGitCredentialID == 0 {
+		return errors.New("Invalid repository credentials. Password or Git credential must be specified when authentication is enabled")
 	}
 	if err := update.ValidateAutoUpdateSettings(payload.AutoUpdate); err != nil {
 		return err
@@ -278,6 +304,12 @@ func (handler *Handler) createComposeStackFromGitRepository(w http.ResponseWrite
 		}
 	}
 
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID != 0 {
+		if httpErr := handler.validateGitCredentialAccess(nil, payload.RepositoryGitCredentialID, userID); httpErr != nil {
+			return httpErr
+		}
+	}
+
 	securityContext, err := security.RetrieveRestrictedRequestContext(r)
 	if err != nil {
 		return httperror.InternalServerError("Unable to retrieve info from request context", err)
@@ -297,6 +329,8 @@ func (handler *Handler) createComposeStackFromGitRepository(w http.ResponseWrite
 		payload.TLSSkipVerify,
 	)
 
+	stackPayload.GitCredentialID = payload.RepositoryGitCredentialID
+
 	composeStackBuilder := stackbuilders.CreateComposeStackGitBuilder(securityContext,
 		handler.DataStore,
 		handler.FileService,
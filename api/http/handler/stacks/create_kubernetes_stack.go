@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
 	"github.com/portainer/portainer/api/git/update"
 	"github.com/portainer/portainer/api/internal/endpointutils"
 	"github.com/portainer/portainer/api/internal/registryutils"
@@ -27,6 +28,10 @@ type kubernetesStringDeploymentPayload struct {
 	StackFileContent string
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
+	// Identifier of a custom template rendered as the stack file, used instead of StackFileContent
+	CustomTemplateID int `example:"1"`
+	// Values of the variables of the custom template, the default value of a variable is used when it has no value
+	Variables []portainer.Pair
 }
 
 func createStackPayloadFromK8sFileContentPayload(name, namespace, fileContent string, composeFormat, fromAppTemplate bool) stackbuilders.StackPayload {
@@ -47,9 +52,11 @@ type kubernetesGitDeploymentPayload struct {
 	RepositoryAuthentication bool
 	RepositoryUsername       string
 	RepositoryPassword       string
-	ManifestFile             string
-	AdditionalFiles          []string
-	AutoUpdate               *portainer.AutoUpdateSettings
+	// Git credential used in place of RepositoryUsername and RepositoryPassword
+	RepositoryGitCredentialID int `example:"0"`
+	ManifestFile              string
+	AdditionalFiles           []string
+	AutoUpdate                *portainer.AutoUpdateSettings
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
 }
@@ -88,20 +95,28 @@ func createStackPayloadFromK8sUrlPayload(name, namespace, manifestUrl string, co
 }
 
 func (payload *kubernetesStringDeploymentPayload) Validate(r *http.Request) error {
-	if len(payload.StackFileContent) == 0 {
+	if len(payload.StackFileContent) == 0 && payload.CustomTemplateID == 0 {
 		return errors.New("Invalid stack file content")
 	}
 
+	if payload.CustomTemplateID != 0 && len(payload.StackFileContent) > 0 {
+		return errors.New("Stack file content and custom template are mutually exclusive")
+	}
+
+	if payload.CustomTemplateID == 0 && len(payload.Variables) > 0 {
+		return errors.New("Variables can only be used with a custom template")
+	}
+
 	return nil
 }
 
 func (payload *kubernetesGitDeploymentPayload) Validate(r *http.Request) error {
-	if len(payload.RepositoryURL) == 0 || !validate.IsURL(payload.RepositoryURL) {
+	if len(payload.RepositoryURL) == 0 || !git.IsRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
 
-	if payload.RepositoryAuthentication && len(payload.RepositoryPassword) == 0 {
-		return errors.New("Invalid repository credentials. Password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && len(payload.RepositoryPassword) == 0 && payload.RepositoryGitCredentialID == 0 {
+		return errors.New("Invalid repository credentials. Password or Git credential must be specified when authentication is enabled")
 	}
 
 	if len(payload.ManifestFile) == 0 {
@@ -126,6 +141,7 @@ type createKubernetesStackResponse struct {
 // @id StackCreateKubernetesFile
 // @summary Deploy a new kubernetes stack from a file
 // @description Deploy a new stack into a Docker environment specified via the environment identifier.
+// @description The stack file can be rendered from a custom template with CustomTemplateID and Variables instead of StackFileContent.
 // @description **Access policy**: authenticated
 // @tags stacks
 // @security ApiKeyAuth
@@ -152,7 +168,17 @@ func (handler *Handler) createKubernetesStackFromFileContent(w http.ResponseWrit
 		return httperror.InternalServerError("Unable to load user information from the database", err)
 	}
 
+	if payload.CustomTemplateID != 0 {
+		var httpErr *httperror.HandlerError
+		payload.StackFileContent, payload.Variables, httpErr = handler.renderCustomTemplate(r, portainer.CustomTemplateID(payload.CustomTemplateID), portainer.KubernetesStack, payload.Variables)
+		if httpErr != nil {
+			return httpErr
+		}
+	}
+
 	stackPayload := createStackPayloadFromK8sFileContentPayload(payload.StackName, payload.Namespace, payload.StackFileContent, payload.ComposeFormat, payload.FromAppTemplate)
+	stackPayload.CustomTemplateID = portainer.CustomTemplateID(payload.CustomTemplateID)
+	stackPayload.CustomTemplateVariables = payload.Variables
 
 	k8sStackBuilder := stackbuilders.CreateK8sStackFileContentBuilder(handler.DataStore,
 		handler.FileService,
@@ -210,6 +236,12 @@ func (handler *Handler) createKubernetesStackFromGitRepository(w http.ResponseWr
 		return httperror.InternalServerError("Unable to load user information from the database", err)
 	}
 
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID != 0 {
+		if httpErr := handler.validateGitCredentialAccess(nil, payload.RepositoryGitCredentialID, userID); httpErr != nil {
+			return httpErr
+		}
+	}
+
 	// Make sure the webhook ID is unique
 	if payload.AutoUpdate != nil && payload.AutoUpdate.Webhook != "" {
 		if isUnique, err := handler.checkUniqueWebhookID(payload.AutoUpdate.Webhook); err != nil {
@@ -232,6 +264,7 @@ func (handler *Handler) createKubernetesStackFromGitRepository(w http.ResponseWr
 		payload.AutoUpdate,
 		payload.TLSSkipVerify,
 	)
+	stackPayload.GitCredentialID = payload.RepositoryGitCredentialID
 
 	k8sStackBuilder := stackbuilders.CreateKubernetesStackGitBuilder(handler.DataStore,
 		handler.FileService,
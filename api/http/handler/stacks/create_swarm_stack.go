@@ -5,13 +5,13 @@ import (
 	"net/http"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
 	"github.com/portainer/portainer/api/git/update"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/stacks/stackbuilders"
 	"github.com/portainer/portainer/api/stacks/stackutils"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
-	valid "github.com/portainer/portainer/pkg/validate"
 
 	"github.com/pkg/errors"
 )
@@ -21,12 +21,16 @@ type swarmStackFromFileContentPayload struct {
 	Name string `example:"myStack" validate:"required"`
 	// Swarm cluster identifier
 	SwarmID string `example:"jpofkc0i9uo9wtx1zesuk649w" validate:"required"`
-	// Content of the Stack file
-	StackFileContent string `example:"version: 3\n services:\n web:\n image:nginx" validate:"required"`
+	// Content of the Stack file, required unless CustomTemplateID is set
+	StackFileContent string `example:"version: 3\n services:\n web:\n image:nginx"`
 	// A list of environment variables used during stack deployment
 	Env []portainer.Pair
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
+	// Identifier of a custom template rendered as the stack file, used instead of StackFileContent
+	CustomTemplateID int `example:"1"`
+	// Values of the variables of the custom template, the default value of a variable is used when it has no value
+	Variables []portainer.Pair
 }
 
 func (payload *swarmStackFromFileContentPayload) Validate(r *http.Request) error {
@@ -36,9 +40,18 @@ func (payload *swarmStackFromFileContentPayload) Validate(r *http.Request) error
 	if len(payload.SwarmID) == 0 {
 		return errors.New("Invalid Swarm ID")
 	}
-	if len(payload.StackFileContent) == 0 {
+	if len(payload.StackFileContent) == 0 && payload.CustomTemplateID == 0 {
 		return errors.New("Invalid stack file content")
 	}
+
+	if payload.CustomTemplateID != 0 && len(payload.StackFileContent) > 0 {
+		return errors.New("Stack file content and custom template are mutually exclusive")
+	}
+
+	if payload.CustomTemplateID == 0 && len(payload.Variables) > 0 {
+		return errors.New("Variables can only be used with a custom template")
+	}
+
 	return nil
 }
 
@@ -55,6 +68,7 @@ func createStackPayloadFromSwarmFileContentPayload(name string, swarmID string,
 // @id StackCreateDockerSwarmString
 // @summary Deploy a new swarm stack from a text
 // @description Deploy a new stack into a Docker environment specified via the environment identifier.
+// @description The stack file can be rendered from a custom template with CustomTemplateID and Variables instead of StackFileContent.
 // @description **Access policy**: authenticated
 // @tags stacks
 // @security ApiKeyAuth
@@ -89,7 +103,17 @@ func (handler *Handler) createSwarmStackFromFileContent(w http.ResponseWriter, r
 		return httperror.InternalServerError("Unable to retrieve info from request context", err)
 	}
 
+	if payload.CustomTemplateID != 0 {
+		var httpErr *httperror.HandlerError
+		payload.StackFileContent, payload.Variables, httpErr = handler.renderCustomTemplate(r, portainer.CustomTemplateID(payload.CustomTemplateID), portainer.DockerSwarmStack, payload.Variables)
+		if httpErr != nil {
+			return httpErr
+		}
+	}
+
 	stackPayload := createStackPayloadFromSwarmFileContentPayload(payload.Name, payload.SwarmID, payload.StackFileContent, payload.Env, payload.FromAppTemplate)
+	stackPayload.CustomTemplateID = portainer.CustomTemplateID(payload.CustomTemplateID)
+	stackPayload.CustomTemplateVariables = payload.Variables
 
 	swarmStackBuilder := stackbuilders.CreateSwarmStackFileContentBuilder(securityContext,
 		handler.DataStore,
@@ -123,6 +147,8 @@ type swarmStackFromGitRepositoryPayload struct {
 	RepositoryUsername string `example:"myGitUsername"`
 	// Password used in basic authentication. Required when RepositoryAuthentication is true.
 	RepositoryPassword string `example:"myGitPassword"`
+	// Git credential used in place of RepositoryUsername and RepositoryPassword
+	RepositoryGitCredentialID int `example:"0"`
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
 	// Path to the Stack file inside the Git repository
@@ -142,11 +168,11 @@ func (payload *swarmStackFromGitRepositoryPayload) Validate(r *http.Request) err
 	if len(payload.SwarmID) == 0 {
 		return errors.New("Invalid Swarm ID")
 	}
-	if len(payload.RepositoryURL) == 0 || !valid.IsURL(payload.RepositoryURL) {
+	if len(payload.RepositoryURL) == 0 || !git.IsRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && len(payload.RepositoryPassword) == 0 {
-		return errors.New("Invalid repository credentials. Password must be specified when authentication is enabled")
+	if payload.RepositoryAuthentication && len(payload.RepositoryPassword) == 0 && payload.RepositoryGitCredentialID == 0 {
+		return errors.New("Invalid repository credentials. Password or Git credential must be specified when authentication is enabled")
 	}
 	if err := update.ValidateAutoUpdateSettings(payload.AutoUpdate); err != nil {
 		return err
@@ -218,6 +244,12 @@ func (handler *Handler) createSwarmStackFromGitRepository(w http.ResponseWriter,
 		}
 	}
 
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID != 0 {
+		if httpErr := handler.validateGitCredentialAccess(nil, payload.RepositoryGitCredentialID, userID); httpErr != nil {
+			return httpErr
+		}
+	}
+
 	securityContext, err := security.RetrieveRestrictedRequestContext(r)
 	if err != nil {
 		return httperror.InternalServerError("Unable to retrieve info from request context", err)
@@ -238,6 +270,8 @@ func (handler *Handler) createSwarmStackFromGitRepository(w http.ResponseWriter,
 		payload.TLSSkipVerify,
 	)
 
+	stackPayload.GitCredentialID = payload.RepositoryGitCredentialID
+
 	swarmStackBuilder := stackbuilders.CreateSwarmStackGitBuilder(securityContext,
 		handler.DataStore,
 		handler.FileService,
@@ -11,12 +11,15 @@ import (
 	"github.com/portainer/portainer/api/dataservices"
 	dockerclient "github.com/portainer/portainer/api/docker/client"
 	"github.com/portainer/portainer/api/docker/consts"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
+	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
 	"github.com/portainer/portainer/api/internal/endpointutils"
 	"github.com/portainer/portainer/api/kubernetes/cli"
 	"github.com/portainer/portainer/api/scheduler"
 	"github.com/portainer/portainer/api/stacks/deployments"
+	"github.com/portainer/portainer/api/stacks/revisions"
 	"github.com/portainer/portainer/api/stacks/stackutils"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 
@@ -24,6 +27,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 )
 
 // Handler is the HTTP handler used to handle stack operations.
@@ -77,6 +81,16 @@ func NewHandler(bouncer security.BouncerService) *Handler {
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackUpdateGit))).Methods(http.MethodPost)
 	h.Handle("/stacks/{id}/git/redeploy",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackGitRedeploy))).Methods(http.MethodPut)
+	h.Handle("/stacks/{id}/plan",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackPlan))).Methods(http.MethodPost)
+	h.Handle("/stacks/{id}/revisions",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackRevisionList))).Methods(http.MethodGet)
+	h.Handle("/stacks/{id}/revisions/diff",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackRevisionDiff))).Methods(http.MethodGet)
+	h.Handle("/stacks/{id}/revisions/{version:[0-9]+}",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackRevisionInspect))).Methods(http.MethodGet)
+	h.Handle("/stacks/{id}/revisions/{version:[0-9]+}/rollback",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackRevisionRollback))).Methods(http.MethodPost)
 	h.Handle("/stacks/{id}/file",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackFile))).Methods(http.MethodGet)
 	h.Handle("/stacks/{id}/migrate",
@@ -91,6 +105,21 @@ func NewHandler(bouncer security.BouncerService) *Handler {
 	return h
 }
 
+// baselineStackRevision records the state of a stack about to be updated when it has no revision yet
+func (handler *Handler) baselineStackRevision(stack *portainer.Stack) {
+	if err := revisions.Baseline(handler.DataStore, stack); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to record the initial revision of the stack")
+	}
+}
+
+// recordStackRevision adds the deployed state of a stack to its revision history, a failure does not fail the
+// deployment and is only logged
+func (handler *Handler) recordStackRevision(stack *portainer.Stack, username, note string) {
+	if _, err := revisions.Record(handler.DataStore, stack, username, note); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to record the stack revision")
+	}
+}
+
 func (handler *Handler) userCanAccessStack(securityContext *security.RestrictedRequestContext, endpointID portainer.EndpointID, resourceControl *portainer.ResourceControl) (bool, error) {
 	user, err := handler.DataStore.User().Read(securityContext.UserID)
 	if err != nil {
@@ -212,3 +241,17 @@ func (handler *Handler) checkUniqueWebhookID(webhookID string) (bool, error) {
 	}
 	return false, err
 }
+
+// validateGitCredentialAccess checks that the user can reference the Git credential, a credential already
+// referenced by the current Git configuration of the stack can be kept by any user allowed to edit the stack
+func (handler *Handler) validateGitCredentialAccess(gitConfig *gittypes.RepoConfig, credentialID int, userID portainer.UserID) *httperror.HandlerError {
+	if gitConfig != nil && gitConfig.Authentication != nil && gitConfig.Authentication.GitCredentialID == credentialID {
+		return nil
+	}
+
+	if err := gitcredentials.ValidateAccess(handler.DataStore, credentialID, userID); err != nil {
+		return httperror.Forbidden("Permission denied to use the Git credential", err)
+	}
+
+	return nil
+}
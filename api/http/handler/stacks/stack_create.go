@@ -4,6 +4,9 @@ import (
 	"net/http"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates"
+	"github.com/portainer/portainer/api/dataservices"
+	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
 	"github.com/portainer/portainer/api/stacks/stackutils"
@@ -140,3 +143,53 @@ func (handler *Handler) decorateStackResponse(w http.ResponseWriter, stack *port
 
 	return response.JSON(w, stack)
 }
+
+// renderCustomTemplate renders the custom template a stack is created from with the provided variable values,
+// it returns the rendered file content and the values of all the template variables
+func (handler *Handler) renderCustomTemplate(r *http.Request, customTemplateID portainer.CustomTemplateID, stackType portainer.StackType, values []portainer.Pair) (string, []portainer.Pair, *httperror.HandlerError) {
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return "", nil, httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	var customTemplate *portainer.CustomTemplate
+	var hasAccess bool
+	if err := handler.DataStore.ViewTx(func(tx dataservices.DataStoreTx) error {
+		if customTemplate, err = tx.CustomTemplate().Read(customTemplateID); err != nil {
+			return err
+		}
+
+		hasAccess, err = customtemplates.UserCanAccess(tx, customTemplate, securityContext)
+
+		return err
+	}); handler.DataStore.IsErrObjectNotFound(err) {
+		return "", nil, httperror.BadRequest("Unable to find a custom template with the specified identifier inside the database", err)
+	} else if err != nil {
+		return "", nil, httperror.InternalServerError("Unable to find a custom template with the specified identifier inside the database", err)
+	}
+
+	if !hasAccess {
+		return "", nil, httperror.Forbidden("Access denied to the custom template", httperrors.ErrResourceAccessDenied)
+	}
+
+	if customTemplate.Type != stackType {
+		return "", nil, httperror.BadRequest("Invalid custom template", errors.New("the custom template cannot be deployed as this type of stack"))
+	}
+
+	variables, err := customtemplates.ResolveVariables(customTemplate.Variables, values)
+	if err != nil {
+		return "", nil, httperror.BadRequest("Invalid template variables", err)
+	}
+
+	fileContent, err := customtemplates.FileContent(handler.FileService, customTemplate)
+	if err != nil {
+		return "", nil, httperror.InternalServerError("Unable to retrieve custom template file from disk", err)
+	}
+
+	rendered, err := customtemplates.Render(string(fileContent), variables)
+	if err != nil {
+		return "", nil, httperror.InternalServerError("Unable to render the custom template", err)
+	}
+
+	return rendered, variables, nil
+}
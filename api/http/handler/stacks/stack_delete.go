@@ -11,6 +11,7 @@ import (
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/stacks/deployments"
+	"github.com/portainer/portainer/api/stacks/revisions"
 	"github.com/portainer/portainer/api/stacks/stackutils"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
@@ -125,6 +126,10 @@ func (handler *Handler) stackDelete(w http.ResponseWriter, r *http.Request) *htt
 		return httperror.InternalServerError("Unable to remove the stack from the database", err)
 	}
 
+	if err := revisions.RemoveAll(handler.DataStore, stack.ID); err != nil {
+		log.Warn().Err(err).Msg("Unable to remove the stack revisions from the database")
+	}
+
 	if resourceControl != nil {
 		if err := handler.DataStore.ResourceControl().Delete(resourceControl.ID); err != nil {
 			return httperror.InternalServerError("Unable to remove the associated resource control from the database", err)
@@ -340,6 +345,10 @@ func (handler *Handler) stackDeleteKubernetesByName(w http.ResponseWriter, r *ht
 			continue
 		}
 
+		if err := revisions.RemoveAll(handler.DataStore, stack.ID); err != nil {
+			log.Warn().Err(err).Msg("Unable to remove the stack revisions from the database")
+		}
+
 		if err := handler.FileService.RemoveDirectory(stack.ProjectPath); err != nil {
 			errors = append(errors, err)
 			log.Warn().Err(err).Msg("Unable to remove stack files from disk")
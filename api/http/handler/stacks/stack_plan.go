@@ -0,0 +1,150 @@
+package stacks
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/git"
+	"github.com/portainer/portainer/api/stacks/stackutils"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/pkg/errors"
+)
+
+type stackPlanPayload struct {
+	// Candidate content of the stack file, the stored file is used when empty. Ignored for Git stacks
+	StackFileContent string `example:"services:\n  web:\n    image: nginx"`
+	// A list of environment(endpoint) variables used by the candidate deployment, the stored variables are used when omitted
+	Env []portainer.Pair
+	// Reference of the repository to plan against, the reference of the stack is used when empty. Only for Git stacks
+	RepositoryReferenceName string `example:"refs/heads/master"`
+}
+
+func (payload *stackPlanPayload) Validate(r *http.Request) error {
+	return nil
+}
+
+type stackPlanResponse struct {
+	// Commit the plan was computed against, only for Git stacks
+	CommitHash string `json:"CommitHash,omitempty" example:"c0ffee"`
+	// Changes applied to each service of the stack
+	Services []portainer.ComposeServiceChange `json:"Services"`
+}
+
+// @id StackPlan
+// @summary Preview the deployment of a stack
+// @description Compute the changes that updating a Compose stack would apply to its containers, without applying them.
+// @description Each service is either created, recreated with the reasons of the recreation, left unchanged,
+// @description or removed/left running when it is no longer in the stack (orphan).
+// @description Git stacks are planned against the latest commit of their reference.
+// @description **Access policy**: restricted
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Stack identifier"
+// @param body body stackPlanPayload true "Candidate stack file and environment variables"
+// @success 200 {object} stackPlanResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/plan [post]
+func (handler *Handler) stackPlan(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stack, endpoint, httpErr := handler.retrieveRevisionStack(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if endpoint == nil {
+		return httperror.BadRequest("The stack is not associated to an environment", errors.New("orphaned stack"))
+	}
+
+	if stack.Type != portainer.DockerComposeStack {
+		return httperror.BadRequest("Deployment plans are only available for Compose stacks", errors.Errorf("unsupported stack type: %v", stack.Type))
+	}
+
+	if stackutils.IsRelativePathStack(stack) {
+		return httperror.BadRequest("Deployment plans are not available for stacks using relative paths", errors.New("relative path stack"))
+	}
+
+	var payload stackPlanPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	stack.Name = handler.ComposeStackManager.NormalizeStackName(stack.Name)
+	if payload.Env != nil {
+		stack.Env = payload.Env
+	}
+
+	tmpDir, err := os.MkdirTemp("", "stack_plan")
+	if err != nil {
+		return httperror.InternalServerError("Unable to create a temporary folder", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var resp stackPlanResponse
+	var filePaths []string
+
+	switch {
+	case stack.GitConfig != nil:
+		resp.CommitHash, err = handler.cloneStackForPlan(stack, payload.RepositoryReferenceName, tmpDir)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the latest version of the stack from the Git repository", err)
+		}
+
+		// Relative paths such as env_file entries must resolve inside the clone being planned
+		stack.ProjectPath = tmpDir
+
+		for _, file := range stackutils.GetStackFilePaths(stack, false) {
+			filePaths = append(filePaths, filesystem.JoinPaths(tmpDir, file))
+		}
+	case payload.StackFileContent != "":
+		entryPoint := filesystem.JoinPaths(tmpDir, filepath.Base(stack.EntryPoint))
+		if err := os.WriteFile(entryPoint, []byte(payload.StackFileContent), 0600); err != nil {
+			return httperror.InternalServerError("Unable to write the candidate stack file", err)
+		}
+
+		filePaths = append([]string{entryPoint}, stackutils.GetStackFilePaths(stack, true)[1:]...)
+	}
+
+	resp.Services, err = handler.ComposeStackManager.Plan(r.Context(), stack, endpoint, portainer.ComposePlanOptions{
+		FilePaths: filePaths,
+	})
+	if err != nil {
+		return httperror.InternalServerError("Unable to compute the deployment plan of the stack", err)
+	}
+
+	return response.JSON(w, resp)
+}
+
+// cloneStackForPlan clones the latest commit of the reference of a Git stack into a folder, using the credentials
+// of the stack, and returns the hash of the commit
+func (handler *Handler) cloneStackForPlan(stack *portainer.Stack, referenceName, dir string) (string, error) {
+	if referenceName == "" {
+		referenceName = stack.GitConfig.ReferenceName
+	}
+
+	username, password, authType, err := git.GetCredentials(handler.GitService, stack.GitConfig.Authentication)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to retrieve the Git credentials")
+	}
+
+	commitHash, err := handler.GitService.LatestCommitID(stack.GitConfig.URL, referenceName, username, password, authType, stack.GitConfig.TLSSkipVerify)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to fetch the latest commit id")
+	}
+
+	if err := handler.GitService.CloneRepository(dir, stack.GitConfig.URL, referenceName, username, password, authType, stack.GitConfig.TLSSkipVerify); err != nil {
+		return "", errors.WithMessage(err, "failed to clone the repository")
+	}
+
+	return commitHash, nil
+}
@@ -0,0 +1,157 @@
+package stacks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+type planComposeStackManager struct {
+	portainer.ComposeStackManager
+
+	env         []portainer.Pair
+	projectPath string
+	filePaths   []string
+	contents    []string
+}
+
+func (manager *planComposeStackManager) Plan(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint, options portainer.ComposePlanOptions) ([]portainer.ComposeServiceChange, error) {
+	manager.env = stack.Env
+	manager.projectPath = stack.ProjectPath
+	manager.filePaths = options.FilePaths
+	manager.contents = nil
+
+	for _, filePath := range options.FilePaths {
+		content, err := os.ReadFile(filePath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		manager.contents = append(manager.contents, string(content))
+	}
+
+	return []portainer.ComposeServiceChange{{Service: "web", Action: "recreate", Reasons: []string{"image"}}}, nil
+}
+
+func TestStackPlan(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	admin := &portainer.User{Username: "admin", Role: portainer.AdministratorRole}
+	require.NoError(t, store.User().Create(admin))
+
+	endpoint := &portainer.Endpoint{ID: 1, Name: "endpoint", Type: portainer.DockerEnvironment}
+	require.NoError(t, store.Endpoint().Create(endpoint))
+
+	composeStack := &portainer.Stack{
+		ID:         1,
+		Name:       "compose",
+		Type:       portainer.DockerComposeStack,
+		EndpointID: endpoint.ID,
+		EntryPoint: "docker-compose.yml",
+		Env:        []portainer.Pair{{Name: "TAG", Value: "1.25"}},
+	}
+	require.NoError(t, store.Stack().Create(composeStack))
+
+	gitStack := &portainer.Stack{
+		ID:          2,
+		Name:        "git",
+		Type:        portainer.DockerComposeStack,
+		EndpointID:  endpoint.ID,
+		EntryPoint:  "docker-compose.yml",
+		ProjectPath: "/data/compose/2",
+		GitConfig:   &gittypes.RepoConfig{URL: "https://github.com/portainer/portainer.git", ReferenceName: "refs/heads/main"},
+	}
+	require.NoError(t, store.Stack().Create(gitStack))
+
+	swarmStack := &portainer.Stack{ID: 3, Name: "swarm", Type: portainer.DockerSwarmStack, EndpointID: endpoint.ID, EntryPoint: "docker-compose.yml"}
+	require.NoError(t, store.Stack().Create(swarmStack))
+
+	manager := &planComposeStackManager{ComposeStackManager: testhelpers.NewComposeStackManager()}
+
+	handler := NewHandler(testhelpers.NewTestRequestBouncer())
+	handler.DataStore = store
+	handler.ComposeStackManager = manager
+	handler.GitService = testhelpers.NewGitService(nil, "c0ffee")
+
+	plan := func(stackID portainer.StackID, payload stackPlanPayload) *httptest.ResponseRecorder {
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/stacks/"+strconv.Itoa(int(stackID))+"/plan", bytes.NewReader(body))
+		req = req.WithContext(security.StoreRestrictedRequestContext(req, &security.RestrictedRequestContext{
+			IsAdmin: true,
+			UserID:  admin.ID,
+		}))
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	t.Run("candidate file", func(t *testing.T) {
+		content := "services:\n  web:\n    image: nginx:1.27\n"
+		env := []portainer.Pair{{Name: "TAG", Value: "1.27"}}
+
+		rr := plan(composeStack.ID, stackPlanPayload{StackFileContent: content, Env: env})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp stackPlanResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		require.Empty(t, resp.CommitHash)
+		require.Equal(t, []portainer.ComposeServiceChange{{Service: "web", Action: "recreate", Reasons: []string{"image"}}}, resp.Services)
+
+		require.Equal(t, []string{content}, manager.contents)
+		require.Equal(t, env, manager.env)
+
+		// The stack itself is not updated
+		stack, err := store.Stack().Read(composeStack.ID)
+		require.NoError(t, err)
+		require.Equal(t, composeStack.Env, stack.Env)
+	})
+
+	t.Run("stored variables", func(t *testing.T) {
+		rr := plan(composeStack.ID, stackPlanPayload{StackFileContent: "services:\n  web:\n    image: nginx:${TAG}\n"})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		require.Equal(t, composeStack.Env, manager.env)
+	})
+
+	t.Run("git stack", func(t *testing.T) {
+		rr := plan(gitStack.ID, stackPlanPayload{})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp stackPlanResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		require.Equal(t, "c0ffee", resp.CommitHash)
+
+		require.Len(t, manager.filePaths, 1)
+		require.Equal(t, "docker-compose.yml", filepath.Base(manager.filePaths[0]))
+
+		// Relative paths are resolved from the clone, not from the deployed files
+		require.NotEqual(t, gitStack.ProjectPath, manager.projectPath)
+		require.Equal(t, filepath.Join(manager.projectPath, "docker-compose.yml"), manager.filePaths[0])
+	})
+
+	t.Run("swarm stack", func(t *testing.T) {
+		rr := plan(swarmStack.ID, stackPlanPayload{})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,66 @@
+package stacks
+
+import (
+	"net/http"
+
+	"github.com/portainer/portainer/api/stacks/revisions"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id StackRevisionDiff
+// @summary Compare two revisions of a stack
+// @description Retrieve the changes between two revisions of a stack, as a unified diff for each changed file
+// @description and as the added, removed and changed environment variables.
+// @description **Access policy**: restricted
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Stack identifier"
+// @param from query int true "Version of the source revision"
+// @param to query int true "Version of the target revision"
+// @success 200 {object} revisions.Diff "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack or revision not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/revisions/diff [get]
+func (handler *Handler) stackRevisionDiff(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stack, _, httpErr := handler.retrieveRevisionStack(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	fromVersion, err := request.RetrieveNumericQueryParameter(r, "from", false)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: from", err)
+	}
+
+	toVersion, err := request.RetrieveNumericQueryParameter(r, "to", false)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: to", err)
+	}
+
+	from, err := revisions.Find(handler.DataStore, stack.ID, fromVersion)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find the source revision for the stack", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the stack revisions from the database", err)
+	}
+
+	to, err := revisions.Find(handler.DataStore, stack.ID, toVersion)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find the target revision for the stack", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the stack revisions from the database", err)
+	}
+
+	diff, err := revisions.Compare(from, to)
+	if err != nil {
+		return httperror.InternalServerError("Unable to compare the stack revisions", err)
+	}
+
+	return response.JSON(w, diff)
+}
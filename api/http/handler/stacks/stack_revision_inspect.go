@@ -0,0 +1,47 @@
+package stacks
+
+import (
+	"net/http"
+
+	"github.com/portainer/portainer/api/stacks/revisions"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+// @id StackRevisionInspect
+// @summary Inspect a revision of a stack
+// @description Retrieve a revision of a stack, including the content of its files.
+// @description **Access policy**: restricted
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Stack identifier"
+// @param version path int true "Revision version"
+// @success 200 {object} portainer.StackRevision "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack or revision not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/revisions/{version} [get]
+func (handler *Handler) stackRevisionInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stack, _, httpErr := handler.retrieveRevisionStack(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	version, err := request.RetrieveNumericRouteVariableValue(r, "version")
+	if err != nil {
+		return httperror.BadRequest("Invalid revision version route variable", err)
+	}
+
+	revision, err := revisions.Find(handler.DataStore, stack.ID, version)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a revision with the specified version for the stack", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the stack revisions from the database", err)
+	}
+
+	return response.JSON(w, revision)
+}
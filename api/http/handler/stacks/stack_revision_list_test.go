@@ -0,0 +1,139 @@
+package stacks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	"github.com/portainer/portainer/api/stacks/revisions"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStackRevisions(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	admin := &portainer.User{Username: "admin", Role: portainer.AdministratorRole}
+	require.NoError(t, store.User().Create(admin))
+
+	endpoint := &portainer.Endpoint{ID: 1, Name: "endpoint", Type: portainer.DockerEnvironment}
+	require.NoError(t, store.Endpoint().Create(endpoint))
+
+	stack := &portainer.Stack{ID: 1, Name: "stack", Type: portainer.DockerComposeStack, EndpointID: endpoint.ID}
+	require.NoError(t, store.Stack().Create(stack))
+
+	for version, tag := range []string{"1.0", "1.1"} {
+		require.NoError(t, store.StackRevision().Create(&portainer.StackRevision{
+			StackID:    stack.ID,
+			Version:    version + 1,
+			EntryPoint: "docker-compose.yml",
+			Files:      map[string]string{"docker-compose.yml": "services:\n  web:\n    image: nginx:" + tag + "\n"},
+			Env:        []portainer.Pair{{Name: "TAG", Value: tag}},
+		}))
+	}
+
+	handler := NewHandler(testhelpers.NewTestRequestBouncer())
+	handler.DataStore = store
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/stacks/"+strconv.Itoa(int(stack.ID))+path, nil)
+		req = req.WithContext(security.StoreRestrictedRequestContext(req, &security.RestrictedRequestContext{
+			IsAdmin: true,
+			UserID:  admin.ID,
+		}))
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	t.Run("list", func(t *testing.T) {
+		rr := get("/revisions")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var list []portainer.StackRevision
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&list))
+		require.Len(t, list, 2)
+		require.Equal(t, 1, list[0].Version)
+		require.Equal(t, 2, list[1].Version)
+		require.Nil(t, list[0].Files)
+	})
+
+	t.Run("inspect", func(t *testing.T) {
+		rr := get("/revisions/2")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var revision portainer.StackRevision
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&revision))
+		require.Equal(t, "services:\n  web:\n    image: nginx:1.1\n", revision.Files["docker-compose.yml"])
+
+		require.Equal(t, http.StatusNotFound, get("/revisions/3").Code)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		rr := get("/revisions/diff?from=1&to=2")
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var diff revisions.Diff
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&diff))
+		require.Len(t, diff.Files, 1)
+		require.Equal(t, revisions.FileModified, diff.Files[0].Status)
+		require.Equal(t, []revisions.EnvChange{{Name: "TAG", From: "1.0", To: "1.1"}}, diff.Env.Changed)
+
+		require.Equal(t, http.StatusBadRequest, get("/revisions/diff?from=1").Code)
+		require.Equal(t, http.StatusNotFound, get("/revisions/diff?from=1&to=5").Code)
+	})
+}
+
+func TestWriteRevisionFiles(t *testing.T) {
+	fileService, err := filesystem.NewService(t.TempDir(), "")
+	require.NoError(t, err)
+
+	handler := NewHandler(testhelpers.NewTestRequestBouncer())
+	handler.FileService = fileService
+
+	stack := &portainer.Stack{ID: 1, EntryPoint: "compose.yml", AdditionalFiles: []string{"override.yml"}}
+	stack.ProjectPath, err = fileService.StoreStackFileFromBytes("1", "compose.yml", []byte("current"))
+	require.NoError(t, err)
+	_, err = fileService.StoreStackFileFromBytes("1", "override.yml", []byte("added"))
+	require.NoError(t, err)
+
+	revision := &portainer.StackRevision{
+		EntryPoint: "docker-compose.yml",
+		Files:      map[string]string{"docker-compose.yml": "previous"},
+	}
+
+	changes, err := handler.writeRevisionFiles(stack, revision)
+	require.NoError(t, err)
+	require.Equal(t, []string{"docker-compose.yml"}, changes.created)
+	require.Empty(t, revisionAdditionalFiles(revision))
+
+	content, err := fileService.GetFileContent(stack.ProjectPath, "docker-compose.yml")
+	require.NoError(t, err)
+	require.Equal(t, "previous", string(content))
+
+	// The files added after the revision are removed
+	for _, path := range []string{"compose.yml", "override.yml"} {
+		exists, err := fileService.FileExists(filesystem.JoinPaths(stack.ProjectPath, path))
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+
+	handler.restoreRevisionFiles(stack, changes)
+
+	content, err = fileService.GetFileContent(stack.ProjectPath, "override.yml")
+	require.NoError(t, err)
+	require.Equal(t, "added", string(content))
+
+	exists, err := fileService.FileExists(filesystem.JoinPaths(stack.ProjectPath, "docker-compose.yml"))
+	require.NoError(t, err)
+	require.False(t, exists)
+}
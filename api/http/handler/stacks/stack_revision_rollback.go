@@ -0,0 +1,218 @@
+package stacks
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/stacks/revisions"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// @id StackRevisionRollback
+// @summary Roll a stack back to a previous revision
+// @description Redeploy the files and the environment variables of a revision of the stack, the rollback is recorded as a new revision.
+// @description The Git configuration of a stack deployed from Git is kept, it is deployed from Git again on its next update.
+// @description **Access policy**: authenticated
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Stack identifier"
+// @param version path int true "Version of the revision to roll back to"
+// @success 200 {object} portainer.Stack "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack or revision not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/revisions/{version}/rollback [post]
+func (handler *Handler) stackRevisionRollback(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stack, endpoint, httpErr := handler.retrieveRevisionStack(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if endpoint == nil {
+		err := errors.New("the environment of the stack does not exist")
+		return httperror.NotFound("Unable to find the environment associated to the stack inside the database", err)
+	}
+
+	version, err := request.RetrieveNumericRouteVariableValue(r, "version")
+	if err != nil {
+		return httperror.BadRequest("Invalid revision version route variable", err)
+	}
+
+	revision, err := revisions.Find(handler.DataStore, stack.ID, version)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a revision with the specified version for the stack", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the stack revisions from the database", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	user, err := handler.DataStore.User().Read(securityContext.UserID)
+	if err != nil {
+		return httperror.BadRequest("Cannot find context user", errors.Wrap(err, "failed to fetch the user"))
+	}
+
+	stackFolder := strconv.Itoa(int(stack.ID))
+	if stack.ProjectPath != handler.FileService.GetStackProjectPath(stackFolder) {
+		err := errors.New("the files of the stack are not stored in its project folder")
+		return httperror.BadRequest("Unable to roll back the stack", err)
+	}
+
+	changes, err := handler.writeRevisionFiles(stack, revision)
+	if err != nil {
+		handler.restoreRevisionFiles(stack, changes)
+
+		return httperror.InternalServerError("Unable to persist the revision files on disk", err)
+	}
+
+	stack.EntryPoint = revision.EntryPoint
+	stack.AdditionalFiles = revisionAdditionalFiles(revision)
+	stack.Env = slices.Clone(revision.Env)
+
+	if httpErr := handler.deployStack(r, stack, false, endpoint); httpErr != nil {
+		handler.restoreRevisionFiles(stack, changes)
+
+		return httpErr
+	}
+
+	for _, path := range changes.updated {
+		if err := handler.FileService.RemoveStackFileBackup(stackFolder, path); err != nil {
+			log.Warn().Err(err).Msg("unable to remove the stack file backup")
+		}
+	}
+
+	stack.UpdatedBy = user.Username
+	stack.UpdateDate = time.Now().Unix()
+	stack.Status = portainer.StackStatusActive
+
+	if err := handler.DataStore.Stack().Update(stack.ID, stack); err != nil {
+		return httperror.InternalServerError("Unable to persist the stack changes inside the database", err)
+	}
+
+	handler.recordStackRevision(stack, user.Username, fmt.Sprintf("Rollback to revision %d", revision.Version))
+
+	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.Password != "" {
+		// Sanitize password in the http response to minimise possible security leaks
+		stack.GitConfig.Authentication.Password = ""
+	}
+
+	return response.JSON(w, stack)
+}
+
+// revisionChanges records the changes made to the files of a stack by a rollback, to undo them when it fails
+type revisionChanges struct {
+	// Files that did not exist
+	created []string
+	// Files that were backed up before being replaced
+	updated []string
+	// Content of the files that are not part of the revision and were removed
+	removed map[string][]byte
+}
+
+// writeRevisionFiles replaces the files of the stack with the ones of the revision and removes the files of the
+// stack that are not part of the revision
+func (handler *Handler) writeRevisionFiles(stack *portainer.Stack, revision *portainer.StackRevision) (*revisionChanges, error) {
+	stackFolder := strconv.Itoa(int(stack.ID))
+	changes := &revisionChanges{removed: make(map[string][]byte)}
+
+	for path, content := range revision.Files {
+		exists, err := handler.FileService.FileExists(filesystem.JoinPaths(stack.ProjectPath, path))
+		if err != nil {
+			return changes, err
+		}
+
+		if !exists {
+			if _, err := handler.FileService.StoreStackFileFromBytes(stackFolder, path, []byte(content)); err != nil {
+				return changes, err
+			}
+
+			changes.created = append(changes.created, path)
+
+			continue
+		}
+
+		changes.updated = append(changes.updated, path)
+
+		if _, err := handler.FileService.UpdateStoreStackFileFromBytes(stackFolder, path, []byte(content)); err != nil {
+			return changes, err
+		}
+	}
+
+	for _, path := range append([]string{stack.EntryPoint}, stack.AdditionalFiles...) {
+		if _, ok := revision.Files[path]; ok {
+			continue
+		}
+
+		content, err := handler.FileService.GetFileContent(stack.ProjectPath, path)
+		if err != nil {
+			return changes, err
+		}
+
+		if err := handler.FileService.RemoveDirectory(filesystem.JoinPaths(stack.ProjectPath, path)); err != nil {
+			return changes, err
+		}
+
+		changes.removed[path] = content
+	}
+
+	return changes, nil
+}
+
+// restoreRevisionFiles puts the files of the stack back after a failed rollback
+func (handler *Handler) restoreRevisionFiles(stack *portainer.Stack, changes *revisionChanges) {
+	stackFolder := strconv.Itoa(int(stack.ID))
+
+	for _, path := range changes.updated {
+		if err := handler.FileService.RollbackStackFile(stackFolder, path); err != nil {
+			log.Warn().Err(err).Msg("rollback stack file error")
+		}
+	}
+
+	for _, path := range changes.created {
+		if err := handler.FileService.RemoveDirectory(filesystem.JoinPaths(stack.ProjectPath, path)); err != nil {
+			log.Warn().Err(err).Msg("unable to remove the stack file")
+		}
+	}
+
+	for path, content := range changes.removed {
+		if _, err := handler.FileService.StoreStackFileFromBytes(stackFolder, path, content); err != nil {
+			log.Warn().Err(err).Msg("unable to restore the stack file")
+		}
+	}
+}
+
+// revisionAdditionalFiles returns the additional files of a revision, the revisions recorded before their order
+// was kept list them in alphabetical order
+func revisionAdditionalFiles(revision *portainer.StackRevision) []string {
+	if revision.AdditionalFiles != nil {
+		return slices.Clone(revision.AdditionalFiles)
+	}
+
+	var files []string
+	for path := range revision.Files {
+		if path != revision.EntryPoint {
+			files = append(files, path)
+		}
+	}
+
+	slices.Sort(files)
+
+	return files
+}
@@ -25,6 +25,11 @@ type updateComposeStackPayload struct {
 	Env []portainer.Pair
 	// Force a pulling to current image with the original tag though the image is already the latest
 	PullImage bool `example:"false"`
+	// Wait for the services to be healthy after the deployment and redeploy the previous version otherwise, not supported
+	// for stacks deployed with relative paths
+	HealthCheck bool `example:"false"`
+	// Maximum time in seconds to wait for the services to be healthy, defaults to 300
+	HealthCheckTimeout int `example:"300"`
 }
 
 func (payload *updateComposeStackPayload) Validate(r *http.Request) error {
@@ -32,6 +37,10 @@ func (payload *updateComposeStackPayload) Validate(r *http.Request) error {
 		return errors.New("Invalid stack file content")
 	}
 
+	if payload.HealthCheckTimeout < 0 {
+		return errors.New("Invalid health check timeout")
+	}
+
 	return nil
 }
 
@@ -134,6 +143,8 @@ func (handler *Handler) stackUpdate(w http.ResponseWriter, r *http.Request) *htt
 		return httperror.Forbidden(errMsg, errors.New(errMsg))
 	}
 
+	handler.baselineStackRevision(stack)
+
 	if err := handler.updateAndDeployStack(r, stack, endpoint); err != nil {
 		return err
 	}
@@ -151,6 +162,8 @@ func (handler *Handler) stackUpdate(w http.ResponseWriter, r *http.Request) *htt
 		return httperror.InternalServerError("Unable to persist the stack changes inside the database", err)
 	}
 
+	handler.recordStackRevision(stack, user.Username, "")
+
 	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.Password != "" {
 		// Sanitize password in the http response to minimise possible security leaks
 		stack.GitConfig.Authentication.Password = ""
@@ -191,7 +204,13 @@ func (handler *Handler) updateComposeStack(r *http.Request, stack *portainer.Sta
 		return httperror.BadRequest("Invalid request payload", err)
 	}
 
+	if payload.HealthCheck && stackutils.IsRelativePathStack(stack) {
+		return httperror.BadRequest("Invalid request payload", errHealthCheckRelativePath)
+	}
+
+	previousEnv := stack.Env
 	stack.Env = payload.Env
+	setHealthCheckOption(stack, payload.HealthCheck, payload.HealthCheckTimeout)
 
 	if stack.GitConfig != nil {
 		// detach from git
@@ -230,19 +249,67 @@ func (handler *Handler) updateComposeStack(r *http.Request, stack *portainer.Sta
 	}
 
 	// Deploy the stack
-	if err := composeDeploymentConfig.Deploy(); err != nil {
+	err = composeDeploymentConfig.Deploy()
+	if err == nil {
+		err = handler.StackDeployer.WaitForHealthyComposeStack(stack, endpoint)
+	}
+
+	if err != nil {
 		if rollbackErr := handler.FileService.RollbackStackFile(stackFolder, stack.EntryPoint); rollbackErr != nil {
 			log.Warn().Err(rollbackErr).Msg("rollback stack file error")
 		}
 
+		if errors.Is(err, deployments.ErrStackUnhealthy) {
+			return handler.redeployPreviousComposeStack(stack, previousEnv, composeDeploymentConfig.Deploy, err)
+		}
+
 		return httperror.InternalServerError(err.Error(), err)
 	}
 
 	handler.FileService.RemoveStackFileBackup(stackFolder, stack.EntryPoint)
 
+	stack.DeploymentError = ""
+
 	return nil
 }
 
+// redeployPreviousComposeStack redeploys the restored files of a Compose stack whose services did not become
+// healthy, and records the deployment error on the stored stack
+func (handler *Handler) redeployPreviousComposeStack(stack *portainer.Stack, previousEnv []portainer.Pair, deploy func() error, deployErr error) *httperror.HandlerError {
+	stack.Env = previousEnv
+
+	if err := deploy(); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to redeploy the previous version of the stack")
+	}
+
+	storedStack, err := handler.DataStore.Stack().Read(stack.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to find a stack with the specified identifier inside the database", err)
+	}
+
+	storedStack.DeploymentError = deployErr.Error()
+
+	if err := handler.DataStore.Stack().Update(storedStack.ID, storedStack); err != nil {
+		return httperror.InternalServerError("Unable to persist the stack changes inside the database", err)
+	}
+
+	return httperror.InternalServerError("The stack did not become healthy, its previous version was redeployed", deployErr)
+}
+
+// errHealthCheckRelativePath is returned when a health check is requested for a stack deployed with relative paths,
+// those stacks are not deployed by the Compose stack manager and cannot be waited on
+var errHealthCheckRelativePath = errors.New("The health check is not supported for stacks deployed with relative paths")
+
+// setHealthCheckOption updates the health check settings of a stack, keeping its other deployment options
+func setHealthCheckOption(stack *portainer.Stack, healthCheck bool, timeout int) {
+	if stack.Option == nil {
+		stack.Option = &portainer.StackOption{}
+	}
+
+	stack.Option.HealthCheck = healthCheck
+	stack.Option.HealthCheckTimeout = timeout
+}
+
 func (handler *Handler) updateSwarmStack(r *http.Request, stack *portainer.Stack, endpoint *portainer.Endpoint) *httperror.HandlerError {
 	// Must not be git based stack. stop the auto update job if there is any
 	if stack.AutoUpdate != nil {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/git/update"
 	httperrors "github.com/portainer/portainer/api/http/errors"
@@ -27,10 +28,20 @@ type stackGitUpdatePayload struct {
 	RepositoryUsername          string
 	RepositoryPassword          string
 	RepositoryAuthorizationType gittypes.GitCredentialAuthType
+	RepositoryGitCredentialID   int
 	TLSSkipVerify               bool
+	// Wait for the services to be healthy after a deployment and redeploy the previous version otherwise (Compose stacks
+	// only), not supported for stacks deployed with relative paths
+	HealthCheck bool
+	// Maximum time in seconds to wait for the services to be healthy, defaults to 300
+	HealthCheckTimeout int
 }
 
 func (payload *stackGitUpdatePayload) Validate(r *http.Request) error {
+	if payload.HealthCheckTimeout < 0 {
+		return errors.New("Invalid health check timeout")
+	}
+
 	return update.ValidateAutoUpdateSettings(payload.AutoUpdate)
 }
 
@@ -73,6 +84,10 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 		return httperror.InternalServerError(msg, errors.New(msg))
 	}
 
+	if payload.HealthCheck && stackutils.IsRelativePathStack(stack) {
+		return httperror.BadRequest("Invalid request payload", errHealthCheckRelativePath)
+	}
+
 	if payload.AutoUpdate != nil && payload.AutoUpdate.Webhook != "" &&
 		(stack.AutoUpdate == nil ||
 			(stack.AutoUpdate != nil && stack.AutoUpdate.Webhook != payload.AutoUpdate.Webhook)) {
@@ -150,7 +165,19 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 		stack.Option = &portainer.StackOption{Prune: payload.Prune}
 	}
 
-	if payload.RepositoryAuthentication {
+	if stack.Type == portainer.DockerComposeStack {
+		setHealthCheckOption(stack, payload.HealthCheck, payload.HealthCheckTimeout)
+	}
+
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID != 0 {
+		if httpErr := handler.validateGitCredentialAccess(stack.GitConfig, payload.RepositoryGitCredentialID, user.ID); httpErr != nil {
+			return httpErr
+		}
+
+		stack.GitConfig.Authentication = &gittypes.GitAuthentication{
+			GitCredentialID: payload.RepositoryGitCredentialID,
+		}
+	} else if payload.RepositoryAuthentication {
 		password := payload.RepositoryPassword
 
 		// When the existing stack is using the custom username/password and the password is not updated,
@@ -164,13 +191,20 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 			Password:          password,
 			AuthorizationType: payload.RepositoryAuthorizationType,
 		}
+	}
+
+	if payload.RepositoryAuthentication {
+		username, password, authType, err := git.GetCredentials(handler.GitService, stack.GitConfig.Authentication)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the Git credential", err)
+		}
 
 		if _, err := handler.GitService.LatestCommitID(
 			stack.GitConfig.URL,
 			stack.GitConfig.ReferenceName,
-			stack.GitConfig.Authentication.Username,
-			stack.GitConfig.Authentication.Password,
-			stack.GitConfig.Authentication.AuthorizationType,
+			username,
+			password,
+			authType,
 			stack.GitConfig.TLSSkipVerify,
 		); err != nil {
 			return httperror.InternalServerError("Unable to fetch git repository", err)
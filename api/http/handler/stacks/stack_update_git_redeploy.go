@@ -25,6 +25,7 @@ type stackGitRedployPayload struct {
 	RepositoryUsername          string
 	RepositoryPassword          string
 	RepositoryAuthorizationType gittypes.GitCredentialAuthType
+	RepositoryGitCredentialID   int
 	Env                         []portainer.Pair
 	Prune                       bool
 	// Force a pulling to current image with the original tag though the image is already the latest
@@ -125,6 +126,7 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		return httperror.BadRequest("Invalid request payload", err)
 	}
 
+	previousEnv := stack.Env
 	stack.GitConfig.ReferenceName = payload.RepositoryReferenceName
 	stack.Env = payload.Env
 	if stack.Type == portainer.DockerSwarmStack {
@@ -135,20 +137,33 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		stack.Name = payload.StackName
 	}
 
-	repositoryUsername := ""
-	repositoryPassword := ""
-	repositoryAuthType := gittypes.GitCredentialAuthType_Basic
+	var auth *gittypes.GitAuthentication
 	if payload.RepositoryAuthentication {
-		repositoryPassword = payload.RepositoryPassword
-		repositoryAuthType = payload.RepositoryAuthorizationType
-
-		// When the existing stack is using the custom username/password and the password is not updated,
-		// the stack should keep using the saved username/password
-		if repositoryPassword == "" && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
-			repositoryPassword = stack.GitConfig.Authentication.Password
-			repositoryAuthType = stack.GitConfig.Authentication.AuthorizationType
+		auth = &gittypes.GitAuthentication{
+			Username:          payload.RepositoryUsername,
+			Password:          payload.RepositoryPassword,
+			AuthorizationType: payload.RepositoryAuthorizationType,
+			GitCredentialID:   payload.RepositoryGitCredentialID,
+		}
+
+		// When the existing stack is using the custom username/password or a Git credential and the password
+		// is not updated, the stack should keep using the saved username/password or credential
+		if auth.Password == "" && auth.GitCredentialID == 0 && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
+			auth.Password = stack.GitConfig.Authentication.Password
+			auth.AuthorizationType = stack.GitConfig.Authentication.AuthorizationType
+			auth.GitCredentialID = stack.GitConfig.Authentication.GitCredentialID
+		}
+
+		if auth.GitCredentialID != 0 {
+			if httpErr := handler.validateGitCredentialAccess(stack.GitConfig, auth.GitCredentialID, securityContext.UserID); httpErr != nil {
+				return httpErr
+			}
 		}
-		repositoryUsername = payload.RepositoryUsername
+	}
+
+	repositoryUsername, repositoryPassword, repositoryAuthType, err := git.GetCredentials(handler.GitService, auth)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the Git credential", err)
 	}
 
 	cloneOptions := git.CloneOptions{
@@ -161,6 +176,8 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		TLSSkipVerify: stack.GitConfig.TLSSkipVerify,
 	}
 
+	handler.baselineStackRevision(stack)
+
 	clean, err := git.CloneWithBackup(handler.GitService, handler.FileService, cloneOptions)
 	if err != nil {
 		return httperror.InternalServerError("Unable to clone git repository directory", err)
@@ -172,11 +189,28 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		return err
 	}
 
+	if stack.Type == portainer.DockerComposeStack {
+		if err := handler.StackDeployer.WaitForHealthyComposeStack(stack, endpoint); err != nil {
+			if restoreErr := git.RestoreBackup(cloneOptions); restoreErr != nil {
+				return httperror.InternalServerError("Unable to restore the previous version of the stack", restoreErr)
+			}
+
+			return handler.redeployPreviousComposeStack(stack, previousEnv, func() error {
+				if err := handler.deployStack(r, stack, false, endpoint); err != nil {
+					return err
+				}
+
+				return nil
+			}, err)
+		}
+	}
+
 	newHash, err := handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, repositoryUsername, repositoryPassword, repositoryAuthType, stack.GitConfig.TLSSkipVerify)
 	if err != nil {
 		return httperror.InternalServerError("Unable get latest commit id", errors.WithMessagef(err, "failed to fetch latest commit id of the stack %v", stack.ID))
 	}
 	stack.GitConfig.ConfigHash = newHash
+	stack.DeploymentError = ""
 
 	user, err := handler.DataStore.User().Read(securityContext.UserID)
 	if err != nil {
@@ -190,6 +224,8 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		return httperror.InternalServerError("Unable to persist the stack changes inside the database", errors.Wrap(err, "failed to update the stack"))
 	}
 
+	handler.recordStackRevision(stack, user.Username, "")
+
 	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.Password != "" {
 		// Sanitize password in the http response to minimise possible security leaks
 		stack.GitConfig.Authentication.Password = ""
@@ -249,6 +285,10 @@ func (handler *Handler) deployStack(r *http.Request, stack *portainer.Stack, pul
 			Kind:      "git",
 		}
 
+		if stack.GitConfig == nil {
+			appLabel.Kind = "content"
+		}
+
 		deploymentConfiger, err = deployments.CreateKubernetesStackDeploymentConfig(stack, handler.KubernetesDeployer, appLabel, user, endpoint)
 		if err != nil {
 			return httperror.InternalServerError(err.Error(), err)
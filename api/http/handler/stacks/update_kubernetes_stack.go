@@ -7,6 +7,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/git"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/git/update"
 	"github.com/portainer/portainer/api/http/security"
@@ -32,6 +33,7 @@ type kubernetesGitStackUpdatePayload struct {
 	RepositoryUsername          string
 	RepositoryPassword          string
 	RepositoryAuthorizationType gittypes.GitCredentialAuthType
+	RepositoryGitCredentialID   int
 	AutoUpdate                  *portainer.AutoUpdateSettings
 	TLSSkipVerify               bool
 }
@@ -65,12 +67,27 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 			return httperror.BadRequest("Invalid request payload", err)
 		}
 
+		previousConfig := *stack.GitConfig
+
 		stack.GitConfig.ReferenceName = payload.RepositoryReferenceName
 		stack.GitConfig.TLSSkipVerify = payload.TLSSkipVerify
 		stack.GitConfig.Authentication = nil
 		stack.AutoUpdate = payload.AutoUpdate
 
-		if payload.RepositoryAuthentication {
+		if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID != 0 {
+			tokenData, err := security.RetrieveTokenData(r)
+			if err != nil {
+				return httperror.BadRequest("Failed to retrieve user token data", err)
+			}
+
+			if httpErr := handler.validateGitCredentialAccess(&previousConfig, payload.RepositoryGitCredentialID, tokenData.ID); httpErr != nil {
+				return httpErr
+			}
+
+			stack.GitConfig.Authentication = &gittypes.GitAuthentication{
+				GitCredentialID: payload.RepositoryGitCredentialID,
+			}
+		} else if payload.RepositoryAuthentication {
 			password := payload.RepositoryPassword
 			if password == "" && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
 				password = stack.GitConfig.Authentication.Password
@@ -81,13 +98,20 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 				Password:          password,
 				AuthorizationType: payload.RepositoryAuthorizationType,
 			}
+		}
+
+		if payload.RepositoryAuthentication {
+			username, password, authType, err := git.GetCredentials(handler.GitService, stack.GitConfig.Authentication)
+			if err != nil {
+				return httperror.InternalServerError("Unable to retrieve the Git credential", err)
+			}
 
 			if _, err := handler.GitService.LatestCommitID(
 				stack.GitConfig.URL,
 				stack.GitConfig.ReferenceName,
-				stack.GitConfig.Authentication.Username,
-				stack.GitConfig.Authentication.Password,
-				stack.GitConfig.Authentication.AuthorizationType,
+				username,
+				password,
+				authType,
 				stack.GitConfig.TLSSkipVerify,
 			); err != nil {
 				return httperror.InternalServerError("Unable to fetch git repository", err)
@@ -6,6 +6,7 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/http/security"
+	operations "github.com/portainer/portainer/api/templates"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 
 	"github.com/gorilla/mux"
@@ -17,6 +18,8 @@ type Handler struct {
 	DataStore   dataservices.DataStore
 	GitService  portainer.GitService
 	FileService portainer.FileService
+	// TemplateService loads and caches the templates of the template sources
+	TemplateService *operations.Service
 }
 
 // NewHandler returns a new instance of Handler.
@@ -29,5 +32,14 @@ func NewHandler(bouncer security.BouncerService) *Handler {
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.templateList))).Methods(http.MethodGet)
 	h.Handle("/templates/{id}/file",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.templateFile))).Methods(http.MethodPost)
+	h.Handle("/templates/sources",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateSourceList))).Methods(http.MethodGet)
+	h.Handle("/templates/sources",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateSourceCreate))).Methods(http.MethodPost)
+	h.Handle("/templates/sources/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateSourceUpdate))).Methods(http.MethodPut)
+	h.Handle("/templates/sources/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.templateSourceDelete))).Methods(http.MethodDelete)
+
 	return h
 }
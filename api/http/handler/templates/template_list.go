@@ -9,7 +9,9 @@ import (
 
 // @id TemplateList
 // @summary List available templates
-// @description List available templates.
+// @description List the templates of all the template sources, merged. The identifiers of the templates of a source
+// @description are in the range source identifier * 100000 + 1 to source identifier * 100000 + 99999.
+// @description A source that cannot be reached is served from its last successful fetch.
 // @description **Access policy**: authenticated
 // @tags templates
 // @security ApiKeyAuth
@@ -19,7 +21,7 @@ import (
 // @failure 500 "Server error"
 // @router /templates [get]
 func (handler *Handler) templateList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
-	templates, httpErr := handler.fetchTemplates()
+	templates, httpErr := handler.fetchTemplates(r)
 	if httpErr != nil {
 		return httpErr
 	}
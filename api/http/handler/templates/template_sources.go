@@ -0,0 +1,300 @@
+package templates
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	portainer "github.com/portainer/portainer/api"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/http/security"
+	operations "github.com/portainer/portainer/api/templates"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+type templateSourceResponse struct {
+	portainer.TemplateSource
+	// Status of the last fetches of the source
+	Status operations.SourceStatus `json:"Status"`
+}
+
+type templateSourcePayload struct {
+	// Name of the source, displayed with its templates
+	Name string `example:"internal" validate:"required"`
+	// Type of the source. Valid values are: url, file or git
+	Type portainer.TemplateSourceType `example:"url" enums:"url,file,git" validate:"required"`
+	// URL of the templates file, for url sources
+	URL string `example:"https://templates.example.com/templates.json"`
+	// Path of the templates file on the Portainer host, for file sources
+	Path string `example:"/data/templates.json"`
+	// Repository holding the templates file, for git sources. ConfigFilePath is the path of the file inside the repository
+	Git *gittypes.RepoConfig
+	// Headers sent with the requests of url sources. On update, a header without value keeps its current value
+	Headers []portainer.Pair
+	// Number of seconds the templates are served from the cache before being revalidated, 3600 when 0
+	CacheTTL int `example:"3600"`
+}
+
+func (payload *templateSourcePayload) Validate(r *http.Request) error {
+	if payload.Name == operations.DefaultSourceName {
+		return errors.New("Invalid name. The name is reserved for the templates URL of the settings")
+	}
+
+	return operations.ValidateSource(payload.source())
+}
+
+func (payload *templateSourcePayload) source() *portainer.TemplateSource {
+	return &portainer.TemplateSource{
+		Name:     payload.Name,
+		Type:     payload.Type,
+		URL:      payload.URL,
+		Path:     payload.Path,
+		Git:      payload.Git,
+		Headers:  payload.Headers,
+		CacheTTL: payload.CacheTTL,
+	}
+}
+
+// @id TemplateSourceList
+// @summary List the template sources
+// @description List the template sources with the status of their last fetches. The first source, with the identifier 0,
+// @description is the templates URL of the settings. The header values and the git passwords are not returned.
+// @description **Access policy**: administrator
+// @tags templates
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} templateSourceResponse "Success"
+// @failure 500 "Server error"
+// @router /templates/sources [get]
+func (handler *Handler) templateSourceList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	sources, err := handler.TemplateService.Sources()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the template sources from the database", err)
+	}
+
+	resp := make([]templateSourceResponse, 0, len(sources))
+	for _, source := range sources {
+		hideSourceSecrets(&source)
+
+		resp = append(resp, templateSourceResponse{
+			TemplateSource: source,
+			Status:         handler.TemplateService.Status(source.ID),
+		})
+	}
+
+	return response.JSON(w, resp)
+}
+
+// @id TemplateSourceCreate
+// @summary Create a template source
+// @description Add a location the application templates are loaded from.
+// @description **Access policy**: administrator
+// @tags templates
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body templateSourcePayload true "Template source details"
+// @success 200 {object} portainer.TemplateSource "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied to use the Git credential"
+// @failure 409 "A template source with the same name already exists"
+// @failure 500 "Server error"
+// @router /templates/sources [post]
+func (handler *Handler) templateSourceCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload templateSourcePayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	source := payload.source()
+
+	if httpErr := handler.checkUniqueSourceName(source); httpErr != nil {
+		return httpErr
+	}
+
+	if httpErr := handler.validateGitCredentialAccess(r, source, nil); httpErr != nil {
+		return httpErr
+	}
+
+	if err := handler.DataStore.TemplateSource().Create(source); err != nil {
+		return httperror.InternalServerError("Unable to persist the template source inside the database", err)
+	}
+
+	hideSourceSecrets(source)
+
+	return response.JSON(w, source)
+}
+
+// @id TemplateSourceUpdate
+// @summary Update a template source
+// @description Update a template source, its cached templates are discarded.
+// @description **Access policy**: administrator
+// @tags templates
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Template source identifier"
+// @param body body templateSourcePayload true "Template source details"
+// @success 200 {object} portainer.TemplateSource "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied to use the Git credential"
+// @failure 404 "Template source not found"
+// @failure 409 "A template source with the same name already exists"
+// @failure 500 "Server error"
+// @router /templates/sources/{id} [put]
+func (handler *Handler) templateSourceUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	id, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid template source identifier route variable", err)
+	}
+
+	var payload templateSourcePayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	current, err := handler.DataStore.TemplateSource().Read(portainer.TemplateSourceID(id))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a template source with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a template source with the specified identifier inside the database", err)
+	}
+
+	source := payload.source()
+	source.ID = current.ID
+
+	if httpErr := handler.checkUniqueSourceName(source); httpErr != nil {
+		return httpErr
+	}
+
+	if httpErr := handler.validateGitCredentialAccess(r, source, current); httpErr != nil {
+		return httpErr
+	}
+
+	keepSourceSecrets(source, current)
+
+	if err := handler.DataStore.TemplateSource().Update(source.ID, source); err != nil {
+		return httperror.InternalServerError("Unable to persist the template source inside the database", err)
+	}
+
+	handler.TemplateService.Invalidate(source.ID)
+
+	hideSourceSecrets(source)
+
+	return response.JSON(w, source)
+}
+
+// @id TemplateSourceDelete
+// @summary Delete a template source
+// @description **Access policy**: administrator
+// @tags templates
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Template source identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Template source not found"
+// @failure 500 "Server error"
+// @router /templates/sources/{id} [delete]
+func (handler *Handler) templateSourceDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	id, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid template source identifier route variable", err)
+	}
+
+	sourceID := portainer.TemplateSourceID(id)
+
+	if exists, err := handler.DataStore.TemplateSource().Exists(sourceID); err != nil {
+		return httperror.InternalServerError("Unable to find a template source with the specified identifier inside the database", err)
+	} else if !exists {
+		return httperror.NotFound("Unable to find a template source with the specified identifier inside the database", nil)
+	}
+
+	if err := handler.DataStore.TemplateSource().Delete(sourceID); err != nil {
+		return httperror.InternalServerError("Unable to remove the template source from the database", err)
+	}
+
+	handler.TemplateService.Invalidate(sourceID)
+
+	return response.Empty(w)
+}
+
+func (handler *Handler) checkUniqueSourceName(source *portainer.TemplateSource) *httperror.HandlerError {
+	sources, err := handler.DataStore.TemplateSource().ReadAll()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the template sources from the database", err)
+	}
+
+	if slices.ContainsFunc(sources, func(s portainer.TemplateSource) bool {
+		return s.Name == source.Name && s.ID != source.ID
+	}) {
+		return httperror.Conflict("A template source with the same name already exists", errors.New("duplicate template source name"))
+	}
+
+	return nil
+}
+
+// validateGitCredentialAccess checks that the user can reference the Git credential of the source, a credential
+// already referenced by the current source can be kept
+func (handler *Handler) validateGitCredentialAccess(r *http.Request, source, current *portainer.TemplateSource) *httperror.HandlerError {
+	if source.Git == nil || source.Git.Authentication == nil || source.Git.Authentication.GitCredentialID == 0 {
+		return nil
+	}
+
+	credentialID := source.Git.Authentication.GitCredentialID
+	if current != nil && current.Git != nil && current.Git.Authentication != nil && current.Git.Authentication.GitCredentialID == credentialID {
+		return nil
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if err := gitcredentials.ValidateAccess(handler.DataStore, credentialID, tokenData.ID); err != nil {
+		return httperror.Forbidden("Permission denied to use the Git credential", err)
+	}
+
+	return nil
+}
+
+func hideSourceSecrets(source *portainer.TemplateSource) {
+	headers := make([]portainer.Pair, 0, len(source.Headers))
+	for _, header := range source.Headers {
+		headers = append(headers, portainer.Pair{Name: header.Name})
+	}
+	source.Headers = headers
+
+	if source.Git != nil && source.Git.Authentication != nil {
+		auth := *source.Git.Authentication
+		auth.Password = ""
+
+		git := *source.Git
+		git.Authentication = &auth
+		source.Git = &git
+	}
+}
+
+// keepSourceSecrets restores the secrets the update payload omits, as they are never returned by the API
+func keepSourceSecrets(source, current *portainer.TemplateSource) {
+	for i, header := range source.Headers {
+		if header.Value != "" {
+			continue
+		}
+
+		if idx := slices.IndexFunc(current.Headers, func(h portainer.Pair) bool { return h.Name == header.Name }); idx != -1 {
+			source.Headers[i].Value = current.Headers[idx].Value
+		}
+	}
+
+	if source.Git != nil && source.Git.Authentication != nil && source.Git.Authentication.Password == "" &&
+		current.Git != nil && current.Git.Authentication != nil && current.Git.Authentication.Username == source.Git.Authentication.Username {
+		source.Git.Authentication.Password = current.Git.Authentication.Password
+	}
+}
@@ -7,6 +7,7 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/apikey"
 	"github.com/portainer/portainer/api/dataservices"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
 	"github.com/portainer/portainer/api/http/security"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 
@@ -23,6 +24,7 @@ var (
 
 func hideFields(user *portainer.User) {
 	user.Password = ""
+	user.TOTP = portainer.UserTOTP{Enabled: user.TOTP.Enabled}
 }
 
 // Handler is the HTTP handler used to handle user operations.
@@ -35,6 +37,7 @@ type Handler struct {
 	passwordStrengthChecker security.PasswordStrengthChecker
 	AdminCreationDone       chan<- struct{}
 	FileService             portainer.FileService
+	GitCredentialService    *gitcredentials.Service
 }
 
 // NewHandler creates a handler to manage user operations.
@@ -73,6 +76,10 @@ func NewHandler(bouncer security.BouncerService, rateLimiter *security.RateLimit
 	restrictedRouter.Handle("/users/{id}/tokens/{keyID}", httperror.LoggerHandler(h.userRemoveAccessToken)).Methods(http.MethodDelete)
 	restrictedRouter.Handle("/users/{id}/memberships", httperror.LoggerHandler(h.userMemberships)).Methods(http.MethodGet)
 	authenticatedRouter.Handle("/users/{id}/passwd", rateLimiter.LimitAccess(httperror.LoggerHandler(h.userUpdatePassword))).Methods(http.MethodPut)
+	authenticatedRouter.Handle("/users/{id}/totp/setup", httperror.LoggerHandler(h.userTOTPSetup)).Methods(http.MethodPost)
+	authenticatedRouter.Handle("/users/{id}/totp/enable", rateLimiter.LimitAccess(httperror.LoggerHandler(h.userTOTPEnable))).Methods(http.MethodPost)
+	authenticatedRouter.Handle("/users/{id}/totp/recovery_codes", rateLimiter.LimitAccess(httperror.LoggerHandler(h.userTOTPRecoveryCodes))).Methods(http.MethodPost)
+	authenticatedRouter.Handle("/users/{id}/totp/disable", rateLimiter.LimitAccess(httperror.LoggerHandler(h.userTOTPDisable))).Methods(http.MethodPost)
 
 	publicRouter.Handle("/users/admin/check", httperror.LoggerHandler(h.adminCheck)).Methods(http.MethodGet)
 	publicRouter.Handle("/users/admin/init", httperror.LoggerHandler(h.adminInit)).Methods(http.MethodPost)
@@ -82,5 +89,12 @@ func NewHandler(bouncer security.BouncerService, rateLimiter *security.RateLimit
 	authenticatedRouter.Handle("/users/{id}/helm/repositories", httperror.LoggerHandler(h.userCreateHelmRepo)).Methods(http.MethodPost)
 	authenticatedRouter.Handle("/users/{id}/helm/repositories/{repositoryID}", httperror.LoggerHandler(h.userDeleteHelmRepo)).Methods(http.MethodDelete)
 
+	// Git credentials
+	authenticatedRouter.Handle("/users/{id}/gitcredentials", httperror.LoggerHandler(h.userGetGitCredentials)).Methods(http.MethodGet)
+	authenticatedRouter.Handle("/users/{id}/gitcredentials", httperror.LoggerHandler(h.userCreateGitCredential)).Methods(http.MethodPost)
+	authenticatedRouter.Handle("/users/{id}/gitcredentials/{credentialID}", httperror.LoggerHandler(h.userInspectGitCredential)).Methods(http.MethodGet)
+	authenticatedRouter.Handle("/users/{id}/gitcredentials/{credentialID}", httperror.LoggerHandler(h.userUpdateGitCredential)).Methods(http.MethodPut)
+	authenticatedRouter.Handle("/users/{id}/gitcredentials/{credentialID}", httperror.LoggerHandler(h.userDeleteGitCredential)).Methods(http.MethodDelete)
+
 	return h
 }
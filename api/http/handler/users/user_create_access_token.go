@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	portainer "github.com/portainer/portainer/api"
 	httperrors "github.com/portainer/portainer/api/http/errors"
@@ -17,6 +18,10 @@ import (
 type userAccessTokenCreatePayload struct {
 	Password    string `validate:"required" example:"password" json:"password"`
 	Description string `validate:"required" example:"github-api-key" json:"description"`
+	// Unix timestamp (UTC) after which the API key is rejected, 0 or omitted for a key that never expires
+	ExpiresAt int64 `example:"1735689600" json:"expiresAt"`
+	// Restrictions applied to the requests authenticated with the API key, omitted for a key with the full access of the user
+	Scope portainer.APIKeyScope `json:"scope"`
 }
 
 func (payload *userAccessTokenCreatePayload) Validate(r *http.Request) error {
@@ -29,6 +34,14 @@ func (payload *userAccessTokenCreatePayload) Validate(r *http.Request) error {
 	if validate.MinStringLength(payload.Description, 128) {
 		return errors.New("invalid description: cannot be longer than 128 characters")
 	}
+	if payload.ExpiresAt != 0 && payload.ExpiresAt <= time.Now().Unix() {
+		return errors.New("invalid expiry date: must be in the future")
+	}
+	for _, operation := range payload.Scope.Operations {
+		if !security.IsValidAPIKeyOperation(operation) {
+			return fmt.Errorf("invalid scope: unknown operation %q", operation)
+		}
+	}
 	return nil
 }
 
@@ -40,6 +53,8 @@ type accessTokenResponse struct {
 // @id UserGenerateAPIKey
 // @summary Generate an API key for a user
 // @description Generates an API key for a user.
+// @description The key can expire and be restricted to read operations, to some environments or to some families of operations
+// @description (webhooks, stack-redeploy, docker, kubernetes).
 // @description Only the calling user can generate a token for themselves.
 // @description Password is required only for internal authentication.
 // @description **Access policy**: restricted
@@ -109,7 +124,15 @@ func (handler *Handler) userCreateAccessToken(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	rawAPIKey, apiKey, err := handler.apiKeyService.GenerateApiKey(*user, payload.Description)
+	for _, endpointID := range payload.Scope.EndpointIDs {
+		if _, err := handler.DataStore.Endpoint().Endpoint(endpointID); handler.DataStore.IsErrObjectNotFound(err) {
+			return httperror.BadRequest("Invalid request payload", fmt.Errorf("invalid scope: environment %d does not exist", endpointID))
+		} else if err != nil {
+			return httperror.InternalServerError("Unable to find an environment with the specified identifier inside the database", err)
+		}
+	}
+
+	rawAPIKey, apiKey, err := handler.apiKeyService.GenerateScopedApiKey(*user, payload.Description, payload.ExpiresAt, payload.Scope)
 	if err != nil {
 		return httperror.InternalServerError("Internal Server Error", err)
 	}
@@ -73,6 +73,56 @@ func Test_userCreateAccessToken(t *testing.T) {
 		is.NotEmpty(resp.RawAPIKey)
 	})
 
+	t.Run("standard user generates an expiring and scoped API key", func(t *testing.T) {
+		err := store.Endpoint().Create(&portainer.Endpoint{ID: 1, Name: "env-1"})
+		require.NoError(t, err)
+
+		data := userAccessTokenCreatePayload{
+			Password:    "password",
+			Description: "test-token-scoped",
+			ExpiresAt:   time.Now().Add(time.Hour).Unix(),
+			Scope: portainer.APIKeyScope{
+				ReadOnly:    true,
+				EndpointIDs: []portainer.EndpointID{1},
+				Operations:  []portainer.APIKeyOperation{portainer.APIKeyOperationDocker},
+			},
+		}
+		payload, err := json.Marshal(data)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/users/2/tokens", bytes.NewBuffer(payload))
+		testhelpers.AddTestSecurityCookie(req, jwt)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusOK, rr.Code)
+
+		var resp accessTokenResponse
+		err = json.NewDecoder(rr.Body).Decode(&resp)
+		require.NoError(t, err, "response should be json")
+		is.Equal(data.ExpiresAt, resp.APIKey.ExpiresAt)
+		is.Equal(data.Scope, resp.APIKey.Scope)
+	})
+
+	t.Run("standard user cannot scope an API key to an unknown environment", func(t *testing.T) {
+		data := userAccessTokenCreatePayload{
+			Password:    "password",
+			Description: "test-token-unknown-env",
+			Scope:       portainer.APIKeyScope{EndpointIDs: []portainer.EndpointID{42}},
+		}
+		payload, err := json.Marshal(data)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/users/2/tokens", bytes.NewBuffer(payload))
+		testhelpers.AddTestSecurityCookie(req, jwt)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusBadRequest, rr.Code)
+	})
+
 	t.Run("admin cannot generate API key for standard user", func(t *testing.T) {
 		data := userAccessTokenCreatePayload{Password: "password", Description: "test-token-admin"}
 		payload, err := json.Marshal(data)
@@ -144,6 +194,22 @@ this string is longer than 128 characters and hence this will fail.
 `},
 			shouldFail: true,
 		},
+		{
+			payload:    userAccessTokenCreatePayload{Password: "password", Description: "test-token", ExpiresAt: time.Now().Add(time.Hour).Unix()},
+			shouldFail: false,
+		},
+		{
+			payload:    userAccessTokenCreatePayload{Password: "password", Description: "test-token", ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+			shouldFail: true,
+		},
+		{
+			payload:    userAccessTokenCreatePayload{Password: "password", Description: "test-token", Scope: portainer.APIKeyScope{Operations: []portainer.APIKeyOperation{portainer.APIKeyOperationWebhooks}}},
+			shouldFail: false,
+		},
+		{
+			payload:    userAccessTokenCreatePayload{Password: "password", Description: "test-token", Scope: portainer.APIKeyScope{Operations: []portainer.APIKeyOperation{"unknown"}}},
+			shouldFail: true,
+		},
 	}
 
 	for _, test := range tests {
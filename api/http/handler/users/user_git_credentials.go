@@ -0,0 +1,342 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/git"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+type gitCredentialPayload struct {
+	// Name of the credential
+	Name string `validate:"required" example:"github-deploy"`
+	// Authorization type (0 - basic, 1 - token, 2 - SSH)
+	AuthorizationType gittypes.GitCredentialAuthType `example:"0"`
+	// Username used with basic authentication, or SSH user (defaults to git)
+	Username string `example:"myGitUsername"`
+	// Password or token, required on creation for basic and token authentication
+	Password string `example:"myGitPassword"`
+	// PEM encoded SSH private key, required on creation for SSH authentication
+	SSHPrivateKey string
+	// Passphrase of the SSH private key
+	SSHPassphrase string
+	// Host keys the SSH server must present, in the known_hosts format, required for SSH authentication
+	SSHKnownHosts string
+}
+
+func (payload *gitCredentialPayload) Validate(r *http.Request) error {
+	if payload.Name == "" {
+		return errors.New("invalid credential name")
+	}
+
+	switch payload.AuthorizationType {
+	case gittypes.GitCredentialAuthType_Basic:
+		if payload.Username == "" {
+			return errors.New("invalid username")
+		}
+	case gittypes.GitCredentialAuthType_Token, gittypes.GitCredentialAuthType_SSH:
+	default:
+		return errors.New("invalid authorization type, must be one of 0 (basic), 1 (token) or 2 (SSH)")
+	}
+
+	return nil
+}
+
+// apply copies the payload to the credential, an empty secret keeps the current one as long as the
+// authorization type is unchanged
+func (payload *gitCredentialPayload) apply(credential *portainer.GitCredential) error {
+	if payload.AuthorizationType != credential.AuthorizationType {
+		credential.Password = ""
+		credential.SSHPrivateKey = ""
+		credential.SSHPassphrase = ""
+	}
+
+	credential.Name = payload.Name
+	credential.AuthorizationType = payload.AuthorizationType
+	credential.Username = payload.Username
+
+	if payload.AuthorizationType != gittypes.GitCredentialAuthType_SSH {
+		if payload.Password != "" {
+			credential.Password = payload.Password
+		}
+
+		credential.SSHPrivateKey = ""
+		credential.SSHPassphrase = ""
+		credential.SSHKnownHosts = ""
+
+		if credential.Password == "" {
+			return errors.New("invalid password or token")
+		}
+
+		return nil
+	}
+
+	if payload.SSHPrivateKey != "" {
+		credential.SSHPrivateKey = payload.SSHPrivateKey
+		credential.SSHPassphrase = payload.SSHPassphrase
+	}
+
+	credential.Password = ""
+	credential.SSHKnownHosts = payload.SSHKnownHosts
+
+	return git.ValidateSSHCredential(gittypes.SSHCredential{
+		PrivateKey: credential.SSHPrivateKey,
+		Passphrase: credential.SSHPassphrase,
+		KnownHosts: credential.SSHKnownHosts,
+	})
+}
+
+// hideGitCredentialSecrets removes the password and the SSH key material from a credential returned by the API
+func hideGitCredentialSecrets(credential *portainer.GitCredential) {
+	credential.Password = ""
+	credential.SSHPrivateKey = ""
+	credential.SSHPassphrase = ""
+}
+
+// @id UserGitCredentialCreate
+// @summary Create a Git credential
+// @description Create a Git credential owned by the user, the password, token and SSH key are encrypted at rest
+// @description and never returned by the API. Stacks, Edge stacks and custom templates reference it through its identifier.
+// @description Only the calling user can create their own Git credentials.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "User identifier"
+// @param body body gitCredentialPayload true "Git credential details"
+// @success 200 {object} portainer.GitCredential "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials [post]
+func (handler *Handler) userCreateGitCredential(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, httpErr := retrieveGitCredentialOwner(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	var payload gitCredentialPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	credential := &portainer.GitCredential{
+		UserID:       userID,
+		CreationDate: time.Now().Unix(),
+	}
+
+	if err := payload.apply(credential); err != nil {
+		return httperror.BadRequest("Invalid Git credential", err)
+	}
+
+	if err := handler.GitCredentialService.Create(credential); err != nil {
+		return httperror.InternalServerError("Unable to persist the Git credential inside the database", err)
+	}
+
+	hideGitCredentialSecrets(credential)
+
+	return response.JSON(w, credential)
+}
+
+// @id UserGitCredentialList
+// @summary List the Git credentials of a user
+// @description List the Git credentials owned by the user, secrets are not returned.
+// @description Only the calling user can list their own Git credentials.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "User identifier"
+// @success 200 {array} portainer.GitCredential "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials [get]
+func (handler *Handler) userGetGitCredentials(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, httpErr := retrieveGitCredentialOwner(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	credentials, err := handler.DataStore.GitCredential().GitCredentialsByUserID(userID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the Git credentials from the database", err)
+	}
+
+	for i := range credentials {
+		hideGitCredentialSecrets(&credentials[i])
+	}
+
+	return response.JSON(w, credentials)
+}
+
+// @id UserGitCredentialInspect
+// @summary Inspect a Git credential
+// @description Retrieve details about a Git credential of the user, secrets are not returned.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "User identifier"
+// @param credentialID path int true "Git credential identifier"
+// @success 200 {object} portainer.GitCredential "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Git credential not found"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials/{credentialID} [get]
+func (handler *Handler) userInspectGitCredential(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	credential, httpErr := handler.readGitCredential(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	hideGitCredentialSecrets(credential)
+
+	return response.JSON(w, credential)
+}
+
+// @id UserGitCredentialUpdate
+// @summary Update a Git credential
+// @description Update a Git credential of the user, the stacks referencing it use the new secret on their next deployment.
+// @description An empty password or SSH private key keeps the current one.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "User identifier"
+// @param credentialID path int true "Git credential identifier"
+// @param body body gitCredentialPayload true "Git credential details"
+// @success 200 {object} portainer.GitCredential "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Git credential not found"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials/{credentialID} [put]
+func (handler *Handler) userUpdateGitCredential(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	credential, httpErr := handler.readGitCredential(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	var payload gitCredentialPayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	if err := payload.apply(credential); err != nil {
+		return httperror.BadRequest("Invalid Git credential", err)
+	}
+
+	if err := handler.GitCredentialService.Update(credential); err != nil {
+		return httperror.InternalServerError("Unable to persist the Git credential changes inside the database", err)
+	}
+
+	hideGitCredentialSecrets(credential)
+
+	return response.JSON(w, credential)
+}
+
+// @id UserGitCredentialDelete
+// @summary Remove a Git credential
+// @description Remove a Git credential of the user, it cannot be removed while a stack, a custom template or a template source references it.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "User identifier"
+// @param credentialID path int true "Git credential identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Git credential not found"
+// @failure 409 "Git credential in use"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials/{credentialID} [delete]
+func (handler *Handler) userDeleteGitCredential(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	credential, httpErr := handler.readGitCredential(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if err := handler.DataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		inUse, err := gitcredentials.IsInUse(tx, credential.ID)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the Git configurations from the database", err)
+		} else if inUse {
+			return httperror.Conflict("Unable to remove the Git credential", gitcredentials.ErrCredentialInUse)
+		}
+
+		if err := tx.GitCredential().Delete(credential.ID); err != nil {
+			return httperror.InternalServerError("Unable to remove the Git credential from the database", err)
+		}
+
+		return nil
+	}); err != nil {
+		return response.TxErrorResponse(err)
+	}
+
+	return response.Empty(w)
+}
+
+// retrieveGitCredentialOwner returns the user of the route, who must be the calling user
+func retrieveGitCredentialOwner(r *http.Request) (portainer.UserID, *httperror.HandlerError) {
+	id, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return 0, httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return 0, httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	userID := portainer.UserID(id)
+	if tokenData.ID != userID {
+		return 0, httperror.Forbidden("Permission denied to access the Git credentials of another user", httperrors.ErrUnauthorized)
+	}
+
+	return userID, nil
+}
+
+// readGitCredential returns the decrypted credential of the route, it must belong to the calling user
+func (handler *Handler) readGitCredential(r *http.Request) (*portainer.GitCredential, *httperror.HandlerError) {
+	userID, httpErr := retrieveGitCredentialOwner(r)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	credentialID, err := request.RetrieveNumericRouteVariableValue(r, "credentialID")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid Git credential identifier route variable", err)
+	}
+
+	credential, err := handler.GitCredentialService.Read(portainer.GitCredentialID(credentialID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find a Git credential with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a Git credential with the specified identifier inside the database", err)
+	}
+
+	if credential.UserID != userID {
+		return nil, httperror.Forbidden("Permission denied to access the Git credential", httperrors.ErrUnauthorized)
+	}
+
+	return credential, nil
+}
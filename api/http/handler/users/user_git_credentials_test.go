@@ -0,0 +1,145 @@
+package users
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/apikey"
+	"github.com/portainer/portainer/api/datastore"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	"github.com/portainer/portainer/api/jwt"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_userGitCredentials(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	user := &portainer.User{Username: "standard", Role: portainer.StandardUserRole}
+	require.NoError(t, store.User().Create(user))
+
+	otherUser := &portainer.User{Username: "other", Role: portainer.StandardUserRole}
+	require.NoError(t, store.User().Create(otherUser))
+
+	jwtService, err := jwt.NewService("1h", store)
+	require.NoError(t, err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	passwordChecker := security.NewPasswordStrengthChecker(store.SettingsService)
+
+	gitCredentialService, err := gitcredentials.NewService(store, []byte("private key"))
+	require.NoError(t, err)
+
+	h := NewHandler(requestBouncer, rateLimiter, apiKeyService, passwordChecker)
+	h.DataStore = store
+	h.GitCredentialService = gitCredentialService
+
+	userJWT, _, _ := jwtService.GenerateToken(&portainer.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+	otherJWT, _, _ := jwtService.GenerateToken(&portainer.TokenData{ID: otherUser.ID, Username: otherUser.Username, Role: otherUser.Role})
+
+	do := func(method, url, token string, payload any) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if payload != nil {
+			require.NoError(t, json.NewEncoder(&body).Encode(payload))
+		}
+
+		req := httptest.NewRequest(method, url, &body)
+		testhelpers.AddTestSecurityCookie(req, token)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	var created portainer.GitCredential
+
+	t.Run("user creates a credential and its secret is not returned", func(t *testing.T) {
+		rr := do(http.MethodPost, fmt.Sprintf("/users/%d/gitcredentials", user.ID), userJWT, gitCredentialPayload{
+			Name:              "github",
+			AuthorizationType: gittypes.GitCredentialAuthType_Token,
+			Password:          "ghp_token",
+		})
+		is.Equal(http.StatusOK, rr.Code, rr.Body.String())
+
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+		is.Equal(user.ID, created.UserID)
+		is.Empty(created.Password)
+
+		stored, err := gitCredentialService.Read(created.ID)
+		require.NoError(t, err)
+		is.Equal("ghp_token", stored.Password)
+	})
+
+	t.Run("credential without password is rejected", func(t *testing.T) {
+		rr := do(http.MethodPost, fmt.Sprintf("/users/%d/gitcredentials", user.ID), userJWT, gitCredentialPayload{
+			Name:              "github",
+			AuthorizationType: gittypes.GitCredentialAuthType_Basic,
+			Username:          "user",
+		})
+		is.Equal(http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("update with an empty password keeps the current one", func(t *testing.T) {
+		rr := do(http.MethodPut, fmt.Sprintf("/users/%d/gitcredentials/%d", user.ID, created.ID), userJWT, gitCredentialPayload{
+			Name:              "github-renamed",
+			AuthorizationType: gittypes.GitCredentialAuthType_Token,
+		})
+		is.Equal(http.StatusOK, rr.Code, rr.Body.String())
+
+		stored, err := gitCredentialService.Read(created.ID)
+		require.NoError(t, err)
+		is.Equal("github-renamed", stored.Name)
+		is.Equal("ghp_token", stored.Password)
+	})
+
+	t.Run("other user cannot access the credentials", func(t *testing.T) {
+		rr := do(http.MethodGet, fmt.Sprintf("/users/%d/gitcredentials", user.ID), otherJWT, nil)
+		is.Equal(http.StatusForbidden, rr.Code)
+
+		rr = do(http.MethodGet, fmt.Sprintf("/users/%d/gitcredentials/%d", otherUser.ID, created.ID), otherJWT, nil)
+		is.Equal(http.StatusForbidden, rr.Code)
+
+		rr = do(http.MethodGet, fmt.Sprintf("/users/%d/gitcredentials", otherUser.ID), otherJWT, nil)
+		is.Equal(http.StatusOK, rr.Code)
+
+		var credentials []portainer.GitCredential
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&credentials))
+		is.Empty(credentials)
+	})
+
+	t.Run("credential used by a stack cannot be deleted", func(t *testing.T) {
+		stack := &portainer.Stack{
+			ID:        1,
+			Name:      "stack",
+			GitConfig: &gittypes.RepoConfig{Authentication: &gittypes.GitAuthentication{GitCredentialID: int(created.ID)}},
+		}
+		require.NoError(t, store.Stack().Create(stack))
+
+		rr := do(http.MethodDelete, fmt.Sprintf("/users/%d/gitcredentials/%d", user.ID, created.ID), userJWT, nil)
+		is.Equal(http.StatusConflict, rr.Code)
+
+		require.NoError(t, store.Stack().Delete(stack.ID))
+	})
+
+	t.Run("user deletes the credential", func(t *testing.T) {
+		rr := do(http.MethodDelete, fmt.Sprintf("/users/%d/gitcredentials/%d", user.ID, created.ID), userJWT, nil)
+		is.Equal(http.StatusNoContent, rr.Code)
+
+		rr = do(http.MethodGet, fmt.Sprintf("/users/%d/gitcredentials/%d", user.ID, created.ID), userJWT, nil)
+		is.Equal(http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,322 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/totp"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+	"github.com/portainer/portainer/pkg/libhttp/request"
+	"github.com/portainer/portainer/pkg/libhttp/response"
+)
+
+type userTOTPSetupResponse struct {
+	// Base32 encoded secret to type in the authenticator application
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	// otpauth URI of the secret, to display as a QR code
+	ProvisioningURI string `json:"provisioningUri" example:"otpauth://totp/Portainer:bob?issuer=Portainer&secret=JBSWY3DPEHPK3PXP"`
+}
+
+type userTOTPCodePayload struct {
+	// Code displayed by the authenticator application
+	Code string `validate:"required" example:"123456"`
+}
+
+func (payload *userTOTPCodePayload) Validate(r *http.Request) error {
+	if len(payload.Code) == 0 {
+		return errors.New("invalid code: cannot be empty")
+	}
+
+	return nil
+}
+
+type userTOTPDisablePayload struct {
+	// Current password of the user, not required when an administrator disables the second factor of another user
+	Password string `example:"password"`
+}
+
+func (payload *userTOTPDisablePayload) Validate(r *http.Request) error {
+	return nil
+}
+
+type userTOTPRecoveryCodesResponse struct {
+	// Single-use recovery codes, they are not displayed again
+	RecoveryCodes []string `json:"recoveryCodes" example:"abcde-fghjk"`
+}
+
+// @id UserTOTPSetup
+// @summary Generate a second factor for a user
+// @description Generates the secret of the authenticator application of the user. The second factor is only enabled
+// @description once a code is provided to /users/{id}/totp/enable.
+// @description Only the calling user can enroll a second factor, and only with the internal authentication.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "User identifier"
+// @success 200 {object} userTOTPSetupResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 409 "Second factor already enabled"
+// @failure 500 "Server error"
+// @router /users/{id}/totp/setup [post]
+func (handler *Handler) userTOTPSetup(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	user, httpErr := handler.readTOTPUser(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	internalAuth, err := handler.usesInternalAuthentication(user.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to determine the authentication method", err)
+	} else if !internalAuth {
+		return httperror.BadRequest("Second factor is only available with the internal authentication", errors.New("internal authentication is disabled"))
+	}
+
+	if user.TOTP.Enabled {
+		return httperror.Conflict("A second factor is already enabled for this user", errors.New("second factor already enabled"))
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return httperror.InternalServerError("Unable to generate the second factor", err)
+	}
+
+	user.TOTP.PendingSecret = secret
+
+	if err := handler.DataStore.User().Update(user.ID, user); err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	return response.JSON(w, &userTOTPSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(user.Username, secret),
+	})
+}
+
+// @id UserTOTPEnable
+// @summary Enable the second factor of a user
+// @description Enables the second factor generated by /users/{id}/totp/setup once the code proves the authenticator
+// @description application was configured, and returns the recovery codes.
+// @description Only the calling user can enable their second factor.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "User identifier"
+// @param body body userTOTPCodePayload true "Code of the authenticator application"
+// @success 200 {object} userTOTPRecoveryCodesResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 422 "Invalid code"
+// @failure 500 "Server error"
+// @router /users/{id}/totp/enable [post]
+func (handler *Handler) userTOTPEnable(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload userTOTPCodePayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	user, httpErr := handler.readTOTPUser(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if user.TOTP.Enabled {
+		return httperror.Conflict("A second factor is already enabled for this user", errors.New("second factor already enabled"))
+	}
+
+	if user.TOTP.PendingSecret == "" {
+		return httperror.BadRequest("No second factor was generated for this user", errors.New("second factor setup required"))
+	}
+
+	step, ok := totp.Validate(payload.Code, user.TOTP.PendingSecret, time.Now(), 0)
+	if !ok {
+		return httperror.NewError(http.StatusUnprocessableEntity, "Invalid authentication code", httperrors.ErrUnauthorized)
+	}
+
+	recoveryCodes, hashes, httpErr := handler.generateRecoveryCodes()
+	if httpErr != nil {
+		return httpErr
+	}
+
+	user.TOTP = portainer.UserTOTP{
+		Enabled:       true,
+		Secret:        user.TOTP.PendingSecret,
+		RecoveryCodes: hashes,
+		LastUsedStep:  step,
+	}
+
+	if err := handler.DataStore.User().Update(user.ID, user); err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	return response.JSON(w, &userTOTPRecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// @id UserTOTPRecoveryCodes
+// @summary Regenerate the recovery codes of a user
+// @description Replaces the recovery codes of the user, the previous ones can no longer be used.
+// @description Only the calling user can regenerate their recovery codes.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "User identifier"
+// @param body body userTOTPCodePayload true "Code of the authenticator application"
+// @success 200 {object} userTOTPRecoveryCodesResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 422 "Invalid code"
+// @failure 500 "Server error"
+// @router /users/{id}/totp/recovery_codes [post]
+func (handler *Handler) userTOTPRecoveryCodes(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload userTOTPCodePayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	user, httpErr := handler.readTOTPUser(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if !user.TOTP.Enabled {
+		return httperror.BadRequest("No second factor is enabled for this user", errors.New("second factor disabled"))
+	}
+
+	step, ok := totp.Validate(payload.Code, user.TOTP.Secret, time.Now(), user.TOTP.LastUsedStep)
+	if !ok {
+		return httperror.NewError(http.StatusUnprocessableEntity, "Invalid authentication code", httperrors.ErrUnauthorized)
+	}
+
+	recoveryCodes, hashes, httpErr := handler.generateRecoveryCodes()
+	if httpErr != nil {
+		return httpErr
+	}
+
+	user.TOTP.RecoveryCodes = hashes
+	user.TOTP.LastUsedStep = step
+
+	if err := handler.DataStore.User().Update(user.ID, user); err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	return response.JSON(w, &userTOTPRecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// @id UserTOTPDisable
+// @summary Disable the second factor of a user
+// @description Disables the second factor of a user. Users must provide their password and cannot disable
+// @description a second factor required by the settings. Administrators can disable the second factor of another user,
+// @description for instance after the loss of a device, the user then enrolls again on the next login when it is required.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @param id path int true "User identifier"
+// @param body body userTOTPDisablePayload true "Password of the user"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 500 "Server error"
+// @router /users/{id}/totp/disable [post]
+func (handler *Handler) userTOTPDisable(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload userTOTPDisablePayload
+	if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if tokenData.ID != portainer.UserID(userID) && tokenData.Role != portainer.AdministratorRole {
+		return httperror.Forbidden("Permission denied to disable the second factor of another user", httperrors.ErrUnauthorized)
+	}
+
+	user, err := handler.DataStore.User().Read(portainer.UserID(userID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a user with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
+	}
+
+	if tokenData.ID == user.ID {
+		if err := handler.CryptoService.CompareHashAndData(user.Password, payload.Password); err != nil {
+			return httperror.Forbidden("Current password doesn't match", errors.New("Current password does not match the password provided. Please try again"))
+		}
+
+		settings, err := handler.DataStore.Settings().Settings()
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the settings from the database", err)
+		}
+
+		if totp.Required(settings, user) {
+			return httperror.Forbidden("A second factor is required for this user", errors.New("second factor required by the settings"))
+		}
+	}
+
+	user.TOTP = portainer.UserTOTP{}
+
+	if err := handler.DataStore.User().Update(user.ID, user); err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	return response.Empty(w)
+}
+
+// readTOTPUser returns the user of the route, it must be the calling user
+func (handler *Handler) readTOTPUser(r *http.Request) (*portainer.User, *httperror.HandlerError) {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if tokenData.ID != portainer.UserID(userID) {
+		return nil, httperror.Forbidden("Permission denied to manage the second factor of another user", httperrors.ErrUnauthorized)
+	}
+
+	user, err := handler.DataStore.User().Read(portainer.UserID(userID))
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
+	}
+
+	return user, nil
+}
+
+func (handler *Handler) generateRecoveryCodes() ([]string, []string, *httperror.HandlerError) {
+	recoveryCodes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to generate the recovery codes", err)
+	}
+
+	hashes, err := totp.HashRecoveryCodes(handler.CryptoService, recoveryCodes)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to generate the recovery codes", err)
+	}
+
+	return recoveryCodes, hashes, nil
+}
@@ -0,0 +1,137 @@
+package users
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/apikey"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+	"github.com/portainer/portainer/api/jwt"
+	"github.com/portainer/portainer/api/totp"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_userTOTP(t *testing.T) {
+	is := assert.New(t)
+
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	adminUser := &portainer.User{Username: "admin", Role: portainer.AdministratorRole}
+	require.NoError(t, store.User().Create(adminUser))
+
+	user := &portainer.User{Username: "standard", Role: portainer.StandardUserRole}
+	require.NoError(t, store.User().Create(user))
+
+	jwtService, err := jwt.NewService("1h", store)
+	require.NoError(t, err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	passwordChecker := security.NewPasswordStrengthChecker(store.SettingsService)
+
+	h := NewHandler(requestBouncer, rateLimiter, apiKeyService, passwordChecker)
+	h.DataStore = store
+	h.CryptoService = testhelpers.NewCryptoService()
+
+	adminJWT, _, _ := jwtService.GenerateToken(&portainer.TokenData{ID: adminUser.ID, Username: adminUser.Username, Role: adminUser.Role})
+	userJWT, _, _ := jwtService.GenerateToken(&portainer.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+
+	do := func(method, url, token string, payload any) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if payload != nil {
+			require.NoError(t, json.NewEncoder(&body).Encode(payload))
+		}
+
+		req := httptest.NewRequest(method, url, &body)
+		testhelpers.AddTestSecurityCookie(req, token)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	t.Run("user cannot enroll a second factor for another user", func(t *testing.T) {
+		rr := do(http.MethodPost, "/users/1/totp/setup", userJWT, nil)
+		is.Equal(http.StatusForbidden, rr.Code)
+	})
+
+	var secret string
+
+	t.Run("user enrolls a second factor", func(t *testing.T) {
+		rr := do(http.MethodPost, "/users/2/totp/setup", userJWT, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var setup userTOTPSetupResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&setup))
+		is.Contains(setup.ProvisioningURI, "Portainer:standard")
+		secret = setup.Secret
+
+		rr = do(http.MethodPost, "/users/2/totp/enable", userJWT, userTOTPCodePayload{Code: "abcdef"})
+		is.Equal(http.StatusUnprocessableEntity, rr.Code)
+
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+
+		rr = do(http.MethodPost, "/users/2/totp/enable", userJWT, userTOTPCodePayload{Code: code})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp userTOTPRecoveryCodesResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		is.Len(resp.RecoveryCodes, totp.RecoveryCodeCount)
+
+		stored, err := store.User().Read(user.ID)
+		require.NoError(t, err)
+		is.True(stored.TOTP.Enabled)
+		is.Equal(secret, stored.TOTP.Secret)
+		is.Empty(stored.TOTP.PendingSecret)
+
+		rr = do(http.MethodPost, "/users/2/totp/setup", userJWT, nil)
+		is.Equal(http.StatusConflict, rr.Code)
+	})
+
+	t.Run("second factor secrets are not returned", func(t *testing.T) {
+		rr := do(http.MethodGet, "/users/2", userJWT, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var inspected portainer.User
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&inspected))
+		is.Equal(portainer.UserTOTP{Enabled: true}, inspected.TOTP)
+	})
+
+	t.Run("user cannot disable a second factor required by the settings", func(t *testing.T) {
+		settings, err := store.Settings().Settings()
+		require.NoError(t, err)
+		settings.TwoFactorRequirement = portainer.TwoFactorRequiredForAll
+		require.NoError(t, store.Settings().UpdateSettings(settings))
+
+		rr := do(http.MethodPost, "/users/2/totp/disable", userJWT, userTOTPDisablePayload{Password: "password"})
+		is.Equal(http.StatusForbidden, rr.Code)
+
+		settings.TwoFactorRequirement = portainer.TwoFactorOptional
+		require.NoError(t, store.Settings().UpdateSettings(settings))
+	})
+
+	t.Run("administrator disables the second factor of another user", func(t *testing.T) {
+		rr := do(http.MethodPost, "/users/2/totp/disable", adminJWT, userTOTPDisablePayload{})
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		stored, err := store.User().Read(user.ID)
+		require.NoError(t, err)
+		is.Equal(portainer.UserTOTP{}, stored.TOTP)
+	})
+
+	t.Run("user cannot disable the second factor of another user", func(t *testing.T) {
+		rr := do(http.MethodPost, "/users/1/totp/disable", userJWT, userTOTPDisablePayload{Password: "password"})
+		is.Equal(http.StatusForbidden, rr.Code)
+	})
+}
@@ -152,8 +152,8 @@ func (handler *Handler) userUpdate(w http.ResponseWriter, r *http.Request) *http
 	// remove all of the users persisted API keys
 	handler.apiKeyService.InvalidateUserKeyCache(user.ID)
 
-	// hide the password field in the response payload
-	user.Password = ""
+	// hide the password and second factor secrets in the response payload
+	hideFields(user)
 
 	return response.JSON(w, user)
 }
@@ -3,9 +3,12 @@ package webhooks
 import (
 	"net/http"
 
+	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/docker"
 	dockerclient "github.com/portainer/portainer/api/docker/client"
 	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/kubernetes/cli"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 
 	"github.com/gorilla/mux"
@@ -14,9 +17,12 @@ import (
 // Handler is the HTTP handler used to handle webhook operations.
 type Handler struct {
 	*mux.Router
-	requestBouncer      security.BouncerService
-	DataStore           dataservices.DataStore
-	DockerClientFactory *dockerclient.ClientFactory
+	requestBouncer          security.BouncerService
+	DataStore               dataservices.DataStore
+	DockerClientFactory     *dockerclient.ClientFactory
+	ContainerService        *docker.ContainerService
+	KubernetesClientFactory *cli.ClientFactory
+	KubernetesDeployer      portainer.KubernetesDeployer
 }
 
 // NewHandler creates a handler to manage webhooks operations.
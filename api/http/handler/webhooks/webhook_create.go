@@ -18,7 +18,7 @@ type webhookCreatePayload struct {
 	ResourceID string
 	EndpointID portainer.EndpointID
 	RegistryID portainer.RegistryID
-	// Type of webhook (1 - service)
+	// Type of webhook (1 - service, 2 - container, 3 - Kubernetes deployment)
 	WebhookType portainer.WebhookType
 }
 
@@ -29,7 +29,13 @@ func (payload *webhookCreatePayload) Validate(r *http.Request) error {
 	if payload.EndpointID == 0 {
 		return errors.New("Invalid EndpointID")
 	}
-	if payload.WebhookType != portainer.ServiceWebhook {
+	switch payload.WebhookType {
+	case portainer.ServiceWebhook, portainer.ContainerWebhook:
+	case portainer.KubernetesDeploymentWebhook:
+		if _, _, err := parseDeploymentResourceID(payload.ResourceID); err != nil {
+			return err
+		}
+	default:
 		return errors.New("Invalid WebhookType")
 	}
 	return nil
@@ -45,6 +51,7 @@ func (payload *webhookCreatePayload) Validate(r *http.Request) error {
 // @param body body webhookCreatePayload true "Webhook data"
 // @success 200 {object} portainer.Webhook
 // @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
 // @failure 409 "A webhook for this resource already exists"
 // @failure 500 "Server error"
 // @router /webhooks [post]
@@ -74,12 +81,16 @@ func (handler *Handler) webhookCreate(w http.ResponseWriter, r *http.Request) *h
 		return httperror.Forbidden("Not authorized to create a webhook", errors.New("not authorized to create a webhook"))
 	}
 
-	if payload.RegistryID != 0 {
-		tokenData, err := security.RetrieveTokenData(r)
-		if err != nil {
-			return httperror.InternalServerError("Unable to retrieve user authentication token", err)
-		}
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if err := security.AuthorizedAPIKeyEndpointAccess(tokenData, endpointID); err != nil {
+		return httperror.Forbidden("Permission denied to access environment", err)
+	}
 
+	if payload.RegistryID != 0 {
 		_, err = access.GetAccessibleRegistry(handler.DataStore, nil, tokenData.ID, endpointID, payload.RegistryID)
 		if err != nil {
 			return httperror.Forbidden("Permission deny to access registry", err)
@@ -97,6 +108,7 @@ func (handler *Handler) webhookCreate(w http.ResponseWriter, r *http.Request) *h
 		EndpointID:  endpointID,
 		RegistryID:  payload.RegistryID,
 		WebhookType: payload.WebhookType,
+		CreatedBy:   securityContext.UserID,
 	}
 
 	err = handler.DataStore.Webhook().Create(webhook)
@@ -0,0 +1,48 @@
+package webhooks
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/testhelpers"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookCreate_APIKeyEndpointScope(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	handler := NewHandler(testhelpers.NewTestRequestBouncer())
+	handler.DataStore = store
+
+	tokenData := &portainer.TokenData{
+		ID:          1,
+		Role:        portainer.AdministratorRole,
+		APIKeyScope: &portainer.APIKeyScope{EndpointIDs: []portainer.EndpointID{1}},
+	}
+
+	create := func(endpointID portainer.EndpointID, resourceID string) int {
+		body, err := json.Marshal(webhookCreatePayload{ResourceID: resourceID, EndpointID: endpointID, WebhookType: portainer.ServiceWebhook})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body))
+		req = req.WithContext(security.StoreTokenData(req, tokenData))
+		req = req.WithContext(security.StoreRestrictedRequestContext(req, &security.RestrictedRequestContext{IsAdmin: true, UserID: tokenData.ID}))
+
+		rr := httptest.NewRecorder()
+		if err := handler.webhookCreate(rr, req); err != nil {
+			return err.StatusCode
+		}
+
+		return rr.Code
+	}
+
+	require.Equal(t, http.StatusForbidden, create(2, "other"))
+	require.Equal(t, http.StatusOK, create(1, "allowed"))
+}
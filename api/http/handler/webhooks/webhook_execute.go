@@ -3,11 +3,16 @@ package webhooks
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/docker/images"
+	"github.com/portainer/portainer/api/internal/endpointutils"
 	"github.com/portainer/portainer/api/internal/registryutils"
+	"github.com/portainer/portainer/api/kubernetes/cli"
 	httperror "github.com/portainer/portainer/pkg/libhttp/error"
 	"github.com/portainer/portainer/pkg/libhttp/request"
 	"github.com/portainer/portainer/pkg/libhttp/response"
@@ -17,10 +22,14 @@ import (
 )
 
 // @summary Execute a webhook
-// @description Acts on a passed in token UUID to restart the docker service
+// @description Acts on a passed in token UUID to restart the docker service, recreate the docker container
+// @description or restart the Kubernetes deployment the webhook is related to.
+// @description When a tag is given, the resource is updated to use the image with this tag.
 // @description **Access policy**: public
 // @tags webhooks
 // @param id path string true "Webhook token"
+// @param tag query string false "Tag of the image to update the resource to"
+// @param container query string false "Name of the container to update, Kubernetes deployments only. Defaults to every container"
 // @success 202 "Webhook executed"
 // @failure 400
 // @failure 500
@@ -55,6 +64,12 @@ func (handler *Handler) webhookExecute(w http.ResponseWriter, r *http.Request) *
 	switch webhookType {
 	case portainer.ServiceWebhook:
 		return handler.executeServiceWebhook(w, endpoint, resourceID, registryID, imageTag)
+	case portainer.ContainerWebhook:
+		return handler.executeContainerWebhook(w, r, webhook, endpoint, imageTag)
+	case portainer.KubernetesDeploymentWebhook:
+		containerName, _ := request.RetrieveQueryParameter(r, "container", true)
+
+		return handler.executeKubernetesDeploymentWebhook(w, webhook, endpoint, imageTag, containerName)
 	default:
 		return httperror.InternalServerError("Unsupported webhook type", errors.New("Webhooks for this resource are not currently supported"))
 	}
@@ -97,17 +112,8 @@ func (handler *Handler) executeServiceWebhook(
 	}
 
 	if registryID != 0 {
-		registry, err := handler.DataStore.Registry().Read(registryID)
-		if err != nil {
-			return httperror.InternalServerError("Error getting registry", err)
-		}
-
-		if registry.Authentication {
-			registryutils.EnsureRegTokenValid(handler.DataStore, registry)
-			serviceUpdateOptions.EncodedRegistryAuth, err = registryutils.GetRegistryAuthHeader(registry)
-			if err != nil {
-				return httperror.InternalServerError("Error getting registry auth header", err)
-			}
+		if serviceUpdateOptions.EncodedRegistryAuth, err = handler.registryAuthHeader(registryID); err != nil {
+			return httperror.InternalServerError("Error getting registry auth header", err)
 		}
 	}
 
@@ -125,3 +131,205 @@ func (handler *Handler) executeServiceWebhook(
 
 	return response.Empty(w)
 }
+
+func (handler *Handler) executeContainerWebhook(
+	w http.ResponseWriter,
+	r *http.Request,
+	webhook *portainer.Webhook,
+	endpoint *portainer.Endpoint,
+	imageTag string,
+) *httperror.HandlerError {
+	if !endpointutils.IsDockerEndpoint(endpoint) {
+		return httperror.BadRequest("Container webhooks are only supported on Docker environments", errors.New("environment is not a Docker environment"))
+	}
+
+	// Without a registry, the image is pulled by Recreate which looks up the credentials of the matching registry
+	forcePullImage := true
+
+	if webhook.RegistryID != 0 {
+		if err := handler.pullContainerImage(r.Context(), endpoint, webhook, imageTag); err != nil {
+			return httperror.InternalServerError("Error pulling image", err)
+		}
+
+		forcePullImage = false
+	}
+
+	container, err := handler.ContainerService.Recreate(r.Context(), endpoint, webhook.ResourceID, forcePullImage, imageTag, "")
+	if err != nil {
+		return httperror.InternalServerError("Error recreating container", err)
+	}
+
+	// The webhook keeps targeting the container when it was created with the identifier of the old one
+	if webhook.ResourceID != strings.TrimPrefix(container.Name, "/") && webhook.ResourceID != container.ID {
+		webhook.ResourceID = container.ID
+
+		if err := handler.DataStore.Webhook().Update(webhook.ID, webhook); err != nil {
+			return httperror.InternalServerError("Unable to persist the webhook changes inside the database", err)
+		}
+	}
+
+	return response.Empty(w)
+}
+
+// pullContainerImage pulls the image of the container, with the given tag if any, using the credentials of the webhook registry
+func (handler *Handler) pullContainerImage(ctx context.Context, endpoint *portainer.Endpoint, webhook *portainer.Webhook, imageTag string) error {
+	registryAuth, err := handler.registryAuthHeader(webhook.RegistryID)
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := handler.DockerClientFactory.CreateClient(endpoint, "", nil)
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	container, err := dockerClient.ContainerInspect(ctx, webhook.ResourceID)
+	if err != nil {
+		return err
+	}
+
+	imageName, err := imageWithTag(container.Config.Image, imageTag)
+	if err != nil {
+		return err
+	}
+
+	rc, err := dockerClient.ImagePull(ctx, imageName, image.PullOptions{RegistryAuth: registryAuth})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// the pull is only complete once its progress stream was fully read
+	_, err = io.Copy(io.Discard, rc)
+
+	return err
+}
+
+func (handler *Handler) executeKubernetesDeploymentWebhook(
+	w http.ResponseWriter,
+	webhook *portainer.Webhook,
+	endpoint *portainer.Endpoint,
+	imageTag string,
+	containerName string,
+) *httperror.HandlerError {
+	if !endpointutils.IsKubernetesEndpoint(endpoint) {
+		return httperror.BadRequest("Kubernetes deployment webhooks are only supported on Kubernetes environments", errors.New("environment is not a Kubernetes environment"))
+	}
+
+	namespace, name, err := parseDeploymentResourceID(webhook.ResourceID)
+	if err != nil {
+		return httperror.InternalServerError("Invalid webhook resource", err)
+	}
+
+	kubeClient, err := handler.KubernetesClientFactory.GetPrivilegedKubeClient(endpoint)
+	if err != nil {
+		return httperror.InternalServerError("Unable to create a Kubernetes client", err)
+	}
+
+	if webhook.RegistryID != 0 {
+		if err := handler.refreshRegistrySecret(kubeClient, webhook.RegistryID, namespace); err != nil {
+			return httperror.InternalServerError("Unable to refresh the registry credentials", err)
+		}
+	}
+
+	resource := "deployment/" + name
+
+	if imageTag == "" {
+		if _, err := handler.KubernetesDeployer.Restart(webhook.CreatedBy, endpoint, []string{resource}, namespace); err != nil {
+			return httperror.InternalServerError("Error restarting deployment", err)
+		}
+
+		return response.Empty(w)
+	}
+
+	images, err := kubeClient.GetDeploymentImages(namespace, name)
+	if err != nil {
+		return httperror.InternalServerError("Error looking up deployment", err)
+	}
+
+	if containerName != "" {
+		currentImage, ok := images[containerName]
+		if !ok {
+			return httperror.BadRequest("Unable to find a container with this name in the deployment", fmt.Errorf("container %q not found", containerName))
+		}
+
+		images = map[string]string{containerName: currentImage}
+	}
+
+	for container, currentImage := range images {
+		if images[container], err = imageWithTag(currentImage, imageTag); err != nil {
+			return httperror.BadRequest("Invalid image tag", err)
+		}
+	}
+
+	if _, err := handler.KubernetesDeployer.SetImage(webhook.CreatedBy, endpoint, resource, images, namespace); err != nil {
+		return httperror.InternalServerError("Error updating deployment image", err)
+	}
+
+	return response.Empty(w)
+}
+
+// refreshRegistrySecret recreates the image pull secret of the registry in the namespace so that it holds valid credentials
+func (handler *Handler) refreshRegistrySecret(kubeClient *cli.KubeClient, registryID portainer.RegistryID, namespace string) error {
+	registry, err := handler.DataStore.Registry().Read(registryID)
+	if err != nil {
+		return err
+	}
+
+	if !registry.Authentication {
+		return nil
+	}
+
+	registryutils.EnsureRegTokenValid(handler.DataStore, registry)
+
+	if err := kubeClient.DeleteRegistrySecret(registry.ID, namespace); err != nil {
+		return err
+	}
+
+	return kubeClient.CreateRegistrySecret(registry, namespace)
+}
+
+// registryAuthHeader returns the encoded credentials of the registry, empty when it does not require authentication
+func (handler *Handler) registryAuthHeader(registryID portainer.RegistryID) (string, error) {
+	registry, err := handler.DataStore.Registry().Read(registryID)
+	if err != nil {
+		return "", err
+	}
+
+	if !registry.Authentication {
+		return "", nil
+	}
+
+	registryutils.EnsureRegTokenValid(handler.DataStore, registry)
+
+	return registryutils.GetRegistryAuthHeader(registry)
+}
+
+// parseDeploymentResourceID splits the resource identifier of a Kubernetes deployment webhook, formatted as namespace/name
+func parseDeploymentResourceID(resourceID string) (string, string, error) {
+	namespace, name, ok := strings.Cut(resourceID, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid Kubernetes deployment %q, expected namespace/name", resourceID)
+	}
+
+	return namespace, name, nil
+}
+
+// imageWithTag returns the image reference with its tag replaced, the image is returned unchanged when tag is empty
+func imageWithTag(imageName, tag string) (string, error) {
+	if tag == "" {
+		return imageName, nil
+	}
+
+	img, err := images.ParseImage(images.ParseImageOptions{Name: imageName})
+	if err != nil {
+		return "", err
+	}
+
+	if err := img.WithTag(tag); err != nil {
+		return "", err
+	}
+
+	return img.FullName(), nil
+}
@@ -0,0 +1,66 @@
+package webhooks
+
+import (
+	"net/http"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDeploymentResourceID(t *testing.T) {
+	namespace, name, err := parseDeploymentResourceID("default/web")
+	require.NoError(t, err)
+	assert.Equal(t, "default", namespace)
+	assert.Equal(t, "web", name)
+
+	for _, resourceID := range []string{"web", "/web", "default/", "default/web/extra"} {
+		_, _, err := parseDeploymentResourceID(resourceID)
+		assert.Error(t, err, resourceID)
+	}
+}
+
+func TestImageWithTag(t *testing.T) {
+	tests := []struct {
+		image    string
+		tag      string
+		expected string
+	}{
+		{"nginx", "", "nginx"},
+		{"nginx", "1.27", "docker.io/library/nginx:1.27"},
+		{"nginx:1.25", "1.27", "docker.io/library/nginx:1.27"},
+		{"registry.example.com:5000/team/app:v1", "v2", "registry.example.com:5000/team/app:v2"},
+	}
+
+	for _, tt := range tests {
+		image, err := imageWithTag(tt.image, tt.tag)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, image)
+	}
+
+	_, err := imageWithTag("nginx", "not a tag")
+	assert.Error(t, err)
+}
+
+func TestWebhookCreatePayloadValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload webhookCreatePayload
+		valid   bool
+	}{
+		{"service", webhookCreatePayload{ResourceID: "abc", EndpointID: 1, WebhookType: portainer.ServiceWebhook}, true},
+		{"container", webhookCreatePayload{ResourceID: "abc", EndpointID: 1, WebhookType: portainer.ContainerWebhook}, true},
+		{"deployment", webhookCreatePayload{ResourceID: "default/web", EndpointID: 1, WebhookType: portainer.KubernetesDeploymentWebhook}, true},
+		{"deployment without namespace", webhookCreatePayload{ResourceID: "web", EndpointID: 1, WebhookType: portainer.KubernetesDeploymentWebhook}, false},
+		{"unknown type", webhookCreatePayload{ResourceID: "abc", EndpointID: 1, WebhookType: 42}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.payload.Validate(&http.Request{})
+			assert.Equal(t, tt.valid, err == nil, err)
+		})
+	}
+}
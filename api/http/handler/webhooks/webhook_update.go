@@ -31,6 +31,7 @@ func (payload *webhookUpdatePayload) Validate(r *http.Request) error {
 // @param body body webhookUpdatePayload true "Webhook data"
 // @success 200 {object} portainer.Webhook
 // @failure 400
+// @failure 403
 // @failure 409
 // @failure 500
 // @router /webhooks/{id} [put]
@@ -63,12 +64,16 @@ func (handler *Handler) webhookUpdate(w http.ResponseWriter, r *http.Request) *h
 		return httperror.Forbidden("Not authorized to update a webhook", errors.New("not authorized to update a webhook"))
 	}
 
-	if payload.RegistryID != 0 {
-		tokenData, err := security.RetrieveTokenData(r)
-		if err != nil {
-			return httperror.InternalServerError("Unable to retrieve user authentication token", err)
-		}
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if err := security.AuthorizedAPIKeyEndpointAccess(tokenData, webhook.EndpointID); err != nil {
+		return httperror.Forbidden("Permission denied to access environment", err)
+	}
 
+	if payload.RegistryID != 0 {
 		_, err = access.GetAccessibleRegistry(handler.DataStore, nil, tokenData.ID, webhook.EndpointID, payload.RegistryID)
 		if err != nil {
 			return httperror.Forbidden("Permission deny to access registry", err)
@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/portainer/portainer/api/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute is the route label of the API requests that did not match any route, it keeps the cardinality of the
+// label bounded whatever the requested paths
+const unmatchedRoute = "unmatched"
+
+type routeKey struct{}
+
+// matchedRoute holds the template of the route matched by a request, along with the path it was matched against,
+// which misses the prefix stripped before reaching the router
+type matchedRoute struct {
+	template string
+	path     string
+}
+
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *metricsResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WithRequestMetrics records the duration of every request, per route template. The template is recorded by the
+// RecordRoute middleware of the router serving the request
+func WithRequestMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t0 := time.Now()
+		mw := &metricsResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		route := &matchedRoute{}
+
+		next.ServeHTTP(mw, r.WithContext(context.WithValue(r.Context(), routeKey{}, route)))
+
+		metrics.ObserveHTTPRequest(r.Method, routeLabel(r.URL.Path, route), mw.statusCode, time.Since(t0))
+	})
+}
+
+// RecordRoute records the template of the route matched by the router for WithRequestMetrics
+func RecordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := r.Context().Value(routeKey{}).(*matchedRoute); ok {
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route.template = template
+					route.path = r.URL.Path
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeLabel returns the template of the route matched by a request, prefixed by the part of the path stripped before
+// reaching the router. The requests outside of the API are grouped under /
+func routeLabel(path string, route *matchedRoute) string {
+	if path != "/api" && !strings.HasPrefix(path, "/api/") {
+		return "/"
+	}
+
+	if route.template == "" || !strings.HasSuffix(path, route.path) {
+		return unmatchedRoute
+	}
+
+	return strings.TrimSuffix(path, route.path) + route.template
+}
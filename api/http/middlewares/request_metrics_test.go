@@ -0,0 +1,61 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteLabel(t *testing.T) {
+	t.Parallel()
+
+	router := mux.NewRouter()
+	router.Use(RecordRoute)
+	router.Handle("/stacks/{id}", http.NotFoundHandler())
+	router.PathPrefix("/{id}/docker").Handler(http.NotFoundHandler())
+
+	edgeRouter := mux.NewRouter()
+	edgeRouter.Use(RecordRoute)
+	edgeRouter.Handle("/api/endpoints/{id}/edge/status", http.NotFoundHandler())
+
+	mainHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path[:min(len(r.URL.Path), 14)] {
+		case "/api/endpoints":
+			if r.URL.Path == "/api/endpoints/1/edge/status" {
+				edgeRouter.ServeHTTP(w, r)
+			} else {
+				http.StripPrefix("/api/endpoints", router).ServeHTTP(w, r)
+			}
+		default:
+			http.StripPrefix("/api", router).ServeHTTP(w, r)
+		}
+	})
+
+	for _, tc := range []struct {
+		path     string
+		expected string
+	}{
+		{path: "/", expected: "/"},
+		{path: "/index.html", expected: "/"},
+		{path: "/api/stacks/12", expected: "/api/stacks/{id}"},
+		{path: "/api/endpoints/1/docker/v1.41/containers/abc/json", expected: "/api/endpoints/{id}/docker"},
+		{path: "/api/endpoints/1/edge/status", expected: "/api/endpoints/{id}/edge/status"},
+		{path: "/api/unknown/a1b2c3", expected: unmatchedRoute},
+		{path: "/api/stacks/12/unknown", expected: unmatchedRoute},
+	} {
+		var label string
+
+		handler := WithRequestMetrics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mainHandler.ServeHTTP(w, r)
+
+			label = routeLabel(r.URL.Path, r.Context().Value(routeKey{}).(*matchedRoute))
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		require.Equal(t, tc.expected, label, tc.path)
+	}
+}
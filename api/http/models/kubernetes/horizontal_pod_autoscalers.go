@@ -0,0 +1,158 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Metric types of a horizontal pod autoscaler, the Resource metrics target the CPU or the memory of the pods, the
+// Pods metrics target a custom metric averaged across the pods and the External metrics a metric not related to a
+// Kubernetes object
+const (
+	K8sHorizontalPodAutoscalerMetricResource = "Resource"
+	K8sHorizontalPodAutoscalerMetricPods     = "Pods"
+	K8sHorizontalPodAutoscalerMetricExternal = "External"
+)
+
+// Target types of a horizontal pod autoscaler metric
+const (
+	K8sHorizontalPodAutoscalerTargetUtilization = "Utilization"
+	K8sHorizontalPodAutoscalerTargetAverage     = "AverageValue"
+	K8sHorizontalPodAutoscalerTargetValue       = "Value"
+)
+
+type (
+	K8sHorizontalPodAutoscaler struct {
+		Name         string            `json:"Name"`
+		UID          string            `json:"UID"`
+		Namespace    string            `json:"Namespace"`
+		Labels       map[string]string `json:"Labels,omitempty"`
+		CreationDate time.Time         `json:"CreationDate"`
+
+		// ApplicationKind and ApplicationName identify the scaled application, a Deployment or a StatefulSet
+		ApplicationKind string `json:"ApplicationKind"`
+		ApplicationName string `json:"ApplicationName"`
+
+		// MinReplicas defaults to 1 when omitted
+		MinReplicas int32                              `json:"MinReplicas"`
+		MaxReplicas int32                              `json:"MaxReplicas"`
+		Metrics     []K8sHorizontalPodAutoscalerMetric `json:"Metrics"`
+
+		CurrentReplicas int32 `json:"CurrentReplicas"`
+		DesiredReplicas int32 `json:"DesiredReplicas"`
+	}
+
+	K8sHorizontalPodAutoscalerMetric struct {
+		// Type is one of Resource, Pods or External
+		Type string `json:"Type"`
+		// Name is cpu or memory for the Resource metrics, the name of the custom metric otherwise
+		Name string `json:"Name"`
+		// TargetType is one of Utilization, AverageValue or Value
+		TargetType string `json:"TargetType"`
+		// AverageUtilization is the targeted percentage of the requested resource, for the Utilization targets
+		AverageUtilization int32 `json:"AverageUtilization,omitempty"`
+		// Value is the targeted quantity, for the AverageValue and Value targets
+		Value string `json:"Value,omitempty"`
+	}
+
+	// K8sHorizontalPodAutoscalerDeleteRequests is a mapping of namespace names to a slice of
+	// horizontal pod autoscaler names.
+	K8sHorizontalPodAutoscalerDeleteRequests map[string][]string
+)
+
+func (r *K8sHorizontalPodAutoscaler) Validate(request *http.Request) error {
+	if r.Name == "" {
+		return errors.New("missing horizontal pod autoscaler name from the request payload")
+	}
+
+	if r.Namespace == "" {
+		return errors.New("missing horizontal pod autoscaler namespace from the request payload")
+	}
+
+	if r.ApplicationKind != "Deployment" && r.ApplicationKind != "StatefulSet" {
+		return errors.New("invalid application kind, only Deployment and StatefulSet applications can be autoscaled")
+	}
+
+	if r.ApplicationName == "" {
+		return errors.New("missing application name from the request payload")
+	}
+
+	if r.MinReplicas == 0 {
+		r.MinReplicas = 1
+	}
+
+	if r.MinReplicas < 0 || r.MaxReplicas < r.MinReplicas {
+		return errors.New("invalid replicas, the maximum number of replicas must be greater than or equal to the minimum")
+	}
+
+	if len(r.Metrics) == 0 {
+		return errors.New("missing metrics from the request payload")
+	}
+
+	for _, metric := range r.Metrics {
+		if err := metric.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m K8sHorizontalPodAutoscalerMetric) validate() error {
+	switch m.Type {
+	case K8sHorizontalPodAutoscalerMetricResource:
+		if m.Name != "cpu" && m.Name != "memory" {
+			return fmt.Errorf("invalid resource metric %q, only cpu and memory are supported", m.Name)
+		}
+
+		if m.TargetType == K8sHorizontalPodAutoscalerTargetUtilization {
+			if m.AverageUtilization <= 0 {
+				return fmt.Errorf("invalid average utilization for the %s metric", m.Name)
+			}
+
+			return nil
+		}
+
+		if m.TargetType != K8sHorizontalPodAutoscalerTargetAverage {
+			return fmt.Errorf("invalid target type %q for the %s metric", m.TargetType, m.Name)
+		}
+	case K8sHorizontalPodAutoscalerMetricPods:
+		if m.TargetType != K8sHorizontalPodAutoscalerTargetAverage {
+			return fmt.Errorf("invalid target type %q for the %s metric, pods metrics target an average value", m.TargetType, m.Name)
+		}
+	case K8sHorizontalPodAutoscalerMetricExternal:
+		if m.TargetType != K8sHorizontalPodAutoscalerTargetAverage && m.TargetType != K8sHorizontalPodAutoscalerTargetValue {
+			return fmt.Errorf("invalid target type %q for the %s metric", m.TargetType, m.Name)
+		}
+	default:
+		return fmt.Errorf("invalid metric type %q", m.Type)
+	}
+
+	if m.Name == "" {
+		return errors.New("missing metric name from the request payload")
+	}
+
+	if _, err := resource.ParseQuantity(m.Value); err != nil {
+		return fmt.Errorf("invalid value %q for the %s metric: %w", m.Value, m.Name, err)
+	}
+
+	return nil
+}
+
+func (r K8sHorizontalPodAutoscalerDeleteRequests) Validate(request *http.Request) error {
+	if len(r) == 0 {
+		return errors.New("missing deletion request list in payload")
+	}
+
+	for ns := range r {
+		if len(ns) == 0 {
+			return errors.New("deletion given with empty namespace")
+		}
+	}
+
+	return nil
+}
@@ -12,6 +12,12 @@ type K8sNamespaceDetails struct {
 	Annotations   map[string]string `json:"Annotations"`
 	ResourceQuota *K8sResourceQuota `json:"ResourceQuota"`
 	Owner         string            `json:"Owner"`
+
+	// NetworkPolicyPreset isolates the namespace from the other namespaces, the isolation is removed when empty and
+	// left unchanged when omitted
+	NetworkPolicyPreset *string `json:"NetworkPolicyPreset,omitempty"`
+	// IngressControllerNamespace is the namespace allowed by the allow-from-ingress-controller-namespace preset
+	IngressControllerNamespace string `json:"IngressControllerNamespace,omitempty"`
 }
 
 type K8sResourceQuota struct {
@@ -31,5 +37,19 @@ func (r *K8sNamespaceDetails) Validate(request *http.Request) error {
 		}
 	}
 
+	if r.NetworkPolicyPreset == nil {
+		return nil
+	}
+
+	switch *r.NetworkPolicyPreset {
+	case K8sNetworkPolicyPresetNone, K8sNetworkPolicyPresetDenyFromOtherNamespaces:
+	case K8sNetworkPolicyPresetAllowFromIngressControllerNamespace:
+		if r.IngressControllerNamespace == "" {
+			return fmt.Errorf("missing ingress controller namespace for the %s network policy preset", *r.NetworkPolicyPreset)
+		}
+	default:
+		return fmt.Errorf("invalid network policy preset %q", *r.NetworkPolicyPreset)
+	}
+
 	return nil
 }
@@ -0,0 +1,138 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Network policy presets of a namespace. Both presets allow the traffic between the pods of the namespace, the
+// second one also allows the traffic coming from the namespace of the ingress controller.
+const (
+	K8sNetworkPolicyPresetNone                                = ""
+	K8sNetworkPolicyPresetDenyFromOtherNamespaces             = "deny-from-other-namespaces"
+	K8sNetworkPolicyPresetAllowFromIngressControllerNamespace = "allow-from-ingress-controller-namespace"
+)
+
+type (
+	K8sNetworkPolicy struct {
+		Name         string            `json:"Name"`
+		UID          string            `json:"UID"`
+		Namespace    string            `json:"Namespace"`
+		Labels       map[string]string `json:"Labels,omitempty"`
+		CreationDate time.Time         `json:"CreationDate"`
+
+		// PodSelector selects the pods of the namespace the policy applies to, an empty selector selects all of them
+		PodSelector metav1.LabelSelector `json:"PodSelector"`
+		// PolicyTypes holds Ingress, Egress or both
+		PolicyTypes []string               `json:"PolicyTypes"`
+		Ingress     []K8sNetworkPolicyRule `json:"Ingress"`
+		Egress      []K8sNetworkPolicyRule `json:"Egress"`
+
+		// Preset is the namespace preset the policy was created for, empty for the other policies
+		Preset string `json:"Preset,omitempty"`
+	}
+
+	// K8sNetworkPolicyRule allows the traffic from (ingress) or to (egress) the peers on the ports. No peers matches
+	// all the sources or destinations and no ports matches all the ports
+	K8sNetworkPolicyRule struct {
+		Peers []K8sNetworkPolicyPeer `json:"Peers"`
+		Ports []K8sNetworkPolicyPort `json:"Ports"`
+	}
+
+	// K8sNetworkPolicyPeer is either an IP block or a combination of a pod selector and a namespace selector
+	K8sNetworkPolicyPeer struct {
+		PodSelector       *metav1.LabelSelector    `json:"PodSelector,omitempty"`
+		NamespaceSelector *metav1.LabelSelector    `json:"NamespaceSelector,omitempty"`
+		IPBlock           *K8sNetworkPolicyIPBlock `json:"IPBlock,omitempty"`
+	}
+
+	K8sNetworkPolicyIPBlock struct {
+		CIDR   string   `json:"CIDR"`
+		Except []string `json:"Except,omitempty"`
+	}
+
+	K8sNetworkPolicyPort struct {
+		// Protocol is TCP, UDP or SCTP, TCP when omitted
+		Protocol string `json:"Protocol,omitempty"`
+		// Port is a port number or a named port of the pods
+		Port    string `json:"Port,omitempty"`
+		EndPort int32  `json:"EndPort,omitempty"`
+	}
+
+	// K8sNetworkPolicyDeleteRequests is a mapping of namespace names to a slice of
+	// network policy names.
+	K8sNetworkPolicyDeleteRequests map[string][]string
+)
+
+func (r *K8sNetworkPolicy) Validate(request *http.Request) error {
+	if r.Name == "" {
+		return errors.New("missing network policy name from the request payload")
+	}
+
+	if r.Namespace == "" {
+		return errors.New("missing network policy namespace from the request payload")
+	}
+
+	for _, policyType := range r.PolicyTypes {
+		if policyType != "Ingress" && policyType != "Egress" {
+			return fmt.Errorf("invalid policy type %q, expected Ingress or Egress", policyType)
+		}
+	}
+
+	for _, rules := range [][]K8sNetworkPolicyRule{r.Ingress, r.Egress} {
+		for _, rule := range rules {
+			if err := rule.validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r K8sNetworkPolicyRule) validate() error {
+	for _, peer := range r.Peers {
+		if peer.IPBlock == nil {
+			continue
+		}
+
+		if peer.PodSelector != nil || peer.NamespaceSelector != nil {
+			return errors.New("invalid peer, an IP block can not be combined with selectors")
+		}
+
+		for _, cidr := range append([]string{peer.IPBlock.CIDR}, peer.IPBlock.Except...) {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+			}
+		}
+	}
+
+	for _, port := range r.Ports {
+		switch port.Protocol {
+		case "", "TCP", "UDP", "SCTP":
+		default:
+			return fmt.Errorf("invalid protocol %q, expected TCP, UDP or SCTP", port.Protocol)
+		}
+	}
+
+	return nil
+}
+
+func (r K8sNetworkPolicyDeleteRequests) Validate(request *http.Request) error {
+	if len(r) == 0 {
+		return errors.New("missing deletion request list in payload")
+	}
+
+	for ns := range r {
+		if len(ns) == 0 {
+			return errors.New("deletion given with empty namespace")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	K8sPodDisruptionBudget struct {
+		Name         string            `json:"Name"`
+		UID          string            `json:"UID"`
+		Namespace    string            `json:"Namespace"`
+		Labels       map[string]string `json:"Labels,omitempty"`
+		CreationDate time.Time         `json:"CreationDate"`
+
+		// ApplicationKind and ApplicationName identify the protected application, a Deployment or a StatefulSet. The
+		// budget selects the pods of the application. They are empty for the budgets not created through Portainer
+		ApplicationKind string            `json:"ApplicationKind"`
+		ApplicationName string            `json:"ApplicationName"`
+		Selector        map[string]string `json:"Selector,omitempty"`
+
+		// Either MinAvailable or MaxUnavailable is set, as a number of pods or as a percentage such as 50%
+		MinAvailable   string `json:"MinAvailable,omitempty"`
+		MaxUnavailable string `json:"MaxUnavailable,omitempty"`
+
+		CurrentHealthy     int32 `json:"CurrentHealthy"`
+		DesiredHealthy     int32 `json:"DesiredHealthy"`
+		ExpectedPods       int32 `json:"ExpectedPods"`
+		DisruptionsAllowed int32 `json:"DisruptionsAllowed"`
+	}
+
+	// K8sPodDisruptionBudgetDeleteRequests is a mapping of namespace names to a slice of
+	// pod disruption budget names.
+	K8sPodDisruptionBudgetDeleteRequests map[string][]string
+)
+
+func (r *K8sPodDisruptionBudget) Validate(request *http.Request) error {
+	if r.Name == "" {
+		return errors.New("missing pod disruption budget name from the request payload")
+	}
+
+	if r.Namespace == "" {
+		return errors.New("missing pod disruption budget namespace from the request payload")
+	}
+
+	if r.ApplicationKind != "Deployment" && r.ApplicationKind != "StatefulSet" {
+		return errors.New("invalid application kind, only Deployment and StatefulSet applications can be protected")
+	}
+
+	if r.ApplicationName == "" {
+		return errors.New("missing application name from the request payload")
+	}
+
+	if (r.MinAvailable == "") == (r.MaxUnavailable == "") {
+		return errors.New("either the minimum available or the maximum unavailable pods must be provided")
+	}
+
+	for _, value := range []string{r.MinAvailable, r.MaxUnavailable} {
+		if value != "" && !isPodCountOrPercentage(value) {
+			return fmt.Errorf("invalid value %q, expected a number of pods or a percentage", value)
+		}
+	}
+
+	return nil
+}
+
+func isPodCountOrPercentage(value string) bool {
+	n, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+
+	return err == nil && n >= 0 && (!strings.HasSuffix(value, "%") || n <= 100)
+}
+
+func (r K8sPodDisruptionBudgetDeleteRequests) Validate(request *http.Request) error {
+	if len(r) == 0 {
+		return errors.New("missing deletion request list in payload")
+	}
+
+	for ns := range r {
+		if len(ns) == 0 {
+			return errors.New("deletion given with empty namespace")
+		}
+	}
+
+	return nil
+}
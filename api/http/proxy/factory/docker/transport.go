@@ -116,6 +116,13 @@ func (transport *Transport) ProxyDockerRequest(request *http.Request) (*http.Res
 	// to   : /containers/{id}/json
 	unversionedPath := apiVersionRe.ReplaceAllString(request.URL.Path, "")
 
+	if tokenData, err := security.RetrieveTokenData(request); err == nil {
+		if security.AuthorizedAPIKeyEndpointAccess(tokenData, transport.endpoint.ID) != nil ||
+			security.AuthorizedAPIKeyMethod(tokenData, request.Method) != nil {
+			return utils.WriteAccessDeniedResponse()
+		}
+	}
+
 	if transport.endpoint.Type == portainer.AgentOnDockerEnvironment || transport.endpoint.Type == portainer.EdgeAgentOnDockerEnvironment {
 		signature, err := transport.signatureService.CreateSignature(portainer.PortainerAgentSignatureMessage)
 		if err != nil {
@@ -5,6 +5,9 @@ import (
 
 	"github.com/pkg/errors"
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/stacks/revisions"
+
+	"github.com/rs/zerolog/log"
 )
 
 func (transport *baseTransport) proxyNamespaceDeleteOperation(request *http.Request, namespace string) (*http.Response, error) {
@@ -57,6 +60,10 @@ func (transport *baseTransport) proxyNamespaceDeleteOperation(request *http.Requ
 			if err := transport.dataStore.Stack().Delete(s.ID); err != nil {
 				return nil, err
 			}
+
+			if err := revisions.RemoveAll(transport.dataStore, s.ID); err != nil {
+				log.Warn().Err(err).Int("stack_id", int(s.ID)).Msg("unable to remove the stack revisions")
+			}
 		}
 	}
 
@@ -12,6 +12,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/http/proxy/factory/utils"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/kubernetes/cli"
 
@@ -48,6 +49,13 @@ func (transport *baseTransport) proxyKubernetesRequest(request *http.Request) (*
 	// URL path examples:
 	// http://localhost:9000/api/endpoints/3/kubernetes/api/v1/namespaces
 	// http://localhost:9000/api/endpoints/3/kubernetes/apis/apps/v1/namespaces/default/deployments
+	if tokenData, err := security.RetrieveTokenData(request); err == nil {
+		if security.AuthorizedAPIKeyEndpointAccess(tokenData, transport.endpoint.ID) != nil ||
+			security.AuthorizedAPIKeyMethod(tokenData, request.Method) != nil {
+			return utils.WriteAccessDeniedResponse()
+		}
+	}
+
 	apiVersionRe := regexp.MustCompile(`^(/kubernetes)?/(api|apis/apps)/v[0-9](\.[0-9])?`)
 	requestPath := apiVersionRe.ReplaceAllString(request.URL.Path, "")
 
@@ -0,0 +1,167 @@
+package security
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+	httperrors "github.com/portainer/portainer/api/http/errors"
+	httperror "github.com/portainer/portainer/pkg/libhttp/error"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrAPIKeyReadOnly          = errors.New("the API key only allows read operations")
+	ErrAPIKeyEndpointForbidden = errors.New("the API key does not allow access to this environment")
+	ErrAPIKeyOperationDenied   = errors.New("the API key does not allow this operation")
+)
+
+// apiKeyOperationPaths lists the API paths, without the /api prefix, of each family of operations
+var apiKeyOperationPaths = map[portainer.APIKeyOperation]*regexp.Regexp{
+	portainer.APIKeyOperationWebhooks:      regexp.MustCompile(`^/webhooks(/|$)`),
+	portainer.APIKeyOperationStackRedeploy: regexp.MustCompile(`^/stacks/[0-9]+/git/redeploy$`),
+	portainer.APIKeyOperationDocker:        regexp.MustCompile(`^/(endpoints/[0-9]+/(agent/)?docker|docker/[0-9]+)(/|$)`),
+	portainer.APIKeyOperationKubernetes:    regexp.MustCompile(`^/(endpoints/[0-9]+/(agent/)?kubernetes|kubernetes/[0-9]+)(/|$)`),
+}
+
+// endpointPathRe matches the API paths, without the /api prefix, that target an environment
+var endpointPathRe = regexp.MustCompile(`^/(?:endpoints|docker|kubernetes)/([0-9]+)(?:/|$)`)
+
+// scopedAPIKeyDeniedPaths matches the API paths, without the /api prefix, that any restricted API key is denied:
+// interactive sessions, kubeconfig files and new access tokens would hand out access that escapes the scope
+var scopedAPIKeyDeniedPaths = regexp.MustCompile(`^/(websocket/|kubernetes/config$|users/[0-9]+/tokens(/|$))`)
+
+// IsValidAPIKeyOperation returns true when the operation is a known family of operations
+func IsValidAPIKeyOperation(operation portainer.APIKeyOperation) bool {
+	_, ok := apiKeyOperationPaths[operation]
+
+	return ok
+}
+
+// isRestrictedAPIKeyScope returns true when the scope restricts the requests of the API key
+func isRestrictedAPIKeyScope(scope *portainer.APIKeyScope) bool {
+	return scope != nil && (scope.ReadOnly || len(scope.EndpointIDs) > 0 || len(scope.Operations) > 0)
+}
+
+// AuthorizedAPIKeyAdministratorAccess returns an error when the request was authenticated with a restricted API
+// key, the administrator operations are not covered by any scope
+func AuthorizedAPIKeyAdministratorAccess(tokenData *portainer.TokenData) error {
+	if isRestrictedAPIKeyScope(tokenData.APIKeyScope) {
+		return ErrAPIKeyOperationDenied
+	}
+
+	return nil
+}
+
+// AuthorizedAPIKeyEndpointAccess returns an error when the request was authenticated with an API key whose
+// scope does not allow the environment
+func AuthorizedAPIKeyEndpointAccess(tokenData *portainer.TokenData, endpointID portainer.EndpointID) error {
+	if tokenData.APIKeyScope == nil || len(tokenData.APIKeyScope.EndpointIDs) == 0 {
+		return nil
+	}
+
+	if !slices.Contains(tokenData.APIKeyScope.EndpointIDs, endpointID) {
+		return ErrAPIKeyEndpointForbidden
+	}
+
+	return nil
+}
+
+// AuthorizedAPIKeyMethod returns an error when the request was authenticated with a read-only API key and the
+// method can change something
+func AuthorizedAPIKeyMethod(tokenData *portainer.TokenData, method string) error {
+	if tokenData.APIKeyScope == nil || !tokenData.APIKeyScope.ReadOnly {
+		return nil
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+
+	return ErrAPIKeyReadOnly
+}
+
+// authorizedAPIKeyRequest verifies that the scope of the API key allows the request
+func authorizedAPIKeyRequest(tokenData *portainer.TokenData, r *http.Request) error {
+	scope := tokenData.APIKeyScope
+	if !isRestrictedAPIKeyScope(scope) {
+		return nil
+	}
+
+	path := apiPath(r)
+
+	if scopedAPIKeyDeniedPaths.MatchString(path) {
+		return ErrAPIKeyOperationDenied
+	}
+
+	if err := AuthorizedAPIKeyMethod(tokenData, r.Method); err != nil {
+		return err
+	}
+
+	if len(scope.Operations) > 0 && !slices.ContainsFunc(scope.Operations, func(operation portainer.APIKeyOperation) bool {
+		re, ok := apiKeyOperationPaths[operation]
+
+		return ok && re.MatchString(path)
+	}) {
+		return ErrAPIKeyOperationDenied
+	}
+
+	if len(scope.EndpointIDs) == 0 {
+		return nil
+	}
+
+	if match := endpointPathRe.FindStringSubmatch(path); match != nil {
+		id, _ := strconv.Atoi(match[1])
+		if err := AuthorizedAPIKeyEndpointAccess(tokenData, portainer.EndpointID(id)); err != nil {
+			return err
+		}
+	}
+
+	if value := r.URL.Query().Get("endpointId"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return ErrAPIKeyEndpointForbidden
+		}
+
+		return AuthorizedAPIKeyEndpointAccess(tokenData, portainer.EndpointID(id))
+	}
+
+	return nil
+}
+
+// apiPath returns the path requested by the client without the /api prefix, the path of the request might
+// already have been stripped by the parent handlers
+func apiPath(r *http.Request) string {
+	path := r.URL.Path
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil && r.RequestURI != "" {
+		path = u.Path
+	}
+
+	return strings.TrimPrefix(path, "/api")
+}
+
+// mwAPIKeyScope rejects the requests that are not allowed by the scope of the API key used to authenticate them
+func mwAPIKeyScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenData, err := RetrieveTokenData(r)
+		if err != nil {
+			httperror.WriteError(w, http.StatusForbidden, "Access denied", httperrors.ErrUnauthorized)
+
+			return
+		}
+
+		if err := authorizedAPIKeyRequest(tokenData, r); err != nil {
+			httperror.WriteError(w, http.StatusForbidden, "Access denied by the API key scope", err)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
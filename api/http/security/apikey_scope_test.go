@@ -0,0 +1,136 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_authorizedAPIKeyRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   *portainer.APIKeyScope
+		method  string
+		uri     string
+		wantErr error
+	}{
+		{
+			name:   "unscoped token is allowed everything",
+			method: http.MethodDelete,
+			uri:    "/api/endpoints/3",
+		},
+		{
+			name:   "empty scope is allowed everything",
+			scope:  &portainer.APIKeyScope{},
+			method: http.MethodPost,
+			uri:    "/api/websocket/exec?endpointId=3",
+		},
+		{
+			name:   "read-only key can read",
+			scope:  &portainer.APIKeyScope{ReadOnly: true},
+			method: http.MethodGet,
+			uri:    "/api/stacks",
+		},
+		{
+			name:    "read-only key cannot write",
+			scope:   &portainer.APIKeyScope{ReadOnly: true},
+			method:  http.MethodPost,
+			uri:     "/api/stacks/create/standalone/string?endpointId=1",
+			wantErr: ErrAPIKeyReadOnly,
+		},
+		{
+			name:   "environment restricted key can access its environment",
+			scope:  &portainer.APIKeyScope{EndpointIDs: []portainer.EndpointID{1, 2}},
+			method: http.MethodGet,
+			uri:    "/api/endpoints/2/docker/containers/json",
+		},
+		{
+			name:    "environment restricted key cannot access another environment",
+			scope:   &portainer.APIKeyScope{EndpointIDs: []portainer.EndpointID{1, 2}},
+			method:  http.MethodGet,
+			uri:     "/api/endpoints/3/docker/containers/json",
+			wantErr: ErrAPIKeyEndpointForbidden,
+		},
+		{
+			name:    "environment restricted key cannot use another environment in the query",
+			scope:   &portainer.APIKeyScope{EndpointIDs: []portainer.EndpointID{1}},
+			method:  http.MethodGet,
+			uri:     "/api/stacks/4/file?endpointId=3",
+			wantErr: ErrAPIKeyEndpointForbidden,
+		},
+		{
+			name:   "operation restricted key can call its operations",
+			scope:  &portainer.APIKeyScope{Operations: []portainer.APIKeyOperation{portainer.APIKeyOperationStackRedeploy}},
+			method: http.MethodPut,
+			uri:    "/api/stacks/4/git/redeploy?endpointId=1",
+		},
+		{
+			name:    "operation restricted key cannot call other operations",
+			scope:   &portainer.APIKeyScope{Operations: []portainer.APIKeyOperation{portainer.APIKeyOperationWebhooks}},
+			method:  http.MethodPut,
+			uri:     "/api/stacks/4/git/redeploy?endpointId=1",
+			wantErr: ErrAPIKeyOperationDenied,
+		},
+		{
+			name:    "restricted key cannot open an interactive session",
+			scope:   &portainer.APIKeyScope{EndpointIDs: []portainer.EndpointID{1}},
+			method:  http.MethodGet,
+			uri:     "/api/websocket/exec?endpointId=1",
+			wantErr: ErrAPIKeyOperationDenied,
+		},
+		{
+			name:    "restricted key cannot generate a kubeconfig",
+			scope:   &portainer.APIKeyScope{Operations: []portainer.APIKeyOperation{portainer.APIKeyOperationKubernetes}},
+			method:  http.MethodGet,
+			uri:     "/api/kubernetes/config",
+			wantErr: ErrAPIKeyOperationDenied,
+		},
+		{
+			name:    "restricted key cannot create an access token",
+			scope:   &portainer.APIKeyScope{EndpointIDs: []portainer.EndpointID{1}},
+			method:  http.MethodPost,
+			uri:     "/api/users/2/tokens",
+			wantErr: ErrAPIKeyOperationDenied,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.uri, nil)
+			tokenData := &portainer.TokenData{ID: 1, APIKeyScope: test.scope}
+
+			err := authorizedAPIKeyRequest(tokenData, req)
+			if test.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func Test_authorizedAPIKeyRequest_StrippedPath(t *testing.T) {
+	scope := &portainer.APIKeyScope{EndpointIDs: []portainer.EndpointID{1}}
+
+	// the parent handlers strip the path prefixes before the sub-handlers are called
+	req := httptest.NewRequest(http.MethodGet, "/api/endpoints/3/docker/info", nil)
+	req.URL.Path = "/3/docker/info"
+
+	err := authorizedAPIKeyRequest(&portainer.TokenData{ID: 1, APIKeyScope: scope}, req)
+	require.ErrorIs(t, err, ErrAPIKeyEndpointForbidden)
+}
+
+func Test_AuthorizedAPIKeyAdministratorAccess(t *testing.T) {
+	require.NoError(t, AuthorizedAPIKeyAdministratorAccess(&portainer.TokenData{Role: portainer.AdministratorRole}))
+	require.NoError(t, AuthorizedAPIKeyAdministratorAccess(&portainer.TokenData{Role: portainer.AdministratorRole, APIKeyScope: &portainer.APIKeyScope{}}))
+
+	err := AuthorizedAPIKeyAdministratorAccess(&portainer.TokenData{
+		Role:        portainer.AdministratorRole,
+		APIKeyScope: &portainer.APIKeyScope{EndpointIDs: []portainer.EndpointID{1}},
+	})
+	require.ErrorIs(t, err, ErrAPIKeyOperationDenied)
+}
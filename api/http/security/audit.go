@@ -0,0 +1,73 @@
+package security
+
+import (
+	"context"
+	"net/http"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// AuditService records the audit trail of the requests made by authenticated users
+type AuditService interface {
+	RecordRequest(r *http.Request, tokenData *portainer.TokenData, authMethod portainer.AuditAuthMethod, statusCode int)
+}
+
+type auditedKey struct{}
+
+// statusRecorder captures the status code written by the next handlers
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// EnableAuditLog makes the bouncer record every request made by an authenticated user
+func (bouncer *RequestBouncer) EnableAuditLog(auditService AuditService) {
+	bouncer.auditService = auditService
+}
+
+// mwAuditLog records the outcome of the request once it has been handled.
+// It expects the token data to be present in the request context.
+func (bouncer *RequestBouncer) mwAuditLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if bouncer.auditService == nil || r.Context().Value(auditedKey{}) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tokenData, err := RetrieveTokenData(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		ctx := context.WithValue(r.Context(), auditedKey{}, true)
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		bouncer.auditService.RecordRequest(r, tokenData, auditAuthMethod(r), recorder.statusCode)
+	})
+}
+
+// auditAuthMethod returns how the request was authenticated, following the
+// order used by the token lookups of mwAuthenticatedUser
+func auditAuthMethod(r *http.Request) portainer.AuditAuthMethod {
+	if _, ok := extractAPIKey(r); ok {
+		return portainer.AuditAuthMethodAPIKey
+	}
+
+	if token, err := extractKeyFromCookie(r); err == nil && token != "" {
+		return portainer.AuditAuthMethodCookie
+	}
+
+	return portainer.AuditAuthMethodBearer
+}
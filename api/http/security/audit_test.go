@@ -0,0 +1,60 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/require"
+)
+
+type auditRecord struct {
+	userID     portainer.UserID
+	authMethod portainer.AuditAuthMethod
+	statusCode int
+}
+
+type testAuditService struct {
+	records []auditRecord
+}
+
+func (s *testAuditService) RecordRequest(r *http.Request, tokenData *portainer.TokenData, authMethod portainer.AuditAuthMethod, statusCode int) {
+	s.records = append(s.records, auditRecord{userID: tokenData.ID, authMethod: authMethod, statusCode: statusCode})
+}
+
+func Test_mwAuditLog(t *testing.T) {
+	auditService := &testAuditService{}
+
+	bouncer := NewRequestBouncer(nil, nil, nil)
+	bouncer.EnableAuditLog(auditService)
+
+	forbidden := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	// nested bouncers must only record the request once
+	h := bouncer.mwAuditLog(bouncer.mwAuditLog(forbidden))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/stacks", nil)
+	req.Header.Set(apiKeyHeader, "ptr_key")
+	req = req.WithContext(StoreTokenData(req, &portainer.TokenData{ID: 3}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	require.Equal(t, []auditRecord{{userID: 3, authMethod: portainer.AuditAuthMethodAPIKey, statusCode: http.StatusForbidden}}, auditService.records)
+}
+
+func Test_auditAuthMethod(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	require.Equal(t, portainer.AuditAuthMethodBearer, auditAuthMethod(req))
+
+	req.AddCookie(&http.Cookie{Name: portainer.AuthCookieKey, Value: "jwt"})
+	require.Equal(t, portainer.AuditAuthMethodCookie, auditAuthMethod(req))
+
+	req.Header.Set(apiKeyHeader, "ptr_key")
+	require.Equal(t, portainer.AuditAuthMethodAPIKey, auditAuthMethod(req))
+}
@@ -44,6 +44,7 @@ type (
 		dataStore     dataservices.DataStore
 		jwtService    portainer.JWTService
 		apiKeyService apikey.APIKeyService
+		auditService  AuditService
 		revokedJWT    sync.Map
 		hsts          bool
 		csp           bool
@@ -156,6 +157,10 @@ func (bouncer *RequestBouncer) AuthorizedEndpointOperation(r *http.Request, endp
 		return err
 	}
 
+	if err := AuthorizedAPIKeyEndpointAccess(tokenData, endpoint.ID); err != nil {
+		return err
+	}
+
 	if tokenData.Role == portainer.AdministratorRole {
 		return nil
 	}
@@ -217,7 +222,11 @@ func (bouncer *RequestBouncer) TrustedEdgeEnvironmentAccess(tx dataservices.Data
 // mwAuthenticatedUser authenticates a request by
 // - adding a secure handlers to the response
 // - authenticating the request with a valid token
+// - recording the request in the audit trail
+// - rejecting the requests not allowed by the scope of the API key
 func (bouncer *RequestBouncer) mwAuthenticatedUser(h http.Handler) http.Handler {
+	h = mwAPIKeyScope(h)
+	h = bouncer.mwAuditLog(h)
 	h = bouncer.mwAuthenticateFirst([]tokenLookup{
 		bouncer.apiKeyLookup,
 		bouncer.CookieAuthLookup,
@@ -240,6 +249,13 @@ func (bouncer *RequestBouncer) mwCheckPortainerAuthorizations(next http.Handler,
 			return
 		}
 
+		if administratorOnly {
+			if err := AuthorizedAPIKeyAdministratorAccess(tokenData); err != nil {
+				httperror.WriteError(w, http.StatusForbidden, "Access denied by the API key scope", err)
+				return
+			}
+		}
+
 		if tokenData.Role == portainer.AdministratorRole {
 			next.ServeHTTP(w, r)
 			return
@@ -410,7 +426,7 @@ func (bouncer *RequestBouncer) cleanUpExpiredJWT() {
 
 // apiKeyLookup looks up an verifies an api-key by:
 // - computing the digest of the raw api-key
-// - verifying it exists in cache/database
+// - verifying it exists in cache/database and has not expired
 // - matching the key to a user (ID, Role)
 // If the key is valid/verified, the last updated time of the key is updated.
 // Successful verification of the key will return a TokenData object - since the downstream handlers
@@ -433,6 +449,9 @@ func (bouncer *RequestBouncer) apiKeyLookup(r *http.Request) (*portainer.TokenDa
 		Username: user.Username,
 		Role:     user.Role,
 	}
+	if scope := apiKey.Scope; isRestrictedAPIKeyScope(&scope) {
+		tokenData.APIKeyScope = &scope
+	}
 	if _, _, err := bouncer.jwtService.GenerateToken(tokenData); err != nil {
 		log.Debug().Err(err).Msg("Failed to generate token")
 		return nil, errors.New("failed to generate token")
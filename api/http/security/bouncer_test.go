@@ -382,6 +382,35 @@ func Test_apiKeyLookup(t *testing.T) {
 
 		is.Greater(apiKeyUpdated.LastUsed, apiKey.LastUsed)
 	})
+
+	t.Run("scoped x-api-key header adds the scope to the token", func(t *testing.T) {
+		scope := portainer.APIKeyScope{ReadOnly: true, EndpointIDs: []portainer.EndpointID{1}}
+		rawAPIKey, apiKey, err := apiKeyService.GenerateScopedApiKey(*user, "test", 0, scope)
+		require.NoError(t, err)
+		defer apiKeyService.DeleteAPIKey(apiKey.ID)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Add("x-api-key", rawAPIKey)
+
+		token, err := bouncer.apiKeyLookup(req)
+		require.NoError(t, err)
+
+		expectedToken := &portainer.TokenData{ID: user.ID, Username: user.Username, Role: portainer.StandardUserRole, APIKeyScope: &scope}
+		is.Equal(expectedToken, token)
+	})
+
+	t.Run("expired x-api-key header fails api-key lookup", func(t *testing.T) {
+		rawAPIKey, apiKey, err := apiKeyService.GenerateScopedApiKey(*user, "test", time.Now().Add(-time.Minute).Unix(), portainer.APIKeyScope{})
+		require.NoError(t, err)
+		defer apiKeyService.DeleteAPIKey(apiKey.ID)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Add("x-api-key", rawAPIKey)
+
+		token, err := bouncer.apiKeyLookup(req)
+		is.Nil(token)
+		require.ErrorIs(t, err, ErrInvalidKey)
+	})
 }
 
 func Test_ShouldSkipCSRFCheck(t *testing.T) {
@@ -11,12 +11,17 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/adminmonitor"
 	"github.com/portainer/portainer/api/apikey"
+	"github.com/portainer/portainer/api/audit"
+	backupops "github.com/portainer/portainer/api/backup"
 	"github.com/portainer/portainer/api/crypto"
 	"github.com/portainer/portainer/api/dataservices"
 	"github.com/portainer/portainer/api/docker"
 	dockerclient "github.com/portainer/portainer/api/docker/client"
+	"github.com/portainer/portainer/api/docker/imageupdate"
+	gitcredentials "github.com/portainer/portainer/api/git/credentials"
 	"github.com/portainer/portainer/api/http/csrf"
 	"github.com/portainer/portainer/api/http/handler"
+	"github.com/portainer/portainer/api/http/handler/auditlogs"
 	"github.com/portainer/portainer/api/http/handler/auth"
 	"github.com/portainer/portainer/api/http/handler/backup"
 	"github.com/portainer/portainer/api/http/handler/customtemplates"
@@ -34,7 +39,9 @@ import (
 	"github.com/portainer/portainer/api/http/handler/hostmanagement/openamt"
 	kubehandler "github.com/portainer/portainer/api/http/handler/kubernetes"
 	"github.com/portainer/portainer/api/http/handler/ldap"
+	metricshandler "github.com/portainer/portainer/api/http/handler/metrics"
 	"github.com/portainer/portainer/api/http/handler/motd"
+	notificationhandler "github.com/portainer/portainer/api/http/handler/notifications"
 	"github.com/portainer/portainer/api/http/handler/registries"
 	"github.com/portainer/portainer/api/http/handler/resourcecontrols"
 	"github.com/portainer/portainer/api/http/handler/roles"
@@ -63,10 +70,12 @@ import (
 	"github.com/portainer/portainer/api/internal/upgrade"
 	k8s "github.com/portainer/portainer/api/kubernetes"
 	"github.com/portainer/portainer/api/kubernetes/cli"
+	"github.com/portainer/portainer/api/notifications"
 	"github.com/portainer/portainer/api/pendingactions"
 	"github.com/portainer/portainer/api/platform"
 	"github.com/portainer/portainer/api/scheduler"
 	"github.com/portainer/portainer/api/stacks/deployments"
+	templateops "github.com/portainer/portainer/api/templates"
 	libhelmtypes "github.com/portainer/portainer/pkg/libhelm/types"
 
 	"github.com/rs/zerolog/log"
@@ -74,6 +83,7 @@ import (
 
 // Server implements the portainer.Server interface
 type Server struct {
+	AuditService                *audit.Service
 	AuthorizationService        *authorization.Service
 	BindAddress                 string
 	BindAddressHTTPS            string
@@ -115,6 +125,10 @@ type Server struct {
 	PlatformService             platform.Service
 	PullLimitCheckDisabled      bool
 	TrustedOrigins              []string
+	MetricsToken                string
+	NotificationService         *notifications.Service
+	GitCredentialService        *gitcredentials.Service
+	SecretKey                   []byte
 }
 
 // Start starts the HTTP server
@@ -126,6 +140,10 @@ func (server *Server) Start() error {
 		requestBouncer.DisableCSP()
 	}
 
+	if server.AuditService != nil {
+		requestBouncer.EnableAuditLog(server.AuditService)
+	}
+
 	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
 	offlineGate := offlinegate.NewOfflineGate()
 
@@ -140,9 +158,21 @@ func (server *Server) Start() error {
 	authHandler.KubernetesTokenCacheManager = kubernetesTokenCacheManager
 	authHandler.OAuthService = server.OAuthService
 
+	var auditLogHandler = auditlogs.NewHandler(requestBouncer)
+	auditLogHandler.DataStore = server.DataStore
+
 	adminMonitor := adminmonitor.New(5*time.Minute, server.DataStore, server.ShutdownCtx)
 	adminMonitor.Start()
 
+	scheduledBackupService, err := backupops.NewScheduledService(server.ShutdownCtx, server.DataStore, offlineGate, server.FileService.GetDatastorePath(), server.Scheduler, server.SecretKey)
+	if err != nil {
+		return err
+	}
+
+	if err := scheduledBackupService.Start(); err != nil {
+		log.Error().Err(err).Msg("unable to schedule the backups")
+	}
+
 	var backupHandler = backup.NewHandler(
 		requestBouncer,
 		server.DataStore,
@@ -150,6 +180,7 @@ func (server *Server) Start() error {
 		server.FileService.GetDatastorePath(),
 		server.ShutdownTrigger,
 		adminMonitor,
+		scheduledBackupService,
 	)
 
 	var roleHandler = roles.NewHandler(requestBouncer)
@@ -202,7 +233,12 @@ func (server *Server) Start() error {
 
 	containerService := docker.NewContainerService(server.DockerClientFactory, server.DataStore)
 
-	var dockerHandler = dockerhandler.NewHandler(requestBouncer, server.AuthorizationService, server.DataStore, server.DockerClientFactory, containerService)
+	imageUpdateService := imageupdate.NewService(server.ShutdownCtx, server.DataStore, server.DockerClientFactory, containerService, server.StackDeployer, server.Scheduler)
+	if err := imageUpdateService.Start(); err != nil {
+		log.Error().Err(err).Msg("unable to schedule the image updates")
+	}
+
+	var dockerHandler = dockerhandler.NewHandler(requestBouncer, server.AuthorizationService, server.DataStore, server.DockerClientFactory, containerService, imageUpdateService)
 
 	var fileHandler = file.NewHandler(filepath.Join(server.AssetsPath, "public"), server.CSP, adminMonitor.WasInstanceDisabled)
 
@@ -217,8 +253,14 @@ func (server *Server) Start() error {
 	ldapHandler.FileService = server.FileService
 	ldapHandler.LDAPService = server.LDAPService
 
+	var metricsHandler = metricshandler.NewHandler(requestBouncer, server.MetricsToken)
+
 	var motdHandler = motd.NewHandler(requestBouncer)
 
+	var notificationHandler = notificationhandler.NewHandler(requestBouncer)
+	notificationHandler.DataStore = server.DataStore
+	notificationHandler.NotificationService = server.NotificationService
+
 	var registryHandler = registries.NewHandler(requestBouncer)
 	registryHandler.DataStore = server.DataStore
 	registryHandler.FileService = server.FileService
@@ -277,6 +319,7 @@ func (server *Server) Start() error {
 	templatesHandler.DataStore = server.DataStore
 	templatesHandler.FileService = server.FileService
 	templatesHandler.GitService = server.GitService
+	templatesHandler.TemplateService = templateops.NewService(server.DataStore, server.GitService, server.FileService)
 
 	var uploadHandler = upload.NewHandler(requestBouncer)
 	uploadHandler.FileService = server.FileService
@@ -286,6 +329,7 @@ func (server *Server) Start() error {
 	userHandler.CryptoService = server.CryptoService
 	userHandler.AdminCreationDone = server.AdminCreationDone
 	userHandler.FileService = server.FileService
+	userHandler.GitCredentialService = server.GitCredentialService
 
 	var websocketHandler = websocket.NewHandler(server.KubernetesTokenCacheManager, requestBouncer)
 	websocketHandler.DataStore = server.DataStore
@@ -296,8 +340,12 @@ func (server *Server) Start() error {
 	var webhookHandler = webhooks.NewHandler(requestBouncer)
 	webhookHandler.DataStore = server.DataStore
 	webhookHandler.DockerClientFactory = server.DockerClientFactory
+	webhookHandler.ContainerService = containerService
+	webhookHandler.KubernetesClientFactory = server.KubernetesClientFactory
+	webhookHandler.KubernetesDeployer = server.KubernetesDeployer
 
 	server.Handler = &handler.Handler{
+		AuditLogHandler:        auditLogHandler,
 		RoleHandler:            roleHandler,
 		AuthHandler:            authHandler,
 		BackupHandler:          backupHandler,
@@ -314,9 +362,11 @@ func (server *Server) Start() error {
 		GitOperationHandler:    gitOperationHandler,
 		FileHandler:            fileHandler,
 		LDAPHandler:            ldapHandler,
+		MetricsHandler:         metricsHandler,
 		HelmTemplatesHandler:   helmTemplatesHandler,
 		KubernetesHandler:      kubernetesHandler,
 		MOTDHandler:            motdHandler,
+		NotificationHandler:    notificationHandler,
 		OpenAMTHandler:         openAMTHandler,
 		RegistryHandler:        registryHandler,
 		ResourceControlHandler: resourceControlHandler,
@@ -335,13 +385,15 @@ func (server *Server) Start() error {
 		WebhookHandler:         webhookHandler,
 	}
 
+	server.Handler.Use(middlewares.RecordRoute)
+
 	errorLogger := NewHTTPLogger()
 
 	handler := adminMonitor.WithRedirect(offlineGate.WaitingMiddleware(time.Minute, server.Handler))
 
-	handler = middlewares.WithPanicLogger(middlewares.WithSlowRequestsLogger(handler))
+	handler = middlewares.WithPanicLogger(middlewares.WithRequestMetrics(middlewares.WithSlowRequestsLogger(handler)))
 
-	handler, err := csrf.WithProtect(handler, server.TrustedOrigins)
+	handler, err = csrf.WithProtect(handler, server.TrustedOrigins)
 	if err != nil {
 		return errors.Wrap(err, "failed to create CSRF middleware")
 	}
@@ -0,0 +1,170 @@
+package edgestacks
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/customtemplates"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+// Variables holding the metadata of the environment that can be used in the values of the edge stack variables,
+// e.g. STORE_ID={{ PORTAINER_ENVIRONMENT_NAME }}
+const (
+	EnvironmentIDVariable    = "PORTAINER_ENVIRONMENT_ID"
+	EnvironmentNameVariable  = "PORTAINER_ENVIRONMENT_NAME"
+	EnvironmentGroupVariable = "PORTAINER_ENVIRONMENT_GROUP"
+	EnvironmentTagsVariable  = "PORTAINER_ENVIRONMENT_TAGS"
+	EdgeIDVariable           = "PORTAINER_EDGE_ID"
+)
+
+var metadataVariables = []string{
+	EnvironmentIDVariable,
+	EnvironmentNameVariable,
+	EnvironmentGroupVariable,
+	EnvironmentTagsVariable,
+	EdgeIDVariable,
+}
+
+// ValidateEnv checks the environment variables of an edge stack and their overrides
+func ValidateEnv(env []portainer.Pair, edgeGroupEnv map[portainer.EdgeGroupID][]portainer.Pair, endpointEnv map[portainer.EndpointID][]portainer.Pair) error {
+	vars := [][]portainer.Pair{env}
+	for _, groupEnv := range edgeGroupEnv {
+		vars = append(vars, groupEnv)
+	}
+
+	for _, environmentEnv := range endpointEnv {
+		vars = append(vars, environmentEnv)
+	}
+
+	for _, pairs := range vars {
+		for _, pair := range pairs {
+			if pair.Name == "" {
+				return errors.New("environment variable name is required")
+			}
+
+			names, err := customtemplates.VariableNames(pair.Value)
+			if err != nil {
+				return fmt.Errorf("invalid value for environment variable %s: %w", pair.Name, err)
+			}
+
+			for _, name := range names {
+				if !slices.Contains(metadataVariables, name) {
+					return fmt.Errorf("invalid value for environment variable %s: unknown variable %s", pair.Name, name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResolveEnv returns the environment variables of an edge stack for an environment. The variables of the stack are
+// overridden by the ones of the edge groups of the environment, in the order of the edge groups of the stack, then by
+// the ones of the environment. The metadata variables of the environment are substituted in the values
+func ResolveEnv(tx dataservices.DataStoreTx, edgeStack *portainer.EdgeStack, endpoint *portainer.Endpoint) ([]portainer.Pair, error) {
+	if len(edgeStack.Env) == 0 && len(edgeStack.EdgeGroupEnv) == 0 && len(edgeStack.EndpointEnv) == 0 {
+		return nil, nil
+	}
+
+	endpointGroup, err := tx.EndpointGroup().Read(endpoint.GroupID)
+	if tx.IsErrObjectNotFound(err) {
+		endpointGroup = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the group of the environment: %w", err)
+	}
+
+	var edgeGroupsEnv [][]portainer.Pair
+	for _, edgeGroupID := range edgeStack.EdgeGroups {
+		groupEnv, ok := edgeStack.EdgeGroupEnv[edgeGroupID]
+		if !ok {
+			continue
+		}
+
+		edgeGroup, err := tx.EdgeGroup().Read(edgeGroupID)
+		if tx.IsErrObjectNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to retrieve the edge group %d: %w", edgeGroupID, err)
+		}
+
+		var endpointGroups []portainer.EndpointGroup
+		if endpointGroup != nil {
+			endpointGroups = append(endpointGroups, *endpointGroup)
+		}
+
+		if len(edge.EdgeGroupRelatedEndpoints(edgeGroup, []portainer.Endpoint{*endpoint}, endpointGroups)) > 0 {
+			edgeGroupsEnv = append(edgeGroupsEnv, groupEnv)
+		}
+	}
+
+	metadata, err := environmentMetadata(tx, endpoint, endpointGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveEnv(edgeStack.Env, edgeGroupsEnv, edgeStack.EndpointEnv[endpoint.ID], metadata)
+}
+
+func resolveEnv(env []portainer.Pair, edgeGroupsEnv [][]portainer.Pair, endpointEnv []portainer.Pair, metadata []portainer.Pair) ([]portainer.Pair, error) {
+	var resolved []portainer.Pair
+
+	override := func(pairs []portainer.Pair) {
+		for _, pair := range pairs {
+			if i := slices.IndexFunc(resolved, func(p portainer.Pair) bool { return p.Name == pair.Name }); i >= 0 {
+				resolved[i].Value = pair.Value
+			} else {
+				resolved = append(resolved, pair)
+			}
+		}
+	}
+
+	override(env)
+	for _, groupEnv := range edgeGroupsEnv {
+		override(groupEnv)
+	}
+	override(endpointEnv)
+
+	for i := range resolved {
+		value, err := customtemplates.Render(resolved[i].Value, metadata)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render the value of the environment variable %s: %w", resolved[i].Name, err)
+		}
+
+		resolved[i].Value = value
+	}
+
+	return resolved, nil
+}
+
+func environmentMetadata(tx dataservices.DataStoreTx, endpoint *portainer.Endpoint, endpointGroup *portainer.EndpointGroup) ([]portainer.Pair, error) {
+	tags := make([]string, 0, len(endpoint.TagIDs))
+	for _, tagID := range endpoint.TagIDs {
+		tag, err := tx.Tag().Read(tagID)
+		if tx.IsErrObjectNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to retrieve the tag %d: %w", tagID, err)
+		}
+
+		tags = append(tags, tag.Name)
+	}
+
+	groupName := ""
+	if endpointGroup != nil {
+		groupName = endpointGroup.Name
+	}
+
+	return []portainer.Pair{
+		{Name: EnvironmentIDVariable, Value: strconv.Itoa(int(endpoint.ID))},
+		{Name: EnvironmentNameVariable, Value: endpoint.Name},
+		{Name: EnvironmentGroupVariable, Value: groupName},
+		{Name: EnvironmentTagsVariable, Value: strings.Join(tags, ",")},
+		{Name: EdgeIDVariable, Value: endpoint.EdgeID},
+	}, nil
+}
@@ -0,0 +1,73 @@
+package edgestacks
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/roar"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveEnv(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	require.NoError(t, store.Tag().Create(&portainer.Tag{ID: 1, Name: "retail"}))
+	require.NoError(t, store.EndpointGroup().Create(&portainer.EndpointGroup{ID: 2, Name: "north"}))
+
+	endpoint := &portainer.Endpoint{ID: 10, Name: "store-042", Type: portainer.EdgeAgentOnDockerEnvironment, GroupID: 2, TagIDs: []portainer.TagID{1}, EdgeID: "edge-042"}
+	require.NoError(t, store.Endpoint().Create(endpoint))
+
+	require.NoError(t, store.EdgeGroup().Create(&portainer.EdgeGroup{ID: 1, Name: "all", EndpointIDs: roar.FromSlice([]portainer.EndpointID{10})}))
+	require.NoError(t, store.EdgeGroup().Create(&portainer.EdgeGroup{ID: 2, Name: "retail", Dynamic: true, TagIDs: []portainer.TagID{1}}))
+	require.NoError(t, store.EdgeGroup().Create(&portainer.EdgeGroup{ID: 3, Name: "other", EndpointIDs: roar.FromSlice([]portainer.EndpointID{11})}))
+
+	edgeStack := &portainer.EdgeStack{
+		ID:         1,
+		EdgeGroups: []portainer.EdgeGroupID{1, 2, 3},
+		Env: []portainer.Pair{
+			{Name: "STORE_ID", Value: "{{ PORTAINER_ENVIRONMENT_NAME }}"},
+			{Name: "LOG_LEVEL", Value: "info"},
+			{Name: "REGION", Value: "default"},
+		},
+		EdgeGroupEnv: map[portainer.EdgeGroupID][]portainer.Pair{
+			1: {{Name: "REGION", Value: "{{ PORTAINER_ENVIRONMENT_GROUP }}"}},
+			2: {{Name: "LOG_LEVEL", Value: "warn"}, {Name: "TAGS", Value: "{{ PORTAINER_ENVIRONMENT_TAGS }}"}},
+			3: {{Name: "LOG_LEVEL", Value: "error"}},
+		},
+		EndpointEnv: map[portainer.EndpointID][]portainer.Pair{
+			10: {{Name: "LOG_LEVEL", Value: "debug"}, {Name: "EDGE", Value: "{{ PORTAINER_EDGE_ID }}-{{ PORTAINER_ENVIRONMENT_ID }}"}},
+			11: {{Name: "LOG_LEVEL", Value: "trace"}},
+		},
+	}
+
+	var env []portainer.Pair
+	require.NoError(t, store.ViewTx(func(tx dataservices.DataStoreTx) error {
+		var err error
+		env, err = ResolveEnv(tx, edgeStack, endpoint)
+
+		return err
+	}))
+
+	require.Equal(t, []portainer.Pair{
+		{Name: "STORE_ID", Value: "store-042"},
+		{Name: "LOG_LEVEL", Value: "debug"},
+		{Name: "REGION", Value: "north"},
+		{Name: "TAGS", Value: "retail"},
+		{Name: "EDGE", Value: "edge-042-10"},
+	}, env)
+
+	// The values of the stack are not modified
+	require.Equal(t, "{{ PORTAINER_ENVIRONMENT_NAME }}", edgeStack.Env[0].Value)
+}
+
+func TestValidateEnv(t *testing.T) {
+	require.NoError(t, ValidateEnv([]portainer.Pair{{Name: "A", Value: "{{ PORTAINER_ENVIRONMENT_NAME }}"}}, nil, nil))
+
+	require.Error(t, ValidateEnv(nil, map[portainer.EdgeGroupID][]portainer.Pair{1: {{Name: "", Value: "a"}}}, nil))
+	require.Error(t, ValidateEnv(nil, nil, map[portainer.EndpointID][]portainer.Pair{1: {{Name: "A", Value: "{{ unclosed"}}}))
+	require.Error(t, ValidateEnv([]portainer.Pair{{Name: "A", Value: "{{ PORTAINER_ENV_NAME }}"}}, nil, nil))
+	require.Error(t, ValidateEnv(nil, map[portainer.EdgeGroupID][]portainer.Pair{1: {{Name: "A", Value: "{{#DEBUG}}{{ PORTAINER_EDGE_ID }}{{/DEBUG}}"}}}, nil))
+}
@@ -0,0 +1,269 @@
+package edgestacks
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/rs/zerolog/log"
+)
+
+const defaultRolloutFailureThreshold = 1
+
+var (
+	// ErrRolloutNotActive is returned when promoting or aborting the rollout of an edge stack that is not in progress
+	ErrRolloutNotActive = errors.New("the edge stack has no rollout in progress")
+	// ErrRolloutFullyReleased is returned when promoting a rollout already released to every environment
+	ErrRolloutFullyReleased = errors.New("the rollout is already released to every environment")
+)
+
+// ValidateRolloutStrategy checks the rollout strategy of an edge stack
+func ValidateRolloutStrategy(strategy *portainer.EdgeStackRolloutStrategy) error {
+	if strategy == nil {
+		return nil
+	}
+
+	if strategy.BatchSize < 0 {
+		return errors.New("invalid rollout batch size")
+	}
+
+	if strategy.BatchPercentage < 0 || strategy.BatchPercentage > 100 {
+		return errors.New("invalid rollout batch percentage, it must be between 1 and 100")
+	}
+
+	if strategy.BatchSize == 0 && strategy.BatchPercentage == 0 {
+		return errors.New("a rollout batch size or batch percentage is required")
+	}
+
+	if strategy.BatchInterval < 0 {
+		return errors.New("invalid rollout batch interval")
+	}
+
+	if strategy.FailureThreshold < 0 {
+		return errors.New("invalid rollout failure threshold")
+	}
+
+	return nil
+}
+
+// StartRollout starts the rollout of the current version of an edge stack to the given environments and releases
+// its first batch. The other environments keep being advertised the previous version, whose files are stored in
+// previousProjectPath and variables in previousEnv, until their batch is released
+func StartRollout(tx dataservices.DataStoreTx, stack *portainer.EdgeStack, previousVersion int, previousProjectPath string, previousEnv *portainer.EdgeStackEnv, endpointIDs []portainer.EndpointID, now time.Time) error {
+	endpoints := slices.Clone(endpointIDs)
+	slices.Sort(endpoints)
+
+	stack.Rollout = &portainer.EdgeStackRollout{
+		Version:             stack.Version,
+		PreviousVersion:     previousVersion,
+		PreviousProjectPath: previousProjectPath,
+		PreviousEnv:         previousEnv,
+		Endpoints:           endpoints,
+	}
+
+	return releaseBatch(tx, stack, now)
+}
+
+// AdvanceRollout updates the failures of the rollout of an edge stack from the statuses of the released
+// environments, then aborts it when the failure threshold is reached, or releases the next batch once every released
+// environment deployed the new version and the batch interval elapsed. It returns true when the rollout changed
+func AdvanceRollout(tx dataservices.DataStoreTx, stack *portainer.EdgeStack, now time.Time) (bool, error) {
+	rollout := stack.Rollout
+	if rollout == nil || rollout.Version != stack.Version || rollout.Status != portainer.EdgeStackRolloutInProgress {
+		return false, nil
+	}
+
+	failures, pending := 0, 0
+	for _, endpointID := range rollout.Endpoints[:rollout.Released] {
+		status, err := tx.EdgeStackStatus().Read(stack.ID, endpointID)
+		if tx.IsErrObjectNotFound(err) {
+			pending++
+
+			continue
+		} else if err != nil {
+			return false, fmt.Errorf("unable to retrieve the status of the edge stack on the environment %d: %w", endpointID, err)
+		}
+
+		switch {
+		case hasStatus(status, portainer.EdgeStackStatusError):
+			failures++
+		case !hasStatus(status, portainer.EdgeStackStatusRunning) && !hasStatus(status, portainer.EdgeStackStatusCompleted):
+			pending++
+		}
+	}
+
+	changed := failures != rollout.Failures
+	rollout.Failures = failures
+
+	if failures >= failureThreshold(stack.RolloutStrategy) {
+		log.Warn().
+			Int("edge_stack_id", int(stack.ID)).
+			Int("version", rollout.Version).
+			Int("failures", failures).
+			Msg("aborting the rollout of the edge stack, the failure threshold is reached")
+
+		rollout.Status = portainer.EdgeStackRolloutAborted
+
+		return true, nil
+	}
+
+	if pending > 0 {
+		return changed, nil
+	}
+
+	if rollout.Released >= len(rollout.Endpoints) {
+		rollout.Status = portainer.EdgeStackRolloutCompleted
+
+		return true, nil
+	}
+
+	if stack.RolloutStrategy != nil && stack.RolloutStrategy.ManualPromotion {
+		rollout.Status = portainer.EdgeStackRolloutWaitingPromotion
+
+		return true, nil
+	}
+
+	if now.Before(time.Unix(rollout.BatchDate, 0).Add(batchInterval(stack.RolloutStrategy))) {
+		return changed, nil
+	}
+
+	return true, releaseBatch(tx, stack, now)
+}
+
+// PromoteRollout releases the next batch of the rollout of an edge stack without waiting for the released
+// environments or the batch interval
+func PromoteRollout(tx dataservices.DataStoreTx, stack *portainer.EdgeStack, now time.Time) error {
+	if !IsRolloutActive(stack) {
+		return ErrRolloutNotActive
+	}
+
+	if stack.Rollout.Released >= len(stack.Rollout.Endpoints) {
+		return ErrRolloutFullyReleased
+	}
+
+	return releaseBatch(tx, stack, now)
+}
+
+// AbortRollout stops the rollout of an edge stack, the released environments keep the new version and the other
+// ones keep the previous version until the next version of the stack
+func AbortRollout(stack *portainer.EdgeStack) error {
+	if !IsRolloutActive(stack) {
+		return ErrRolloutNotActive
+	}
+
+	stack.Rollout.Status = portainer.EdgeStackRolloutAborted
+
+	return nil
+}
+
+// IsRolloutActive returns true when the current version of an edge stack is being rolled out
+func IsRolloutActive(stack *portainer.EdgeStack) bool {
+	rollout := stack.Rollout
+
+	return rollout != nil && rollout.Version == stack.Version &&
+		(rollout.Status == portainer.EdgeStackRolloutInProgress || rollout.Status == portainer.EdgeStackRolloutWaitingPromotion)
+}
+
+// releaseBatch releases the new version to the next batch of environments and clears their statuses so that the
+// failures of the batch are counted against the new version
+func releaseBatch(tx dataservices.DataStoreTx, stack *portainer.EdgeStack, now time.Time) error {
+	rollout := stack.Rollout
+
+	start := rollout.Released
+	rollout.Released = min(start+batchSize(stack.RolloutStrategy, len(rollout.Endpoints)), len(rollout.Endpoints))
+	rollout.Batch++
+	rollout.BatchDate = now.Unix()
+	rollout.Status = portainer.EdgeStackRolloutInProgress
+
+	return tx.EdgeStackStatus().Clear(stack.ID, rollout.Endpoints[start:rollout.Released])
+}
+
+// batchSize returns the number of environments released per batch, every environment is released at once when the
+// strategy was removed during the rollout
+func batchSize(strategy *portainer.EdgeStackRolloutStrategy, count int) int {
+	if strategy == nil {
+		return count
+	}
+
+	size := strategy.BatchSize
+	if strategy.BatchPercentage > 0 {
+		size = (count*strategy.BatchPercentage + 99) / 100
+	}
+
+	return max(size, 1)
+}
+
+func batchInterval(strategy *portainer.EdgeStackRolloutStrategy) time.Duration {
+	if strategy == nil {
+		return 0
+	}
+
+	return time.Duration(strategy.BatchInterval) * time.Second
+}
+
+func failureThreshold(strategy *portainer.EdgeStackRolloutStrategy) int {
+	if strategy == nil || strategy.FailureThreshold == 0 {
+		return defaultRolloutFailureThreshold
+	}
+
+	return strategy.FailureThreshold
+}
+
+func hasStatus(status *portainer.EdgeStackStatusForEnv, statusType portainer.EdgeStackStatusType) bool {
+	return slices.ContainsFunc(status.Status, func(s portainer.EdgeStackDeploymentStatus) bool {
+		return s.Type == statusType
+	})
+}
+
+// RemovePreviousVersion removes the files of the previous version of an edge stack once its rollout is completed,
+// they are no longer advertised to any environment
+func RemovePreviousVersion(fileService portainer.FileService, stack *portainer.EdgeStack) {
+	rollout := stack.Rollout
+	if rollout == nil || rollout.Status != portainer.EdgeStackRolloutCompleted || rollout.PreviousProjectPath == "" {
+		return
+	}
+
+	if err := fileService.RemoveDirectory(rollout.PreviousProjectPath); err != nil {
+		log.Warn().Err(err).Int("edge_stack_id", int(stack.ID)).Msg("unable to remove the files of the previous version of the edge stack")
+
+		return
+	}
+
+	rollout.PreviousProjectPath = ""
+}
+
+// AdvanceRollouts advances the rollouts in progress of every edge stack, it releases the batches whose interval
+// elapsed after the last status update of their environments
+func (service *Service) AdvanceRollouts(fileService portainer.FileService) error {
+	return service.dataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		edgeStacks, err := tx.EdgeStack().EdgeStacks()
+		if err != nil {
+			return fmt.Errorf("unable to retrieve the edge stacks: %w", err)
+		}
+
+		for i := range edgeStacks {
+			stack := &edgeStacks[i]
+
+			changed, err := AdvanceRollout(tx, stack, time.Now())
+			if err != nil {
+				log.Warn().Err(err).Int("edge_stack_id", int(stack.ID)).Msg("unable to advance the rollout of the edge stack")
+
+				continue
+			} else if !changed {
+				continue
+			}
+
+			RemovePreviousVersion(fileService, stack)
+
+			if err := tx.EdgeStack().UpdateEdgeStack(stack.ID, stack); err != nil {
+				return fmt.Errorf("unable to persist the rollout of the edge stack %d: %w", stack.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package edgestacks
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/datastore"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setStatus(t *testing.T, tx dataservices.DataStoreTx, stackID portainer.EdgeStackID, endpointID portainer.EndpointID, statusType portainer.EdgeStackStatusType) {
+	t.Helper()
+
+	require.NoError(t, tx.EdgeStackStatus().Update(stackID, endpointID, &portainer.EdgeStackStatusForEnv{
+		EndpointID: endpointID,
+		Status:     []portainer.EdgeStackDeploymentStatus{{Type: statusType}},
+	}))
+}
+
+func TestRollout(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	now := time.Now()
+
+	stack := &portainer.EdgeStack{
+		ID:              1,
+		Version:         3,
+		RolloutStrategy: &portainer.EdgeStackRolloutStrategy{BatchSize: 2, BatchInterval: 60, FailureThreshold: 2},
+	}
+
+	require.NoError(t, store.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		for _, endpointID := range []portainer.EndpointID{1, 2, 3, 4, 5} {
+			setStatus(t, tx, stack.ID, endpointID, portainer.EdgeStackStatusRunning)
+		}
+
+		require.NoError(t, StartRollout(tx, stack, 2, "/previous", nil, []portainer.EndpointID{5, 3, 1, 4, 2}, now))
+		require.Equal(t, &portainer.EdgeStackRollout{
+			Version:             3,
+			PreviousVersion:     2,
+			PreviousProjectPath: "/previous",
+			Endpoints:           []portainer.EndpointID{1, 2, 3, 4, 5},
+			Released:            2,
+			Batch:               1,
+			BatchDate:           now.Unix(),
+			Status:              portainer.EdgeStackRolloutInProgress,
+		}, stack.Rollout)
+
+		// The statuses of the first batch are cleared
+		status, err := tx.EdgeStackStatus().Read(stack.ID, 1)
+		require.NoError(t, err)
+		require.Empty(t, status.Status)
+
+		// The first batch did not deploy the new version yet
+		changed, err := AdvanceRollout(tx, stack, now)
+		require.NoError(t, err)
+		require.False(t, changed)
+
+		setStatus(t, tx, stack.ID, 1, portainer.EdgeStackStatusRunning)
+		setStatus(t, tx, stack.ID, 2, portainer.EdgeStackStatusError)
+
+		// A failure below the threshold
+		changed, err = AdvanceRollout(tx, stack, now)
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, 1, stack.Rollout.Failures)
+		require.Equal(t, 2, stack.Rollout.Released)
+
+		// The batch interval elapsed
+		changed, err = AdvanceRollout(tx, stack, now.Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, 4, stack.Rollout.Released)
+		require.Equal(t, 2, stack.Rollout.Batch)
+
+		// The environments of the next batches keep the statuses of the previous version
+		status, err = tx.EdgeStackStatus().Read(stack.ID, 5)
+		require.NoError(t, err)
+		require.Len(t, status.Status, 1)
+
+		setStatus(t, tx, stack.ID, 3, portainer.EdgeStackStatusError)
+
+		changed, err = AdvanceRollout(tx, stack, now.Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, portainer.EdgeStackRolloutAborted, stack.Rollout.Status)
+		require.Equal(t, 2, stack.Rollout.Failures)
+		require.Equal(t, 4, stack.Rollout.Released)
+
+		require.ErrorIs(t, PromoteRollout(tx, stack, now), ErrRolloutNotActive)
+
+		return nil
+	}))
+}
+
+func TestRollout_manualPromotion(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	now := time.Now()
+
+	stack := &portainer.EdgeStack{
+		ID:              1,
+		Version:         3,
+		RolloutStrategy: &portainer.EdgeStackRolloutStrategy{BatchPercentage: 50, ManualPromotion: true},
+	}
+
+	require.NoError(t, store.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		require.NoError(t, StartRollout(tx, stack, 2, "/previous", nil, []portainer.EndpointID{1, 2, 3}, now))
+		require.Equal(t, 2, stack.Rollout.Released)
+
+		setStatus(t, tx, stack.ID, 1, portainer.EdgeStackStatusRunning)
+		setStatus(t, tx, stack.ID, 2, portainer.EdgeStackStatusCompleted)
+
+		changed, err := AdvanceRollout(tx, stack, now)
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, portainer.EdgeStackRolloutWaitingPromotion, stack.Rollout.Status)
+		require.Equal(t, 2, stack.Rollout.Released)
+
+		require.NoError(t, PromoteRollout(tx, stack, now))
+		require.Equal(t, portainer.EdgeStackRolloutInProgress, stack.Rollout.Status)
+		require.Equal(t, 3, stack.Rollout.Released)
+
+		require.ErrorIs(t, PromoteRollout(tx, stack, now), ErrRolloutFullyReleased)
+
+		setStatus(t, tx, stack.ID, 3, portainer.EdgeStackStatusRunning)
+
+		changed, err = AdvanceRollout(tx, stack, now)
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, portainer.EdgeStackRolloutCompleted, stack.Rollout.Status)
+
+		require.ErrorIs(t, AbortRollout(stack), ErrRolloutNotActive)
+
+		return nil
+	}))
+}
+
+func TestValidateRolloutStrategy(t *testing.T) {
+	require.NoError(t, ValidateRolloutStrategy(nil))
+	require.NoError(t, ValidateRolloutStrategy(&portainer.EdgeStackRolloutStrategy{BatchSize: 1}))
+	require.NoError(t, ValidateRolloutStrategy(&portainer.EdgeStackRolloutStrategy{BatchPercentage: 10, BatchInterval: 60}))
+
+	require.Error(t, ValidateRolloutStrategy(&portainer.EdgeStackRolloutStrategy{}))
+	require.Error(t, ValidateRolloutStrategy(&portainer.EdgeStackRolloutStrategy{BatchPercentage: 120}))
+	require.Error(t, ValidateRolloutStrategy(&portainer.EdgeStackRolloutStrategy{BatchSize: 1, FailureThreshold: -1}))
+}
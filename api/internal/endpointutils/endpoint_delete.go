@@ -0,0 +1,120 @@
+package endpointutils
+
+import (
+	"strconv"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/metrics"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DeleteEndpoint removes the environment(endpoint) from the database along with the objects referencing it and its
+// TLS files. The failures to clean up the related objects are only logged so that the environment can always be removed
+func DeleteEndpoint(tx dataservices.DataStoreTx, fileService portainer.FileService, endpoint *portainer.Endpoint) error {
+	if endpoint.TLSConfig.TLS {
+		folder := strconv.Itoa(int(endpoint.ID))
+		if err := fileService.DeleteTLSFiles(folder); err != nil {
+			log.Error().Err(err).Msgf("Unable to remove TLS files from disk when deleting endpoint %d", endpoint.ID)
+		}
+	}
+
+	if err := tx.Snapshot().Delete(endpoint.ID); err != nil {
+		log.Warn().Err(err).Msg("Unable to remove the snapshot from the database")
+	}
+
+	if err := tx.SnapshotHistory().Delete(endpoint.ID); err != nil && !tx.IsErrObjectNotFound(err) {
+		log.Warn().Err(err).Msg("Unable to remove the snapshot history from the database")
+	}
+
+	// the scheduled job stops by itself once the policy is missing
+	if err := tx.ImageUpdatePolicy().Delete(endpoint.ID); err != nil && !tx.IsErrObjectNotFound(err) {
+		log.Warn().Err(err).Msg("Unable to remove the image update policy from the database")
+	}
+
+	if err := tx.EndpointRelation().DeleteEndpointRelation(endpoint.ID); err != nil {
+		log.Warn().Err(err).Msg("Unable to remove environment relation from the database")
+	}
+
+	for _, tagID := range endpoint.TagIDs {
+		tag, err := tx.Tag().Read(tagID)
+		if err == nil {
+			delete(tag.Endpoints, endpoint.ID)
+			err = tx.Tag().Update(tagID, tag)
+		}
+
+		if tx.IsErrObjectNotFound(err) {
+			log.Warn().Err(err).Msg("Unable to find tag inside the database")
+		} else if err != nil {
+			log.Warn().Err(err).Msg("Unable to delete tag relation from the database")
+		}
+	}
+
+	edgeGroups, err := tx.EdgeGroup().ReadAll()
+	if err != nil {
+		log.Warn().Err(err).Msgf("Unable to retrieve edge groups from the database")
+	}
+
+	for _, edgeGroup := range edgeGroups {
+		edgeGroup.EndpointIDs.Remove(endpoint.ID)
+
+		if err := tx.EdgeGroup().Update(edgeGroup.ID, &edgeGroup); err != nil {
+			log.Warn().Err(err).Msg("Unable to update edge group")
+		}
+	}
+
+	edgeStacks, err := tx.EdgeStack().EdgeStacks()
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to retrieve edge stacks from the database")
+	}
+
+	for _, edgeStack := range edgeStacks {
+		if err := tx.EdgeStackStatus().Delete(edgeStack.ID, endpoint.ID); err != nil {
+			log.Warn().Err(err).Msg("Unable to delete edge stack status")
+		}
+	}
+
+	registries, err := tx.Registry().ReadAll()
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to retrieve registries from the database")
+	}
+
+	for idx := range registries {
+		registry := &registries[idx]
+		if _, ok := registry.RegistryAccesses[endpoint.ID]; ok {
+			delete(registry.RegistryAccesses, endpoint.ID)
+
+			if err := tx.Registry().Update(registry.ID, registry); err != nil {
+				log.Warn().Err(err).Msg("Unable to update registry accesses")
+			}
+		}
+	}
+
+	if IsEdgeEndpoint(endpoint) {
+		edgeJobs, err := tx.EdgeJob().ReadAll()
+		if err != nil {
+			log.Warn().Err(err).Msg("Unable to retrieve edge jobs from the database")
+		}
+
+		for idx := range edgeJobs {
+			edgeJob := &edgeJobs[idx]
+			if _, ok := edgeJob.Endpoints[endpoint.ID]; ok {
+				delete(edgeJob.Endpoints, endpoint.ID)
+
+				if err := tx.EdgeJob().Update(edgeJob.ID, edgeJob); err != nil {
+					log.Warn().Err(err).Msg("Unable to update edge job")
+				}
+			}
+		}
+	}
+
+	// delete the pending actions
+	if err := tx.PendingActions().DeleteByEndpointID(endpoint.ID); err != nil {
+		log.Warn().Err(err).Int("endpointId", int(endpoint.ID)).Msg("Unable to delete pending actions")
+	}
+
+	metrics.DeleteEndpoint(endpoint.ID)
+
+	return tx.Endpoint().DeleteEndpoint(endpoint.ID)
+}
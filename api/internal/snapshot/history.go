@@ -0,0 +1,198 @@
+package snapshot
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+)
+
+// HistoryRetention returns the durations the raw and the hourly samples of the snapshot history are kept for
+func HistoryRetention(settings portainer.SnapshotHistorySettings) (raw, hourly time.Duration, err error) {
+	raw, err = time.ParseDuration(cmp.Or(settings.RawRetention, portainer.DefaultSnapshotHistoryRawRetention))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	hourly, err = time.ParseDuration(cmp.Or(settings.HourlyRetention, portainer.DefaultSnapshotHistoryHourlyRetention))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if raw < 0 || hourly < 0 {
+		return 0, 0, errors.New("retention cannot be negative")
+	}
+
+	return raw, hourly, nil
+}
+
+// recordHistory adds the summary of the snapshot to the history of its environment and downsamples the older samples
+func recordHistory(tx dataservices.DataStoreTx, snapshot *portainer.Snapshot, now time.Time) error {
+	settings, err := tx.Settings().Settings()
+	if err != nil {
+		return err
+	}
+
+	raw, hourly, err := HistoryRetention(settings.SnapshotHistory)
+	if err != nil {
+		return err
+	}
+
+	if raw == 0 {
+		return nil
+	}
+
+	history, err := tx.SnapshotHistory().Read(snapshot.EndpointID)
+	if tx.IsErrObjectNotFound(err) {
+		history = &portainer.SnapshotHistory{EndpointID: snapshot.EndpointID}
+	} else if err != nil {
+		return err
+	}
+
+	sample := newHistorySample(snapshot)
+	sample.Time = now.Unix()
+
+	history.Samples = downsample(append(history.Samples, sample), now, raw, hourly)
+
+	return tx.SnapshotHistory().Update(snapshot.EndpointID, history)
+}
+
+func newHistorySample(snapshot *portainer.Snapshot) portainer.SnapshotHistorySample {
+	var sample portainer.SnapshotHistorySample
+	var metrics *portainer.PerformanceMetrics
+
+	if s := snapshot.Docker; s != nil {
+		sample = portainer.SnapshotHistorySample{
+			ContainerCount:          s.ContainerCount,
+			RunningContainerCount:   s.RunningContainerCount,
+			StoppedContainerCount:   s.StoppedContainerCount,
+			HealthyContainerCount:   s.HealthyContainerCount,
+			UnhealthyContainerCount: s.UnhealthyContainerCount,
+			ImageCount:              s.ImageCount,
+			VolumeCount:             s.VolumeCount,
+			ServiceCount:            s.ServiceCount,
+			StackCount:              s.StackCount,
+			NodeCount:               s.NodeCount,
+			TotalCPU:                int64(s.TotalCPU),
+			TotalMemory:             s.TotalMemory,
+		}
+		metrics = s.PerformanceMetrics
+	} else if s := snapshot.Kubernetes; s != nil {
+		sample = portainer.SnapshotHistorySample{
+			NodeCount:   s.NodeCount,
+			TotalCPU:    s.TotalCPU,
+			TotalMemory: s.TotalMemory,
+		}
+		metrics = s.PerformanceMetrics
+	}
+
+	if metrics != nil {
+		sample.CPUUsage = metrics.CPUUsage
+		sample.MemoryUsage = metrics.MemoryUsage
+	}
+
+	return sample
+}
+
+// downsample replaces the raw samples of the hours that ended before the raw retention by their hourly average,
+// and removes the hourly samples older than the hourly retention. The samples must be sorted by time.
+func downsample(samples []portainer.SnapshotHistorySample, now time.Time, raw, hourly time.Duration) []portainer.SnapshotHistorySample {
+	rawCutoff := now.Add(-raw).Unix()
+	hourlyCutoff := now.Add(-hourly).Unix()
+
+	result := make([]portainer.SnapshotHistorySample, 0, len(samples))
+
+	var hour []portainer.SnapshotHistorySample
+	flush := func() {
+		if len(hour) > 0 {
+			if average := averageSample(hour); average.Time >= hourlyCutoff {
+				result = append(result, average)
+			}
+		}
+
+		hour = nil
+	}
+
+	for _, sample := range samples {
+		hourStart := time.Unix(sample.Time, 0).Truncate(time.Hour).Unix()
+
+		// keep the raw samples until the whole hour is past the raw retention, so that each hour is averaged once
+		if sample.Hourly || hourStart+int64(time.Hour.Seconds()) > rawCutoff {
+			flush()
+
+			if !sample.Hourly || sample.Time >= hourlyCutoff {
+				result = append(result, sample)
+			}
+
+			continue
+		}
+
+		if len(hour) > 0 && time.Unix(hour[0].Time, 0).Truncate(time.Hour).Unix() != hourStart {
+			flush()
+		}
+
+		hour = append(hour, sample)
+	}
+
+	flush()
+
+	return result
+}
+
+func averageSample(samples []portainer.SnapshotHistorySample) portainer.SnapshotHistorySample {
+	var sum portainer.SnapshotHistorySample
+	for _, s := range samples {
+		sum.ContainerCount += s.ContainerCount
+		sum.RunningContainerCount += s.RunningContainerCount
+		sum.StoppedContainerCount += s.StoppedContainerCount
+		sum.HealthyContainerCount += s.HealthyContainerCount
+		sum.UnhealthyContainerCount += s.UnhealthyContainerCount
+		sum.ImageCount += s.ImageCount
+		sum.VolumeCount += s.VolumeCount
+		sum.ServiceCount += s.ServiceCount
+		sum.StackCount += s.StackCount
+		sum.NodeCount += s.NodeCount
+		sum.TotalCPU += s.TotalCPU
+		sum.TotalMemory += s.TotalMemory
+		sum.CPUUsage += s.CPUUsage
+		sum.MemoryUsage += s.MemoryUsage
+	}
+
+	n := len(samples)
+	avg := func(v int) int { return int(math.Round(float64(v) / float64(n))) }
+	avg64 := func(v int64) int64 { return int64(math.Round(float64(v) / float64(n))) }
+
+	return portainer.SnapshotHistorySample{
+		Time:                    time.Unix(samples[0].Time, 0).Truncate(time.Hour).Unix(),
+		Hourly:                  true,
+		ContainerCount:          avg(sum.ContainerCount),
+		RunningContainerCount:   avg(sum.RunningContainerCount),
+		StoppedContainerCount:   avg(sum.StoppedContainerCount),
+		HealthyContainerCount:   avg(sum.HealthyContainerCount),
+		UnhealthyContainerCount: avg(sum.UnhealthyContainerCount),
+		ImageCount:              avg(sum.ImageCount),
+		VolumeCount:             avg(sum.VolumeCount),
+		ServiceCount:            avg(sum.ServiceCount),
+		StackCount:              avg(sum.StackCount),
+		NodeCount:               avg(sum.NodeCount),
+		TotalCPU:                avg64(sum.TotalCPU),
+		TotalMemory:             avg64(sum.TotalMemory),
+		CPUUsage:                math.Round(sum.CPUUsage/float64(n)*100) / 100,
+		MemoryUsage:             math.Round(sum.MemoryUsage/float64(n)*100) / 100,
+	}
+}
+
+// HistorySamples returns the samples of the history recorded between from and to, both unix timestamps included
+func HistorySamples(history *portainer.SnapshotHistory, from, to int64) []portainer.SnapshotHistorySample {
+	samples := []portainer.SnapshotHistorySample{}
+	for _, sample := range history.Samples {
+		if sample.Time >= from && sample.Time <= to {
+			samples = append(samples, sample)
+		}
+	}
+
+	return samples
+}
@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_downsample(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 30, 0, 0, time.UTC)
+
+	at := func(d time.Duration, running int, memory float64) portainer.SnapshotHistorySample {
+		return portainer.SnapshotHistorySample{Time: now.Add(-d).Unix(), RunningContainerCount: running, MemoryUsage: memory}
+	}
+
+	samples := []portainer.SnapshotHistorySample{
+		{Time: now.Add(-40 * 24 * time.Hour).Truncate(time.Hour).Unix(), Hourly: true, RunningContainerCount: 1},
+		{Time: now.Add(-2 * 24 * time.Hour).Truncate(time.Hour).Unix(), Hourly: true, RunningContainerCount: 2},
+		// 09:05 and 09:35, the hour ended before the cutoff at 10:30
+		at(3*time.Hour+25*time.Minute, 4, 50),
+		at(2*time.Hour+55*time.Minute, 6, 70),
+		// 10:05 and 10:35, the hour straddles the cutoff
+		at(2*time.Hour+25*time.Minute, 8, 80),
+		at(1*time.Hour+55*time.Minute, 8, 80),
+		at(5*time.Minute, 10, 90),
+	}
+
+	result := downsample(samples, now, 2*time.Hour, 30*24*time.Hour)
+
+	require.Len(t, result, 5)
+
+	assert.Equal(t, samples[1], result[0], "hourly samples within the retention should be kept")
+
+	assert.Equal(t, portainer.SnapshotHistorySample{
+		Time:                  time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC).Unix(),
+		Hourly:                true,
+		RunningContainerCount: 5,
+		MemoryUsage:           60,
+	}, result[1], "the raw samples of a past hour should be averaged")
+
+	assert.Equal(t, samples[4:], result[2:], "the raw samples of an hour straddling the cutoff should be kept")
+
+	t.Run("the hourly average is computed once", func(t *testing.T) {
+		again := downsample(result, now.Add(time.Hour), 2*time.Hour, 30*24*time.Hour)
+
+		require.Len(t, again, 4)
+		assert.Equal(t, result[1], again[1])
+		assert.True(t, again[2].Hourly)
+		assert.Equal(t, 8, again[2].RunningContainerCount)
+		assert.Equal(t, result[4], again[3])
+	})
+
+	t.Run("no hourly retention", func(t *testing.T) {
+		assert.Equal(t, samples[4:], downsample(samples, now, 2*time.Hour, 0))
+	})
+}
+
+func Test_HistoryRetention(t *testing.T) {
+	raw, hourly, err := HistoryRetention(portainer.SnapshotHistorySettings{})
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, raw)
+	assert.Equal(t, 720*time.Hour, hourly)
+
+	_, _, err = HistoryRetention(portainer.SnapshotHistorySettings{RawRetention: "one day"})
+	require.Error(t, err)
+
+	_, _, err = HistoryRetention(portainer.SnapshotHistorySettings{HourlyRetention: "-1h"})
+	require.Error(t, err)
+}
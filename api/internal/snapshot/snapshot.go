@@ -3,12 +3,15 @@ package snapshot
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/agent"
 	"github.com/portainer/portainer/api/crypto"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/metrics"
+	"github.com/portainer/portainer/api/notifications"
 	"github.com/portainer/portainer/api/pendingactions"
 	endpointsutils "github.com/portainer/portainer/pkg/endpoints"
 
@@ -161,7 +164,22 @@ func (service *Service) SnapshotEndpoint(endpoint *portainer.Endpoint) error {
 }
 
 func (service *Service) Create(snapshot portainer.Snapshot) error {
-	return service.dataStore.Snapshot().Create(&snapshot)
+	return service.createSnapshot(&snapshot)
+}
+
+// createSnapshot replaces the snapshot of the environment and adds its summary to the history
+func (service *Service) createSnapshot(snapshot *portainer.Snapshot) error {
+	if err := service.dataStore.Snapshot().Create(snapshot); err != nil {
+		return err
+	}
+
+	if err := service.dataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		return recordHistory(tx, snapshot, time.Now())
+	}); err != nil {
+		log.Warn().Err(err).Int("endpoint_id", int(snapshot.EndpointID)).Msg("unable to record the snapshot history")
+	}
+
+	return nil
 }
 
 func (service *Service) FillSnapshotData(endpoint *portainer.Endpoint, includeRaw bool) error {
@@ -177,7 +195,7 @@ func (service *Service) snapshotKubernetesEndpoint(endpoint *portainer.Endpoint)
 	if kubernetesSnapshot != nil {
 		snapshot := &portainer.Snapshot{EndpointID: endpoint.ID, Kubernetes: kubernetesSnapshot}
 
-		return service.dataStore.Snapshot().Create(snapshot)
+		return service.createSnapshot(snapshot)
 	}
 
 	return nil
@@ -196,7 +214,7 @@ func (service *Service) snapshotDockerEndpoint(endpoint *portainer.Endpoint) err
 	if dockerSnapshot != nil {
 		snapshot := &portainer.Snapshot{EndpointID: endpoint.ID, Docker: dockerSnapshot}
 
-		return service.dataStore.Snapshot().Create(snapshot)
+		return service.createSnapshot(snapshot)
 	}
 
 	return nil
@@ -253,10 +271,13 @@ func (service *Service) snapshotEndpoints() error {
 			continue
 		}
 
+		t0 := time.Now()
 		snapshotError := service.SnapshotEndpoint(&endpoint)
+		metrics.ObserveSnapshot(endpoint.ID, time.Since(t0), snapshotError)
 
+		var event *portainer.NotificationEvent
 		if err := service.dataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
-			updateEndpointStatus(tx, &endpoint, snapshotError, service.pendingActionsService)
+			event = updateEndpointStatus(tx, &endpoint, snapshotError, service.pendingActionsService)
 
 			return nil
 		}); err != nil {
@@ -264,13 +285,18 @@ func (service *Service) snapshotEndpoints() error {
 				Err(err).
 				Int("endpoint_id", int(endpoint.ID)).
 				Msg("unable to update environment status")
+		} else if event != nil {
+			// Notified once the transaction is committed, a retried transaction must not notify twice
+			notifications.Notify(*event)
 		}
 	}
 
 	return nil
 }
 
-func updateEndpointStatus(tx dataservices.DataStoreTx, endpoint *portainer.Endpoint, snapshotError error, pendingActionsService *pendingactions.PendingActionsService) {
+// updateEndpointStatus persists the status of the environment after a snapshot, it returns the event to notify
+// when the environment went down or up again
+func updateEndpointStatus(tx dataservices.DataStoreTx, endpoint *portainer.Endpoint, snapshotError error, pendingActionsService *pendingactions.PendingActionsService) *portainer.NotificationEvent {
 	latestEndpointReference, err := tx.Endpoint().Endpoint(endpoint.ID)
 	if latestEndpointReference == nil {
 		log.Debug().
@@ -278,9 +304,10 @@ func updateEndpointStatus(tx dataservices.DataStoreTx, endpoint *portainer.Endpo
 			Str("URL", endpoint.URL).Err(err).
 			Msg("background schedule error (environment snapshot), environment not found inside the database anymore")
 
-		return
+		return nil
 	}
 
+	previousStatus := latestEndpointReference.Status
 	latestEndpointReference.Status = portainer.EndpointStatusUp
 
 	if snapshotError != nil {
@@ -294,17 +321,42 @@ func updateEndpointStatus(tx dataservices.DataStoreTx, endpoint *portainer.Endpo
 
 	latestEndpointReference.Agent.Version = endpoint.Agent.Version
 
+	var event *portainer.NotificationEvent
+
 	if err := tx.Endpoint().UpdateEndpoint(latestEndpointReference.ID, latestEndpointReference); err != nil {
 		log.Debug().
 			Str("endpoint", endpoint.Name).
 			Str("URL", endpoint.URL).Err(err).
 			Msg("background schedule error (environment snapshot), unable to update environment")
+	} else {
+		event = statusChangeEvent(latestEndpointReference, previousStatus, snapshotError)
 	}
 
 	// Run the pending actions
 	if latestEndpointReference.Status == portainer.EndpointStatusUp {
 		pendingActionsService.Execute(endpoint.ID)
 	}
+
+	return event
+}
+
+func statusChangeEvent(endpoint *portainer.Endpoint, previousStatus portainer.EndpointStatus, snapshotError error) *portainer.NotificationEvent {
+	switch {
+	case endpoint.Status == portainer.EndpointStatusDown && previousStatus != portainer.EndpointStatusDown:
+		return &portainer.NotificationEvent{
+			Type:       portainer.NotificationEventEndpointDown,
+			Message:    fmt.Sprintf("Environment %s is down: %s", endpoint.Name, snapshotError),
+			EndpointID: endpoint.ID,
+		}
+	case endpoint.Status == portainer.EndpointStatusUp && previousStatus == portainer.EndpointStatusDown:
+		return &portainer.NotificationEvent{
+			Type:       portainer.NotificationEventEndpointUp,
+			Message:    fmt.Sprintf("Environment %s is up again", endpoint.Name),
+			EndpointID: endpoint.ID,
+		}
+	}
+
+	return nil
 }
 
 // FetchDockerID fetches info.Swarm.Cluster.ID if environment(endpoint) is swarm and info.ID otherwise
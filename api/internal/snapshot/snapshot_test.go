@@ -0,0 +1,67 @@
+package snapshot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/internal/snapshot"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate_recordsHistory(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	service, err := snapshot.NewService("", store, nil, nil, context.Background(), nil)
+	require.NoError(t, err)
+
+	dockerSnapshot := portainer.Snapshot{
+		EndpointID: 1,
+		Docker: &portainer.DockerSnapshot{
+			RunningContainerCount:   3,
+			UnhealthyContainerCount: 1,
+			TotalCPU:                4,
+			TotalMemory:             1024,
+		},
+	}
+
+	start := time.Now().Unix()
+
+	require.NoError(t, service.Create(dockerSnapshot))
+	require.NoError(t, service.Create(dockerSnapshot))
+
+	history, err := store.SnapshotHistory().Read(1)
+	require.NoError(t, err)
+	require.Len(t, history.Samples, 2)
+
+	sample := history.Samples[0]
+	assert.GreaterOrEqual(t, sample.Time, start)
+	assert.Equal(t, portainer.SnapshotHistorySample{
+		Time:                    sample.Time,
+		RunningContainerCount:   3,
+		UnhealthyContainerCount: 1,
+		TotalCPU:                4,
+		TotalMemory:             1024,
+	}, sample)
+
+	assert.Len(t, snapshot.HistorySamples(history, start, time.Now().Unix()), 2)
+	assert.Empty(t, snapshot.HistorySamples(history, 0, start-1))
+
+	t.Run("disabled history", func(t *testing.T) {
+		settings, err := store.Settings().Settings()
+		require.NoError(t, err)
+
+		settings.SnapshotHistory.RawRetention = "0"
+		require.NoError(t, store.Settings().UpdateSettings(settings))
+
+		require.NoError(t, service.Create(dockerSnapshot))
+
+		history, err := store.SnapshotHistory().Read(1)
+		require.NoError(t, err)
+		assert.Len(t, history.Samples, 2)
+	})
+}
@@ -36,3 +36,11 @@ func (manager composeStackManager) Down(ctx context.Context, stack *portainer.St
 func (manager composeStackManager) Pull(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint, options portainer.ComposeOptions) error {
 	return nil
 }
+
+func (manager composeStackManager) Plan(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint, options portainer.ComposePlanOptions) ([]portainer.ComposeServiceChange, error) {
+	return nil, nil
+}
+
+func (manager composeStackManager) WaitForHealthy(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	return nil
+}
@@ -1,6 +1,7 @@
 package testhelpers
 
 import (
+	"os"
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
@@ -13,6 +14,7 @@ import (
 var _ dataservices.DataStore = &testDatastore{}
 
 type testDatastore struct {
+	auditLog                dataservices.AuditLogService
 	customTemplate          dataservices.CustomTemplateService
 	edgeGroup               dataservices.EdgeGroupService
 	edgeJob                 dataservices.EdgeJobService
@@ -38,19 +40,52 @@ type testDatastore struct {
 	version                 dataservices.VersionService
 	webhook                 dataservices.WebhookService
 	pendingActionsService   dataservices.PendingActionsService
+	notificationChannel     dataservices.NotificationChannelService
+	notificationDelivery    dataservices.NotificationDeliveryService
+	backupSettings          dataservices.BackupSettingsService
+	gitCredential           dataservices.GitCredentialService
+	stackRevision           dataservices.StackRevisionService
+	imageUpdatePolicy       dataservices.ImageUpdatePolicyService
+	snapshotHistory         dataservices.SnapshotHistoryService
+	templateSource          dataservices.TemplateSourceService
 	connection              portainer.Connection
 }
 
-func (d *testDatastore) Backup(path string) (string, error)                  { return "", nil }
+func (d *testDatastore) Backup(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	// an empty database file, so that the backup archives can be restored
+	return path, os.WriteFile(path, nil, 0o600)
+}
+
 func (d *testDatastore) Open() (bool, error)                                 { return false, nil }
 func (d *testDatastore) Init() error                                         { return nil }
 func (d *testDatastore) Close() error                                        { return nil }
 func (d *testDatastore) UpdateTx(func(dataservices.DataStoreTx) error) error { return nil }
 func (d *testDatastore) ViewTx(func(dataservices.DataStoreTx) error) error   { return nil }
 
-func (d *testDatastore) CheckCurrentEdition() error                         { return nil }
-func (d *testDatastore) MigrateData() error                                 { return nil }
-func (d *testDatastore) Rollback(force bool) error                          { return nil }
+func (d *testDatastore) CheckCurrentEdition() error { return nil }
+func (d *testDatastore) MigrateData() error         { return nil }
+func (d *testDatastore) Rollback(force bool) error  { return nil }
+func (d *testDatastore) NotificationChannel() dataservices.NotificationChannelService {
+	return d.notificationChannel
+}
+func (d *testDatastore) NotificationDelivery() dataservices.NotificationDeliveryService {
+	return d.notificationDelivery
+}
+func (d *testDatastore) BackupSettings() dataservices.BackupSettingsService { return d.backupSettings }
+func (d *testDatastore) GitCredential() dataservices.GitCredentialService   { return d.gitCredential }
+func (d *testDatastore) StackRevision() dataservices.StackRevisionService   { return d.stackRevision }
+func (d *testDatastore) ImageUpdatePolicy() dataservices.ImageUpdatePolicyService {
+	return d.imageUpdatePolicy
+}
+func (d *testDatastore) SnapshotHistory() dataservices.SnapshotHistoryService {
+	return d.snapshotHistory
+}
+func (d *testDatastore) TemplateSource() dataservices.TemplateSourceService { return d.templateSource }
+func (d *testDatastore) AuditLog() dataservices.AuditLogService             { return d.auditLog }
 func (d *testDatastore) CustomTemplate() dataservices.CustomTemplateService { return d.customTemplate }
 func (d *testDatastore) EdgeGroup() dataservices.EdgeGroupService           { return d.edgeGroup }
 func (d *testDatastore) EdgeJob() dataservices.EdgeJobService               { return d.edgeJob }
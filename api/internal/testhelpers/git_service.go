@@ -65,3 +65,7 @@ func (g *gitService) ListFiles(
 ) ([]string, error) {
 	return nil, nil
 }
+
+func (g *gitService) ResolveAuthentication(auth *gittypes.GitAuthentication) (*gittypes.GitAuthentication, error) {
+	return auth, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/segmentio/encoding/json"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // NamespaceAccessPoliciesDeleteNamespace removes stored policies associated with a given namespace
@@ -175,3 +176,17 @@ func (kcl *KubeClient) SetClientNonAdminNamespaces(nonAdminNamespaces []string)
 
 	kcl.nonAdminNamespaces = nonAdminNamespaces
 }
+
+// checkNamespaceAccess returns a forbidden error when the client is not an admin and the namespace is not one of
+// its non-admin namespaces, so that the namespaced writes respect the same filtering as the reads
+func (kcl *KubeClient) checkNamespaceAccess(namespace string) error {
+	if kcl.GetIsKubeAdmin() {
+		return nil
+	}
+
+	if _, ok := kcl.buildNonAdminNamespacesMap()[namespace]; ok {
+		return nil
+	}
+
+	return k8serrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, namespace, errors.New("the user does not have access to the namespace"))
+}
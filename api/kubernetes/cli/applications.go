@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"fmt"
 
 	models "github.com/portainer/portainer/api/http/models/kubernetes"
 	"github.com/rs/zerolog/log"
@@ -572,3 +573,33 @@ func hasNoScheduledPods(obj interface{}) bool {
 		return false
 	}
 }
+
+// getApplicationSelector returns the pod selector of a Deployment or a StatefulSet of a given namespace.
+func (kcl *KubeClient) getApplicationSelector(namespace, kind, name string) (*metav1.LabelSelector, error) {
+	var selector *metav1.LabelSelector
+
+	switch kind {
+	case "Deployment":
+		deployment, err := kcl.cli.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		selector = deployment.Spec.Selector
+	case "StatefulSet":
+		statefulSet, err := kcl.cli.AppsV1().StatefulSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		selector = statefulSet.Spec.Selector
+	default:
+		return nil, fmt.Errorf("unsupported application kind %s", kind)
+	}
+
+	if selector == nil || (len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0) {
+		return nil, fmt.Errorf("the application %s/%s has no pod selector", namespace, name)
+	}
+
+	return selector, nil
+}
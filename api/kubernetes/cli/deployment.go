@@ -20,3 +20,18 @@ func (kcl *KubeClient) HasStackName(namespace string, stackName string) (bool, e
 	}
 	return true, nil
 }
+
+// GetDeploymentImages returns the image of each container of the deployment, keyed by container name.
+func (kcl *KubeClient) GetDeploymentImages(namespace, name string) (map[string]string, error) {
+	deployment, err := kcl.cli.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	images := make(map[string]string, len(deployment.Spec.Template.Spec.Containers))
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		images[container.Name] = container.Image
+	}
+
+	return images, nil
+}
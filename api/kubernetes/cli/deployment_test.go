@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDeploymentImages(t *testing.T) {
+	kcl := &KubeClient{cli: kfake.NewSimpleClientset()}
+
+	_, err := kcl.cli.AppsV1().Deployments("default").Create(context.Background(), &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "app", Image: "nginx:1.27"},
+						{Name: "sidecar", Image: "envoyproxy/envoy:v1.30"},
+					},
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	images, err := kcl.GetDeploymentImages("default", "web")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"app": "nginx:1.27", "sidecar": "envoyproxy/envoy:v1.30"}, images)
+
+	_, err = kcl.GetDeploymentImages("default", "missing")
+	require.Error(t, err)
+}
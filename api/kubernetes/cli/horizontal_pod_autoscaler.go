@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/rs/zerolog/log"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetHorizontalPodAutoscalers gets the horizontal pod autoscalers of a given namespace, or of all the namespaces when
+// the namespace is empty. Non-admin users only get the autoscalers of the namespaces they have access to.
+func (kcl *KubeClient) GetHorizontalPodAutoscalers(namespace string) ([]models.K8sHorizontalPodAutoscaler, error) {
+	if kcl.GetIsKubeAdmin() {
+		return kcl.fetchHorizontalPodAutoscalers(namespace)
+	}
+
+	return kcl.fetchHorizontalPodAutoscalersForNonAdmin(namespace)
+}
+
+// fetchHorizontalPodAutoscalersForNonAdmin gets the horizontal pod autoscalers of the non-admin namespaces.
+func (kcl *KubeClient) fetchHorizontalPodAutoscalersForNonAdmin(namespace string) ([]models.K8sHorizontalPodAutoscaler, error) {
+	nonAdminNamespaces := kcl.GetClientNonAdminNamespaces()
+
+	log.Debug().
+		Strs("non_admin_namespaces", nonAdminNamespaces).
+		Msg("fetching horizontal pod autoscalers for non-admin user")
+
+	if len(nonAdminNamespaces) == 0 {
+		return nil, nil
+	}
+
+	hpas, err := kcl.fetchHorizontalPodAutoscalers(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	nonAdminNamespaceSet := kcl.buildNonAdminNamespacesMap()
+	results := make([]models.K8sHorizontalPodAutoscaler, 0)
+	for _, hpa := range hpas {
+		if _, ok := nonAdminNamespaceSet[hpa.Namespace]; ok {
+			results = append(results, hpa)
+		}
+	}
+
+	return results, nil
+}
+
+// fetchHorizontalPodAutoscalers gets the horizontal pod autoscalers of a given namespace.
+func (kcl *KubeClient) fetchHorizontalPodAutoscalers(namespace string) ([]models.K8sHorizontalPodAutoscaler, error) {
+	hpas, err := kcl.cli.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.K8sHorizontalPodAutoscaler, 0, len(hpas.Items))
+	for _, hpa := range hpas.Items {
+		results = append(results, parseHorizontalPodAutoscaler(hpa))
+	}
+
+	return results, nil
+}
+
+// GetHorizontalPodAutoscaler gets a horizontal pod autoscaler in a given namespace.
+func (kcl *KubeClient) GetHorizontalPodAutoscaler(namespace, name string) (models.K8sHorizontalPodAutoscaler, error) {
+	if err := kcl.checkNamespaceAccess(namespace); err != nil {
+		return models.K8sHorizontalPodAutoscaler{}, err
+	}
+
+	hpa, err := kcl.cli.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return models.K8sHorizontalPodAutoscaler{}, err
+	}
+
+	return parseHorizontalPodAutoscaler(*hpa), nil
+}
+
+// parseHorizontalPodAutoscaler converts a k8s native horizontal pod autoscaler object to a Portainer
+// K8sHorizontalPodAutoscaler object. Only the Resource, Pods and External metrics are parsed.
+func parseHorizontalPodAutoscaler(hpa autoscalingv2.HorizontalPodAutoscaler) models.K8sHorizontalPodAutoscaler {
+	result := models.K8sHorizontalPodAutoscaler{
+		Name:            hpa.Name,
+		UID:             string(hpa.UID),
+		Namespace:       hpa.Namespace,
+		Labels:          hpa.Labels,
+		CreationDate:    hpa.CreationTimestamp.Time,
+		ApplicationKind: hpa.Spec.ScaleTargetRef.Kind,
+		ApplicationName: hpa.Spec.ScaleTargetRef.Name,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		Metrics:         make([]models.K8sHorizontalPodAutoscalerMetric, 0, len(hpa.Spec.Metrics)),
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+		DesiredReplicas: hpa.Status.DesiredReplicas,
+	}
+
+	result.MinReplicas = 1
+	if hpa.Spec.MinReplicas != nil {
+		result.MinReplicas = *hpa.Spec.MinReplicas
+	}
+
+	for _, metric := range hpa.Spec.Metrics {
+		switch {
+		case metric.Type == autoscalingv2.ResourceMetricSourceType && metric.Resource != nil:
+			result.Metrics = append(result.Metrics, parseMetricTarget(string(metric.Type), string(metric.Resource.Name), metric.Resource.Target))
+		case metric.Type == autoscalingv2.PodsMetricSourceType && metric.Pods != nil:
+			result.Metrics = append(result.Metrics, parseMetricTarget(string(metric.Type), metric.Pods.Metric.Name, metric.Pods.Target))
+		case metric.Type == autoscalingv2.ExternalMetricSourceType && metric.External != nil:
+			result.Metrics = append(result.Metrics, parseMetricTarget(string(metric.Type), metric.External.Metric.Name, metric.External.Target))
+		}
+	}
+
+	return result
+}
+
+func parseMetricTarget(metricType, name string, target autoscalingv2.MetricTarget) models.K8sHorizontalPodAutoscalerMetric {
+	metric := models.K8sHorizontalPodAutoscalerMetric{
+		Type:       metricType,
+		Name:       name,
+		TargetType: string(target.Type),
+	}
+
+	switch {
+	case target.AverageUtilization != nil:
+		metric.AverageUtilization = *target.AverageUtilization
+	case target.AverageValue != nil:
+		metric.Value = target.AverageValue.String()
+	case target.Value != nil:
+		metric.Value = target.Value.String()
+	}
+
+	return metric
+}
+
+// convertToK8sHorizontalPodAutoscaler converts a K8sHorizontalPodAutoscaler object back to a k8s native horizontal
+// pod autoscaler object.
+func convertToK8sHorizontalPodAutoscaler(info models.K8sHorizontalPodAutoscaler) (autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpa := autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      info.Name,
+			Namespace: info.Namespace,
+			Labels:    info.Labels,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       info.ApplicationKind,
+				Name:       info.ApplicationName,
+			},
+			MinReplicas: &info.MinReplicas,
+			MaxReplicas: info.MaxReplicas,
+		},
+	}
+
+	for _, m := range info.Metrics {
+		target, err := convertToK8sMetricTarget(m)
+		if err != nil {
+			return hpa, err
+		}
+
+		metric := autoscalingv2.MetricSpec{Type: autoscalingv2.MetricSourceType(m.Type)}
+		switch m.Type {
+		case models.K8sHorizontalPodAutoscalerMetricResource:
+			metric.Resource = &autoscalingv2.ResourceMetricSource{Name: corev1.ResourceName(m.Name), Target: target}
+		case models.K8sHorizontalPodAutoscalerMetricPods:
+			metric.Pods = &autoscalingv2.PodsMetricSource{Metric: autoscalingv2.MetricIdentifier{Name: m.Name}, Target: target}
+		case models.K8sHorizontalPodAutoscalerMetricExternal:
+			metric.External = &autoscalingv2.ExternalMetricSource{Metric: autoscalingv2.MetricIdentifier{Name: m.Name}, Target: target}
+		}
+
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, metric)
+	}
+
+	return hpa, nil
+}
+
+func convertToK8sMetricTarget(metric models.K8sHorizontalPodAutoscalerMetric) (autoscalingv2.MetricTarget, error) {
+	target := autoscalingv2.MetricTarget{Type: autoscalingv2.MetricTargetType(metric.TargetType)}
+	if metric.TargetType == models.K8sHorizontalPodAutoscalerTargetUtilization {
+		target.AverageUtilization = &metric.AverageUtilization
+
+		return target, nil
+	}
+
+	value, err := resource.ParseQuantity(metric.Value)
+	if err != nil {
+		return target, fmt.Errorf("invalid value %q for the %s metric: %w", metric.Value, metric.Name, err)
+	}
+
+	if metric.TargetType == models.K8sHorizontalPodAutoscalerTargetValue {
+		target.Value = &value
+	} else {
+		target.AverageValue = &value
+	}
+
+	return target, nil
+}
+
+// CreateHorizontalPodAutoscaler creates a horizontal pod autoscaler for an application of a given namespace.
+// The application must exist.
+func (kcl *KubeClient) CreateHorizontalPodAutoscaler(namespace string, info models.K8sHorizontalPodAutoscaler) error {
+	if err := kcl.checkNamespaceAccess(namespace); err != nil {
+		return err
+	}
+
+	if _, err := kcl.getApplicationSelector(namespace, info.ApplicationKind, info.ApplicationName); err != nil {
+		return err
+	}
+
+	hpa, err := convertToK8sHorizontalPodAutoscaler(info)
+	if err != nil {
+		return err
+	}
+
+	_, err = kcl.cli.AutoscalingV2().HorizontalPodAutoscalers(namespace).Create(context.Background(), &hpa, metav1.CreateOptions{})
+
+	return err
+}
+
+// UpdateHorizontalPodAutoscaler updates the target, the replicas and the metrics of a horizontal pod autoscaler of
+// a given namespace.
+func (kcl *KubeClient) UpdateHorizontalPodAutoscaler(namespace string, info models.K8sHorizontalPodAutoscaler) error {
+	if err := kcl.checkNamespaceAccess(namespace); err != nil {
+		return err
+	}
+
+	client := kcl.cli.AutoscalingV2().HorizontalPodAutoscalers(namespace)
+
+	existing, err := client.Get(context.Background(), info.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if _, err := kcl.getApplicationSelector(namespace, info.ApplicationKind, info.ApplicationName); err != nil {
+		return err
+	}
+
+	hpa, err := convertToK8sHorizontalPodAutoscaler(info)
+	if err != nil {
+		return err
+	}
+
+	existing.Labels = hpa.Labels
+	mergeHorizontalPodAutoscalerSpec(&existing.Spec, hpa.Spec)
+
+	_, err = client.Update(context.Background(), existing, metav1.UpdateOptions{})
+
+	return err
+}
+
+// mergeHorizontalPodAutoscalerSpec replaces the fields of the spec managed by K8sHorizontalPodAutoscaler, the behavior
+// and the metrics that are not parsed, such as the Object and ContainerResource metrics, are kept
+func mergeHorizontalPodAutoscalerSpec(spec *autoscalingv2.HorizontalPodAutoscalerSpec, managed autoscalingv2.HorizontalPodAutoscalerSpec) {
+	spec.ScaleTargetRef = managed.ScaleTargetRef
+	spec.MinReplicas = managed.MinReplicas
+	spec.MaxReplicas = managed.MaxReplicas
+	spec.Metrics = append(managed.Metrics, slices.DeleteFunc(spec.Metrics, isManagedMetric)...)
+}
+
+// isManagedMetric returns true for the metrics parsed by parseHorizontalPodAutoscaler
+func isManagedMetric(metric autoscalingv2.MetricSpec) bool {
+	switch metric.Type {
+	case autoscalingv2.ResourceMetricSourceType:
+		return metric.Resource != nil
+	case autoscalingv2.PodsMetricSourceType:
+		return metric.Pods != nil
+	case autoscalingv2.ExternalMetricSourceType:
+		return metric.External != nil
+	}
+
+	return false
+}
+
+// DeleteHorizontalPodAutoscalers deletes the provided list of horizontal pod autoscalers in their namespace.
+// The autoscalers that no longer exist are ignored.
+func (kcl *KubeClient) DeleteHorizontalPodAutoscalers(reqs models.K8sHorizontalPodAutoscalerDeleteRequests) error {
+	for namespace := range reqs {
+		if err := kcl.checkNamespaceAccess(namespace); err != nil {
+			return err
+		}
+	}
+
+	for namespace := range reqs {
+		for _, name := range reqs[namespace] {
+			err := kcl.cli.AutoscalingV2().HorizontalPodAutoscalers(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("unable to delete the horizontal pod autoscaler %s/%s: %w", namespace, name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestHorizontalPodAutoscaler(namespace string) models.K8sHorizontalPodAutoscaler {
+	return models.K8sHorizontalPodAutoscaler{
+		Name:            "web",
+		Namespace:       namespace,
+		ApplicationKind: "Deployment",
+		ApplicationName: "web",
+		MinReplicas:     2,
+		MaxReplicas:     5,
+		Metrics: []models.K8sHorizontalPodAutoscalerMetric{
+			{Type: "Resource", Name: "cpu", TargetType: "Utilization", AverageUtilization: 80},
+			{Type: "Resource", Name: "memory", TargetType: "AverageValue", Value: "512Mi"},
+			{Type: "Pods", Name: "requests_per_second", TargetType: "AverageValue", Value: "100"},
+		},
+	}
+}
+
+func TestHorizontalPodAutoscalers(t *testing.T) {
+	kcl := &KubeClient{cli: kfake.NewSimpleClientset(), instanceID: "test", isKubeAdmin: true}
+
+	hpa := newTestHorizontalPodAutoscaler("default")
+
+	// the application must exist
+	err := kcl.CreateHorizontalPodAutoscaler("default", hpa)
+	require.True(t, k8serrors.IsNotFound(err))
+
+	_, err = kcl.cli.AppsV1().Deployments("default").Create(context.Background(), createTestDeployment("web", "default", 1), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, kcl.CreateHorizontalPodAutoscaler("default", hpa))
+
+	got, err := kcl.GetHorizontalPodAutoscaler("default", "web")
+	require.NoError(t, err)
+	require.Equal(t, "Deployment", got.ApplicationKind)
+	require.Equal(t, "web", got.ApplicationName)
+	require.EqualValues(t, 2, got.MinReplicas)
+	require.EqualValues(t, 5, got.MaxReplicas)
+	require.Equal(t, hpa.Metrics, got.Metrics)
+
+	// the fields that are not represented by the model are kept on update
+	native, err := kcl.cli.AutoscalingV2().HorizontalPodAutoscalers("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	stabilizationWindow := int32(300)
+	behavior := &autoscalingv2.HorizontalPodAutoscalerBehavior{
+		ScaleDown: &autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: &stabilizationWindow},
+	}
+	objectMetric := autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ObjectMetricSourceType,
+		Object: &autoscalingv2.ObjectMetricSource{
+			DescribedObject: autoscalingv2.CrossVersionObjectReference{Kind: "Ingress", Name: "web"},
+			Metric:          autoscalingv2.MetricIdentifier{Name: "requests"},
+		},
+	}
+	native.Spec.Behavior = behavior
+	native.Spec.Metrics = append(native.Spec.Metrics, objectMetric)
+	_, err = kcl.cli.AutoscalingV2().HorizontalPodAutoscalers("default").Update(context.Background(), native, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	hpa.MaxReplicas = 10
+	hpa.Metrics = hpa.Metrics[:1]
+	require.NoError(t, kcl.UpdateHorizontalPodAutoscaler("default", hpa))
+
+	native, err = kcl.cli.AutoscalingV2().HorizontalPodAutoscalers("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, behavior, native.Spec.Behavior)
+	require.Len(t, native.Spec.Metrics, 2)
+	require.Equal(t, objectMetric, native.Spec.Metrics[1])
+
+	hpas, err := kcl.GetHorizontalPodAutoscalers("")
+	require.NoError(t, err)
+	require.Len(t, hpas, 1)
+	require.EqualValues(t, 10, hpas[0].MaxReplicas)
+	require.Len(t, hpas[0].Metrics, 1)
+
+	require.NoError(t, kcl.DeleteHorizontalPodAutoscalers(models.K8sHorizontalPodAutoscalerDeleteRequests{"default": {"web", "missing"}}))
+
+	hpas, err = kcl.GetHorizontalPodAutoscalers("default")
+	require.NoError(t, err)
+	require.Empty(t, hpas)
+}
+
+func TestHorizontalPodAutoscalers_nonAdmin(t *testing.T) {
+	kcl := &KubeClient{cli: kfake.NewSimpleClientset(), instanceID: "test", isKubeAdmin: true}
+
+	for _, namespace := range []string{"team", "other"} {
+		_, err := kcl.cli.AppsV1().Deployments(namespace).Create(context.Background(), createTestDeployment("web", namespace, 1), metav1.CreateOptions{})
+		require.NoError(t, err)
+		require.NoError(t, kcl.CreateHorizontalPodAutoscaler(namespace, newTestHorizontalPodAutoscaler(namespace)))
+	}
+
+	kcl.SetIsKubeAdmin(false)
+	kcl.SetClientNonAdminNamespaces([]string{"team"})
+
+	hpas, err := kcl.GetHorizontalPodAutoscalers("")
+	require.NoError(t, err)
+	require.Len(t, hpas, 1)
+	require.Equal(t, "team", hpas[0].Namespace)
+
+	_, err = kcl.GetHorizontalPodAutoscaler("other", "web")
+	require.True(t, k8serrors.IsForbidden(err))
+
+	err = kcl.UpdateHorizontalPodAutoscaler("other", newTestHorizontalPodAutoscaler("other"))
+	require.True(t, k8serrors.IsForbidden(err))
+
+	err = kcl.DeleteHorizontalPodAutoscalers(models.K8sHorizontalPodAutoscalerDeleteRequests{"team": {"web"}, "other": {"web"}})
+	require.True(t, k8serrors.IsForbidden(err))
+
+	// nothing is deleted when one of the namespaces is not accessible
+	_, err = kcl.GetHorizontalPodAutoscaler("team", "web")
+	require.NoError(t, err)
+}
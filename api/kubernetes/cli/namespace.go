@@ -121,7 +121,22 @@ func (kcl *KubeClient) GetNamespace(name string) (portainer.K8sNamespaceInfo, er
 		return portainer.K8sNamespaceInfo{}, err
 	}
 
-	return parseNamespace(namespace), nil
+	info := parseNamespace(namespace)
+
+	// The preset is optional, e.g. the user may not be allowed to list the network policies
+	preset, ingressControllerNamespace, err := kcl.getNamespaceNetworkPolicyPreset(name)
+	if err != nil {
+		log.Warn().
+			Str("context", "GetNamespace").
+			Str("namespace", name).
+			Err(err).
+			Msg("Failed to get the network policy preset of the namespace")
+	} else {
+		info.NetworkPolicyPreset = preset
+		info.IngressControllerNamespace = ingressControllerNamespace
+	}
+
+	return info, nil
 }
 
 // CreateNamespace creates a new namespace in a k8s endpoint.
@@ -156,6 +171,15 @@ func (kcl *KubeClient) CreateNamespace(info models.K8sNamespaceDetails) (*corev1
 		return nil, err
 	}
 
+	if err := kcl.reconcileNamespaceNetworkPolicy(info, portainerLabels); err != nil {
+		log.Error().
+			Err(err).
+			Str("context", "CreateNamespace").
+			Str("name", info.Name).
+			Msg("failed to reconcile the network policy of the namespace")
+		return nil, err
+	}
+
 	return namespace, nil
 }
 
@@ -192,6 +216,15 @@ func (kcl *KubeClient) UpdateNamespace(info models.K8sNamespaceDetails) (*corev1
 		return nil, err
 	}
 
+	if err := kcl.reconcileNamespaceNetworkPolicy(info, portainerLabels); err != nil {
+		log.Error().
+			Err(err).
+			Str("context", "UpdateNamespace").
+			Str("name", info.Name).
+			Msg("failed to reconcile the network policy of the namespace")
+		return nil, err
+	}
+
 	return updatedNamespace, nil
 }
 
@@ -0,0 +1,371 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	networkPolicyPresetLabel = "io.portainer.kubernetes.networkpolicy.preset"
+	// namespaceNamePolicyLabel is set by Kubernetes on every namespace, it selects a namespace by name
+	namespaceNamePolicyLabel = "kubernetes.io/metadata.name"
+)
+
+// GetNetworkPolicies gets the network policies of a given namespace, or of all the namespaces when the namespace is
+// empty. Non-admin users only get the policies of the namespaces they have access to.
+func (kcl *KubeClient) GetNetworkPolicies(namespace string) ([]models.K8sNetworkPolicy, error) {
+	if kcl.GetIsKubeAdmin() {
+		return kcl.fetchNetworkPolicies(namespace)
+	}
+
+	return kcl.fetchNetworkPoliciesForNonAdmin(namespace)
+}
+
+// fetchNetworkPoliciesForNonAdmin gets the network policies of the non-admin namespaces.
+func (kcl *KubeClient) fetchNetworkPoliciesForNonAdmin(namespace string) ([]models.K8sNetworkPolicy, error) {
+	nonAdminNamespaces := kcl.GetClientNonAdminNamespaces()
+
+	log.Debug().
+		Strs("non_admin_namespaces", nonAdminNamespaces).
+		Msg("fetching network policies for non-admin user")
+
+	if len(nonAdminNamespaces) == 0 {
+		return nil, nil
+	}
+
+	policies, err := kcl.fetchNetworkPolicies(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	nonAdminNamespaceSet := kcl.buildNonAdminNamespacesMap()
+	results := make([]models.K8sNetworkPolicy, 0)
+	for _, policy := range policies {
+		if _, ok := nonAdminNamespaceSet[policy.Namespace]; ok {
+			results = append(results, policy)
+		}
+	}
+
+	return results, nil
+}
+
+// fetchNetworkPolicies gets the network policies of a given namespace.
+func (kcl *KubeClient) fetchNetworkPolicies(namespace string) ([]models.K8sNetworkPolicy, error) {
+	policies, err := kcl.cli.NetworkingV1().NetworkPolicies(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.K8sNetworkPolicy, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		results = append(results, parseNetworkPolicy(policy))
+	}
+
+	return results, nil
+}
+
+// GetNetworkPolicy gets a network policy in a given namespace.
+func (kcl *KubeClient) GetNetworkPolicy(namespace, name string) (models.K8sNetworkPolicy, error) {
+	if err := kcl.checkNamespaceAccess(namespace); err != nil {
+		return models.K8sNetworkPolicy{}, err
+	}
+
+	policy, err := kcl.cli.NetworkingV1().NetworkPolicies(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return models.K8sNetworkPolicy{}, err
+	}
+
+	return parseNetworkPolicy(*policy), nil
+}
+
+// parseNetworkPolicy converts a k8s native network policy object to a Portainer K8sNetworkPolicy object.
+func parseNetworkPolicy(policy netv1.NetworkPolicy) models.K8sNetworkPolicy {
+	result := models.K8sNetworkPolicy{
+		Name:         policy.Name,
+		UID:          string(policy.UID),
+		Namespace:    policy.Namespace,
+		Labels:       policy.Labels,
+		CreationDate: policy.CreationTimestamp.Time,
+		PodSelector:  policy.Spec.PodSelector,
+		PolicyTypes:  make([]string, 0, len(policy.Spec.PolicyTypes)),
+		Ingress:      make([]models.K8sNetworkPolicyRule, 0, len(policy.Spec.Ingress)),
+		Egress:       make([]models.K8sNetworkPolicyRule, 0, len(policy.Spec.Egress)),
+		Preset:       policy.Labels[networkPolicyPresetLabel],
+	}
+
+	for _, policyType := range policy.Spec.PolicyTypes {
+		result.PolicyTypes = append(result.PolicyTypes, string(policyType))
+	}
+
+	for _, rule := range policy.Spec.Ingress {
+		result.Ingress = append(result.Ingress, parseNetworkPolicyRule(rule.From, rule.Ports))
+	}
+
+	for _, rule := range policy.Spec.Egress {
+		result.Egress = append(result.Egress, parseNetworkPolicyRule(rule.To, rule.Ports))
+	}
+
+	return result
+}
+
+func parseNetworkPolicyRule(peers []netv1.NetworkPolicyPeer, ports []netv1.NetworkPolicyPort) models.K8sNetworkPolicyRule {
+	rule := models.K8sNetworkPolicyRule{
+		Peers: make([]models.K8sNetworkPolicyPeer, 0, len(peers)),
+		Ports: make([]models.K8sNetworkPolicyPort, 0, len(ports)),
+	}
+
+	for _, peer := range peers {
+		p := models.K8sNetworkPolicyPeer{
+			PodSelector:       peer.PodSelector,
+			NamespaceSelector: peer.NamespaceSelector,
+		}
+
+		if peer.IPBlock != nil {
+			p.IPBlock = &models.K8sNetworkPolicyIPBlock{CIDR: peer.IPBlock.CIDR, Except: peer.IPBlock.Except}
+		}
+
+		rule.Peers = append(rule.Peers, p)
+	}
+
+	for _, port := range ports {
+		p := models.K8sNetworkPolicyPort{}
+
+		if port.Protocol != nil {
+			p.Protocol = string(*port.Protocol)
+		}
+
+		if port.Port != nil {
+			p.Port = port.Port.String()
+		}
+
+		if port.EndPort != nil {
+			p.EndPort = *port.EndPort
+		}
+
+		rule.Ports = append(rule.Ports, p)
+	}
+
+	return rule
+}
+
+// convertToK8sNetworkPolicy converts a K8sNetworkPolicy object back to a k8s native network policy object.
+func convertToK8sNetworkPolicy(info models.K8sNetworkPolicy) netv1.NetworkPolicy {
+	policy := netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      info.Name,
+			Namespace: info.Namespace,
+			Labels:    info.Labels,
+		},
+		Spec: netv1.NetworkPolicySpec{
+			PodSelector: info.PodSelector,
+		},
+	}
+
+	for _, policyType := range info.PolicyTypes {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, netv1.PolicyType(policyType))
+	}
+
+	for _, rule := range info.Ingress {
+		peers, ports := convertToK8sNetworkPolicyRule(rule)
+		policy.Spec.Ingress = append(policy.Spec.Ingress, netv1.NetworkPolicyIngressRule{From: peers, Ports: ports})
+	}
+
+	for _, rule := range info.Egress {
+		peers, ports := convertToK8sNetworkPolicyRule(rule)
+		policy.Spec.Egress = append(policy.Spec.Egress, netv1.NetworkPolicyEgressRule{To: peers, Ports: ports})
+	}
+
+	return policy
+}
+
+func convertToK8sNetworkPolicyRule(rule models.K8sNetworkPolicyRule) ([]netv1.NetworkPolicyPeer, []netv1.NetworkPolicyPort) {
+	var peers []netv1.NetworkPolicyPeer
+	for _, p := range rule.Peers {
+		peer := netv1.NetworkPolicyPeer{
+			PodSelector:       p.PodSelector,
+			NamespaceSelector: p.NamespaceSelector,
+		}
+
+		if p.IPBlock != nil {
+			peer.IPBlock = &netv1.IPBlock{CIDR: p.IPBlock.CIDR, Except: p.IPBlock.Except}
+		}
+
+		peers = append(peers, peer)
+	}
+
+	var ports []netv1.NetworkPolicyPort
+	for _, p := range rule.Ports {
+		port := netv1.NetworkPolicyPort{}
+
+		if p.Protocol != "" {
+			protocol := corev1.Protocol(p.Protocol)
+			port.Protocol = &protocol
+		}
+
+		if p.Port != "" {
+			value := intstr.Parse(p.Port)
+			port.Port = &value
+		}
+
+		if p.EndPort != 0 {
+			endPort := p.EndPort
+			port.EndPort = &endPort
+		}
+
+		ports = append(ports, port)
+	}
+
+	return peers, ports
+}
+
+// checkNetworkPolicyWriteAccess only allows the admin users to write the network policies of a namespace, a policy
+// written by a non-admin user could open or remove the isolation of the namespace.
+func (kcl *KubeClient) checkNetworkPolicyWriteAccess(namespace string) error {
+	if kcl.GetIsKubeAdmin() {
+		return nil
+	}
+
+	return k8serrors.NewForbidden(schema.GroupResource{Group: netv1.GroupName, Resource: "networkpolicies"}, namespace, errors.New("only administrators can manage the network policies"))
+}
+
+// CreateNetworkPolicy creates a network policy in a given namespace. Only the admin users can create network policies.
+func (kcl *KubeClient) CreateNetworkPolicy(namespace string, info models.K8sNetworkPolicy) error {
+	if err := kcl.checkNetworkPolicyWriteAccess(namespace); err != nil {
+		return err
+	}
+
+	policy := convertToK8sNetworkPolicy(info)
+	_, err := kcl.cli.NetworkingV1().NetworkPolicies(namespace).Create(context.Background(), &policy, metav1.CreateOptions{})
+
+	return err
+}
+
+// DeleteNetworkPolicies deletes the provided list of network policies in their namespace.
+// The policies that no longer exist are ignored. Only the admin users can delete network policies.
+func (kcl *KubeClient) DeleteNetworkPolicies(reqs models.K8sNetworkPolicyDeleteRequests) error {
+	for namespace := range reqs {
+		if err := kcl.checkNetworkPolicyWriteAccess(namespace); err != nil {
+			return err
+		}
+	}
+
+	for namespace := range reqs {
+		for _, name := range reqs[namespace] {
+			err := kcl.cli.NetworkingV1().NetworkPolicies(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("unable to delete the network policy %s/%s: %w", namespace, name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// namespaceNetworkPolicyName is the name of the network policy isolating a namespace created by Portainer
+func namespaceNetworkPolicyName(namespace string) string {
+	return "portainer-np-" + namespace
+}
+
+// buildNamespaceNetworkPolicy builds the network policy implementing the network policy preset of a namespace. The
+// policy selects all the pods of the namespace and only allows the traffic coming from the namespace itself, and from
+// the ingress controller namespace for the allow-from-ingress-controller-namespace preset.
+func buildNamespaceNetworkPolicy(info models.K8sNamespaceDetails, portainerLabels map[string]string) netv1.NetworkPolicy {
+	labels := map[string]string{networkPolicyPresetLabel: *info.NetworkPolicyPreset}
+	for k, v := range portainerLabels {
+		labels[k] = v
+	}
+
+	peers := []netv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+	if *info.NetworkPolicyPreset == models.K8sNetworkPolicyPresetAllowFromIngressControllerNamespace {
+		peers = append(peers, netv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{namespaceNamePolicyLabel: info.IngressControllerNamespace},
+			},
+		})
+	}
+
+	return netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespaceNetworkPolicyName(info.Name),
+			Namespace: info.Name,
+			Labels:    labels,
+		},
+		Spec: netv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeIngress},
+			Ingress:     []netv1.NetworkPolicyIngressRule{{From: peers}},
+		},
+	}
+}
+
+// reconcileNamespaceNetworkPolicy creates, updates or deletes the network policy of a namespace so that it matches
+// its network policy preset. The policy is left unchanged when the preset is not provided.
+func (kcl *KubeClient) reconcileNamespaceNetworkPolicy(info models.K8sNamespaceDetails, portainerLabels map[string]string) error {
+	if info.NetworkPolicyPreset == nil {
+		return nil
+	}
+
+	client := kcl.cli.NetworkingV1().NetworkPolicies(info.Name)
+	name := namespaceNetworkPolicyName(info.Name)
+
+	if *info.NetworkPolicyPreset == models.K8sNetworkPolicyPresetNone {
+		if err := client.Delete(context.Background(), name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	policy := buildNamespaceNetworkPolicy(info, portainerLabels)
+
+	existing, err := client.Get(context.Background(), name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		_, err = client.Create(context.Background(), &policy, metav1.CreateOptions{})
+
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	existing.Labels = policy.Labels
+	existing.Spec = policy.Spec
+	_, err = client.Update(context.Background(), existing, metav1.UpdateOptions{})
+
+	return err
+}
+
+// getNamespaceNetworkPolicyPreset returns the network policy preset of a namespace and the ingress controller namespace
+// it allows, both are empty when the namespace is not isolated.
+func (kcl *KubeClient) getNamespaceNetworkPolicyPreset(namespace string) (string, string, error) {
+	policy, err := kcl.cli.NetworkingV1().NetworkPolicies(namespace).Get(context.Background(), namespaceNetworkPolicyName(namespace), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return models.K8sNetworkPolicyPresetNone, "", nil
+	} else if err != nil {
+		return "", "", err
+	}
+
+	preset := policy.Labels[networkPolicyPresetLabel]
+	if preset != models.K8sNetworkPolicyPresetAllowFromIngressControllerNamespace {
+		return preset, "", nil
+	}
+
+	for _, rule := range policy.Spec.Ingress {
+		for _, peer := range rule.From {
+			if peer.NamespaceSelector != nil {
+				return preset, peer.NamespaceSelector.MatchLabels[namespaceNamePolicyLabel], nil
+			}
+		}
+	}
+
+	return preset, "", nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestNetworkPolicies(t *testing.T) {
+	kcl := &KubeClient{cli: kfake.NewSimpleClientset(), instanceID: "test", isKubeAdmin: true}
+
+	policy := models.K8sNetworkPolicy{
+		Name:        "allow-web",
+		Namespace:   "team",
+		PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		PolicyTypes: []string{"Ingress", "Egress"},
+		Ingress: []models.K8sNetworkPolicyRule{{
+			Peers: []models.K8sNetworkPolicyPeer{
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "front"}}},
+				{IPBlock: &models.K8sNetworkPolicyIPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+			},
+			Ports: []models.K8sNetworkPolicyPort{{Protocol: "TCP", Port: "8080"}, {Port: "http"}},
+		}},
+		Egress: []models.K8sNetworkPolicyRule{{
+			Peers: []models.K8sNetworkPolicyPeer{},
+			Ports: []models.K8sNetworkPolicyPort{{Protocol: "UDP", Port: "53"}},
+		}},
+	}
+
+	require.NoError(t, kcl.CreateNetworkPolicy("team", policy))
+
+	got, err := kcl.GetNetworkPolicy("team", "allow-web")
+	require.NoError(t, err)
+	require.Equal(t, policy.PodSelector, got.PodSelector)
+	require.Equal(t, policy.PolicyTypes, got.PolicyTypes)
+	require.Equal(t, policy.Ingress, got.Ingress)
+	require.Equal(t, policy.Egress, got.Egress)
+
+	kcl.SetIsKubeAdmin(false)
+	kcl.SetClientNonAdminNamespaces([]string{"other"})
+
+	policies, err := kcl.GetNetworkPolicies("")
+	require.NoError(t, err)
+	require.Empty(t, policies)
+
+	_, err = kcl.GetNetworkPolicy("team", "allow-web")
+	require.True(t, k8serrors.IsForbidden(err))
+
+	err = kcl.DeleteNetworkPolicies(models.K8sNetworkPolicyDeleteRequests{"team": {"allow-web"}})
+	require.True(t, k8serrors.IsForbidden(err))
+
+	kcl.SetClientNonAdminNamespaces([]string{"team"})
+
+	policies, err = kcl.GetNetworkPolicies("")
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+
+	err = kcl.CreateNetworkPolicy("team", models.K8sNetworkPolicy{Name: "allow-all", Namespace: "team"})
+	require.True(t, k8serrors.IsForbidden(err))
+
+	err = kcl.DeleteNetworkPolicies(models.K8sNetworkPolicyDeleteRequests{"team": {"allow-web"}})
+	require.True(t, k8serrors.IsForbidden(err))
+
+	kcl.SetIsKubeAdmin(true)
+
+	require.NoError(t, kcl.DeleteNetworkPolicies(models.K8sNetworkPolicyDeleteRequests{"team": {"allow-web", "missing"}}))
+
+	policies, err = kcl.GetNetworkPolicies("team")
+	require.NoError(t, err)
+	require.Empty(t, policies)
+}
+
+func TestNamespaceNetworkPolicyPreset(t *testing.T) {
+	kcl := &KubeClient{cli: kfake.NewSimpleClientset(), instanceID: "test", isKubeAdmin: true}
+
+	preset := func(preset string) *string { return &preset }
+
+	info := models.K8sNamespaceDetails{
+		Name:                "team",
+		ResourceQuota:       &models.K8sResourceQuota{},
+		NetworkPolicyPreset: preset(models.K8sNetworkPolicyPresetDenyFromOtherNamespaces),
+	}
+
+	_, err := kcl.CreateNamespace(info)
+	require.NoError(t, err)
+
+	policy, err := kcl.GetNetworkPolicy("team", namespaceNetworkPolicyName("team"))
+	require.NoError(t, err)
+	require.Equal(t, models.K8sNetworkPolicyPresetDenyFromOtherNamespaces, policy.Preset)
+	require.Equal(t, []string{"Ingress"}, policy.PolicyTypes)
+	require.Len(t, policy.Ingress, 1)
+	require.Equal(t, []models.K8sNetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}, policy.Ingress[0].Peers)
+
+	info.NetworkPolicyPreset = preset(models.K8sNetworkPolicyPresetAllowFromIngressControllerNamespace)
+	info.IngressControllerNamespace = "ingress-nginx"
+	_, err = kcl.UpdateNamespace(info)
+	require.NoError(t, err)
+
+	// an update without the preset keeps the isolation
+	info.NetworkPolicyPreset = nil
+	_, err = kcl.UpdateNamespace(info)
+	require.NoError(t, err)
+
+	namespace, err := kcl.GetNamespace("team")
+	require.NoError(t, err)
+	require.Equal(t, models.K8sNetworkPolicyPresetAllowFromIngressControllerNamespace, namespace.NetworkPolicyPreset)
+	require.Equal(t, "ingress-nginx", namespace.IngressControllerNamespace)
+
+	policy, err = kcl.GetNetworkPolicy("team", namespaceNetworkPolicyName("team"))
+	require.NoError(t, err)
+	require.Equal(t, models.K8sNetworkPolicyPresetAllowFromIngressControllerNamespace, policy.Preset)
+	require.Len(t, policy.Ingress[0].Peers, 2)
+	require.Equal(t, map[string]string{namespaceNamePolicyLabel: "ingress-nginx"}, policy.Ingress[0].Peers[1].NamespaceSelector.MatchLabels)
+
+	info.NetworkPolicyPreset = preset(models.K8sNetworkPolicyPresetNone)
+	_, err = kcl.UpdateNamespace(info)
+	require.NoError(t, err)
+
+	namespace, err = kcl.GetNamespace("team")
+	require.NoError(t, err)
+	require.Empty(t, namespace.NetworkPolicyPreset)
+
+	_, err = kcl.cli.NetworkingV1().NetworkPolicies("team").Get(context.Background(), namespaceNetworkPolicyName("team"), metav1.GetOptions{})
+	require.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestGetNamespace_networkPolicyForbidden(t *testing.T) {
+	cli := kfake.NewSimpleClientset(&core.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team"}})
+	cli.PrependReactor("get", "networkpolicies", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewForbidden(schema.GroupResource{Group: "networking.k8s.io", Resource: "networkpolicies"}, "", nil)
+	})
+
+	kcl := &KubeClient{cli: cli, instanceID: "test"}
+
+	namespace, err := kcl.GetNamespace("team")
+	require.NoError(t, err)
+	require.Equal(t, "team", namespace.Name)
+	require.Empty(t, namespace.NetworkPolicyPreset)
+}
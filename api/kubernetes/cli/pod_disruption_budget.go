@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/rs/zerolog/log"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	podDisruptionBudgetApplicationKindLabel = "io.portainer.kubernetes.application.kind"
+	podDisruptionBudgetApplicationNameLabel = "io.portainer.kubernetes.application.name"
+)
+
+// GetPodDisruptionBudgets gets the pod disruption budgets of a given namespace, or of all the namespaces when the
+// namespace is empty. Non-admin users only get the budgets of the namespaces they have access to.
+func (kcl *KubeClient) GetPodDisruptionBudgets(namespace string) ([]models.K8sPodDisruptionBudget, error) {
+	if kcl.GetIsKubeAdmin() {
+		return kcl.fetchPodDisruptionBudgets(namespace)
+	}
+
+	return kcl.fetchPodDisruptionBudgetsForNonAdmin(namespace)
+}
+
+// fetchPodDisruptionBudgetsForNonAdmin gets the pod disruption budgets of the non-admin namespaces.
+func (kcl *KubeClient) fetchPodDisruptionBudgetsForNonAdmin(namespace string) ([]models.K8sPodDisruptionBudget, error) {
+	nonAdminNamespaces := kcl.GetClientNonAdminNamespaces()
+
+	log.Debug().
+		Strs("non_admin_namespaces", nonAdminNamespaces).
+		Msg("fetching pod disruption budgets for non-admin user")
+
+	if len(nonAdminNamespaces) == 0 {
+		return nil, nil
+	}
+
+	pdbs, err := kcl.fetchPodDisruptionBudgets(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	nonAdminNamespaceSet := kcl.buildNonAdminNamespacesMap()
+	results := make([]models.K8sPodDisruptionBudget, 0)
+	for _, pdb := range pdbs {
+		if _, ok := nonAdminNamespaceSet[pdb.Namespace]; ok {
+			results = append(results, pdb)
+		}
+	}
+
+	return results, nil
+}
+
+// fetchPodDisruptionBudgets gets the pod disruption budgets of a given namespace.
+func (kcl *KubeClient) fetchPodDisruptionBudgets(namespace string) ([]models.K8sPodDisruptionBudget, error) {
+	pdbs, err := kcl.cli.PolicyV1().PodDisruptionBudgets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.K8sPodDisruptionBudget, 0, len(pdbs.Items))
+	for _, pdb := range pdbs.Items {
+		results = append(results, parsePodDisruptionBudget(pdb))
+	}
+
+	return results, nil
+}
+
+// GetPodDisruptionBudget gets a pod disruption budget in a given namespace.
+func (kcl *KubeClient) GetPodDisruptionBudget(namespace, name string) (models.K8sPodDisruptionBudget, error) {
+	if err := kcl.checkNamespaceAccess(namespace); err != nil {
+		return models.K8sPodDisruptionBudget{}, err
+	}
+
+	pdb, err := kcl.cli.PolicyV1().PodDisruptionBudgets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return models.K8sPodDisruptionBudget{}, err
+	}
+
+	return parsePodDisruptionBudget(*pdb), nil
+}
+
+// parsePodDisruptionBudget converts a k8s native pod disruption budget object to a Portainer K8sPodDisruptionBudget
+// object. The application is read from the labels set by Portainer.
+func parsePodDisruptionBudget(pdb policyv1.PodDisruptionBudget) models.K8sPodDisruptionBudget {
+	result := models.K8sPodDisruptionBudget{
+		Name:               pdb.Name,
+		UID:                string(pdb.UID),
+		Namespace:          pdb.Namespace,
+		Labels:             pdb.Labels,
+		CreationDate:       pdb.CreationTimestamp.Time,
+		ApplicationKind:    pdb.Labels[podDisruptionBudgetApplicationKindLabel],
+		ApplicationName:    pdb.Labels[podDisruptionBudgetApplicationNameLabel],
+		CurrentHealthy:     pdb.Status.CurrentHealthy,
+		DesiredHealthy:     pdb.Status.DesiredHealthy,
+		ExpectedPods:       pdb.Status.ExpectedPods,
+		DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+	}
+
+	if pdb.Spec.Selector != nil {
+		result.Selector = pdb.Spec.Selector.MatchLabels
+	}
+
+	if pdb.Spec.MinAvailable != nil {
+		result.MinAvailable = pdb.Spec.MinAvailable.String()
+	}
+
+	if pdb.Spec.MaxUnavailable != nil {
+		result.MaxUnavailable = pdb.Spec.MaxUnavailable.String()
+	}
+
+	return result
+}
+
+// convertToK8sPodDisruptionBudget converts a K8sPodDisruptionBudget object back to a k8s native pod disruption
+// budget object selecting the pods of the application.
+func convertToK8sPodDisruptionBudget(info models.K8sPodDisruptionBudget, selector *metav1.LabelSelector) policyv1.PodDisruptionBudget {
+	labels := make(map[string]string, len(info.Labels)+2)
+	for k, v := range info.Labels {
+		labels[k] = v
+	}
+	labels[podDisruptionBudgetApplicationKindLabel] = info.ApplicationKind
+	labels[podDisruptionBudgetApplicationNameLabel] = info.ApplicationName
+
+	pdb := policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      info.Name,
+			Namespace: info.Namespace,
+			Labels:    labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: selector,
+		},
+	}
+
+	if info.MinAvailable != "" {
+		minAvailable := intstr.Parse(info.MinAvailable)
+		pdb.Spec.MinAvailable = &minAvailable
+	}
+
+	if info.MaxUnavailable != "" {
+		maxUnavailable := intstr.Parse(info.MaxUnavailable)
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+
+	return pdb
+}
+
+// CreatePodDisruptionBudget creates a pod disruption budget selecting the pods of an application of a given
+// namespace. The application must exist.
+func (kcl *KubeClient) CreatePodDisruptionBudget(namespace string, info models.K8sPodDisruptionBudget) error {
+	if err := kcl.checkNamespaceAccess(namespace); err != nil {
+		return err
+	}
+
+	selector, err := kcl.getApplicationSelector(namespace, info.ApplicationKind, info.ApplicationName)
+	if err != nil {
+		return err
+	}
+
+	pdb := convertToK8sPodDisruptionBudget(info, selector)
+	_, err = kcl.cli.PolicyV1().PodDisruptionBudgets(namespace).Create(context.Background(), &pdb, metav1.CreateOptions{})
+
+	return err
+}
+
+// UpdatePodDisruptionBudget updates the application and the availability requirements of a pod disruption budget
+// of a given namespace.
+func (kcl *KubeClient) UpdatePodDisruptionBudget(namespace string, info models.K8sPodDisruptionBudget) error {
+	if err := kcl.checkNamespaceAccess(namespace); err != nil {
+		return err
+	}
+
+	client := kcl.cli.PolicyV1().PodDisruptionBudgets(namespace)
+
+	existing, err := client.Get(context.Background(), info.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	selector, err := kcl.getApplicationSelector(namespace, info.ApplicationKind, info.ApplicationName)
+	if err != nil {
+		return err
+	}
+
+	pdb := convertToK8sPodDisruptionBudget(info, selector)
+	// the fields of the spec that are not represented by K8sPodDisruptionBudget, such as the unhealthy pod eviction
+	// policy, are kept
+	existing.Labels = pdb.Labels
+	existing.Spec.Selector = pdb.Spec.Selector
+	existing.Spec.MinAvailable = pdb.Spec.MinAvailable
+	existing.Spec.MaxUnavailable = pdb.Spec.MaxUnavailable
+
+	_, err = client.Update(context.Background(), existing, metav1.UpdateOptions{})
+
+	return err
+}
+
+// DeletePodDisruptionBudgets deletes the provided list of pod disruption budgets in their namespace.
+// The budgets that no longer exist are ignored.
+func (kcl *KubeClient) DeletePodDisruptionBudgets(reqs models.K8sPodDisruptionBudgetDeleteRequests) error {
+	for namespace := range reqs {
+		if err := kcl.checkNamespaceAccess(namespace); err != nil {
+			return err
+		}
+	}
+
+	for namespace := range reqs {
+		for _, name := range reqs[namespace] {
+			err := kcl.cli.PolicyV1().PodDisruptionBudgets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("unable to delete the pod disruption budget %s/%s: %w", namespace, name, err)
+			}
+		}
+	}
+
+	return nil
+}
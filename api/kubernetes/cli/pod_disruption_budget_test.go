@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	models "github.com/portainer/portainer/api/http/models/kubernetes"
+	"github.com/stretchr/testify/require"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodDisruptionBudgets(t *testing.T) {
+	kcl := &KubeClient{cli: kfake.NewSimpleClientset(), instanceID: "test", isKubeAdmin: true}
+
+	pdb := models.K8sPodDisruptionBudget{
+		Name:            "db",
+		Namespace:       "default",
+		ApplicationKind: "StatefulSet",
+		ApplicationName: "db",
+		MinAvailable:    "2",
+	}
+
+	err := kcl.CreatePodDisruptionBudget("default", pdb)
+	require.True(t, k8serrors.IsNotFound(err))
+
+	statefulSet := createTestStatefulSet("db", "default", 3)
+	_, err = kcl.cli.AppsV1().StatefulSets("default").Create(context.Background(), statefulSet, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, kcl.CreatePodDisruptionBudget("default", pdb))
+
+	got, err := kcl.GetPodDisruptionBudget("default", "db")
+	require.NoError(t, err)
+	require.Equal(t, "StatefulSet", got.ApplicationKind)
+	require.Equal(t, "db", got.ApplicationName)
+	require.Equal(t, statefulSet.Spec.Selector.MatchLabels, got.Selector)
+	require.Equal(t, "2", got.MinAvailable)
+	require.Empty(t, got.MaxUnavailable)
+
+	// the unhealthy pod eviction policy is not represented by the model and is kept on update
+	native, err := kcl.cli.PolicyV1().PodDisruptionBudgets("default").Get(context.Background(), "db", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	alwaysAllow := policyv1.AlwaysAllow
+	native.Spec.UnhealthyPodEvictionPolicy = &alwaysAllow
+	_, err = kcl.cli.PolicyV1().PodDisruptionBudgets("default").Update(context.Background(), native, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	pdb.MinAvailable = ""
+	pdb.MaxUnavailable = "50%"
+	require.NoError(t, kcl.UpdatePodDisruptionBudget("default", pdb))
+
+	native, err = kcl.cli.PolicyV1().PodDisruptionBudgets("default").Get(context.Background(), "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, &alwaysAllow, native.Spec.UnhealthyPodEvictionPolicy)
+	require.Nil(t, native.Spec.MinAvailable)
+
+	pdbs, err := kcl.GetPodDisruptionBudgets("")
+	require.NoError(t, err)
+	require.Len(t, pdbs, 1)
+	require.Empty(t, pdbs[0].MinAvailable)
+	require.Equal(t, "50%", pdbs[0].MaxUnavailable)
+
+	kcl.SetIsKubeAdmin(false)
+	kcl.SetClientNonAdminNamespaces([]string{"team"})
+
+	pdbs, err = kcl.GetPodDisruptionBudgets("")
+	require.NoError(t, err)
+	require.Empty(t, pdbs)
+
+	err = kcl.DeletePodDisruptionBudgets(models.K8sPodDisruptionBudgetDeleteRequests{"default": {"db"}})
+	require.True(t, k8serrors.IsForbidden(err))
+
+	kcl.SetIsKubeAdmin(true)
+	require.NoError(t, kcl.DeletePodDisruptionBudgets(models.K8sPodDisruptionBudgetDeleteRequests{"default": {"db"}}))
+
+	_, err = kcl.GetPodDisruptionBudget("default", "db")
+	require.True(t, k8serrors.IsNotFound(err))
+}
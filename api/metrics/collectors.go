@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"strconv"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// TunnelLister lists the reverse tunnels that are currently open
+type TunnelLister interface {
+	ActiveTunnels() map[portainer.EndpointID]portainer.TunnelDetails
+}
+
+// DatabaseSizer returns the size of the database file
+type DatabaseSizer interface {
+	GetDatabaseFileSize() (int64, error)
+}
+
+var (
+	tunnelsOpenDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "chisel", "tunnels_open"),
+		"Number of reverse tunnels currently open.",
+		nil, nil,
+	)
+
+	tunnelLastActivityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "chisel", "tunnel_last_activity_timestamp_seconds"),
+		"Unix timestamp of the last activity of each open reverse tunnel.",
+		[]string{"endpoint_id", "status"}, nil,
+	)
+
+	databaseSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "database", "size_bytes"),
+		"Size of the database file.",
+		nil, nil,
+	)
+)
+
+type tunnelCollector struct {
+	tunnels TunnelLister
+}
+
+func (c *tunnelCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tunnelsOpenDesc
+	ch <- tunnelLastActivityDesc
+}
+
+func (c *tunnelCollector) Collect(ch chan<- prometheus.Metric) {
+	tunnels := c.tunnels.ActiveTunnels()
+
+	ch <- prometheus.MustNewConstMetric(tunnelsOpenDesc, prometheus.GaugeValue, float64(len(tunnels)))
+
+	for endpointID, tunnel := range tunnels {
+		ch <- prometheus.MustNewConstMetric(
+			tunnelLastActivityDesc,
+			prometheus.GaugeValue,
+			float64(tunnel.LastActivity.Unix()),
+			strconv.Itoa(int(endpointID)),
+			tunnel.Status,
+		)
+	}
+}
+
+type databaseCollector struct {
+	connection DatabaseSizer
+}
+
+func (c *databaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- databaseSizeDesc
+}
+
+func (c *databaseCollector) Collect(ch chan<- prometheus.Metric) {
+	size, err := c.connection.GetDatabaseFileSize()
+	if err != nil {
+		log.Warn().Err(err).Msg("unable to retrieve the database file size")
+
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(databaseSizeDesc, prometheus.GaugeValue, float64(size))
+}
+
+// RegisterTunnelCollector exposes the state of the reverse tunnels, it must be called once at startup
+func RegisterTunnelCollector(tunnels TunnelLister) error {
+	return registry.Register(&tunnelCollector{tunnels: tunnels})
+}
+
+// RegisterDatabaseCollector exposes the size of the database file, it must be called once at startup
+func RegisterDatabaseCollector(connection DatabaseSizer) error {
+	return registry.Register(&databaseCollector{connection: connection})
+}
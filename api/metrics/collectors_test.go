@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+type testTunnels map[portainer.EndpointID]portainer.TunnelDetails
+
+func (t testTunnels) ActiveTunnels() map[portainer.EndpointID]portainer.TunnelDetails {
+	return t
+}
+
+type testDatabase struct {
+	size int64
+	err  error
+}
+
+func (d testDatabase) GetDatabaseFileSize() (int64, error) {
+	return d.size, d.err
+}
+
+func collect(t *testing.T, c prometheus.Collector) map[string][]*dto.Metric {
+	ch := make(chan prometheus.Metric, 16)
+	c.Collect(ch)
+	close(ch)
+
+	metrics := make(map[string][]*dto.Metric)
+	for m := range ch {
+		var metric dto.Metric
+		require.NoError(t, m.Write(&metric))
+
+		metrics[m.Desc().String()] = append(metrics[m.Desc().String()], &metric)
+	}
+
+	return metrics
+}
+
+func TestTunnelCollector(t *testing.T) {
+	t.Parallel()
+
+	metrics := collect(t, &tunnelCollector{tunnels: testTunnels{
+		1: {Status: portainer.EdgeAgentManagementRequired, LastActivity: time.Unix(1700000000, 0)},
+		2: {Status: portainer.EdgeAgentIdle, LastActivity: time.Unix(1700000060, 0)},
+	}})
+
+	require.Len(t, metrics[tunnelsOpenDesc.String()], 1)
+	require.InDelta(t, 2, metrics[tunnelsOpenDesc.String()][0].GetGauge().GetValue(), 0)
+	require.Len(t, metrics[tunnelLastActivityDesc.String()], 2)
+}
+
+func TestDatabaseCollector(t *testing.T) {
+	t.Parallel()
+
+	metrics := collect(t, &databaseCollector{connection: testDatabase{size: 1024}})
+	require.Len(t, metrics[databaseSizeDesc.String()], 1)
+	require.InDelta(t, 1024, metrics[databaseSizeDesc.String()][0].GetGauge().GetValue(), 0)
+
+	metrics = collect(t, &databaseCollector{connection: testDatabase{err: errors.New("unavailable")}})
+	require.Empty(t, metrics)
+}
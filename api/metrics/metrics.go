@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "portainer"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the HTTP requests served by Portainer, per route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+
+	snapshotDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "snapshot",
+		Name:      "duration_seconds",
+		Help:      "Duration of the environment snapshots, per environment.",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"endpoint_id"})
+
+	snapshotFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "snapshot",
+		Name:      "failures_total",
+		Help:      "Number of failed environment snapshots, per environment.",
+	}, []string{"endpoint_id"})
+
+	autoUpdateRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "stack_autoupdate",
+		Name:      "runs_total",
+		Help:      "Number of stack auto-update jobs that were run, per stack.",
+	}, []string{"stack_id"})
+
+	autoUpdateFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "stack_autoupdate",
+		Name:      "failures_total",
+		Help:      "Number of stack auto-update jobs that failed, per stack.",
+	}, []string{"stack_id"})
+
+	edgeCheckins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "edge",
+		Name:      "checkins_total",
+		Help:      "Number of Edge agent check-ins, per environment.",
+	}, []string{"endpoint_id"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		snapshotDuration,
+		snapshotFailures,
+		autoUpdateRuns,
+		autoUpdateFailures,
+		edgeCheckins,
+	)
+}
+
+// Handler returns the HTTP handler exposing the registered metrics in the Prometheus/OpenMetrics format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// ObserveHTTPRequest records the duration of an HTTP request served for the given route
+func ObserveHTTPRequest(method, route string, statusCode int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(statusCode)).Observe(duration.Seconds())
+}
+
+// ObserveSnapshot records the duration and the outcome of an environment snapshot
+func ObserveSnapshot(endpointID portainer.EndpointID, duration time.Duration, err error) {
+	label := strconv.Itoa(int(endpointID))
+
+	snapshotDuration.WithLabelValues(label).Observe(duration.Seconds())
+
+	if err != nil {
+		snapshotFailures.WithLabelValues(label).Inc()
+	}
+}
+
+// ObserveAutoUpdate records a run of the auto-update job of a stack and whether it failed
+func ObserveAutoUpdate(stackID portainer.StackID, err error) {
+	label := strconv.Itoa(int(stackID))
+
+	autoUpdateRuns.WithLabelValues(label).Inc()
+
+	if err != nil {
+		autoUpdateFailures.WithLabelValues(label).Inc()
+	}
+}
+
+// IncEdgeCheckin records a check-in of the Edge agent of the given environment
+func IncEdgeCheckin(endpointID portainer.EndpointID) {
+	edgeCheckins.WithLabelValues(strconv.Itoa(int(endpointID))).Inc()
+}
+
+// DeleteEndpoint removes the series of a deleted environment
+func DeleteEndpoint(endpointID portainer.EndpointID) {
+	label := strconv.Itoa(int(endpointID))
+
+	snapshotDuration.DeleteLabelValues(label)
+	snapshotFailures.DeleteLabelValues(label)
+	edgeCheckins.DeleteLabelValues(label)
+}
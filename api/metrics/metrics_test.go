@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func endpointLabels(t *testing.T, c prometheus.Collector) []string {
+	var labels []string
+	for _, metrics := range collect(t, c) {
+		for _, metric := range metrics {
+			labels = append(labels, metric.GetLabel()[0].GetValue())
+		}
+	}
+
+	return labels
+}
+
+func TestDeleteEndpoint(t *testing.T) {
+	ObserveSnapshot(1001, time.Second, errors.New("failed"))
+	ObserveSnapshot(1002, time.Second, errors.New("failed"))
+	IncEdgeCheckin(1001)
+
+	DeleteEndpoint(1001)
+
+	require.NotContains(t, endpointLabels(t, snapshotDuration), "1001")
+	require.NotContains(t, endpointLabels(t, snapshotFailures), "1001")
+	require.NotContains(t, endpointLabels(t, edgeCheckins), "1001")
+	require.Contains(t, endpointLabels(t, snapshotFailures), "1002")
+}
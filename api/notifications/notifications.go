@@ -0,0 +1,332 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/crypto"
+	"github.com/portainer/portainer/api/dataservices"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	queueSize         = 256
+	maxAttempts       = 5
+	initialBackoff    = 2 * time.Second
+	sendTimeout       = 30 * time.Second
+	deliveryRetention = 30 * 24 * time.Hour
+	secretKeyInfo     = "portainer notification channels"
+)
+
+var defaultService atomic.Pointer[Service]
+
+// Service delivers the notification events to the channels subscribed to them
+type Service struct {
+	dataStore   dataservices.DataStore
+	cipher      *crypto.SecretCipher
+	client      *http.Client
+	events      chan portainer.NotificationEvent
+	shutdownCtx context.Context
+	backoff     time.Duration
+	wg          sync.WaitGroup
+}
+
+// NewService creates a notification service and starts delivering the events it is notified of. The secrets of
+// the channels are encrypted with a key derived from secretKey, the secret key of the instance
+func NewService(shutdownCtx context.Context, dataStore dataservices.DataStore, secretKey []byte) (*Service, error) {
+	cipher, err := crypto.NewSecretCipher(secretKey, secretKeyInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	service := &Service{
+		dataStore:   dataStore,
+		cipher:      cipher,
+		client:      &http.Client{Timeout: sendTimeout},
+		events:      make(chan portainer.NotificationEvent, queueSize),
+		shutdownCtx: shutdownCtx,
+		backoff:     initialBackoff,
+	}
+
+	go service.run()
+
+	return service, nil
+}
+
+// SetDefault makes service the one receiving the events sent with Notify
+func SetDefault(service *Service) {
+	defaultService.Store(service)
+}
+
+// Notify sends the event to the default notification service, it is a no-op until one is set
+func Notify(event portainer.NotificationEvent) {
+	if service := defaultService.Load(); service != nil {
+		service.Notify(event)
+	}
+}
+
+// Notify queues the event for delivery without blocking, the event is dropped if the queue is full
+func (service *Service) Notify(event portainer.NotificationEvent) {
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+
+	select {
+	case service.events <- event:
+	default:
+		log.Warn().Str("event", string(event.Type)).Msg("notification queue is full, dropping the event")
+	}
+}
+
+// Send makes a single attempt to deliver the event to the channel
+func (service *Service) Send(ctx context.Context, channel *portainer.NotificationChannel, event portainer.NotificationEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	switch channel.Type {
+	case portainer.NotificationChannelTypeWebhook:
+		if channel.Webhook == nil {
+			return errors.New("missing webhook configuration")
+		}
+
+		return sendWebhook(ctx, service.client, channel.Webhook, event)
+	case portainer.NotificationChannelTypeSlack:
+		if channel.Slack == nil {
+			return errors.New("missing Slack configuration")
+		}
+
+		return sendSlack(ctx, service.client, channel.Slack, event)
+	case portainer.NotificationChannelTypeSMTP:
+		if channel.SMTP == nil {
+			return errors.New("missing SMTP configuration")
+		}
+
+		return sendSMTP(ctx, channel.SMTP, event)
+	}
+
+	return fmt.Errorf("unsupported notification channel type: %s", channel.Type)
+}
+
+// CreateChannel encrypts the secrets of the channel and saves it, the channel is left unchanged apart from its ID
+func (service *Service) CreateChannel(channel *portainer.NotificationChannel) error {
+	encrypted, err := service.encryptChannel(channel)
+	if err != nil {
+		return err
+	}
+
+	if err := service.dataStore.NotificationChannel().Create(encrypted); err != nil {
+		return err
+	}
+
+	channel.ID = encrypted.ID
+
+	return nil
+}
+
+// UpdateChannel encrypts the secrets of the channel and saves it
+func (service *Service) UpdateChannel(channel *portainer.NotificationChannel) error {
+	encrypted, err := service.encryptChannel(channel)
+	if err != nil {
+		return err
+	}
+
+	return service.dataStore.NotificationChannel().Update(channel.ID, encrypted)
+}
+
+// ReadChannel returns the channel with its secrets decrypted
+func (service *Service) ReadChannel(id portainer.NotificationChannelID) (*portainer.NotificationChannel, error) {
+	channel, err := service.dataStore.NotificationChannel().Read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return channel, service.decryptChannel(channel)
+}
+
+// ReadChannels returns the channels matching the predicates with their secrets decrypted
+func (service *Service) ReadChannels(predicates ...func(portainer.NotificationChannel) bool) ([]portainer.NotificationChannel, error) {
+	channels, err := service.dataStore.NotificationChannel().ReadAll(predicates...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range channels {
+		if err := service.decryptChannel(&channels[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return channels, nil
+}
+
+func (service *Service) encryptChannel(channel *portainer.NotificationChannel) (*portainer.NotificationChannel, error) {
+	encrypted := *channel
+
+	// The configurations are copied so that the channel of the caller keeps its secrets in clear
+	if channel.Webhook != nil {
+		webhook := *channel.Webhook
+		encrypted.Webhook = &webhook
+	}
+
+	if channel.Slack != nil {
+		slack := *channel.Slack
+		encrypted.Slack = &slack
+	}
+
+	if channel.SMTP != nil {
+		smtp := *channel.SMTP
+		encrypted.SMTP = &smtp
+	}
+
+	for _, field := range secretFields(&encrypted) {
+		var err error
+		if *field, err = service.cipher.Encrypt(*field); err != nil {
+			return nil, err
+		}
+	}
+
+	return &encrypted, nil
+}
+
+func (service *Service) decryptChannel(channel *portainer.NotificationChannel) error {
+	for _, field := range secretFields(channel) {
+		var err error
+		if *field, err = service.cipher.Decrypt(*field); err != nil {
+			return fmt.Errorf("unable to decrypt the notification channel %d: %w", channel.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// secretFields returns the webhook secret, the Slack webhook URL and the SMTP password of the channel
+func secretFields(channel *portainer.NotificationChannel) []*string {
+	var fields []*string
+
+	if channel.Webhook != nil {
+		fields = append(fields, &channel.Webhook.Secret)
+	}
+
+	if channel.Slack != nil {
+		fields = append(fields, &channel.Slack.URL)
+	}
+
+	if channel.SMTP != nil {
+		fields = append(fields, &channel.SMTP.Password)
+	}
+
+	return fields
+}
+
+// Prune removes the deliveries older than the retention period from the history
+func (service *Service) Prune() error {
+	return service.dataStore.NotificationDelivery().DeleteBefore(time.Now().Add(-deliveryRetention).Unix())
+}
+
+func (service *Service) run() {
+	for {
+		select {
+		case event := <-service.events:
+			service.dispatch(event)
+		case <-service.shutdownCtx.Done():
+			service.wg.Wait()
+
+			return
+		}
+	}
+}
+
+func (service *Service) dispatch(event portainer.NotificationEvent) {
+	channels, err := service.ReadChannels(func(channel portainer.NotificationChannel) bool {
+		return channel.Enabled && slices.Contains(channel.Events, event.Type)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("unable to retrieve the notification channels from the database")
+
+		return
+	}
+
+	for _, channel := range channels {
+		service.wg.Add(1)
+
+		go func() {
+			defer service.wg.Done()
+
+			service.deliver(&channel, event)
+		}()
+	}
+}
+
+// deliver sends the event to the channel, retrying with an exponential backoff, and records the outcome
+func (service *Service) deliver(channel *portainer.NotificationChannel, event portainer.NotificationEvent) {
+	delivery := &portainer.NotificationDelivery{
+		ChannelID: channel.ID,
+		Event:     event,
+		Status:    portainer.NotificationDeliveryFailure,
+	}
+
+	backoff := service.backoff
+
+	for {
+		delivery.Attempts++
+
+		err := service.Send(service.shutdownCtx, channel, event)
+		if err == nil {
+			delivery.Status = portainer.NotificationDeliverySuccess
+			delivery.Error = ""
+
+			break
+		}
+
+		delivery.Error = err.Error()
+
+		log.Debug().
+			Err(err).
+			Int("channel_id", int(channel.ID)).
+			Str("event", string(event.Type)).
+			Int("attempt", delivery.Attempts).
+			Msg("unable to deliver the notification")
+
+		if delivery.Attempts >= maxAttempts || !service.wait(backoff) {
+			break
+		}
+
+		backoff *= 2
+	}
+
+	delivery.Timestamp = time.Now().Unix()
+
+	if delivery.Status == portainer.NotificationDeliveryFailure {
+		log.Warn().
+			Int("channel_id", int(channel.ID)).
+			Str("channel", channel.Name).
+			Str("event", string(event.Type)).
+			Str("error", delivery.Error).
+			Msg("unable to deliver the notification")
+	}
+
+	if err := service.dataStore.NotificationDelivery().Create(delivery); err != nil {
+		log.Error().Err(err).Msg("unable to persist the notification delivery")
+	}
+}
+
+// wait pauses for the given duration, it returns false if the service was shut down in the meantime
+func (service *Service) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-service.shutdownCtx.Done():
+		return false
+	}
+}
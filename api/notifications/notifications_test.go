@@ -0,0 +1,77 @@
+package notifications_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/notifications"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	var subscribed, unsubscribed, unsigned atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subscribed" {
+			unsubscribed.Add(1)
+
+			return
+		}
+
+		// The secret stored encrypted is decrypted to sign the payload
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(notifications.SignatureHeader) != notifications.Sign("s3cr3t", body) {
+			unsigned.Add(1)
+		}
+
+		// Fail the first attempt to exercise the retry
+		if subscribed.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service, err := notifications.NewService(ctx, store, []byte("secret key"))
+	require.NoError(t, err)
+
+	for _, channel := range []portainer.NotificationChannel{
+		{Name: "subscribed", Enabled: true, Events: []portainer.NotificationEventType{portainer.NotificationEventEdgeStackError}},
+		{Name: "unsubscribed", Enabled: true, Events: []portainer.NotificationEventType{portainer.NotificationEventEndpointDown}},
+		{Name: "disabled", Enabled: false, Events: []portainer.NotificationEventType{portainer.NotificationEventEdgeStackError}},
+	} {
+		channel.Type = portainer.NotificationChannelTypeWebhook
+		channel.Webhook = &portainer.WebhookNotificationConfig{URL: srv.URL + "/" + channel.Name, Secret: "s3cr3t"}
+		require.NoError(t, service.CreateChannel(&channel))
+	}
+
+	service.Notify(portainer.NotificationEvent{Type: portainer.NotificationEventEdgeStackError, EdgeStackID: 2})
+
+	var deliveries []portainer.NotificationDelivery
+
+	require.Eventually(t, func() bool {
+		var err error
+		deliveries, err = store.NotificationDelivery().ReadAll()
+
+		return err == nil && len(deliveries) == 1
+	}, 10*time.Second, 50*time.Millisecond)
+
+	require.Equal(t, portainer.NotificationDeliverySuccess, deliveries[0].Status)
+	require.Equal(t, 2, deliveries[0].Attempts)
+	require.Equal(t, portainer.EdgeStackID(2), deliveries[0].Event.EdgeStackID)
+	require.NotZero(t, deliveries[0].Event.Timestamp)
+	require.Zero(t, unsubscribed.Load())
+	require.Zero(t, unsigned.Load())
+}
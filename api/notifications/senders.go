@@ -0,0 +1,168 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+const (
+	// EventHeader is the header holding the type of the event posted to a webhook channel
+	EventHeader = "X-Portainer-Event"
+	// SignatureHeader is the header holding the HMAC-SHA256 signature of the payload posted to a webhook channel
+	SignatureHeader = "X-Portainer-Signature"
+)
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// Sign returns the signature of a webhook payload, as sent in the SignatureHeader header
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func sendWebhook(ctx context.Context, client *http.Client, config *portainer.WebhookNotificationConfig, event portainer.NotificationEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{EventHeader: string(event.Type)}
+	if config.Secret != "" {
+		headers[SignatureHeader] = Sign(config.Secret, payload)
+	}
+
+	return post(ctx, client, config.URL, payload, headers)
+}
+
+func sendSlack(ctx context.Context, client *http.Client, config *portainer.SlackNotificationConfig, event portainer.NotificationEvent) error {
+	payload, err := json.Marshal(slackPayload{Text: fmt.Sprintf("*[Portainer] %s*\n%s", event.Type, event.Message)})
+	if err != nil {
+		return err
+	}
+
+	return post(ctx, client, config.URL, payload, nil)
+}
+
+func post(ctx context.Context, client *http.Client, url string, payload []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// sendSMTP delivers the mail with a connection bounded by the deadline of ctx and closed when ctx is done
+func sendSMTP(ctx context.Context, config *portainer.SMTPNotificationConfig, event portainer.NotificationEvent) error {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(config.Host, strconv.Itoa(config.Port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: config.Host}); err != nil {
+			return err
+		}
+	}
+
+	if config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", config.Username, config.Password, config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(config.From); err != nil {
+		return err
+	}
+
+	for _, to := range config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(buildMail(config, event)); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func buildMail(config *portainer.SMTPNotificationConfig, event portainer.NotificationEvent) []byte {
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [Portainer] %s\r\n", event.Type)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Unix(event.Timestamp, 0).UTC().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(event.Message)
+	msg.WriteString("\r\n")
+
+	return msg.Bytes()
+}
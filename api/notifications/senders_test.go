@@ -0,0 +1,107 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendWebhook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		require.Equal(t, string(portainer.NotificationEventEndpointDown), r.Header.Get(EventHeader))
+		require.Equal(t, Sign("s3cr3t", body), r.Header.Get(SignatureHeader))
+
+		var event portainer.NotificationEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		require.Equal(t, portainer.EndpointID(3), event.EndpointID)
+	}))
+	defer srv.Close()
+
+	service := &Service{client: http.DefaultClient}
+
+	channel := &portainer.NotificationChannel{
+		Type:    portainer.NotificationChannelTypeWebhook,
+		Webhook: &portainer.WebhookNotificationConfig{URL: srv.URL, Secret: "s3cr3t"},
+	}
+
+	err := service.Send(context.Background(), channel, portainer.NotificationEvent{Type: portainer.NotificationEventEndpointDown, EndpointID: 3})
+	require.NoError(t, err)
+}
+
+func TestSendSlack(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload slackPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		require.Equal(t, "*[Portainer] backup.failed*\ndisk full", payload.Text)
+
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid_payload"))
+	}))
+	defer srv.Close()
+
+	service := &Service{client: http.DefaultClient}
+
+	channel := &portainer.NotificationChannel{
+		Type:  portainer.NotificationChannelTypeSlack,
+		Slack: &portainer.SlackNotificationConfig{URL: srv.URL},
+	}
+
+	err := service.Send(context.Background(), channel, portainer.NotificationEvent{Type: portainer.NotificationEventBackupFailed, Message: "disk full"})
+	require.ErrorContains(t, err, "unexpected response status 400: invalid_payload")
+}
+
+func TestBuildMail(t *testing.T) {
+	config := &portainer.SMTPNotificationConfig{From: "portainer@example.com", To: []string{"a@example.com", "b@example.com"}}
+
+	mail := string(buildMail(config, portainer.NotificationEvent{Type: portainer.NotificationEventBackupCompleted, Message: "Backup completed"}))
+
+	require.Contains(t, mail, "To: a@example.com, b@example.com\r\n")
+	require.Contains(t, mail, "Subject: [Portainer] backup.completed\r\n")
+	require.Contains(t, mail, "\r\n\r\nBackup completed\r\n")
+}
+
+func TestSendSMTPHonoursTheContext(t *testing.T) {
+	// A server accepting the connection without ever greeting the client
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	config := &portainer.SMTPNotificationConfig{Host: host, Port: portNumber, From: "portainer@example.com", To: []string{"ops@example.com"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = sendSMTP(ctx, config, portainer.NotificationEvent{Type: portainer.NotificationEventTest})
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
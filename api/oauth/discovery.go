@@ -0,0 +1,88 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/pkg/errors"
+	"github.com/segmentio/encoding/json"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// DiscoveryDocument represents the subset of the OpenID Connect provider metadata used by Portainer
+type DiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// Discover retrieves the OpenID Connect provider metadata of the issuer
+func Discover(ctx context.Context, issuerURL string) (*DiscoveryDocument, error) {
+	issuerURL = strings.TrimSuffix(issuerURL, "/")
+
+	var doc DiscoveryDocument
+	if err := getJSON(ctx, issuerURL+discoveryPath, &doc); err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve the OpenID Connect discovery document")
+	}
+
+	if strings.TrimSuffix(doc.Issuer, "/") != issuerURL {
+		return nil, fmt.Errorf("the issuer of the discovery document %q does not match %q", doc.Issuer, issuerURL)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, errors.New("the discovery document does not provide the authorization and token endpoints")
+	}
+
+	return &doc, nil
+}
+
+// ApplyDiscovery sets the endpoints of the settings from the discovery document, the logout URL is only set
+// when none is configured
+func ApplyDiscovery(settings *portainer.OAuthSettings, doc *DiscoveryDocument) {
+	settings.AuthorizationURI = doc.AuthorizationEndpoint
+	settings.AccessTokenURI = doc.TokenEndpoint
+	settings.ResourceURI = doc.UserinfoEndpoint
+	settings.JWKSURI = doc.JWKSURI
+
+	if settings.LogoutURI == "" {
+		settings.LogoutURI = doc.EndSessionEndpoint
+	}
+}
+
+func getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,176 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func runOIDCServer(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	t.Helper()
+
+	var srv *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 srv.URL,
+			"authorization_endpoint": srv.URL + "/authorize",
+			"token_endpoint":         srv.URL + "/token",
+			"userinfo_endpoint":      srv.URL + "/userinfo",
+			"jwks_uri":               srv.URL + "/jwks",
+			"end_session_endpoint":   srv.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]any{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func signIdToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-key"
+
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return raw
+}
+
+func Test_Discover(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	srv := runOIDCServer(t, key)
+
+	doc, err := Discover(context.Background(), srv.URL+"/")
+	require.NoError(t, err)
+
+	settings := &portainer.OAuthSettings{LogoutURI: "https://example.com/logout"}
+	ApplyDiscovery(settings, doc)
+
+	assert.Equal(t, srv.URL+"/authorize", settings.AuthorizationURI)
+	assert.Equal(t, srv.URL+"/token", settings.AccessTokenURI)
+	assert.Equal(t, srv.URL+"/userinfo", settings.ResourceURI)
+	assert.Equal(t, srv.URL+"/jwks", settings.JWKSURI)
+	assert.Equal(t, "https://example.com/logout", settings.LogoutURI)
+
+	_, err = Discover(context.Background(), srv.URL+"/other")
+	assert.Error(t, err, "a missing discovery document should fail")
+}
+
+func Test_verifyIdToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	srv := runOIDCServer(t, key)
+
+	settings := &portainer.OAuthSettings{ClientID: "portainer", IssuerURL: srv.URL, JWKSURI: srv.URL + "/jwks"}
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   srv.URL,
+			"aud":   "portainer",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": "test-nonce",
+			"email": "john@example.com",
+		}
+	}
+
+	cache := newKeySetCache()
+
+	t.Run("valid id_token", func(t *testing.T) {
+		idToken, err := cache.verifyIdToken(context.Background(), signIdToken(t, key, claims()), settings, "test-nonce")
+		require.NoError(t, err)
+		assert.Equal(t, "john@example.com", idToken["email"])
+	})
+
+	for name, tc := range map[string]struct {
+		key    *rsa.PrivateKey
+		update func(jwt.MapClaims)
+	}{
+		"invalid signature": {key: otherKey},
+		"expired":           {update: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		"invalid audience":  {update: func(c jwt.MapClaims) { c["aud"] = "other-client" }},
+		"invalid issuer":    {update: func(c jwt.MapClaims) { c["iss"] = "https://example.com" }},
+		"invalid nonce":     {update: func(c jwt.MapClaims) { c["nonce"] = "other-nonce" }},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := claims()
+			if tc.update != nil {
+				tc.update(c)
+			}
+
+			signingKey := key
+			if tc.key != nil {
+				signingKey = tc.key
+			}
+
+			_, err := cache.verifyIdToken(context.Background(), signIdToken(t, signingKey, c), settings, "test-nonce")
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("unsigned id_token", func(t *testing.T) {
+		raw, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = cache.verifyIdToken(context.Background(), raw, settings, "test-nonce")
+		assert.Error(t, err)
+	})
+}
+
+func Test_LoginURL(t *testing.T) {
+	settings := &portainer.OAuthSettings{
+		ClientID:         "portainer",
+		AuthorizationURI: "https://example.com/authorize",
+		RedirectURI:      "https://portainer.example.com/",
+		Scopes:           "openid,email",
+	}
+
+	verifier := oauth2.GenerateVerifier()
+
+	loginURL, err := url.Parse(LoginURL(settings, "test-state", "test-nonce", verifier))
+	require.NoError(t, err)
+
+	query := loginURL.Query()
+	assert.Equal(t, "test-state", query.Get("state"))
+	assert.Equal(t, "test-nonce", query.Get("nonce"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, oauth2.S256ChallengeFromVerifier(verifier), query.Get("code_challenge"))
+	assert.Equal(t, "login", query.Get("prompt"))
+	assert.Equal(t, "portainer", query.Get("client_id"))
+}
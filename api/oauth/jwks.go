@@ -0,0 +1,211 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	// keySetTTL is the time the keys of a JSON Web Key Set are kept before being retrieved again
+	keySetTTL = time.Hour
+	// keySetMinRefreshInterval limits how often an unknown key identifier triggers a retrieval of the key set
+	keySetMinRefreshInterval = time.Minute
+)
+
+var idTokenSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type keySet struct {
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// keySetCache keeps the signing keys of the authorization servers, indexed by JWKS URL
+type keySetCache struct {
+	mu   sync.Mutex
+	sets map[string]*keySet
+}
+
+func newKeySetCache() *keySetCache {
+	return &keySetCache{sets: make(map[string]*keySet)}
+}
+
+// key returns the key of the set identified by kid, an empty kid is only accepted when the set has a single key
+func (cache *keySetCache) key(ctx context.Context, jwksURI, kid string) (crypto.PublicKey, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	set := cache.sets[jwksURI]
+	if set == nil || time.Since(set.fetchedAt) > keySetTTL {
+		if err := cache.refresh(ctx, jwksURI); err != nil {
+			return nil, err
+		}
+
+		set = cache.sets[jwksURI]
+	}
+
+	if key := set.find(kid); key != nil {
+		return key, nil
+	}
+
+	// the authorization server might have rotated its keys
+	if time.Since(set.fetchedAt) > keySetMinRefreshInterval {
+		if err := cache.refresh(ctx, jwksURI); err != nil {
+			return nil, err
+		}
+
+		if key := cache.sets[jwksURI].find(kid); key != nil {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no signing key found for the key identifier %q", kid)
+}
+
+func (cache *keySetCache) refresh(ctx context.Context, jwksURI string) error {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := getJSON(ctx, jwksURI, &doc); err != nil {
+		return errors.Wrap(err, "unable to retrieve the JSON Web Key Set")
+	}
+
+	set := &keySet{keys: make(map[string]crypto.PublicKey), fetchedAt: time.Now()}
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+
+		set.keys[jwk.Kid] = key
+	}
+
+	cache.sets[jwksURI] = set
+
+	return nil
+}
+
+func (set *keySet) find(kid string) crypto.PublicKey {
+	if kid == "" && len(set.keys) == 1 {
+		for _, key := range set.keys {
+			return key
+		}
+	}
+
+	return set.keys[kid]
+}
+
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// verifyIdToken verifies the signature of the ID token against the JSON Web Key Set of the settings and
+// returns its claims. The issuer is verified when the settings use discovery, the nonce when it is not empty.
+func (cache *keySetCache) verifyIdToken(ctx context.Context, rawIdToken string, configuration *portainer.OAuthSettings, nonce string) (map[string]any, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods(idTokenSigningMethods))
+
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(rawIdToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return cache.key(ctx, configuration.JWKSURI, kid)
+	}); err != nil {
+		return nil, errors.Wrap(err, "invalid id_token")
+	}
+
+	if !claims.VerifyAudience(configuration.ClientID, true) {
+		return nil, errors.New("invalid id_token: the audience does not match the client ID")
+	}
+
+	if configuration.IssuerURL != "" {
+		issuer, _ := claims["iss"].(string)
+		if strings.TrimSuffix(issuer, "/") != strings.TrimSuffix(configuration.IssuerURL, "/") {
+			return nil, errors.New("invalid id_token: the issuer does not match")
+		}
+	}
+
+	if nonce != "" {
+		if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+			return nil, errors.New("invalid id_token: the nonce does not match")
+		}
+	}
+
+	return claims, nil
+}
@@ -20,34 +20,53 @@ import (
 )
 
 // Service represents a service used to authenticate users against an authorization server
-type Service struct{}
+type Service struct {
+	keySets *keySetCache
+}
 
 // NewService returns a pointer to a new instance of this service
 func NewService() Service {
-	return Service{}
+	return Service{keySets: newKeySetCache()}
 }
 
 // Authenticate takes an access code and exchanges it for an access token from portainer OAuthSettings token environment(endpoint).
-// On success, it will then return the username and token expiry time associated to authenticated user by fetching this information
-// from the resource server and matching it with the user identifier setting.
-func (Service) Authenticate(code string, configuration *portainer.OAuthSettings) (string, error) {
-	token, err := GetOAuthToken(code, configuration)
+// On success, it will then return the username and groups associated to authenticated user by fetching this information
+// from the ID token and the resource server and matching it with the user identifier and group claim settings.
+// The code verifier is sent when the login used PKCE, the nonce is verified against the ID token when it is not empty.
+func (service Service) Authenticate(code string, configuration *portainer.OAuthSettings, codeVerifier, nonce string) (*portainer.OAuthUser, error) {
+	var opts []oauth2.AuthCodeOption
+	if codeVerifier != "" {
+		opts = append(opts, oauth2.VerifierOption(codeVerifier))
+	}
+
+	token, err := GetOAuthToken(code, configuration, opts...)
 	if err != nil {
 		log.Error().Err(err).Msg("failed retrieving oauth token")
 
-		return "", err
+		return nil, err
 	}
 
-	idToken, err := GetIdToken(token)
-	if err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var idToken map[string]any
+	if rawIdToken, ok := token.Extra("id_token").(string); ok && configuration.JWKSURI != "" {
+		if idToken, err = service.keySets.verifyIdToken(ctx, rawIdToken, configuration, nonce); err != nil {
+			log.Error().Err(err).Msg("failed verifying id_token")
+
+			return nil, err
+		}
+	} else if idToken, err = GetIdToken(token); err != nil {
 		log.Error().Err(err).Msg("failed parsing id_token")
 	}
 
-	resource, err := GetResource(token.AccessToken, configuration.ResourceURI)
-	if err != nil {
-		log.Error().Err(err).Msg("failed retrieving resource")
+	resource := make(map[string]any)
+	if configuration.ResourceURI != "" {
+		if resource, err = GetResource(token.AccessToken, configuration.ResourceURI); err != nil {
+			log.Error().Err(err).Msg("failed retrieving resource")
 
-		return "", err
+			return nil, err
+		}
 	}
 
 	maps.Copy(resource, idToken)
@@ -56,13 +75,35 @@ func (Service) Authenticate(code string, configuration *portainer.OAuthSettings)
 	if err != nil {
 		log.Error().Err(err).Msg("failed retrieving username")
 
-		return "", err
+		return nil, err
+	}
+
+	return &portainer.OAuthUser{
+		Username: username,
+		Groups:   GetGroups(resource, configuration.GroupClaim),
+	}, nil
+}
+
+// LoginURL returns the URL of the authorization server the users are redirected to in order to log in
+func LoginURL(configuration *portainer.OAuthSettings, state, nonce, codeVerifier string) string {
+	var opts []oauth2.AuthCodeOption
+	if nonce != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", nonce))
+	}
+
+	if codeVerifier != "" {
+		opts = append(opts, oauth2.S256ChallengeOption(codeVerifier))
+	}
+
+	// Control prompt=login param according to the SSO setting
+	if !configuration.SSO {
+		opts = append(opts, oauth2.SetAuthURLParam("prompt", "login"))
 	}
 
-	return username, nil
+	return buildConfig(configuration).AuthCodeURL(state, opts...)
 }
 
-func GetOAuthToken(code string, configuration *portainer.OAuthSettings) (*oauth2.Token, error) {
+func GetOAuthToken(code string, configuration *portainer.OAuthSettings, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
 	unescapedCode, err := url.QueryUnescape(code)
 	if err != nil {
 		return nil, err
@@ -73,7 +114,7 @@ func GetOAuthToken(code string, configuration *portainer.OAuthSettings) (*oauth2
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	return config.Exchange(ctx, unescapedCode)
+	return config.Exchange(ctx, unescapedCode, opts...)
 }
 
 // GetIdToken retrieves parsed id_token from the OAuth token response.
@@ -20,3 +20,28 @@ func GetUsername(datamap map[string]any, userIdentifier string) (string, error)
 
 	return "", errors.New("failed to extract username from oauth resource")
 }
+
+// GetGroups returns the values of the group claim, which can be a list or a single value
+func GetGroups(datamap map[string]any, groupClaim string) []string {
+	if groupClaim == "" {
+		return nil
+	}
+
+	switch value := datamap[groupClaim].(type) {
+	case string:
+		if value != "" {
+			return []string{value}
+		}
+	case []any:
+		groups := make([]string, 0, len(value))
+		for _, v := range value {
+			if group, ok := v.(string); ok && group != "" {
+				groups = append(groups, group)
+			}
+		}
+
+		return groups
+	}
+
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_getUsername(t *testing.T) {
@@ -71,3 +73,15 @@ func Test_getUsername(t *testing.T) {
 		}
 	})
 }
+
+func Test_getGroups(t *testing.T) {
+	datamap := map[string]any{
+		"group":  "developers",
+		"groups": []any{"developers", 1, "operators"},
+	}
+
+	assert.Equal(t, []string{"developers"}, GetGroups(datamap, "group"))
+	assert.Equal(t, []string{"developers", "operators"}, GetGroups(datamap, "groups"))
+	assert.Empty(t, GetGroups(datamap, "roles"))
+	assert.Empty(t, GetGroups(datamap, ""))
+}
@@ -118,7 +118,7 @@ func Test_Authenticate(t *testing.T) {
 		srv, config := oauthtest.RunOAuthServer(code, &portainer.OAuthSettings{})
 		defer srv.Close()
 
-		if _, err := authService.Authenticate(code, config); err == nil {
+		if _, err := authService.Authenticate(code, config, "", ""); err == nil {
 			t.Error("Authenticate should fail to extract username from resource if incorrect UserIdentifier provided")
 		}
 	})
@@ -128,15 +128,25 @@ func Test_Authenticate(t *testing.T) {
 		srv, config := oauthtest.RunOAuthServer(code, config)
 		defer srv.Close()
 
-		username, err := authService.Authenticate(code, config)
+		user, err := authService.Authenticate(code, config, "", "")
 		if err != nil {
-			t.Errorf("Authenticate should succeed to extract username from resource if correct UserIdentifier provided; UserIdentifier=%s", config.UserIdentifier)
+			t.Fatalf("Authenticate should succeed to extract username from resource if correct UserIdentifier provided; UserIdentifier=%s", config.UserIdentifier)
 		}
 
 		want := "test-oauth-user"
-		if username != want {
-			t.Errorf("Authenticate should return correct username; got=%s, want=%s", username, want)
+		if user.Username != want {
+			t.Errorf("Authenticate should return correct username; got=%s, want=%s", user.Username, want)
 		}
 	})
 
+	t.Run("should return the groups of the group claim", func(t *testing.T) {
+		config := &portainer.OAuthSettings{UserIdentifier: "username", GroupClaim: "groups"}
+		srv, config := oauthtest.RunOAuthServer(code, config)
+		defer srv.Close()
+
+		user, err := authService.Authenticate(code, config, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"testing"}, user.Groups)
+	})
+
 }
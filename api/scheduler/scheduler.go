@@ -102,6 +102,28 @@ func (s *Scheduler) StopJob(jobID string) error {
 // Returns job id that could be used to stop the given job.
 // When job run returns an error, that job won't be run again.
 func (s *Scheduler) StartJobEvery(duration time.Duration, job func() error) string {
+	return s.startJob(cron.Every(duration), job)
+}
+
+// StartCronJob schedules a new job run according to a cron expression in the standard 5 fields format.
+// Returns job id that could be used to stop the given job.
+func (s *Scheduler) StartCronJob(cronRule string, job func() error) (string, error) {
+	schedule, err := cron.ParseStandard(cronRule)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid cron rule %q", cronRule)
+	}
+
+	return s.startJob(schedule, job), nil
+}
+
+// ValidateCronRule checks that the cron expression can be used with StartCronJob
+func ValidateCronRule(cronRule string) error {
+	_, err := cron.ParseStandard(cronRule)
+
+	return err
+}
+
+func (s *Scheduler) startJob(schedule cron.Schedule, job func() error) string {
 	entryID := new(cron.EntryID)
 
 	cancelFn := func() {
@@ -134,7 +156,7 @@ func (s *Scheduler) StartJobEvery(duration time.Duration, job func() error) stri
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	*entryID = s.crontab.Schedule(cron.Every(duration), jobFn)
+	*entryID = s.crontab.Schedule(schedule, jobFn)
 	s.activeJobs[*entryID] = cancelFn
 
 	return strconv.Itoa(int(*entryID))
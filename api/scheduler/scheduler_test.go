@@ -151,3 +151,16 @@ func Test_StartJobEvery_Concurrently(t *testing.T) {
 
 	<-ctx.Done()
 }
+
+func Test_StartCronJob(t *testing.T) {
+	s := NewScheduler(context.Background())
+	defer requireNoShutdownErr(t, s.Shutdown)
+
+	_, err := s.StartCronJob("not a cron rule", func() error { return nil })
+	assert.Error(t, err)
+
+	jobID, err := s.StartCronJob("0 2 * * *", func() error { return nil })
+	assert.NoError(t, err)
+	assert.NotEmpty(t, jobID)
+	assert.NoError(t, s.StopJob(jobID))
+}
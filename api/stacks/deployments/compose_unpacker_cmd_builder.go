@@ -1,10 +1,12 @@
 package deployments
 
 import (
+	"errors"
 	"fmt"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
+	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/internal/registryutils"
 )
 
@@ -56,6 +58,11 @@ func (d *stackDeployer) buildUnpackerCmdForStack(stack *portainer.Stack, operati
 		return nil, fmt.Errorf("unknown stack operation %s", operation)
 	}
 
+	stack, err := d.resolveGitCredential(stack)
+	if err != nil {
+		return nil, err
+	}
+
 	registriesStrings := generateRegistriesStrings(opts.registries, d.dataStore)
 	envStrings := getEnv(stack.Env)
 
@@ -179,6 +186,30 @@ func buildSwarmStopCmd(stack *portainer.Stack, opts unpackerCmdBuilderOptions, r
 	return []string{UnpackerCmdSwarmUndeploy, "-k", stack.Name, opts.composeDestination}
 }
 
+// resolveGitCredential returns a copy of the stack holding the stored Git credential it references, if any
+func (d *stackDeployer) resolveGitCredential(stack *portainer.Stack) (*portainer.Stack, error) {
+	if stack.GitConfig == nil || stack.GitConfig.Authentication == nil || stack.GitConfig.Authentication.GitCredentialID == 0 {
+		return stack, nil
+	}
+
+	auth, err := d.gitService.ResolveAuthentication(stack.GitConfig.Authentication)
+	if err != nil {
+		return nil, err
+	}
+
+	if auth.AuthorizationType == gittypes.GitCredentialAuthType_SSH {
+		return nil, errors.New("SSH Git credentials are not supported by the unpacker")
+	}
+
+	gitConfig := *stack.GitConfig
+	gitConfig.Authentication = auth
+
+	resolved := *stack
+	resolved.GitConfig = &gitConfig
+
+	return &resolved, nil
+}
+
 func appendGitAuthIfNeeded(cmd []string, stack *portainer.Stack) []string {
 	if stack.GitConfig.Authentication == nil || stack.GitConfig.Authentication.Password == "" {
 		return cmd
@@ -3,6 +3,7 @@ package deployments
 import (
 	"cmp"
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 
@@ -10,9 +11,13 @@ import (
 	"github.com/portainer/portainer/api/agent"
 	"github.com/portainer/portainer/api/crypto"
 	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/filesystem"
 	"github.com/portainer/portainer/api/git/update"
 	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/metrics"
+	"github.com/portainer/portainer/api/notifications"
 	"github.com/portainer/portainer/api/scheduler"
+	"github.com/portainer/portainer/api/stacks/revisions"
 	"github.com/portainer/portainer/api/stacks/stackutils"
 
 	"github.com/pkg/errors"
@@ -34,6 +39,13 @@ var singleflightGroup = &singleflight.Group{}
 // RedeployWhenChanged pull and redeploy the stack when git repo changed
 // Stack will always be redeployed if force deployment is set to true
 func RedeployWhenChanged(stackID portainer.StackID, deployer StackDeployer, datastore dataservices.DataStore, gitService portainer.GitService) error {
+	err := redeployStackWhenChanged(stackID, deployer, datastore, gitService)
+	metrics.ObserveAutoUpdate(stackID, err)
+
+	return err
+}
+
+func redeployStackWhenChanged(stackID portainer.StackID, deployer StackDeployer, datastore dataservices.DataStore, gitService portainer.GitService) error {
 	stack, err := datastore.Stack().Read(stackID)
 	if dataservices.IsErrObjectNotFound(err) {
 		return scheduler.NewPermanentError(errors.WithMessagef(err, "failed to get the stack %v", stackID))
@@ -101,13 +113,30 @@ func redeployWhenChanged(stack *portainer.Stack, deployer StackDeployer, datasto
 					Str("author", author).
 					Int("endpoint_id", int(stack.EndpointID)).
 					Msg("webhook failed to redeploy a stack")
+
+				notifyRedeployFailure(stack, err)
 			}
 		}()
 
 		return nil
 	}
 
-	return redeployWhenChangedSecondStage(stack, deployer, datastore, gitService, user, endpoint)
+	if err := redeployWhenChangedSecondStage(stack, deployer, datastore, gitService, user, endpoint); err != nil {
+		notifyRedeployFailure(stack, err)
+
+		return err
+	}
+
+	return nil
+}
+
+func notifyRedeployFailure(stack *portainer.Stack, err error) {
+	notifications.Notify(portainer.NotificationEvent{
+		Type:       portainer.NotificationEventStackAutoUpdateFailed,
+		Message:    fmt.Sprintf("Auto-update of the stack %s failed: %s", stack.Name, err),
+		EndpointID: stack.EndpointID,
+		StackID:    stack.ID,
+	})
 }
 
 func redeployWhenChangedSecondStage(
@@ -119,8 +148,24 @@ func redeployWhenChangedSecondStage(
 	endpoint *portainer.Endpoint,
 ) error {
 	var gitCommitChangedOrForceUpdate bool
+	var backupPath string
 
 	if !stack.FromAppTemplate {
+		if err := revisions.Baseline(datastore, stack); err != nil {
+			log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to record the initial revision of the stack")
+		}
+
+		if isHealthGatedComposeStack(stack) {
+			backupPath = stack.ProjectPath + "-previous"
+			if err := filesystem.CopyDir(stack.ProjectPath, backupPath, false); err != nil {
+				log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to back up the stack, it will not be rolled back when unhealthy")
+
+				backupPath = ""
+			}
+
+			defer os.RemoveAll(backupPath)
+		}
+
 		updated, newHash, err := update.UpdateGitObject(gitService, fmt.Sprintf("stack:%d", stack.ID), stack.GitConfig, false, false, stack.ProjectPath)
 		if err != nil {
 			return err
@@ -150,9 +195,14 @@ func redeployWhenChangedSecondStage(
 			err = deployer.DeployRemoteComposeStack(stack, endpoint, registries, true, false)
 		} else {
 			err = deployer.DeployComposeStack(stack, endpoint, registries, true, false)
+			if err == nil {
+				err = deployer.WaitForHealthyComposeStack(stack, endpoint)
+			}
 		}
 
-		if err != nil {
+		if err != nil && backupPath != "" && errors.Is(err, ErrStackUnhealthy) {
+			return rollbackUnhealthyComposeStack(stack, deployer, datastore, endpoint, registries, backupPath, err)
+		} else if err != nil {
 			return errors.WithMessagef(err, "failed to deploy a docker compose stack %v", stack.ID)
 		}
 	case portainer.DockerSwarmStack:
@@ -175,14 +225,87 @@ func redeployWhenChangedSecondStage(
 	}
 
 	stack.Status = portainer.StackStatusActive
+	stack.DeploymentError = ""
 
 	if err := datastore.Stack().Update(stack.ID, stack); err != nil {
 		return errors.WithMessagef(err, "failed to update the stack %v", stack.ID)
 	}
 
+	if _, err := revisions.Record(datastore, stack, user.Username, "Automatic update from Git"); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to record the stack revision")
+	}
+
 	return nil
 }
 
+func isHealthGatedComposeStack(stack *portainer.Stack) bool {
+	return stack.Type == portainer.DockerComposeStack &&
+		!stackutils.IsRelativePathStack(stack) &&
+		stack.Option != nil && stack.Option.HealthCheck
+}
+
+// rollbackUnhealthyComposeStack restores the project folder of a Git stack whose services did not become healthy
+// after an automatic update and redeploys it. The hash of the new commit is kept so that the faulty commit is not
+// deployed again on every check, the stack is only updated again by the next commit
+func rollbackUnhealthyComposeStack(
+	stack *portainer.Stack,
+	deployer StackDeployer,
+	datastore dataservices.DataStore,
+	endpoint *portainer.Endpoint,
+	registries []portainer.Registry,
+	backupPath string,
+	deployErr error,
+) error {
+	if err := filesystem.MoveDirectory(backupPath, stack.ProjectPath, true); err != nil {
+		return errors.WithMessagef(err, "failed to restore the previous version of the stack %v", stack.ID)
+	}
+
+	if err := deployer.DeployComposeStack(stack, endpoint, registries, false, false); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to redeploy the previous version of the stack")
+	}
+
+	stack.DeploymentError = deployErr.Error()
+
+	if err := datastore.Stack().Update(stack.ID, stack); err != nil {
+		return errors.WithMessagef(err, "failed to update the stack %v", stack.ID)
+	}
+
+	return errors.WithMessagef(deployErr, "failed to deploy a docker compose stack %v, the previous version was redeployed", stack.ID)
+}
+
+// RedeployWithLatestImages redeploys a compose stack pulling the latest version of its images, with the
+// registries available to the author of the stack
+func RedeployWithLatestImages(stack *portainer.Stack, deployer StackDeployer, datastore dataservices.DataStore) error {
+	if stack.Type != portainer.DockerComposeStack {
+		return errors.Errorf("cannot redeploy stack, type %v is unsupported", stack.Type)
+	}
+
+	endpoint, err := datastore.Endpoint().Endpoint(stack.EndpointID)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to find the environment %v associated to the stack %v", stack.EndpointID, stack.ID)
+	}
+
+	author := cmp.Or(stack.UpdatedBy, stack.CreatedBy)
+
+	user, err := datastore.User().UserByUsername(author)
+	if err != nil {
+		return &StackAuthorMissingErr{int(stack.ID), author}
+	}
+
+	registries, err := getUserRegistries(datastore, user, endpoint.ID)
+	if err != nil {
+		return err
+	}
+
+	if stackutils.IsRelativePathStack(stack) {
+		err = deployer.DeployRemoteComposeStack(stack, endpoint, registries, true, false)
+	} else {
+		err = deployer.DeployComposeStack(stack, endpoint, registries, true, false)
+	}
+
+	return errors.WithMessagef(err, "failed to deploy a docker compose stack %v", stack.ID)
+}
+
 func getUserRegistries(datastore dataservices.DataStore, user *portainer.User, endpointID portainer.EndpointID) ([]portainer.Registry, error) {
 	registries, err := datastore.Registry().ReadAll()
 	if err != nil {
@@ -4,7 +4,10 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -86,6 +89,10 @@ func (s noopDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *porta
 	return nil
 }
 
+func (s noopDeployer) WaitForHealthyComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	return nil
+}
+
 func (s noopDeployer) DeployKubernetesStack(stack *portainer.Stack, endpoint *portainer.Endpoint, user *portainer.User) error {
 	return nil
 }
@@ -366,3 +373,87 @@ func Test_getUserRegistries(t *testing.T) {
 		assert.ElementsMatch(t, []portainer.Registry{registryReachableByUser, registryReachableByTeam}, registries)
 	})
 }
+
+type unhealthyDeployer struct {
+	noopDeployer
+
+	entryPoint string
+	contents   []string
+	waits      int
+}
+
+// DeployComposeStack simulates the deployment of a new commit
+func (d *unhealthyDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, forcePullImage, forceRecreate bool) error {
+	if len(d.contents) == 0 {
+		if err := os.WriteFile(d.entryPoint, []byte("new"), 0600); err != nil {
+			return err
+		}
+	}
+
+	content, err := os.ReadFile(d.entryPoint)
+	if err != nil {
+		return err
+	}
+
+	d.contents = append(d.contents, string(content))
+
+	return nil
+}
+
+// WaitForHealthyComposeStack simulates services that do not become healthy
+func (d *unhealthyDeployer) WaitForHealthyComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	d.waits++
+
+	return fmt.Errorf("%w: service web is unhealthy", ErrStackUnhealthy)
+}
+
+func Test_redeployWhenChanged_RollsBackUnhealthyStack(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, true)
+
+	tmpDir := filepath.Join(t.TempDir(), "stack")
+	require.NoError(t, os.MkdirAll(tmpDir, 0700))
+
+	entryPoint := filepath.Join(tmpDir, "docker-compose.yml")
+	require.NoError(t, os.WriteFile(entryPoint, []byte("previous"), 0600))
+
+	require.NoError(t, store.Endpoint().Create(&portainer.Endpoint{ID: 1}))
+
+	username := "user"
+	require.NoError(t, store.User().Create(&portainer.User{Username: username, Role: portainer.AdministratorRole}))
+
+	stack := portainer.Stack{
+		ID:          1,
+		EndpointID:  1,
+		Type:        portainer.DockerComposeStack,
+		EntryPoint:  "docker-compose.yml",
+		ProjectPath: tmpDir,
+		UpdatedBy:   username,
+		Option:      &portainer.StackOption{HealthCheck: true},
+		GitConfig: &gittypes.RepoConfig{
+			URL:           "url",
+			ReferenceName: "ref",
+			ConfigHash:    "oldHash",
+		},
+	}
+	require.NoError(t, store.Stack().Create(&stack))
+
+	deployer := &unhealthyDeployer{entryPoint: entryPoint}
+
+	err := RedeployWhenChanged(1, deployer, store, testhelpers.NewGitService(nil, "newHash"))
+	require.ErrorIs(t, err, ErrStackUnhealthy)
+
+	require.Equal(t, []string{"new", "previous"}, deployer.contents)
+	// The previous version is not waited for again
+	require.Equal(t, 1, deployer.waits)
+
+	content, err := os.ReadFile(entryPoint)
+	require.NoError(t, err)
+	require.Equal(t, "previous", string(content))
+	require.NoDirExists(t, tmpDir+"-previous")
+
+	updatedStack, err := store.Stack().Read(stack.ID)
+	require.NoError(t, err)
+	require.Contains(t, updatedStack.DeploymentError, "service web is unhealthy")
+	require.Equal(t, "newHash", updatedStack.GitConfig.ConfigHash)
+	require.True(t, updatedStack.Option.HealthCheck)
+}
@@ -2,19 +2,29 @@ package deployments
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/dataservices"
 	dockerclient "github.com/portainer/portainer/api/docker/client"
 	k "github.com/portainer/portainer/api/kubernetes"
+	"github.com/portainer/portainer/api/stacks/stackutils"
 
 	"github.com/pkg/errors"
 )
 
+// ErrStackUnhealthy is returned when the services of a stack deployed with a health check do not become healthy
+var ErrStackUnhealthy = errors.New("the stack is not healthy")
+
+const defaultHealthCheckTimeout = 5 * time.Minute
+
 type BaseStackDeployer interface {
 	DeploySwarmStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, prune, pullImage bool) error
 	DeployComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, forcePullImage, forceRecreate bool) error
+	// WaitForHealthyComposeStack waits for the services of a Compose stack deployed with a health check to be healthy
+	WaitForHealthyComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint) error
 	DeployKubernetesStack(stack *portainer.Stack, endpoint *portainer.Endpoint, user *portainer.User) error
 }
 
@@ -30,11 +40,12 @@ type stackDeployer struct {
 	kubernetesDeployer  portainer.KubernetesDeployer
 	ClientFactory       *dockerclient.ClientFactory
 	dataStore           dataservices.DataStore
+	gitService          portainer.GitService
 }
 
 // NewStackDeployer inits a stackDeployer struct with a SwarmStackManager, a ComposeStackManager and a KubernetesDeployer
 func NewStackDeployer(swarmStackManager portainer.SwarmStackManager, composeStackManager portainer.ComposeStackManager,
-	kubernetesDeployer portainer.KubernetesDeployer, clientFactory *dockerclient.ClientFactory, dataStore dataservices.DataStore) *stackDeployer {
+	kubernetesDeployer portainer.KubernetesDeployer, clientFactory *dockerclient.ClientFactory, dataStore dataservices.DataStore, gitService portainer.GitService) *stackDeployer {
 	return &stackDeployer{
 		lock:                &sync.Mutex{},
 		swarmStackManager:   swarmStackManager,
@@ -42,6 +53,7 @@ func NewStackDeployer(swarmStackManager portainer.SwarmStackManager, composeStac
 		kubernetesDeployer:  kubernetesDeployer,
 		ClientFactory:       clientFactory,
 		dataStore:           dataStore,
+		gitService:          gitService,
 	}
 }
 func (d *stackDeployer) DeploySwarmStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, prune, pullImage bool) error {
@@ -79,6 +91,34 @@ func (d *stackDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *por
 	return nil
 }
 
+// WaitForHealthyComposeStack waits for the services of a Compose stack to be healthy when the stack is deployed with
+// a health check, it returns ErrStackUnhealthy when they are not. Only the update paths wait, they are the ones able to
+// redeploy the previous version of the stack
+func (d *stackDeployer) WaitForHealthyComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint) error {
+	if stack.Option == nil || !stack.Option.HealthCheck || stackutils.IsRelativePathStack(stack) {
+		return nil
+	}
+
+	// The lock is not held while waiting so that the deployment of other stacks is not delayed
+	ctx, cancel := context.WithTimeout(context.TODO(), healthCheckTimeout(stack))
+	defer cancel()
+
+	if err := d.composeStackManager.WaitForHealthy(ctx, stack, endpoint); err != nil {
+		return fmt.Errorf("%w: %s", ErrStackUnhealthy, err)
+	}
+
+	return nil
+}
+
+// healthCheckTimeout returns the maximum time to wait for the services of a stack to be healthy
+func healthCheckTimeout(stack *portainer.Stack) time.Duration {
+	if stack.Option.HealthCheckTimeout > 0 {
+		return time.Duration(stack.Option.HealthCheckTimeout) * time.Second
+	}
+
+	return defaultHealthCheckTimeout
+}
+
 func (d *stackDeployer) DeployKubernetesStack(stack *portainer.Stack, endpoint *portainer.Endpoint, user *portainer.User) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
@@ -0,0 +1,146 @@
+package revisions
+
+import (
+	"slices"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// FileAdded is the status of a file only present in the target revision
+	FileAdded = "added"
+	// FileRemoved is the status of a file only present in the source revision
+	FileRemoved = "removed"
+	// FileModified is the status of a file whose content differs between the revisions
+	FileModified = "modified"
+)
+
+// Diff represents the changes between two revisions of a stack
+type Diff struct {
+	// Version of the source revision
+	From int `json:"From" example:"1"`
+	// Version of the target revision
+	To int `json:"To" example:"3"`
+	// Changed files, sorted by path
+	Files []FileDiff `json:"Files"`
+	// Changed environment variables
+	Env EnvDiff `json:"Env"`
+}
+
+// FileDiff represents the changes of a stack file
+type FileDiff struct {
+	// Path of the file, relative to the project path
+	Path string `json:"Path" example:"docker-compose.yml"`
+	// One of added, removed or modified
+	Status string `json:"Status" example:"modified"`
+	// Changes in the unified diff format
+	Diff string `json:"Diff"`
+}
+
+// EnvDiff represents the changes of the environment variables of a stack
+type EnvDiff struct {
+	Added   []portainer.Pair `json:"Added"`
+	Removed []portainer.Pair `json:"Removed"`
+	Changed []EnvChange      `json:"Changed"`
+}
+
+// EnvChange represents an environment variable whose value differs between two revisions
+type EnvChange struct {
+	Name string `json:"Name" example:"TAG"`
+	From string `json:"From" example:"1.0"`
+	To   string `json:"To" example:"1.1"`
+}
+
+// Compare returns the changes required to go from a revision to another one
+func Compare(from, to *portainer.StackRevision) (*Diff, error) {
+	diff := &Diff{
+		From:  from.Version,
+		To:    to.Version,
+		Files: []FileDiff{},
+		Env: EnvDiff{
+			Added:   []portainer.Pair{},
+			Removed: []portainer.Pair{},
+			Changed: []EnvChange{},
+		},
+	}
+
+	paths := make([]string, 0, len(from.Files)+len(to.Files))
+	for path := range from.Files {
+		paths = append(paths, path)
+	}
+
+	for path := range to.Files {
+		if _, ok := from.Files[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		before, inFrom := from.Files[path]
+		after, inTo := to.Files[path]
+		if before == after && inFrom == inTo {
+			continue
+		}
+
+		status := FileModified
+		if !inFrom {
+			status = FileAdded
+		} else if !inTo {
+			status = FileRemoved
+		}
+
+		unified, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(before),
+			B:        splitLines(after),
+			FromFile: "a/" + path,
+			ToFile:   "b/" + path,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		diff.Files = append(diff.Files, FileDiff{Path: path, Status: status, Diff: unified})
+	}
+
+	fromEnv := make(map[string]string, len(from.Env))
+	for _, pair := range from.Env {
+		fromEnv[pair.Name] = pair.Value
+	}
+
+	toEnv := make(map[string]string, len(to.Env))
+	for _, pair := range to.Env {
+		toEnv[pair.Name] = pair.Value
+
+		value, ok := fromEnv[pair.Name]
+		if !ok {
+			diff.Env.Added = append(diff.Env.Added, pair)
+		} else if value != pair.Value {
+			diff.Env.Changed = append(diff.Env.Changed, EnvChange{Name: pair.Name, From: value, To: pair.Value})
+		}
+	}
+
+	for _, pair := range from.Env {
+		if _, ok := toEnv[pair.Name]; !ok {
+			diff.Env.Removed = append(diff.Env.Removed, pair)
+		}
+	}
+
+	return diff, nil
+}
+
+// splitLines splits a content in lines keeping their line break, unlike difflib.SplitLines it does not add an
+// empty line after a trailing line break
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
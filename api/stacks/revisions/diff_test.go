@@ -0,0 +1,73 @@
+package revisions
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	from := &portainer.StackRevision{
+		Version: 1,
+		Files: map[string]string{
+			"docker-compose.yml": "services:\n  web:\n    image: nginx:1.0\n",
+			"override.yml":       "services: {}\n",
+			"unchanged.yml":      "services: {}\n",
+		},
+		Env: []portainer.Pair{
+			{Name: "TAG", Value: "1.0"},
+			{Name: "DEBUG", Value: "true"},
+			{Name: "PORT", Value: "80"},
+		},
+	}
+
+	to := &portainer.StackRevision{
+		Version: 3,
+		Files: map[string]string{
+			"docker-compose.yml": "services:\n  web:\n    image: nginx:1.1\n",
+			"prod.yml":           "services: {}\n",
+			"unchanged.yml":      "services: {}\n",
+		},
+		Env: []portainer.Pair{
+			{Name: "TAG", Value: "1.1"},
+			{Name: "PORT", Value: "80"},
+			{Name: "REPLICAS", Value: "2"},
+		},
+	}
+
+	diff, err := Compare(from, to)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, diff.From)
+	require.Equal(t, 3, diff.To)
+
+	require.Len(t, diff.Files, 3)
+	require.Equal(t, "docker-compose.yml", diff.Files[0].Path)
+	require.Equal(t, FileModified, diff.Files[0].Status)
+	require.Equal(t, "--- a/docker-compose.yml\n+++ b/docker-compose.yml\n@@ -1,3 +1,3 @@\n services:\n   web:\n-    image: nginx:1.0\n+    image: nginx:1.1\n", diff.Files[0].Diff)
+	require.Equal(t, "override.yml", diff.Files[1].Path)
+	require.Equal(t, FileRemoved, diff.Files[1].Status)
+	require.Equal(t, "prod.yml", diff.Files[2].Path)
+	require.Equal(t, FileAdded, diff.Files[2].Status)
+
+	require.Equal(t, []portainer.Pair{{Name: "REPLICAS", Value: "2"}}, diff.Env.Added)
+	require.Equal(t, []portainer.Pair{{Name: "DEBUG", Value: "true"}}, diff.Env.Removed)
+	require.Equal(t, []EnvChange{{Name: "TAG", From: "1.0", To: "1.1"}}, diff.Env.Changed)
+}
+
+func TestCompareIdenticalRevisions(t *testing.T) {
+	revision := &portainer.StackRevision{
+		Version: 2,
+		Files:   map[string]string{"docker-compose.yml": "services: {}\n"},
+		Env:     []portainer.Pair{{Name: "TAG", Value: "1.0"}},
+	}
+
+	diff, err := Compare(revision, revision)
+	require.NoError(t, err)
+	require.Empty(t, diff.Files)
+	require.Empty(t, diff.Env.Added)
+	require.Empty(t, diff.Env.Removed)
+	require.Empty(t, diff.Env.Changed)
+}
@@ -0,0 +1,191 @@
+package revisions
+
+import (
+	"cmp"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	dserrors "github.com/portainer/portainer/api/dataservices/errors"
+	"github.com/portainer/portainer/api/filesystem"
+
+	"github.com/pkg/errors"
+)
+
+// Baseline records the current state of a stack as its first revision, it does nothing when the stack already
+// has revisions. It must be called before the files of the stack are changed, so that the state deployed before
+// the revision history existed can be rolled back to.
+func Baseline(dataStore dataservices.DataStore, stack *portainer.Stack) error {
+	revisions, err := dataStore.StackRevision().StackRevisionsByStackID(stack.ID)
+	if err != nil {
+		return errors.WithMessage(err, "unable to retrieve the stack revisions")
+	}
+
+	if len(revisions) > 0 {
+		return nil
+	}
+
+	revision, err := newRevision(stack)
+	if err != nil {
+		return err
+	}
+
+	revision.Version = 1
+	revision.CreatedBy = cmp.Or(stack.UpdatedBy, stack.CreatedBy)
+	revision.CreationDate = cmp.Or(stack.UpdateDate, stack.CreationDate)
+
+	return dataStore.StackRevision().Create(revision)
+}
+
+// Record saves the current state of a stack as a new revision and removes the oldest revisions beyond the
+// limit defined in the settings. Nothing is recorded when the state is identical to the latest revision, which
+// is returned instead.
+func Record(dataStore dataservices.DataStore, stack *portainer.Stack, username, note string) (*portainer.StackRevision, error) {
+	revision, err := newRevision(stack)
+	if err != nil {
+		return nil, err
+	}
+
+	revision.CreatedBy = username
+	revision.CreationDate = time.Now().Unix()
+	revision.Note = note
+
+	err = dataStore.UpdateTx(func(tx dataservices.DataStoreTx) error {
+		revisions, err := tx.StackRevision().StackRevisionsByStackID(stack.ID)
+		if err != nil {
+			return errors.WithMessage(err, "unable to retrieve the stack revisions")
+		}
+
+		sortByVersion(revisions)
+
+		if len(revisions) > 0 {
+			latest := revisions[len(revisions)-1]
+			if sameState(&latest, revision) {
+				revision = &latest
+
+				return nil
+			}
+
+			revision.Version = latest.Version
+		}
+
+		revision.Version++
+
+		if err := tx.StackRevision().Create(revision); err != nil {
+			return errors.WithMessage(err, "unable to persist the stack revision")
+		}
+
+		settings, err := tx.Settings().Settings()
+		if err != nil {
+			return errors.WithMessage(err, "unable to retrieve the settings")
+		}
+
+		limit := settings.MaxStackRevisions
+		if limit <= 0 {
+			limit = portainer.DefaultMaxStackRevisions
+		}
+
+		// The new revision is not part of the list, one slot is kept for it
+		for len(revisions) >= limit {
+			if err := tx.StackRevision().Delete(revisions[0].ID); err != nil {
+				return errors.WithMessage(err, "unable to remove the stack revision")
+			}
+
+			revisions = revisions[1:]
+		}
+
+		return nil
+	})
+
+	return revision, err
+}
+
+// List returns the revisions of a stack, sorted from the oldest to the latest
+func List(dataStore dataservices.DataStoreTx, stackID portainer.StackID) ([]portainer.StackRevision, error) {
+	revisions, err := dataStore.StackRevision().StackRevisionsByStackID(stackID)
+	if err != nil {
+		return nil, err
+	}
+
+	sortByVersion(revisions)
+
+	return revisions, nil
+}
+
+// Find returns the revision of a stack with the specified version
+func Find(dataStore dataservices.DataStoreTx, stackID portainer.StackID, version int) (*portainer.StackRevision, error) {
+	revisions, err := dataStore.StackRevision().StackRevisionsByStackID(stackID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range revisions {
+		if revisions[i].Version == version {
+			return &revisions[i], nil
+		}
+	}
+
+	return nil, dserrors.ErrObjectNotFound
+}
+
+// RemoveAll removes the revisions of a stack
+func RemoveAll(dataStore dataservices.DataStoreTx, stackID portainer.StackID) error {
+	revisions, err := dataStore.StackRevision().StackRevisionsByStackID(stackID)
+	if err != nil {
+		return err
+	}
+
+	for _, revision := range revisions {
+		if err := dataStore.StackRevision().Delete(revision.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newRevision reads the files of the stack from its project path
+func newRevision(stack *portainer.Stack) (*portainer.StackRevision, error) {
+	files := make(map[string]string, len(stack.AdditionalFiles)+1)
+
+	for _, path := range append([]string{stack.EntryPoint}, stack.AdditionalFiles...) {
+		content, err := os.ReadFile(filesystem.JoinPaths(stack.ProjectPath, path))
+		if err != nil {
+			return nil, errors.WithMessage(err, "unable to read the stack files")
+		}
+
+		files[path] = string(content)
+	}
+
+	revision := &portainer.StackRevision{
+		StackID:         stack.ID,
+		EntryPoint:      stack.EntryPoint,
+		AdditionalFiles: slices.Clone(stack.AdditionalFiles),
+		Files:           files,
+		Env:             slices.Clone(stack.Env),
+	}
+
+	if stack.GitConfig != nil {
+		revision.ConfigHash = stack.GitConfig.ConfigHash
+		revision.ReferenceName = stack.GitConfig.ReferenceName
+	}
+
+	return revision, nil
+}
+
+func sameState(a, b *portainer.StackRevision) bool {
+	return a.EntryPoint == b.EntryPoint &&
+		slices.Equal(a.AdditionalFiles, b.AdditionalFiles) &&
+		a.ConfigHash == b.ConfigHash &&
+		maps.Equal(a.Files, b.Files) &&
+		slices.Equal(a.Env, b.Env)
+}
+
+func sortByVersion(revisions []portainer.StackRevision) {
+	slices.SortFunc(revisions, func(a, b portainer.StackRevision) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+}
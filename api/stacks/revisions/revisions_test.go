@@ -0,0 +1,137 @@
+package revisions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	gittypes "github.com/portainer/portainer/api/git/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeStackFile(t *testing.T, stack *portainer.Stack, content string) {
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(stack.ProjectPath, stack.EntryPoint), []byte(content), 0600)
+	require.NoError(t, err)
+}
+
+func newTestStack(t *testing.T) *portainer.Stack {
+	stack := &portainer.Stack{
+		ID:           1,
+		EntryPoint:   "docker-compose.yml",
+		ProjectPath:  t.TempDir(),
+		Env:          []portainer.Pair{{Name: "TAG", Value: "1.0"}},
+		CreatedBy:    "admin",
+		CreationDate: 1587399600,
+	}
+
+	writeStackFile(t, stack, "services:\n  web:\n    image: nginx:1.0\n")
+
+	return stack
+}
+
+func TestBaseline(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+	stack := newTestStack(t)
+
+	require.NoError(t, Baseline(store, stack))
+
+	writeStackFile(t, stack, "services:\n  web:\n    image: nginx:1.1\n")
+	require.NoError(t, Baseline(store, stack))
+
+	revisions, err := List(store, stack.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, 1, revisions[0].Version)
+	require.Equal(t, "admin", revisions[0].CreatedBy)
+	require.Equal(t, int64(1587399600), revisions[0].CreationDate)
+	require.Equal(t, "services:\n  web:\n    image: nginx:1.0\n", revisions[0].Files["docker-compose.yml"])
+}
+
+func TestRecord(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+	stack := newTestStack(t)
+	stack.GitConfig = &gittypes.RepoConfig{ConfigHash: "abc", ReferenceName: "refs/heads/main"}
+
+	first, err := Record(store, stack, "admin", "")
+	require.NoError(t, err)
+	require.Equal(t, 1, first.Version)
+	require.Equal(t, "abc", first.ConfigHash)
+	require.Equal(t, "refs/heads/main", first.ReferenceName)
+
+	// An identical state is not recorded again
+	same, err := Record(store, stack, "bob", "")
+	require.NoError(t, err)
+	require.Equal(t, first.ID, same.ID)
+
+	stack.Env = []portainer.Pair{{Name: "TAG", Value: "1.1"}}
+
+	second, err := Record(store, stack, "bob", "")
+	require.NoError(t, err)
+	require.Equal(t, 2, second.Version)
+	require.Equal(t, "bob", second.CreatedBy)
+
+	revisions, err := List(store, stack.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+
+	found, err := Find(store, stack.ID, 2)
+	require.NoError(t, err)
+	require.Equal(t, second.ID, found.ID)
+
+	_, err = Find(store, stack.ID, 3)
+	require.True(t, store.IsErrObjectNotFound(err))
+}
+
+func TestRecordPrunesOldestRevisions(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	settings, err := store.Settings().Settings()
+	require.NoError(t, err)
+	settings.MaxStackRevisions = 2
+	require.NoError(t, store.Settings().UpdateSettings(settings))
+
+	stack := newTestStack(t)
+
+	for _, tag := range []string{"1.0", "1.1", "1.2", "1.3"} {
+		stack.Env = []portainer.Pair{{Name: "TAG", Value: tag}}
+
+		_, err := Record(store, stack, "admin", "")
+		require.NoError(t, err)
+	}
+
+	// Revisions of other stacks are left untouched
+	other := newTestStack(t)
+	other.ID = 2
+	_, err = Record(store, other, "admin", "")
+	require.NoError(t, err)
+
+	revisions, err := List(store, stack.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, 3, revisions[0].Version)
+	require.Equal(t, 4, revisions[1].Version)
+
+	require.NoError(t, RemoveAll(store, stack.ID))
+
+	revisions, err = List(store, stack.ID)
+	require.NoError(t, err)
+	require.Empty(t, revisions)
+
+	revisions, err = List(store, other.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+}
+
+func TestRecordFailsWhenAFileIsMissing(t *testing.T) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+	stack := newTestStack(t)
+	stack.AdditionalFiles = []string{"missing.yml"}
+
+	_, err := Record(store, stack, "admin", "")
+	require.Error(t, err)
+}
@@ -44,6 +44,8 @@ func (b *ComposeStackFileContentBuilder) SetUniqueInfo(payload *StackPayload) Fi
 	b.stack.EntryPoint = filesystem.ComposeFileDefaultName
 	b.stack.Env = payload.Env
 	b.stack.FromAppTemplate = payload.FromAppTemplate
+	b.stack.CustomTemplateID = payload.CustomTemplateID
+	b.stack.CustomTemplateVariables = payload.CustomTemplateVariables
 	return b
 }
 
@@ -54,6 +54,8 @@ func (b *K8sStackFileContentBuilder) SetUniqueInfo(payload *StackPayload) FileCo
 	b.stack.Namespace = payload.Namespace
 	b.stack.CreatedBy = b.User.Username
 	b.stack.FromAppTemplate = payload.FromAppTemplate
+	b.stack.CustomTemplateID = payload.CustomTemplateID
+	b.stack.CustomTemplateVariables = payload.CustomTemplateVariables
 
 	return b
 }
@@ -59,7 +59,11 @@ func (b *GitMethodStackBuilder) SetGitRepository(payload *StackPayload) GitMetho
 	}
 
 	var repoConfig gittypes.RepoConfig
-	if payload.Authentication {
+	if payload.Authentication && payload.GitCredentialID != 0 {
+		repoConfig.Authentication = &gittypes.GitAuthentication{
+			GitCredentialID: payload.GitCredentialID,
+		}
+	} else if payload.Authentication {
 		repoConfig.Authentication = &gittypes.GitAuthentication{
 			Username: payload.Username,
 			Password: payload.Password,
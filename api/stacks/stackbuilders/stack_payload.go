@@ -21,6 +21,10 @@ type StackPayload struct {
 	AutoUpdate *portainer.AutoUpdateSettings
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
+	// Identifier of the custom template the stack file was rendered from
+	CustomTemplateID portainer.CustomTemplateID
+	// Values of the variables the custom template was rendered with
+	CustomTemplateVariables []portainer.Pair
 	// Kubernetes stack name
 	StackName string
 	// Kubernetes stack namespace
@@ -50,6 +54,8 @@ type RepositoryConfigPayload struct {
 	// Password used in basic authentication. Required when RepositoryAuthentication is true
 	// and RepositoryGitCredentialID is 0
 	Password string `example:"myGitPassword"`
+	// Git credential used in place of Username and Password
+	GitCredentialID int `example:"0"`
 	// TLSSkipVerify skips SSL verification when cloning the Git repository
 	TLSSkipVerify bool `example:"false"`
 }
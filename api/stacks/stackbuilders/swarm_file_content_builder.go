@@ -45,6 +45,8 @@ func (b *SwarmStackFileContentBuilder) SetUniqueInfo(payload *StackPayload) File
 	b.stack.EntryPoint = filesystem.ComposeFileDefaultName
 	b.stack.Env = payload.Env
 	b.stack.FromAppTemplate = payload.FromAppTemplate
+	b.stack.CustomTemplateID = payload.CustomTemplateID
+	b.stack.CustomTemplateVariables = payload.CustomTemplateVariables
 	return b
 }
 
@@ -17,17 +17,13 @@ var (
 // DownloadGitRepository downloads the target git repository on the disk
 // The first return value represents the commit hash of the downloaded git repository
 func DownloadGitRepository(config gittypes.RepoConfig, gitService portainer.GitService, getProjectPath func() string) (string, error) {
-	username := ""
-	password := ""
-	authType := gittypes.GitCredentialAuthType_Basic
-	if config.Authentication != nil {
-		username = config.Authentication.Username
-		password = config.Authentication.Password
-		authType = config.Authentication.AuthorizationType
+	username, password, authType, err := git.GetCredentials(gitService, config.Authentication)
+	if err != nil {
+		return "", err
 	}
 
 	projectPath := getProjectPath()
-	err := gitService.CloneRepository(
+	err = gitService.CloneRepository(
 		projectPath,
 		config.URL,
 		config.ReferenceName,
@@ -0,0 +1,211 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/git"
+	"github.com/portainer/portainer/pkg/validate"
+
+	"github.com/rs/zerolog/log"
+)
+
+// httpClient is used by the url sources, a slow source must not hold the template list for long
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// validator identifies the version of the templates of a source so they are only downloaded again when they change
+type validator struct {
+	// ETag of url sources, modification time of file sources or commit of git sources
+	version      string
+	lastModified string
+}
+
+type fetcher interface {
+	// fetch returns the templates of the source, or nil when they still match the previous validator
+	fetch(previous validator) (*File, validator, error)
+}
+
+func (service *Service) fetcher(source portainer.TemplateSource) (fetcher, error) {
+	switch source.Type {
+	case portainer.TemplateSourceURL:
+		return &urlFetcher{source: source}, nil
+	case portainer.TemplateSourceFile:
+		return &fileFetcher{path: source.Path}, nil
+	case portainer.TemplateSourceGit:
+		return &gitFetcher{source: source, gitService: service.gitService, fileService: service.fileService}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported template source type %q", source.Type)
+}
+
+// ValidateSource checks the configuration of a source
+func ValidateSource(source *portainer.TemplateSource) error {
+	if source.Name == "" {
+		return errors.New("the name is required")
+	}
+
+	if source.CacheTTL < 0 {
+		return errors.New("the cache TTL cannot be negative")
+	}
+
+	for _, header := range source.Headers {
+		if header.Name == "" {
+			return errors.New("the header names are required")
+		}
+	}
+
+	switch source.Type {
+	case portainer.TemplateSourceURL:
+		if !validate.IsURL(source.URL) {
+			return errors.New("a valid URL is required for url sources")
+		}
+	case portainer.TemplateSourceFile:
+		if !filepath.IsAbs(source.Path) {
+			return errors.New("an absolute path is required for file sources")
+		}
+	case portainer.TemplateSourceGit:
+		if source.Git == nil || source.Git.URL == "" || source.Git.ConfigFilePath == "" {
+			return errors.New("a repository URL and the path of the templates file are required for git sources")
+		}
+	default:
+		return fmt.Errorf("invalid source type %q. Valid values are: url, file or git", source.Type)
+	}
+
+	return nil
+}
+
+type urlFetcher struct {
+	source portainer.TemplateSource
+}
+
+func (f *urlFetcher) fetch(previous validator) (*File, validator, error) {
+	req, err := http.NewRequest(http.MethodGet, f.source.URL, nil)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	for _, header := range f.source.Headers {
+		req.Header.Set(header.Name, header.Value)
+	}
+
+	if previous.version != "" {
+		req.Header.Set("If-None-Match", previous.version)
+	}
+
+	if previous.lastModified != "" {
+		req.Header.Set("If-Modified-Since", previous.lastModified)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, validator{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && previous != (validator{}) {
+		return nil, previous, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, validator{}, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	file, err := parseFile(content)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	return file, validator{version: resp.Header.Get("ETag"), lastModified: resp.Header.Get("Last-Modified")}, nil
+}
+
+type fileFetcher struct {
+	path string
+}
+
+func (f *fileFetcher) fetch(previous validator) (*File, validator, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	current := validator{version: info.ModTime().UTC().Format(time.RFC3339Nano) + "-" + strconv.FormatInt(info.Size(), 10)}
+	if current == previous {
+		return nil, previous, nil
+	}
+
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	file, err := parseFile(content)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	return file, current, nil
+}
+
+type gitFetcher struct {
+	source      portainer.TemplateSource
+	gitService  portainer.GitService
+	fileService portainer.FileService
+}
+
+func (f *gitFetcher) fetch(previous validator) (*File, validator, error) {
+	repo := f.source.Git
+
+	username, password, authType, err := git.GetCredentials(f.gitService, repo.Authentication)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	commitID, err := f.gitService.LatestCommitID(repo.URL, repo.ReferenceName, username, password, authType, repo.TLSSkipVerify)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	current := validator{version: commitID}
+	if current == previous {
+		return nil, previous, nil
+	}
+
+	projectPath, err := f.fileService.GetTemporaryPath()
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	defer func() {
+		if err := f.fileService.RemoveDirectory(projectPath); err != nil {
+			log.Debug().Err(err).Msg("unable to remove the clone of the template source")
+		}
+	}()
+
+	if err := f.gitService.CloneRepository(projectPath, repo.URL, repo.ReferenceName, username, password, authType, repo.TLSSkipVerify); err != nil {
+		return nil, validator{}, err
+	}
+
+	content, err := f.fileService.GetFileContent(projectPath, repo.ConfigFilePath)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	file, err := parseFile(content)
+	if err != nil {
+		return nil, validator{}, err
+	}
+
+	return file, current, nil
+}
@@ -0,0 +1,387 @@
+package templates
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/dataservices"
+	"github.com/portainer/portainer/api/filesystem"
+	libclient "github.com/portainer/portainer/pkg/libhttp/client"
+
+	"github.com/rs/zerolog/log"
+	"github.com/segmentio/encoding/json"
+	"golang.org/x/sync/singleflight"
+)
+
+// IDNamespace is the size of the range of template identifiers allotted to each source. The templates of the source N
+// are identified by N*IDNamespace+1, N*IDNamespace+2... so that their identifiers don't depend on the other sources
+const IDNamespace = 100000
+
+// DefaultSourceName is the name of the source built from the templates URL of the settings
+const DefaultSourceName = "default"
+
+const (
+	// fetchTimeout bounds the time a request waits for a source without cached templates
+	fetchTimeout = 15 * time.Second
+	// retryDelay is the time before fetching again a source that failed and has no cached templates
+	retryDelay = time.Minute
+)
+
+// File is the format of a templates file
+type File struct {
+	Version   string               `json:"version"`
+	Templates []portainer.Template `json:"templates"`
+}
+
+// SourceStatus describes the last fetches of a source
+type SourceStatus struct {
+	// Unix timestamp of the last fetch attempt
+	LastAttempt int64 `json:"LastAttempt,omitempty" example:"1700000000"`
+	// Unix timestamp of the last successful fetch or revalidation
+	LastSuccess int64 `json:"LastSuccess,omitempty" example:"1700000000"`
+	// Error of the last fetch attempt, the templates of the last successful fetch are served meanwhile
+	Error string `json:"Error,omitempty"`
+	// Number of templates loaded from the source
+	TemplateCount int `json:"TemplateCount" example:"42"`
+}
+
+// persistedEntry is the last good copy of the templates of a source, it is stored on disk so that the templates can
+// still be served after a restart while the source cannot be reached
+type persistedEntry struct {
+	// hash of the configuration of the source, the configuration itself holds secrets such as the request headers
+	ConfigHash   string `json:"ConfigHash"`
+	File         *File  `json:"File"`
+	Version      string `json:"Version,omitempty"`
+	LastModified string `json:"LastModified,omitempty"`
+	FetchedAt    int64  `json:"FetchedAt"`
+}
+
+type cacheEntry struct {
+	// configuration of the source the templates were fetched with, a change invalidates the entry
+	config    string
+	file      *File
+	validator validator
+	fetchedAt time.Time
+	status    SourceStatus
+	err       error
+}
+
+// Service loads the application templates of all the sources and caches them
+type Service struct {
+	dataStore   dataservices.DataStore
+	gitService  portainer.GitService
+	fileService portainer.FileService
+	mu          sync.Mutex
+	entries     map[portainer.TemplateSourceID]*cacheEntry
+	fetches     singleflight.Group
+}
+
+// NewService creates a new templates service
+func NewService(dataStore dataservices.DataStore, gitService portainer.GitService, fileService portainer.FileService) *Service {
+	return &Service{
+		dataStore:   dataStore,
+		gitService:  gitService,
+		fileService: fileService,
+		entries:     make(map[portainer.TemplateSourceID]*cacheEntry),
+	}
+}
+
+// Sources returns the default source built from the settings followed by the sources of the database
+func (service *Service) Sources() ([]portainer.TemplateSource, error) {
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	sources, err := service.dataStore.TemplateSource().ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSource := portainer.TemplateSource{
+		Name: DefaultSourceName,
+		Type: portainer.TemplateSourceURL,
+		URL:  cmp.Or(settings.TemplatesURL, portainer.DefaultTemplatesURL),
+	}
+
+	slices.SortFunc(sources, func(a, b portainer.TemplateSource) int {
+		return int(a.ID) - int(b.ID)
+	})
+
+	return append([]portainer.TemplateSource{defaultSource}, sources...), nil
+}
+
+// Templates returns the templates of all the sources. A source that cannot be reached is served from its last
+// successful fetch, or ignored when it never succeeded
+func (service *Service) Templates(ctx context.Context) (*File, error) {
+	sources, err := service.Sources()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*File, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			file, err := service.sourceTemplates(ctx, source)
+			if errors.Is(err, libclient.ErrExternalRequestsBlocked) {
+				log.Debug().Err(err).Str("source", source.Name).Msg("external requests are disabled, skipping the template source")
+
+				return
+			} else if err != nil {
+				log.Warn().Err(err).Str("source", source.Name).Msg("unable to load the templates of the source")
+
+				return
+			}
+
+			files[i] = file
+		}()
+	}
+
+	wg.Wait()
+
+	result := &File{Templates: []portainer.Template{}}
+	for i, file := range files {
+		if file == nil {
+			continue
+		}
+
+		result.Version = cmp.Or(result.Version, file.Version)
+
+		for j, template := range file.Templates {
+			template.ID = portainer.TemplateID(int(sources[i].ID)*IDNamespace + j + 1)
+			template.Source = sources[i].Name
+
+			result.Templates = append(result.Templates, template)
+		}
+	}
+
+	return result, nil
+}
+
+// Status returns the status of the last fetches of a source
+func (service *Service) Status(sourceID portainer.TemplateSourceID) SourceStatus {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if entry, ok := service.entries[sourceID]; ok {
+		return entry.status
+	}
+
+	return SourceStatus{}
+}
+
+// Invalidate removes the cached templates of a source
+func (service *Service) Invalidate(sourceID portainer.TemplateSourceID) {
+	service.mu.Lock()
+	delete(service.entries, sourceID)
+	service.mu.Unlock()
+
+	if err := service.fileService.RemoveDirectory(service.fileService.GetTemplateSourcePath(strconv.Itoa(int(sourceID)))); err != nil {
+		log.Warn().Err(err).Int("source_id", int(sourceID)).Msg("unable to remove the last good copy of the templates")
+	}
+}
+
+// restore loads the last good copy of the templates of the source from the disk when they are not cached yet
+func (service *Service) restore(sourceID portainer.TemplateSourceID, config string) {
+	service.mu.Lock()
+	_, ok := service.entries[sourceID]
+	service.mu.Unlock()
+
+	if ok {
+		return
+	}
+
+	content, err := service.fileService.GetFileContent(service.fileService.GetTemplateSourcePath(strconv.Itoa(int(sourceID))), filesystem.TemplateSourceFileName)
+	if err != nil {
+		return
+	}
+
+	var persisted persistedEntry
+	if err := json.Unmarshal(content, &persisted); err != nil || persisted.File == nil || persisted.ConfigHash != configHash(config) {
+		return
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if _, ok := service.entries[sourceID]; ok {
+		return
+	}
+
+	service.entries[sourceID] = &cacheEntry{
+		config:    config,
+		file:      persisted.File,
+		validator: validator{version: persisted.Version, lastModified: persisted.LastModified},
+		fetchedAt: time.Unix(persisted.FetchedAt, 0),
+		status: SourceStatus{
+			LastSuccess:   persisted.FetchedAt,
+			TemplateCount: len(persisted.File.Templates),
+		},
+	}
+}
+
+// persist stores the last good copy of the templates of the source on disk
+func (service *Service) persist(sourceID portainer.TemplateSourceID, persisted persistedEntry) {
+	content, err := json.Marshal(persisted)
+	if err == nil {
+		_, err = service.fileService.StoreTemplateSourceFileFromBytes(strconv.Itoa(int(sourceID)), content)
+	}
+
+	if err != nil {
+		log.Warn().Err(err).Int("source_id", int(sourceID)).Msg("unable to store the last good copy of the templates")
+	}
+}
+
+func configHash(config string) string {
+	hash := sha256.Sum256([]byte(config))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// sourceTemplates returns the cached templates of the source. Fresh templates are returned as is, stale templates
+// are returned while being revalidated in the background, and the templates are fetched when there are none.
+func (service *Service) sourceTemplates(ctx context.Context, source portainer.TemplateSource) (*File, error) {
+	config, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(cmp.Or(source.CacheTTL, portainer.DefaultTemplateSourceCacheTTL)) * time.Second
+
+	service.restore(source.ID, string(config))
+
+	service.mu.Lock()
+	entry, ok := service.entries[source.ID]
+	ok = ok && entry.config == string(config)
+	cached := ok && entry.file != nil
+	var file *File
+	var fresh bool
+	var lastError error
+	if cached {
+		file = entry.file
+		fresh = time.Since(entry.fetchedAt) < ttl
+	}
+	// don't retry a failing source on every request
+	if ok && entry.err != nil && time.Since(time.Unix(entry.status.LastAttempt, 0)) < retryDelay {
+		lastError = entry.err
+	}
+	service.mu.Unlock()
+
+	if fresh || (cached && lastError != nil) {
+		return file, nil
+	} else if lastError != nil {
+		return nil, lastError
+	}
+
+	fetch := service.fetches.DoChan(fmt.Sprint(source.ID), func() (any, error) {
+		return service.refresh(source, string(config))
+	})
+
+	if cached {
+		return file, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	select {
+	case result := <-fetch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return result.Val.(*File), nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("the source did not respond in time: %w", ctx.Err())
+	}
+}
+
+// refresh fetches the templates of the source, revalidating the cached ones, and updates the cache
+func (service *Service) refresh(source portainer.TemplateSource, config string) (*File, error) {
+	service.mu.Lock()
+	entry, ok := service.entries[source.ID]
+	if !ok || entry.config != config {
+		entry = &cacheEntry{config: config}
+		service.entries[source.ID] = entry
+	}
+	previous := entry.validator
+	service.mu.Unlock()
+
+	fetcher, err := service.fetcher(source)
+	if err == nil && source.Type != portainer.TemplateSourceFile {
+		err = libclient.ExternalRequestDisabled(source.URL)
+	}
+
+	var file *File
+	var validator validator
+	if err == nil {
+		file, validator, err = fetcher.fetch(previous)
+	}
+
+	service.mu.Lock()
+
+	now := time.Now()
+	entry.status.LastAttempt = now.Unix()
+
+	entry.err = err
+	if err != nil {
+		entry.status.Error = err.Error()
+		file = entry.file
+		service.mu.Unlock()
+
+		return file, err
+	}
+
+	// a nil file means the cached templates are still valid
+	if file != nil {
+		entry.file = file
+		entry.validator = validator
+	}
+
+	entry.fetchedAt = now
+	entry.status.LastSuccess = now.Unix()
+	entry.status.Error = ""
+	entry.status.TemplateCount = len(entry.file.Templates)
+
+	file = entry.file
+	persisted := persistedEntry{
+		ConfigHash:   configHash(config),
+		File:         file,
+		Version:      entry.validator.version,
+		LastModified: entry.validator.lastModified,
+		FetchedAt:    now.Unix(),
+	}
+	service.mu.Unlock()
+
+	service.persist(source.ID, persisted)
+
+	return file, nil
+}
+
+func parseFile(content []byte) (*File, error) {
+	var file File
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse the templates file: %w", err)
+	}
+
+	if file.Templates == nil {
+		file.Templates = []portainer.Template{}
+	}
+
+	return &file, nil
+}
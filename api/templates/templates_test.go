@@ -0,0 +1,216 @@
+package templates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/datastore"
+	"github.com/portainer/portainer/api/filesystem"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const templatesFile = `{"version": "3", "templates": [{"type": 1, "title": "nginx", "image": "nginx:latest"}, {"type": 1, "title": "redis", "image": "redis:latest"}]}`
+
+type templatesServer struct {
+	*httptest.Server
+	requests    atomic.Int32
+	notModified atomic.Int32
+	down        atomic.Bool
+}
+
+func newTemplatesServer(t *testing.T) *templatesServer {
+	s := &templatesServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+
+		if s.down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			s.notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(templatesFile))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func newTestService(t *testing.T, sources ...portainer.TemplateSource) (*Service, []portainer.TemplateSource) {
+	_, store := datastore.MustNewTestStore(t, true, false)
+
+	settings, err := store.Settings().Settings()
+	require.NoError(t, err)
+
+	// the default source must not reach the internet during the tests
+	settings.TemplatesURL = "http://127.0.0.1:1/templates.json"
+	require.NoError(t, store.Settings().UpdateSettings(settings))
+
+	for i := range sources {
+		require.NoError(t, store.TemplateSource().Create(&sources[i]))
+	}
+
+	fileService, err := filesystem.NewService(t.TempDir(), "")
+	require.NoError(t, err)
+
+	return NewService(store, nil, fileService), sources
+}
+
+func expire(service *Service, sourceID portainer.TemplateSourceID) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	service.entries[sourceID].fetchedAt = time.Time{}
+}
+
+func TestTemplates_mergesSources(t *testing.T) {
+	server := newTemplatesServer(t)
+
+	path := filepath.Join(t.TempDir(), "templates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": "3", "templates": [{"type": 3, "title": "wordpress"}]}`), 0o600))
+
+	service, sources := newTestService(t,
+		portainer.TemplateSource{Name: "remote", Type: portainer.TemplateSourceURL, URL: server.URL, Headers: []portainer.Pair{{Name: "Authorization", Value: "Bearer token"}}},
+		portainer.TemplateSource{Name: "local", Type: portainer.TemplateSourceFile, Path: path},
+	)
+
+	file, err := service.Templates(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "3", file.Version)
+	require.Len(t, file.Templates, 3, "the templates of the unreachable default source should be ignored")
+
+	remoteID, localID := int(sources[0].ID), int(sources[1].ID)
+
+	assert.Equal(t, portainer.TemplateID(remoteID*IDNamespace+1), file.Templates[0].ID)
+	assert.Equal(t, "remote", file.Templates[0].Source)
+	assert.Equal(t, portainer.TemplateID(remoteID*IDNamespace+2), file.Templates[1].ID)
+	assert.Equal(t, portainer.TemplateID(localID*IDNamespace+1), file.Templates[2].ID)
+	assert.Equal(t, "wordpress", file.Templates[2].Title)
+	assert.Equal(t, "local", file.Templates[2].Source)
+
+	status := service.Status(0)
+	assert.NotEmpty(t, status.Error, "the failure of the default source should be reported")
+	assert.Equal(t, 2, service.Status(sources[0].ID).TemplateCount)
+}
+
+func TestTemplates_cacheRevalidation(t *testing.T) {
+	server := newTemplatesServer(t)
+
+	service, sources := newTestService(t,
+		portainer.TemplateSource{Name: "remote", Type: portainer.TemplateSourceURL, URL: server.URL, Headers: []portainer.Pair{{Name: "Authorization", Value: "Bearer token"}}},
+	)
+	sourceID := sources[0].ID
+
+	_, err := service.Templates(context.Background())
+	require.NoError(t, err)
+
+	_, err = service.Templates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), server.requests.Load(), "fresh templates should be served from the cache")
+
+	expire(service, sourceID)
+
+	file, err := service.Templates(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, file.Templates, 2, "stale templates should be served while being revalidated")
+
+	require.Eventually(t, func() bool { return server.notModified.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	t.Run("the last good copy is served when the source is down", func(t *testing.T) {
+		server.down.Store(true)
+		expire(service, sourceID)
+
+		_, err := service.Templates(context.Background())
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool { return service.Status(sourceID).Error != "" }, time.Second, 10*time.Millisecond)
+
+		file, err := service.Templates(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, file.Templates, 2)
+		assert.Equal(t, 2, service.Status(sourceID).TemplateCount)
+	})
+
+	t.Run("the last good copy is served after a restart when the source is down", func(t *testing.T) {
+		restarted := NewService(service.dataStore, nil, service.fileService)
+
+		file, err := restarted.Templates(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, file.Templates, 2)
+		assert.NotZero(t, restarted.Status(sourceID).LastSuccess)
+
+		restarted.Invalidate(sourceID)
+
+		file, err = NewService(service.dataStore, nil, service.fileService).Templates(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, file.Templates, "the last good copy should be removed with the cached templates")
+	})
+}
+
+func TestTemplates_fileSourceChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.json")
+	require.NoError(t, os.WriteFile(path, []byte(templatesFile), 0o600))
+
+	service, sources := newTestService(t, portainer.TemplateSource{Name: "local", Type: portainer.TemplateSourceFile, Path: path})
+
+	file, err := service.Templates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, file.Templates, 2)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": "3", "templates": [{"type": 1, "title": "caddy"}]}`), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+
+	_, err = service.refresh(sources[0], service.entries[sources[0].ID].config)
+	require.NoError(t, err)
+
+	file, err = service.Templates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, file.Templates, 1)
+	assert.Equal(t, "caddy", file.Templates[0].Title)
+}
+
+func TestValidateSource(t *testing.T) {
+	valid := []portainer.TemplateSource{
+		{Name: "remote", Type: portainer.TemplateSourceURL, URL: "https://example.com/templates.json"},
+		{Name: "local", Type: portainer.TemplateSourceFile, Path: "/data/templates.json"},
+	}
+	for _, source := range valid {
+		assert.NoError(t, ValidateSource(&source), source.Name)
+	}
+
+	invalid := map[string]portainer.TemplateSource{
+		"missing name":  {Type: portainer.TemplateSourceURL, URL: "https://example.com/templates.json"},
+		"invalid url":   {Name: "remote", Type: portainer.TemplateSourceURL, URL: "https://exa mple.com"},
+		"relative path": {Name: "local", Type: portainer.TemplateSourceFile, Path: "templates.json"},
+		"missing file":  {Name: "git", Type: portainer.TemplateSourceGit},
+		"invalid type":  {Name: "s3", Type: "s3"},
+		"negative TTL":  {Name: "remote", Type: portainer.TemplateSourceURL, URL: "https://example.com/templates.json", CacheTTL: -1},
+	}
+	for name, source := range invalid {
+		assert.Error(t, ValidateSource(&source), name)
+	}
+}
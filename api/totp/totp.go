@@ -0,0 +1,214 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Period is the number of seconds during which a code is valid
+	Period = 30
+	// Digits is the number of digits of a code
+	Digits = 6
+	// Issuer is the name displayed by the authenticator applications
+	Issuer = "Portainer"
+	// RecoveryCodeCount is the number of recovery codes generated for a user
+	RecoveryCodeCount = 10
+
+	secretSize = 20
+	// skew is the number of periods before and after the current one during which a code is still accepted,
+	// to absorb the clock drift of the devices
+	skew = 1
+)
+
+var (
+	ErrInvalidSecret = errors.New("invalid TOTP secret")
+
+	encoding             = base32.StdEncoding.WithPadding(base32.NoPadding)
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// GenerateSecret returns a new random secret, base32 encoded as expected by the authenticator applications
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "unable to generate the TOTP secret")
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// ProvisioningURI returns the otpauth URI of the secret, usually displayed as a QR code to enroll
+// an authenticator application
+func ProvisioningURI(accountName, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", Issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(Period))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + Issuer + ":" + accountName,
+		RawQuery: query.Encode(),
+	}
+
+	return u.String()
+}
+
+// Validate verifies the code against the secret at the given time and returns the time step that matched.
+// Codes of a time step lower or equal to lastUsedStep are rejected so that a code cannot be replayed.
+func Validate(code, secret string, t time.Time, lastUsedStep int64) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := t.Unix() / Period
+	for step := current - skew; step <= current+skew; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(generate(key, uint64(step))), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// GenerateCode returns the code of the secret at the given time
+func GenerateCode(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return generate(key, uint64(t.Unix()/Period)), nil
+}
+
+// GenerateRecoveryCodes returns a new set of single-use recovery codes
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+
+	for i := range codes {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, errors.Wrap(err, "unable to generate the recovery codes")
+		}
+
+		var sb strings.Builder
+		for j, b := range buf {
+			if j == len(buf)/2 {
+				sb.WriteByte('-')
+			}
+
+			sb.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+
+		codes[i] = sb.String()
+	}
+
+	return codes, nil
+}
+
+// NormalizeRecoveryCode removes the formatting a user might add when typing a recovery code
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+
+	return key, nil
+}
+
+// generate implements the HOTP algorithm of RFC 4226 used by TOTP with the time step as counter
+func generate(key []byte, counter uint64) string {
+	return generateDigits(key, counter, Digits)
+}
+
+func generateDigits(key []byte, counter uint64, digits int) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
+
+// Required returns true when the settings require the user to authenticate with a second factor
+func Required(settings *portainer.Settings, user *portainer.User) bool {
+	switch settings.TwoFactorRequirement {
+	case portainer.TwoFactorRequiredForAll:
+		return true
+	case portainer.TwoFactorRequiredForAdministrators:
+		return user.Role == portainer.AdministratorRole
+	}
+
+	return false
+}
+
+// HashRecoveryCodes returns the hashes of the recovery codes, only the hashes are persisted
+func HashRecoveryCodes(cryptoService portainer.CryptoService, codes []string) ([]string, error) {
+	hashes := make([]string, len(codes))
+
+	for i, code := range codes {
+		hash, err := cryptoService.Hash(code)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to hash the recovery codes")
+		}
+
+		hashes[i] = hash
+	}
+
+	return hashes, nil
+}
+
+// ConsumeRecoveryCode removes the recovery code from the enrollment and returns true when it was one of its codes
+func ConsumeRecoveryCode(cryptoService portainer.CryptoService, enrollment *portainer.UserTOTP, code string) bool {
+	code = NormalizeRecoveryCode(code)
+
+	for i, hash := range enrollment.RecoveryCodes {
+		if cryptoService.CompareHashAndData(hash, code) == nil {
+			enrollment.RecoveryCodes = append(enrollment.RecoveryCodes[:i], enrollment.RecoveryCodes[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDigits_RFC6238Vectors(t *testing.T) {
+	// test vectors of RFC 6238 appendix B for the SHA1 algorithm
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		time int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.code, generateDigits(key, uint64(test.time/Period), 8))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	code, err := GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	t.Run("accepts the current code", func(t *testing.T) {
+		step, ok := Validate(code, secret, now, 0)
+		assert.True(t, ok)
+		assert.Equal(t, now.Unix()/Period, step)
+	})
+
+	t.Run("accepts the code of the previous period", func(t *testing.T) {
+		_, ok := Validate(code, secret, now.Add(Period*time.Second), 0)
+		assert.True(t, ok)
+	})
+
+	t.Run("rejects an outdated code", func(t *testing.T) {
+		_, ok := Validate(code, secret, now.Add(3*Period*time.Second), 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects a replayed code", func(t *testing.T) {
+		_, ok := Validate(code, secret, now, now.Unix()/Period)
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects a wrong code", func(t *testing.T) {
+		wrongCode := "000000"
+		if code == wrongCode {
+			wrongCode = "111111"
+		}
+
+		_, ok := Validate(wrongCode, secret, now, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects an invalid secret", func(t *testing.T) {
+		_, ok := Validate(code, "not base32!", now, 0)
+		assert.False(t, ok)
+	})
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("admin", "JBSWY3DPEHPK3PXP")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Portainer:admin?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Portainer")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Len(t, code, 11)
+		assert.Equal(t, code, NormalizeRecoveryCode(" "+strings.ToUpper(code)+" "))
+		seen[code] = true
+	}
+
+	assert.Len(t, seen, RecoveryCodeCount)
+}
@@ -0,0 +1,54 @@
+package libkubectl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/kubectl/pkg/cmd/set"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// SetImage updates the image of the containers of a resource, images maps the container names to their new image
+func (c *Client) SetImage(ctx context.Context, resource string, images map[string]string) (string, error) {
+	buf := new(bytes.Buffer)
+
+	var fatalErr error
+	cmdutil.BehaviorOnFatal(func(msg string, code int) {
+		fatalErr = newKubectlFatalError(code, msg)
+	})
+	defer cmdutil.DefaultBehaviorOnFatal()
+
+	cmd := set.NewCmdSet(c.factory, c.streams)
+	cmd.SetArgs(setImageArgs(resource, images))
+	cmd.SetOut(buf)
+
+	err := cmd.ExecuteContext(ctx)
+	// check for the fatal error first so we don't return the error from the command execution
+	if fatalErr != nil {
+		return "", fatalErr
+	}
+	// if there is no fatal error, return the error from the command execution
+	if err != nil {
+		return "", fmt.Errorf("error setting images: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+func setImageArgs(resource string, images map[string]string) []string {
+	args := []string{"image", resource}
+
+	containers := make([]string, 0, len(images))
+	for container := range images {
+		containers = append(containers, container)
+	}
+	slices.Sort(containers)
+
+	for _, container := range containers {
+		args = append(args, container+"="+images[container])
+	}
+
+	return args
+}
@@ -0,0 +1,16 @@
+package libkubectl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetImageArgs(t *testing.T) {
+	args := setImageArgs("deployment/web", map[string]string{
+		"sidecar": "envoyproxy/envoy:v1.30",
+		"app":     "nginx:1.27",
+	})
+
+	assert.Equal(t, []string{"image", "deployment/web", "app=nginx:1.27", "sidecar=envoyproxy/envoy:v1.30"}, args)
+}
@@ -0,0 +1,329 @@
+package orastest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/segmentio/encoding/json"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+var (
+	manifestPath = regexp.MustCompile(`^/v2/(.+)/manifests/([^/]+)$`)
+	blobPath     = regexp.MustCompile(`^/v2/(.+)/blobs/([^/]+)$`)
+	uploadPath   = regexp.MustCompile(`^/v2/(.+)/blobs/uploads/([^/]*)$`)
+	tagsPath     = regexp.MustCompile(`^/v2/(.+)/tags/list$`)
+)
+
+type manifest struct {
+	mediaType string
+	content   []byte
+}
+
+type repository struct {
+	manifests map[digest.Digest]manifest
+	tags      map[string]digest.Digest
+}
+
+// Registry is an in-memory registry implementing the subset of the OCI distribution specification used by liboras
+type Registry struct {
+	mu           sync.Mutex
+	repositories map[string]*repository
+	blobs        map[digest.Digest][]byte
+	uploads      int
+}
+
+// RunRegistry starts an in-memory OCI registry, the registry URL is the host of the returned server
+func RunRegistry() (*httptest.Server, *Registry) {
+	reg := &Registry{
+		repositories: make(map[string]*repository),
+		blobs:        make(map[digest.Digest][]byte),
+	}
+
+	return httptest.NewServer(reg), reg
+}
+
+// Tags returns the tags of the repository
+func (reg *Registry) Tags(name string) []string {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	return reg.tags(name)
+}
+
+func (reg *Registry) tags(name string) []string {
+	repo, ok := reg.repositories[name]
+	if !ok {
+		return nil
+	}
+
+	tags := make([]string, 0, len(repo.tags))
+	for tag := range repo.tags {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+
+	return tags
+}
+
+func (reg *Registry) repository(name string) *repository {
+	repo, ok := reg.repositories[name]
+	if !ok {
+		repo = &repository{
+			manifests: make(map[digest.Digest]manifest),
+			tags:      make(map[string]digest.Digest),
+		}
+		reg.repositories[name] = repo
+	}
+
+	return repo
+}
+
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	path := r.URL.Path
+
+	switch {
+	case path == "/v2/" || path == "/v2":
+		w.WriteHeader(http.StatusOK)
+	case path == "/v2/_catalog" && r.Method == http.MethodGet:
+		names := make([]string, 0, len(reg.repositories))
+		for name := range reg.repositories {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		writeJSON(w, map[string]any{"repositories": names})
+	case tagsPath.MatchString(path) && r.Method == http.MethodGet:
+		name := tagsPath.FindStringSubmatch(path)[1]
+		if _, ok := reg.repositories[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		writeJSON(w, map[string]any{"name": name, "tags": reg.tags(name)})
+	case uploadPath.MatchString(path):
+		reg.serveUpload(w, r, uploadPath.FindStringSubmatch(path))
+	case manifestPath.MatchString(path):
+		match := manifestPath.FindStringSubmatch(path)
+		reg.serveManifest(w, r, match[1], match[2])
+	case blobPath.MatchString(path):
+		reg.serveBlob(w, r, blobPath.FindStringSubmatch(path)[2])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (reg *Registry) serveUpload(w http.ResponseWriter, r *http.Request, match []string) {
+	name, id := match[1], match[2]
+
+	switch {
+	case r.Method == http.MethodPost && id == "":
+		reg.uploads++
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d", name, reg.uploads))
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && id != "":
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		dgst, err := digest.Parse(r.URL.Query().Get("digest"))
+		if err != nil || dgst != digest.FromBytes(body) {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		reg.blobs[dgst] = body
+		reg.repository(name)
+
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (reg *Registry) serveBlob(w http.ResponseWriter, r *http.Request, reference string) {
+	dgst, err := digest.Parse(reference)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	content, ok := reg.blobs[dgst]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		writeContent(w, r, "application/octet-stream", dgst, content)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (reg *Registry) serveManifest(w http.ResponseWriter, r *http.Request, name, reference string) {
+	if r.Method == http.MethodPut {
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		dgst := digest.FromBytes(content)
+		repo := reg.repository(name)
+		repo.manifests[dgst] = manifest{mediaType: r.Header.Get("Content-Type"), content: content}
+
+		if _, err := digest.Parse(reference); err != nil {
+			repo.tags[reference] = dgst
+		}
+
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+
+		return
+	}
+
+	repo, ok := reg.repositories[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	dgst, err := digest.Parse(reference)
+	if err != nil {
+		if dgst, ok = repo.tags[reference]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+	}
+
+	m, ok := repo.manifests[dgst]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		writeContent(w, r, m.mediaType, dgst, m.content)
+	case http.MethodDelete:
+		// deleting a manifest removes all the tags pointing to it, like most registries do
+		delete(repo.manifests, dgst)
+		for tag, tagDigest := range repo.tags {
+			if tagDigest == dgst {
+				delete(repo.tags, tag)
+			}
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeContent(w http.ResponseWriter, r *http.Request, mediaType string, dgst digest.Digest, content []byte) {
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Docker-Content-Digest", dgst.String())
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// PushImage pushes a single layer image built for the platform to the repository and tags it
+func PushImage(ctx context.Context, registryClient *remote.Registry, name, tag string, platform ocispec.Platform, created time.Time) (ocispec.Descriptor, error) {
+	repo, err := registryClient.Repository(ctx, name)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	layer := []byte(fmt.Sprintf("%s:%s %s/%s", name, tag, platform.OS, platform.Architecture))
+	layerDescriptor, err := pushBlob(ctx, repo, ocispec.MediaTypeImageLayer, layer)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	config, err := json.Marshal(ocispec.Image{Created: &created, Platform: platform})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	configDescriptor, err := pushBlob(ctx, repo, ocispec.MediaTypeImageConfig, config)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	return pushManifest(ctx, repo, ocispec.MediaTypeImageManifest, tag, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDescriptor,
+		Layers:    []ocispec.Descriptor{layerDescriptor},
+	})
+}
+
+// PushIndex pushes an image index referencing the manifests and tags it
+func PushIndex(ctx context.Context, registryClient *remote.Registry, name, tag string, manifests ...ocispec.Descriptor) (ocispec.Descriptor, error) {
+	repo, err := registryClient.Repository(ctx, name)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	return pushManifest(ctx, repo, ocispec.MediaTypeImageIndex, tag, ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: manifests,
+	})
+}
+
+func pushBlob(ctx context.Context, repo registry.Repository, mediaType string, content []byte) (ocispec.Descriptor, error) {
+	descriptor := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(content), Size: int64(len(content))}
+
+	return descriptor, repo.Blobs().Push(ctx, descriptor, bytes.NewReader(content))
+}
+
+func pushManifest(ctx context.Context, repo registry.Repository, mediaType, tag string, v any) (ocispec.Descriptor, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	descriptor := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(content), Size: int64(len(content))}
+
+	return descriptor, repo.Manifests().PushReference(ctx, descriptor, bytes.NewReader(content), tag)
+}
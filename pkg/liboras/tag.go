@@ -0,0 +1,248 @@
+package liboras
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/portainer/portainer/api/concurrent"
+	"github.com/segmentio/encoding/json"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// Docker media types, registries still serve them for images built with the classic builder
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeDockerImageConfig  = "application/vnd.docker.container.image.v1+json"
+)
+
+// TagDetails describes the content a tag points to
+type TagDetails struct {
+	// Name of the tag
+	Name string `json:"Name" example:"1.25"`
+	// Digest of the manifest the tag points to
+	Digest string `json:"Digest" example:"sha256:6af6f8a1b5b7d0c0f2b7d8e3c4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3"`
+	// Media type of the manifest
+	MediaType string `json:"MediaType" example:"application/vnd.oci.image.index.v1+json"`
+	// Total size in bytes of the manifests, configs and layers referenced by the tag
+	Size int64 `json:"Size" example:"71234567"`
+	// Creation date of the image, the most recent one for multi-platform images
+	Created *time.Time `json:"Created,omitempty"`
+	// Platforms the image is available for
+	Platforms []ocispec.Platform `json:"Platforms"`
+	// Reason why the details of the tag could not be retrieved, only the name is set then
+	Error string `json:"Error,omitempty" example:"failed to resolve tag 1.25: not found"`
+}
+
+// IsNotFound returns true when the error is caused by a repository, tag or manifest missing from the registry
+func IsNotFound(err error) bool {
+	if errors.Is(err, errdef.ErrNotFound) {
+		return true
+	}
+
+	var errResp *errcode.ErrorResponse
+
+	return errors.As(err, &errResp) && errResp.StatusCode == http.StatusNotFound
+}
+
+// ListTags returns the tags of a repository, sorted by name
+func ListTags(ctx context.Context, registryClient *remote.Registry, repository string) ([]string, error) {
+	repo, err := registryClient.Repository(ctx, repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository handle: %w", err)
+	}
+
+	tags := []string{}
+	if err := repo.Tags(ctx, "", func(tagList []string) error {
+		tags = append(tags, tagList...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	slices.Sort(tags)
+
+	return tags, nil
+}
+
+// ListTagDetails returns the details of all the tags of a repository, sorted by name. A tag whose details cannot
+// be retrieved is listed with the error instead of failing the whole listing.
+func ListTagDetails(ctx context.Context, registryClient *remote.Registry, repository string) ([]TagDetails, error) {
+	tags, err := ListTags(ctx, registryClient, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := registryClient.Repository(ctx, repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository handle: %w", err)
+	}
+
+	// Run concurrently as repositories pushed by CI pipelines often have hundreds of tags
+	tasks := make([]concurrent.Func, 0, len(tags))
+	for _, tag := range tags {
+		tasks = append(tasks, func(ctx context.Context) (any, error) {
+			details, err := GetTagDetails(ctx, repo, tag)
+			if err != nil {
+				return TagDetails{Name: tag, Platforms: []ocispec.Platform{}, Error: err.Error()}, nil
+			}
+
+			return details, nil
+		})
+	}
+
+	results, err := concurrent.Run(ctx, 10, tasks...)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]TagDetails, 0, len(results))
+	for _, result := range results {
+		if tagDetails, ok := result.Result.(TagDetails); ok {
+			details = append(details, tagDetails)
+		}
+	}
+
+	slices.SortFunc(details, func(a, b TagDetails) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return details, nil
+}
+
+// GetTagDetails resolves a tag and computes the size, creation date and platforms of the content it points to
+func GetTagDetails(ctx context.Context, repo registry.Repository, tag string) (TagDetails, error) {
+	descriptor, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return TagDetails{}, fmt.Errorf("failed to resolve tag %s: %w", tag, err)
+	}
+
+	details := TagDetails{
+		Name:      tag,
+		Digest:    descriptor.Digest.String(),
+		MediaType: descriptor.MediaType,
+		Platforms: []ocispec.Platform{},
+	}
+
+	if err := collectDetails(ctx, repo, descriptor, &details); err != nil {
+		return TagDetails{}, fmt.Errorf("failed to inspect tag %s: %w", tag, err)
+	}
+
+	return details, nil
+}
+
+// collectDetails adds the size, creation date and platform of the content described by the descriptor to the details,
+// walking through the manifests of an index
+func collectDetails(ctx context.Context, repo registry.Repository, descriptor ocispec.Descriptor, details *TagDetails) error {
+	details.Size += descriptor.Size
+
+	switch descriptor.MediaType {
+	case ocispec.MediaTypeImageIndex, MediaTypeDockerManifestList:
+		var index ocispec.Index
+		if err := fetchJSON(ctx, repo.Manifests(), descriptor, &index); err != nil {
+			return err
+		}
+
+		for _, manifest := range index.Manifests {
+			// skip the attestations attached by BuildKit, they are not images
+			if manifest.Platform != nil && manifest.Platform.OS == "unknown" {
+				continue
+			}
+
+			if err := collectDetails(ctx, repo, manifest, details); err != nil {
+				return err
+			}
+		}
+	case ocispec.MediaTypeImageManifest, MediaTypeDockerManifest:
+		var manifest ocispec.Manifest
+		if err := fetchJSON(ctx, repo.Manifests(), descriptor, &manifest); err != nil {
+			return err
+		}
+
+		details.Size += manifest.Config.Size
+		for _, layer := range manifest.Layers {
+			details.Size += layer.Size
+		}
+
+		if manifest.Config.MediaType != ocispec.MediaTypeImageConfig && manifest.Config.MediaType != MediaTypeDockerImageConfig {
+			// not a container image, e.g. a Helm chart
+			return nil
+		}
+
+		var config ocispec.Image
+		if err := fetchJSON(ctx, repo.Blobs(), manifest.Config, &config); err != nil {
+			return err
+		}
+
+		if config.Created != nil && (details.Created == nil || config.Created.After(*details.Created)) {
+			details.Created = config.Created
+		}
+
+		if config.OS != "" {
+			details.Platforms = append(details.Platforms, config.Platform)
+		}
+	}
+
+	return nil
+}
+
+func fetchJSON(ctx context.Context, fetcher content.Fetcher, descriptor ocispec.Descriptor, v any) error {
+	b, err := content.FetchAll(ctx, fetcher, descriptor)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// FetchManifest returns the descriptor and the raw content of the manifest identified by the reference, a tag or a digest
+func FetchManifest(ctx context.Context, registryClient *remote.Registry, repository, reference string) (ocispec.Descriptor, []byte, error) {
+	repo, err := registryClient.Repository(ctx, repository)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to get repository handle: %w", err)
+	}
+
+	descriptor, reader, err := repo.FetchReference(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	defer reader.Close()
+
+	manifestBytes, err := content.ReadAll(reader, descriptor)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return descriptor, manifestBytes, nil
+}
+
+// RetagManifest creates or moves a tag to the manifest identified by the reference, a tag or a digest.
+// Unlike AddTagToManifest, the manifest keeps its media type so Docker manifests and image indexes can be tagged.
+func RetagManifest(ctx context.Context, registryClient *remote.Registry, repository, reference, tagName string) (ocispec.Descriptor, error) {
+	descriptor, manifestBytes, err := FetchManifest(ctx, registryClient, repository, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	repo, err := registryClient.Repository(ctx, repository)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to get repository handle: %w", err)
+	}
+
+	if err := repo.Manifests().PushReference(ctx, descriptor, bytes.NewReader(manifestBytes), tagName); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to tag manifest: %w", err)
+	}
+
+	return descriptor, nil
+}
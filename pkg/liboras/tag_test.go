@@ -0,0 +1,117 @@
+package liboras
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/pkg/liboras/orastest"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagOperations(t *testing.T) {
+	ctx := context.Background()
+
+	srv, reg := orastest.RunRegistry()
+	defer srv.Close()
+
+	registryClient, err := CreateClient(portainer.Registry{
+		Type: portainer.CustomRegistry,
+		URL:  strings.TrimPrefix(srv.URL, "http://"),
+	})
+	require.NoError(t, err)
+
+	amd64 := ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	amd64Image, err := orastest.PushImage(ctx, registryClient, "team/app", "1.0-amd64", amd64, older)
+	require.NoError(t, err)
+	amd64Image.Platform = &amd64
+
+	arm64Image, err := orastest.PushImage(ctx, registryClient, "team/app", "1.0-arm64", arm64, newer)
+	require.NoError(t, err)
+	arm64Image.Platform = &arm64
+
+	index, err := orastest.PushIndex(ctx, registryClient, "team/app", "1.0", amd64Image, arm64Image)
+	require.NoError(t, err)
+
+	t.Run("lists the tags with their details", func(t *testing.T) {
+		details, err := ListTagDetails(ctx, registryClient, "team/app")
+		require.NoError(t, err)
+		require.Len(t, details, 3)
+
+		assert.Equal(t, "1.0", details[0].Name)
+		assert.Equal(t, index.Digest.String(), details[0].Digest)
+		assert.Equal(t, ocispec.MediaTypeImageIndex, details[0].MediaType)
+		assert.Equal(t, []ocispec.Platform{amd64, arm64}, details[0].Platforms)
+		assert.Equal(t, newer, *details[0].Created)
+
+		imageDetails := details[1]
+		assert.Equal(t, "1.0-amd64", imageDetails.Name)
+		assert.Equal(t, []ocispec.Platform{amd64}, imageDetails.Platforms)
+		assert.Equal(t, older, *imageDetails.Created)
+		assert.Equal(t, details[1].Size+details[2].Size+index.Size, details[0].Size)
+	})
+
+	t.Run("fetches a manifest", func(t *testing.T) {
+		descriptor, manifestBytes, err := FetchManifest(ctx, registryClient, "team/app", "1.0")
+		require.NoError(t, err)
+		assert.Equal(t, index.Digest, descriptor.Digest)
+
+		var manifest ocispec.Index
+		require.NoError(t, json.Unmarshal(manifestBytes, &manifest))
+		assert.Len(t, manifest.Manifests, 2)
+
+		_, _, err = FetchManifest(ctx, registryClient, "team/app", "missing")
+		assert.True(t, IsNotFound(err))
+	})
+
+	t.Run("retags an image index", func(t *testing.T) {
+		descriptor, err := RetagManifest(ctx, registryClient, "team/app", "1.0", "latest")
+		require.NoError(t, err)
+		assert.Equal(t, index.Digest, descriptor.Digest)
+
+		resolved, _, err := FetchManifest(ctx, registryClient, "team/app", "latest")
+		require.NoError(t, err)
+		assert.Equal(t, index.Digest, resolved.Digest)
+		assert.Equal(t, ocispec.MediaTypeImageIndex, resolved.MediaType)
+	})
+
+	t.Run("deletes a tag without removing the other tags of the manifest", func(t *testing.T) {
+		require.NoError(t, SafeDeleteTags(registryClient, "team/app", []string{"latest"}))
+
+		assert.Equal(t, []string{"1.0", "1.0-amd64", "1.0-arm64"}, reg.Tags("team/app"))
+	})
+
+	t.Run("reports the errors of each tag", func(t *testing.T) {
+		_, err := orastest.PushImage(ctx, registryClient, "team/broken", "1.0", amd64, older)
+		require.NoError(t, err)
+
+		missing := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("missing"), Size: 7}
+		_, err = orastest.PushIndex(ctx, registryClient, "team/broken", "2.0", missing)
+		require.NoError(t, err)
+
+		details, err := ListTagDetails(ctx, registryClient, "team/broken")
+		require.NoError(t, err)
+		require.Len(t, details, 2)
+
+		assert.Empty(t, details[0].Error)
+		assert.Equal(t, []ocispec.Platform{amd64}, details[0].Platforms)
+
+		assert.Equal(t, "2.0", details[1].Name)
+		assert.NotEmpty(t, details[1].Error)
+	})
+
+	t.Run("fails for a missing repository", func(t *testing.T) {
+		_, err := ListTags(ctx, registryClient, "missing")
+		assert.True(t, IsNotFound(err))
+	})
+}
@@ -0,0 +1,291 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/portainer/portainer/pkg/libstack"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/cli/cli/command"
+	cmdcompose "github.com/docker/compose/v2/cmd/compose"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/compose"
+	"github.com/docker/compose/v2/pkg/utils"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// Plan computes the changes that Deploy would apply to the running containers of the project, it follows the
+// convergence rules of docker compose up: a service is recreated when its configuration hash or its image changed
+func (c *ComposeDeployer) Plan(ctx context.Context, filePaths []string, options libstack.DeployOptions) (libstack.Plan, error) {
+	var plan libstack.Plan
+
+	if err := withCli(ctx, options.Options, func(ctx context.Context, cli *command.DockerCli) error {
+		project, err := createProject(ctx, filePaths, options.Options)
+		if err != nil {
+			return fmt.Errorf("failed to create compose project: %w", err)
+		}
+
+		addServiceLabels(project, false, options.EdgeStackID)
+
+		plan, err = planProject(ctx, cli.Client(), project, options)
+
+		return err
+	}); err != nil {
+		return libstack.Plan{}, fmt.Errorf("compose plan operation failed: %w", err)
+	}
+
+	return plan, nil
+}
+
+func planProject(ctx context.Context, apiClient client.APIClient, project *types.Project, options libstack.DeployOptions) (libstack.Plan, error) {
+	containers, err := apiClient.ContainerList(ctx, container.ListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", api.ProjectLabel+"="+project.Name),
+			filters.Arg("label", api.OneoffLabel+"=False"),
+		),
+	})
+	if err != nil {
+		return libstack.Plan{}, fmt.Errorf("unable to list the containers of the project: %w", err)
+	}
+
+	containersByService := make(map[string][]container.Summary)
+	for _, ctr := range containers {
+		name := ctr.Labels[api.ServiceLabel]
+		containersByService[name] = append(containersByService[name], ctr)
+	}
+
+	var plan libstack.Plan
+
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		servicePlan, err := planService(ctx, apiClient, project, project.Services[name], containersByService[name], options.ForceRecreate)
+		if err != nil {
+			return libstack.Plan{}, err
+		}
+
+		plan.Services = append(plan.Services, servicePlan)
+	}
+
+	removeOrphans := options.RemoveOrphans
+	if v, ok := project.Environment[cmdcompose.ComposeRemoveOrphans]; ok {
+		removeOrphans = utils.StringToBool(v)
+	}
+
+	orphanAction := libstack.ServiceActionOrphan
+	if removeOrphans {
+		orphanAction = libstack.ServiceActionRemove
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(containersByService)) {
+		if _, ok := project.Services[name]; ok {
+			continue
+		}
+
+		if _, ok := project.DisabledServices[name]; ok {
+			continue
+		}
+
+		plan.Services = append(plan.Services, libstack.ServicePlan{Name: name, Action: orphanAction})
+	}
+
+	return plan, nil
+}
+
+func planService(ctx context.Context, apiClient client.APIClient, project *types.Project, service types.ServiceConfig, containers []container.Summary, forceRecreate bool) (libstack.ServicePlan, error) {
+	plan := libstack.ServicePlan{Name: service.Name, Action: libstack.ServiceActionUnchanged}
+
+	if len(containers) == 0 {
+		plan.Action = libstack.ServiceActionCreate
+
+		return plan, nil
+	}
+
+	if forceRecreate {
+		plan.Action = libstack.ServiceActionRecreate
+		plan.Reasons = []libstack.ChangeReason{libstack.ChangeReasonForced}
+
+		return plan, nil
+	}
+
+	configHash, err := compose.ServiceHash(service)
+	if err != nil {
+		return plan, fmt.Errorf("unable to compute the configuration hash of the service %s: %w", service.Name, err)
+	}
+
+	imageName := api.GetImageNameOrDefault(service, project.Name)
+
+	// The image is only known when it is already present, otherwise it will be pulled or built by the deployment
+	var imageID string
+	if img, err := apiClient.ImageInspect(ctx, imageName); err == nil {
+		imageID = img.ID
+	} else if !cerrdefs.IsNotFound(err) {
+		return plan, fmt.Errorf("unable to inspect the image %s: %w", imageName, err)
+	}
+
+	var reasons []libstack.ChangeReason
+
+	for _, ctr := range containers {
+		imageChanged := imageID != "" && ctr.Labels[api.ImageDigestLabel] != imageID
+		if ctr.Labels[api.ConfigHashLabel] == configHash && !imageChanged {
+			continue
+		}
+
+		plan.Action = libstack.ServiceActionRecreate
+
+		if imageChanged {
+			reasons = append(reasons, libstack.ChangeReasonImage)
+		}
+
+		inspect, err := apiClient.ContainerInspect(ctx, ctr.ID)
+		if err != nil {
+			return plan, fmt.Errorf("unable to inspect the container %s: %w", ctr.ID, err)
+		}
+
+		reasons = append(reasons, diffService(project, service, imageName, inspect)...)
+	}
+
+	if plan.Action != libstack.ServiceActionRecreate {
+		return plan, nil
+	}
+
+	slices.Sort(reasons)
+	plan.Reasons = slices.Compact(reasons)
+
+	if len(plan.Reasons) == 0 {
+		plan.Reasons = []libstack.ChangeReason{libstack.ChangeReasonConfiguration}
+	}
+
+	return plan, nil
+}
+
+// diffService returns the reasons why a container does not match the configuration of its service, a change that
+// is not one of the detailed reasons is only caught by the configuration hash
+func diffService(project *types.Project, service types.ServiceConfig, imageName string, actual container.InspectResponse) []libstack.ChangeReason {
+	var reasons []libstack.ChangeReason
+
+	if actual.Config != nil && actual.Config.Image != imageName {
+		reasons = append(reasons, libstack.ChangeReasonImage)
+	}
+
+	if actual.Config != nil && !containsEnvironment(actual.Config.Env, service.Environment) {
+		reasons = append(reasons, libstack.ChangeReasonEnvironment)
+	}
+
+	if actual.ContainerJSONBase != nil && actual.HostConfig != nil && !equalPorts(actual.HostConfig, service.Ports) {
+		reasons = append(reasons, libstack.ChangeReasonPorts)
+	}
+
+	if !equalVolumes(project, actual.Mounts, service.Volumes) {
+		reasons = append(reasons, libstack.ChangeReasonVolumes)
+	}
+
+	if service.NetworkMode == "" && actual.NetworkSettings != nil && !equalNetworks(project, actual.NetworkSettings.Networks, service.Networks) {
+		reasons = append(reasons, libstack.ChangeReasonNetworks)
+	}
+
+	return reasons
+}
+
+// containsEnvironment checks that all the variables of the service are set in the container, the container also
+// holds the variables of its image
+func containsEnvironment(actual []string, expected types.MappingWithEquals) bool {
+	for key, value := range expected {
+		if value == nil {
+			continue
+		}
+
+		if !slices.Contains(actual, key+"="+*value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalPorts(actual *container.HostConfig, expected []types.ServicePortConfig) bool {
+	expectedPorts := make(map[string]struct{})
+	for _, port := range expected {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+
+		expectedPorts[port.Published+":"+strconv.FormatUint(uint64(port.Target), 10)+"/"+protocol] = struct{}{}
+	}
+
+	actualPorts := make(map[string]struct{})
+	for port, bindings := range actual.PortBindings {
+		for _, binding := range bindings {
+			actualPorts[binding.HostPort+":"+string(port)] = struct{}{}
+		}
+	}
+
+	return maps.Equal(expectedPorts, actualPorts)
+}
+
+func equalVolumes(project *types.Project, actual []container.MountPoint, expected []types.ServiceVolumeConfig) bool {
+	expectedSources := make(map[string]string)
+	for _, volume := range expected {
+		switch volume.Type {
+		case types.VolumeTypeBind:
+			expectedSources[volume.Target] = volume.Source
+		case types.VolumeTypeVolume:
+			source := volume.Source
+			if v, ok := project.Volumes[volume.Source]; ok && v.Name != "" {
+				source = v.Name
+			}
+
+			expectedSources[volume.Target] = source
+		}
+	}
+
+	for _, mountPoint := range actual {
+		source, ok := expectedSources[mountPoint.Destination]
+
+		switch mountPoint.Type {
+		case mount.TypeBind:
+			if !ok || mountPoint.Source != source {
+				return false
+			}
+		case mount.TypeVolume:
+			// Anonymous volumes, including the ones declared by the image, have a generated name
+			if ok && source != "" && mountPoint.Name != source {
+				return false
+			}
+		default:
+			continue
+		}
+
+		delete(expectedSources, mountPoint.Destination)
+	}
+
+	return len(expectedSources) == 0
+}
+
+func equalNetworks(project *types.Project, actual map[string]*network.EndpointSettings, expected map[string]*types.ServiceNetworkConfig) bool {
+	if len(actual) != len(expected) {
+		return false
+	}
+
+	for key := range expected {
+		name := key
+		if nw, ok := project.Networks[key]; ok && nw.Name != "" {
+			name = nw.Name
+		}
+
+		if _, ok := actual[name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,173 @@
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/portainer/portainer/pkg/libstack"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/compose/v2/pkg/api"
+	"github.com/docker/compose/v2/pkg/compose"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+type planClient struct {
+	client.APIClient
+
+	containers []container.Summary
+	inspects   map[string]container.InspectResponse
+	images     map[string]string
+}
+
+func (c *planClient) ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+	return c.containers, nil
+}
+
+func (c *planClient) ContainerInspect(ctx context.Context, id string) (container.InspectResponse, error) {
+	return c.inspects[id], nil
+}
+
+func (c *planClient) ImageInspect(ctx context.Context, name string, _ ...client.ImageInspectOption) (image.InspectResponse, error) {
+	id, ok := c.images[name]
+	if !ok {
+		return image.InspectResponse{}, cerrdefs.ErrNotFound
+	}
+
+	return image.InspectResponse{ID: id}, nil
+}
+
+func loadPlanProject(t *testing.T) *types.Project {
+	t.Helper()
+
+	filePath := createFile(t, t.TempDir(), "docker-compose.yml", `services:
+  web:
+    image: nginx:1.27
+    environment:
+      A: "1"
+    ports:
+      - "8080:80"
+  db:
+    image: postgres:16
+  cache:
+    image: redis
+`)
+
+	project, err := createProject(context.Background(), []string{filePath}, libstack.Options{ProjectName: "stack"})
+	require.NoError(t, err)
+
+	addServiceLabels(project, false, 0)
+
+	return project
+}
+
+func newPlanClient(t *testing.T, project *types.Project) *planClient {
+	t.Helper()
+
+	dbHash, err := compose.ServiceHash(project.Services["db"])
+	require.NoError(t, err)
+
+	networks := map[string]*network.EndpointSettings{"stack_default": {}}
+
+	return &planClient{
+		containers: []container.Summary{
+			{ID: "web-1", Labels: map[string]string{api.ServiceLabel: "web", api.ConfigHashLabel: "outdated"}},
+			{ID: "db-1", Labels: map[string]string{api.ServiceLabel: "db", api.ConfigHashLabel: dbHash, api.ImageDigestLabel: "sha256:db"}},
+			{ID: "old-1", Labels: map[string]string{api.ServiceLabel: "old"}},
+		},
+		inspects: map[string]container.InspectResponse{
+			"web-1": {
+				ContainerJSONBase: &container.ContainerJSONBase{
+					HostConfig: &container.HostConfig{
+						PortBindings: nat.PortMap{"80/tcp": {{HostPort: "8080"}}},
+					},
+				},
+				Config:          &container.Config{Image: "nginx:1.26", Env: []string{"A=1", "PATH=/usr/bin"}},
+				NetworkSettings: &container.NetworkSettings{Networks: networks},
+			},
+		},
+		images: map[string]string{"postgres:16": "sha256:db"},
+	}
+}
+
+func TestPlanProject(t *testing.T) {
+	project := loadPlanProject(t)
+	apiClient := newPlanClient(t, project)
+
+	plan, err := planProject(context.Background(), apiClient, project, libstack.DeployOptions{})
+	require.NoError(t, err)
+
+	require.Equal(t, []libstack.ServicePlan{
+		{Name: "cache", Action: libstack.ServiceActionCreate},
+		{Name: "db", Action: libstack.ServiceActionUnchanged},
+		{Name: "web", Action: libstack.ServiceActionRecreate, Reasons: []libstack.ChangeReason{libstack.ChangeReasonImage}},
+		{Name: "old", Action: libstack.ServiceActionOrphan},
+	}, plan.Services)
+}
+
+func TestPlanProject_pulledImage(t *testing.T) {
+	project := loadPlanProject(t)
+	apiClient := newPlanClient(t, project)
+	apiClient.images["postgres:16"] = "sha256:newer"
+
+	plan, err := planProject(context.Background(), apiClient, project, libstack.DeployOptions{})
+	require.NoError(t, err)
+
+	require.Equal(t, libstack.ServicePlan{
+		Name:    "db",
+		Action:  libstack.ServiceActionRecreate,
+		Reasons: []libstack.ChangeReason{libstack.ChangeReasonImage},
+	}, plan.Services[1])
+}
+
+func TestPlanProject_options(t *testing.T) {
+	project := loadPlanProject(t)
+	apiClient := newPlanClient(t, project)
+
+	plan, err := planProject(context.Background(), apiClient, project, libstack.DeployOptions{
+		ForceRecreate: true,
+		RemoveOrphans: true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []libstack.ServicePlan{
+		{Name: "cache", Action: libstack.ServiceActionCreate},
+		{Name: "db", Action: libstack.ServiceActionRecreate, Reasons: []libstack.ChangeReason{libstack.ChangeReasonForced}},
+		{Name: "web", Action: libstack.ServiceActionRecreate, Reasons: []libstack.ChangeReason{libstack.ChangeReasonForced}},
+		{Name: "old", Action: libstack.ServiceActionRemove},
+	}, plan.Services)
+}
+
+func TestDiffService(t *testing.T) {
+	project := loadPlanProject(t)
+	service := project.Services["web"]
+
+	actual := container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			HostConfig: &container.HostConfig{
+				PortBindings: nat.PortMap{"80/tcp": {{HostPort: "9090"}}},
+			},
+		},
+		Config: &container.Config{Image: "nginx:1.27", Env: []string{"A=2"}},
+		Mounts: []container.MountPoint{
+			{Type: mount.TypeBind, Source: "/data", Destination: "/data"},
+		},
+		NetworkSettings: &container.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{"bridge": {}},
+		},
+	}
+
+	require.Equal(t, []libstack.ChangeReason{
+		libstack.ChangeReasonEnvironment,
+		libstack.ChangeReasonPorts,
+		libstack.ChangeReasonVolumes,
+		libstack.ChangeReasonNetworks,
+	}, diffService(project, service, "nginx:1.27", actual))
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/portainer/portainer/pkg/libstack"
@@ -40,10 +41,13 @@ type service struct {
 }
 
 // docker container state can be one of "created", "running", "paused", "restarting", "removing", "exited", or "dead"
-func getServiceStatus(ctx context.Context, service service) (libstack.Status, string) {
+//
+// when requireHealthy is set, a running container is only considered running once its healthcheck passes
+func getServiceStatus(ctx context.Context, options libstack.Options, service service, requireHealthy bool) (libstack.Status, string) {
 	log.Debug().
 		Str("service", service.Name).
 		Str("state", service.State).
+		Str("health", service.Health).
 		Int("exitCode", service.ExitCode).
 		Msg("getServiceStatus")
 
@@ -51,6 +55,18 @@ func getServiceStatus(ctx context.Context, service service) (libstack.Status, st
 	case "created", "restarting", "paused":
 		return libstack.StatusStarting, ""
 	case "running":
+		if !requireHealthy {
+			return libstack.StatusRunning, ""
+		}
+
+		switch service.Health {
+		case "starting":
+			return libstack.StatusStarting, ""
+		case "unhealthy":
+			return libstack.StatusError, fmt.Sprintf("service %s is unhealthy\n", service.Name) +
+				serviceErrorMessage(ctx, options, service)
+		}
+
 		return libstack.StatusRunning, ""
 	case "removing":
 		return libstack.StatusRemoving, ""
@@ -59,40 +75,40 @@ func getServiceStatus(ctx context.Context, service service) (libstack.Status, st
 			return libstack.StatusCompleted, ""
 		}
 
-		errorMessage, err := getContainerLogsTail(ctx, service)
-		if err != nil {
-			log.Error().
-				Err(err).
-				Str("service", service.Name).
-				Msg("failed to get logs from container")
-			errorMessage = fmt.Sprintf("service %s exited with code %d", service.Name, service.ExitCode)
-		}
-
-		return libstack.StatusError, errorMessage
+		return libstack.StatusError, serviceErrorMessage(ctx, options, service)
 	case "dead":
 		if service.ExitCode == 0 {
 			return libstack.StatusRemoved, ""
 		}
 
-		errorMessage, err := getContainerLogsTail(ctx, service)
-		if err != nil {
-			log.Error().
-				Err(err).
-				Str("service", service.Name).
-				Msg("failed to get logs from container")
-			errorMessage = fmt.Sprintf("service %s exited with code %d", service.Name, service.ExitCode)
-		}
-
-		return libstack.StatusError, errorMessage
+		return libstack.StatusError, serviceErrorMessage(ctx, options, service)
 	default:
 		return libstack.StatusUnknown, ""
 	}
 }
 
-func getContainerLogsTail(ctx context.Context, service service) (string, error) {
+// serviceErrorMessage returns the tail of the logs of a failed service container
+func serviceErrorMessage(ctx context.Context, options libstack.Options, service service) string {
+	errorMessage, err := getContainerLogsTail(ctx, options, service)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("service", service.Name).
+			Msg("failed to get logs from container")
+
+		return fmt.Sprintf("service %s exited with code %d", service.Name, service.ExitCode)
+	}
+
+	return errorMessage
+}
+
+// containerLogsTail fetches the tail of the logs of a service container, replaced in tests
+var containerLogsTail = getContainerLogsTail
+
+func getContainerLogsTail(ctx context.Context, options libstack.Options, service service) (string, error) {
 	var combinedOutput bytes.Buffer
 
-	if err := withCli(ctx, libstack.Options{ProjectName: service.Project}, func(ctx context.Context, cli *command.DockerCli) error {
+	if err := withCli(ctx, libstack.Options{Host: options.Host, ProjectName: service.Project}, func(ctx context.Context, cli *command.DockerCli) error {
 		out, err := cli.Client().ContainerLogs(ctx, service.Name, container.LogsOptions{
 			ShowStdout: true,
 			ShowStderr: true,
@@ -118,7 +134,7 @@ func getContainerLogsTail(ctx context.Context, service service) (string, error)
 	return combinedOutput.String(), nil
 }
 
-func aggregateStatuses(ctx context.Context, services []service) (libstack.Status, string) {
+func aggregateStatuses(ctx context.Context, options libstack.Options, services []service, requireHealthy bool) (libstack.Status, string) {
 	servicesCount := len(services)
 
 	if servicesCount == 0 {
@@ -131,7 +147,7 @@ func aggregateStatuses(ctx context.Context, services []service) (libstack.Status
 	statusCounts := make(map[libstack.Status]int)
 	errorMessage := ""
 	for _, service := range services {
-		status, serviceError := getServiceStatus(ctx, service)
+		status, serviceError := getServiceStatus(ctx, options, service, requireHealthy)
 		if serviceError != "" {
 			errorMessage = serviceError
 		}
@@ -165,12 +181,28 @@ func aggregateStatuses(ctx context.Context, services []service) (libstack.Status
 }
 
 func (c *ComposeDeployer) WaitForStatus(ctx context.Context, name string, status libstack.Status) libstack.WaitResult {
+	return c.waitForStatus(ctx, libstack.Options{ProjectName: name}, status, false)
+}
+
+// WaitForHealthy waits until every service of the project is running and healthy, or has completed
+func (c *ComposeDeployer) WaitForHealthy(ctx context.Context, options libstack.Options) libstack.WaitResult {
+	return c.waitForStatus(ctx, options, libstack.StatusRunning, true)
+}
+
+func (c *ComposeDeployer) waitForStatus(ctx context.Context, options libstack.Options, status libstack.Status, requireHealthy bool) libstack.WaitResult {
 	waitResult := libstack.WaitResult{Status: status}
+	name := options.ProjectName
+
+	var services []service
 
 	for {
 		if ctx.Err() != nil {
 			waitResult.ErrorMsg = "failed to wait for status: " + ctx.Err().Error()
 
+			if requireHealthy {
+				waitResult.ErrorMsg += pendingServicesMessage(ctx, options, services)
+			}
+
 			return waitResult
 		}
 
@@ -178,7 +210,7 @@ func (c *ComposeDeployer) WaitForStatus(ctx context.Context, name string, status
 
 		var containerSummaries []api.ContainerSummary
 
-		if err := c.withComposeService(ctx, nil, libstack.Options{ProjectName: name}, func(composeService api.Service, project *types.Project) error {
+		if err := c.withComposeService(ctx, nil, options, func(composeService api.Service, project *types.Project) error {
 			var err error
 
 			psCtx, cancelFunc := context.WithTimeout(context.Background(), time.Minute)
@@ -195,13 +227,13 @@ func (c *ComposeDeployer) WaitForStatus(ctx context.Context, name string, status
 			continue
 		}
 
-		services := serviceListFromContainerSummary(containerSummaries)
+		services = serviceListFromContainerSummary(containerSummaries)
 
 		if len(services) == 0 && status == libstack.StatusRemoved {
 			return waitResult
 		}
 
-		aggregateStatus, errorMessage := aggregateStatuses(ctx, services)
+		aggregateStatus, errorMessage := aggregateStatuses(ctx, options, services, requireHealthy)
 		if aggregateStatus == status {
 			return waitResult
 		}
@@ -226,6 +258,42 @@ func (c *ComposeDeployer) WaitForStatus(ctx context.Context, name string, status
 	}
 }
 
+// pendingServicesMessage describes the services that are not running yet, followed by the logs of the first one
+//
+// it is called once the wait context has expired, so the logs are fetched with a fresh short-lived context
+func pendingServicesMessage(ctx context.Context, options libstack.Options, services []service) string {
+	logsCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	var message strings.Builder
+
+	logged := false
+	for _, service := range services {
+		if status, _ := getServiceStatus(logsCtx, options, service, true); status == libstack.StatusRunning || status == libstack.StatusCompleted {
+			continue
+		}
+
+		state := service.State
+		if service.Health != "" {
+			state += " (" + service.Health + ")"
+		}
+
+		fmt.Fprintf(&message, "\nservice %s is %s", service.Name, state)
+
+		if logged {
+			continue
+		}
+
+		logged = true
+
+		if logs, err := containerLogsTail(logsCtx, options, service); err == nil {
+			message.WriteString("\n" + logs)
+		}
+	}
+
+	return message.String()
+}
+
 func serviceListFromContainerSummary(containerSummaries []api.ContainerSummary) []service {
 	var services []service
 
@@ -0,0 +1,121 @@
+package compose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/portainer/portainer/pkg/libstack"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateStatuses_health(t *testing.T) {
+	testCases := []struct {
+		name           string
+		services       []service
+		requireHealthy bool
+		expected       libstack.Status
+	}{
+		{
+			name:     "starting healthcheck without health requirement",
+			services: []service{{Name: "web", State: "running", Health: "starting"}},
+			expected: libstack.StatusRunning,
+		},
+		{
+			name:           "starting healthcheck",
+			services:       []service{{Name: "web", State: "running", Health: "starting"}},
+			requireHealthy: true,
+			expected:       libstack.StatusStarting,
+		},
+		{
+			name: "healthy and without healthcheck",
+			services: []service{
+				{Name: "web", State: "running", Health: "healthy"},
+				{Name: "worker", State: "running"},
+			},
+			requireHealthy: true,
+			expected:       libstack.StatusRunning,
+		},
+		{
+			name: "completed service",
+			services: []service{
+				{Name: "web", State: "running", Health: "healthy"},
+				{Name: "migrate", State: "exited", ExitCode: 0},
+			},
+			requireHealthy: true,
+			expected:       libstack.StatusRunning,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			status, errorMessage := aggregateStatuses(context.Background(), libstack.Options{ProjectName: "stack"}, testCase.services, testCase.requireHealthy)
+			require.Equal(t, testCase.expected, status)
+			require.Empty(t, errorMessage)
+		})
+	}
+}
+
+func TestAggregateStatuses_unhealthy(t *testing.T) {
+	// The logs of the containers cannot be retrieved without a Docker host
+	options := libstack.Options{ProjectName: "stack", Host: "unix:///nonexistent/docker.sock"}
+
+	services := []service{
+		{Name: "web", State: "running", Health: "unhealthy"},
+		{Name: "worker", State: "running", Health: "healthy"},
+	}
+
+	status, errorMessage := aggregateStatuses(context.Background(), options, services, true)
+	require.Equal(t, libstack.StatusError, status)
+	require.Contains(t, errorMessage, "service web is unhealthy")
+
+	status, errorMessage = aggregateStatuses(context.Background(), options, services, false)
+	require.Equal(t, libstack.StatusRunning, status)
+	require.Empty(t, errorMessage)
+}
+
+func TestPendingServicesMessage(t *testing.T) {
+	options := libstack.Options{ProjectName: "stack", Host: "unix:///nonexistent/docker.sock"}
+
+	services := []service{
+		{Name: "web", State: "running", Health: "starting"},
+		{Name: "worker", State: "running", Health: "healthy"},
+		{Name: "migrate", State: "exited", ExitCode: 0},
+		{Name: "queue", State: "restarting"},
+	}
+
+	message := pendingServicesMessage(context.Background(), options, services)
+	require.Equal(t, "\nservice web is running (starting)\nservice queue is restarting", message)
+}
+
+func TestPendingServicesMessage_expiredContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	logsTail := containerLogsTail
+	t.Cleanup(func() { containerLogsTail = logsTail })
+
+	containerLogsTail = func(ctx context.Context, options libstack.Options, service service) (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		return "logs of " + service.Name, nil
+	}
+
+	services := []service{
+		{Name: "web", State: "running", Health: "starting"},
+		{Name: "queue", State: "restarting"},
+	}
+
+	message := pendingServicesMessage(ctx, libstack.Options{ProjectName: "stack"}, services)
+	require.Equal(t, "\nservice web is running (starting)\nlogs of web\nservice queue is restarting", message)
+}
+
+func TestWaitForHealthy_timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	result := (&ComposeDeployer{}).WaitForHealthy(ctx, libstack.Options{ProjectName: "stack"})
+	require.Equal(t, "failed to wait for status: context deadline exceeded", result.ErrorMsg)
+}
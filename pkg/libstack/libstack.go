@@ -19,7 +19,13 @@ type Deployer interface {
 	Run(ctx context.Context, filePaths []string, serviceName string, options RunOptions) error
 	Validate(ctx context.Context, filePaths []string, options Options) error
 	WaitForStatus(ctx context.Context, name string, status Status) WaitResult
+	// WaitForHealthy waits until every service of the project is running, and healthy when it has a healthcheck,
+	// or has completed. The wait ends with an error when a service fails or when the context is done
+	WaitForHealthy(ctx context.Context, options Options) WaitResult
 	Config(ctx context.Context, filePaths []string, options Options) ([]byte, error)
+	// Plan computes the changes that Deploy would apply to the running containers of the project, nothing is
+	// created, pulled or removed
+	Plan(ctx context.Context, filePaths []string, options DeployOptions) (Plan, error)
 	GetExistingEdgeStacks(ctx context.Context) ([]EdgeStack, error)
 }
 
@@ -87,6 +93,44 @@ type RemoveOptions struct {
 	Volumes bool
 }
 
+type ServiceAction string
+
+const (
+	// ServiceActionCreate is used when the service has no container yet
+	ServiceActionCreate ServiceAction = "create"
+	// ServiceActionRecreate is used when the containers of the service are replaced
+	ServiceActionRecreate ServiceAction = "recreate"
+	// ServiceActionUnchanged is used when the containers of the service are kept as they are
+	ServiceActionUnchanged ServiceAction = "unchanged"
+	// ServiceActionRemove is used for the containers of a service that is no longer in the project and that are removed
+	ServiceActionRemove ServiceAction = "remove"
+	// ServiceActionOrphan is used for the containers of a service that is no longer in the project and that are
+	// left running
+	ServiceActionOrphan ServiceAction = "orphan"
+)
+
+type ChangeReason string
+
+const (
+	ChangeReasonImage         ChangeReason = "image"
+	ChangeReasonEnvironment   ChangeReason = "environment"
+	ChangeReasonPorts         ChangeReason = "ports"
+	ChangeReasonVolumes       ChangeReason = "volumes"
+	ChangeReasonNetworks      ChangeReason = "networks"
+	ChangeReasonConfiguration ChangeReason = "configuration"
+	ChangeReasonForced        ChangeReason = "forced"
+)
+
+type ServicePlan struct {
+	Name    string
+	Action  ServiceAction
+	Reasons []ChangeReason
+}
+
+type Plan struct {
+	Services []ServicePlan
+}
+
 type EdgeStack struct {
 	ID       int
 	Name     string
@@ -0,0 +1,840 @@
+package swarm
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/cli/opts"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+const (
+	// StackNamespaceLabel is the label holding the name of the stack of a Swarm resource, it is shared with the
+	// Docker CLI so that the stacks deployed by either of them can be managed by the other one
+	StackNamespaceLabel = "com.docker.stack.namespace"
+	// stackImageLabel keeps the image written in the compose file, the image of the service spec being replaced by
+	// its digest when the manager resolves it
+	stackImageLabel = "com.docker.stack.image"
+
+	defaultNetworkName   = "default"
+	defaultNetworkDriver = "overlay"
+)
+
+// scope prefixes a resource name with the name of the stack
+func scope(project *types.Project, name string) string {
+	return project.Name + "_" + name
+}
+
+// stackLabels returns a copy of the labels holding the stack namespace label
+func stackLabels(namespace string, labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	maps.Copy(result, labels)
+	result[StackNamespaceLabel] = namespace
+
+	return result
+}
+
+// convertNetworks returns the create options of the stack networks used by the services keyed by network name, and
+// the names of the external networks used by the services
+func convertNetworks(project *types.Project) (map[string]network.CreateOptions, []string) {
+	result := make(map[string]network.CreateOptions)
+	var externalNetworks []string
+
+	for _, key := range usedNetworks(project) {
+		nw := project.Networks[key]
+		if nw.External {
+			externalNetworks = append(externalNetworks, nw.Name)
+			continue
+		}
+
+		createOpts := network.CreateOptions{
+			Labels:     stackLabels(project.Name, nw.Labels),
+			Driver:     nw.Driver,
+			Options:    nw.DriverOpts,
+			Internal:   nw.Internal,
+			Attachable: nw.Attachable,
+		}
+
+		if createOpts.Driver == "" {
+			createOpts.Driver = defaultNetworkDriver
+		}
+
+		if nw.Ipam.Driver != "" || len(nw.Ipam.Config) > 0 {
+			createOpts.IPAM = &network.IPAM{Driver: nw.Ipam.Driver}
+
+			for _, pool := range nw.Ipam.Config {
+				createOpts.IPAM.Config = append(createOpts.IPAM.Config, network.IPAMConfig{
+					Subnet:     pool.Subnet,
+					IPRange:    pool.IPRange,
+					Gateway:    pool.Gateway,
+					AuxAddress: pool.AuxiliaryAddresses,
+				})
+			}
+		}
+
+		result[nw.Name] = createOpts
+	}
+
+	sort.Strings(externalNetworks)
+
+	return result, externalNetworks
+}
+
+// usedNetworks returns the sorted keys of the networks used by the services of the project
+func usedNetworks(project *types.Project) []string {
+	keys := make(map[string]struct{})
+	for _, service := range project.Services {
+		if len(service.Networks) == 0 {
+			if _, ok := project.Networks[defaultNetworkName]; ok {
+				keys[defaultNetworkName] = struct{}{}
+			}
+
+			continue
+		}
+
+		for key := range service.Networks {
+			keys[key] = struct{}{}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(keys))
+}
+
+// convertSecrets converts the secrets of the project that are not external to Swarm secret specs
+func convertSecrets(project *types.Project) ([]swarm.SecretSpec, error) {
+	var result []swarm.SecretSpec
+
+	for _, key := range slices.Sorted(maps.Keys(project.Secrets)) {
+		secret := project.Secrets[key]
+		if secret.External {
+			continue
+		}
+
+		spec := swarm.SecretSpec{
+			Annotations: swarm.Annotations{
+				Name:   secret.Name,
+				Labels: stackLabels(project.Name, secret.Labels),
+			},
+		}
+
+		if secret.Driver != "" {
+			spec.Data = []byte{}
+			spec.Driver = &swarm.Driver{
+				Name:    secret.Driver,
+				Options: secret.DriverOpts,
+			}
+		} else {
+			data, err := fileObjectData(project, types.FileObjectConfig(secret))
+			if err != nil {
+				return nil, fmt.Errorf("secret %s: %w", key, err)
+			}
+
+			spec.Data = data
+		}
+
+		if secret.TemplateDriver != "" {
+			spec.Templating = &swarm.Driver{Name: secret.TemplateDriver}
+		}
+
+		result = append(result, spec)
+	}
+
+	return result, nil
+}
+
+// convertConfigs converts the configs of the project that are not external to Swarm config specs
+func convertConfigs(project *types.Project) ([]swarm.ConfigSpec, error) {
+	var result []swarm.ConfigSpec
+
+	for _, key := range slices.Sorted(maps.Keys(project.Configs)) {
+		config := project.Configs[key]
+		if config.External {
+			continue
+		}
+
+		data, err := fileObjectData(project, types.FileObjectConfig(config))
+		if err != nil {
+			return nil, fmt.Errorf("config %s: %w", key, err)
+		}
+
+		spec := swarm.ConfigSpec{
+			Annotations: swarm.Annotations{
+				Name:   config.Name,
+				Labels: stackLabels(project.Name, config.Labels),
+			},
+			Data: data,
+		}
+
+		if config.TemplateDriver != "" {
+			spec.Templating = &swarm.Driver{Name: config.TemplateDriver}
+		}
+
+		result = append(result, spec)
+	}
+
+	return result, nil
+}
+
+// fileObjectData reads the content of a secret or a config from its inline content, its environment variable or its file
+func fileObjectData(project *types.Project, obj types.FileObjectConfig) ([]byte, error) {
+	switch {
+	case obj.Content != "":
+		return []byte(obj.Content), nil
+	case obj.Environment != "":
+		value, ok := project.Environment[obj.Environment]
+		if !ok {
+			return nil, fmt.Errorf("environment variable %q is not set", obj.Environment)
+		}
+
+		return []byte(value), nil
+	case obj.File != "":
+		return os.ReadFile(obj.File)
+	}
+
+	return nil, errors.New("one of file, content or environment is required")
+}
+
+// convertService converts a compose service to a Swarm service spec. The secret, config and credential spec
+// references only hold the names of the objects, their IDs are set by resolveReferences once the objects exist.
+func convertService(project *types.Project, service types.ServiceConfig) (swarm.ServiceSpec, error) {
+	if service.Image == "" {
+		return swarm.ServiceSpec{}, errors.New("an image is required, building images is not supported by Swarm")
+	}
+
+	var deploy types.DeployConfig
+	if service.Deploy != nil {
+		deploy = *service.Deploy
+	}
+
+	mode, err := convertDeployMode(deploy.Mode, deploy.Replicas)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	endpoint, err := convertEndpointSpec(deploy.EndpointMode, service.Ports)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	mounts, err := convertVolumes(project, service.Volumes)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	resources, err := convertResources(deploy.Resources)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	restartPolicy, err := convertRestartPolicy(service.Restart, deploy.RestartPolicy)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	healthcheck, err := convertHealthcheck(service.HealthCheck)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	networks, err := convertServiceNetworks(project, service)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	secrets, err := convertServiceSecrets(project, service.Secrets)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	configs, err := convertServiceConfigs(project, service.Configs)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	credentialSpec, credentialSpecConfig, err := convertCredentialSpec(project, service.CredentialSpec)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+
+	if credentialSpecConfig != nil {
+		configs = append(configs, credentialSpecConfig)
+	}
+
+	var logDriver *swarm.Driver
+	if service.Logging != nil {
+		logDriver = &swarm.Driver{
+			Name:    service.Logging.Driver,
+			Options: service.Logging.Options,
+		}
+	}
+
+	spec := swarm.ServiceSpec{
+		Annotations: swarm.Annotations{
+			Name:   scope(project, service.Name),
+			Labels: stackLabels(project.Name, deploy.Labels),
+		},
+		TaskTemplate: swarm.TaskSpec{
+			ContainerSpec: &swarm.ContainerSpec{
+				Image:           service.Image,
+				Command:         service.Entrypoint,
+				Args:            service.Command,
+				Hostname:        service.Hostname,
+				Hosts:           convertExtraHosts(service.ExtraHosts),
+				DNSConfig:       convertDNSConfig(service),
+				Healthcheck:     healthcheck,
+				Env:             convertEnvironment(service.Environment),
+				Labels:          stackLabels(project.Name, service.Labels),
+				Dir:             service.WorkingDir,
+				User:            service.User,
+				Mounts:          mounts,
+				StopGracePeriod: durationPtr(service.StopGracePeriod),
+				StopSignal:      service.StopSignal,
+				TTY:             service.Tty,
+				OpenStdin:       service.StdinOpen,
+				Secrets:         secrets,
+				Configs:         configs,
+				ReadOnly:        service.ReadOnly,
+				Privileges:      &swarm.Privileges{CredentialSpec: credentialSpec},
+				Isolation:       container.Isolation(service.Isolation),
+				Init:            service.Init,
+				Sysctls:         service.Sysctls,
+				CapabilityAdd:   service.CapAdd,
+				CapabilityDrop:  service.CapDrop,
+				Ulimits:         convertUlimits(service.Ulimits),
+				OomScoreAdj:     service.OomScoreAdj,
+			},
+			LogDriver:     logDriver,
+			Resources:     resources,
+			RestartPolicy: restartPolicy,
+			Placement: &swarm.Placement{
+				Constraints: deploy.Placement.Constraints,
+				Preferences: convertPlacementPreferences(deploy.Placement.Preferences),
+				MaxReplicas: deploy.Placement.MaxReplicas,
+			},
+			Networks: networks,
+		},
+		EndpointSpec:   endpoint,
+		Mode:           mode,
+		UpdateConfig:   convertUpdateConfig(deploy.UpdateConfig),
+		RollbackConfig: convertUpdateConfig(deploy.RollbackConfig),
+	}
+
+	spec.Labels[stackImageLabel] = service.Image
+
+	return spec, nil
+}
+
+func convertDeployMode(mode string, replicas *int) (swarm.ServiceMode, error) {
+	var count *uint64
+	if replicas != nil {
+		if *replicas < 0 {
+			return swarm.ServiceMode{}, fmt.Errorf("invalid number of replicas: %d", *replicas)
+		}
+
+		c := uint64(*replicas)
+		count = &c
+	}
+
+	switch mode {
+	case "global", "global-job":
+		if count != nil {
+			return swarm.ServiceMode{}, errors.New("replicas can only be used with the replicated and replicated-job modes")
+		}
+
+		if mode == "global-job" {
+			return swarm.ServiceMode{GlobalJob: &swarm.GlobalJob{}}, nil
+		}
+
+		return swarm.ServiceMode{Global: &swarm.GlobalService{}}, nil
+	case "replicated-job":
+		return swarm.ServiceMode{ReplicatedJob: &swarm.ReplicatedJob{MaxConcurrent: count, TotalCompletions: count}}, nil
+	case "replicated", "":
+		return swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: count}}, nil
+	}
+
+	return swarm.ServiceMode{}, fmt.Errorf("unknown deploy mode: %s", mode)
+}
+
+func convertEndpointSpec(endpointMode string, ports []types.ServicePortConfig) (*swarm.EndpointSpec, error) {
+	portConfigs := make([]swarm.PortConfig, 0, len(ports))
+
+	for _, port := range ports {
+		var published uint64
+		if port.Published != "" {
+			var err error
+			if published, err = strconv.ParseUint(port.Published, 10, 16); err != nil {
+				return nil, fmt.Errorf("invalid published port %q", port.Published)
+			}
+		}
+
+		portConfigs = append(portConfigs, swarm.PortConfig{
+			Name:          port.Name,
+			Protocol:      swarm.PortConfigProtocol(port.Protocol),
+			TargetPort:    port.Target,
+			PublishedPort: uint32(published),
+			PublishMode:   swarm.PortConfigPublishMode(port.Mode),
+		})
+	}
+
+	sort.SliceStable(portConfigs, func(i, j int) bool {
+		return portConfigs[i].PublishedPort < portConfigs[j].PublishedPort
+	})
+
+	return &swarm.EndpointSpec{
+		Mode:  swarm.ResolutionMode(strings.ToLower(endpointMode)),
+		Ports: portConfigs,
+	}, nil
+}
+
+func convertVolumes(project *types.Project, volumes []types.ServiceVolumeConfig) ([]mount.Mount, error) {
+	mounts := make([]mount.Mount, 0, len(volumes))
+
+	for _, volume := range volumes {
+		m := mount.Mount{
+			Type:        mount.Type(volume.Type),
+			Source:      volume.Source,
+			Target:      volume.Target,
+			ReadOnly:    volume.ReadOnly,
+			Consistency: mount.Consistency(volume.Consistency),
+		}
+
+		switch volume.Type {
+		case types.VolumeTypeVolume:
+			if volume.Volume != nil {
+				m.VolumeOptions = &mount.VolumeOptions{
+					NoCopy:  volume.Volume.NoCopy,
+					Subpath: volume.Volume.Subpath,
+				}
+			}
+
+			// Anonymous volumes have no source
+			if volume.Source == "" {
+				break
+			}
+
+			stackVolume, ok := project.Volumes[volume.Source]
+			if !ok {
+				return nil, fmt.Errorf("undefined volume %q", volume.Source)
+			}
+
+			m.Source = stackVolume.Name
+			if stackVolume.External {
+				break
+			}
+
+			if m.VolumeOptions == nil {
+				m.VolumeOptions = &mount.VolumeOptions{}
+			}
+
+			m.VolumeOptions.Labels = stackLabels(project.Name, stackVolume.Labels)
+			if stackVolume.Driver != "" || stackVolume.DriverOpts != nil {
+				m.VolumeOptions.DriverConfig = &mount.Driver{
+					Name:    stackVolume.Driver,
+					Options: stackVolume.DriverOpts,
+				}
+			}
+		case types.VolumeTypeBind:
+			if volume.Bind != nil && volume.Bind.Propagation != "" {
+				m.BindOptions = &mount.BindOptions{Propagation: mount.Propagation(volume.Bind.Propagation)}
+			}
+		case types.VolumeTypeTmpfs:
+			if volume.Tmpfs != nil {
+				m.TmpfsOptions = &mount.TmpfsOptions{
+					SizeBytes: int64(volume.Tmpfs.Size),
+					Mode:      os.FileMode(volume.Tmpfs.Mode),
+				}
+			}
+		case types.VolumeTypeNamedPipe:
+		default:
+			return nil, fmt.Errorf("volume type %q is not supported by Swarm", volume.Type)
+		}
+
+		mounts = append(mounts, m)
+	}
+
+	return mounts, nil
+}
+
+func convertResources(source types.Resources) (*swarm.ResourceRequirements, error) {
+	resources := &swarm.ResourceRequirements{}
+
+	if source.Limits != nil {
+		cpus, err := convertCPUs(source.Limits.NanoCPUs)
+		if err != nil {
+			return nil, err
+		}
+
+		resources.Limits = &swarm.Limit{
+			NanoCPUs:    cpus,
+			MemoryBytes: int64(source.Limits.MemoryBytes),
+			Pids:        source.Limits.Pids,
+		}
+	}
+
+	if source.Reservations != nil {
+		cpus, err := convertCPUs(source.Reservations.NanoCPUs)
+		if err != nil {
+			return nil, err
+		}
+
+		var generic []swarm.GenericResource
+		for _, res := range source.Reservations.GenericResources {
+			var r swarm.GenericResource
+			if res.DiscreteResourceSpec != nil {
+				r.DiscreteResourceSpec = &swarm.DiscreteGenericResource{
+					Kind:  res.DiscreteResourceSpec.Kind,
+					Value: res.DiscreteResourceSpec.Value,
+				}
+			}
+
+			generic = append(generic, r)
+		}
+
+		resources.Reservations = &swarm.Resources{
+			NanoCPUs:         cpus,
+			MemoryBytes:      int64(source.Reservations.MemoryBytes),
+			GenericResources: generic,
+		}
+	}
+
+	return resources, nil
+}
+
+// convertCPUs converts a number of CPUs to nano CPUs, going through its decimal representation to not carry the
+// rounding errors of the float
+func convertCPUs(cpus types.NanoCPUs) (int64, error) {
+	if cpus == 0 {
+		return 0, nil
+	}
+
+	return opts.ParseCPUs(strconv.FormatFloat(float64(cpus), 'f', -1, 32))
+}
+
+func convertRestartPolicy(restart string, source *types.RestartPolicy) (*swarm.RestartPolicy, error) {
+	if source != nil {
+		return &swarm.RestartPolicy{
+			Condition:   swarm.RestartPolicyCondition(source.Condition),
+			Delay:       durationPtr(source.Delay),
+			MaxAttempts: source.MaxAttempts,
+			Window:      durationPtr(source.Window),
+		}, nil
+	}
+
+	name, count, _ := strings.Cut(restart, ":")
+	switch name {
+	case "", "no":
+		return nil, nil
+	case "always", "unless-stopped":
+		return &swarm.RestartPolicy{Condition: swarm.RestartPolicyConditionAny}, nil
+	case "on-failure":
+		policy := &swarm.RestartPolicy{Condition: swarm.RestartPolicyConditionOnFailure}
+		if count != "" {
+			attempts, err := strconv.ParseUint(count, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid restart policy %q: %w", restart, err)
+			}
+
+			policy.MaxAttempts = &attempts
+		}
+
+		return policy, nil
+	}
+
+	return nil, fmt.Errorf("unknown restart policy: %s", restart)
+}
+
+func convertHealthcheck(healthcheck *types.HealthCheckConfig) (*container.HealthConfig, error) {
+	if healthcheck == nil {
+		return nil, nil
+	}
+
+	if healthcheck.Disable {
+		if len(healthcheck.Test) != 0 {
+			return nil, errors.New("healthcheck test and disable can't be set at the same time")
+		}
+
+		return &container.HealthConfig{Test: []string{"NONE"}}, nil
+	}
+
+	result := &container.HealthConfig{Test: healthcheck.Test}
+
+	if healthcheck.Timeout != nil {
+		result.Timeout = time.Duration(*healthcheck.Timeout)
+	}
+
+	if healthcheck.Interval != nil {
+		result.Interval = time.Duration(*healthcheck.Interval)
+	}
+
+	if healthcheck.StartPeriod != nil {
+		result.StartPeriod = time.Duration(*healthcheck.StartPeriod)
+	}
+
+	if healthcheck.StartInterval != nil {
+		result.StartInterval = time.Duration(*healthcheck.StartInterval)
+	}
+
+	if healthcheck.Retries != nil {
+		result.Retries = int(*healthcheck.Retries)
+	}
+
+	return result, nil
+}
+
+// convertServiceNetworks attaches the service to its networks, or to the default network of the stack when it
+// does not declare any. The service name is added to the aliases of the user defined networks.
+func convertServiceNetworks(project *types.Project, service types.ServiceConfig) ([]swarm.NetworkAttachmentConfig, error) {
+	networks := service.Networks
+	if len(networks) == 0 {
+		if _, ok := project.Networks[defaultNetworkName]; !ok {
+			return nil, nil
+		}
+
+		networks = map[string]*types.ServiceNetworkConfig{defaultNetworkName: nil}
+	}
+
+	attachments := make([]swarm.NetworkAttachmentConfig, 0, len(networks))
+	for key, config := range networks {
+		nw, ok := project.Networks[key]
+		if !ok {
+			return nil, fmt.Errorf("undefined network %q", key)
+		}
+
+		attachment := swarm.NetworkAttachmentConfig{Target: nw.Name}
+		if config != nil {
+			attachment.Aliases = slices.Clone(config.Aliases)
+			attachment.DriverOpts = config.DriverOpts
+		}
+
+		// Only the user defined networks support aliases
+		if container.NetworkMode(nw.Name).IsUserDefined() {
+			attachment.Aliases = append(attachment.Aliases, service.Name)
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].Target < attachments[j].Target
+	})
+
+	return attachments, nil
+}
+
+func convertServiceSecrets(project *types.Project, refs []types.ServiceSecretConfig) ([]*swarm.SecretReference, error) {
+	result := make([]*swarm.SecretReference, 0, len(refs))
+
+	for _, ref := range refs {
+		secret, ok := project.Secrets[ref.Source]
+		if !ok {
+			return nil, fmt.Errorf("undefined secret %q", ref.Source)
+		}
+
+		name, uid, gid, mode := fileReferenceTarget(types.FileReferenceConfig(ref))
+		result = append(result, &swarm.SecretReference{
+			File:       &swarm.SecretReferenceFileTarget{Name: name, UID: uid, GID: gid, Mode: mode},
+			SecretName: secret.Name,
+		})
+	}
+
+	// Sorted so that the services are not updated when only the order changes
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].SecretName < result[j].SecretName
+	})
+
+	return result, nil
+}
+
+func convertServiceConfigs(project *types.Project, refs []types.ServiceConfigObjConfig) ([]*swarm.ConfigReference, error) {
+	result := make([]*swarm.ConfigReference, 0, len(refs))
+
+	for _, ref := range refs {
+		config, ok := project.Configs[ref.Source]
+		if !ok {
+			return nil, fmt.Errorf("undefined config %q", ref.Source)
+		}
+
+		name, uid, gid, mode := fileReferenceTarget(types.FileReferenceConfig(ref))
+		result = append(result, &swarm.ConfigReference{
+			File:       &swarm.ConfigReferenceFileTarget{Name: name, UID: uid, GID: gid, Mode: mode},
+			ConfigName: config.Name,
+		})
+	}
+
+	// Sorted so that the services are not updated when only the order changes
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ConfigName < result[j].ConfigName
+	})
+
+	return result, nil
+}
+
+// fileReferenceTarget returns the file a secret or a config is mounted to, with the defaults of the Docker CLI
+func fileReferenceTarget(ref types.FileReferenceConfig) (string, string, string, os.FileMode) {
+	name, uid, gid, mode := ref.Target, ref.UID, ref.GID, os.FileMode(0o444)
+
+	if name == "" {
+		name = ref.Source
+	}
+
+	if uid == "" {
+		uid = "0"
+	}
+
+	if gid == "" {
+		gid = "0"
+	}
+
+	if ref.Mode != nil {
+		mode = os.FileMode(*ref.Mode)
+	}
+
+	return name, uid, gid, mode
+}
+
+// convertCredentialSpec converts the credential spec of a service. When it is read from a config, the config is
+// also returned as a runtime config reference of the service.
+func convertCredentialSpec(project *types.Project, spec *types.CredentialSpecConfig) (*swarm.CredentialSpec, *swarm.ConfigReference, error) {
+	if spec == nil {
+		return nil, nil, nil
+	}
+
+	var sources []string
+	for source, value := range map[string]string{"config": spec.Config, "file": spec.File, "registry": spec.Registry} {
+		if value != "" {
+			sources = append(sources, source)
+		}
+	}
+
+	switch len(sources) {
+	case 0:
+		return nil, nil, nil
+	case 1:
+	default:
+		slices.Sort(sources)
+		return nil, nil, fmt.Errorf("invalid credential spec: only one of %s can be set", strings.Join(sources, ", "))
+	}
+
+	if spec.Config == "" {
+		return &swarm.CredentialSpec{File: spec.File, Registry: spec.Registry}, nil, nil
+	}
+
+	config, ok := project.Configs[spec.Config]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid credential spec: undefined config %q", spec.Config)
+	}
+
+	return &swarm.CredentialSpec{Config: config.Name}, &swarm.ConfigReference{
+		ConfigName: config.Name,
+		Runtime:    &swarm.ConfigReferenceRuntimeTarget{},
+	}, nil
+}
+
+// convertExtraHosts converts the host to IP mappings to the "IP-address hostname" notation of SwarmKit
+func convertExtraHosts(extraHosts types.HostsList) []string {
+	hosts := make([]string, 0, len(extraHosts))
+	for _, host := range slices.Sorted(maps.Keys(extraHosts)) {
+		for _, ip := range extraHosts[host] {
+			hosts = append(hosts, ip+" "+host)
+		}
+	}
+
+	return hosts
+}
+
+func convertDNSConfig(service types.ServiceConfig) *swarm.DNSConfig {
+	if service.DNS == nil && service.DNSSearch == nil && service.DNSOpts == nil {
+		return nil
+	}
+
+	return &swarm.DNSConfig{
+		Nameservers: service.DNS,
+		Search:      service.DNSSearch,
+		Options:     service.DNSOpts,
+	}
+}
+
+// convertEnvironment converts the environment of a service to a sorted list of variables
+func convertEnvironment(environment types.MappingWithEquals) []string {
+	result := make([]string, 0, len(environment))
+	for name, value := range environment {
+		if value == nil {
+			result = append(result, name)
+			continue
+		}
+
+		result = append(result, name+"="+*value)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func convertUlimits(ulimits map[string]*types.UlimitsConfig) []*container.Ulimit {
+	result := make([]*container.Ulimit, 0, len(ulimits))
+	for _, name := range slices.Sorted(maps.Keys(ulimits)) {
+		u := ulimits[name]
+
+		soft, hard := int64(u.Soft), int64(u.Hard)
+		if u.Single != 0 {
+			soft, hard = int64(u.Single), int64(u.Single)
+		}
+
+		result = append(result, &container.Ulimit{Name: name, Soft: soft, Hard: hard})
+	}
+
+	return result
+}
+
+func convertPlacementPreferences(preferences []types.PlacementPreferences) []swarm.PlacementPreference {
+	result := make([]swarm.PlacementPreference, 0, len(preferences))
+	for _, preference := range preferences {
+		result = append(result, swarm.PlacementPreference{
+			Spread: &swarm.SpreadOver{SpreadDescriptor: preference.Spread},
+		})
+	}
+
+	return result
+}
+
+func convertUpdateConfig(source *types.UpdateConfig) *swarm.UpdateConfig {
+	if source == nil {
+		return nil
+	}
+
+	parallelism := uint64(1)
+	if source.Parallelism != nil {
+		parallelism = *source.Parallelism
+	}
+
+	return &swarm.UpdateConfig{
+		Parallelism:     parallelism,
+		Delay:           time.Duration(source.Delay),
+		FailureAction:   source.FailureAction,
+		Monitor:         time.Duration(source.Monitor),
+		MaxFailureRatio: source.MaxFailureRatio,
+		Order:           source.Order,
+	}
+}
+
+func durationPtr(d *types.Duration) *time.Duration {
+	if d == nil {
+		return nil
+	}
+
+	result := time.Duration(*d)
+
+	return &result
+}
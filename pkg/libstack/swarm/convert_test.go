@@ -0,0 +1,241 @@
+package swarm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer/pkg/libstack"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/require"
+)
+
+func createFile(t *testing.T, dir, fileName, content string) string {
+	t.Helper()
+
+	filePath := filepath.Join(dir, fileName)
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+
+	return filePath
+}
+
+func loadTestProject(t *testing.T, content string, env ...string) *types.Project {
+	t.Helper()
+
+	filePath := createFile(t, t.TempDir(), "docker-compose.yml", content)
+
+	project, err := createProject(context.Background(), []string{filePath}, libstack.Options{ProjectName: "stack", Env: env})
+	require.NoError(t, err)
+
+	return project
+}
+
+func TestConvertService(t *testing.T) {
+	project := loadTestProject(t, `services:
+  web:
+    image: nginx:${TAG}
+    command: ["nginx", "-g", "daemon off;"]
+    environment:
+      - B=2
+      - A=1
+    labels:
+      container.label: value
+    ports:
+      - "8080:80"
+    networks:
+      front:
+        aliases:
+          - www
+    volumes:
+      - data:/data
+      - type: tmpfs
+        target: /tmp
+        tmpfs:
+          size: 1024
+    secrets:
+      - source: password
+        target: db_password
+    healthcheck:
+      test: ["CMD", "true"]
+      interval: 10s
+      retries: 3
+    stop_grace_period: 30s
+    deploy:
+      replicas: 2
+      labels:
+        service.label: value
+      resources:
+        limits:
+          cpus: "0.1"
+          memory: 64M
+      restart_policy:
+        condition: on-failure
+        max_attempts: 3
+      placement:
+        constraints:
+          - node.role == worker
+      update_config:
+        parallelism: 2
+        order: start-first
+networks:
+  front:
+volumes:
+  data:
+secrets:
+  password:
+    environment: PASSWORD
+`, "TAG=1.27", "PASSWORD=secret")
+
+	spec, err := convertService(project, project.Services["web"])
+	require.NoError(t, err)
+
+	require.Equal(t, "stack_web", spec.Name)
+	require.Equal(t, map[string]string{
+		StackNamespaceLabel: "stack",
+		stackImageLabel:     "nginx:1.27",
+		"service.label":     "value",
+	}, spec.Labels)
+
+	containerSpec := spec.TaskTemplate.ContainerSpec
+	require.Equal(t, "nginx:1.27", containerSpec.Image)
+	require.Equal(t, []string{"nginx", "-g", "daemon off;"}, containerSpec.Args)
+	require.Equal(t, []string{"A=1", "B=2"}, containerSpec.Env)
+	require.Equal(t, map[string]string{StackNamespaceLabel: "stack", "container.label": "value"}, containerSpec.Labels)
+	require.Equal(t, 30*time.Second, *containerSpec.StopGracePeriod)
+	require.Equal(t, []string{"CMD", "true"}, containerSpec.Healthcheck.Test)
+	require.Equal(t, 10*time.Second, containerSpec.Healthcheck.Interval)
+	require.Equal(t, 3, containerSpec.Healthcheck.Retries)
+
+	require.Len(t, containerSpec.Mounts, 2)
+	require.Equal(t, mount.TypeVolume, containerSpec.Mounts[0].Type)
+	require.Equal(t, "stack_data", containerSpec.Mounts[0].Source)
+	require.Equal(t, "stack", containerSpec.Mounts[0].VolumeOptions.Labels[StackNamespaceLabel])
+	require.Equal(t, mount.TypeTmpfs, containerSpec.Mounts[1].Type)
+	require.Equal(t, int64(1024), containerSpec.Mounts[1].TmpfsOptions.SizeBytes)
+
+	require.Len(t, containerSpec.Secrets, 1)
+	require.Equal(t, "stack_password", containerSpec.Secrets[0].SecretName)
+	require.Equal(t, "db_password", containerSpec.Secrets[0].File.Name)
+	require.Equal(t, os.FileMode(0o444), containerSpec.Secrets[0].File.Mode)
+
+	require.Equal(t, []swarm.NetworkAttachmentConfig{
+		{Target: "stack_front", Aliases: []string{"www", "web"}},
+	}, spec.TaskTemplate.Networks)
+
+	require.Equal(t, uint64(2), *spec.Mode.Replicated.Replicas)
+	require.Equal(t, int64(100000000), spec.TaskTemplate.Resources.Limits.NanoCPUs)
+	require.Equal(t, int64(64*1024*1024), spec.TaskTemplate.Resources.Limits.MemoryBytes)
+	require.Equal(t, swarm.RestartPolicyConditionOnFailure, spec.TaskTemplate.RestartPolicy.Condition)
+	require.Equal(t, uint64(3), *spec.TaskTemplate.RestartPolicy.MaxAttempts)
+	require.Equal(t, []string{"node.role == worker"}, spec.TaskTemplate.Placement.Constraints)
+	require.Equal(t, uint64(2), spec.UpdateConfig.Parallelism)
+	require.Equal(t, "start-first", spec.UpdateConfig.Order)
+
+	require.Equal(t, []swarm.PortConfig{{
+		Protocol:      swarm.PortConfigProtocolTCP,
+		TargetPort:    80,
+		PublishedPort: 8080,
+		PublishMode:   swarm.PortConfigPublishModeIngress,
+	}}, spec.EndpointSpec.Ports)
+
+	secrets, err := convertSecrets(project)
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	require.Equal(t, "stack_password", secrets[0].Name)
+	require.Equal(t, []byte("secret"), secrets[0].Data)
+}
+
+func TestConvertService_defaultNetwork(t *testing.T) {
+	project := loadTestProject(t, `services:
+  web:
+    image: nginx
+`)
+
+	spec, err := convertService(project, project.Services["web"])
+	require.NoError(t, err)
+	require.Equal(t, []swarm.NetworkAttachmentConfig{
+		{Target: "stack_default", Aliases: []string{"web"}},
+	}, spec.TaskTemplate.Networks)
+
+	networks, externalNetworks := convertNetworks(project)
+	require.Empty(t, externalNetworks)
+	require.Len(t, networks, 1)
+	require.Equal(t, "overlay", networks["stack_default"].Driver)
+	require.Equal(t, "stack", networks["stack_default"].Labels[StackNamespaceLabel])
+}
+
+func TestConvertService_externalNetwork(t *testing.T) {
+	project := loadTestProject(t, `services:
+  web:
+    image: nginx
+    networks:
+      - proxy
+networks:
+  proxy:
+    external: true
+`)
+
+	spec, err := convertService(project, project.Services["web"])
+	require.NoError(t, err)
+	require.Equal(t, "proxy", spec.TaskTemplate.Networks[0].Target)
+
+	networks, externalNetworks := convertNetworks(project)
+	require.Empty(t, networks)
+	require.Equal(t, []string{"proxy"}, externalNetworks)
+}
+
+func TestConvertService_missingImage(t *testing.T) {
+	project := loadTestProject(t, `services:
+  web:
+    build: .
+`)
+
+	_, err := convertService(project, project.Services["web"])
+	require.Error(t, err)
+}
+
+func TestConvertDeployMode(t *testing.T) {
+	replicas := 3
+
+	mode, err := convertDeployMode("", nil)
+	require.NoError(t, err)
+	require.NotNil(t, mode.Replicated)
+	require.Nil(t, mode.Replicated.Replicas)
+
+	mode, err = convertDeployMode("global", nil)
+	require.NoError(t, err)
+	require.NotNil(t, mode.Global)
+
+	mode, err = convertDeployMode("replicated-job", &replicas)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), *mode.ReplicatedJob.TotalCompletions)
+
+	_, err = convertDeployMode("global", &replicas)
+	require.Error(t, err)
+
+	_, err = convertDeployMode("unknown", nil)
+	require.Error(t, err)
+}
+
+func TestConvertRestartPolicy(t *testing.T) {
+	policy, err := convertRestartPolicy("no", nil)
+	require.NoError(t, err)
+	require.Nil(t, policy)
+
+	policy, err = convertRestartPolicy("unless-stopped", nil)
+	require.NoError(t, err)
+	require.Equal(t, swarm.RestartPolicyConditionAny, policy.Condition)
+
+	policy, err = convertRestartPolicy("on-failure:5", nil)
+	require.NoError(t, err)
+	require.Equal(t, swarm.RestartPolicyConditionOnFailure, policy.Condition)
+	require.Equal(t, uint64(5), *policy.MaxAttempts)
+
+	_, err = convertRestartPolicy("sometimes", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,581 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/portainer/portainer/pkg/libstack"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	configtypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/registry"
+	"github.com/rs/zerolog/log"
+)
+
+const portainerEnvVarsPrefix = "PORTAINER_"
+
+// taskPollInterval is the interval between two checks of the tasks of a stack being removed
+var taskPollInterval = time.Second
+
+// ResolveImage defines when the Swarm manager queries the registry to pin the image of a service to a digest
+type ResolveImage string
+
+const (
+	// ResolveImageAlways queries the registry for the images of all the services
+	ResolveImageAlways ResolveImage = "always"
+	// ResolveImageChanged queries the registry for the services whose image changed in the compose file
+	ResolveImageChanged ResolveImage = "changed"
+	// ResolveImageNever never queries the registry, the images are used as written in the compose file
+	ResolveImageNever ResolveImage = "never"
+)
+
+// Operations reported by a ServiceError
+const (
+	ServiceOperationConvert = "convert"
+	ServiceOperationCreate  = "create"
+	ServiceOperationUpdate  = "update"
+	ServiceOperationRemove  = "remove"
+)
+
+type DeployOptions struct {
+	libstack.Options
+	// Prune removes the services of the stack that are no longer part of the compose files
+	Prune        bool
+	ResolveImage ResolveImage
+}
+
+// ServiceError is the error of an operation on a service of a stack. The errors of a deployment are joined so that
+// one failing service does not prevent the others from being deployed.
+type ServiceError struct {
+	// Service is the name of the service in the compose file
+	Service   string
+	Operation string
+	Err       error
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("failed to %s service %s: %s", e.Operation, e.Service, e.Err)
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// SwarmDeployer deploys compose files as Swarm stacks through the Docker API, the same way the
+// docker stack deploy and docker stack rm commands do
+type SwarmDeployer struct{}
+
+// NewSwarmDeployer returns a new SwarmDeployer
+func NewSwarmDeployer() *SwarmDeployer {
+	return &SwarmDeployer{}
+}
+
+// Deploy creates or updates the networks, secrets, configs and services of a stack. The compose files are
+// converted before anything is changed on the cluster, then the errors of the services are collected as
+// ServiceErrors.
+func (d *SwarmDeployer) Deploy(ctx context.Context, apiClient client.APIClient, filePaths []string, options DeployOptions) error {
+	if options.ProjectName == "" {
+		return errors.New("missing stack name")
+	}
+
+	if err := checkSwarmManager(ctx, apiClient); err != nil {
+		return err
+	}
+
+	project, err := createProject(ctx, filePaths, options.Options)
+	if err != nil {
+		return err
+	}
+
+	specs := make(map[string]swarm.ServiceSpec, len(project.Services))
+
+	var errs []error
+	for name, service := range project.Services {
+		spec, err := convertService(project, service)
+		if err != nil {
+			errs = append(errs, &ServiceError{Service: name, Operation: ServiceOperationConvert, Err: err})
+			continue
+		}
+
+		specs[name] = spec
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if err := createNetworks(ctx, apiClient, project); err != nil {
+		return err
+	}
+
+	if err := createSecrets(ctx, apiClient, project); err != nil {
+		return err
+	}
+
+	if err := createConfigs(ctx, apiClient, project); err != nil {
+		return err
+	}
+
+	if options.Prune {
+		errs = append(errs, pruneServices(ctx, apiClient, project)...)
+	}
+
+	errs = append(errs, deployServices(ctx, apiClient, project.Name, specs, options)...)
+
+	return errors.Join(errs...)
+}
+
+// Remove removes the services, secrets, configs and networks of a stack, then waits for the tasks of the stack
+// to be stopped
+func (d *SwarmDeployer) Remove(ctx context.Context, apiClient client.APIClient, stackName string) error {
+	services, err := apiClient.ServiceList(ctx, swarm.ServiceListOptions{Filters: stackFilter(stackName)})
+	if err != nil {
+		return fmt.Errorf("failed to list the services of the stack: %w", err)
+	}
+
+	secrets, err := apiClient.SecretList(ctx, swarm.SecretListOptions{Filters: stackFilter(stackName)})
+	if err != nil {
+		return fmt.Errorf("failed to list the secrets of the stack: %w", err)
+	}
+
+	configs, err := apiClient.ConfigList(ctx, swarm.ConfigListOptions{Filters: stackFilter(stackName)})
+	if err != nil {
+		return fmt.Errorf("failed to list the configs of the stack: %w", err)
+	}
+
+	networks, err := apiClient.NetworkList(ctx, network.ListOptions{Filters: stackFilter(stackName)})
+	if err != nil {
+		return fmt.Errorf("failed to list the networks of the stack: %w", err)
+	}
+
+	var errs []error
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Spec.Name < services[j].Spec.Name
+	})
+
+	for _, service := range services {
+		if err := apiClient.ServiceRemove(ctx, service.ID); err != nil {
+			errs = append(errs, &ServiceError{
+				Service:   strings.TrimPrefix(service.Spec.Name, stackName+"_"),
+				Operation: ServiceOperationRemove,
+				Err:       err,
+			})
+		}
+	}
+
+	for _, secret := range secrets {
+		if err := apiClient.SecretRemove(ctx, secret.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove secret %s: %w", secret.Spec.Name, err))
+		}
+	}
+
+	for _, config := range configs {
+		if err := apiClient.ConfigRemove(ctx, config.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove config %s: %w", config.Spec.Name, err))
+		}
+	}
+
+	for _, nw := range networks {
+		if err := apiClient.NetworkRemove(ctx, nw.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove network %s: %w", nw.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return waitForTasksRemoval(ctx, apiClient, stackName)
+}
+
+func createProject(ctx context.Context, configFilepaths []string, options libstack.Options) (*types.Project, error) {
+	var workingDir string
+	if len(configFilepaths) > 0 {
+		workingDir = filepath.Dir(configFilepaths[0])
+	}
+
+	if options.ProjectDir != "" {
+		workingDir = options.ProjectDir
+	}
+
+	var envFiles []string
+	if options.EnvFilePath != "" {
+		envFiles = append(envFiles, options.EnvFilePath)
+	}
+
+	var osPortainerEnvVars []string
+	for _, ev := range os.Environ() {
+		if strings.HasPrefix(ev, portainerEnvVarsPrefix) {
+			osPortainerEnvVars = append(osPortainerEnvVars, ev)
+		}
+	}
+
+	projectOptions, err := cli.NewProjectOptions(configFilepaths,
+		cli.WithWorkingDirectory(workingDir),
+		cli.WithName(options.ProjectName),
+		cli.WithoutEnvironmentResolution,
+		cli.WithResolvedPaths(true),
+		cli.WithEnv(osPortainerEnvVars),
+		cli.WithEnv(options.Env),
+		cli.WithEnvFiles(envFiles...),
+		// Swarm has no profiles, all the services are deployed
+		cli.WithProfiles([]string{"*"}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the compose file options : %w", err)
+	}
+
+	project, err := projectOptions.LoadProject(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the compose file : %w", err)
+	}
+
+	if project, err = project.WithServicesEnvironmentResolved(true); err != nil {
+		return nil, fmt.Errorf("failed to resolve services environment: %w", err)
+	}
+
+	return project, nil
+}
+
+func checkSwarmManager(ctx context.Context, apiClient client.APIClient) error {
+	info, err := apiClient.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the Docker information: %w", err)
+	}
+
+	if !info.Swarm.ControlAvailable {
+		return errors.New("the Docker environment is not a Swarm manager")
+	}
+
+	return nil
+}
+
+func stackFilter(stackName string) filters.Args {
+	return filters.NewArgs(filters.Arg("label", StackNamespaceLabel+"="+stackName))
+}
+
+// createNetworks creates the missing networks of the stack and makes sure the external networks exist
+func createNetworks(ctx context.Context, apiClient client.APIClient, project *types.Project) error {
+	networks, externalNetworks := convertNetworks(project)
+
+	for _, name := range externalNetworks {
+		// The networks that are not user defined exist on all the nodes
+		if !container.NetworkMode(name).IsUserDefined() {
+			continue
+		}
+
+		nw, err := apiClient.NetworkInspect(ctx, name, network.InspectOptions{})
+		switch {
+		case cerrdefs.IsNotFound(err):
+			return fmt.Errorf("network %q is declared as external, but could not be found", name)
+		case err != nil:
+			return fmt.Errorf("failed to inspect network %s: %w", name, err)
+		case nw.Scope != "swarm":
+			return fmt.Errorf("network %q is declared as external, but its scope is %q instead of \"swarm\"", name, nw.Scope)
+		}
+	}
+
+	existing, err := apiClient.NetworkList(ctx, network.ListOptions{Filters: stackFilter(project.Name)})
+	if err != nil {
+		return fmt.Errorf("failed to list the networks of the stack: %w", err)
+	}
+
+	existingNames := make(map[string]struct{}, len(existing))
+	for _, nw := range existing {
+		existingNames[nw.Name] = struct{}{}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(networks)) {
+		if _, ok := existingNames[name]; ok {
+			continue
+		}
+
+		log.Debug().Str("network", name).Msg("creating network")
+
+		if _, err := apiClient.NetworkCreate(ctx, name, networks[name]); err != nil {
+			return fmt.Errorf("failed to create network %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// createSecrets creates the secrets of the stack, or updates them when they already exist
+func createSecrets(ctx context.Context, apiClient client.APIClient, project *types.Project) error {
+	specs, err := convertSecrets(project)
+	if err != nil {
+		return err
+	}
+
+	for _, spec := range specs {
+		secret, _, err := apiClient.SecretInspectWithRaw(ctx, spec.Name)
+		switch {
+		case err == nil:
+			if err := apiClient.SecretUpdate(ctx, secret.ID, secret.Version, spec); err != nil {
+				return fmt.Errorf("failed to update secret %s: %w", spec.Name, err)
+			}
+		case cerrdefs.IsNotFound(err):
+			log.Debug().Str("secret", spec.Name).Msg("creating secret")
+
+			if _, err := apiClient.SecretCreate(ctx, spec); err != nil {
+				return fmt.Errorf("failed to create secret %s: %w", spec.Name, err)
+			}
+		default:
+			return fmt.Errorf("failed to inspect secret %s: %w", spec.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// createConfigs creates the configs of the stack, or updates them when they already exist
+func createConfigs(ctx context.Context, apiClient client.APIClient, project *types.Project) error {
+	specs, err := convertConfigs(project)
+	if err != nil {
+		return err
+	}
+
+	for _, spec := range specs {
+		config, _, err := apiClient.ConfigInspectWithRaw(ctx, spec.Name)
+		switch {
+		case err == nil:
+			if err := apiClient.ConfigUpdate(ctx, config.ID, config.Version, spec); err != nil {
+				return fmt.Errorf("failed to update config %s: %w", spec.Name, err)
+			}
+		case cerrdefs.IsNotFound(err):
+			log.Debug().Str("config", spec.Name).Msg("creating config")
+
+			if _, err := apiClient.ConfigCreate(ctx, spec); err != nil {
+				return fmt.Errorf("failed to create config %s: %w", spec.Name, err)
+			}
+		default:
+			return fmt.Errorf("failed to inspect config %s: %w", spec.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// pruneServices removes the services of the stack that are no longer part of the project
+func pruneServices(ctx context.Context, apiClient client.APIClient, project *types.Project) []error {
+	services, err := apiClient.ServiceList(ctx, swarm.ServiceListOptions{Filters: stackFilter(project.Name)})
+	if err != nil {
+		return []error{fmt.Errorf("failed to list the services of the stack: %w", err)}
+	}
+
+	var errs []error
+	for _, service := range services {
+		name := strings.TrimPrefix(service.Spec.Name, project.Name+"_")
+		if _, ok := project.Services[name]; ok {
+			continue
+		}
+
+		log.Debug().Str("service", service.Spec.Name).Msg("removing service")
+
+		if err := apiClient.ServiceRemove(ctx, service.ID); err != nil {
+			errs = append(errs, &ServiceError{Service: name, Operation: ServiceOperationRemove, Err: err})
+		}
+	}
+
+	return errs
+}
+
+// deployServices creates the services of the stack, or updates them when they already exist
+func deployServices(ctx context.Context, apiClient client.APIClient, stackName string, specs map[string]swarm.ServiceSpec, options DeployOptions) []error {
+	existing, err := apiClient.ServiceList(ctx, swarm.ServiceListOptions{Filters: stackFilter(stackName)})
+	if err != nil {
+		return []error{fmt.Errorf("failed to list the services of the stack: %w", err)}
+	}
+
+	existingServices := make(map[string]swarm.Service, len(existing))
+	for _, service := range existing {
+		existingServices[service.Spec.Name] = service
+	}
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(specs)) {
+		spec := specs[name]
+
+		service, exists := existingServices[spec.Name]
+
+		operation := ServiceOperationCreate
+		if exists {
+			operation = ServiceOperationUpdate
+		}
+
+		if err := deployService(ctx, apiClient, spec, service, exists, options); err != nil {
+			errs = append(errs, &ServiceError{Service: name, Operation: operation, Err: err})
+		}
+	}
+
+	return errs
+}
+
+func deployService(ctx context.Context, apiClient client.APIClient, spec swarm.ServiceSpec, existing swarm.Service, exists bool, options DeployOptions) error {
+	if err := resolveReferences(ctx, apiClient, &spec); err != nil {
+		return err
+	}
+
+	image := spec.TaskTemplate.ContainerSpec.Image
+
+	encodedAuth, err := encodedRegistryAuth(image, options.Registries)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		log.Debug().Str("service", spec.Name).Msg("creating service")
+
+		_, err := apiClient.ServiceCreate(ctx, spec, swarm.ServiceCreateOptions{
+			EncodedRegistryAuth: encodedAuth,
+			QueryRegistry:       options.ResolveImage != ResolveImageNever,
+		})
+
+		return err
+	}
+
+	log.Debug().Str("service", spec.Name).Str("id", existing.ID).Msg("updating service")
+
+	updateOpts := swarm.ServiceUpdateOptions{EncodedRegistryAuth: encodedAuth}
+
+	imageChanged := image != existing.Spec.Labels[stackImageLabel]
+	if options.ResolveImage == ResolveImageAlways || (options.ResolveImage != ResolveImageNever && imageChanged) {
+		updateOpts.QueryRegistry = true
+	} else if !imageChanged {
+		// Keep the digest resolved by the previous deployment, otherwise the service would be updated
+		spec.TaskTemplate.ContainerSpec.Image = existing.Spec.TaskTemplate.ContainerSpec.Image
+	}
+
+	// Keep the tasks of an unchanged service running
+	spec.TaskTemplate.ForceUpdate = existing.Spec.TaskTemplate.ForceUpdate
+
+	response, err := apiClient.ServiceUpdate(ctx, existing.ID, existing.Version, spec, updateOpts)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range response.Warnings {
+		log.Warn().Str("service", spec.Name).Msg(warning)
+	}
+
+	return nil
+}
+
+// resolveReferences sets the IDs of the secrets and configs referenced by a service spec
+func resolveReferences(ctx context.Context, apiClient client.APIClient, spec *swarm.ServiceSpec) error {
+	containerSpec := spec.TaskTemplate.ContainerSpec
+
+	for _, ref := range containerSpec.Secrets {
+		secret, _, err := apiClient.SecretInspectWithRaw(ctx, ref.SecretName)
+		if err != nil {
+			return fmt.Errorf("failed to find secret %s: %w", ref.SecretName, err)
+		}
+
+		ref.SecretID = secret.ID
+	}
+
+	for _, ref := range containerSpec.Configs {
+		config, _, err := apiClient.ConfigInspectWithRaw(ctx, ref.ConfigName)
+		if err != nil {
+			return fmt.Errorf("failed to find config %s: %w", ref.ConfigName, err)
+		}
+
+		ref.ConfigID = config.ID
+
+		if ref.Runtime != nil && containerSpec.Privileges != nil && containerSpec.Privileges.CredentialSpec != nil &&
+			containerSpec.Privileges.CredentialSpec.Config == ref.ConfigName {
+			containerSpec.Privileges.CredentialSpec.Config = config.ID
+		}
+	}
+
+	return nil
+}
+
+// encodedRegistryAuth returns the encoded credentials of the registry of an image, empty when none of the
+// registries matches
+func encodedRegistryAuth(image string, registries []configtypes.AuthConfig) (string, error) {
+	if len(registries) == 0 {
+		return "", nil
+	}
+
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %q: %w", image, err)
+	}
+
+	repoInfo, err := registry.ParseRepositoryInfo(named)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %q: %w", image, err)
+	}
+
+	authConfigs := make(map[string]registrytypes.AuthConfig, len(registries))
+	for _, r := range registries {
+		if r.ServerAddress == "" || r.ServerAddress == registry.DefaultNamespace {
+			r.ServerAddress = registry.IndexServer
+		}
+
+		authConfigs[r.ServerAddress] = registrytypes.AuthConfig{
+			Username:      r.Username,
+			Password:      r.Password,
+			Auth:          r.Auth,
+			ServerAddress: r.ServerAddress,
+			IdentityToken: r.IdentityToken,
+			RegistryToken: r.RegistryToken,
+		}
+	}
+
+	authConfig := registry.ResolveAuthConfig(authConfigs, repoInfo.Index)
+	if authConfig == (registrytypes.AuthConfig{}) {
+		return "", nil
+	}
+
+	return registrytypes.EncodeAuthConfig(authConfig)
+}
+
+// waitForTasksRemoval waits for all the tasks of a stack to reach a terminal state
+func waitForTasksRemoval(ctx context.Context, apiClient client.APIClient, stackName string) error {
+	for {
+		tasks, err := apiClient.TaskList(ctx, swarm.TaskListOptions{Filters: stackFilter(stackName)})
+		if err != nil {
+			return fmt.Errorf("failed to list the tasks of the stack: %w", err)
+		}
+
+		if !slices.ContainsFunc(tasks, func(task swarm.Task) bool { return !terminalState(task.Status.State) }) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to wait for the tasks of the stack to stop: %w", ctx.Err())
+		case <-time.After(taskPollInterval):
+		}
+	}
+}
+
+func terminalState(state swarm.TaskState) bool {
+	switch state {
+	case swarm.TaskStateComplete, swarm.TaskStateShutdown, swarm.TaskStateFailed,
+		swarm.TaskStateRejected, swarm.TaskStateRemove, swarm.TaskStateOrphaned:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,312 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/portainer/portainer/pkg/libstack"
+
+	cerrdefs "github.com/containerd/errdefs"
+	configtypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is an in-memory Swarm manager, the methods that are not overridden panic when called
+type fakeClient struct {
+	client.APIClient
+
+	notManager bool
+
+	networks map[string]network.Summary
+	secrets  map[string]swarm.Secret
+	configs  map[string]swarm.Config
+	services map[string]swarm.Service
+
+	createOptions map[string]swarm.ServiceCreateOptions
+	updateOptions map[string]swarm.ServiceUpdateOptions
+	failServices  map[string]error
+	removed       []string
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		networks:      make(map[string]network.Summary),
+		secrets:       make(map[string]swarm.Secret),
+		configs:       make(map[string]swarm.Config),
+		services:      make(map[string]swarm.Service),
+		createOptions: make(map[string]swarm.ServiceCreateOptions),
+		updateOptions: make(map[string]swarm.ServiceUpdateOptions),
+		failServices:  make(map[string]error),
+	}
+}
+
+func (c *fakeClient) Info(ctx context.Context) (system.Info, error) {
+	return system.Info{Swarm: swarm.Info{ControlAvailable: !c.notManager}}, nil
+}
+
+func (c *fakeClient) NetworkList(ctx context.Context, options network.ListOptions) ([]network.Summary, error) {
+	var result []network.Summary
+	for _, nw := range c.networks {
+		if matchesStack(options.Filters.Get("label"), nw.Labels) {
+			result = append(result, nw)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *fakeClient) NetworkCreate(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error) {
+	c.networks[name] = network.Summary{ID: "id-" + name, Name: name, Labels: options.Labels, Driver: options.Driver}
+
+	return network.CreateResponse{ID: "id-" + name}, nil
+}
+
+func (c *fakeClient) NetworkRemove(ctx context.Context, id string) error {
+	delete(c.networks, strings.TrimPrefix(id, "id-"))
+
+	return nil
+}
+
+func (c *fakeClient) SecretInspectWithRaw(ctx context.Context, name string) (swarm.Secret, []byte, error) {
+	secret, ok := c.secrets[name]
+	if !ok {
+		return swarm.Secret{}, nil, cerrdefs.ErrNotFound
+	}
+
+	return secret, nil, nil
+}
+
+func (c *fakeClient) SecretCreate(ctx context.Context, spec swarm.SecretSpec) (swarm.SecretCreateResponse, error) {
+	c.secrets[spec.Name] = swarm.Secret{ID: "id-" + spec.Name, Spec: spec}
+
+	return swarm.SecretCreateResponse{ID: "id-" + spec.Name}, nil
+}
+
+func (c *fakeClient) SecretUpdate(ctx context.Context, id string, version swarm.Version, spec swarm.SecretSpec) error {
+	c.secrets[spec.Name] = swarm.Secret{ID: id, Spec: spec}
+
+	return nil
+}
+
+func (c *fakeClient) SecretList(ctx context.Context, options swarm.SecretListOptions) ([]swarm.Secret, error) {
+	var result []swarm.Secret
+	for _, secret := range c.secrets {
+		if matchesStack(options.Filters.Get("label"), secret.Spec.Labels) {
+			result = append(result, secret)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *fakeClient) SecretRemove(ctx context.Context, id string) error {
+	delete(c.secrets, strings.TrimPrefix(id, "id-"))
+
+	return nil
+}
+
+func (c *fakeClient) ConfigList(ctx context.Context, options swarm.ConfigListOptions) ([]swarm.Config, error) {
+	return nil, nil
+}
+
+func (c *fakeClient) ServiceList(ctx context.Context, options swarm.ServiceListOptions) ([]swarm.Service, error) {
+	var result []swarm.Service
+	for _, service := range c.services {
+		if matchesStack(options.Filters.Get("label"), service.Spec.Labels) {
+			result = append(result, service)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *fakeClient) ServiceCreate(ctx context.Context, spec swarm.ServiceSpec, options swarm.ServiceCreateOptions) (swarm.ServiceCreateResponse, error) {
+	if err := c.failServices[spec.Name]; err != nil {
+		return swarm.ServiceCreateResponse{}, err
+	}
+
+	c.services[spec.Name] = swarm.Service{ID: "id-" + spec.Name, Spec: spec}
+	c.createOptions[spec.Name] = options
+
+	return swarm.ServiceCreateResponse{ID: "id-" + spec.Name}, nil
+}
+
+func (c *fakeClient) ServiceUpdate(ctx context.Context, id string, version swarm.Version, spec swarm.ServiceSpec, options swarm.ServiceUpdateOptions) (swarm.ServiceUpdateResponse, error) {
+	c.services[spec.Name] = swarm.Service{ID: id, Spec: spec}
+	c.updateOptions[spec.Name] = options
+
+	return swarm.ServiceUpdateResponse{}, nil
+}
+
+func (c *fakeClient) ServiceRemove(ctx context.Context, id string) error {
+	name := strings.TrimPrefix(id, "id-")
+	delete(c.services, name)
+	c.removed = append(c.removed, name)
+
+	return nil
+}
+
+func (c *fakeClient) TaskList(ctx context.Context, options swarm.TaskListOptions) ([]swarm.Task, error) {
+	return nil, nil
+}
+
+func matchesStack(labelFilters []string, labels map[string]string) bool {
+	for _, filter := range labelFilters {
+		key, value, _ := strings.Cut(filter, "=")
+		if labels[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+const testComposeFile = `services:
+  web:
+    image: nginx:latest
+    secrets:
+      - password
+  db:
+    image: registry.example.com/postgres:16
+secrets:
+  password:
+    environment: PASSWORD
+`
+
+var testEnv = []string{"PASSWORD=secret"}
+
+func TestDeploy(t *testing.T) {
+	cli := newFakeClient()
+	filePath := createFile(t, t.TempDir(), "docker-compose.yml", testComposeFile)
+
+	err := NewSwarmDeployer().Deploy(context.Background(), cli, []string{filePath}, DeployOptions{
+		Options: libstack.Options{
+			ProjectName: "stack",
+			Env:         testEnv,
+			Registries: []configtypes.AuthConfig{
+				{ServerAddress: "registry.example.com", Username: "user", Password: "pass"},
+			},
+		},
+		ResolveImage: ResolveImageAlways,
+	})
+	require.NoError(t, err)
+
+	require.Contains(t, cli.networks, "stack_default")
+	require.Contains(t, cli.secrets, "stack_password")
+	require.Equal(t, []byte("secret"), cli.secrets["stack_password"].Spec.Data)
+
+	require.Len(t, cli.services, 2)
+	web := cli.services["stack_web"]
+	require.Equal(t, "id-stack_password", web.Spec.TaskTemplate.ContainerSpec.Secrets[0].SecretID)
+	require.True(t, cli.createOptions["stack_web"].QueryRegistry)
+	require.Empty(t, cli.createOptions["stack_web"].EncodedRegistryAuth)
+
+	auth, err := registry.DecodeAuthConfig(cli.createOptions["stack_db"].EncodedRegistryAuth)
+	require.NoError(t, err)
+	require.Equal(t, "user", auth.Username)
+	require.Equal(t, "pass", auth.Password)
+}
+
+func TestDeploy_update(t *testing.T) {
+	cli := newFakeClient()
+	filePath := createFile(t, t.TempDir(), "docker-compose.yml", testComposeFile)
+	options := DeployOptions{Options: libstack.Options{ProjectName: "stack", Env: testEnv}, ResolveImage: ResolveImageChanged}
+
+	deployer := NewSwarmDeployer()
+	require.NoError(t, deployer.Deploy(context.Background(), cli, []string{filePath}, options))
+
+	// Simulates the digest resolved by the manager
+	web := cli.services["stack_web"]
+	web.Spec.TaskTemplate.ContainerSpec.Image = "nginx:latest@sha256:abc"
+	cli.services["stack_web"] = web
+
+	require.NoError(t, deployer.Deploy(context.Background(), cli, []string{filePath}, options))
+
+	require.False(t, cli.updateOptions["stack_web"].QueryRegistry)
+	require.Equal(t, "nginx:latest@sha256:abc", cli.services["stack_web"].Spec.TaskTemplate.ContainerSpec.Image)
+
+	options.ResolveImage = ResolveImageAlways
+	require.NoError(t, deployer.Deploy(context.Background(), cli, []string{filePath}, options))
+
+	require.True(t, cli.updateOptions["stack_web"].QueryRegistry)
+	require.Equal(t, "nginx:latest", cli.services["stack_web"].Spec.TaskTemplate.ContainerSpec.Image)
+}
+
+func TestDeploy_prune(t *testing.T) {
+	cli := newFakeClient()
+	cli.services["stack_old"] = swarm.Service{
+		ID:   "id-stack_old",
+		Spec: swarm.ServiceSpec{Annotations: swarm.Annotations{Name: "stack_old", Labels: map[string]string{StackNamespaceLabel: "stack"}}},
+	}
+	cli.services["other_old"] = swarm.Service{
+		ID:   "id-other_old",
+		Spec: swarm.ServiceSpec{Annotations: swarm.Annotations{Name: "other_old", Labels: map[string]string{StackNamespaceLabel: "other"}}},
+	}
+
+	filePath := createFile(t, t.TempDir(), "docker-compose.yml", testComposeFile)
+
+	err := NewSwarmDeployer().Deploy(context.Background(), cli, []string{filePath}, DeployOptions{
+		Options: libstack.Options{ProjectName: "stack", Env: testEnv},
+		Prune:   true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"stack_old"}, cli.removed)
+	require.Contains(t, cli.services, "other_old")
+}
+
+func TestDeploy_serviceErrors(t *testing.T) {
+	cli := newFakeClient()
+	cli.failServices["stack_db"] = errors.New("no suitable node")
+
+	filePath := createFile(t, t.TempDir(), "docker-compose.yml", testComposeFile)
+
+	err := NewSwarmDeployer().Deploy(context.Background(), cli, []string{filePath}, DeployOptions{
+		Options: libstack.Options{ProjectName: "stack", Env: testEnv},
+	})
+	require.Error(t, err)
+
+	var serviceErr *ServiceError
+	require.ErrorAs(t, err, &serviceErr)
+	require.Equal(t, "db", serviceErr.Service)
+	require.Equal(t, ServiceOperationCreate, serviceErr.Operation)
+
+	// The other services are still deployed
+	require.Contains(t, cli.services, "stack_web")
+}
+
+func TestDeploy_notManager(t *testing.T) {
+	cli := newFakeClient()
+	cli.notManager = true
+
+	filePath := createFile(t, t.TempDir(), "docker-compose.yml", testComposeFile)
+
+	err := NewSwarmDeployer().Deploy(context.Background(), cli, []string{filePath}, DeployOptions{
+		Options: libstack.Options{ProjectName: "stack", Env: testEnv},
+	})
+	require.Error(t, err)
+	require.Empty(t, cli.services)
+}
+
+func TestRemove(t *testing.T) {
+	cli := newFakeClient()
+	filePath := createFile(t, t.TempDir(), "docker-compose.yml", testComposeFile)
+
+	deployer := NewSwarmDeployer()
+	require.NoError(t, deployer.Deploy(context.Background(), cli, []string{filePath}, DeployOptions{
+		Options: libstack.Options{ProjectName: "stack", Env: testEnv},
+	}))
+
+	require.NoError(t, deployer.Remove(context.Background(), cli, "stack"))
+
+	require.Empty(t, cli.services)
+	require.Empty(t, cli.secrets)
+	require.Empty(t, cli.networks)
+}